@@ -8,9 +8,13 @@ import (
 
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
+	"myapp/internal/pkg/config"
 	"myapp/internal/service/health"
 )
 
+// stopGracePeriod is the time left for the other stop hooks, such as closing databases, after the HTTP server drains
+const stopGracePeriod = 5 * time.Second
+
 var (
 	version   = "1.0.0"
 	buildTime = "unknown"
@@ -51,9 +55,11 @@ func main() {
 
 // runServe starts the health service with all its dependencies
 func runServe(cmd *cobra.Command, args []string) error {
+	var cfg *config.Config
 	app := fx.New(
 		health.AppModule, // Uses health's own app.go
 		fx.NopLogger,
+		fx.Populate(&cfg),
 	)
 
 	startCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -66,7 +72,8 @@ func runServe(cmd *cobra.Command, args []string) error {
 	// Wait for interrupt signal
 	<-app.Done()
 
-	stopCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	// Give the HTTP server its full drain budget, plus time for the remaining hooks
+	stopCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout()+stopGracePeriod)
 	defer cancel()
 
 	if err := app.Stop(stopCtx); err != nil {
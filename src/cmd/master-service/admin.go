@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/cache"
+)
+
+// adminModules wires the auth service against the master database for create-admin
+var adminModules = fx.Options(
+	commandModules,
+	cache.Module,
+	auth.ServiceModule,
+	fx.Invoke(auth.RegisterMigrations), // Create the users table on a fresh database
+)
+
+// createAdminOptions holds the flags of the create-admin command
+type createAdminOptions struct {
+	email    string
+	password string
+}
+
+// newCreateAdminCmd creates the create-admin command. modules must provide *auth.Service.
+func newCreateAdminCmd(modules fx.Option) *cobra.Command {
+	opts := &createAdminOptions{}
+	cmd := &cobra.Command{
+		Use:   "create-admin",
+		Short: "Create the initial admin user",
+		Long: "Create a user with the admin role in the master database.\n" +
+			"Missing --email or --password values are read from stdin. An existing email is left unchanged.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCreateAdmin(cmd, modules, opts)
+		},
+	}
+	cmd.Flags().StringVar(&opts.email, "email", "", "admin email")
+	cmd.Flags().StringVar(&opts.password, "password", "", "admin password (prompted when empty)")
+	return cmd
+}
+
+// runCreateAdmin starts the auth dependencies and creates the admin user
+func runCreateAdmin(cmd *cobra.Command, modules fx.Option, opts *createAdminOptions) error {
+	in := bufio.NewReader(cmd.InOrStdin())
+	out := cmd.OutOrStdout()
+
+	email, err := promptIfEmpty(in, out, "Email: ", opts.email)
+	if err != nil {
+		return err
+	}
+	password, err := promptIfEmpty(in, out, "Password: ", opts.password)
+	if err != nil {
+		return err
+	}
+
+	var service *auth.Service
+	stop, err := startCommandApp(modules, &service)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	user, err := service.CreateAdmin(cmd.Context(), email, password)
+	var exists *auth.ErrEmailExists
+	if errors.As(err, &exists) {
+		fmt.Fprintf(out, "User %s already exists, skipping\n", exists.Email)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("create admin: %w", err)
+	}
+
+	fmt.Fprintf(out, "Created admin %s (id %d)\n", user.Email, user.ID)
+	return nil
+}
+
+// promptIfEmpty returns value, or asks for it on out and reads a line from in when it is empty
+func promptIfEmpty(in *bufio.Reader, out io.Writer, prompt, value string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+
+	fmt.Fprint(out, prompt)
+	line, err := in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("read %s: %w", strings.TrimSuffix(strings.ToLower(prompt), ": "), err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,72 @@
+// +build cgo
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/testsupport"
+)
+
+// runCreateAdminCmd executes create-admin against service with args and stdin, and returns its output
+func runCreateAdminCmd(service *auth.Service, stdin string, args ...string) (string, error) {
+	cmd := newCreateAdminCmd(fx.Supply(service))
+	var out bytes.Buffer
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestCreateAdmin(t *testing.T) {
+	db := testsupport.NewTestDB(t)
+	require.NoError(t, auth.RunMigrations(db))
+	service := testsupport.NewTestAuthService(t, db)
+	ctx := context.Background()
+
+	out, err := runCreateAdminCmd(service, "", "--email", "Admin@Example.com", "--password", "AdminPass123")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Created admin admin@example.com")
+
+	resp, err := service.Login(ctx, &auth.LoginRequest{Email: "admin@example.com", Password: "AdminPass123"})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", resp.User.Role)
+	assert.True(t, resp.User.EmailVerified)
+
+	t.Run("existing email is skipped", func(t *testing.T) {
+		out, err := runCreateAdminCmd(service, "", "--email", "admin@example.com", "--password", "OtherPass123")
+		require.NoError(t, err)
+		assert.Contains(t, out, "already exists")
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "admin@example.com", Password: "AdminPass123"})
+		assert.NoError(t, err, "the existing password is kept")
+	})
+
+	t.Run("missing values are prompted", func(t *testing.T) {
+		out, err := runCreateAdminCmd(service, "prompted@example.com\nPromptPass123\n")
+		require.NoError(t, err)
+		assert.Contains(t, out, "Email: ")
+		assert.Contains(t, out, "Password: ")
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "prompted@example.com", Password: "PromptPass123"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("short password is rejected", func(t *testing.T) {
+		_, err := runCreateAdminCmd(service, "", "--email", "short@example.com", "--password", "short")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least 8 characters")
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "short@example.com", Password: "short"})
+		assert.Error(t, err)
+	})
+}
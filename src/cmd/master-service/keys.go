@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"myapp/internal/pkg/auth/keys"
+)
+
+// minKeyBits is the smallest RSA key size the token manager accepts
+const minKeyBits = 2048
+
+// keysGenerateOptions holds the flags of the keys generate command
+type keysGenerateOptions struct {
+	privatePath string
+	publicPath  string
+	bits        int
+	force       bool
+}
+
+// newKeysCmd creates the keys command used to manage the RSA key pair that signs access tokens
+func newKeysCmd() *cobra.Command {
+	keysCmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the RSA keys used to sign tokens",
+	}
+
+	opts := &keysGenerateOptions{}
+	generateCmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate a new RSA key pair",
+		Long: "Generate a new RSA key pair for auth.rsa_private_key_path and auth.rsa_public_key_path.\n" +
+			"Existing key files are kept unless --force is given.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runKeysGenerate(cmd, opts)
+		},
+	}
+	generateCmd.Flags().StringVar(&opts.privatePath, "private", "internal/pkg/auth/keys/private.pem", "path to write the private key")
+	generateCmd.Flags().StringVar(&opts.publicPath, "public", "internal/pkg/auth/keys/public.pem", "path to write the public key")
+	generateCmd.Flags().IntVar(&opts.bits, "bits", minKeyBits, fmt.Sprintf("key size in bits (at least %d)", minKeyBits))
+	generateCmd.Flags().BoolVar(&opts.force, "force", false, "overwrite existing key files")
+
+	keysCmd.AddCommand(generateCmd)
+	return keysCmd
+}
+
+// runKeysGenerate writes a new key pair and prints the public key fingerprint
+func runKeysGenerate(cmd *cobra.Command, opts *keysGenerateOptions) error {
+	if opts.bits < minKeyBits {
+		return fmt.Errorf("--bits must be at least %d, got %d", minKeyBits, opts.bits)
+	}
+	if opts.privatePath == "" || opts.publicPath == "" {
+		return fmt.Errorf("--private and --public are required")
+	}
+	if filepath.Clean(opts.privatePath) == filepath.Clean(opts.publicPath) {
+		return fmt.Errorf("--private and --public must be different files")
+	}
+
+	if !opts.force {
+		for _, path := range []string{opts.privatePath, opts.publicPath} {
+			_, err := os.Stat(path)
+			if err == nil {
+				return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("check %s: %w", path, err)
+			}
+		}
+	}
+
+	if err := keys.GenerateAndSaveKeyPair(opts.privatePath, opts.publicPath, opts.bits); err != nil {
+		return err
+	}
+
+	publicKey, err := keys.LoadPublicKeyPEM(opts.publicPath)
+	if err != nil {
+		return err
+	}
+	fingerprint, err := keys.PublicKeyFingerprint(publicKey)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Private key: %s\n", opts.privatePath)
+	fmt.Fprintf(out, "Public key:  %s\n", opts.publicPath)
+	fmt.Fprintf(out, "Fingerprint: %s\n", fingerprint)
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth/keys"
+)
+
+// runKeysCmd executes the keys command with args and returns its output
+func runKeysCmd(args ...string) (string, error) {
+	cmd := newKeysCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestKeysGenerate(t *testing.T) {
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "private.pem")
+	publicPath := filepath.Join(dir, "public.pem")
+
+	out, err := runKeysCmd("generate", "--private", privatePath, "--public", publicPath)
+	require.NoError(t, err)
+
+	privateKey, err := keys.LoadPrivateKeyPEM(privatePath)
+	require.NoError(t, err)
+	publicKey, err := keys.LoadPublicKeyPEM(publicPath)
+	require.NoError(t, err)
+	assert.Equal(t, 2048, privateKey.N.BitLen())
+	assert.True(t, privateKey.PublicKey.Equal(publicKey))
+
+	fingerprint, err := keys.PublicKeyFingerprint(publicKey)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Fingerprint: "+fingerprint)
+
+	t.Run("existing files are kept without force", func(t *testing.T) {
+		before, err := os.ReadFile(privatePath)
+		require.NoError(t, err)
+
+		_, err = runKeysCmd("generate", "--private", privatePath, "--public", publicPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--force")
+
+		after, err := os.ReadFile(privatePath)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("force overwrites", func(t *testing.T) {
+		_, err := runKeysCmd("generate", "--private", privatePath, "--public", publicPath, "--force")
+		require.NoError(t, err)
+
+		regenerated, err := keys.LoadPublicKeyPEM(publicPath)
+		require.NoError(t, err)
+		assert.False(t, regenerated.Equal(publicKey))
+	})
+}
+
+func TestKeysGenerate_InvalidFlags(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("bits below minimum", func(t *testing.T) {
+		_, err := runKeysCmd("generate", "--private", filepath.Join(dir, "a.pem"), "--public", filepath.Join(dir, "b.pem"), "--bits", "1024")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least 2048")
+	})
+
+	t.Run("same file for both keys", func(t *testing.T) {
+		_, err := runKeysCmd("generate", "--private", filepath.Join(dir, "key.pem"), "--public", filepath.Join(dir, "key.pem"))
+		require.Error(t, err)
+	})
+
+	_, err := os.Stat(filepath.Join(dir, "a.pem"))
+	assert.True(t, os.IsNotExist(err), "rejected flags write no files")
+}
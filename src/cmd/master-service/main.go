@@ -8,9 +8,15 @@ import (
 
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/logger"
 	"myapp/internal/service/master"
 )
 
+// stopGracePeriod is the time left for the other stop hooks, such as closing databases, after the HTTP server drains
+const stopGracePeriod = 5 * time.Second
+
 var (
 	version   = "1.0.0"
 	buildTime = "unknown"
@@ -45,7 +51,7 @@ func main() {
 		},
 	}
 
-	rootCmd.AddCommand(serveCmd, versionCmd)
+	rootCmd.AddCommand(serveCmd, versionCmd, newKeysCmd(), newCreateAdminCmd(adminModules), newMigrateCmd(commandModules))
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -55,9 +61,11 @@ func main() {
 
 // runServe starts the master service with all its dependencies
 func runServe(cmd *cobra.Command, args []string) error {
+	var cfg *config.Config
 	app := fx.New(
 		master.AppModule, // Uses master's own app.go (includes auth module)
 		fx.NopLogger,
+		fx.Populate(&cfg),
 	)
 
 	startCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
@@ -70,7 +78,8 @@ func runServe(cmd *cobra.Command, args []string) error {
 	// Wait for interrupt signal
 	<-app.Done()
 
-	stopCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	// Give the HTTP server its full drain budget, plus time for the remaining hooks
+	stopCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout()+stopGracePeriod)
 	defer cancel()
 
 	if err := app.Stop(stopCtx); err != nil {
@@ -79,3 +88,30 @@ func runServe(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// commandModules provides the config, logger and databases for commands that do not serve HTTP
+var commandModules = fx.Options(
+	config.Module,
+	logger.Module,
+	database.Module,
+)
+
+// startCommandApp starts modules for a one-off command and populates targets.
+// The returned stop function closes the app, including its database connections.
+func startCommandApp(modules fx.Option, targets ...interface{}) (func(), error) {
+	app := fx.New(modules, fx.NopLogger, fx.Populate(targets...))
+
+	startCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := app.Start(startCtx); err != nil {
+		return nil, fmt.Errorf("failed to start dependencies: %w", err)
+	}
+
+	return func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), stopGracePeriod)
+		defer cancel()
+		if err := app.Stop(stopCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error stopping dependencies: %v\n", err)
+		}
+	}, nil
+}
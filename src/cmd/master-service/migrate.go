@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/database"
+	custommw "myapp/internal/pkg/middleware"
+	mastermigration "myapp/internal/service/master/migration"
+)
+
+// masterMigrationSets returns the migrations of every service sharing the master database, in apply order
+func masterMigrationSets(logger *zap.Logger) []database.MigrationSet {
+	return []database.MigrationSet{
+		auth.Migrations(),
+		custommw.Migrations(),
+		mastermigration.Migrations(logger),
+	}
+}
+
+// newMigrateCmd creates the migrate command. modules must provide *database.DatabaseManager and *zap.Logger.
+func newMigrateCmd(modules fx.Option) *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply, roll back and inspect master database migrations",
+	}
+
+	upCmd := &cobra.Command{
+		Use:   "up",
+		Short: "Apply all pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withMigrators(modules, func(migrators []*database.Migrator) error {
+				return runMigrateUp(cmd, migrators)
+			})
+		},
+	}
+
+	var service string
+	downCmd := &cobra.Command{
+		Use:   "down",
+		Short: "Roll back the last applied migration of a service",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withMigrators(modules, func(migrators []*database.Migrator) error {
+				return runMigrateDown(cmd, migrators, service)
+			})
+		},
+	}
+	downCmd.Flags().StringVar(&service, "service", "", "service whose last migration is rolled back")
+	downCmd.MarkFlagRequired("service")
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "List migrations and when they were applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return withMigrators(modules, func(migrators []*database.Migrator) error {
+				return runMigrateStatus(cmd, migrators)
+			})
+		},
+	}
+
+	migrateCmd.AddCommand(upCmd, downCmd, statusCmd)
+	return migrateCmd
+}
+
+// withMigrators starts modules and calls fn with a migrator per master database service
+func withMigrators(modules fx.Option, fn func([]*database.Migrator) error) error {
+	var dbManager *database.DatabaseManager
+	var logger *zap.Logger
+	stop, err := startCommandApp(modules, &dbManager, &logger)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	sets := masterMigrationSets(logger)
+	migrators := make([]*database.Migrator, 0, len(sets))
+	for _, set := range sets {
+		migrator, err := database.NewMigrator(dbManager.MasterDB, set)
+		if err != nil {
+			return err
+		}
+		migrators = append(migrators, migrator)
+	}
+	return fn(migrators)
+}
+
+// runMigrateUp applies pending migrations service by service
+func runMigrateUp(cmd *cobra.Command, migrators []*database.Migrator) error {
+	out := cmd.OutOrStdout()
+	total := 0
+	for _, migrator := range migrators {
+		applied, err := migrator.Up(cmd.Context())
+		for _, m := range applied {
+			fmt.Fprintf(out, "Applied %s %d: %s\n", migrator.Service(), m.Version, m.Description)
+		}
+		total += len(applied)
+		if err != nil {
+			return err
+		}
+	}
+	if total == 0 {
+		fmt.Fprintln(out, "No pending migrations")
+	}
+	return nil
+}
+
+// runMigrateDown rolls back the last applied migration of service
+func runMigrateDown(cmd *cobra.Command, migrators []*database.Migrator, service string) error {
+	for _, migrator := range migrators {
+		if migrator.Service() != service {
+			continue
+		}
+		m, err := migrator.Down(cmd.Context())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Rolled back %s %d: %s\n", service, m.Version, m.Description)
+		return nil
+	}
+	return fmt.Errorf("unknown service %q", service)
+}
+
+// runMigrateStatus prints every migration and when it was applied
+func runMigrateStatus(cmd *cobra.Command, migrators []*database.Migrator) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tVERSION\tDESCRIPTION\tAPPLIED AT")
+	for _, migrator := range migrators {
+		statuses, err := migrator.Status(cmd.Context())
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", status.Service, status.Version, status.Description, appliedAt)
+		}
+	}
+	return w.Flush()
+}
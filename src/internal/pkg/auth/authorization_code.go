@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// codeChallengeMethodS256 is the only PKCE method accepted; plain challenges expose the verifier
+	codeChallengeMethodS256 = "S256"
+	// grantTypeAuthorizationCode is the OAuth2 grant exchanging an authorization code for tokens
+	grantTypeAuthorizationCode = "authorization_code"
+	// defaultAuthorizationCodeDuration is used when the config leaves the authorization code lifetime unset
+	defaultAuthorizationCodeDuration = time.Minute
+	// minCodeChallengeLength and maxCodeChallengeLength bound a base64url encoded challenge (RFC 7636 section 4.2)
+	minCodeChallengeLength = 43
+	maxCodeChallengeLength = 128
+)
+
+// Authorize issues a single-use authorization code for the user, bound to the request's PKCE code challenge
+// and redirect URI. The code is returned once and only its hash is stored.
+func (s *Service) Authorize(ctx context.Context, userID uint, req *AuthorizeRequest) (*AuthorizeResponse, error) {
+	if err := validateAuthorizeRequest(req); err != nil {
+		return nil, err
+	}
+
+	code, err := s.tokenManager.GenerateRefreshToken()
+	if err != nil {
+		return nil, fmt.Errorf("generate authorization code: %w", err)
+	}
+
+	duration := s.config.Auth.AuthorizationCodeDuration
+	if duration <= 0 {
+		duration = defaultAuthorizationCodeDuration
+	}
+	err = s.tokenRepo.SaveAuthorizationCode(ctx, &AuthorizationCode{
+		UserID:              userID,
+		Code:                hashToken(code),
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		RedirectURI:         req.RedirectURI,
+		ExpiresAt:           time.Now().Add(duration),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthorizeResponse{
+		Code:      code,
+		State:     req.State,
+		ExpiresIn: int64(duration.Seconds()),
+	}, nil
+}
+
+// ExchangeAuthorizationCode redeems an authorization code for an access and refresh token. The code is
+// consumed before the verifier is checked, so a wrong verifier also burns the code.
+func (s *Service) ExchangeAuthorizationCode(ctx context.Context, req *TokenRequest) (*LoginResponse, error) {
+	stored, err := s.tokenRepo.ConsumeAuthorizationCode(ctx, hashToken(req.Code))
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.RedirectURI != req.RedirectURI {
+		return nil, &ErrTokenInvalid{Message: "redirect_uri does not match the authorization request"}
+	}
+	if !ValidateCodeVerifier(req.CodeVerifier, stored.CodeChallenge, stored.CodeChallengeMethod) {
+		s.log(ctx).Warn("Authorization code presented with a mismatched code verifier",
+			zap.Uint("user_id", stored.UserID))
+		return nil, &ErrTokenInvalid{Message: "code_verifier does not match the code challenge"}
+	}
+
+	user, err := s.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	if user.IsLocked(time.Now()) {
+		return nil, &ErrAccountLocked{Until: *user.LockedUntil}
+	}
+
+	resp, err := s.issueLoginTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.log(ctx).Info("Authorization code exchanged",
+		zap.Uint("user_id", user.ID))
+	return resp, nil
+}
+
+// validateAuthorizeRequest checks the response type and the PKCE parameters of an authorization request
+func validateAuthorizeRequest(req *AuthorizeRequest) error {
+	if req.ResponseType != "code" {
+		return &ErrInvalidAuthorizationRequest{Message: "response_type must be code"}
+	}
+	if req.CodeChallengeMethod != codeChallengeMethodS256 {
+		return &ErrInvalidAuthorizationRequest{Message: "code_challenge_method must be S256"}
+	}
+	if len(req.CodeChallenge) < minCodeChallengeLength || len(req.CodeChallenge) > maxCodeChallengeLength {
+		return &ErrInvalidAuthorizationRequest{Message: fmt.Sprintf("code_challenge must be %d to %d characters",
+			minCodeChallengeLength, maxCodeChallengeLength)}
+	}
+	return nil
+}
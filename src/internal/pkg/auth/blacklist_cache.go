@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"myapp/internal/pkg/cache"
+	"myapp/internal/pkg/config"
+)
+
+// defaultBlacklistCacheSize is used when auth.blacklist_cache_size is not set
+const defaultBlacklistCacheSize = 10000
+
+// BlacklistCache remembers blacklist lookups so ValidateToken does not query the database on every request.
+// A miss says nothing about the token; the caller must then check the database.
+type BlacklistCache interface {
+	// Get returns the cached verdict for jti and whether there was one
+	Get(ctx context.Context, jti string) (blacklisted bool, found bool)
+	// SetBlacklisted records jti as blacklisted until expiresAt, replacing any cached verdict
+	SetBlacklisted(ctx context.Context, jti string, expiresAt time.Time)
+	// SetValid records jti as not blacklisted until the given time; a cached blacklisting is never replaced
+	SetValid(ctx context.Context, jti string, until time.Time)
+}
+
+// NewBlacklistCache returns a Redis-backed cache when Redis caching is enabled, shared by every instance,
+// otherwise an in-memory LRU holding up to auth.blacklist_cache_size entries
+func NewBlacklistCache(cfg *config.Config, redisCache *cache.Cache) BlacklistCache {
+	if redisCache != nil {
+		return NewRedisBlacklistCache(redisCache)
+	}
+	return NewMemoryBlacklistCache(cfg.Auth.BlacklistCacheSize)
+}
+
+// RegisterBlacklistCache installs the provided blacklist cache on the service
+func RegisterBlacklistCache(service *Service, blacklist BlacklistCache) {
+	service.SetBlacklistCache(blacklist)
+}
+
+// memoryBlacklistEntry is a cached verdict in MemoryBlacklistCache
+type memoryBlacklistEntry struct {
+	jti         string
+	blacklisted bool
+	expiresAt   time.Time
+}
+
+// MemoryBlacklistCache is a BlacklistCache kept in process memory, evicting the least recently used entry
+// when full. Blacklistings made by other instances are only seen once a cached valid verdict expires.
+type MemoryBlacklistCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // Front is the most recently used entry
+	entries map[string]*list.Element
+}
+
+// NewMemoryBlacklistCache creates an in-memory cache of up to size entries; size <= 0 uses the default
+func NewMemoryBlacklistCache(size int) *MemoryBlacklistCache {
+	if size <= 0 {
+		size = defaultBlacklistCacheSize
+	}
+	return &MemoryBlacklistCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns the cached verdict for jti; expired entries are dropped and reported as a miss
+func (c *MemoryBlacklistCache) Get(ctx context.Context, jti string) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[jti]
+	if !ok {
+		return false, false
+	}
+	entry := elem.Value.(*memoryBlacklistEntry)
+	if !time.Now().Before(entry.expiresAt) {
+		c.removeLocked(elem)
+		return false, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.blacklisted, true
+}
+
+// SetBlacklisted records jti as blacklisted until expiresAt
+func (c *MemoryBlacklistCache) SetBlacklisted(ctx context.Context, jti string, expiresAt time.Time) {
+	c.set(jti, true, expiresAt)
+}
+
+// SetValid records jti as not blacklisted until the given time unless it is cached as blacklisted
+func (c *MemoryBlacklistCache) SetValid(ctx context.Context, jti string, until time.Time) {
+	c.set(jti, false, until)
+}
+
+// Len returns the number of cached entries, including expired ones not yet dropped
+func (c *MemoryBlacklistCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// set stores a verdict; a valid verdict never replaces a blacklisting that has not expired
+func (c *MemoryBlacklistCache) set(jti string, blacklisted bool, expiresAt time.Time) {
+	now := time.Now()
+	if !now.Before(expiresAt) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[jti]; ok {
+		entry := elem.Value.(*memoryBlacklistEntry)
+		if !blacklisted && entry.blacklisted && now.Before(entry.expiresAt) {
+			return
+		}
+		entry.blacklisted = blacklisted
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[jti] = c.order.PushFront(&memoryBlacklistEntry{jti: jti, blacklisted: blacklisted, expiresAt: expiresAt})
+	if c.order.Len() > c.size {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// removeLocked drops an entry; the caller must hold c.mu
+func (c *MemoryBlacklistCache) removeLocked(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*memoryBlacklistEntry).jti)
+}
+
+// RedisBlacklistCache is a BlacklistCache stored in Redis, so a logout is seen by every instance at once.
+// Redis errors are treated as misses.
+type RedisBlacklistCache struct {
+	cache *cache.Cache
+}
+
+// NewRedisBlacklistCache creates a blacklist cache on top of the shared Redis cache
+func NewRedisBlacklistCache(redisCache *cache.Cache) *RedisBlacklistCache {
+	return &RedisBlacklistCache{cache: redisCache}
+}
+
+// blacklistKey returns the Redis key holding the verdict for jti
+func blacklistKey(jti string) string {
+	return "auth:blacklist:" + jti
+}
+
+// Get returns the cached verdict for jti
+func (c *RedisBlacklistCache) Get(ctx context.Context, jti string) (bool, bool) {
+	var blacklisted bool
+	if !c.cache.Get(ctx, blacklistKey(jti), &blacklisted) {
+		return false, false
+	}
+	return blacklisted, true
+}
+
+// SetBlacklisted records jti as blacklisted until expiresAt
+func (c *RedisBlacklistCache) SetBlacklisted(ctx context.Context, jti string, expiresAt time.Time) {
+	if ttl := time.Until(expiresAt); ttl > 0 {
+		c.cache.SetWithTTL(ctx, blacklistKey(jti), true, ttl)
+	}
+}
+
+// SetValid records jti as not blacklisted until the given time unless another verdict is cached
+func (c *RedisBlacklistCache) SetValid(ctx context.Context, jti string, until time.Time) {
+	if ttl := time.Until(until); ttl > 0 {
+		c.cache.AddWithTTL(ctx, blacklistKey(jti), false, ttl)
+	}
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"myapp/internal/pkg/database"
+)
+
+// DeadLetterRepository stores webhooks that exhausted their delivery attempts
+type DeadLetterRepository struct {
+	*database.MasterRepo[WebhookDeadLetter]
+}
+
+// NewDeadLetterRepository creates a new dead-letter repository using master database
+func NewDeadLetterRepository(dbManager *database.DatabaseManager) *DeadLetterRepository {
+	return &DeadLetterRepository{
+		MasterRepo: database.NewMasterRepo[WebhookDeadLetter](dbManager),
+	}
+}
+
+// SaveWebhookDeadLetter persists an undeliverable webhook
+func (r *DeadLetterRepository) SaveWebhookDeadLetter(ctx context.Context, letter *WebhookDeadLetter) error {
+	if err := r.Insert(ctx, letter); err != nil {
+		return fmt.Errorf("save webhook dead letter: %w", err)
+	}
+	return nil
+}
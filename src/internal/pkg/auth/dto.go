@@ -5,11 +5,11 @@ import (
 	"time"
 )
 
-// RegisterRequest represents user registration request
+// RegisterRequest represents user registration request.
+// It has no role: self-registered accounts are always users, admins are created with CreateAdmin.
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
-	Role     string `json:"role"`
 }
 
 // LoginRequest represents user login request
@@ -27,6 +27,70 @@ type LoginResponse struct {
 	User         UserResponse `json:"user"`
 }
 
+// VerifyEmailRequest represents an email verification request
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// ResendVerificationRequest represents a request for a new verification token
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ForgotPasswordRequest represents a request to start a password reset
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents a request to set a new password with a reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// ChangePasswordRequest represents a password change by an authenticated user
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8"`
+}
+
+// AuthorizeRequest represents an OAuth2 authorization code request protected with PKCE (RFC 7636)
+type AuthorizeRequest struct {
+	ResponseType        string `query:"response_type"`
+	RedirectURI         string `query:"redirect_uri"`
+	State               string `query:"state"`
+	CodeChallenge       string `query:"code_challenge"`
+	CodeChallengeMethod string `query:"code_challenge_method"`
+}
+
+// AuthorizeResponse carries a single-use authorization code
+type AuthorizeResponse struct {
+	Code      string `json:"code"`
+	State     string `json:"state,omitempty"`
+	ExpiresIn int64  `json:"expires_in"` // seconds until the code expires
+}
+
+// TokenRequest represents an OAuth2 token request, sent as a form or as JSON
+type TokenRequest struct {
+	GrantType    string `json:"grant_type" form:"grant_type"`
+	Code         string `json:"code" form:"code"`
+	CodeVerifier string `json:"code_verifier" form:"code_verifier"`
+	RedirectURI  string `json:"redirect_uri" form:"redirect_uri"`
+}
+
+// OAuthErrorResponse is an OAuth2 error response (RFC 6749 section 5.2)
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// RevokeTokensResponse reports how many tokens were revoked for a user
+type RevokeTokensResponse struct {
+	UserID               uint  `json:"user_id"`
+	RefreshTokensRevoked int64 `json:"refresh_tokens_revoked"`
+	AccessTokensRevoked  int64 `json:"access_tokens_revoked"`
+}
+
 // RefreshRequest represents refresh token request
 type RefreshRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
@@ -42,10 +106,12 @@ type RefreshResponse struct {
 
 // UserResponse represents user data response (without sensitive info)
 type UserResponse struct {
-	ID        string    `json:"id"` // UUIDv7
-	Email     string    `json:"email"`
-	Role      string    `json:"role"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            string    `json:"id"` // UUIDv7
+	Email         string    `json:"email"`
+	Role          string    `json:"role"`
+	Scopes        []string  `json:"scopes"`
+	EmailVerified bool      `json:"email_verified"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // ToUserResponse converts a User model to UserResponse DTO
@@ -58,9 +124,11 @@ func (u *User) ToUserResponse() UserResponse {
 		idStr = fmt.Sprintf("%d", u.ID)
 	}
 	return UserResponse{
-		ID:        idStr,
-		Email:     u.Email,
-		Role:      u.Role,
-		CreatedAt: u.CreatedAt,
+		ID:            idStr,
+		Email:         u.Email,
+		Role:          u.Role,
+		Scopes:        u.Scopes,
+		EmailVerified: u.EmailVerified,
+		CreatedAt:     u.CreatedAt,
 	}
 }
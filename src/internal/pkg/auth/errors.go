@@ -1,6 +1,9 @@
 package auth
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Custom error types for authentication operations
 
@@ -85,3 +88,59 @@ func (e *ErrUserNotFound) Error() string {
 	}
 	return fmt.Sprintf("user with id %d not found", e.ID)
 }
+
+// ErrPasswordUnchanged is returned when a new password is the same as the current one
+type ErrPasswordUnchanged struct{}
+
+func (e *ErrPasswordUnchanged) Error() string {
+	return "new password must differ from the current password"
+}
+
+// ErrEmailNotVerified is returned when login requires a verified email address
+type ErrEmailNotVerified struct {
+	Email string
+}
+
+func (e *ErrEmailNotVerified) Error() string {
+	return fmt.Sprintf("email %s has not been verified", e.Email)
+}
+
+// ErrEmailAlreadyVerified is returned when verifying a user whose email is already verified
+type ErrEmailAlreadyVerified struct {
+	UserID uint
+}
+
+func (e *ErrEmailAlreadyVerified) Error() string {
+	return fmt.Sprintf("email for user %d is already verified", e.UserID)
+}
+
+// ErrTokenReuseDetected is returned when an already rotated or revoked refresh token is presented again.
+// The token's whole family has been revoked by the time this is returned.
+type ErrTokenReuseDetected struct {
+	UserID uint
+}
+
+func (e *ErrTokenReuseDetected) Error() string {
+	return "refresh token reuse detected"
+}
+
+// ErrAccountLocked is returned when logging in to an account locked after too many failed attempts
+type ErrAccountLocked struct {
+	Until time.Time
+}
+
+func (e *ErrAccountLocked) Error() string {
+	return fmt.Sprintf("account is locked until %s", e.Until.UTC().Format(time.RFC3339))
+}
+
+// ErrInvalidAuthorizationRequest is returned when an OAuth2 authorization request is malformed
+type ErrInvalidAuthorizationRequest struct {
+	Message string
+}
+
+func (e *ErrInvalidAuthorizationRequest) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	return "invalid authorization request"
+}
@@ -1,13 +1,27 @@
 package auth
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+	"myapp/internal/pkg/validation"
 )
 
+// minPasswordLength is the shortest password accepted when one is set or changed
+const minPasswordLength = 8
+
+// passwordLengthMessage is returned when a new password is too short
+var passwordLengthMessage = fmt.Sprintf("password must be at least %d characters", minPasswordLength)
+
+// validPasswordLength reports whether password meets the minimum length
+func validPasswordLength(password string) bool {
+	return len(password) >= minPasswordLength
+}
+
 // Handler provides HTTP handlers for authentication endpoints
 type Handler struct {
 	service *Service
@@ -31,11 +45,8 @@ func (h *Handler) Register(c echo.Context) error {
 	}
 	
 	// Validate request
-	if req.Email == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "email is required")
-	}
-	if req.Password == "" || len(req.Password) < 8 {
-		return echo.NewHTTPError(http.StatusBadRequest, "password must be at least 8 characters")
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 	
 	// Register user
@@ -64,11 +75,8 @@ func (h *Handler) Login(c echo.Context) error {
 	}
 	
 	// Validate request
-	if req.Email == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "email is required")
-	}
-	if req.Password == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "password is required")
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 	
 	// Login user
@@ -77,6 +85,10 @@ func (h *Handler) Login(c echo.Context) error {
 		switch err.(type) {
 		case *ErrInvalidCredentials:
 			return echo.NewHTTPError(http.StatusUnauthorized, "invalid credentials")
+		case *ErrEmailNotVerified:
+			return echo.NewHTTPError(http.StatusForbidden, "email address has not been verified")
+		case *ErrAccountLocked:
+			return echo.NewHTTPError(http.StatusLocked, "account is temporarily locked due to too many failed login attempts")
 		default:
 			h.logger.Error("Login failed",
 				zap.String("email", req.Email),
@@ -97,8 +109,8 @@ func (h *Handler) RefreshToken(c echo.Context) error {
 	}
 	
 	// Validate request
-	if req.RefreshToken == "" {
-		return echo.NewHTTPError(http.StatusBadRequest, "refresh_token is required")
+	if err := validation.Struct(&req); err != nil {
+		return err
 	}
 	
 	// Refresh token
@@ -111,6 +123,8 @@ func (h *Handler) RefreshToken(c echo.Context) error {
 			return echo.NewHTTPError(http.StatusUnauthorized, "refresh token has expired")
 		case *ErrTokenRevoked:
 			return echo.NewHTTPError(http.StatusUnauthorized, "refresh token has been revoked")
+		case *ErrTokenReuseDetected:
+			return echo.NewHTTPError(http.StatusUnauthorized, "refresh token reuse detected, please log in again")
 		default:
 			h.logger.Error("Token refresh failed",
 				zap.Error(err))
@@ -121,6 +135,69 @@ func (h *Handler) RefreshToken(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+// VerifyEmail handles email verification
+// POST /api/auth/verify
+func (h *Handler) VerifyEmail(c echo.Context) error {
+	var req VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	
+	// Validate request
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+	
+	if err := h.service.VerifyEmail(c.Request().Context(), req.Token); err != nil {
+		switch err.(type) {
+		case *ErrTokenInvalid:
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid verification token")
+		case *ErrTokenExpired:
+			return echo.NewHTTPError(http.StatusBadRequest, "verification token has expired")
+		case *ErrEmailAlreadyVerified:
+			return echo.NewHTTPError(http.StatusConflict, "email already verified")
+		default:
+			h.logger.Error("Email verification failed",
+				zap.Error(err))
+			return echo.NewHTTPError(http.StatusInternalServerError, "email verification failed")
+		}
+	}
+	
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "email verified successfully",
+	})
+}
+
+// ResendVerification handles requests for a new verification token
+// POST /api/auth/resend-verification
+// The response is the same whether or not the account exists to avoid leaking registered emails
+func (h *Handler) ResendVerification(c echo.Context) error {
+	var req ResendVerificationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	
+	// Validate request
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+	
+	if err := h.service.ResendVerification(c.Request().Context(), req.Email); err != nil {
+		switch err.(type) {
+		case *ErrUserNotFound, *ErrEmailAlreadyVerified:
+		default:
+			h.logger.Error("Resend verification failed",
+				zap.String("email", req.Email),
+				zap.Error(err))
+			return echo.NewHTTPError(http.StatusInternalServerError, "resend verification failed")
+		}
+	}
+	
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "if the account exists and is unverified, a verification email has been sent",
+	})
+}
+
 // Logout handles user logout
 // POST /api/auth/logout
 func (h *Handler) Logout(c echo.Context) error {
@@ -153,6 +230,131 @@ func (h *Handler) Logout(c echo.Context) error {
 	})
 }
 
+// ForgotPassword handles starting a password reset
+// POST /api/auth/forgot-password
+// The response is the same whether or not the account exists to avoid leaking registered emails
+func (h *Handler) ForgotPassword(c echo.Context) error {
+	var req ForgotPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	
+	// Validate request
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+	
+	if _, err := h.service.RequestPasswordReset(c.Request().Context(), req.Email); err != nil {
+		h.logger.Error("Password reset request failed",
+			zap.String("email", req.Email),
+			zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "password reset request failed")
+	}
+	
+	return c.JSON(http.StatusAccepted, map[string]string{
+		"message": "if the account exists, a password reset email has been sent",
+	})
+}
+
+// ResetPassword handles setting a new password with a reset token
+// POST /api/auth/reset-password
+func (h *Handler) ResetPassword(c echo.Context) error {
+	var req ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	
+	// Validate request
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+	
+	if err := h.service.ResetPassword(c.Request().Context(), req.Token, req.Password); err != nil {
+		switch err.(type) {
+		case *ErrTokenInvalid:
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid password reset token")
+		case *ErrTokenExpired:
+			return echo.NewHTTPError(http.StatusBadRequest, "password reset token has expired")
+		default:
+			h.logger.Error("Password reset failed",
+				zap.Error(err))
+			return echo.NewHTTPError(http.StatusInternalServerError, "password reset failed")
+		}
+	}
+	
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "password reset successfully",
+	})
+}
+
+// ChangePassword handles changing the password of the authenticated user
+// POST /api/auth/change-password
+func (h *Handler) ChangePassword(c echo.Context) error {
+	userCtx, err := GetUserFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "user not found in context")
+	}
+	
+	var req ChangePasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+	
+	// Validate request
+	if err := validation.Struct(&req); err != nil {
+		return err
+	}
+	
+	if err := h.service.ChangePassword(c.Request().Context(), userCtx.UserID, req.CurrentPassword, req.NewPassword); err != nil {
+		switch err.(type) {
+		case *ErrInvalidCredentials:
+			return echo.NewHTTPError(http.StatusBadRequest, "current password is incorrect")
+		case *ErrPasswordUnchanged:
+			return echo.NewHTTPError(http.StatusBadRequest, "new password must differ from the current password")
+		default:
+			h.logger.Error("Change password failed",
+				zap.Uint("user_id", userCtx.UserID),
+				zap.Error(err))
+			return echo.NewHTTPError(http.StatusInternalServerError, "change password failed")
+		}
+	}
+	
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "password changed successfully",
+	})
+}
+
+// RevokeUserTokens handles force-logging-out a user (admin only)
+// POST /api/admin/users/:id/revoke-tokens
+func (h *Handler) RevokeUserTokens(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user id")
+	}
+	
+	resp, err := h.service.RevokeUserTokens(c.Request().Context(), uint(id))
+	if err != nil {
+		switch err.(type) {
+		case *ErrUserNotFound:
+			return echo.NewHTTPError(http.StatusNotFound, "user not found")
+		default:
+			h.logger.Error("Revoke user tokens failed",
+				zap.Uint64("user_id", id),
+				zap.Error(err))
+			return echo.NewHTTPError(http.StatusInternalServerError, "failed to revoke tokens")
+		}
+	}
+	
+	return c.JSON(http.StatusOK, resp)
+}
+
+// JWKS serves the public key set used to verify access tokens
+// GET /.well-known/jwks.json
+func (h *Handler) JWKS(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age=3600")
+	return c.JSON(http.StatusOK, h.service.PublicJWKS())
+}
+
 // GetCurrentUser returns the current authenticated user
 // GET /api/auth/me
 func (h *Handler) GetCurrentUser(c echo.Context) error {
@@ -173,3 +375,62 @@ func (h *Handler) GetCurrentUser(c echo.Context) error {
 	
 	return c.JSON(http.StatusOK, user.ToUserResponse())
 }
+
+// Authorize issues a PKCE-bound authorization code for the authenticated user
+// GET /api/auth/authorize
+func (h *Handler) Authorize(c echo.Context) error {
+	userCtx, err := GetUserFromContext(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, "user not found in context")
+	}
+	
+	var req AuthorizeRequest
+	if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+		return c.JSON(http.StatusBadRequest, OAuthErrorResponse{Error: "invalid_request", ErrorDescription: "invalid query parameters"})
+	}
+	
+	resp, err := h.service.Authorize(c.Request().Context(), userCtx.UserID, &req)
+	if err != nil {
+		if invalid, ok := err.(*ErrInvalidAuthorizationRequest); ok {
+			return c.JSON(http.StatusBadRequest, OAuthErrorResponse{Error: "invalid_request", ErrorDescription: invalid.Error()})
+		}
+		h.logger.Error("Authorize failed",
+			zap.Uint("user_id", userCtx.UserID),
+			zap.Error(err))
+		return echo.NewHTTPError(http.StatusInternalServerError, "failed to issue authorization code")
+	}
+	
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Token exchanges an authorization code and its PKCE code verifier for tokens
+// POST /api/auth/token
+func (h *Handler) Token(c echo.Context) error {
+	var req TokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, OAuthErrorResponse{Error: "invalid_request", ErrorDescription: "invalid request body"})
+	}
+	
+	if req.GrantType != grantTypeAuthorizationCode {
+		return c.JSON(http.StatusBadRequest, OAuthErrorResponse{Error: "unsupported_grant_type"})
+	}
+	if req.Code == "" || req.CodeVerifier == "" {
+		return c.JSON(http.StatusBadRequest, OAuthErrorResponse{Error: "invalid_request", ErrorDescription: "code and code_verifier are required"})
+	}
+	
+	c.Response().Header().Set(echo.HeaderCacheControl, "no-store")
+	response, err := h.service.ExchangeAuthorizationCode(c.Request().Context(), &req)
+	if err != nil {
+		switch err.(type) {
+		case *ErrTokenInvalid, *ErrTokenExpired, *ErrAccountLocked:
+			return c.JSON(http.StatusBadRequest, OAuthErrorResponse{Error: "invalid_grant", ErrorDescription: err.Error()})
+		default:
+			h.logger.Error("Authorization code exchange failed",
+				zap.Error(err))
+			return echo.NewHTTPError(http.StatusInternalServerError, "token exchange failed")
+		}
+	}
+	
+	return c.JSON(http.StatusOK, response)
+}
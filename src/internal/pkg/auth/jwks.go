@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+)
+
+// JWK is a JSON Web Key (RFC 7517) describing an RSA signing key
+type JWK struct {
+	Kty string `json:"kty"` // "RSA"
+	Use string `json:"use"` // "sig"
+	Alg string `json:"alg"` // "RS256"
+	Kid string `json:"kid"`
+	N   string `json:"n"` // base64url modulus
+	E   string `json:"e"` // base64url public exponent
+}
+
+// JWKS is a JSON Web Key Set as served from /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS returns the token verification keys as a JWK set: the primary key first,
+// then any verification-only keys kept for rotation
+func (tm *TokenManager) PublicJWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{rsaJWK(tm.publicKey, tm.keyID)}}
+	for _, kid := range tm.verificationKeyIDs() {
+		jwks.Keys = append(jwks.Keys, rsaJWK(tm.verificationKey(kid), kid))
+	}
+	return jwks
+}
+
+// KeyID returns the kid placed in access token headers
+func (tm *TokenManager) KeyID() string {
+	return tm.keyID
+}
+
+// rsaJWK encodes an RSA public key as a JWK
+func rsaJWK(key *rsa.PublicKey, kid string) JWK {
+	return JWK{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: "RS256",
+		Kid: kid,
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// keyThumbprint computes the RFC 7638 JWK thumbprint of an RSA public key, used as its kid.
+// The thumbprint only changes when the key does, so consumers can cache keys by kid.
+func keyThumbprint(key *rsa.PublicKey) string {
+	jwk := rsaJWK(key, "")
+	// RFC 7638 requires the required members in lexicographic order with no whitespace
+	canonical, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{E: jwk.E, Kty: jwk.Kty, N: jwk.N})
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -3,7 +3,9 @@ package keys
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"fmt"
 	"os"
@@ -147,6 +149,17 @@ func LoadPublicKeyPEM(filename string) (*rsa.PublicKey, error) {
 	return publicKey, nil
 }
 
+// PublicKeyFingerprint returns the SHA-256 fingerprint of the key's PKIX encoding, formatted as "SHA256:<base64>"
+func PublicKeyFingerprint(key *rsa.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return "", fmt.Errorf("marshal public key: %w", err)
+	}
+	
+	sum := sha256.Sum256(der)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
+
 // GenerateAndSaveKeyPair generates RSA key pair and saves both keys to files
 func GenerateAndSaveKeyPair(privateKeyPath, publicKeyPath string, bits int) error {
 	// Generate key pair
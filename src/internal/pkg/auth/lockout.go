@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"myapp/internal/pkg/config"
+	applog "myapp/internal/pkg/logger"
+)
+
+// LockoutReasonFailedLogins is the reason given when an account is locked after repeated failed logins
+const LockoutReasonFailedLogins = "too many failed login attempts"
+
+const (
+	// defaultLockoutDuration is used when the config leaves the lockout duration unset
+	defaultLockoutDuration = 15 * time.Minute
+	// lockoutNotifyTimeout bounds a lockout notification, including retries
+	lockoutNotifyTimeout = time.Minute
+)
+
+// LockoutEvent describes an account that has just been locked
+type LockoutEvent struct {
+	UserID         uint      `json:"user_id"`
+	Email          string    `json:"email"`
+	Reason         string    `json:"reason"`
+	FailedAttempts int       `json:"failed_attempts"`
+	LockedUntil    time.Time `json:"locked_until"`
+}
+
+// LockoutNotifier is told when an account gets locked, e.g. to alert a security team.
+// Notifications run in the background and never delay or fail the login request.
+type LockoutNotifier interface {
+	NotifyLockout(ctx context.Context, event LockoutEvent) error
+}
+
+// NewLockoutNotifier returns a webhook notifier when auth.lockout_webhook_url is set, otherwise a logging one.
+// Undeliverable lockout webhooks are recorded in webhook_dead_letters.
+func NewLockoutNotifier(cfg *config.Config, deadLetters *DeadLetterRepository, logger *zap.Logger) LockoutNotifier {
+	if cfg.Auth.LockoutWebhookURL != "" {
+		deliverer := NewWebhookDeliverer(&cfg.Auth, nil, deadLetters, logger)
+		return NewWebhookLockoutNotifier(cfg.Auth.LockoutWebhookURL, deliverer)
+	}
+	return &logLockoutNotifier{logger: logger}
+}
+
+// RegisterLockoutNotifier installs the provided notifier on the service
+func RegisterLockoutNotifier(service *Service, notifier LockoutNotifier) {
+	service.SetLockoutNotifier(notifier)
+}
+
+// logLockoutNotifier is the default notifier; it logs the lockout at warn level
+type logLockoutNotifier struct {
+	logger *zap.Logger
+}
+
+// NotifyLockout logs the lockout event
+func (n *logLockoutNotifier) NotifyLockout(ctx context.Context, event LockoutEvent) error {
+	applog.FromContextOr(ctx, n.logger).Warn("Account locked",
+		zap.Uint("user_id", event.UserID),
+		zap.String("email", event.Email),
+		zap.String("reason", event.Reason),
+		zap.Int("failed_attempts", event.FailedAttempts),
+		zap.Time("locked_until", event.LockedUntil))
+	return nil
+}
+
+// WebhookLockoutNotifier posts lockout events as JSON to a URL
+type WebhookLockoutNotifier struct {
+	url       string
+	deliverer *WebhookDeliverer
+}
+
+// NewWebhookLockoutNotifier creates a notifier that posts to url; a nil deliverer makes a single attempt
+func NewWebhookLockoutNotifier(url string, deliverer *WebhookDeliverer) *WebhookLockoutNotifier {
+	if deliverer == nil {
+		deliverer = NewWebhookDeliverer(&config.AuthConfig{}, nil, nil, nil)
+	}
+	return &WebhookLockoutNotifier{url: url, deliverer: deliverer}
+}
+
+// NotifyLockout posts the event to the webhook, retrying until it succeeds or is dead-lettered
+func (n *WebhookLockoutNotifier) NotifyLockout(ctx context.Context, event LockoutEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal lockout event: %w", err)
+	}
+
+	if err := n.deliverer.Deliver(ctx, n.url, body); err != nil {
+		return fmt.Errorf("send lockout webhook: %w", err)
+	}
+	return nil
+}
+
+// SetLockoutNotifier replaces how account lockouts are reported
+func (s *Service) SetLockoutNotifier(notifier LockoutNotifier) {
+	s.lockoutNotifier = notifier
+}
+
+// recordFailedLogin counts a failed login and locks the account once auth.max_failed_logins is reached.
+// Lockout is disabled when max_failed_logins is 0.
+func (s *Service) recordFailedLogin(ctx context.Context, user *User) {
+	maxAttempts := s.config.Auth.MaxFailedLogins
+	if maxAttempts <= 0 {
+		return
+	}
+
+	attempts, err := s.userRepo.IncrementFailedLogins(ctx, user.ID)
+	if err != nil {
+		s.log(ctx).Warn("Failed to record failed login",
+			zap.Uint("user_id", user.ID),
+			zap.Error(err))
+		return
+	}
+	if attempts < maxAttempts {
+		return
+	}
+
+	duration := s.config.Auth.LockoutDuration
+	if duration <= 0 {
+		duration = defaultLockoutDuration
+	}
+	until := time.Now().Add(duration)
+	if err := s.userRepo.LockUser(ctx, user.ID, until); err != nil {
+		s.log(ctx).Warn("Failed to lock user",
+			zap.Uint("user_id", user.ID),
+			zap.Error(err))
+		return
+	}
+
+	s.notifyLockout(ctx, LockoutEvent{
+		UserID:         user.ID,
+		Email:          user.Email,
+		Reason:         LockoutReasonFailedLogins,
+		FailedAttempts: attempts,
+		LockedUntil:    until,
+	})
+}
+
+// notifyLockout reports a lockout in the background so a slow notifier never blocks the login request
+func (s *Service) notifyLockout(ctx context.Context, event LockoutEvent) {
+	notifier := s.lockoutNotifier
+	if notifier == nil {
+		return
+	}
+
+	// Detach from the request so the notification outlives it
+	notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), lockoutNotifyTimeout)
+	go func() {
+		defer cancel()
+		if err := notifier.NotifyLockout(notifyCtx, event); err != nil {
+			s.log(notifyCtx).Error("Failed to send lockout notification",
+				zap.Uint("user_id", event.UserID),
+				zap.Error(err))
+		}
+	}()
+}
@@ -7,13 +7,26 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+	"myapp/internal/pkg/database"
+	applog "myapp/internal/pkg/logger"
 )
 
 // UserContext represents user information extracted from JWT
 type UserContext struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID uint     `json:"user_id"`
+	Email  string   `json:"email"`
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes"`
+}
+
+// HasScope reports whether the user was granted scope
+func (u *UserContext) HasScope(scope string) bool {
+	for _, s := range u.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // JWTMiddleware creates middleware that validates JWT tokens
@@ -25,41 +38,69 @@ func JWTMiddleware(service *Service, logger *zap.Logger) echo.MiddlewareFunc {
 			if token == "" {
 				return echo.NewHTTPError(http.StatusUnauthorized, "missing authorization token")
 			}
-			
-			// Validate token
-			claims, err := service.ValidateToken(c.Request().Context(), token)
-			if err != nil {
-				logger.Debug("Token validation failed",
-					zap.Error(err),
-					zap.String("path", c.Path()))
-				
-				switch err.(type) {
-				case *ErrTokenExpired:
-					return echo.NewHTTPError(http.StatusUnauthorized, "token has expired")
-				case *ErrTokenRevoked:
-					return echo.NewHTTPError(http.StatusUnauthorized, "token has been revoked")
-				case *ErrTokenInvalid:
-					return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
-				default:
-					return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
-				}
+			if err := authenticate(c, service, logger, token); err != nil {
+				return err
 			}
-			
-			// Create user context
-			userCtx := &UserContext{
-				UserID: claims.UserID,
-				Email:  claims.Email,
-				Role:   claims.Role,
+			return next(c)
+		}
+	}
+}
+
+// OptionalJWTMiddleware authenticates requests that carry a token, like JWTMiddleware, and lets requests
+// without one through anonymously, for public routes that show more to some users
+func OptionalJWTMiddleware(service *Service, logger *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := extractToken(c.Request())
+			if token == "" {
+				return next(c)
+			}
+			if err := authenticate(c, service, logger, token); err != nil {
+				return err
 			}
-			
-			// Store user context in Echo context
-			c.Set("user", userCtx)
-			
 			return next(c)
 		}
 	}
 }
 
+// authenticate validates token and stores the user it belongs to in the context
+func authenticate(c echo.Context, service *Service, logger *zap.Logger, token string) error {
+	// Validate token
+	claims, err := service.ValidateToken(c.Request().Context(), token)
+	if err != nil {
+		logger.Debug("Token validation failed",
+			zap.Error(err),
+			zap.String("path", c.Path()))
+		
+		switch err.(type) {
+		case *ErrTokenExpired:
+			return echo.NewHTTPError(http.StatusUnauthorized, "token has expired")
+		case *ErrTokenRevoked:
+			return echo.NewHTTPError(http.StatusUnauthorized, "token has been revoked")
+		case *ErrTokenInvalid:
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+		default:
+			return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
+		}
+	}
+	
+	// Create user context
+	userCtx := &UserContext{
+		UserID: claims.UserID,
+		Email:  claims.Email,
+		Role:   claims.Role,
+		Scopes: claims.Scopes,
+	}
+	
+	// Store user context in Echo context, and the user ID in the request context for logging and
+	// the repositories' created_by/updated_by columns
+	c.Set("user", userCtx)
+	ctx := applog.WithUserID(c.Request().Context(), userCtx.UserID)
+	c.SetRequest(c.Request().WithContext(database.WithUserID(ctx, userCtx.UserID)))
+	
+	return nil
+}
+
 // RequireRole creates middleware that requires specific roles
 func RequireRole(roles ...string) echo.MiddlewareFunc {
 	roleMap := make(map[string]bool)
@@ -83,6 +124,26 @@ func RequireRole(roles ...string) echo.MiddlewareFunc {
 	}
 }
 
+// RequireScope creates middleware that requires every one of the given scopes
+func RequireScope(scopes ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			user, err := GetUserFromContext(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized, "user not found in context")
+			}
+			
+			for _, scope := range scopes {
+				if !user.HasScope(scope) {
+					return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("insufficient permissions: required scopes: %v", scopes))
+				}
+			}
+			
+			return next(c)
+		}
+	}
+}
+
 // extractToken extracts the JWT token from Authorization header
 func extractToken(req *http.Request) string {
 	auth := req.Header.Get("Authorization")
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"myapp/internal/pkg/database"
+)
+
+// Migrations returns the versioned schema changes of the auth tables
+func Migrations() database.MigrationSet {
+	return database.MigrationSet{
+		Service: "auth",
+		Migrations: []database.Migration{
+			{
+				Version:     1,
+				Description: "create auth tables",
+				Up: func(tx *gorm.DB) error {
+					return tx.AutoMigrate(
+						&User{},
+						&RefreshToken{},
+						&TokenBlacklist{},
+						&IssuedAccessToken{},
+						&PasswordResetToken{},
+						&UsedNonce{},
+						&WebhookDeadLetter{},
+					)
+				},
+				Down: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable(
+						&WebhookDeadLetter{},
+						&UsedNonce{},
+						&PasswordResetToken{},
+						&IssuedAccessToken{},
+						&TokenBlacklist{},
+						&RefreshToken{},
+						&User{},
+					)
+				},
+			},
+			{
+				Version:     2,
+				Description: "create authorization_codes table",
+				Up: func(tx *gorm.DB) error {
+					return tx.AutoMigrate(&AuthorizationCode{})
+				},
+				Down: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable(&AuthorizationCode{})
+				},
+			},
+			{
+				Version:     3,
+				Description: "create access_sessions table",
+				Up: func(tx *gorm.DB) error {
+					return tx.AutoMigrate(&AccessSession{})
+				},
+				Down: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable(&AccessSession{})
+				},
+			},
+		},
+	}
+}
+
+// RunMigrations creates or updates the auth tables and their indexes:
+// users.email (unique), refresh_tokens.token (unique), refresh_tokens(user_id, revoked),
+// refresh_tokens.expires_at, token_blacklist.expires_at and issued_access_tokens(user_id, expires_at).
+// password_reset_tokens.token (unique), authorization_codes.code (unique), access_sessions(user_id, expires_at),
+// used_nonces.expires_at and webhook_dead_letters.created_at. token_blacklist.jti, issued_access_tokens.jti,
+// access_sessions.token and used_nonces.nonce are primary keys.
+// It migrates the current schema in one step; Migrations records the same changes as versions.
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(
+		&User{},
+		&RefreshToken{},
+		&TokenBlacklist{},
+		&IssuedAccessToken{},
+		&PasswordResetToken{},
+		&AuthorizationCode{},
+		&AccessSession{},
+		&UsedNonce{},
+		&WebhookDeadLetter{},
+	); err != nil {
+		return fmt.Errorf("migrate auth tables: %w", err)
+	}
+	return nil
+}
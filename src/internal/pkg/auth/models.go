@@ -7,11 +7,26 @@ import (
 // User represents a user in the system
 type User struct {
 	ID        uint      `gorm:"primarykey" json:"id"`
-	Email     string    `gorm:"uniqueIndex;not null" json:"email"`
+	Email     string    `gorm:"uniqueIndex:idx_users_email;not null" json:"email"`
 	Password  string    `gorm:"not null" json:"-"` // Never expose password in JSON
 	Role      string    `gorm:"not null;default:'user'" json:"role"`
+	Scopes    []string  `gorm:"serializer:json" json:"scopes"` // Fine-grained permissions, e.g. products:write
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// Email verification; the token is stored as a SHA-256 hash like refresh tokens
+	EmailVerified      bool       `gorm:"not null;default:false" json:"email_verified"`
+	VerificationToken  string     `gorm:"index" json:"-"`
+	VerificationExpiry *time.Time `json:"-"`
+
+	// Login lockout after repeated failed attempts
+	FailedLoginAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil         *time.Time `json:"-"`
+}
+
+// IsLocked reports whether the account is locked at the given time
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
 }
 
 // TableName specifies the table name for User model
@@ -22,11 +37,12 @@ func (User) TableName() string {
 // RefreshToken represents a refresh token for token rotation
 type RefreshToken struct {
 	ID        uint       `gorm:"primarykey"`
-	UserID    uint       `gorm:"index;not null"`
-	Token     string     `gorm:"uniqueIndex;not null"` // Hashed token value
+	UserID    uint       `gorm:"index;index:idx_refresh_tokens_user_revoked,priority:1;not null"`
+	Token     string     `gorm:"uniqueIndex:idx_refresh_tokens_token;not null"` // Hashed token value
+	FamilyID  string     `gorm:"index;size:32"`                                 // Shared by every token rotated from the same login
 	ExpiresAt time.Time  `gorm:"index;not null"`
 	CreatedAt time.Time  `gorm:"not null"`
-	Revoked   bool       `gorm:"default:false"`
+	Revoked   bool       `gorm:"default:false;index:idx_refresh_tokens_user_revoked,priority:2"`
 	RevokedAt *time.Time `gorm:"default:null"`
 }
 
@@ -37,7 +53,7 @@ func (RefreshToken) TableName() string {
 
 // TokenBlacklist represents a blacklisted access token (by JTI)
 type TokenBlacklist struct {
-	JTI       string    `gorm:"primarykey"` // JWT ID (JTI claim)
+	JTI       string    `gorm:"primarykey"` // JWT ID (JTI claim); the primary key doubles as the lookup index
 	ExpiresAt time.Time `gorm:"index"`
 	CreatedAt time.Time `gorm:"not null"`
 }
@@ -46,3 +62,96 @@ type TokenBlacklist struct {
 func (TokenBlacklist) TableName() string {
 	return "token_blacklist"
 }
+
+// UsedNonce records a replay-protection nonce until its request timestamp leaves the allowed window
+type UsedNonce struct {
+	Nonce     string    `gorm:"primarykey"` // The primary key makes a second use of the nonce fail atomically
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for UsedNonce model
+func (UsedNonce) TableName() string {
+	return "used_nonces"
+}
+
+// PasswordResetToken represents a single-use forgot-password token
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primarykey"`
+	UserID    uint       `gorm:"index;not null"`
+	Token     string     `gorm:"uniqueIndex:idx_password_reset_tokens_token;not null"` // Hashed token value
+	ExpiresAt time.Time  `gorm:"index;not null"`
+	UsedAt    *time.Time `gorm:"default:null"`
+	CreatedAt time.Time  `gorm:"not null"`
+}
+
+// TableName specifies the table name for PasswordResetToken model
+func (PasswordResetToken) TableName() string {
+	return "password_reset_tokens"
+}
+
+// AuthorizationCode is a single-use OAuth2 authorization code bound to a PKCE code challenge
+type AuthorizationCode struct {
+	ID                  uint       `gorm:"primarykey"`
+	UserID              uint       `gorm:"index;not null"`
+	Code                string     `gorm:"uniqueIndex:idx_authorization_codes_code;not null"` // Hashed code value
+	CodeChallenge       string     `gorm:"size:128;not null"`
+	CodeChallengeMethod string     `gorm:"size:10;not null"`
+	RedirectURI         string     `gorm:"size:2048"` // Must be repeated when the code is exchanged, if set
+	ExpiresAt           time.Time  `gorm:"index;not null"`
+	UsedAt              *time.Time `gorm:"default:null"`
+	CreatedAt           time.Time  `gorm:"not null"`
+}
+
+// TableName specifies the table name for AuthorizationCode model
+func (AuthorizationCode) TableName() string {
+	return "authorization_codes"
+}
+
+// AccessSession backs an opaque access token: the claims are kept server-side and the token itself
+// is only a random lookup key, so deleting the row revokes the token immediately
+type AccessSession struct {
+	Token     string    `gorm:"primarykey;size:64"` // Hashed token value
+	JTI       string    `gorm:"size:32;not null"`
+	UserID    uint      `gorm:"index;not null"`
+	Email     string    `gorm:"not null"`
+	Role      string    `gorm:"not null"`
+	Scopes    []string  `gorm:"serializer:json"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for AccessSession model
+func (AccessSession) TableName() string {
+	return "access_sessions"
+}
+
+// IssuedAccessToken records the JTI of each access token handed out so a user's
+// outstanding access tokens can be blacklisted on demand
+type IssuedAccessToken struct {
+	JTI       string    `gorm:"primarykey"`
+	UserID    uint      `gorm:"index;not null"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for IssuedAccessToken model
+func (IssuedAccessToken) TableName() string {
+	return "issued_access_tokens"
+}
+
+// WebhookDeadLetter records an outbound webhook that could not be delivered after every retry
+type WebhookDeadLetter struct {
+	ID         uint      `gorm:"primarykey"`
+	URL        string    `gorm:"not null"`
+	Payload    string    `gorm:"type:text;not null"`
+	Attempts   int       `gorm:"not null"`
+	LastStatus int       // HTTP status of the last attempt, 0 when no response was received
+	LastError  string    `gorm:"type:text"`
+	CreatedAt  time.Time `gorm:"index;not null"`
+}
+
+// TableName specifies the table name for WebhookDeadLetter model
+func (WebhookDeadLetter) TableName() string {
+	return "webhook_dead_letters"
+}
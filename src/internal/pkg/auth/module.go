@@ -7,34 +7,54 @@ import (
 	"github.com/labstack/echo/v4"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+	"myapp/internal/pkg/config"
 	"myapp/internal/pkg/database"
 )
 
-// Module exports auth dependency injection module
-var Module = fx.Options(
-	// Provide dependencies
+const (
+	// defaultCleanupInterval is used when auth.cleanup_interval is not set
+	defaultCleanupInterval = time.Hour
+	// cleanupTimeout bounds a single cleanup run
+	cleanupTimeout = 30 * time.Second
+)
+
+// ServiceModule provides the auth Service without routes or workers, for services that
+// only verify tokens with JWTMiddleware. It needs the cache module for blacklist lookups.
+var ServiceModule = fx.Options(
 	fx.Provide(NewTokenManager),
 	fx.Provide(NewRepository),
 	fx.Provide(NewTokenRepository),
 	fx.Provide(NewService),
+	fx.Provide(NewBlacklistCache),
+	fx.Invoke(RegisterBlacklistCache),
+)
+
+// Module exports auth dependency injection module
+var Module = fx.Options(
+	// Provide dependencies
+	ServiceModule,
+	fx.Provide(NewDeadLetterRepository),
 	fx.Provide(NewHandler),
+	fx.Provide(NewLockoutNotifier),
+	fx.Provide(NewTokenSender),
 	
 	// Invoke setup functions
 	fx.Invoke(RegisterMigrations),
+	fx.Invoke(RegisterLockoutNotifier),
+	fx.Invoke(RegisterTokenSender),
 	fx.Invoke(RegisterRoutesWithMiddleware),
 	fx.Invoke(StartCleanupWorker),
 )
 
-// RegisterMigrations registers database migrations for auth tables
-func RegisterMigrations(dbManager *database.DatabaseManager, logger *zap.Logger) {
-	db := dbManager.MasterDB
+// RegisterMigrations registers database migrations for auth tables.
+// Migrations only run when master_database.auto_migrate is enabled.
+func RegisterMigrations(cfg *config.Config, dbManager *database.DatabaseManager, logger *zap.Logger) {
+	if !cfg.MasterDatabase.AutoMigrate {
+		logger.Info("Skipping auth migrations, master_database.auto_migrate is disabled")
+		return
+	}
 	
-	// Auto-migrate auth tables
-	if err := db.AutoMigrate(
-		&User{},
-		&RefreshToken{},
-		&TokenBlacklist{},
-	); err != nil {
+	if err := database.MigrateUp(context.Background(), dbManager.MasterDB, Migrations()); err != nil {
 		logger.Error("Failed to migrate auth tables", zap.Error(err))
 		return
 	}
@@ -42,42 +62,50 @@ func RegisterMigrations(dbManager *database.DatabaseManager, logger *zap.Logger)
 	logger.Info("Auth tables migrated successfully")
 }
 
-// RegisterRoutesWithMiddleware registers auth routes with JWT middleware
+// RegisterRoutesWithMiddleware registers auth routes with JWT and replay protection middleware
 func RegisterRoutesWithMiddleware(
 	e *echo.Echo,
+	cfg *config.Config,
 	handler *Handler,
 	service *Service,
+	tokenRepo *TokenRepository,
 	logger *zap.Logger,
 ) {
 	middleware := JWTMiddleware(service, logger)
-	RegisterRoutes(e, handler, middleware)
+	replay := ReplayProtectionMiddleware(cfg, tokenRepo, logger)
+	RegisterRoutes(e, handler, middleware, replay)
 }
 
-// StartCleanupWorker starts a background worker to periodically clean up expired tokens
+// StartCleanupWorker starts a background worker that periodically cleans up expired tokens.
+// The interval comes from auth.cleanup_interval; the worker stops when the app stops.
 func StartCleanupWorker(
 	lc fx.Lifecycle,
+	cfg *config.Config,
 	tokenRepo *TokenRepository,
 	logger *zap.Logger,
 ) {
+	interval := cfg.Auth.CleanupInterval
+	if interval <= 0 {
+		interval = defaultCleanupInterval
+	}
+	
 	// Create a context that will be cancelled when the app stops
 	workerCtx, cancel := context.WithCancel(context.Background())
-
+	done := make(chan struct{})
+	
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			// Start cleanup worker in background
 			go func() {
-				ticker := time.NewTicker(1 * time.Hour) // Run cleanup every hour
+				defer close(done)
+				ticker := time.NewTicker(interval)
 				defer ticker.Stop()
 				
 				for {
 					select {
 					case <-ticker.C:
-						cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), 30*time.Second)
-						if err := tokenRepo.CleanupExpiredTokens(cleanupCtx); err != nil {
-							logger.Error("Failed to cleanup expired tokens", zap.Error(err))
-						} else {
-							logger.Debug("Cleaned up expired tokens")
-						}
+						cleanupCtx, cleanupCancel := context.WithTimeout(workerCtx, cleanupTimeout)
+						RunTokenCleanup(cleanupCtx, tokenRepo, logger)
 						cleanupCancel()
 					case <-workerCtx.Done():
 						logger.Info("Cleanup worker stopped")
@@ -86,13 +114,39 @@ func StartCleanupWorker(
 				}
 			}()
 			
-			logger.Info("Token cleanup worker started")
+			logger.Info("Token cleanup worker started", zap.Duration("interval", interval))
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
 			logger.Info("Stopping token cleanup worker")
 			cancel()
+			
+			// Wait for an in-flight cleanup to finish
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
 			return nil
 		},
 	})
 }
+
+// RunTokenCleanup deletes expired tokens once and logs how many rows were removed
+func RunTokenCleanup(ctx context.Context, tokenRepo *TokenRepository, logger *zap.Logger) (*CleanupResult, error) {
+	result, err := tokenRepo.CleanupExpiredTokens(ctx)
+	if err != nil {
+		logger.Error("Failed to cleanup expired tokens", zap.Error(err))
+		return nil, err
+	}
+	
+	logger.Info("Cleaned up expired tokens",
+		zap.Int64("deleted", result.Total()),
+		zap.Int64("blacklist", result.Blacklist),
+		zap.Int64("issued_access_tokens", result.IssuedAccessTokens),
+		zap.Int64("password_reset_tokens", result.PasswordResetTokens),
+		zap.Int64("authorization_codes", result.AuthorizationCodes),
+		zap.Int64("access_sessions", result.AccessSessions),
+		zap.Int64("refresh_tokens", result.RefreshTokens),
+		zap.Int64("used_nonces", result.UsedNonces))
+	return result, nil
+}
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"myapp/internal/pkg/config"
+)
+
+// argon2id parameters (RFC 9106 second recommended option)
+const (
+	argon2idPrefix  = "$argon2id$"
+	argon2idTime    = 3
+	argon2idMemory  = 64 * 1024 // KiB
+	argon2idThreads = 4
+	argon2idSaltLen = 16
+	argon2idKeyLen  = 32
+)
+
+// ErrPasswordMismatch is returned when a password does not match its hash
+var ErrPasswordMismatch = errors.New("password does not match")
+
+// PasswordHasher hashes and verifies passwords with one algorithm.
+// Hashes are self-describing: bcrypt hashes start with $2a$ or $2b$, argon2id hashes
+// use the PHC string format starting with $argon2id$.
+type PasswordHasher interface {
+	// Algorithm returns the config.PasswordAlgorithm* name of the algorithm
+	Algorithm() string
+	Hash(password string) (string, error)
+	Verify(hashedPassword, password string) error
+}
+
+// NewPasswordHasher returns the hasher for the configured password algorithm, defaulting to bcrypt
+func NewPasswordHasher(cfg *config.AuthConfig) PasswordHasher {
+	if cfg.PasswordAlgorithm == config.PasswordAlgorithmArgon2id {
+		return argon2idHasher{}
+	}
+	cost := cfg.BCryptCost
+	if cost <= 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return bcryptHasher{cost: cost}
+}
+
+// hasherForHash returns the hasher that produced hashedPassword, judged by its prefix
+func hasherForHash(hashedPassword string) PasswordHasher {
+	if strings.HasPrefix(hashedPassword, argon2idPrefix) {
+		return argon2idHasher{}
+	}
+	return bcryptHasher{cost: bcrypt.DefaultCost}
+}
+
+// PasswordNeedsRehash reports whether hashedPassword was produced by an algorithm other than the configured one
+func PasswordNeedsRehash(hashedPassword string, cfg *config.AuthConfig) bool {
+	return hasherForHash(hashedPassword).Algorithm() != NewPasswordHasher(cfg).Algorithm()
+}
+
+// bcryptHasher hashes passwords with bcrypt at a fixed cost
+type bcryptHasher struct {
+	cost int
+}
+
+func (h bcryptHasher) Algorithm() string {
+	return config.PasswordAlgorithmBcrypt
+}
+
+func (h bcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes), nil
+}
+
+func (h bcryptHasher) Verify(hashedPassword, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	return err
+}
+
+// argon2idHasher hashes passwords with argon2id, encoding the parameters and salt alongside the key
+type argon2idHasher struct{}
+
+func (h argon2idHasher) Algorithm() string {
+	return config.PasswordAlgorithmArgon2id
+}
+
+func (h argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt: %w", err)
+	}
+	key := argon2.IDKey([]byte(password), salt, argon2idTime, argon2idMemory, argon2idThreads, argon2idKeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		argon2idMemory, argon2idTime, argon2idThreads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify recomputes the key with the parameters stored in the hash, so hashes made with
+// older parameters keep verifying
+func (h argon2idHasher) Verify(hashedPassword, password string) error {
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 {
+		return fmt.Errorf("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return fmt.Errorf("unsupported argon2id version %q", parts[2])
+	}
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return fmt.Errorf("malformed argon2id key: %w", err)
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
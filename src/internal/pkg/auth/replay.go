@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"myapp/internal/pkg/config"
+)
+
+// Headers a client sends on routes guarded by ReplayProtectionMiddleware
+const (
+	HeaderReplayNonce     = "X-Request-Nonce"
+	HeaderReplayTimestamp = "X-Request-Timestamp" // Unix seconds
+	HeaderReplaySignature = "X-Request-Signature" // Hex HMAC-SHA256, see SignReplayNonce
+)
+
+// maxNonceLength caps the nonce stored per request
+const maxNonceLength = 128
+
+// SignReplayNonce returns the signature a client sends in X-Request-Signature:
+// the hex HMAC-SHA256 of "METHOD\nPATH\nTIMESTAMP\nNONCE" keyed with auth.replay_protection_secret
+func SignReplayNonce(secret, method, path string, timestamp int64, nonce string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + path + "\n" + strconv.FormatInt(timestamp, 10) + "\n" + nonce))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ReplayProtectionMiddleware rejects replayed requests on sensitive routes. The client signs a fresh nonce and
+// the current time; requests with a bad signature, a timestamp outside auth.replay_window or a nonce that was
+// already used are refused. It is a no-op when auth.replay_protection_secret is empty.
+func ReplayProtectionMiddleware(cfg *config.Config, tokenRepo *TokenRepository, logger *zap.Logger) echo.MiddlewareFunc {
+	secret := cfg.Auth.ReplayProtectionSecret
+	window := cfg.Auth.ReplayWindow
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if secret == "" {
+			return next
+		}
+		return func(c echo.Context) error {
+			req := c.Request()
+			nonce := req.Header.Get(HeaderReplayNonce)
+			signature := req.Header.Get(HeaderReplaySignature)
+			rawTimestamp := req.Header.Get(HeaderReplayTimestamp)
+			if nonce == "" || signature == "" || rawTimestamp == "" {
+				return echo.NewHTTPError(http.StatusBadRequest, "missing request nonce, timestamp or signature")
+			}
+			if len(nonce) > maxNonceLength {
+				return echo.NewHTTPError(http.StatusBadRequest, "request nonce is too long")
+			}
+			timestamp, err := strconv.ParseInt(rawTimestamp, 10, 64)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid request timestamp")
+			}
+
+			expected := SignReplayNonce(secret, req.Method, req.URL.Path, timestamp, nonce)
+			if !hmac.Equal([]byte(expected), []byte(signature)) {
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid request signature")
+			}
+
+			requestTime := time.Unix(timestamp, 0)
+			drift := time.Since(requestTime)
+			if drift > window || drift < -window {
+				return echo.NewHTTPError(http.StatusUnauthorized, "request timestamp outside allowed window")
+			}
+
+			// Keep the nonce until its timestamp could no longer pass the window check
+			fresh, err := tokenRepo.UseNonce(req.Context(), nonce, requestTime.Add(window))
+			if err != nil {
+				logger.Error("Failed to record request nonce", zap.Error(err))
+				return echo.NewHTTPError(http.StatusInternalServerError, "failed to verify request")
+			}
+			if !fresh {
+				logger.Warn("Replayed request rejected",
+					zap.String("path", req.URL.Path),
+					zap.String("remote_addr", req.RemoteAddr))
+				return echo.NewHTTPError(http.StatusUnauthorized, "request has already been used")
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"myapp/internal/pkg/database"
@@ -21,11 +24,12 @@ func NewRepository(dbManager *database.DatabaseManager) *Repository {
 	}
 }
 
-// GetByEmail retrieves a user by email address
+// GetByEmail retrieves a user by email address, ignoring case. Accounts created before emails were
+// normalized to lowercase may still be stored in mixed case.
 func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error) {
 	var user User
-	if err := r.GetDB().WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
+	if err := r.GetDB().WithContext(ctx).Where("LOWER(email) = ?", strings.ToLower(email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, &ErrUserNotFound{Email: email}
 		}
 		return nil, fmt.Errorf("get user by email: %w", err)
@@ -33,10 +37,10 @@ func (r *Repository) GetByEmail(ctx context.Context, email string) (*User, error
 	return &user, nil
 }
 
-// EmailExists checks if a user with the given email already exists
+// EmailExists checks if a user with the given email already exists, ignoring case like GetByEmail
 func (r *Repository) EmailExists(ctx context.Context, email string) (bool, error) {
 	var count int64
-	if err := r.GetDB().WithContext(ctx).Model(&User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+	if err := r.GetDB().WithContext(ctx).Model(&User{}).Where("LOWER(email) = ?", strings.ToLower(email)).Count(&count).Error; err != nil {
 		return false, fmt.Errorf("check email exists: %w", err)
 	}
 	return count > 0, nil
@@ -55,3 +59,98 @@ func (r *Repository) GetByID(ctx context.Context, id uint) (*User, error) {
 func (r *Repository) Create(ctx context.Context, user *User) error {
 	return r.MasterRepo.Insert(ctx, user)
 }
+
+// GetByVerificationToken retrieves a user by the hash of their email verification token
+func (r *Repository) GetByVerificationToken(ctx context.Context, tokenHash string) (*User, error) {
+	var user User
+	if err := r.GetDB().WithContext(ctx).Where("verification_token = ?", tokenHash).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &ErrTokenInvalid{Message: "invalid verification token"}
+		}
+		return nil, fmt.Errorf("get user by verification token: %w", err)
+	}
+	return &user, nil
+}
+
+// SetVerificationToken stores the hash and expiry of a new email verification token for a user
+func (r *Repository) SetVerificationToken(ctx context.Context, userID uint, tokenHash string, expiresAt time.Time) error {
+	if err := r.GetDB().WithContext(ctx).
+		Model(&User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"verification_token":  tokenHash,
+			"verification_expiry": expiresAt,
+		}).Error; err != nil {
+		return fmt.Errorf("set verification token: %w", err)
+	}
+	return nil
+}
+
+// UpdatePassword replaces the stored password hash of a user
+func (r *Repository) UpdatePassword(ctx context.Context, userID uint, passwordHash string) error {
+	if err := r.GetDB().WithContext(ctx).
+		Model(&User{}).
+		Where("id = ?", userID).
+		Update("password", passwordHash).Error; err != nil {
+		return fmt.Errorf("update password: %w", err)
+	}
+	return nil
+}
+
+// IncrementFailedLogins adds one to the user's consecutive failed login count and returns the new count
+func (r *Repository) IncrementFailedLogins(ctx context.Context, userID uint) (int, error) {
+	db := r.GetDB().WithContext(ctx)
+	if err := db.Model(&User{}).
+		Where("id = ?", userID).
+		Update("failed_login_attempts", gorm.Expr("failed_login_attempts + 1")).Error; err != nil {
+		return 0, fmt.Errorf("increment failed logins: %w", err)
+	}
+	
+	var attempts int
+	if err := db.Model(&User{}).
+		Where("id = ?", userID).
+		Pluck("failed_login_attempts", &attempts).Error; err != nil {
+		return 0, fmt.Errorf("get failed logins: %w", err)
+	}
+	return attempts, nil
+}
+
+// LockUser locks the user's account until the given time
+func (r *Repository) LockUser(ctx context.Context, userID uint, until time.Time) error {
+	if err := r.GetDB().WithContext(ctx).
+		Model(&User{}).
+		Where("id = ?", userID).
+		Update("locked_until", until).Error; err != nil {
+		return fmt.Errorf("lock user: %w", err)
+	}
+	return nil
+}
+
+// ResetFailedLogins clears the failed login count and any lock on the user's account
+func (r *Repository) ResetFailedLogins(ctx context.Context, userID uint) error {
+	if err := r.GetDB().WithContext(ctx).
+		Model(&User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		}).Error; err != nil {
+		return fmt.Errorf("reset failed logins: %w", err)
+	}
+	return nil
+}
+
+// MarkEmailVerified marks a user's email as verified and clears the verification token
+func (r *Repository) MarkEmailVerified(ctx context.Context, userID uint) error {
+	if err := r.GetDB().WithContext(ctx).
+		Model(&User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"email_verified":      true,
+			"verification_token":  "",
+			"verification_expiry": nil,
+		}).Error; err != nil {
+		return fmt.Errorf("mark email verified: %w", err)
+	}
+	return nil
+}
@@ -4,17 +4,32 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// RegisterRoutes registers authentication routes
-func RegisterRoutes(e *echo.Echo, handler *Handler, middleware echo.MiddlewareFunc) {
+// RegisterRoutes registers authentication routes.
+// replay guards the token and password endpoints against replayed requests.
+func RegisterRoutes(e *echo.Echo, handler *Handler, middleware, replay echo.MiddlewareFunc) {
+	// Public key discovery for services verifying tokens independently
+	e.GET("/.well-known/jwks.json", handler.JWKS)
+	
 	api := e.Group("/api")
 	auth := api.Group("/auth")
 	
 	// Public routes (no authentication required)
 	auth.POST("/register", handler.Register)
 	auth.POST("/login", handler.Login)
-	auth.POST("/refresh", handler.RefreshToken)
+	auth.POST("/refresh", handler.RefreshToken, replay)
+	auth.POST("/verify", handler.VerifyEmail)
+	auth.POST("/resend-verification", handler.ResendVerification)
+	auth.POST("/forgot-password", handler.ForgotPassword, replay)
+	auth.POST("/reset-password", handler.ResetPassword, replay)
+	auth.POST("/token", handler.Token, replay)
 	
 	// Protected routes (require authentication)
 	auth.POST("/logout", handler.Logout, middleware)
 	auth.GET("/me", handler.GetCurrentUser, middleware)
+	auth.GET("/authorize", handler.Authorize, middleware)
+	auth.POST("/change-password", handler.ChangePassword, middleware, replay)
+	
+	// Admin routes (require authentication + admin role)
+	admin := api.Group("/admin", middleware, RequireRole("admin"))
+	admin.POST("/users/:id/revoke-tokens", handler.RevokeUserTokens)
 }
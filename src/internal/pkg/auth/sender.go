@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	applog "myapp/internal/pkg/logger"
+)
+
+// VerificationSender delivers email verification tokens to users
+type VerificationSender interface {
+	SendVerification(ctx context.Context, user *User, token string) error
+}
+
+// PasswordResetSender delivers password reset tokens to users
+type PasswordResetSender interface {
+	SendPasswordReset(ctx context.Context, user *User, token string) error
+}
+
+// TokenSender delivers both verification and password reset tokens, e.g. by email.
+// Apps provide their own with fx.Decorate or fx.Replace; the default only logs.
+type TokenSender interface {
+	VerificationSender
+	PasswordResetSender
+}
+
+// NewTokenSender returns the default sender, which records that a token was issued without delivering it
+func NewTokenSender(logger *zap.Logger) TokenSender {
+	return &logSender{logger: logger}
+}
+
+// RegisterTokenSender installs the provided sender on the service
+func RegisterTokenSender(service *Service, sender TokenSender) {
+	service.SetVerificationSender(sender)
+	service.SetPasswordResetSender(sender)
+}
+
+// logSender is the default token sender. Tokens are credentials, so it logs only who a token was issued for.
+type logSender struct {
+	logger *zap.Logger
+}
+
+// SendVerification logs that a verification token was issued
+func (s *logSender) SendVerification(ctx context.Context, user *User, token string) error {
+	applog.FromContextOr(ctx, s.logger).Info("Email verification token issued; no sender is configured to deliver it",
+		zap.Uint("user_id", user.ID))
+	return nil
+}
+
+// SendPasswordReset logs that a password reset token was issued
+func (s *logSender) SendPasswordReset(ctx context.Context, user *User, token string) error {
+	applog.FromContextOr(ctx, s.logger).Info("Password reset token issued; no sender is configured to deliver it",
+		zap.Uint("user_id", user.ID))
+	return nil
+}
@@ -4,11 +4,23 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 	"myapp/internal/pkg/config"
+	applog "myapp/internal/pkg/logger"
+	"myapp/internal/pkg/normalize"
+)
+
+const (
+	// defaultVerificationTokenDuration is used when the config leaves the verification token lifetime unset
+	defaultVerificationTokenDuration = 24 * time.Hour
+	// defaultPasswordResetTokenDuration is used when the config leaves the password reset token lifetime unset
+	defaultPasswordResetTokenDuration = time.Hour
 )
 
 // Service provides authentication business logic
@@ -18,6 +30,11 @@ type Service struct {
 	tokenManager    *TokenManager
 	config          *config.Config
 	logger          *zap.Logger
+	norm            normalize.Policy
+	verifier        VerificationSender
+	resetSender     PasswordResetSender
+	lockoutNotifier LockoutNotifier
+	blacklist       BlacklistCache
 }
 
 // NewService creates a new auth service
@@ -29,16 +46,43 @@ func NewService(
 	logger *zap.Logger,
 ) *Service {
 	return &Service{
-		userRepo:     userRepo,
-		tokenRepo:    tokenRepo,
-		tokenManager: tokenManager,
-		config:       cfg,
-		logger:       logger,
+		userRepo:        userRepo,
+		tokenRepo:       tokenRepo,
+		tokenManager:    tokenManager,
+		config:          cfg,
+		logger:          logger,
+		norm:            normalize.NewPolicy(cfg.Normalization),
+		verifier:        &logSender{logger: logger},
+		resetSender:     &logSender{logger: logger},
+		lockoutNotifier: &logLockoutNotifier{logger: logger},
 	}
 }
 
+// log returns the request-scoped logger from ctx, carrying request, tenant and user IDs,
+// or the service logger when ctx was not seeded by the server
+func (s *Service) log(ctx context.Context) *zap.Logger {
+	return applog.FromContextOr(ctx, s.logger)
+}
+
+// SetVerificationSender replaces how verification tokens are delivered
+func (s *Service) SetVerificationSender(sender VerificationSender) {
+	s.verifier = sender
+}
+
+// SetPasswordResetSender replaces how password reset tokens are delivered
+func (s *Service) SetPasswordResetSender(sender PasswordResetSender) {
+	s.resetSender = sender
+}
+
+// SetBlacklistCache installs a cache in front of the token blacklist; nil checks the database on every validation
+func (s *Service) SetBlacklistCache(blacklist BlacklistCache) {
+	s.blacklist = blacklist
+}
+
 // Register creates a new user account
 func (s *Service) Register(ctx context.Context, req *RegisterRequest) (*User, error) {
+	req.Email = s.norm.Email(req.Email)
+
 	// Check if email already exists
 	exists, err := s.userRepo.EmailExists(ctx, req.Email)
 	if err != nil {
@@ -54,53 +98,151 @@ func (s *Service) Register(ctx context.Context, req *RegisterRequest) (*User, er
 		return nil, fmt.Errorf("hash password: %w", err)
 	}
 	
-	// Set default role if not provided
-	role := req.Role
-	if role == "" {
-		role = "user"
-	}
-	
-	// Create user
+	// Create user; the registration endpoint is public, so it never grants more than the user role
 	user := &User{
 		Email:    req.Email,
 		Password: hashedPassword,
-		Role:     role,
+		Role:     "user",
 	}
 	
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("create user: %w", err)
 	}
 	
-	s.logger.Info("User registered successfully",
+	s.log(ctx).Info("User registered successfully",
 		zap.String("email", user.Email),
 		zap.Uint("user_id", user.ID))
 	
+	// A delivery failure does not undo the registration; the user can request a new token
+	if err := s.sendVerification(ctx, user); err != nil {
+		s.log(ctx).Warn("Failed to send verification token",
+			zap.Uint("user_id", user.ID),
+			zap.Error(err))
+	}
+	
+	return user, nil
+}
+
+// CreateAdmin creates a user with the admin role for bootstrapping a deployment. The email counts as verified
+// since an operator created the account. It returns ErrEmailExists when the email is already registered.
+func (s *Service) CreateAdmin(ctx context.Context, email, password string) (*User, error) {
+	email = s.norm.Email(email)
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+	if !validPasswordLength(password) {
+		return nil, fmt.Errorf("%s", passwordLengthMessage)
+	}
+
+	exists, err := s.userRepo.EmailExists(ctx, email)
+	if err != nil {
+		return nil, fmt.Errorf("check email exists: %w", err)
+	}
+	if exists {
+		return nil, &ErrEmailExists{Email: email}
+	}
+
+	hashedPassword, err := HashPassword(password, &s.config.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("hash password: %w", err)
+	}
+
+	user := &User{
+		Email:         email,
+		Password:      hashedPassword,
+		Role:          "admin",
+		EmailVerified: true,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+
+	s.log(ctx).Info("Admin user created",
+		zap.String("email", user.Email),
+		zap.Uint("user_id", user.ID))
 	return user, nil
 }
 
 // Login authenticates a user and returns access + refresh tokens
 func (s *Service) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+	req.Email = s.norm.Email(req.Email)
+
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
-		s.logger.Warn("Login attempt with invalid email",
+		s.log(ctx).Warn("Login attempt with invalid email",
 			zap.String("email", req.Email),
 			zap.Error(err))
 		return nil, &ErrInvalidCredentials{}
 	}
 	
+	// Reject locked accounts before checking the password so guesses stop counting
+	if user.IsLocked(time.Now()) {
+		return nil, &ErrAccountLocked{Until: *user.LockedUntil}
+	}
+	
 	// Verify password
 	if err := VerifyPassword(user.Password, req.Password); err != nil {
-		s.logger.Warn("Login attempt with invalid password",
+		s.log(ctx).Warn("Login attempt with invalid password",
 			zap.String("email", req.Email),
 			zap.Uint("user_id", user.ID))
+		s.recordFailedLogin(ctx, user)
 		return nil, &ErrInvalidCredentials{}
 	}
 	
+	if PasswordNeedsRehash(user.Password, &s.config.Auth) {
+		s.upgradePasswordHash(ctx, user, req.Password)
+	}
+	
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		if err := s.userRepo.ResetFailedLogins(ctx, user.ID); err != nil {
+			s.log(ctx).Warn("Failed to reset failed logins",
+				zap.Uint("user_id", user.ID),
+				zap.Error(err))
+		}
+	}
+	
+	if s.config.Auth.RequireEmailVerification && !user.EmailVerified {
+		return nil, &ErrEmailNotVerified{Email: user.Email}
+	}
+	
+	resp, err := s.issueLoginTokens(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	
+	s.log(ctx).Info("User logged in successfully",
+		zap.String("email", user.Email),
+		zap.Uint("user_id", user.ID))
+	return resp, nil
+}
+
+// upgradePasswordHash rehashes a verified password with the configured algorithm.
+// The upgrade is best effort; the old hash keeps verifying until it succeeds.
+func (s *Service) upgradePasswordHash(ctx context.Context, user *User, password string) {
+	hashedPassword, err := HashPassword(password, &s.config.Auth)
+	if err == nil {
+		err = s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword)
+	}
+	if err != nil {
+		s.log(ctx).Warn("Failed to upgrade password hash",
+			zap.Uint("user_id", user.ID),
+			zap.Error(err))
+		return
+	}
+	
+	user.Password = hashedPassword
+	s.log(ctx).Info("Upgraded password hash",
+		zap.Uint("user_id", user.ID),
+		zap.String("algorithm", s.config.Auth.PasswordAlgorithm))
+}
+
+// issueLoginTokens issues an access token and a refresh token starting a new token family
+func (s *Service) issueLoginTokens(ctx context.Context, user *User) (*LoginResponse, error) {
 	// Generate Access Token (RS256, 15 min)
-	accessToken, err := s.tokenManager.GenerateAccessToken(user)
+	accessToken, err := s.issueAccessToken(ctx, user)
 	if err != nil {
-		return nil, fmt.Errorf("generate access token: %w", err)
+		return nil, err
 	}
 	
 	// Generate Refresh Token (random string, 7 days)
@@ -118,10 +260,6 @@ func (s *Service) Login(ctx context.Context, req *LoginRequest) (*LoginResponse,
 		return nil, fmt.Errorf("save refresh token: %w", err)
 	}
 	
-	s.logger.Info("User logged in successfully",
-		zap.String("email", user.Email),
-		zap.Uint("user_id", user.ID))
-	
 	return &LoginResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
@@ -138,8 +276,13 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*Refre
 	
 	// Get refresh token from database
 	storedToken, err := s.tokenRepo.GetRefreshToken(ctx, refreshTokenHash)
+	if _, ok := err.(*ErrRefreshTokenNotFound); ok {
+		return nil, s.detectTokenReuse(ctx, refreshTokenHash, err)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("get refresh token: %w", err)
+		// Returned as-is: the repository already wraps unexpected errors, and the handler
+		// switches on the typed expired error
+		return nil, err
 	}
 	
 	// Check if token is revoked
@@ -155,15 +298,15 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*Refre
 	
 	// Revoke old refresh token (token rotation)
 	if err := s.tokenRepo.RevokeRefreshToken(ctx, refreshTokenHash); err != nil {
-		s.logger.Warn("Failed to revoke old refresh token",
+		s.log(ctx).Warn("Failed to revoke old refresh token",
 			zap.Uint("user_id", user.ID),
 			zap.Error(err))
 	}
 	
 	// Generate new Access Token
-	newAccessToken, err := s.tokenManager.GenerateAccessToken(user)
+	newAccessToken, err := s.issueAccessToken(ctx, user)
 	if err != nil {
-		return nil, fmt.Errorf("generate access token: %w", err)
+		return nil, err
 	}
 	
 	// Generate new Refresh Token
@@ -176,11 +319,11 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*Refre
 	newRefreshTokenHash := hashToken(newRefreshToken)
 	expiresAt := time.Now().Add(s.tokenManager.GetRefreshTokenExpiration())
 	
-	if err := s.tokenRepo.SaveRefreshToken(ctx, user.ID, newRefreshTokenHash, expiresAt); err != nil {
+	if err := s.tokenRepo.SaveRefreshTokenInFamily(ctx, user.ID, newRefreshTokenHash, storedToken.FamilyID, expiresAt); err != nil {
 		return nil, fmt.Errorf("save refresh token: %w", err)
 	}
 	
-	s.logger.Info("Token refreshed successfully",
+	s.log(ctx).Info("Token refreshed successfully",
 		zap.Uint("user_id", user.ID))
 	
 	return &RefreshResponse{
@@ -191,8 +334,42 @@ func (s *Service) RefreshToken(ctx context.Context, refreshToken string) (*Refre
 	}, nil
 }
 
+// detectTokenReuse checks whether an unknown refresh token is one that was already rotated or revoked.
+// A replayed token means it may have been stolen, so every token in its family is revoked and
+// ErrTokenReuseDetected is returned; otherwise notFound is returned unchanged.
+func (s *Service) detectTokenReuse(ctx context.Context, refreshTokenHash string, notFound error) error {
+	revoked, err := s.tokenRepo.GetRevokedRefreshToken(ctx, refreshTokenHash)
+	if err != nil {
+		if _, ok := err.(*ErrRefreshTokenNotFound); ok {
+			return notFound
+		}
+		return err
+	}
+	
+	// Tokens issued before family tracking have no family, so fall back to the whole user
+	var revokeErr error
+	if revoked.FamilyID != "" {
+		_, revokeErr = s.tokenRepo.RevokeTokenFamily(ctx, revoked.FamilyID)
+	} else {
+		revokeErr = s.tokenRepo.RevokeAllUserTokens(ctx, revoked.UserID)
+	}
+	if revokeErr != nil {
+		return fmt.Errorf("revoke token family: %w", revokeErr)
+	}
+	
+	s.log(ctx).Warn("Refresh token reuse detected, token family revoked",
+		zap.Uint("user_id", revoked.UserID),
+		zap.String("family_id", revoked.FamilyID))
+	
+	return &ErrTokenReuseDetected{UserID: revoked.UserID}
+}
+
 // Logout revokes access token and all refresh tokens for a user
 func (s *Service) Logout(ctx context.Context, accessToken string) error {
+	if s.tokenManager.Opaque() {
+		return s.logoutOpaque(ctx, accessToken)
+	}
+	
 	// Validate and parse access token
 	token, err := s.tokenManager.ValidateAccessToken(accessToken)
 	if err != nil {
@@ -213,28 +390,104 @@ func (s *Service) Logout(ctx context.Context, accessToken string) error {
 	
 	// Add access token to blacklist
 	expiresAt := claims.ExpiresAt.Time
-	if err := s.tokenRepo.AddToBlacklist(ctx, jti, expiresAt); err != nil {
-		s.logger.Warn("Failed to add token to blacklist",
+	if err := s.blacklistToken(ctx, jti, expiresAt); err != nil {
+		s.log(ctx).Warn("Failed to add token to blacklist",
 			zap.String("jti", jti),
 			zap.Error(err))
 	}
 	
 	// Revoke all user's refresh tokens
 	if err := s.tokenRepo.RevokeAllUserTokens(ctx, claims.UserID); err != nil {
-		s.logger.Warn("Failed to revoke user refresh tokens",
+		s.log(ctx).Warn("Failed to revoke user refresh tokens",
 			zap.Uint("user_id", claims.UserID),
 			zap.Error(err))
 	}
 	
-	s.logger.Info("User logged out successfully",
+	s.log(ctx).Info("User logged out successfully",
 		zap.Uint("user_id", claims.UserID),
 		zap.String("jti", jti))
 	
 	return nil
 }
 
+// logoutOpaque deletes the session behind an opaque access token and revokes the user's refresh tokens
+func (s *Service) logoutOpaque(ctx context.Context, accessToken string) error {
+	tokenHash := hashToken(accessToken)
+	session, err := s.tokenRepo.GetAccessSession(ctx, tokenHash)
+	if err != nil {
+		return err
+	}
+	
+	if err := s.tokenRepo.DeleteAccessSession(ctx, tokenHash); err != nil {
+		return err
+	}
+	
+	// Revoke all user's refresh tokens
+	if err := s.tokenRepo.RevokeAllUserTokens(ctx, session.UserID); err != nil {
+		s.log(ctx).Warn("Failed to revoke user refresh tokens",
+			zap.Uint("user_id", session.UserID),
+			zap.Error(err))
+	}
+	
+	s.log(ctx).Info("User logged out successfully",
+		zap.Uint("user_id", session.UserID),
+		zap.String("jti", session.JTI))
+	
+	return nil
+}
+
+// RevokeUserTokens force-logs-out a user: all refresh tokens are revoked, every
+// known unexpired access token is blacklisted and every opaque access token session is deleted
+func (s *Service) RevokeUserTokens(ctx context.Context, userID uint) (*RevokeTokensResponse, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &ErrUserNotFound{ID: userID}
+		}
+		return nil, err
+	}
+	
+	refreshRevoked, err := s.tokenRepo.RevokeUserRefreshTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	
+	accessTokens, err := s.tokenRepo.GetActiveAccessTokens(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	
+	var accessRevoked int64
+	for _, token := range accessTokens {
+		if err := s.blacklistToken(ctx, token.JTI, token.ExpiresAt); err != nil {
+			return nil, err
+		}
+		accessRevoked++
+	}
+	
+	sessionsDeleted, err := s.tokenRepo.DeleteUserAccessSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	accessRevoked += sessionsDeleted
+	
+	s.log(ctx).Info("Revoked all user tokens",
+		zap.Uint("user_id", userID),
+		zap.Int64("refresh_tokens_revoked", refreshRevoked),
+		zap.Int64("access_tokens_revoked", accessRevoked))
+	
+	return &RevokeTokensResponse{
+		UserID:               userID,
+		RefreshTokensRevoked: refreshRevoked,
+		AccessTokensRevoked:  accessRevoked,
+	}, nil
+}
+
 // ValidateToken validates an access token and returns claims
 func (s *Service) ValidateToken(ctx context.Context, tokenString string) (*TokenClaims, error) {
+	if s.tokenManager.Opaque() {
+		return s.validateOpaqueToken(ctx, tokenString)
+	}
+	
 	// Validate token signature and expiration
 	token, err := s.tokenManager.ValidateAccessToken(tokenString)
 	if err != nil {
@@ -248,7 +501,7 @@ func (s *Service) ValidateToken(ctx context.Context, tokenString string) (*Token
 	}
 	
 	// Check if token is blacklisted
-	isBlacklisted, err := s.tokenRepo.IsBlacklisted(ctx, claims.ID)
+	isBlacklisted, err := s.isBlacklisted(ctx, claims.ID, claims.ExpiresAt.Time)
 	if err != nil {
 		return nil, fmt.Errorf("check blacklist: %w", err)
 	}
@@ -259,6 +512,69 @@ func (s *Service) ValidateToken(ctx context.Context, tokenString string) (*Token
 	return claims, nil
 }
 
+// isBlacklisted checks the blacklist cache and falls back to the database on a miss, caching what it finds.
+// A valid verdict is cached for at most auth.blacklist_cache_ttl so blacklistings by other instances show up.
+func (s *Service) isBlacklisted(ctx context.Context, jti string, expiresAt time.Time) (bool, error) {
+	if s.blacklist != nil {
+		if blacklisted, found := s.blacklist.Get(ctx, jti); found {
+			return blacklisted, nil
+		}
+	}
+	
+	blacklisted, err := s.tokenRepo.IsBlacklisted(ctx, jti)
+	if err != nil {
+		return false, err
+	}
+	
+	if s.blacklist != nil {
+		if blacklisted {
+			s.blacklist.SetBlacklisted(ctx, jti, expiresAt)
+		} else if ttl := s.config.Auth.BlacklistCacheTTL; ttl > 0 {
+			until := time.Now().Add(ttl)
+			if until.After(expiresAt) {
+				until = expiresAt
+			}
+			s.blacklist.SetValid(ctx, jti, until)
+		}
+	}
+	return blacklisted, nil
+}
+
+// blacklistToken adds a JTI to the blacklist and pushes it into the blacklist cache so it takes effect at once
+func (s *Service) blacklistToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	if s.blacklist != nil {
+		s.blacklist.SetBlacklisted(ctx, jti, expiresAt)
+	}
+	return s.tokenRepo.AddToBlacklist(ctx, jti, expiresAt)
+}
+
+// validateOpaqueToken looks up the session behind an opaque access token and returns its claims
+func (s *Service) validateOpaqueToken(ctx context.Context, tokenString string) (*TokenClaims, error) {
+	session, err := s.tokenRepo.GetAccessSession(ctx, hashToken(tokenString))
+	if err != nil {
+		return nil, err
+	}
+	
+	return &TokenClaims{
+		UserID:    session.UserID,
+		Email:     session.Email,
+		Role:      session.Role,
+		Scopes:    session.Scopes,
+		TokenType: TokenTypeAccess,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(session.ExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(session.CreatedAt),
+			Issuer:    s.config.Auth.Issuer,
+			ID:        session.JTI,
+		},
+	}, nil
+}
+
+// PublicJWKS returns the JWK set other services use to verify access tokens
+func (s *Service) PublicJWKS() JWKS {
+	return s.tokenManager.PublicJWKS()
+}
+
 // GetUserByID retrieves a user by ID
 func (s *Service) GetUserByID(ctx context.Context, id uint) (*User, error) {
 	user, err := s.userRepo.GetByID(ctx, id)
@@ -268,6 +584,217 @@ func (s *Service) GetUserByID(ctx context.Context, id uint) (*User, error) {
 	return user, nil
 }
 
+// issueAccessToken generates an access token and records its JTI so it can be revoked later.
+// Opaque tokens are only usable once their session is stored, so that write is not best effort.
+func (s *Service) issueAccessToken(ctx context.Context, user *User) (string, error) {
+	accessToken, claims, err := s.tokenManager.GenerateAccessTokenWithClaims(user)
+	if err != nil {
+		return "", fmt.Errorf("generate access token: %w", err)
+	}
+	
+	if s.tokenManager.Opaque() {
+		err := s.tokenRepo.SaveAccessSession(ctx, &AccessSession{
+			Token:     hashToken(accessToken),
+			JTI:       claims.ID,
+			UserID:    user.ID,
+			Email:     claims.Email,
+			Role:      claims.Role,
+			Scopes:    claims.Scopes,
+			ExpiresAt: claims.ExpiresAt.Time,
+			CreatedAt: claims.IssuedAt.Time,
+		})
+		if err != nil {
+			return "", err
+		}
+		return accessToken, nil
+	}
+	
+	// Tracking is best effort; an untracked token still expires on its own
+	if err := s.tokenRepo.RecordAccessToken(ctx, user.ID, claims.ID, claims.ExpiresAt.Time); err != nil {
+		s.log(ctx).Warn("Failed to record issued access token",
+			zap.Uint("user_id", user.ID),
+			zap.Error(err))
+	}
+	return accessToken, nil
+}
+
+// GenerateVerificationToken issues a new email verification token for a user, replacing any previous one.
+// The plain token is returned; only its hash is stored.
+func (s *Service) GenerateVerificationToken(ctx context.Context, userID uint) (string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user.EmailVerified {
+		return "", &ErrEmailAlreadyVerified{UserID: user.ID}
+	}
+	return s.issueVerificationToken(ctx, user)
+}
+
+// VerifyEmail marks the email of the user owning token as verified
+func (s *Service) VerifyEmail(ctx context.Context, token string) error {
+	user, err := s.userRepo.GetByVerificationToken(ctx, hashToken(token))
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return &ErrEmailAlreadyVerified{UserID: user.ID}
+	}
+	if user.VerificationExpiry == nil || time.Now().After(*user.VerificationExpiry) {
+		return &ErrTokenExpired{Message: "verification token has expired"}
+	}
+	
+	if err := s.userRepo.MarkEmailVerified(ctx, user.ID); err != nil {
+		return err
+	}
+	
+	s.log(ctx).Info("Email verified",
+		zap.String("email", user.Email),
+		zap.Uint("user_id", user.ID))
+	return nil
+}
+
+// ResendVerification issues and sends a new verification token to the user with the given email
+func (s *Service) ResendVerification(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, s.norm.Email(email))
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return &ErrEmailAlreadyVerified{UserID: user.ID}
+	}
+	return s.sendVerification(ctx, user)
+}
+
+// sendVerification issues a verification token for the user and hands it to the sender
+func (s *Service) sendVerification(ctx context.Context, user *User) error {
+	token, err := s.issueVerificationToken(ctx, user)
+	if err != nil {
+		return err
+	}
+	if err := s.verifier.SendVerification(ctx, user, token); err != nil {
+		return fmt.Errorf("send verification: %w", err)
+	}
+	return nil
+}
+
+// issueVerificationToken generates and stores a verification token for the user
+func (s *Service) issueVerificationToken(ctx context.Context, user *User) (string, error) {
+	token, err := s.tokenManager.GenerateRefreshToken()
+	if err != nil {
+		return "", fmt.Errorf("generate verification token: %w", err)
+	}
+	
+	duration := s.config.Auth.VerificationTokenDuration
+	if duration <= 0 {
+		duration = defaultVerificationTokenDuration
+	}
+	expiresAt := time.Now().Add(duration)
+	if err := s.userRepo.SetVerificationToken(ctx, user.ID, hashToken(token), expiresAt); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// RequestPasswordReset issues a single-use password reset token and sends it to the user.
+// Unknown emails are not reported as errors so callers cannot probe for registered accounts;
+// in that case the returned token is empty.
+func (s *Service) RequestPasswordReset(ctx context.Context, email string) (string, error) {
+	email = s.norm.Email(email)
+	
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		if _, ok := err.(*ErrUserNotFound); ok {
+			s.log(ctx).Info("Password reset requested for unknown email",
+				zap.String("email", email))
+			return "", nil
+		}
+		return "", err
+	}
+	
+	token, err := s.tokenManager.GenerateRefreshToken()
+	if err != nil {
+		return "", fmt.Errorf("generate password reset token: %w", err)
+	}
+	
+	duration := s.config.Auth.PasswordResetTokenDuration
+	if duration <= 0 {
+		duration = defaultPasswordResetTokenDuration
+	}
+	if err := s.tokenRepo.SavePasswordResetToken(ctx, user.ID, hashToken(token), time.Now().Add(duration)); err != nil {
+		return "", err
+	}
+	
+	if err := s.resetSender.SendPasswordReset(ctx, user, token); err != nil {
+		return "", fmt.Errorf("send password reset: %w", err)
+	}
+	
+	s.log(ctx).Info("Password reset requested",
+		zap.Uint("user_id", user.ID))
+	return token, nil
+}
+
+// ResetPassword sets a new password using a reset token and signs the user out of all sessions
+func (s *Service) ResetPassword(ctx context.Context, resetToken, newPassword string) error {
+	storedToken, err := s.tokenRepo.ConsumePasswordResetToken(ctx, hashToken(resetToken))
+	if err != nil {
+		return err
+	}
+	
+	hashedPassword, err := HashPassword(newPassword, &s.config.Auth)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	
+	if err := s.userRepo.UpdatePassword(ctx, storedToken.UserID, hashedPassword); err != nil {
+		return err
+	}
+	
+	// Existing sessions may belong to whoever knew the old password
+	if err := s.tokenRepo.RevokeAllUserTokens(ctx, storedToken.UserID); err != nil {
+		return err
+	}
+	
+	s.log(ctx).Info("Password reset completed",
+		zap.Uint("user_id", storedToken.UserID))
+	return nil
+}
+
+// ChangePassword replaces a user's password after checking the current one, then revokes
+// all refresh tokens so other sessions have to log in again
+func (s *Service) ChangePassword(ctx context.Context, userID uint, currentPassword, newPassword string) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	
+	if err := VerifyPassword(user.Password, currentPassword); err != nil {
+		s.log(ctx).Warn("Change password attempt with invalid current password",
+			zap.Uint("user_id", user.ID))
+		return &ErrInvalidCredentials{Message: "current password is incorrect"}
+	}
+	if VerifyPassword(user.Password, newPassword) == nil {
+		return &ErrPasswordUnchanged{}
+	}
+	
+	hashedPassword, err := HashPassword(newPassword, &s.config.Auth)
+	if err != nil {
+		return fmt.Errorf("hash password: %w", err)
+	}
+	
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+		return err
+	}
+	
+	if err := s.tokenRepo.RevokeAllUserTokens(ctx, user.ID); err != nil {
+		return err
+	}
+	
+	s.log(ctx).Info("Password changed",
+		zap.Uint("user_id", user.ID))
+	return nil
+}
+
 // hashToken hashes a token using SHA-256 for storage
 func hashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
@@ -0,0 +1,193 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/auth"
+)
+
+// authorizationCodeFixture serves the auth routes for a registered user
+type authorizationCodeFixture struct {
+	e           *echo.Echo
+	db          *gorm.DB
+	service     *auth.Service
+	accessToken string
+}
+
+// newAuthorizationCodeFixture registers the auth routes and logs a user in
+func newAuthorizationCodeFixture(t *testing.T) *authorizationCodeFixture {
+	service, db, cleanup := setupTestServiceWithDB(t)
+	t.Cleanup(cleanup)
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, &auth.RegisterRequest{Email: "pkce@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	login, err := service.Login(ctx, &auth.LoginRequest{Email: "pkce@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	e := echo.New()
+	noReplay := func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	auth.RegisterRoutes(e, auth.NewHandler(service, zap.NewNop()), auth.JWTMiddleware(service, zap.NewNop()), noReplay)
+	return &authorizationCodeFixture{e: e, db: db, service: service, accessToken: login.AccessToken}
+}
+
+// authorize requests an authorization code with the given query parameters
+func (f *authorizationCodeFixture) authorize(params url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/auth/authorize?"+params.Encode(), nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+f.accessToken)
+	rec := httptest.NewRecorder()
+	f.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// token posts a form-encoded token request
+func (f *authorizationCodeFixture) token(form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/token", strings.NewReader(form.Encode()))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	rec := httptest.NewRecorder()
+	f.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// issueCode runs the authorization step for verifier and returns the code
+func (f *authorizationCodeFixture) issueCode(t *testing.T, verifier, redirectURI string) string {
+	rec := f.authorize(url.Values{
+		"response_type":         {"code"},
+		"redirect_uri":          {redirectURI},
+		"state":                 {"xyz"},
+		"code_challenge":        {auth.GenerateCodeChallenge(verifier, "S256")},
+		"code_challenge_method": {"S256"},
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp auth.AuthorizeResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "xyz", resp.State)
+	assert.Equal(t, int64(60), resp.ExpiresIn)
+	require.NotEmpty(t, resp.Code)
+	return resp.Code
+}
+
+// oauthError decodes an OAuth2 error response
+func oauthError(t *testing.T, rec *httptest.ResponseRecorder) string {
+	var resp auth.OAuthErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp.Error
+}
+
+func TestAuthorizationCode_S256Flow(t *testing.T) {
+	f := newAuthorizationCodeFixture(t)
+	verifier, err := auth.GenerateCodeVerifier(64)
+	require.NoError(t, err)
+	const redirectURI = "https://client.example.com/callback"
+
+	code := f.issueCode(t, verifier, redirectURI)
+
+	rec := f.token(url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"code_verifier": {verifier},
+		"redirect_uri":  {redirectURI},
+	})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "no-store", rec.Header().Get(echo.HeaderCacheControl))
+
+	var tokens auth.LoginResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tokens))
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.NotEmpty(t, tokens.RefreshToken)
+	claims, err := f.service.ValidateToken(context.Background(), tokens.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "pkce@example.com", claims.Email)
+
+	t.Run("reused code is rejected", func(t *testing.T) {
+		rec := f.token(url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"code_verifier": {verifier},
+			"redirect_uri":  {redirectURI},
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid_grant", oauthError(t, rec))
+	})
+}
+
+func TestAuthorizationCode_Rejections(t *testing.T) {
+	f := newAuthorizationCodeFixture(t)
+	verifier, err := auth.GenerateCodeVerifier(64)
+	require.NoError(t, err)
+
+	t.Run("mismatched verifier burns the code", func(t *testing.T) {
+		code := f.issueCode(t, verifier, "")
+		other, err := auth.GenerateCodeVerifier(64)
+		require.NoError(t, err)
+
+		rec := f.token(url.Values{"grant_type": {"authorization_code"}, "code": {code}, "code_verifier": {other}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid_grant", oauthError(t, rec))
+
+		rec = f.token(url.Values{"grant_type": {"authorization_code"}, "code": {code}, "code_verifier": {verifier}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "a code is single-use even after a failed exchange")
+	})
+
+	t.Run("redirect uri must match", func(t *testing.T) {
+		code := f.issueCode(t, verifier, "https://client.example.com/callback")
+
+		rec := f.token(url.Values{
+			"grant_type":    {"authorization_code"},
+			"code":          {code},
+			"code_verifier": {verifier},
+			"redirect_uri":  {"https://evil.example.com/callback"},
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid_grant", oauthError(t, rec))
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		code := f.issueCode(t, verifier, "")
+		require.NoError(t, f.db.Model(&auth.AuthorizationCode{}).
+			Where("used_at IS NULL").
+			Update("expires_at", time.Now().Add(-time.Second)).Error)
+
+		rec := f.token(url.Values{"grant_type": {"authorization_code"}, "code": {code}, "code_verifier": {verifier}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid_grant", oauthError(t, rec))
+	})
+
+	t.Run("unsupported grant type", func(t *testing.T) {
+		rec := f.token(url.Values{"grant_type": {"password"}})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "unsupported_grant_type", oauthError(t, rec))
+	})
+
+	t.Run("plain challenge method is rejected", func(t *testing.T) {
+		rec := f.authorize(url.Values{
+			"response_type":         {"code"},
+			"code_challenge":        {verifier},
+			"code_challenge_method": {"plain"},
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "invalid_request", oauthError(t, rec))
+	})
+
+	t.Run("authorize requires authentication", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/auth/authorize?response_type=code", nil)
+		rec := httptest.NewRecorder()
+		f.e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
@@ -0,0 +1,207 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/cache"
+	"myapp/internal/pkg/config"
+)
+
+// countBlacklistQueries counts the reads of token_blacklist made through db
+func countBlacklistQueries(t testing.TB, db *gorm.DB) *int {
+	count := 0
+	err := db.Callback().Query().After("gorm:query").Register("test:count_blacklist", func(tx *gorm.DB) {
+		if tx.Statement.Table == "token_blacklist" {
+			count++
+		}
+	})
+	require.NoError(t, err)
+	return &count
+}
+
+// setupBlacklistCacheService creates a service with an in-memory blacklist cache and a logged-in user
+func setupBlacklistCacheService(t testing.TB, ttl time.Duration) (*auth.Service, *gorm.DB, *auth.LoginResponse) {
+	service, db, cleanup := setupTestServiceWithConfig(t, func(cfg *config.AuthConfig) {
+		cfg.BlacklistCacheTTL = ttl
+	})
+	t.Cleanup(cleanup)
+	service.SetBlacklistCache(auth.NewMemoryBlacklistCache(100))
+
+	ctx := context.Background()
+	_, err := service.Register(ctx, &auth.RegisterRequest{Email: "cached@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	login, err := service.Login(ctx, &auth.LoginRequest{Email: "cached@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	return service, db, login
+}
+
+func TestService_ValidateToken_BlacklistCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("database is queried only on a cache miss", func(t *testing.T) {
+		service, db, login := setupBlacklistCacheService(t, time.Minute)
+		queries := countBlacklistQueries(t, db)
+
+		for i := 0; i < 3; i++ {
+			_, err := service.ValidateToken(ctx, login.AccessToken)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, *queries)
+	})
+
+	t.Run("logout takes effect without a database read", func(t *testing.T) {
+		service, db, login := setupBlacklistCacheService(t, time.Minute)
+		_, err := service.ValidateToken(ctx, login.AccessToken)
+		require.NoError(t, err)
+		queries := countBlacklistQueries(t, db)
+
+		require.NoError(t, service.Logout(ctx, login.AccessToken))
+		for i := 0; i < 2; i++ {
+			_, err = service.ValidateToken(ctx, login.AccessToken)
+			assert.IsType(t, &auth.ErrTokenRevoked{}, err)
+		}
+		assert.Zero(t, *queries)
+	})
+
+	t.Run("miss falls back to the database", func(t *testing.T) {
+		service, db, login := setupBlacklistCacheService(t, time.Minute)
+
+		// Blacklisted elsewhere, e.g. by another instance, before this one ever saw the token
+		claims := parseClaims(t, login.AccessToken)
+		require.NoError(t, db.Create(&auth.TokenBlacklist{JTI: claims.ID, ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now()}).Error)
+
+		_, err := service.ValidateToken(ctx, login.AccessToken)
+		assert.IsType(t, &auth.ErrTokenRevoked{}, err)
+	})
+
+	t.Run("zero ttl caches only blacklistings", func(t *testing.T) {
+		service, db, login := setupBlacklistCacheService(t, 0)
+		queries := countBlacklistQueries(t, db)
+
+		for i := 0; i < 2; i++ {
+			_, err := service.ValidateToken(ctx, login.AccessToken)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, *queries)
+
+		// Blacklisted without going through the service; the next read finds it and caches it
+		claims := parseClaims(t, login.AccessToken)
+		require.NoError(t, db.Create(&auth.TokenBlacklist{JTI: claims.ID, ExpiresAt: time.Now().Add(time.Hour), CreatedAt: time.Now()}).Error)
+		for i := 0; i < 2; i++ {
+			_, err := service.ValidateToken(ctx, login.AccessToken)
+			assert.IsType(t, &auth.ErrTokenRevoked{}, err)
+		}
+		assert.Equal(t, 3, *queries)
+	})
+}
+
+// parseClaims extracts the claims of an access token without checking the blacklist
+func parseClaims(t testing.TB, accessToken string) *auth.TokenClaims {
+	claims := &auth.TokenClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(accessToken, claims)
+	require.NoError(t, err)
+	return claims
+}
+
+func TestMemoryBlacklistCache(t *testing.T) {
+	ctx := context.Background()
+	later := time.Now().Add(time.Hour)
+
+	t.Run("expired entries are misses", func(t *testing.T) {
+		c := auth.NewMemoryBlacklistCache(10)
+		c.SetBlacklisted(ctx, "expired", time.Now().Add(-time.Second))
+		c.SetValid(ctx, "short", time.Now().Add(10*time.Millisecond))
+
+		_, found := c.Get(ctx, "expired")
+		assert.False(t, found)
+		time.Sleep(20 * time.Millisecond)
+		_, found = c.Get(ctx, "short")
+		assert.False(t, found)
+	})
+
+	t.Run("valid verdict never replaces a blacklisting", func(t *testing.T) {
+		c := auth.NewMemoryBlacklistCache(10)
+		c.SetValid(ctx, "jti", later)
+		c.SetBlacklisted(ctx, "jti", later)
+		c.SetValid(ctx, "jti", later)
+
+		blacklisted, found := c.Get(ctx, "jti")
+		assert.True(t, found)
+		assert.True(t, blacklisted)
+	})
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		c := auth.NewMemoryBlacklistCache(2)
+		c.SetValid(ctx, "a", later)
+		c.SetValid(ctx, "b", later)
+		c.Get(ctx, "a")
+		c.SetValid(ctx, "c", later)
+
+		assert.Equal(t, 2, c.Len())
+		_, found := c.Get(ctx, "b")
+		assert.False(t, found)
+		_, found = c.Get(ctx, "a")
+		assert.True(t, found)
+	})
+}
+
+func TestRedisBlacklistCache(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	redisCache := cache.NewWithClient(redis.NewClient(&redis.Options{Addr: server.Addr()}), time.Minute, nil)
+	t.Cleanup(func() { redisCache.Close() })
+	c := auth.NewRedisBlacklistCache(redisCache)
+
+	c.SetBlacklisted(ctx, "revoked", time.Now().Add(time.Hour))
+	blacklisted, found := c.Get(ctx, "revoked")
+	assert.True(t, found)
+	assert.True(t, blacklisted)
+	assert.InDelta(t, time.Hour.Seconds(), server.TTL("auth:blacklist:revoked").Seconds(), 2, "expires with the token")
+
+	c.SetValid(ctx, "revoked", time.Now().Add(time.Minute))
+	blacklisted, _ = c.Get(ctx, "revoked")
+	assert.True(t, blacklisted, "valid verdict never replaces a blacklisting")
+
+	c.SetValid(ctx, "valid", time.Now().Add(time.Minute))
+	blacklisted, found = c.Get(ctx, "valid")
+	assert.True(t, found)
+	assert.False(t, blacklisted)
+
+	server.Close()
+	_, found = c.Get(ctx, "revoked")
+	assert.False(t, found, "an unreachable Redis is a miss")
+}
+
+func BenchmarkService_ValidateToken(b *testing.B) {
+	ctx := context.Background()
+	run := func(b *testing.B, service *auth.Service, accessToken string) {
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := service.ValidateToken(ctx, accessToken); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("database", func(b *testing.B) {
+		service, _, login := setupBlacklistCacheService(b, time.Minute)
+		service.SetBlacklistCache(nil)
+		run(b, service, login.AccessToken)
+	})
+
+	b.Run("memory cache", func(b *testing.B) {
+		service, _, login := setupBlacklistCacheService(b, time.Minute)
+		run(b, service, login.AccessToken)
+	})
+}
@@ -0,0 +1,98 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/validation"
+)
+
+func TestService_ChangePassword(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, &auth.RegisterRequest{Email: "changepw@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	t.Run("wrong current password", func(t *testing.T) {
+		err := service.ChangePassword(ctx, user.ID, "WrongPass123", "AnotherPass456")
+		assert.IsType(t, &auth.ErrInvalidCredentials{}, err)
+	})
+
+	t.Run("same as old rejected", func(t *testing.T) {
+		err := service.ChangePassword(ctx, user.ID, "SecurePass123", "SecurePass123")
+		assert.IsType(t, &auth.ErrPasswordUnchanged{}, err)
+	})
+
+	t.Run("successful change invalidates old sessions", func(t *testing.T) {
+		session, err := service.Login(ctx, &auth.LoginRequest{Email: "changepw@example.com", Password: "SecurePass123"})
+		require.NoError(t, err)
+
+		require.NoError(t, service.ChangePassword(ctx, user.ID, "SecurePass123", "AnotherPass456"))
+
+		_, err = service.RefreshToken(ctx, session.RefreshToken)
+		assert.Error(t, err, "old refresh token is revoked")
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "changepw@example.com", Password: "SecurePass123"})
+		assert.IsType(t, &auth.ErrInvalidCredentials{}, err)
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "changepw@example.com", Password: "AnotherPass456"})
+		assert.NoError(t, err)
+	})
+}
+
+func TestHandler_ChangePassword(t *testing.T) {
+	handler, service := setupTestHandler(t)
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, &auth.RegisterRequest{Email: "changepwhandler@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	changePassword := func(body auth.ChangePasswordRequest) error {
+		e := echo.New()
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/change-password", bytes.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set("user", &auth.UserContext{UserID: user.ID, Email: user.Email, Role: user.Role})
+		return handler.ChangePassword(c)
+	}
+
+	assertStatus := func(t *testing.T, err error, status int) {
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, status, httpErr.Code)
+	}
+
+	t.Run("new password too short", func(t *testing.T) {
+		err := changePassword(auth.ChangePasswordRequest{CurrentPassword: "SecurePass123", NewPassword: "short"})
+		var validationErr *validation.ValidationError
+		require.True(t, errors.As(err, &validationErr), "got %v", err)
+		assert.Equal(t, []validation.FieldError{
+			{Field: "new_password", Tag: "min", Message: "new_password must be at least 8 characters"},
+		}, validationErr.Fields)
+	})
+
+	t.Run("wrong current password", func(t *testing.T) {
+		err := changePassword(auth.ChangePasswordRequest{CurrentPassword: "WrongPass123", NewPassword: "AnotherPass456"})
+		assertStatus(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("valid change", func(t *testing.T) {
+		err := changePassword(auth.ChangePasswordRequest{CurrentPassword: "SecurePass123", NewPassword: "AnotherPass456"})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,82 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+	"myapp/internal/testsupport"
+)
+
+func TestStartCleanupWorker(t *testing.T) {
+	repo, db := setupTestTokenRepository(t)
+	ctx := context.Background()
+	user := testsupport.NewTestUser(t, db, "cleanup@example.com", "user")
+
+	expired := time.Now().Add(-time.Hour)
+	valid := time.Now().Add(time.Hour)
+	require.NoError(t, repo.SaveRefreshToken(ctx, user.ID, "expired_refresh", expired))
+	require.NoError(t, repo.SaveRefreshToken(ctx, user.ID, "valid_refresh", valid))
+	require.NoError(t, repo.AddToBlacklist(ctx, "expired_jti", expired))
+	require.NoError(t, repo.AddToBlacklist(ctx, "valid_jti", valid))
+	require.NoError(t, repo.RecordAccessToken(ctx, user.ID, "expired_issued", expired))
+	require.NoError(t, repo.RecordAccessToken(ctx, user.ID, "valid_issued", valid))
+	require.NoError(t, repo.SavePasswordResetToken(ctx, user.ID, "expired_reset", expired))
+	require.NoError(t, repo.SavePasswordResetToken(ctx, user.ID, "valid_reset", valid))
+
+	lc := fxtest.NewLifecycle(t)
+	cfg := &config.Config{Auth: config.AuthConfig{CleanupInterval: 10 * time.Millisecond}}
+	auth.StartCleanupWorker(lc, cfg, repo, zaptest.NewLogger(t))
+	lc.RequireStart()
+
+	count := func(model interface{}) int64 {
+		var n int64
+		require.NoError(t, db.Model(model).Count(&n).Error)
+		return n
+	}
+
+	// Every table ends up with only its valid row
+	require.Eventually(t, func() bool {
+		return count(&auth.RefreshToken{}) == 1 &&
+			count(&auth.TokenBlacklist{}) == 1 &&
+			count(&auth.IssuedAccessToken{}) == 1 &&
+			count(&auth.PasswordResetToken{}) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	lc.RequireStop()
+
+	var refresh auth.RefreshToken
+	require.NoError(t, db.First(&refresh).Error)
+	assert.Equal(t, "valid_refresh", refresh.Token)
+
+	var blacklisted auth.TokenBlacklist
+	require.NoError(t, db.First(&blacklisted).Error)
+	assert.Equal(t, "valid_jti", blacklisted.JTI)
+}
+
+func TestRunTokenCleanup(t *testing.T) {
+	repo, db := setupTestTokenRepository(t)
+	ctx := context.Background()
+	user := testsupport.NewTestUser(t, db, "cleanup-once@example.com", "user")
+
+	require.NoError(t, repo.SaveRefreshToken(ctx, user.ID, "expired_refresh", time.Now().Add(-time.Hour)))
+	require.NoError(t, repo.SaveRefreshToken(ctx, user.ID, "valid_refresh", time.Now().Add(time.Hour)))
+
+	result, err := auth.RunTokenCleanup(ctx, repo, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.RefreshTokens)
+	assert.Equal(t, int64(1), result.Total())
+
+	// A second run has nothing left to delete
+	result, err = auth.RunTokenCleanup(ctx, repo, zaptest.NewLogger(t))
+	require.NoError(t, err)
+	assert.Zero(t, result.Total())
+}
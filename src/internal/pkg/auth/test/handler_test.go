@@ -6,15 +6,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/validation"
 )
 
 // setupTestHandler creates a test handler with all dependencies
@@ -26,7 +30,7 @@ func setupTestHandler(t *testing.T) (*auth.Handler, *auth.Service) {
 }
 
 func TestHandler_Register(t *testing.T) {
-	handler, _ := setupTestHandler(t)
+	handler, service := setupTestHandler(t)
 
 	tests := []struct {
 		name           string
@@ -36,10 +40,9 @@ func TestHandler_Register(t *testing.T) {
 	}{
 		{
 			name: "valid registration",
-			requestBody: RegisterRequest{
+			requestBody: auth.RegisterRequest{
 				Email:    "register@example.com",
 				Password: "SecurePass123",
-				Role:     "user",
 			},
 			expectedStatus: http.StatusCreated,
 			checkResponse: func(rec *httptest.ResponseRecorder) {
@@ -51,14 +54,14 @@ func TestHandler_Register(t *testing.T) {
 		},
 		{
 			name: "missing email",
-			requestBody: RegisterRequest{
+			requestBody: auth.RegisterRequest{
 				Password: "SecurePass123",
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
 		{
 			name: "password too short",
-			requestBody: RegisterRequest{
+			requestBody: auth.RegisterRequest{
 				Email:    "shortpass@example.com",
 				Password: "short",
 			},
@@ -66,7 +69,7 @@ func TestHandler_Register(t *testing.T) {
 		},
 		{
 			name: "duplicate email",
-			requestBody: RegisterRequest{
+			requestBody: auth.RegisterRequest{
 				Email:    "duplicate@example.com",
 				Password: "SecurePass123",
 			},
@@ -78,11 +81,11 @@ func TestHandler_Register(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Register duplicate user first if needed
 			if tt.name == "duplicate email" {
-				dupReq := RegisterRequest{
+				dupReq := auth.RegisterRequest{
 					Email:    "duplicate@example.com",
 					Password: "SecurePass123",
 				}
-				_, err := handler.service.Register(context.Background(), &dupReq)
+				_, err := service.Register(context.Background(), &dupReq)
 				require.NoError(t, err)
 			}
 
@@ -113,6 +116,40 @@ func TestHandler_Register(t *testing.T) {
 	}
 }
 
+// TestHandler_Register_ValidationFields tests that each invalid field is reported as a separate entry
+func TestHandler_Register_ValidationFields(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(`{"email":"not-an-email","password":"short"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	err := handler.Register(e.NewContext(req, httptest.NewRecorder()))
+
+	var validationErr *validation.ValidationError
+	require.True(t, errors.As(err, &validationErr), "got %v", err)
+	assert.Equal(t, []validation.FieldError{
+		{Field: "email", Tag: "email", Message: "email must be a valid email address"},
+		{Field: "password", Tag: "min", Message: "password must be at least 8 characters"},
+	}, validationErr.Fields)
+}
+
+// TestHandler_Register_IgnoresRole tests that the public endpoint cannot be used to create an admin
+func TestHandler_Register_IgnoresRole(t *testing.T) {
+	handler, _ := setupTestHandler(t)
+
+	e := echo.New()
+	body := `{"email":"wannabe@example.com","password":"SecurePass123","role":"admin"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/register", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	require.NoError(t, handler.Register(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusCreated, rec.Code)
+
+	var response auth.UserResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, "user", response.Role)
+}
+
 func TestHandler_Login(t *testing.T) {
 	handler, service := setupTestHandler(t)
 	ctx := context.Background()
@@ -132,7 +169,7 @@ func TestHandler_Login(t *testing.T) {
 	}{
 		{
 			name: "valid login",
-			requestBody: LoginRequest{
+			requestBody: auth.LoginRequest{
 				Email:    "loginhandler@example.com",
 				Password: "SecurePass123",
 			},
@@ -140,7 +177,7 @@ func TestHandler_Login(t *testing.T) {
 		},
 		{
 			name: "invalid email",
-			requestBody: LoginRequest{
+			requestBody: auth.LoginRequest{
 				Email:    "wrong@example.com",
 				Password: "SecurePass123",
 			},
@@ -148,7 +185,7 @@ func TestHandler_Login(t *testing.T) {
 		},
 		{
 			name: "invalid password",
-			requestBody: LoginRequest{
+			requestBody: auth.LoginRequest{
 				Email:    "loginhandler@example.com",
 				Password: "WrongPassword",
 			},
@@ -156,7 +193,7 @@ func TestHandler_Login(t *testing.T) {
 		},
 		{
 			name: "missing email",
-			requestBody: LoginRequest{
+			requestBody: auth.LoginRequest{
 				Password: "SecurePass123",
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -260,6 +297,45 @@ func TestHandler_RefreshToken(t *testing.T) {
 	})
 }
 
+// TestHandler_RefreshToken_Expired tests that an expired refresh token maps to 401, which needs
+// the service to return the repository's typed error unwrapped for the handler's type switch
+func TestHandler_RefreshToken_Expired(t *testing.T) {
+	service, db, cleanup := setupTestServiceWithDB(t)
+	defer cleanup()
+	handler := auth.NewHandler(service, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, &auth.RegisterRequest{
+		Email:    "expiredrefresh@example.com",
+		Password: "SecurePass123",
+	})
+	require.NoError(t, err)
+	loginResponse, err := service.Login(ctx, &auth.LoginRequest{
+		Email:    "expiredrefresh@example.com",
+		Password: "SecurePass123",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Model(&auth.RefreshToken{}).Where("1 = 1").
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	body, err := json.Marshal(auth.RefreshRequest{RefreshToken: loginResponse.RefreshToken})
+	require.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err = handler.RefreshToken(c)
+	require.Error(t, err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+	assert.Equal(t, "refresh token has expired", httpErr.Message)
+}
+
 func TestHandler_Logout(t *testing.T) {
 	handler, service := setupTestHandler(t)
 	ctx := context.Background()
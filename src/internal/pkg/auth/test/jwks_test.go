@@ -0,0 +1,100 @@
+package auth_test
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/auth/keys"
+	"myapp/internal/pkg/config"
+)
+
+// publicKeyFromJWK rebuilds an RSA public key from its JWK encoding
+func publicKeyFromJWK(t *testing.T, jwk auth.JWK) *rsa.PublicKey {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	require.NoError(t, err)
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}
+}
+
+func TestTokenManager_PublicJWKS(t *testing.T) {
+	tempDir := t.TempDir()
+	privateKeyPath := filepath.Join(tempDir, "private.pem")
+	publicKeyPath := filepath.Join(tempDir, "public.pem")
+	require.NoError(t, keys.GenerateAndSaveKeyPair(privateKeyPath, publicKeyPath, 2048))
+
+	tm, err := auth.NewTokenManager(&config.AuthConfig{
+		AccessTokenDuration: 15 * time.Minute,
+		RSAPrivateKeyPath:   privateKeyPath,
+		RSAPublicKeyPath:    publicKeyPath,
+		Issuer:              "test-issuer",
+	})
+	require.NoError(t, err)
+
+	// Round-trip through JSON as a consumer would see it
+	raw, err := json.Marshal(tm.PublicJWKS())
+	require.NoError(t, err)
+	var jwks auth.JWKS
+	require.NoError(t, json.Unmarshal(raw, &jwks))
+	require.Len(t, jwks.Keys, 1)
+
+	jwk := jwks.Keys[0]
+	assert.Equal(t, "RSA", jwk.Kty)
+	assert.Equal(t, "sig", jwk.Use)
+	assert.Equal(t, "RS256", jwk.Alg)
+	assert.NotEmpty(t, jwk.Kid)
+	assert.Equal(t, tm.KeyID(), jwk.Kid)
+
+	t.Run("reconstructed key matches generated key", func(t *testing.T) {
+		expected, err := keys.LoadPublicKeyPEM(publicKeyPath)
+		require.NoError(t, err)
+		assert.True(t, expected.Equal(publicKeyFromJWK(t, jwk)))
+	})
+
+	t.Run("access token kid matches and verifies with JWK", func(t *testing.T) {
+		tokenString, err := tm.GenerateAccessToken(&auth.User{ID: 1, Email: "jwks@example.com", Role: "user"})
+		require.NoError(t, err)
+
+		token, err := jwt.ParseWithClaims(tokenString, &auth.TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+			assert.Equal(t, jwk.Kid, token.Header["kid"])
+			return publicKeyFromJWK(t, jwk), nil
+		}, jwt.WithValidMethods([]string{"RS256"}))
+		require.NoError(t, err)
+		assert.True(t, token.Valid)
+	})
+
+	t.Run("kid is stable for the same key", func(t *testing.T) {
+		again, err := auth.NewTokenManager(&config.AuthConfig{
+			RSAPrivateKeyPath: privateKeyPath,
+			RSAPublicKeyPath:  publicKeyPath,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, tm.KeyID(), again.KeyID())
+	})
+
+	t.Run("kid differs for a different key", func(t *testing.T) {
+		otherDir := t.TempDir()
+		otherPrivate := filepath.Join(otherDir, "private.pem")
+		otherPublic := filepath.Join(otherDir, "public.pem")
+		require.NoError(t, keys.GenerateAndSaveKeyPair(otherPrivate, otherPublic, 2048))
+
+		other, err := auth.NewTokenManager(&config.AuthConfig{
+			RSAPrivateKeyPath: otherPrivate,
+			RSAPublicKeyPath:  otherPublic,
+		})
+		require.NoError(t, err)
+		assert.NotEqual(t, tm.KeyID(), other.KeyID())
+	})
+}
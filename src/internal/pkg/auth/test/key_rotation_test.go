@@ -0,0 +1,96 @@
+package auth_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/auth/keys"
+	"myapp/internal/pkg/config"
+)
+
+// generateKeyPair writes a fresh key pair to a temp dir and returns the private and public key paths
+func generateKeyPair(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	privateKeyPath := filepath.Join(dir, "private.pem")
+	publicKeyPath := filepath.Join(dir, "public.pem")
+	require.NoError(t, keys.GenerateAndSaveKeyPair(privateKeyPath, publicKeyPath, 2048))
+	return privateKeyPath, publicKeyPath
+}
+
+func TestTokenManager_KeyRotation(t *testing.T) {
+	oldPrivate, oldPublic := generateKeyPair(t)
+	newPrivate, newPublic := generateKeyPair(t)
+	user := &auth.User{ID: 1, Email: "rotate@example.com", Role: "user"}
+
+	oldTM, err := auth.NewTokenManager(&config.AuthConfig{
+		AccessTokenDuration: 15 * time.Minute,
+		RSAPrivateKeyPath:   oldPrivate,
+		RSAPublicKeyPath:    oldPublic,
+		Issuer:              "test-issuer",
+	})
+	require.NoError(t, err)
+
+	oldToken, err := oldTM.GenerateAccessToken(user)
+	require.NoError(t, err)
+
+	newConfig := &config.AuthConfig{
+		AccessTokenDuration: 15 * time.Minute,
+		RSAPrivateKeyPath:   newPrivate,
+		RSAPublicKeyPath:    newPublic,
+		Issuer:              "test-issuer",
+	}
+
+	t.Run("old token rejected without the old key", func(t *testing.T) {
+		newTM, err := auth.NewTokenManager(newConfig)
+		require.NoError(t, err)
+
+		_, err = newTM.ValidateAccessToken(oldToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("old token verifies after AddVerificationKey", func(t *testing.T) {
+		newTM, err := auth.NewTokenManager(newConfig)
+		require.NoError(t, err)
+
+		oldKey, err := keys.LoadPublicKeyPEM(oldPublic)
+		require.NoError(t, err)
+		newTM.AddVerificationKey(oldTM.KeyID(), oldKey)
+
+		_, err = newTM.ValidateAccessToken(oldToken)
+		assert.NoError(t, err)
+
+		// New tokens are signed with the new primary key
+		newToken, err := newTM.GenerateAccessToken(user)
+		require.NoError(t, err)
+		_, err = newTM.ValidateAccessToken(newToken)
+		assert.NoError(t, err)
+		_, err = oldTM.ValidateAccessToken(newToken)
+		assert.Error(t, err, "the retired key cannot verify tokens from the new primary")
+
+		jwks := newTM.PublicJWKS()
+		require.Len(t, jwks.Keys, 2)
+		assert.Equal(t, newTM.KeyID(), jwks.Keys[0].Kid)
+		assert.Equal(t, oldTM.KeyID(), jwks.Keys[1].Kid)
+	})
+
+	t.Run("old token verifies with configured verification key paths", func(t *testing.T) {
+		cfg := *newConfig
+		cfg.VerificationKeyPaths = []string{oldPublic}
+		newTM, err := auth.NewTokenManager(&cfg)
+		require.NoError(t, err)
+
+		_, err = newTM.ValidateAccessToken(oldToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("invalid verification key path", func(t *testing.T) {
+		cfg := *newConfig
+		cfg.VerificationKeyPaths = []string{"/nonexistent/public.pem"}
+		_, err := auth.NewTokenManager(&cfg)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,146 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+)
+
+// spyLockoutNotifier forwards every lockout event to a channel
+type spyLockoutNotifier struct {
+	events chan auth.LockoutEvent
+}
+
+func (n *spyLockoutNotifier) NotifyLockout(ctx context.Context, event auth.LockoutEvent) error {
+	n.events <- event
+	return nil
+}
+
+func TestService_Login_Lockout(t *testing.T) {
+	service, db, cleanup := setupTestServiceWithConfig(t, func(cfg *config.AuthConfig) {
+		cfg.MaxFailedLogins = 3
+		cfg.LockoutDuration = time.Hour
+	})
+	defer cleanup()
+	ctx := context.Background()
+
+	spy := &spyLockoutNotifier{events: make(chan auth.LockoutEvent, 1)}
+	service.SetLockoutNotifier(spy)
+
+	user, err := service.Register(ctx, &auth.RegisterRequest{Email: "locked@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	wrong := &auth.LoginRequest{Email: "locked@example.com", Password: "WrongPass123"}
+	right := &auth.LoginRequest{Email: "locked@example.com", Password: "SecurePass123"}
+
+	t.Run("failed attempts below the limit", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := service.Login(ctx, wrong)
+			assert.IsType(t, &auth.ErrInvalidCredentials{}, err)
+		}
+		select {
+		case event := <-spy.events:
+			t.Fatalf("unexpected lockout notification: %+v", event)
+		default:
+		}
+	})
+
+	t.Run("reaching the limit locks and notifies", func(t *testing.T) {
+		before := time.Now()
+		_, err := service.Login(ctx, wrong)
+		assert.IsType(t, &auth.ErrInvalidCredentials{}, err)
+
+		select {
+		case event := <-spy.events:
+			assert.Equal(t, user.ID, event.UserID)
+			assert.Equal(t, "locked@example.com", event.Email)
+			assert.Equal(t, auth.LockoutReasonFailedLogins, event.Reason)
+			assert.Equal(t, 3, event.FailedAttempts)
+			assert.WithinDuration(t, before.Add(time.Hour), event.LockedUntil, time.Minute)
+		case <-time.After(2 * time.Second):
+			t.Fatal("lockout notifier was not called")
+		}
+	})
+
+	t.Run("correct password rejected while locked", func(t *testing.T) {
+		_, err := service.Login(ctx, right)
+		locked, ok := err.(*auth.ErrAccountLocked)
+		require.True(t, ok, "expected ErrAccountLocked, got %T", err)
+		assert.True(t, locked.Until.After(time.Now()))
+	})
+
+	t.Run("login succeeds after the lock expires and resets the count", func(t *testing.T) {
+		require.NoError(t, db.Model(&auth.User{}).Where("id = ?", user.ID).
+			Update("locked_until", time.Now().Add(-time.Minute)).Error)
+
+		_, err := service.Login(ctx, right)
+		require.NoError(t, err)
+
+		var stored auth.User
+		require.NoError(t, db.First(&stored, user.ID).Error)
+		assert.Zero(t, stored.FailedLoginAttempts)
+		assert.Nil(t, stored.LockedUntil)
+	})
+}
+
+func TestService_Login_LockoutDisabled(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	spy := &spyLockoutNotifier{events: make(chan auth.LockoutEvent, 1)}
+	service.SetLockoutNotifier(spy)
+
+	_, err := service.Register(ctx, &auth.RegisterRequest{Email: "unlimited@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		_, err := service.Login(ctx, &auth.LoginRequest{Email: "unlimited@example.com", Password: "WrongPass123"})
+		assert.IsType(t, &auth.ErrInvalidCredentials{}, err)
+	}
+
+	_, err = service.Login(ctx, &auth.LoginRequest{Email: "unlimited@example.com", Password: "SecurePass123"})
+	assert.NoError(t, err)
+	assert.Empty(t, spy.events)
+}
+
+func TestWebhookLockoutNotifier(t *testing.T) {
+	received := make(chan auth.LockoutEvent, 1)
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var event auth.LockoutEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	notifier := auth.NewWebhookLockoutNotifier(server.URL, nil)
+	event := auth.LockoutEvent{UserID: 7, Email: "hook@example.com", Reason: auth.LockoutReasonFailedLogins, FailedAttempts: 5}
+
+	t.Run("posts the event", func(t *testing.T) {
+		require.NoError(t, notifier.NotifyLockout(context.Background(), event))
+		got := <-received
+		assert.Equal(t, uint(7), got.UserID)
+		assert.Equal(t, "hook@example.com", got.Email)
+	})
+
+	t.Run("non-2xx response is an error", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		assert.Error(t, notifier.NotifyLockout(context.Background(), event))
+		<-received
+	})
+}
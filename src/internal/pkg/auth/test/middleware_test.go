@@ -12,7 +12,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"myapp/internal/pkg/auth"
+	applog "myapp/internal/pkg/logger"
 )
 
 // setupTestMiddleware creates a test middleware with a mock service
@@ -120,17 +123,59 @@ func TestJWTMiddleware_InvalidHeaderFormat(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
 }
 
+// TestOptionalJWTMiddleware tests that requests without a token pass anonymously and tokens are still verified
+func TestOptionalJWTMiddleware(t *testing.T) {
+	service, _ := setupTestService(t)
+	middleware := auth.OptionalJWTMiddleware(service, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, &auth.RegisterRequest{Email: "optional@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	loginResponse, err := service.Login(ctx, &auth.LoginRequest{Email: "optional@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	run := func(authorization string) (*auth.UserContext, error) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+
+		var user *auth.UserContext
+		err := middleware(func(c echo.Context) error {
+			user, _ = auth.GetUserFromContext(c)
+			return c.NoContent(http.StatusOK)
+		})(c)
+		return user, err
+	}
+
+	t.Run("no token", func(t *testing.T) {
+		user, err := run("")
+		require.NoError(t, err)
+		assert.Nil(t, user)
+	})
+
+	t.Run("valid token", func(t *testing.T) {
+		user, err := run("Bearer " + loginResponse.AccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, user)
+		assert.Equal(t, "optional@example.com", user.Email)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, err := run("Bearer invalid.token.here")
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+	})
+}
+
 func TestRequireRole(t *testing.T) {
 	_, service := setupTestMiddleware(t)
 	ctx := context.Background()
 
-	// Register admin user
-		registerReq := &auth.RegisterRequest{
-		Email:    "admin@example.com",
-		Password: "SecurePass123",
-		Role:     "admin",
-	}
-	_, err := service.Register(ctx, registerReq)
+	// Create admin user; registration only creates users
+	_, err := service.CreateAdmin(ctx, "admin@example.com", "SecurePass123")
 	require.NoError(t, err)
 
 		loginReq := &auth.LoginRequest{
@@ -147,7 +192,7 @@ func TestRequireRole(t *testing.T) {
 	c := e.NewContext(req, rec)
 
 	// First apply JWT middleware
-	jwtMiddleware := JWTMiddleware(service, zap.NewNop())
+	jwtMiddleware := auth.JWTMiddleware(service, zap.NewNop())
 	err = jwtMiddleware(func(c echo.Context) error {
 		return nil
 	})(c)
@@ -171,7 +216,6 @@ func TestRequireRole_InsufficientPermissions(t *testing.T) {
 		registerReq := &auth.RegisterRequest{
 		Email:    "user@example.com",
 		Password: "SecurePass123",
-		Role:     "user",
 	}
 	_, err := service.Register(ctx, registerReq)
 	require.NoError(t, err)
@@ -190,7 +234,7 @@ func TestRequireRole_InsufficientPermissions(t *testing.T) {
 	c := e.NewContext(req, rec)
 
 	// First apply JWT middleware
-	jwtMiddleware := JWTMiddleware(service, zap.NewNop())
+	jwtMiddleware := auth.JWTMiddleware(service, zap.NewNop())
 	err = jwtMiddleware(func(c echo.Context) error {
 		return nil
 	})(c)
@@ -216,18 +260,17 @@ func TestGetUserFromContext(t *testing.T) {
 	c := e.NewContext(req, rec)
 
 	// Test without user context
-	_, err := GetUserFromContext(c)
+	_, err := auth.GetUserFromContext(c)
 	assert.Error(t, err)
 
 	// Test with user context
 		userCtx := &auth.UserContext{
 		UserID: 1,
 		Email:  "test@example.com",
-		Role:   "user",
 	}
 	c.Set("user", userCtx)
 
-	user, err := GetUserFromContext(c)
+	user, err := auth.GetUserFromContext(c)
 	assert.NoError(t, err)
 	assert.NotNil(t, user)
 	assert.Equal(t, userCtx.UserID, user.UserID)
@@ -242,18 +285,119 @@ func TestGetUserIDFromContext(t *testing.T) {
 	c := e.NewContext(req, rec)
 
 	// Test without user context
-	_, err := GetUserIDFromContext(c)
+	_, err := auth.GetUserIDFromContext(c)
 	assert.Error(t, err)
 
 	// Test with user context
 		userCtx := &auth.UserContext{
 		UserID: 123,
 		Email:  "test@example.com",
-		Role:   "user",
 	}
 	c.Set("user", userCtx)
 
-	userID, err := GetUserIDFromContext(c)
+	userID, err := auth.GetUserIDFromContext(c)
 	assert.NoError(t, err)
 	assert.Equal(t, uint(123), userID)
 }
+
+func TestRequireScope(t *testing.T) {
+	service, db, cleanup := setupTestServiceWithDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	registered, err := service.Register(ctx, &auth.RegisterRequest{
+		Email:    "scoped@example.com",
+		Password: "SecurePass123",
+	})
+	require.NoError(t, err)
+
+	// Grant a subset of the scopes used below
+	require.NoError(t, db.Model(&auth.User{}).
+		Where("email = ?", registered.Email).
+		Updates(&auth.User{Scopes: []string{"products:read", "products:write"}}).Error)
+
+	loginResponse, err := service.Login(ctx, &auth.LoginRequest{
+		Email:    "scoped@example.com",
+		Password: "SecurePass123",
+	})
+	require.NoError(t, err)
+
+	// authenticate runs the JWT middleware and returns the resulting context
+	authenticate := func(t *testing.T) echo.Context {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+loginResponse.AccessToken)
+		c := echo.New().NewContext(req, httptest.NewRecorder())
+
+		err := auth.JWTMiddleware(service, zap.NewNop())(func(c echo.Context) error {
+			return nil
+		})(c)
+		require.NoError(t, err)
+		return c
+	}
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	}
+
+	t.Run("scopes exposed in context", func(t *testing.T) {
+		user, err := auth.GetUserFromContext(authenticate(t))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"products:read", "products:write"}, user.Scopes)
+	})
+
+	t.Run("all required scopes granted", func(t *testing.T) {
+		err := auth.RequireScope("products:read", "products:write")(handler)(authenticate(t))
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing scope denied", func(t *testing.T) {
+		err := auth.RequireScope("products:write", "products:delete")(handler)(authenticate(t))
+		require.Error(t, err)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code)
+	})
+
+	t.Run("role middleware unaffected", func(t *testing.T) {
+		err := auth.RequireRole("user")(handler)(authenticate(t))
+		assert.NoError(t, err)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		err := auth.RequireScope("products:read")(handler)(c)
+		require.Error(t, err)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+	})
+}
+
+func TestJWTMiddleware_AddsUserIDToContextLogger(t *testing.T) {
+	middleware, service := setupTestMiddleware(t)
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, &auth.RegisterRequest{Email: "logged@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	loginResponse, err := service.Login(ctx, &auth.LoginRequest{Email: "logged@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	core, recorded := observer.New(zapcore.InfoLevel)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(applog.WithRequestID(applog.WithContext(req.Context(), zap.New(core)), "req-auth-1"))
+	req.Header.Set("Authorization", "Bearer "+loginResponse.AccessToken)
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+
+	err = middleware(func(c echo.Context) error {
+		applog.FromContext(c.Request().Context()).Info("authenticated request")
+		return c.NoContent(http.StatusOK)
+	})(c)
+	require.NoError(t, err)
+
+	entries := recorded.FilterMessage("authenticated request").All()
+	require.Len(t, entries, 1)
+	assert.Equal(t, uint64(user.ID), entries[0].ContextMap()["user_id"])
+	assert.Equal(t, "req-auth-1", entries[0].ContextMap()["request_id"])
+}
@@ -0,0 +1,138 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/testsupport"
+)
+
+// sqliteIndex is a row of PRAGMA index_list
+type sqliteIndex struct {
+	Name   string
+	Unique bool
+	Origin string
+}
+
+// indexesOf returns the indexes of a SQLite table keyed by name
+func indexesOf(t *testing.T, db *gorm.DB, table string) map[string]sqliteIndex {
+	var rows []sqliteIndex
+	require.NoError(t, db.Raw("SELECT name, \"unique\", origin FROM pragma_index_list(?)", table).Scan(&rows).Error)
+
+	indexes := make(map[string]sqliteIndex, len(rows))
+	for _, row := range rows {
+		indexes[row.Name] = row
+	}
+	return indexes
+}
+
+// indexColumns returns the column names of a SQLite index in order
+func indexColumns(t *testing.T, db *gorm.DB, index string) []string {
+	var columns []string
+	require.NoError(t, db.Raw("SELECT name FROM pragma_index_info(?) ORDER BY seqno", index).Scan(&columns).Error)
+	return columns
+}
+
+func TestRunMigrations_Indexes(t *testing.T) {
+	db := testsupport.NewTestDB(t)
+	require.NoError(t, auth.RunMigrations(db))
+
+	t.Run("users", func(t *testing.T) {
+		indexes := indexesOf(t, db, "users")
+		require.Contains(t, indexes, "idx_users_email")
+		assert.True(t, indexes["idx_users_email"].Unique)
+		assert.Equal(t, []string{"email"}, indexColumns(t, db, "idx_users_email"))
+	})
+
+	t.Run("refresh_tokens", func(t *testing.T) {
+		indexes := indexesOf(t, db, "refresh_tokens")
+		require.Contains(t, indexes, "idx_refresh_tokens_token")
+		assert.True(t, indexes["idx_refresh_tokens_token"].Unique)
+		assert.Equal(t, []string{"token"}, indexColumns(t, db, "idx_refresh_tokens_token"))
+
+		require.Contains(t, indexes, "idx_refresh_tokens_user_revoked")
+		assert.Equal(t, []string{"user_id", "revoked"}, indexColumns(t, db, "idx_refresh_tokens_user_revoked"))
+
+		assert.Contains(t, indexes, "idx_refresh_tokens_user_id")
+		assert.Contains(t, indexes, "idx_refresh_tokens_expires_at")
+	})
+
+	t.Run("token_blacklist", func(t *testing.T) {
+		indexes := indexesOf(t, db, "token_blacklist")
+		var pk *sqliteIndex
+		for _, index := range indexes {
+			if index.Origin == "pk" {
+				index := index
+				pk = &index
+			}
+		}
+		require.NotNil(t, pk, "jti primary key index")
+		assert.Equal(t, []string{"jti"}, indexColumns(t, db, pk.Name))
+		assert.Contains(t, indexes, "idx_token_blacklist_expires_at")
+	})
+
+	t.Run("idempotent", func(t *testing.T) {
+		assert.NoError(t, auth.RunMigrations(db))
+	})
+}
+
+func TestRegisterMigrations_AutoMigrate(t *testing.T) {
+	t.Run("disabled leaves schema untouched", func(t *testing.T) {
+		db := testsupport.NewTestDB(t)
+		cfg := &config.Config{MasterDatabase: config.DatabaseConfig{AutoMigrate: false}}
+
+		auth.RegisterMigrations(cfg, &database.DatabaseManager{MasterDB: db}, zaptest.NewLogger(t))
+
+		assert.False(t, db.Migrator().HasTable(&auth.User{}))
+		assert.False(t, db.Migrator().HasTable(&auth.RefreshToken{}))
+	})
+
+	t.Run("enabled creates tables", func(t *testing.T) {
+		db := testsupport.NewTestDB(t)
+		cfg := &config.Config{MasterDatabase: config.DatabaseConfig{AutoMigrate: true}}
+
+		auth.RegisterMigrations(cfg, &database.DatabaseManager{MasterDB: db}, zaptest.NewLogger(t))
+
+		assert.True(t, db.Migrator().HasTable(&auth.User{}))
+		assert.True(t, db.Migrator().HasTable(&auth.RefreshToken{}))
+	})
+}
+
+func TestMigrations_RecordedAndReversible(t *testing.T) {
+	db := testsupport.NewTestDB(t)
+	cfg := &config.Config{MasterDatabase: config.DatabaseConfig{AutoMigrate: true}}
+	auth.RegisterMigrations(cfg, &database.DatabaseManager{MasterDB: db}, zaptest.NewLogger(t))
+
+	migrator, err := database.NewMigrator(db, auth.Migrations())
+	require.NoError(t, err)
+	statuses, err := migrator.Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, "auto migrate records version %d", status.Version)
+	}
+
+	_, err = migrator.Down(context.Background())
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable(&auth.AccessSession{}))
+	assert.True(t, db.Migrator().HasTable(&auth.AuthorizationCode{}))
+
+	_, err = migrator.Down(context.Background())
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable(&auth.AuthorizationCode{}))
+	assert.True(t, db.Migrator().HasTable(&auth.User{}))
+
+	_, err = migrator.Down(context.Background())
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable(&auth.User{}))
+	assert.False(t, db.Migrator().HasTable(&auth.WebhookDeadLetter{}))
+}
@@ -0,0 +1,107 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+)
+
+// setupOpaqueTestService creates a service issuing opaque access tokens and registers a user
+func setupOpaqueTestService(t *testing.T) *auth.Service {
+	service, _, cleanup := setupTestServiceWithConfig(t, func(cfg *config.AuthConfig) {
+		cfg.TokenMode = config.TokenModeOpaque
+	})
+	t.Cleanup(cleanup)
+
+	_, err := service.Register(context.Background(), &auth.RegisterRequest{Email: "opaque@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	return service
+}
+
+func TestOpaqueToken_LoginAndValidate(t *testing.T) {
+	service := setupOpaqueTestService(t)
+	ctx := context.Background()
+
+	login, err := service.Login(ctx, &auth.LoginRequest{Email: "opaque@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", login.TokenType)
+	assert.Len(t, strings.Split(login.AccessToken, "."), 1, "opaque tokens are not JWTs")
+
+	claims, err := service.ValidateToken(ctx, login.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "opaque@example.com", claims.Email)
+	assert.Equal(t, "user", claims.Role)
+	assert.Equal(t, auth.TokenTypeAccess, claims.TokenType)
+	assert.NotEmpty(t, claims.ID)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), claims.ExpiresAt.Time, time.Minute)
+
+	_, err = service.ValidateToken(ctx, login.AccessToken+"x")
+	var invalid *auth.ErrTokenInvalid
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestOpaqueToken_Refresh(t *testing.T) {
+	service := setupOpaqueTestService(t)
+	ctx := context.Background()
+
+	login, err := service.Login(ctx, &auth.LoginRequest{Email: "opaque@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	refreshed, err := service.RefreshToken(ctx, login.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, login.AccessToken, refreshed.AccessToken)
+
+	claims, err := service.ValidateToken(ctx, refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "opaque@example.com", claims.Email)
+}
+
+func TestOpaqueToken_LogoutInvalidates(t *testing.T) {
+	service := setupOpaqueTestService(t)
+	ctx := context.Background()
+
+	login, err := service.Login(ctx, &auth.LoginRequest{Email: "opaque@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	other, err := service.Login(ctx, &auth.LoginRequest{Email: "opaque@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	require.NoError(t, service.Logout(ctx, login.AccessToken))
+
+	_, err = service.ValidateToken(ctx, login.AccessToken)
+	var invalid *auth.ErrTokenInvalid
+	assert.ErrorAs(t, err, &invalid, "the session row is deleted on logout")
+
+	_, err = service.RefreshToken(ctx, login.RefreshToken)
+	assert.Error(t, err, "logout revokes the user's refresh tokens")
+
+	_, err = service.ValidateToken(ctx, other.AccessToken)
+	assert.NoError(t, err, "logout only deletes the presented session")
+
+	err = service.Logout(ctx, login.AccessToken)
+	assert.ErrorAs(t, err, &invalid)
+}
+
+func TestOpaqueToken_RevokeUserTokens(t *testing.T) {
+	service := setupOpaqueTestService(t)
+	ctx := context.Background()
+
+	login, err := service.Login(ctx, &auth.LoginRequest{Email: "opaque@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	claims, err := service.ValidateToken(ctx, login.AccessToken)
+	require.NoError(t, err)
+
+	resp, err := service.RevokeUserTokens(ctx, claims.UserID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.AccessTokensRevoked)
+
+	_, err = service.ValidateToken(ctx, login.AccessToken)
+	assert.Error(t, err)
+}
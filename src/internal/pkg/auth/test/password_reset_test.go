@@ -0,0 +1,87 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth"
+)
+
+func TestService_PasswordReset(t *testing.T) {
+	service, db, cleanup := setupTestServiceWithDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	register := func(t *testing.T, email string) {
+		_, err := service.Register(ctx, &auth.RegisterRequest{Email: email, Password: "SecurePass123"})
+		require.NoError(t, err)
+	}
+
+	t.Run("valid reset", func(t *testing.T) {
+		register(t, "reset@example.com")
+		session, err := service.Login(ctx, &auth.LoginRequest{Email: "reset@example.com", Password: "SecurePass123"})
+		require.NoError(t, err)
+
+		token, err := service.RequestPasswordReset(ctx, "reset@example.com")
+		require.NoError(t, err)
+		require.NotEmpty(t, token)
+
+		require.NoError(t, service.ResetPassword(ctx, token, "BrandNewPass456"))
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "reset@example.com", Password: "SecurePass123"})
+		assert.IsType(t, &auth.ErrInvalidCredentials{}, err, "old password no longer works")
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "reset@example.com", Password: "BrandNewPass456"})
+		assert.NoError(t, err)
+
+		_, err = service.RefreshToken(ctx, session.RefreshToken)
+		assert.Error(t, err, "existing refresh tokens are revoked")
+	})
+
+	t.Run("reused token rejected", func(t *testing.T) {
+		register(t, "reuse@example.com")
+
+		token, err := service.RequestPasswordReset(ctx, "reuse@example.com")
+		require.NoError(t, err)
+		require.NoError(t, service.ResetPassword(ctx, token, "FirstReset123"))
+
+		err = service.ResetPassword(ctx, token, "SecondReset123")
+		assert.IsType(t, &auth.ErrTokenInvalid{}, err)
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "reuse@example.com", Password: "FirstReset123"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired token rejected", func(t *testing.T) {
+		register(t, "expiredreset@example.com")
+
+		token, err := service.RequestPasswordReset(ctx, "expiredreset@example.com")
+		require.NoError(t, err)
+
+		require.NoError(t, db.Model(&auth.PasswordResetToken{}).
+			Where("1 = 1").
+			Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+		err = service.ResetPassword(ctx, token, "TooLate12345")
+		assert.IsType(t, &auth.ErrTokenExpired{}, err)
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "expiredreset@example.com", Password: "SecurePass123"})
+		assert.NoError(t, err, "password is unchanged")
+	})
+
+	t.Run("unknown token rejected", func(t *testing.T) {
+		err := service.ResetPassword(ctx, "not-a-real-token", "Whatever12345")
+		assert.IsType(t, &auth.ErrTokenInvalid{}, err)
+	})
+
+	t.Run("unknown email succeeds silently", func(t *testing.T) {
+		token, err := service.RequestPasswordReset(ctx, "nobody@example.com")
+		assert.NoError(t, err)
+		assert.Empty(t, token)
+	})
+}
@@ -0,0 +1,88 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+)
+
+const replaySecret = "replay-test-secret"
+
+// setupReplayServer serves POST /api/auth/reset-password behind replay protection
+func setupReplayServer(t *testing.T, secret string) *echo.Echo {
+	repo, _ := setupTestTokenRepository(t)
+	cfg := &config.Config{Auth: config.AuthConfig{ReplayProtectionSecret: secret, ReplayWindow: time.Minute}}
+
+	e := echo.New()
+	e.POST("/api/auth/reset-password", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, auth.ReplayProtectionMiddleware(cfg, repo, zap.NewNop()))
+	return e
+}
+
+// signedRequest builds a reset-password request signed with the given nonce and timestamp
+func signedRequest(secret, nonce string, timestamp time.Time) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/reset-password", nil)
+	ts := timestamp.Unix()
+	req.Header.Set(auth.HeaderReplayNonce, nonce)
+	req.Header.Set(auth.HeaderReplayTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(auth.HeaderReplaySignature, auth.SignReplayNonce(secret, http.MethodPost, "/api/auth/reset-password", ts, nonce))
+	return req
+}
+
+func TestReplayProtectionMiddleware(t *testing.T) {
+	e := setupReplayServer(t, replaySecret)
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("fresh request passes and its replay is rejected", func(t *testing.T) {
+		now := time.Now()
+		assert.Equal(t, http.StatusOK, serve(signedRequest(replaySecret, "nonce-1", now)).Code)
+
+		rec := serve(signedRequest(replaySecret, "nonce-1", now))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "already been used")
+
+		assert.Equal(t, http.StatusOK, serve(signedRequest(replaySecret, "nonce-2", now)).Code)
+	})
+
+	t.Run("expired timestamp is rejected", func(t *testing.T) {
+		rec := serve(signedRequest(replaySecret, "nonce-old", time.Now().Add(-2*time.Minute)))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), "outside allowed window")
+	})
+
+	t.Run("wrong signature is rejected without burning the nonce", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(signedRequest("other-secret", "nonce-3", time.Now())).Code)
+		assert.Equal(t, http.StatusOK, serve(signedRequest(replaySecret, "nonce-3", time.Now())).Code)
+	})
+
+	t.Run("missing headers are rejected", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/reset-password", nil)
+		assert.Equal(t, http.StatusBadRequest, serve(req).Code)
+	})
+}
+
+func TestReplayProtectionMiddleware_Disabled(t *testing.T) {
+	e := setupReplayServer(t, "")
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/auth/reset-password", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+}
@@ -8,22 +8,15 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"myapp/internal/pkg/auth"
 	"myapp/internal/pkg/database"
+	"myapp/internal/testsupport"
 )
 
-// setupTestDB creates an in-memory SQLite database for testing
-func setupTestDB(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	require.NoError(t, err)
-
-	// Auto migrate auth tables
-	err = db.AutoMigrate(&auth.User{}, &auth.RefreshToken{}, &auth.TokenBlacklist{})
-	require.NoError(t, err)
-
-	return db
+// setupTestDB creates a SQLite database with the auth tables for testing
+func setupTestDB(t testing.TB) *gorm.DB {
+	return testsupport.NewTestDB(t, testsupport.AuthModels()...)
 }
 
 // setupTestRepository creates a test repository
@@ -44,7 +37,7 @@ func TestRepository_GetByEmail(t *testing.T) {
 	ctx := context.Background()
 
 	// Create test user
-	user := &User{
+	user := &auth.User{
 		Email:    "test@example.com",
 		Password: "hashed_password",
 		Role:     "user",
@@ -72,7 +65,7 @@ func TestRepository_EmailExists(t *testing.T) {
 	ctx := context.Background()
 
 	// Create test user
-	user := &User{
+	user := &auth.User{
 		Email:    "test@example.com",
 		Password: "hashed_password",
 		Role:     "user",
@@ -98,7 +91,7 @@ func TestRepository_GetByID(t *testing.T) {
 	ctx := context.Background()
 
 	// Create test user
-	user := &User{
+	user := &auth.User{
 		Email:    "test@example.com",
 		Password: "hashed_password",
 		Role:     "user",
@@ -138,7 +131,7 @@ func TestRepository_Create(t *testing.T) {
 	})
 
 	t.Run("create user with duplicate email", func(t *testing.T) {
-		user1 := &User{
+		user1 := &auth.User{
 			Email:    "duplicate@example.com",
 			Password: "hashed_password",
 			Role:     "user",
@@ -146,7 +139,7 @@ func TestRepository_Create(t *testing.T) {
 		err := repo.Create(ctx, user1)
 		require.NoError(t, err)
 
-		user2 := &User{
+		user2 := &auth.User{
 			Email:    "duplicate@example.com",
 			Password: "hashed_password",
 			Role:     "user",
@@ -0,0 +1,128 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth"
+)
+
+func TestService_RevokeUserTokens(t *testing.T) {
+	service, cleanup := setupTestService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	login := func(t *testing.T, email string) *auth.LoginResponse {
+		resp, err := service.Login(ctx, &auth.LoginRequest{Email: email, Password: "SecurePass123"})
+		require.NoError(t, err)
+		return resp
+	}
+
+	target, err := service.Register(ctx, &auth.RegisterRequest{Email: "incident@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	_, err = service.Register(ctx, &auth.RegisterRequest{Email: "bystander@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	// Two sessions for the target, one of which has been refreshed once
+	first := login(t, "incident@example.com")
+	second := login(t, "incident@example.com")
+	refreshed, err := service.RefreshToken(ctx, second.RefreshToken)
+	require.NoError(t, err)
+	bystander := login(t, "bystander@example.com")
+
+	t.Run("revokes refresh and access tokens", func(t *testing.T) {
+		result, err := service.RevokeUserTokens(ctx, target.ID)
+		require.NoError(t, err)
+		assert.Equal(t, target.ID, result.UserID)
+		assert.Equal(t, int64(2), result.RefreshTokensRevoked, "rotated refresh token was already revoked")
+		assert.Equal(t, int64(3), result.AccessTokensRevoked)
+
+		for _, accessToken := range []string{first.AccessToken, second.AccessToken, refreshed.AccessToken} {
+			_, err := service.ValidateToken(ctx, accessToken)
+			assert.IsType(t, &auth.ErrTokenRevoked{}, err)
+		}
+
+		for _, refreshToken := range []string{first.RefreshToken, refreshed.RefreshToken} {
+			_, err := service.RefreshToken(ctx, refreshToken)
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("other users are unaffected", func(t *testing.T) {
+		_, err := service.ValidateToken(ctx, bystander.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("new login after revocation works", func(t *testing.T) {
+		fresh := login(t, "incident@example.com")
+		_, err := service.ValidateToken(ctx, fresh.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("revoking again is harmless", func(t *testing.T) {
+		result, err := service.RevokeUserTokens(ctx, target.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), result.RefreshTokensRevoked, "only the post-revocation login remains")
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := service.RevokeUserTokens(ctx, 9999)
+		assert.IsType(t, &auth.ErrUserNotFound{}, err)
+	})
+}
+
+func TestHandler_RevokeUserTokens(t *testing.T) {
+	handler, service := setupTestHandler(t)
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, &auth.RegisterRequest{Email: "revokehandler@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	loginResponse, err := service.Login(ctx, &auth.LoginRequest{Email: "revokehandler@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	revoke := func(id string) (*httptest.ResponseRecorder, error) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/admin/users/"+id+"/revoke-tokens", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return rec, handler.RevokeUserTokens(c)
+	}
+
+	t.Run("valid user", func(t *testing.T) {
+		rec, err := revoke(user.ToUserResponse().ID)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response auth.RevokeTokensResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, int64(1), response.RefreshTokensRevoked)
+		assert.Equal(t, int64(1), response.AccessTokensRevoked)
+
+		_, err = service.ValidateToken(ctx, loginResponse.AccessToken)
+		assert.IsType(t, &auth.ErrTokenRevoked{}, err)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := revoke("9999")
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		_, err := revoke("abc")
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+}
@@ -6,28 +6,38 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
-	"gorm.io/driver/sqlite"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"gorm.io/gorm"
 	"myapp/internal/pkg/auth"
 	"myapp/internal/pkg/auth/keys"
 	"myapp/internal/pkg/config"
 	"myapp/internal/pkg/database"
+	applog "myapp/internal/pkg/logger"
 )
 
 // setupTestService creates a complete test service with all dependencies
 func setupTestService(t *testing.T) (*auth.Service, func()) {
-	// Setup database
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	require.NoError(t, err)
+	service, _, cleanup := setupTestServiceWithDB(t)
+	return service, cleanup
+}
 
-	err = db.AutoMigrate(&auth.User{}, &auth.RefreshToken{}, &auth.TokenBlacklist{})
-	require.NoError(t, err)
+// setupTestServiceWithDB creates a test service and also returns its database for direct manipulation
+func setupTestServiceWithDB(t *testing.T) (*auth.Service, *gorm.DB, func()) {
+	return setupTestServiceWithConfig(t, nil)
+}
+
+// setupTestServiceWithConfig is setupTestServiceWithDB with a hook to adjust the auth config
+func setupTestServiceWithConfig(t testing.TB, configure func(*config.AuthConfig)) (*auth.Service, *gorm.DB, func()) {
+	// Setup database
+	db := setupTestDB(t)
 
 	dbManager := &database.DatabaseManager{
 		MasterDB: db,
@@ -42,7 +52,7 @@ func setupTestService(t *testing.T) (*auth.Service, func()) {
 	privateKeyPath := filepath.Join(tempDir, "private.pem")
 	publicKeyPath := filepath.Join(tempDir, "public.pem")
 
-	err = keys.GenerateAndSaveKeyPair(privateKeyPath, publicKeyPath, 2048)
+	err := keys.GenerateAndSaveKeyPair(privateKeyPath, publicKeyPath, 2048)
 	require.NoError(t, err)
 
 	authConfig := &config.AuthConfig{
@@ -53,6 +63,9 @@ func setupTestService(t *testing.T) (*auth.Service, func()) {
 		Issuer:               "test-issuer",
 		BCryptCost:           10,
 	}
+	if configure != nil {
+		configure(authConfig)
+	}
 
 	tokenManager, err := auth.NewTokenManager(authConfig)
 	require.NoError(t, err)
@@ -70,7 +83,7 @@ func setupTestService(t *testing.T) (*auth.Service, func()) {
 		os.Remove(publicKeyPath)
 	}
 
-	return service, cleanup
+	return service, db, cleanup
 }
 
 func TestService_Register(t *testing.T) {
@@ -82,14 +95,13 @@ func TestService_Register(t *testing.T) {
 		req := &auth.RegisterRequest{
 			Email:    "newuser@example.com",
 			Password: "SecurePass123",
-			Role:     "user",
 		}
 
 		user, err := service.Register(ctx, req)
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
 		assert.Equal(t, req.Email, user.Email)
-		assert.Equal(t, req.Role, user.Role)
+		assert.Equal(t, "user", user.Role)
 		assert.NotEmpty(t, user.Password) // Should be hashed
 		assert.NotEqual(t, req.Password, user.Password)
 	})
@@ -121,16 +133,53 @@ func TestService_Register(t *testing.T) {
 	})
 }
 
+// TestService_EmailCase tests that emails are stored lowercase and matched regardless of case,
+// including accounts stored in mixed case before emails were normalized
+func TestService_EmailCase(t *testing.T) {
+	service, db, cleanup := setupTestServiceWithDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	user, err := service.Register(ctx, &auth.RegisterRequest{Email: "  Mixed@Example.COM ", Password: "SecurePass123"})
+	require.NoError(t, err)
+	assert.Equal(t, "mixed@example.com", user.Email)
+
+	t.Run("register in another case", func(t *testing.T) {
+		_, err := service.Register(ctx, &auth.RegisterRequest{Email: "MIXED@example.com", Password: "SecurePass123"})
+		assert.IsType(t, &auth.ErrEmailExists{}, err)
+	})
+
+	t.Run("login in another case", func(t *testing.T) {
+		_, err := service.Login(ctx, &auth.LoginRequest{Email: "Mixed@example.com", Password: "SecurePass123"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("legacy mixed-case row", func(t *testing.T) {
+		legacy, err := service.Register(ctx, &auth.RegisterRequest{Email: "legacy@example.com", Password: "SecurePass123"})
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&auth.User{}).Where("id = ?", legacy.ID).Update("email", "Legacy@Example.com").Error)
+
+		response, err := service.Login(ctx, &auth.LoginRequest{Email: "legacy@example.com", Password: "SecurePass123"})
+		require.NoError(t, err)
+		assert.Equal(t, "Legacy@Example.com", response.User.Email)
+
+		_, err = service.Register(ctx, &auth.RegisterRequest{Email: "LEGACY@example.com", Password: "SecurePass123"})
+		assert.IsType(t, &auth.ErrEmailExists{}, err)
+
+		_, err = service.RequestPasswordReset(ctx, " legacy@EXAMPLE.com")
+		assert.NoError(t, err)
+	})
+}
+
 func TestService_Login(t *testing.T) {
 	service, cleanup := setupTestService(t)
 	defer cleanup()
 	ctx := context.Background()
 
 	// Register a user first
-	registerReq := &RegisterRequest{
+	registerReq := &auth.RegisterRequest{
 		Email:    "login@example.com",
 		Password: "SecurePass123",
-		Role:     "user",
 	}
 	_, err := service.Register(ctx, registerReq)
 	require.NoError(t, err)
@@ -173,6 +222,56 @@ func TestService_Login(t *testing.T) {
 		assert.Error(t, err)
 		assert.IsType(t, &auth.ErrInvalidCredentials{}, err)
 	})
+
+	t.Run("failed login is logged with the request's IDs", func(t *testing.T) {
+		core, recorded := observer.New(zapcore.WarnLevel)
+		reqCtx := applog.WithContext(ctx, zap.New(core))
+		reqCtx = applog.WithTenantID(applog.WithRequestID(reqCtx, "req-login-1"), "tenant-a")
+
+		_, err := service.Login(reqCtx, &auth.LoginRequest{Email: "login@example.com", Password: "WrongPassword"})
+		assert.Error(t, err)
+
+		entries := recorded.FilterMessage("Login attempt with invalid password").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "req-login-1", entries[0].ContextMap()["request_id"])
+		assert.Equal(t, "tenant-a", entries[0].ContextMap()["tenant_id"])
+	})
+}
+
+func TestService_Login_UpgradesPasswordHash(t *testing.T) {
+	service, db, cleanup := setupTestServiceWithConfig(t, func(cfg *config.AuthConfig) {
+		cfg.PasswordAlgorithm = config.PasswordAlgorithmArgon2id
+	})
+	defer cleanup()
+	ctx := context.Background()
+
+	// A user created before the switch to argon2id still has a bcrypt hash
+	bcryptHash, err := auth.HashPassword("SecurePass123", &config.AuthConfig{BCryptCost: 10})
+	require.NoError(t, err)
+	user := &auth.User{Email: "upgrade@example.com", Password: bcryptHash, Role: "user"}
+	require.NoError(t, db.Create(user).Error)
+
+	t.Run("wrong password keeps the old hash", func(t *testing.T) {
+		_, err := service.Login(ctx, &auth.LoginRequest{Email: "upgrade@example.com", Password: "WrongPassword"})
+		assert.IsType(t, &auth.ErrInvalidCredentials{}, err)
+
+		var stored auth.User
+		require.NoError(t, db.First(&stored, user.ID).Error)
+		assert.Equal(t, bcryptHash, stored.Password)
+	})
+
+	t.Run("successful login rehashes with argon2id", func(t *testing.T) {
+		_, err := service.Login(ctx, &auth.LoginRequest{Email: "upgrade@example.com", Password: "SecurePass123"})
+		require.NoError(t, err)
+
+		var stored auth.User
+		require.NoError(t, db.First(&stored, user.ID).Error)
+		assert.True(t, strings.HasPrefix(stored.Password, "$argon2id$"), stored.Password)
+		assert.False(t, auth.PasswordNeedsRehash(stored.Password, &config.AuthConfig{PasswordAlgorithm: config.PasswordAlgorithmArgon2id}))
+
+		_, err = service.Login(ctx, &auth.LoginRequest{Email: "upgrade@example.com", Password: "SecurePass123"})
+		assert.NoError(t, err, "the upgraded hash verifies")
+	})
 }
 
 func TestService_RefreshToken(t *testing.T) {
@@ -181,14 +280,14 @@ func TestService_RefreshToken(t *testing.T) {
 	ctx := context.Background()
 
 	// Register and login to get refresh token
-	registerReq := &RegisterRequest{
+	registerReq := &auth.RegisterRequest{
 		Email:    "refresh@example.com",
 		Password: "SecurePass123",
 	}
 	_, err := service.Register(ctx, registerReq)
 	require.NoError(t, err)
 
-	loginReq := &LoginRequest{
+	loginReq := &auth.LoginRequest{
 		Email:    "refresh@example.com",
 		Password: "SecurePass123",
 	}
@@ -221,14 +320,17 @@ func TestService_RefreshToken(t *testing.T) {
 		refreshResponse1, err := service.RefreshToken(ctx, loginResponse2.RefreshToken)
 		require.NoError(t, err)
 
-		// Try to use the old refresh token (should fail due to rotation)
-		_, err = service.RefreshToken(ctx, loginResponse2.RefreshToken)
-		assert.Error(t, err)
-
 		// Use the new refresh token (should work)
 		refreshResponse2, err := service.RefreshToken(ctx, refreshResponse1.RefreshToken)
-		assert.NoError(t, err)
+		require.NoError(t, err)
 		assert.NotEqual(t, refreshResponse1.RefreshToken, refreshResponse2.RefreshToken)
+
+		// Try to use the old refresh token (should fail due to rotation and revoke the family)
+		_, err = service.RefreshToken(ctx, loginResponse2.RefreshToken)
+		assert.IsType(t, &auth.ErrTokenReuseDetected{}, err)
+
+		_, err = service.RefreshToken(ctx, refreshResponse2.RefreshToken)
+		assert.Error(t, err)
 	})
 }
 
@@ -238,14 +340,14 @@ func TestService_Logout(t *testing.T) {
 	ctx := context.Background()
 
 	// Register and login
-	registerReq := &RegisterRequest{
+	registerReq := &auth.RegisterRequest{
 		Email:    "logout@example.com",
 		Password: "SecurePass123",
 	}
 	_, err := service.Register(ctx, registerReq)
 	require.NoError(t, err)
 
-	loginReq := &LoginRequest{
+	loginReq := &auth.LoginRequest{
 		Email:    "logout@example.com",
 		Password: "SecurePass123",
 	}
@@ -275,14 +377,14 @@ func TestService_ValidateToken(t *testing.T) {
 	ctx := context.Background()
 
 	// Register and login
-	registerReq := &RegisterRequest{
+	registerReq := &auth.RegisterRequest{
 		Email:    "validate@example.com",
 		Password: "SecurePass123",
 	}
 	_, err := service.Register(ctx, registerReq)
 	require.NoError(t, err)
 
-	loginReq := &LoginRequest{
+	loginReq := &auth.LoginRequest{
 		Email:    "validate@example.com",
 		Password: "SecurePass123",
 	}
@@ -320,7 +422,7 @@ func TestService_GetUserByID(t *testing.T) {
 	ctx := context.Background()
 
 	// Register a user
-	registerReq := &RegisterRequest{
+	registerReq := &auth.RegisterRequest{
 		Email:    "getuser@example.com",
 		Password: "SecurePass123",
 	}
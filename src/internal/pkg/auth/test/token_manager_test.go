@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"myapp/internal/pkg/auth"
@@ -120,6 +121,7 @@ func TestTokenManager_GenerateAccessToken_Claims(t *testing.T) {
 	assert.Equal(t, user.ID, claims.UserID)
 	assert.Equal(t, user.Email, claims.Email)
 	assert.Equal(t, user.Role, claims.Role)
+	assert.Equal(t, auth.TokenTypeAccess, claims.TokenType)
 	assert.Equal(t, "test-issuer", claims.Issuer) // From setupTestTokenManager
 	assert.NotEmpty(t, claims.ID) // JTI should be present
 	assert.NotNil(t, claims.ExpiresAt)
@@ -230,6 +232,61 @@ func TestTokenManager_ValidateAccessToken_Expired(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestTokenManager_ValidateAccessToken_TokenType(t *testing.T) {
+	tempDir := t.TempDir()
+	privateKeyPath := filepath.Join(tempDir, "private.pem")
+	publicKeyPath := filepath.Join(tempDir, "public.pem")
+
+	err := keys.GenerateAndSaveKeyPair(privateKeyPath, publicKeyPath, 2048)
+	require.NoError(t, err)
+
+	tm, err := auth.NewTokenManager(&config.AuthConfig{
+		AccessTokenDuration: 15 * time.Minute,
+		RSAPrivateKeyPath:   privateKeyPath,
+		RSAPublicKeyPath:    publicKeyPath,
+		Issuer:              "test-issuer",
+	})
+	require.NoError(t, err)
+
+	privateKey, err := keys.LoadPrivateKeyPEM(privateKeyPath)
+	require.NoError(t, err)
+
+	// sign creates a token signed with the manager's key but with the given token type
+	sign := func(tokenType string) string {
+		now := time.Now()
+		claims := &auth.TokenClaims{
+			UserID:    1,
+			Email:     "test@example.com",
+			Role:      "user",
+			TokenType: tokenType,
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+				IssuedAt:  jwt.NewNumericDate(now),
+				Issuer:    "test-issuer",
+				ID:        "jti-" + tokenType,
+			},
+		}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(privateKey)
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("access type accepted", func(t *testing.T) {
+		_, err := tm.ValidateAccessToken(sign(auth.TokenTypeAccess))
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing type rejected", func(t *testing.T) {
+		_, err := tm.ValidateAccessToken(sign(""))
+		assert.ErrorContains(t, err, "invalid token type")
+	})
+
+	t.Run("refresh type rejected", func(t *testing.T) {
+		_, err := tm.ValidateAccessToken(sign(auth.TokenTypeRefresh))
+		assert.ErrorContains(t, err, "invalid token type")
+	})
+}
+
 func TestTokenManager_ExtractClaims(t *testing.T) {
 	tm, cleanup := setupTestTokenManager(t)
 	defer cleanup()
@@ -9,20 +9,15 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"myapp/internal/pkg/auth"
 	"myapp/internal/pkg/database"
+	"myapp/internal/testsupport"
 )
 
 // setupTestTokenRepository creates a test token repository
 func setupTestTokenRepository(t *testing.T) (*auth.TokenRepository, *gorm.DB) {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
-	require.NoError(t, err)
-
-	// Auto migrate auth tables
-	err = db.AutoMigrate(&auth.User{}, &auth.RefreshToken{}, &auth.TokenBlacklist{})
-	require.NoError(t, err)
+	db := setupTestDB(t)
 
 	// Create a mock DatabaseManager
 	dbManager := &database.DatabaseManager{
@@ -38,13 +33,7 @@ func TestTokenRepository_SaveRefreshToken(t *testing.T) {
 	ctx := context.Background()
 
 	// Create test user
-		user := &auth.User{
-		Email:    "test@example.com",
-		Password: "hashed_password",
-		Role:     "user",
-	}
-	err := db.Create(user).Error
-	require.NoError(t, err)
+	user := testsupport.NewTestUser(t, db, "test@example.com", "user")
 
 	t.Run("save refresh token", func(t *testing.T) {
 		tokenHash := "hashed_refresh_token_123"
@@ -54,7 +43,7 @@ func TestTokenRepository_SaveRefreshToken(t *testing.T) {
 		assert.NoError(t, err)
 
 		// Verify token was saved
-		var savedToken RefreshToken
+		var savedToken auth.RefreshToken
 		err = db.Where("token = ?", tokenHash).First(&savedToken).Error
 		assert.NoError(t, err)
 		assert.Equal(t, user.ID, savedToken.UserID)
@@ -68,18 +57,12 @@ func TestTokenRepository_GetRefreshToken(t *testing.T) {
 	ctx := context.Background()
 
 	// Create test user
-		user := &auth.User{
-		Email:    "test@example.com",
-		Password: "hashed_password",
-		Role:     "user",
-	}
-	err := db.Create(user).Error
-	require.NoError(t, err)
+	user := testsupport.NewTestUser(t, db, "test@example.com", "user")
 
 	tokenHash := "hashed_refresh_token_123"
 	expiresAt := time.Now().Add(7 * 24 * time.Hour)
 
-	err = repo.SaveRefreshToken(ctx, user.ID, tokenHash, expiresAt)
+	err := repo.SaveRefreshToken(ctx, user.ID, tokenHash, expiresAt)
 	require.NoError(t, err)
 
 	t.Run("get existing token", func(t *testing.T) {
@@ -124,18 +107,12 @@ func TestTokenRepository_RevokeRefreshToken(t *testing.T) {
 	ctx := context.Background()
 
 	// Create test user
-		user := &auth.User{
-		Email:    "test@example.com",
-		Password: "hashed_password",
-		Role:     "user",
-	}
-	err := db.Create(user).Error
-	require.NoError(t, err)
+	user := testsupport.NewTestUser(t, db, "test@example.com", "user")
 
 	tokenHash := "hashed_refresh_token_123"
 	expiresAt := time.Now().Add(7 * 24 * time.Hour)
 
-	err = repo.SaveRefreshToken(ctx, user.ID, tokenHash, expiresAt)
+	err := repo.SaveRefreshToken(ctx, user.ID, tokenHash, expiresAt)
 	require.NoError(t, err)
 
 	t.Run("revoke token", func(t *testing.T) {
@@ -156,13 +133,7 @@ func TestTokenRepository_RevokeAllUserTokens(t *testing.T) {
 	ctx := context.Background()
 
 	// Create test user
-		user := &auth.User{
-		Email:    "test@example.com",
-		Password: "hashed_password",
-		Role:     "user",
-	}
-	err := db.Create(user).Error
-	require.NoError(t, err)
+	user := testsupport.NewTestUser(t, db, "test@example.com", "user")
 
 	// Create multiple tokens for the user
 	token1 := "token_hash_1"
@@ -170,7 +141,7 @@ func TestTokenRepository_RevokeAllUserTokens(t *testing.T) {
 	token3 := "token_hash_3"
 	expiresAt := time.Now().Add(7 * 24 * time.Hour)
 
-	err = repo.SaveRefreshToken(ctx, user.ID, token1, expiresAt)
+	err := repo.SaveRefreshToken(ctx, user.ID, token1, expiresAt)
 	require.NoError(t, err)
 	err = repo.SaveRefreshToken(ctx, user.ID, token2, expiresAt)
 	require.NoError(t, err)
@@ -237,18 +208,12 @@ func TestTokenRepository_CleanupExpiredTokens(t *testing.T) {
 	ctx := context.Background()
 
 	// Create test user
-		user := &auth.User{
-		Email:    "test@example.com",
-		Password: "hashed_password",
-		Role:     "user",
-	}
-	err := db.Create(user).Error
-	require.NoError(t, err)
+	user := testsupport.NewTestUser(t, db, "test@example.com", "user")
 
 	// Create expired refresh token
 	expiredTokenHash := "expired_token"
 	expiredAt := time.Now().Add(-1 * time.Hour)
-	err = repo.SaveRefreshToken(ctx, user.ID, expiredTokenHash, expiredAt)
+	err := repo.SaveRefreshToken(ctx, user.ID, expiredTokenHash, expiredAt)
 	require.NoError(t, err)
 
 	// Create expired blacklist entry
@@ -269,8 +234,11 @@ func TestTokenRepository_CleanupExpiredTokens(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("cleanup expired tokens", func(t *testing.T) {
-		err := repo.CleanupExpiredTokens(ctx)
+		result, err := repo.CleanupExpiredTokens(ctx)
 		assert.NoError(t, err)
+		assert.Equal(t, int64(1), result.RefreshTokens)
+		assert.Equal(t, int64(1), result.Blacklist)
+		assert.Equal(t, int64(2), result.Total())
 
 		// Verify expired refresh token is deleted
 		var expiredToken auth.RefreshToken
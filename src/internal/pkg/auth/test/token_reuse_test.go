@@ -0,0 +1,127 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth"
+)
+
+// hashToken mirrors how the service stores refresh tokens
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func TestService_RefreshToken_ReuseDetection(t *testing.T) {
+	service, db, cleanup := setupTestServiceWithDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, &auth.RegisterRequest{Email: "family@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+
+	login := func(t *testing.T) *auth.LoginResponse {
+		resp, err := service.Login(ctx, &auth.LoginRequest{Email: "family@example.com", Password: "SecurePass123"})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("rotated tokens share a family", func(t *testing.T) {
+		session := login(t)
+		rotated, err := service.RefreshToken(ctx, session.RefreshToken)
+		require.NoError(t, err)
+
+		var tokens []auth.RefreshToken
+		require.NoError(t, db.Where("token IN ?", []string{hashToken(session.RefreshToken), hashToken(rotated.RefreshToken)}).Find(&tokens).Error)
+		require.Len(t, tokens, 2)
+		assert.NotEmpty(t, tokens[0].FamilyID)
+		assert.Equal(t, tokens[0].FamilyID, tokens[1].FamilyID)
+	})
+
+	t.Run("replaying a rotated token revokes the whole family", func(t *testing.T) {
+		stolen := login(t)
+		other := login(t)
+
+		first, err := service.RefreshToken(ctx, stolen.RefreshToken)
+		require.NoError(t, err)
+		second, err := service.RefreshToken(ctx, first.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = service.RefreshToken(ctx, stolen.RefreshToken)
+		require.Error(t, err)
+		reuse, ok := err.(*auth.ErrTokenReuseDetected)
+		require.True(t, ok, "expected ErrTokenReuseDetected, got %T", err)
+		assert.NotZero(t, reuse.UserID)
+
+		// The latest token in the family no longer works
+		_, err = service.RefreshToken(ctx, second.RefreshToken)
+		assert.Error(t, err)
+
+		var active int64
+		require.NoError(t, db.Model(&auth.RefreshToken{}).
+			Where("token IN ? AND revoked = ?", []string{hashToken(first.RefreshToken), hashToken(second.RefreshToken)}, false).
+			Count(&active).Error)
+		assert.Zero(t, active)
+
+		// Sessions from other logins are untouched
+		_, err = service.RefreshToken(ctx, other.RefreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("tokens without a family revoke every user token", func(t *testing.T) {
+		legacy := login(t)
+		other := login(t)
+		require.NoError(t, db.Model(&auth.RefreshToken{}).
+			Where("token = ?", hashToken(legacy.RefreshToken)).
+			Update("family_id", "").Error)
+
+		_, err := service.RefreshToken(ctx, legacy.RefreshToken)
+		require.NoError(t, err)
+
+		_, err = service.RefreshToken(ctx, legacy.RefreshToken)
+		assert.IsType(t, &auth.ErrTokenReuseDetected{}, err)
+
+		_, err = service.RefreshToken(ctx, other.RefreshToken)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown token is not reuse", func(t *testing.T) {
+		_, err := service.RefreshToken(ctx, "never-issued")
+		assert.IsType(t, &auth.ErrRefreshTokenNotFound{}, err)
+	})
+}
+
+func TestHandler_RefreshToken_ReuseDetected(t *testing.T) {
+	handler, service := setupTestHandler(t)
+	ctx := context.Background()
+
+	_, err := service.Register(ctx, &auth.RegisterRequest{Email: "replay@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	session, err := service.Login(ctx, &auth.LoginRequest{Email: "replay@example.com", Password: "SecurePass123"})
+	require.NoError(t, err)
+	_, err = service.RefreshToken(ctx, session.RefreshToken)
+	require.NoError(t, err)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refresh_token":"`+session.RefreshToken+`"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	err = handler.RefreshToken(e.NewContext(req, rec))
+	require.Error(t, err)
+	httpErr, ok := err.(*echo.HTTPError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+	assert.Contains(t, httpErr.Message, "reuse detected")
+}
@@ -1,6 +1,7 @@
 package auth_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -157,3 +158,43 @@ func TestHashPassword_SamePasswordDifferentHashes(t *testing.T) {
 	assert.NoError(t, auth.VerifyPassword(hashed1, password))
 	assert.NoError(t, auth.VerifyPassword(hashed2, password))
 }
+
+func TestHashPassword_Argon2id(t *testing.T) {
+	cfg := &config.AuthConfig{PasswordAlgorithm: config.PasswordAlgorithmArgon2id}
+
+	hashed, err := auth.HashPassword("SecurePass123", cfg)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$"), hashed)
+
+	assert.NoError(t, auth.VerifyPassword(hashed, "SecurePass123"))
+	err = auth.VerifyPassword(hashed, "WrongPassword")
+	assert.ErrorIs(t, err, auth.ErrPasswordMismatch)
+
+	other, err := auth.HashPassword("SecurePass123", cfg)
+	require.NoError(t, err)
+	assert.NotEqual(t, hashed, other, "each hash uses a fresh salt")
+
+	t.Run("malformed hash", func(t *testing.T) {
+		assert.Error(t, auth.VerifyPassword("$argon2id$v=19$m=65536,t=3,p=4$bm90LWJhc2U2NA", "SecurePass123"))
+		assert.Error(t, auth.VerifyPassword("$argon2id$v=16$m=65536,t=3,p=4$c2FsdA$a2V5", "SecurePass123"))
+	})
+}
+
+func TestVerifyPassword_DispatchesOnHashPrefix(t *testing.T) {
+	bcryptHash, err := auth.HashPassword("SecurePass123", &config.AuthConfig{BCryptCost: 10})
+	require.NoError(t, err)
+	argonHash, err := auth.HashPassword("SecurePass123", &config.AuthConfig{PasswordAlgorithm: config.PasswordAlgorithmArgon2id})
+	require.NoError(t, err)
+
+	// Verification does not depend on the configured algorithm
+	assert.NoError(t, auth.VerifyPassword(bcryptHash, "SecurePass123"))
+	assert.NoError(t, auth.VerifyPassword(argonHash, "SecurePass123"))
+	assert.ErrorIs(t, auth.VerifyPassword(bcryptHash, "WrongPassword"), auth.ErrPasswordMismatch)
+
+	bcryptCfg := &config.AuthConfig{PasswordAlgorithm: config.PasswordAlgorithmBcrypt}
+	argonCfg := &config.AuthConfig{PasswordAlgorithm: config.PasswordAlgorithmArgon2id}
+	assert.False(t, auth.PasswordNeedsRehash(bcryptHash, bcryptCfg))
+	assert.True(t, auth.PasswordNeedsRehash(bcryptHash, argonCfg))
+	assert.False(t, auth.PasswordNeedsRehash(argonHash, argonCfg))
+	assert.True(t, auth.PasswordNeedsRehash(argonHash, bcryptCfg))
+}
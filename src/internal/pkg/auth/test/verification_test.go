@@ -0,0 +1,129 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"myapp/internal/pkg/auth"
+)
+
+// captureSender records the last verification token handed out by the service
+type captureSender struct {
+	tokens map[string]string
+}
+
+func (s *captureSender) SendVerification(ctx context.Context, user *auth.User, token string) error {
+	s.tokens[user.Email] = token
+	return nil
+}
+
+func TestService_VerifyEmail(t *testing.T) {
+	service, db, cleanup := setupTestServiceWithDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	sender := &captureSender{tokens: map[string]string{}}
+	service.SetVerificationSender(sender)
+
+	register := func(t *testing.T, email string) *auth.User {
+		user, err := service.Register(ctx, &auth.RegisterRequest{
+			Email:    email,
+			Password: "SecurePass123",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, sender.tokens[email], "register should send a verification token")
+		return user
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		user := register(t, "verify@example.com")
+		assert.False(t, user.EmailVerified)
+
+		err := service.VerifyEmail(ctx, sender.tokens[user.Email])
+		require.NoError(t, err)
+
+		verified, err := service.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.True(t, verified.EmailVerified)
+		assert.Empty(t, verified.VerificationToken)
+		assert.Nil(t, verified.VerificationExpiry)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		user := register(t, "expired@example.com")
+
+		past := time.Now().Add(-time.Minute)
+		require.NoError(t, db.Model(&auth.User{}).Where("id = ?", user.ID).Update("verification_expiry", past).Error)
+
+		err := service.VerifyEmail(ctx, sender.tokens[user.Email])
+		assert.IsType(t, &auth.ErrTokenExpired{}, err)
+	})
+
+	t.Run("already verified user", func(t *testing.T) {
+		user := register(t, "twice@example.com")
+		require.NoError(t, service.VerifyEmail(ctx, sender.tokens[user.Email]))
+
+		_, err := service.GenerateVerificationToken(ctx, user.ID)
+		assert.IsType(t, &auth.ErrEmailAlreadyVerified{}, err)
+
+		err = service.ResendVerification(ctx, user.Email)
+		assert.IsType(t, &auth.ErrEmailAlreadyVerified{}, err)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		err := service.VerifyEmail(ctx, "not-a-real-token")
+		assert.IsType(t, &auth.ErrTokenInvalid{}, err)
+	})
+
+	t.Run("new token replaces the previous one", func(t *testing.T) {
+		user := register(t, "replace@example.com")
+		first := sender.tokens[user.Email]
+
+		second, err := service.GenerateVerificationToken(ctx, user.ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+
+		assert.IsType(t, &auth.ErrTokenInvalid{}, service.VerifyEmail(ctx, first))
+		assert.NoError(t, service.VerifyEmail(ctx, second))
+	})
+}
+
+func TestTokenSender_DoesNotLogTokens(t *testing.T) {
+	service, _, cleanup := setupTestServiceWithDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	auth.RegisterTokenSender(service, auth.NewTokenSender(zap.New(core)))
+
+	user, err := service.Register(ctx, &auth.RegisterRequest{
+		Email:    "quiet@example.com",
+		Password: "SecurePass123",
+	})
+	require.NoError(t, err)
+
+	verificationToken, err := service.GenerateVerificationToken(ctx, user.ID)
+	require.NoError(t, err)
+	resetToken, err := service.RequestPasswordReset(ctx, user.Email)
+	require.NoError(t, err)
+	require.NotEmpty(t, resetToken)
+
+	require.Equal(t, 1, logs.FilterMessageSnippet("Email verification token issued").Len())
+	require.Equal(t, 1, logs.FilterMessageSnippet("Password reset token issued").Len())
+	for _, entry := range logs.All() {
+		assert.EqualValues(t, user.ID, entry.ContextMap()["user_id"])
+		for _, value := range entry.ContextMap() {
+			assert.NotContains(t, fmt.Sprint(value), verificationToken)
+			assert.NotContains(t, fmt.Sprint(value), resetToken)
+		}
+	}
+}
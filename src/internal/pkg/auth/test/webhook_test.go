@@ -0,0 +1,101 @@
+// +build cgo
+
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+func TestWebhookDeliverer_DeadLetter(t *testing.T) {
+	db := setupTestDB(t)
+	deadLetters := auth.NewDeadLetterRepository(&database.DatabaseManager{MasterDB: db})
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	cfg := &config.AuthConfig{WebhookMaxAttempts: 3, WebhookRetryBackoff: time.Millisecond}
+	deliverer := auth.NewWebhookDeliverer(cfg, nil, deadLetters, zap.New(core))
+
+	err := deliverer.Deliver(context.Background(), server.URL, []byte(`{"user_id":1}`))
+	require.Error(t, err)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// One log entry per attempt, the last one marked for dead-lettering
+	attempts := logs.FilterMessage("Webhook delivery attempt failed").All()
+	require.Len(t, attempts, 3)
+	for i, entry := range attempts {
+		fields := entry.ContextMap()
+		assert.Equal(t, server.URL, fields["url"])
+		assert.Equal(t, int64(i+1), fields["attempt"])
+		assert.Equal(t, int64(http.StatusServiceUnavailable), fields["status"])
+		assert.Contains(t, fields, "latency")
+	}
+	assert.Equal(t, auth.WebhookOutcomeRetrying, attempts[0].ContextMap()["outcome"])
+	assert.Equal(t, auth.WebhookOutcomeDeadLetter, attempts[2].ContextMap()["outcome"])
+	assert.Equal(t, 1, logs.FilterMessage("Webhook moved to dead letter").Len())
+
+	var letters []auth.WebhookDeadLetter
+	require.NoError(t, db.Find(&letters).Error)
+	require.Len(t, letters, 1)
+	assert.Equal(t, server.URL, letters[0].URL)
+	assert.Equal(t, `{"user_id":1}`, letters[0].Payload)
+	assert.Equal(t, 3, letters[0].Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, letters[0].LastStatus)
+	assert.NotEmpty(t, letters[0].LastError)
+
+	metrics := deliverer.Metrics()
+	assert.Equal(t, int64(3), metrics.Attempts)
+	assert.Equal(t, int64(3), metrics.FailedAttempts)
+	assert.Equal(t, int64(1), metrics.DeadLettered)
+	assert.Zero(t, metrics.Delivered)
+}
+
+func TestWebhookDeliverer_RetryThenDeliver(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	cfg := &config.AuthConfig{WebhookMaxAttempts: 3, WebhookRetryBackoff: time.Millisecond}
+	deliverer := auth.NewWebhookDeliverer(cfg, nil, nil, zap.New(core))
+
+	require.NoError(t, deliverer.Deliver(context.Background(), server.URL, []byte(`{}`)))
+	assert.Equal(t, int32(2), calls.Load())
+
+	delivered := logs.FilterMessage("Webhook delivered").All()
+	require.Len(t, delivered, 1)
+	assert.Equal(t, int64(2), delivered[0].ContextMap()["attempt"])
+	assert.Equal(t, auth.WebhookOutcomeDelivered, delivered[0].ContextMap()["outcome"])
+	assert.Zero(t, logs.FilterMessage("Webhook moved to dead letter").Len())
+
+	metrics := deliverer.Metrics()
+	assert.Equal(t, int64(2), metrics.Attempts)
+	assert.Equal(t, int64(1), metrics.Delivered)
+	assert.Equal(t, int64(1), metrics.FailedAttempts)
+	assert.Zero(t, metrics.DeadLettered)
+}
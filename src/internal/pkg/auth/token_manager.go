@@ -5,6 +5,8 @@ import (
 	"crypto/rsa"
 	"encoding/base64"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,19 +14,33 @@ import (
 	"myapp/internal/pkg/config"
 )
 
+// Token types carried in the token_type claim
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
 // TokenClaims represents JWT token claims
 type TokenClaims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
-	Role   string `json:"role"`
+	UserID    uint     `json:"user_id"`
+	Email     string   `json:"email"`
+	Role      string   `json:"role"`
+	Scopes    []string `json:"scopes,omitempty"`
+	TokenType string   `json:"token_type"` // access; guards against one token kind being accepted as another
 	jwt.RegisteredClaims
 }
 
-// TokenManager handles JWT token generation and validation using RS256
+// TokenManager handles JWT token generation and validation using RS256.
+// Tokens are signed with the primary key; additional public keys, looked up by kid,
+// keep tokens signed by a previous key valid while keys are rotated.
 type TokenManager struct {
 	privateKey *rsa.PrivateKey
 	publicKey  *rsa.PublicKey
+	keyID      string // JWK thumbprint of publicKey, sent as the kid header
 	config     *config.AuthConfig
+
+	mu               sync.RWMutex
+	verificationKeys map[string]*rsa.PublicKey // verification-only keys by kid
 }
 
 // NewTokenManager creates a new TokenManager instance
@@ -41,16 +57,74 @@ func NewTokenManager(cfg *config.AuthConfig) (*TokenManager, error) {
 		return nil, fmt.Errorf("load public key: %w", err)
 	}
 	
-	return &TokenManager{
-		privateKey: privateKey,
-		publicKey:  publicKey,
-		config:     cfg,
-	}, nil
+	tm := &TokenManager{
+		privateKey:       privateKey,
+		publicKey:        publicKey,
+		keyID:            keyThumbprint(publicKey),
+		config:           cfg,
+		verificationKeys: make(map[string]*rsa.PublicKey),
+	}
+	
+	// Load retired public keys that may still have live tokens
+	for _, path := range cfg.VerificationKeyPaths {
+		key, err := keys.LoadPublicKeyPEM(path)
+		if err != nil {
+			return nil, fmt.Errorf("load verification key %s: %w", path, err)
+		}
+		tm.AddVerificationKey(keyThumbprint(key), key)
+	}
+	
+	return tm, nil
+}
+
+// AddVerificationKey registers a public key that is accepted for tokens carrying the given kid.
+// It is never used for signing. Adding the primary key's kid is a no-op.
+func (tm *TokenManager) AddVerificationKey(kid string, key *rsa.PublicKey) {
+	if kid == tm.keyID {
+		return
+	}
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.verificationKeys[kid] = key
+}
+
+// verificationKey returns the key for a kid, falling back to the primary key for missing or unknown kids
+func (tm *TokenManager) verificationKey(kid string) *rsa.PublicKey {
+	if kid != "" && kid != tm.keyID {
+		tm.mu.RLock()
+		key, ok := tm.verificationKeys[kid]
+		tm.mu.RUnlock()
+		if ok {
+			return key
+		}
+	}
+	return tm.publicKey
+}
+
+// verificationKeyIDs returns the kids of the verification-only keys in a stable order
+func (tm *TokenManager) verificationKeyIDs() []string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	kids := make([]string, 0, len(tm.verificationKeys))
+	for kid := range tm.verificationKeys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	return kids
 }
 
 // GenerateAccessToken generates a new JWT access token for a user (RS256)
 // Token expires after AccessTokenDuration (default: 15 minutes)
 func (tm *TokenManager) GenerateAccessToken(user *User) (string, error) {
+	tokenString, _, err := tm.GenerateAccessTokenWithClaims(user)
+	return tokenString, err
+}
+
+// GenerateAccessTokenWithClaims generates a new access token and also returns its claims,
+// so callers can track the JTI and expiry of issued tokens.
+// In opaque mode the token is a random string that carries no claims; the caller must store
+// the claims server-side, keyed by the token's hash.
+func (tm *TokenManager) GenerateAccessTokenWithClaims(user *User) (string, *TokenClaims, error) {
 	now := time.Now()
 	expiresAt := now.Add(tm.config.AccessTokenDuration)
 	
@@ -58,9 +132,11 @@ func (tm *TokenManager) GenerateAccessToken(user *User) (string, error) {
 	jti := generateJTI()
 	
 	claims := &TokenClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role,
+		Scopes:    user.Scopes,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -70,13 +146,22 @@ func (tm *TokenManager) GenerateAccessToken(user *User) (string, error) {
 		},
 	}
 	
+	if tm.Opaque() {
+		tokenString, err := tm.GenerateRefreshToken()
+		if err != nil {
+			return "", nil, fmt.Errorf("generate opaque token: %w", err)
+		}
+		return tokenString, claims, nil
+	}
+	
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = tm.keyID
 	tokenString, err := token.SignedString(tm.privateKey)
 	if err != nil {
-		return "", fmt.Errorf("sign token: %w", err)
+		return "", nil, fmt.Errorf("sign token: %w", err)
 	}
 	
-	return tokenString, nil
+	return tokenString, claims, nil
 }
 
 // GenerateRefreshToken generates a secure random refresh token string
@@ -93,13 +178,15 @@ func (tm *TokenManager) GenerateRefreshToken() (string, error) {
 }
 
 // ValidateAccessToken validates and parses an access token
+// Tokens without a token_type claim of "access" are rejected
 func (tm *TokenManager) ValidateAccessToken(tokenString string) (*jwt.Token, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// Verify signing method is RS256
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return tm.publicKey, nil
+		kid, _ := token.Header["kid"].(string)
+		return tm.verificationKey(kid), nil
 	})
 	
 	if err != nil {
@@ -110,6 +197,11 @@ func (tm *TokenManager) ValidateAccessToken(tokenString string) (*jwt.Token, err
 		return nil, fmt.Errorf("invalid token")
 	}
 	
+	claims, ok := token.Claims.(*TokenClaims)
+	if !ok || claims.TokenType != TokenTypeAccess {
+		return nil, fmt.Errorf("invalid token type: expected %s", TokenTypeAccess)
+	}
+	
 	return token, nil
 }
 
@@ -122,6 +214,11 @@ func (tm *TokenManager) ExtractClaims(token *jwt.Token) (*TokenClaims, error) {
 	return claims, nil
 }
 
+// Opaque reports whether access tokens are opaque lookup keys rather than signed JWTs
+func (tm *TokenManager) Opaque() bool {
+	return tm.config.TokenMode == config.TokenModeOpaque
+}
+
 // GetTokenExpiration returns the expiration time for access tokens
 func (tm *TokenManager) GetTokenExpiration() time.Duration {
 	return tm.config.AccessTokenDuration
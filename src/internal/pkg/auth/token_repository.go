@@ -6,13 +6,19 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"myapp/internal/pkg/database"
 )
 
-// TokenRepository provides database operations for refresh tokens and token blacklist
+// TokenRepository provides database operations for refresh tokens, issued access tokens and token blacklist
 type TokenRepository struct {
 	refreshTokenRepo *database.MasterRepo[RefreshToken]
 	blacklistRepo    *database.MasterRepo[TokenBlacklist]
+	issuedRepo       *database.MasterRepo[IssuedAccessToken]
+	resetRepo        *database.MasterRepo[PasswordResetToken]
+	codeRepo         *database.MasterRepo[AuthorizationCode]
+	sessionRepo      *database.MasterRepo[AccessSession]
+	nonceRepo        *database.MasterRepo[UsedNonce]
 }
 
 // NewTokenRepository creates a new token repository
@@ -20,14 +26,25 @@ func NewTokenRepository(dbManager *database.DatabaseManager) *TokenRepository {
 	return &TokenRepository{
 		refreshTokenRepo: database.NewMasterRepo[RefreshToken](dbManager),
 		blacklistRepo:    database.NewMasterRepo[TokenBlacklist](dbManager),
+		issuedRepo:       database.NewMasterRepo[IssuedAccessToken](dbManager),
+		resetRepo:        database.NewMasterRepo[PasswordResetToken](dbManager),
+		codeRepo:         database.NewMasterRepo[AuthorizationCode](dbManager),
+		sessionRepo:      database.NewMasterRepo[AccessSession](dbManager),
+		nonceRepo:        database.NewMasterRepo[UsedNonce](dbManager),
 	}
 }
 
-// SaveRefreshToken saves a refresh token to the database (hashed)
+// SaveRefreshToken saves a refresh token to the database (hashed), starting a new token family
 func (r *TokenRepository) SaveRefreshToken(ctx context.Context, userID uint, tokenHash string, expiresAt time.Time) error {
+	return r.SaveRefreshTokenInFamily(ctx, userID, tokenHash, generateJTI(), expiresAt)
+}
+
+// SaveRefreshTokenInFamily saves a refresh token (hashed) that was rotated from a token in familyID
+func (r *TokenRepository) SaveRefreshTokenInFamily(ctx context.Context, userID uint, tokenHash, familyID string, expiresAt time.Time) error {
 	refreshToken := &RefreshToken{
 		UserID:    userID,
 		Token:     tokenHash,
+		FamilyID:  familyID,
 		ExpiresAt: expiresAt,
 		CreatedAt: time.Now(),
 		Revoked:   false,
@@ -59,6 +76,20 @@ func (r *TokenRepository) GetRefreshToken(ctx context.Context, tokenHash string)
 	return &refreshToken, nil
 }
 
+// GetRevokedRefreshToken retrieves a revoked refresh token by its hash; used to detect refresh token reuse
+func (r *TokenRepository) GetRevokedRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, error) {
+	var refreshToken RefreshToken
+	if err := r.refreshTokenRepo.GetDB().WithContext(ctx).
+		Where("token = ? AND revoked = ?", tokenHash, true).
+		First(&refreshToken).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &ErrRefreshTokenNotFound{}
+		}
+		return nil, fmt.Errorf("get revoked refresh token: %w", err)
+	}
+	return &refreshToken, nil
+}
+
 // RevokeRefreshToken revokes a refresh token
 func (r *TokenRepository) RevokeRefreshToken(ctx context.Context, tokenHash string) error {
 	now := time.Now()
@@ -76,19 +107,68 @@ func (r *TokenRepository) RevokeRefreshToken(ctx context.Context, tokenHash stri
 
 // RevokeAllUserTokens revokes all refresh tokens for a user
 func (r *TokenRepository) RevokeAllUserTokens(ctx context.Context, userID uint) error {
+	_, err := r.RevokeUserRefreshTokens(ctx, userID)
+	return err
+}
+
+// RevokeUserRefreshTokens revokes all refresh tokens for a user and returns how many were revoked
+func (r *TokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID uint) (int64, error) {
 	now := time.Now()
-	if err := r.refreshTokenRepo.GetDB().WithContext(ctx).
+	result := r.refreshTokenRepo.GetDB().WithContext(ctx).
 		Model(&RefreshToken{}).
 		Where("user_id = ? AND revoked = ?", userID, false).
 		Updates(map[string]interface{}{
 			"revoked":   true,
 			"revoked_at": now,
-		}).Error; err != nil {
-		return fmt.Errorf("revoke all user tokens: %w", err)
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("revoke all user tokens: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// RevokeTokenFamily revokes every refresh token in a token family and returns how many were revoked
+func (r *TokenRepository) RevokeTokenFamily(ctx context.Context, familyID string) (int64, error) {
+	now := time.Now()
+	result := r.refreshTokenRepo.GetDB().WithContext(ctx).
+		Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked = ?", familyID, false).
+		Updates(map[string]interface{}{
+			"revoked":   true,
+			"revoked_at": now,
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("revoke token family: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// RecordAccessToken stores the JTI of an issued access token for later revocation
+func (r *TokenRepository) RecordAccessToken(ctx context.Context, userID uint, jti string, expiresAt time.Time) error {
+	issued := &IssuedAccessToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	
+	if err := r.issuedRepo.Insert(ctx, issued); err != nil {
+		return fmt.Errorf("record access token: %w", err)
 	}
 	return nil
 }
 
+// GetActiveAccessTokens returns the issued access tokens of a user that have not yet expired
+func (r *TokenRepository) GetActiveAccessTokens(ctx context.Context, userID uint) ([]IssuedAccessToken, error) {
+	var tokens []IssuedAccessToken
+	if err := r.issuedRepo.GetDB().WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("get active access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
 // AddToBlacklist adds a JTI to the token blacklist
 func (r *TokenRepository) AddToBlacklist(ctx context.Context, jti string, expiresAt time.Time) error {
 	blacklistEntry := &TokenBlacklist{
@@ -116,23 +196,248 @@ func (r *TokenRepository) IsBlacklisted(ctx context.Context, jti string) (bool,
 	return count > 0, nil
 }
 
-// CleanupExpiredTokens removes expired tokens from blacklist and refresh tokens
-func (r *TokenRepository) CleanupExpiredTokens(ctx context.Context) error {
+// UseNonce records a replay-protection nonce and reports whether this was its first use
+func (r *TokenRepository) UseNonce(ctx context.Context, nonce string, expiresAt time.Time) (bool, error) {
+	result := r.nonceRepo.GetDB().WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&UsedNonce{
+			Nonce:     nonce,
+			ExpiresAt: expiresAt,
+			CreatedAt: time.Now(),
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("use nonce: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// SavePasswordResetToken saves a password reset token to the database (hashed)
+func (r *TokenRepository) SavePasswordResetToken(ctx context.Context, userID uint, tokenHash string, expiresAt time.Time) error {
+	resetToken := &PasswordResetToken{
+		UserID:    userID,
+		Token:     tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: time.Now(),
+	}
+	
+	if err := r.resetRepo.Insert(ctx, resetToken); err != nil {
+		return fmt.Errorf("save password reset token: %w", err)
+	}
+	return nil
+}
+
+// ConsumePasswordResetToken marks an unused, unexpired reset token as used and returns it.
+// The update is conditional so a token can only be consumed once even under concurrent requests.
+func (r *TokenRepository) ConsumePasswordResetToken(ctx context.Context, tokenHash string) (*PasswordResetToken, error) {
+	var resetToken PasswordResetToken
+	if err := r.resetRepo.GetDB().WithContext(ctx).
+		Where("token = ?", tokenHash).
+		First(&resetToken).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &ErrTokenInvalid{Message: "invalid password reset token"}
+		}
+		return nil, fmt.Errorf("get password reset token: %w", err)
+	}
+	
+	if resetToken.UsedAt != nil {
+		return nil, &ErrTokenInvalid{Message: "password reset token has already been used"}
+	}
+	if time.Now().After(resetToken.ExpiresAt) {
+		return nil, &ErrTokenExpired{Message: "password reset token has expired"}
+	}
+	
+	now := time.Now()
+	result := r.resetRepo.GetDB().WithContext(ctx).
+		Model(&PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", resetToken.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, fmt.Errorf("consume password reset token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, &ErrTokenInvalid{Message: "password reset token has already been used"}
+	}
+	
+	resetToken.UsedAt = &now
+	return &resetToken, nil
+}
+
+// SaveAuthorizationCode stores an authorization code whose Code field holds the hashed value
+func (r *TokenRepository) SaveAuthorizationCode(ctx context.Context, code *AuthorizationCode) error {
+	if err := r.codeRepo.Insert(ctx, code); err != nil {
+		return fmt.Errorf("save authorization code: %w", err)
+	}
+	return nil
+}
+
+// ConsumeAuthorizationCode marks an unused, unexpired authorization code as used and returns it.
+// Like ConsumePasswordResetToken the update is conditional, so a code can only be exchanged once.
+func (r *TokenRepository) ConsumeAuthorizationCode(ctx context.Context, codeHash string) (*AuthorizationCode, error) {
+	var code AuthorizationCode
+	if err := r.codeRepo.GetDB().WithContext(ctx).
+		Where("code = ?", codeHash).
+		First(&code).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &ErrTokenInvalid{Message: "invalid authorization code"}
+		}
+		return nil, fmt.Errorf("get authorization code: %w", err)
+	}
+	
+	if code.UsedAt != nil {
+		return nil, &ErrTokenInvalid{Message: "authorization code has already been used"}
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return nil, &ErrTokenExpired{Message: "authorization code has expired"}
+	}
+	
 	now := time.Now()
+	result := r.codeRepo.GetDB().WithContext(ctx).
+		Model(&AuthorizationCode{}).
+		Where("id = ? AND used_at IS NULL", code.ID).
+		Update("used_at", now)
+	if result.Error != nil {
+		return nil, fmt.Errorf("consume authorization code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, &ErrTokenInvalid{Message: "authorization code has already been used"}
+	}
+	
+	code.UsedAt = &now
+	return &code, nil
+}
+
+// SaveAccessSession stores the server-side session of an opaque access token whose Token field holds the hashed value
+func (r *TokenRepository) SaveAccessSession(ctx context.Context, session *AccessSession) error {
+	if err := r.sessionRepo.Insert(ctx, session); err != nil {
+		return fmt.Errorf("save access session: %w", err)
+	}
+	return nil
+}
+
+// GetAccessSession retrieves the session of an opaque access token by its hash.
+// Expired sessions are returned as ErrTokenExpired until cleanup removes them.
+func (r *TokenRepository) GetAccessSession(ctx context.Context, tokenHash string) (*AccessSession, error) {
+	var session AccessSession
+	if err := r.sessionRepo.GetDB().WithContext(ctx).
+		Where("token = ?", tokenHash).
+		First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, &ErrTokenInvalid{Message: "invalid access token"}
+		}
+		return nil, fmt.Errorf("get access session: %w", err)
+	}
+	
+	if time.Now().After(session.ExpiresAt) {
+		return nil, &ErrTokenExpired{Message: "access token has expired"}
+	}
+	return &session, nil
+}
+
+// DeleteAccessSession deletes the session of an opaque access token, revoking it
+func (r *TokenRepository) DeleteAccessSession(ctx context.Context, tokenHash string) error {
+	if err := r.sessionRepo.GetDB().WithContext(ctx).
+		Where("token = ?", tokenHash).
+		Delete(&AccessSession{}).Error; err != nil {
+		return fmt.Errorf("delete access session: %w", err)
+	}
+	return nil
+}
+
+// DeleteUserAccessSessions deletes every unexpired access session of a user and returns how many were deleted
+func (r *TokenRepository) DeleteUserAccessSessions(ctx context.Context, userID uint) (int64, error) {
+	result := r.sessionRepo.GetDB().WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Delete(&AccessSession{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("delete user access sessions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// CleanupResult reports how many expired rows CleanupExpiredTokens deleted per table
+type CleanupResult struct {
+	Blacklist           int64
+	IssuedAccessTokens  int64
+	PasswordResetTokens int64
+	AuthorizationCodes  int64
+	AccessSessions      int64
+	RefreshTokens       int64
+	UsedNonces          int64
+}
+
+// Total returns the number of rows deleted across all tables
+func (r *CleanupResult) Total() int64 {
+	return r.Blacklist + r.IssuedAccessTokens + r.PasswordResetTokens + r.AuthorizationCodes + r.AccessSessions + r.RefreshTokens + r.UsedNonces
+}
+
+// CleanupExpiredTokens removes expired tokens from blacklist, issued access tokens, password reset tokens,
+// authorization codes, access sessions, refresh tokens and used nonces
+func (r *TokenRepository) CleanupExpiredTokens(ctx context.Context) (*CleanupResult, error) {
+	now := time.Now()
+	result := &CleanupResult{}
 	
 	// Cleanup expired blacklist entries
-	if err := r.blacklistRepo.GetDB().WithContext(ctx).
+	deleted := r.blacklistRepo.GetDB().WithContext(ctx).
+		Where("expires_at < ?", now).
+		Delete(&TokenBlacklist{})
+	if deleted.Error != nil {
+		return nil, fmt.Errorf("cleanup expired blacklist: %w", deleted.Error)
+	}
+	result.Blacklist = deleted.RowsAffected
+	
+	// Cleanup expired issued access token records
+	deleted = r.issuedRepo.GetDB().WithContext(ctx).
+		Where("expires_at < ?", now).
+		Delete(&IssuedAccessToken{})
+	if deleted.Error != nil {
+		return nil, fmt.Errorf("cleanup expired issued access tokens: %w", deleted.Error)
+	}
+	result.IssuedAccessTokens = deleted.RowsAffected
+	
+	// Cleanup expired password reset tokens
+	deleted = r.resetRepo.GetDB().WithContext(ctx).
 		Where("expires_at < ?", now).
-		Delete(&TokenBlacklist{}).Error; err != nil {
-		return fmt.Errorf("cleanup expired blacklist: %w", err)
+		Delete(&PasswordResetToken{})
+	if deleted.Error != nil {
+		return nil, fmt.Errorf("cleanup expired password reset tokens: %w", deleted.Error)
 	}
+	result.PasswordResetTokens = deleted.RowsAffected
+	
+	// Cleanup expired authorization codes, used or not
+	deleted = r.codeRepo.GetDB().WithContext(ctx).
+		Where("expires_at < ?", now).
+		Delete(&AuthorizationCode{})
+	if deleted.Error != nil {
+		return nil, fmt.Errorf("cleanup expired authorization codes: %w", deleted.Error)
+	}
+	result.AuthorizationCodes = deleted.RowsAffected
+	
+	// Cleanup expired opaque access token sessions
+	deleted = r.sessionRepo.GetDB().WithContext(ctx).
+		Where("expires_at < ?", now).
+		Delete(&AccessSession{})
+	if deleted.Error != nil {
+		return nil, fmt.Errorf("cleanup expired access sessions: %w", deleted.Error)
+	}
+	result.AccessSessions = deleted.RowsAffected
 	
 	// Cleanup expired refresh tokens
-	if err := r.refreshTokenRepo.GetDB().WithContext(ctx).
+	deleted = r.refreshTokenRepo.GetDB().WithContext(ctx).
 		Where("expires_at < ?", now).
-		Delete(&RefreshToken{}).Error; err != nil {
-		return fmt.Errorf("cleanup expired refresh tokens: %w", err)
+		Delete(&RefreshToken{})
+	if deleted.Error != nil {
+		return nil, fmt.Errorf("cleanup expired refresh tokens: %w", deleted.Error)
 	}
+	result.RefreshTokens = deleted.RowsAffected
 	
-	return nil
+	// Cleanup nonces whose requests can no longer pass the timestamp check
+	deleted = r.nonceRepo.GetDB().WithContext(ctx).
+		Where("expires_at < ?", now).
+		Delete(&UsedNonce{})
+	if deleted.Error != nil {
+		return nil, fmt.Errorf("cleanup expired nonces: %w", deleted.Error)
+	}
+	result.UsedNonces = deleted.RowsAffected
+	
+	return result, nil
 }
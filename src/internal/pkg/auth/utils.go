@@ -3,28 +3,23 @@ package auth
 import (
 	"fmt"
 
-	"golang.org/x/crypto/bcrypt"
 	"myapp/internal/pkg/config"
 )
 
-// HashPassword hashes a plain text password using bcrypt
-// Uses the cost from config, defaulting to bcrypt.DefaultCost if not set
+// HashPassword hashes a plain text password with the configured algorithm
+// bcrypt uses the cost from config, defaulting to bcrypt.DefaultCost if not set
 func HashPassword(password string, cfg *config.AuthConfig) (string, error) {
-	cost := cfg.BCryptCost
-	if cost <= 0 {
-		cost = bcrypt.DefaultCost
-	}
-	
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	hashed, err := NewPasswordHasher(cfg).Hash(password)
 	if err != nil {
 		return "", fmt.Errorf("hash password: %w", err)
 	}
-	return string(bytes), nil
+	return hashed, nil
 }
 
-// VerifyPassword verifies a plain text password against a hashed password
+// VerifyPassword verifies a plain text password against a hashed password,
+// using the algorithm the hash was made with
 func VerifyPassword(hashedPassword, password string) error {
-	if err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)); err != nil {
+	if err := hasherForHash(hashedPassword).Verify(hashedPassword, password); err != nil {
 		return fmt.Errorf("password mismatch: %w", err)
 	}
 	return nil
@@ -0,0 +1,208 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"myapp/internal/pkg/config"
+)
+
+// Outcomes logged for each webhook delivery attempt
+const (
+	WebhookOutcomeDelivered  = "delivered"
+	WebhookOutcomeRetrying   = "retrying"
+	WebhookOutcomeDeadLetter = "dead_letter"
+)
+
+// webhookRequestTimeout bounds a single webhook HTTP request
+const webhookRequestTimeout = 10 * time.Second
+
+// DeadLetterStore keeps webhooks that could not be delivered
+type DeadLetterStore interface {
+	SaveWebhookDeadLetter(ctx context.Context, letter *WebhookDeadLetter) error
+}
+
+// WebhookMetrics counts webhook delivery attempts and their outcomes
+type WebhookMetrics struct {
+	Attempts       int64
+	Delivered      int64
+	FailedAttempts int64
+	DeadLettered   int64
+	TotalLatency   time.Duration
+}
+
+// webhookCounters holds the live counters behind WebhookMetrics
+type webhookCounters struct {
+	attempts       atomic.Int64
+	delivered      atomic.Int64
+	failedAttempts atomic.Int64
+	deadLettered   atomic.Int64
+	latency        atomic.Int64
+}
+
+// WebhookDeliverer posts JSON payloads to webhooks, retrying failures with backoff.
+// Every attempt is logged with its URL, status, attempt number, latency and outcome;
+// a webhook that fails every attempt is saved as a dead letter.
+type WebhookDeliverer struct {
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	deadLetters DeadLetterStore
+	logger      *zap.Logger
+	counters    webhookCounters
+}
+
+// NewWebhookDeliverer creates a deliverer using auth.webhook_max_attempts and auth.webhook_retry_backoff.
+// A nil client uses one with a short timeout, a nil store skips dead-letter records and a nil logger logs nothing.
+func NewWebhookDeliverer(cfg *config.AuthConfig, client *http.Client, deadLetters DeadLetterStore, logger *zap.Logger) *WebhookDeliverer {
+	if client == nil {
+		client = &http.Client{Timeout: webhookRequestTimeout}
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	maxAttempts := cfg.WebhookMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	return &WebhookDeliverer{
+		client:      client,
+		maxAttempts: maxAttempts,
+		backoff:     cfg.WebhookRetryBackoff,
+		deadLetters: deadLetters,
+		logger:      logger,
+	}
+}
+
+// Metrics returns a snapshot of the delivery counters
+func (d *WebhookDeliverer) Metrics() WebhookMetrics {
+	return WebhookMetrics{
+		Attempts:       d.counters.attempts.Load(),
+		Delivered:      d.counters.delivered.Load(),
+		FailedAttempts: d.counters.failedAttempts.Load(),
+		DeadLettered:   d.counters.deadLettered.Load(),
+		TotalLatency:   time.Duration(d.counters.latency.Load()),
+	}
+}
+
+// Deliver posts payload to url until a 2xx response or the attempts run out.
+// It returns the last error once the webhook has been dead-lettered.
+func (d *WebhookDeliverer) Deliver(ctx context.Context, url string, payload []byte) error {
+	var (
+		lastStatus int
+		lastErr    error
+		attempt    int
+	)
+
+	for attempt = 1; attempt <= d.maxAttempts; attempt++ {
+		start := time.Now()
+		lastStatus, lastErr = d.send(ctx, url, payload)
+		latency := time.Since(start)
+
+		d.counters.attempts.Add(1)
+		d.counters.latency.Add(int64(latency))
+
+		fields := []zap.Field{
+			zap.String("url", url),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", d.maxAttempts),
+			zap.Int("status", lastStatus),
+			zap.Duration("latency", latency),
+		}
+
+		if lastErr == nil {
+			d.counters.delivered.Add(1)
+			d.logger.Info("Webhook delivered", append(fields, zap.String("outcome", WebhookOutcomeDelivered))...)
+			return nil
+		}
+		d.counters.failedAttempts.Add(1)
+
+		if attempt == d.maxAttempts {
+			d.logger.Warn("Webhook delivery attempt failed",
+				append(fields, zap.String("outcome", WebhookOutcomeDeadLetter), zap.Error(lastErr))...)
+			break
+		}
+		d.logger.Warn("Webhook delivery attempt failed",
+			append(fields, zap.String("outcome", WebhookOutcomeRetrying), zap.Error(lastErr))...)
+
+		if !d.wait(ctx, attempt) {
+			// Context is done, give up without burning the remaining attempts
+			break
+		}
+	}
+
+	d.deadLetter(ctx, url, payload, attempt, lastStatus, lastErr)
+	return fmt.Errorf("webhook delivery failed after %d attempts: %w", attempt, lastErr)
+}
+
+// send performs a single POST and fails on a non-2xx response
+func (d *WebhookDeliverer) send(ctx context.Context, url string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// wait sleeps before the next attempt, doubling the backoff each time; it returns false if ctx ends first
+func (d *WebhookDeliverer) wait(ctx context.Context, attempt int) bool {
+	delay := d.backoff << (attempt - 1)
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// deadLetter logs and stores a webhook that exhausted its attempts
+func (d *WebhookDeliverer) deadLetter(ctx context.Context, url string, payload []byte, attempts, lastStatus int, lastErr error) {
+	d.counters.deadLettered.Add(1)
+	d.logger.Error("Webhook moved to dead letter",
+		zap.String("url", url),
+		zap.Int("attempts", attempts),
+		zap.Int("status", lastStatus),
+		zap.Error(lastErr))
+
+	if d.deadLetters == nil {
+		return
+	}
+
+	letter := &WebhookDeadLetter{
+		URL:        url,
+		Payload:    string(payload),
+		Attempts:   attempts,
+		LastStatus: lastStatus,
+	}
+	if lastErr != nil {
+		letter.LastError = lastErr.Error()
+	}
+	// Record the dead letter even if the caller's context was cancelled
+	if err := d.deadLetters.SaveWebhookDeadLetter(context.WithoutCancel(ctx), letter); err != nil {
+		d.logger.Error("Failed to record webhook dead letter",
+			zap.String("url", url),
+			zap.Error(err))
+	}
+}
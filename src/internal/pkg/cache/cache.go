@@ -0,0 +1,128 @@
+// Package cache caches single-record reads in Redis. Redis is an optimization only: every error is logged
+// and treated as a miss, so reads fall through to the database while Redis is unavailable.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"myapp/internal/pkg/config"
+)
+
+// Cache stores JSON-encoded values in Redis with a fixed TTL. A nil *Cache caches nothing.
+type Cache struct {
+	client *redis.Client
+	ttl    time.Duration
+	logger *zap.Logger
+}
+
+// New creates a cache from the cache config section, or returns nil when cache.redis_addr is empty.
+// The connection is not checked here; an unreachable Redis only makes every read a miss.
+func New(cfg *config.Config, logger *zap.Logger) *Cache {
+	if !cfg.Cache.Enabled() {
+		logger.Info("Cache disabled, cache.redis_addr is empty")
+		return nil
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         cfg.Cache.RedisAddr,
+		Password:     cfg.Cache.RedisPassword,
+		DB:           cfg.Cache.RedisDB,
+		DialTimeout:  cfg.Cache.Timeout,
+		ReadTimeout:  cfg.Cache.Timeout,
+		WriteTimeout: cfg.Cache.Timeout,
+	})
+	logger.Info("Cache enabled", zap.String("redis_addr", cfg.Cache.RedisAddr), zap.Duration("ttl", cfg.Cache.TTL))
+	return NewWithClient(client, cfg.Cache.TTL, logger)
+}
+
+// NewWithClient creates a cache using an existing Redis client. A nil logger logs nothing.
+func NewWithClient(client *redis.Client, ttl time.Duration, logger *zap.Logger) *Cache {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &Cache{client: client, ttl: ttl, logger: logger}
+}
+
+// Get decodes the value stored under key into dest and reports whether it was found
+func (c *Cache) Get(ctx context.Context, key string, dest interface{}) bool {
+	if c == nil {
+		return false
+	}
+
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			c.logger.Warn("Cache read failed", zap.String("key", key), zap.Error(err))
+		}
+		return false
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		c.logger.Warn("Cache entry could not be decoded", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// Set stores value under key as JSON for the cache TTL
+func (c *Cache) Set(ctx context.Context, key string, value interface{}) {
+	if c == nil {
+		return
+	}
+	c.SetWithTTL(ctx, key, value, c.ttl)
+}
+
+// SetWithTTL stores value under key as JSON for ttl instead of the cache TTL
+func (c *Cache) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Warn("Cache entry could not be encoded", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if err := c.client.Set(ctx, key, data, ttl).Err(); err != nil {
+		c.logger.Warn("Cache write failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// AddWithTTL is SetWithTTL that leaves an existing entry under key unchanged
+func (c *Cache) AddWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) {
+	if c == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Warn("Cache entry could not be encoded", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if err := c.client.SetNX(ctx, key, data, ttl).Err(); err != nil {
+		c.logger.Warn("Cache write failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// Delete removes the given keys. A failure is logged; the entries then expire with their TTL.
+func (c *Cache) Delete(ctx context.Context, keys ...string) {
+	if c == nil || len(keys) == 0 {
+		return
+	}
+
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		c.logger.Warn("Cache invalidation failed", zap.Strings("keys", keys), zap.Error(err))
+	}
+}
+
+// Close closes the Redis connection
+func (c *Cache) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.client.Close()
+}
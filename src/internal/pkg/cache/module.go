@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+)
+
+// Module provides the Redis cache, nil when caching is disabled, and closes it when the app stops
+var Module = fx.Options(
+	fx.Provide(New),
+	fx.Invoke(registerLifecycle),
+)
+
+// registerLifecycle closes the Redis connection on shutdown
+func registerLifecycle(lc fx.Lifecycle, cache *Cache) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return cache.Close()
+		},
+	})
+}
@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"myapp/internal/pkg/database"
+)
+
+// keyPrefix namespaces the keys written by CachingRepository
+const keyPrefix = "entity"
+
+// Store is the part of a repository CachingRepository decorates, such as a BaseRepository or TenantRepo
+type Store[T any] interface {
+	GetByID(ctx context.Context, id uint) (*T, error)
+	UpdateByID(ctx context.Context, id uint, entity *T) error
+	DeleteByID(ctx context.Context, id uint) error
+}
+
+// CachingRepository caches GetByID results of a Store and drops them when the entity is updated or deleted
+// through it. Writes made another way must call Invalidate. A read racing a write can put the old entity
+// back in the cache, so the cache TTL bounds how stale a read can be.
+type CachingRepository[T any] struct {
+	store        Store[T]
+	cache        *Cache
+	name         string
+	tenantScoped bool
+}
+
+// NewCachingRepository caches entities of a master database store under name, such as "master"
+func NewCachingRepository[T any](store Store[T], cache *Cache, name string) *CachingRepository[T] {
+	return &CachingRepository[T]{store: store, cache: cache, name: name}
+}
+
+// NewTenantCachingRepository caches entities of a tenant database store under name, with keys namespaced by
+// the tenant ID in the context. Without a tenant ID reads and writes bypass the cache.
+func NewTenantCachingRepository[T any](store Store[T], cache *Cache, name string) *CachingRepository[T] {
+	return &CachingRepository[T]{store: store, cache: cache, name: name, tenantScoped: true}
+}
+
+// GetByID returns the cached entity, or reads it from the store and caches it
+func (r *CachingRepository[T]) GetByID(ctx context.Context, id uint) (*T, error) {
+	key, ok := r.key(ctx, id)
+	if ok {
+		var entity T
+		if r.cache.Get(ctx, key, &entity) {
+			return &entity, nil
+		}
+	}
+
+	entity, err := r.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		r.cache.Set(ctx, key, entity)
+	}
+	return entity, nil
+}
+
+// UpdateByID updates the entity in the store and invalidates its cache entry
+func (r *CachingRepository[T]) UpdateByID(ctx context.Context, id uint, entity *T) error {
+	if err := r.store.UpdateByID(ctx, id, entity); err != nil {
+		return err
+	}
+	r.Invalidate(ctx, id)
+	return nil
+}
+
+// DeleteByID deletes the entity from the store and invalidates its cache entry
+func (r *CachingRepository[T]) DeleteByID(ctx context.Context, id uint) error {
+	if err := r.store.DeleteByID(ctx, id); err != nil {
+		return err
+	}
+	r.Invalidate(ctx, id)
+	return nil
+}
+
+// Invalidate drops the cache entries of the given IDs
+func (r *CachingRepository[T]) Invalidate(ctx context.Context, ids ...uint) {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if key, ok := r.key(ctx, id); ok {
+			keys = append(keys, key)
+		}
+	}
+	r.cache.Delete(ctx, keys...)
+}
+
+// key returns the cache key of an entity, namespaced by type and, for tenant stores, by tenant.
+// It reports false when a tenant store is used without a tenant in the context.
+func (r *CachingRepository[T]) key(ctx context.Context, id uint) (string, bool) {
+	if !r.tenantScoped {
+		return fmt.Sprintf("%s:%s:%d", keyPrefix, r.name, id), true
+	}
+	tenantID, err := database.GetTenantID(ctx)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s:%s:%s:%d", keyPrefix, r.name, tenantID, id), true
+}
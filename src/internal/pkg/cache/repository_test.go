@@ -0,0 +1,156 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/cache"
+	"myapp/internal/pkg/database"
+)
+
+// item is the entity cached in these tests
+type item struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// memoryStore is an in-memory Store that counts reads
+type memoryStore struct {
+	items map[uint]*item
+	reads int
+}
+
+func (s *memoryStore) GetByID(ctx context.Context, id uint) (*item, error) {
+	s.reads++
+	found, ok := s.items[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *found
+	return &copied, nil
+}
+
+func (s *memoryStore) UpdateByID(ctx context.Context, id uint, entity *item) error {
+	copied := *entity
+	s.items[id] = &copied
+	return nil
+}
+
+func (s *memoryStore) DeleteByID(ctx context.Context, id uint) error {
+	delete(s.items, id)
+	return nil
+}
+
+// setupCache starts a miniredis server and returns a cache using it
+func setupCache(t *testing.T) (*cache.Cache, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	c := cache.NewWithClient(client, time.Minute, nil)
+	t.Cleanup(func() { c.Close() })
+	return c, server
+}
+
+// TestCachingRepository_GetByID tests cache hits, invalidation and expiry
+func TestCachingRepository_GetByID(t *testing.T) {
+	c, server := setupCache(t)
+	store := &memoryStore{items: map[uint]*item{1: {ID: 1, Name: "Keyboard"}}}
+	repo := cache.NewCachingRepository[item](store, c, "item")
+	ctx := context.Background()
+
+	t.Run("second read is a cache hit", func(t *testing.T) {
+		first, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		second, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+
+		assert.Equal(t, first, second)
+		assert.Equal(t, 1, store.reads)
+		assert.True(t, server.Exists("entity:item:1"))
+		assert.Equal(t, time.Minute, server.TTL("entity:item:1"))
+	})
+
+	t.Run("update invalidates the entry", func(t *testing.T) {
+		require.NoError(t, repo.UpdateByID(ctx, 1, &item{ID: 1, Name: "Keyboard Pro"}))
+		assert.False(t, server.Exists("entity:item:1"))
+
+		updated, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Keyboard Pro", updated.Name)
+		assert.Equal(t, 2, store.reads)
+	})
+
+	t.Run("delete invalidates the entry", func(t *testing.T) {
+		require.NoError(t, repo.DeleteByID(ctx, 1))
+
+		_, err := repo.GetByID(ctx, 1)
+		assert.Error(t, err)
+		assert.False(t, server.Exists("entity:item:1"), "misses are not cached")
+	})
+
+	t.Run("expired entry is read again", func(t *testing.T) {
+		store.items[2] = &item{ID: 2, Name: "Mouse"}
+		reads := store.reads
+		_, err := repo.GetByID(ctx, 2)
+		require.NoError(t, err)
+
+		server.FastForward(2 * time.Minute)
+		_, err = repo.GetByID(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, reads+2, store.reads)
+	})
+}
+
+// TestCachingRepository_TenantKeys tests that tenant stores namespace keys by tenant and bypass the cache without one
+func TestCachingRepository_TenantKeys(t *testing.T) {
+	c, server := setupCache(t)
+	store := &memoryStore{items: map[uint]*item{1: {ID: 1, Name: "Keyboard"}}}
+	repo := cache.NewTenantCachingRepository[item](store, c, "item")
+
+	_, err := repo.GetByID(database.WithTenantID(context.Background(), "tenant-a"), 1)
+	require.NoError(t, err)
+	assert.True(t, server.Exists("entity:item:tenant-a:1"))
+
+	_, err = repo.GetByID(database.WithTenantID(context.Background(), "tenant-b"), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.reads, "tenants do not share entries")
+
+	_, err = repo.GetByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Len(t, server.Keys(), 2, "reads without a tenant are not cached")
+}
+
+// TestCachingRepository_Unavailable tests that reads fall through to the store when Redis is down or disabled
+func TestCachingRepository_Unavailable(t *testing.T) {
+	t.Run("redis down", func(t *testing.T) {
+		c, server := setupCache(t)
+		store := &memoryStore{items: map[uint]*item{1: {ID: 1, Name: "Keyboard"}}}
+		repo := cache.NewCachingRepository[item](store, c, "item")
+		server.Close()
+
+		for i := 0; i < 2; i++ {
+			found, err := repo.GetByID(context.Background(), 1)
+			require.NoError(t, err)
+			assert.Equal(t, "Keyboard", found.Name)
+		}
+		assert.Equal(t, 2, store.reads)
+		require.NoError(t, repo.UpdateByID(context.Background(), 1, &item{ID: 1, Name: "Keyboard Pro"}))
+	})
+
+	t.Run("cache disabled", func(t *testing.T) {
+		store := &memoryStore{items: map[uint]*item{1: {ID: 1, Name: "Keyboard"}}}
+		repo := cache.NewCachingRepository[item](store, nil, "item")
+
+		for i := 0; i < 2; i++ {
+			_, err := repo.GetByID(context.Background(), 1)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, store.reads)
+		require.NoError(t, repo.DeleteByID(context.Background(), 1))
+	})
+}
@@ -1,39 +1,149 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
 )
 
 // Config represents the application configuration
 type Config struct {
-	Server         ServerConfig   `mapstructure:"server"`
-	MasterDatabase DatabaseConfig `mapstructure:"master_database"`
-	TenantDatabase DatabaseConfig `mapstructure:"tenant_database"`
-	JWT            JWTConfig      `mapstructure:"jwt"`
-	Auth           AuthConfig     `mapstructure:"auth"`
-	Logger         LoggerConfig   `mapstructure:"logger"`
+	Server            ServerConfig            `mapstructure:"server"`
+	MasterDatabase    DatabaseConfig          `mapstructure:"master_database"`
+	ReadReplicas      []DatabaseConfig        `mapstructure:"read_replicas"` // Optional read replicas of the master database
+	TenantDatabase    DatabaseConfig          `mapstructure:"tenant_database"`
+	TenantConnections TenantConnectionsConfig `mapstructure:"tenant_connections"`
+	JWT               JWTConfig               `mapstructure:"jwt"`
+	Auth              AuthConfig              `mapstructure:"auth"`
+	Logger            LoggerConfig            `mapstructure:"logger"`
+	Normalization     NormalizationConfig     `mapstructure:"normalization"`
+	Bulk              BulkConfig              `mapstructure:"bulk"`
+	Health            HealthConfig            `mapstructure:"health"`
+	Encryption        EncryptionConfig        `mapstructure:"encryption"`
+	Tracing           TracingConfig           `mapstructure:"tracing"`
+	RateLimit         RateLimitConfig         `mapstructure:"rate_limit"`
+	Idempotency       IdempotencyConfig       `mapstructure:"idempotency"`
+	Outbox            OutboxConfig            `mapstructure:"outbox"`
+	Cache             CacheConfig             `mapstructure:"cache"`
 }
 
 // ServerConfig represents HTTP server configuration
 type ServerConfig struct {
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
+	Host                   string     `mapstructure:"host"`
+	Port                   int        `mapstructure:"port"`
+	RequestTimeoutSeconds  int        `mapstructure:"request_timeout_seconds"`  // 0 disables the global request timeout
+	MaxBodyBytes           int64      `mapstructure:"max_body_bytes"`           // Larger request bodies get 413, 1 MB; 0 disables the limit
+	ShutdownTimeoutSeconds int        `mapstructure:"shutdown_timeout_seconds"` // How long in-flight requests may drain on shutdown, 10 seconds
+	DeleteResponse         string     `mapstructure:"delete_response"`          // no_content, structured or message
+	UnknownFields          string     `mapstructure:"unknown_fields"`           // ignore or reject names outside the allowlist in ?fields=
+	CompressionEnabled     bool       `mapstructure:"compression_enabled"`      // Gzip responses for clients that accept it
+	CompressionMinLength   int        `mapstructure:"compression_min_length"`   // Responses smaller than this many bytes are sent uncompressed, 1024
+	MetricsEnabled         bool       `mapstructure:"metrics_enabled"`          // Record HTTP metrics and serve them on /metrics
+	PprofEnabled           bool       `mapstructure:"pprof_enabled"`            // Serve net/http/pprof on /debug/pprof/ to admins; off by default
+	TrustedProxies         []string   `mapstructure:"trusted_proxies"`          // CIDRs of reverse proxies whose X-Forwarded-For is believed; empty uses the connection address
+	CORS                   CORSConfig `mapstructure:"cors"`
+
+	SecurityHeaders SecurityHeadersConfig `mapstructure:"security_headers"`
+}
+
+// CORSConfig represents cross-origin request settings
+type CORSConfig struct {
+	AllowedOrigins   []string `mapstructure:"allowed_origins"`   // Empty allows any origin, without credentials
+	AllowedMethods   []string `mapstructure:"allowed_methods"`   // Empty uses the middleware defaults
+	AllowedHeaders   []string `mapstructure:"allowed_headers"`   // Empty reflects the headers the browser asks for
+	AllowCredentials bool     `mapstructure:"allow_credentials"` // Requires explicit origins
+}
+
+// SecurityHeadersConfig represents the security headers added to every response; an empty value omits the header
+type SecurityHeadersConfig struct {
+	ContentTypeOptions    string `mapstructure:"content_type_options"`    // X-Content-Type-Options, nosniff
+	FrameOptions          string `mapstructure:"frame_options"`           // X-Frame-Options, DENY
+	ReferrerPolicy        string `mapstructure:"referrer_policy"`         // Referrer-Policy, no-referrer
+	ContentSecurityPolicy string `mapstructure:"content_security_policy"` // Content-Security-Policy, default-src 'none'; frame-ancestors 'none'
+	HSTSMaxAge            int    `mapstructure:"hsts_max_age"`            // Strict-Transport-Security max-age on HTTPS requests, 1 year; 0 omits the header
+	HSTSIncludeSubdomains bool   `mapstructure:"hsts_include_subdomains"` // Add includeSubDomains to Strict-Transport-Security
+	HSTSPreload           bool   `mapstructure:"hsts_preload"`            // Add preload to Strict-Transport-Security
 }
 
+// Delete response modes for successful DELETE requests
+const (
+	DeleteResponseNoContent  = "no_content" // 204 with an empty body
+	DeleteResponseStructured = "structured" // 200 {"deleted": true, "id": ...}
+	DeleteResponseMessage    = "message"    // 200 {"message": "..."} (legacy)
+)
+
+// Handling of unknown names in a list endpoint's fields query parameter
+const (
+	UnknownFieldsIgnore = "ignore" // drop them and return the known fields
+	UnknownFieldsReject = "reject" // 400 naming the first unknown field
+)
+
 // DatabaseConfig represents database connection configuration
 type DatabaseConfig struct {
-	Driver       string `mapstructure:"driver"`
-	Host         string `mapstructure:"host"`
-	Port         int    `mapstructure:"port"`
-	Name         string `mapstructure:"name"`
-	User         string `mapstructure:"user"`
-	Password     string `mapstructure:"password"`
-	MaxOpenConns int    `mapstructure:"max_open_conns"`
-	MaxIdleConns int    `mapstructure:"max_idle_conns"`
+	Driver         string        `mapstructure:"driver"`
+	Host           string        `mapstructure:"host"`
+	Port           int           `mapstructure:"port"`
+	Name           string        `mapstructure:"name"`
+	User           string        `mapstructure:"user"`
+	Password       string        `mapstructure:"password"`
+	MaxOpenConns   int           `mapstructure:"max_open_conns"`
+	MaxIdleConns   int           `mapstructure:"max_idle_conns"`
+	SSLMode        string        `mapstructure:"ssl_mode"`        // disable, require, verify-ca or verify-full
+	SSLRootCert    string        `mapstructure:"ssl_root_cert"`   // Optional CA certificate path
+	SSLCert        string        `mapstructure:"ssl_cert"`        // Optional client certificate path
+	SSLKey         string        `mapstructure:"ssl_key"`         // Optional client key path
+	AutoMigrate    bool          `mapstructure:"auto_migrate"`    // Run schema migrations on startup (off by default)
+	ConnectRetries int           `mapstructure:"connect_retries"` // Retries when the database cannot be reached on startup; 0 fails at once
+	ConnectBackoff time.Duration `mapstructure:"connect_backoff"` // Wait before the first retry, doubled on each later retry, 500ms
+}
+
+// Supported access token modes
+const (
+	TokenModeJWT    = "jwt"    // Signed JWTs, verifiable without a database lookup
+	TokenModeOpaque = "opaque" // Random tokens looked up in the server-side session store
+)
+
+// Supported password hashing algorithms
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"   // bcrypt with BCryptCost
+	PasswordAlgorithmArgon2id = "argon2id" // argon2id, preferred for new deployments
+)
+
+// Supported SSL modes for database connections
+const (
+	SSLModeDisable    = "disable"
+	SSLModeRequire    = "require"
+	SSLModeVerifyCA   = "verify-ca"
+	SSLModeVerifyFull = "verify-full"
+)
+
+// ValidSSLMode reports whether mode is a supported SSL mode
+func ValidSSLMode(mode string) bool {
+	switch mode {
+	case SSLModeDisable, SSLModeRequire, SSLModeVerifyCA, SSLModeVerifyFull:
+		return true
+	}
+	return false
+}
+
+// TenantConnectionsConfig represents the tenant connection cache configuration
+type TenantConnectionsConfig struct {
+	MaxCached      int           `mapstructure:"max_cached"`      // 0 means unlimited
+	IdleTimeout    time.Duration `mapstructure:"idle_timeout"`    // 0 disables idle eviction
+	CloseGrace     time.Duration `mapstructure:"close_grace"`     // Evicted connections stay open this long, then until no longer in use
+	StrictContext  bool          `mapstructure:"strict_context"`  // Log and count tenant repository calls without a tenant in context
+	ConnectRetries int           `mapstructure:"connect_retries"` // Retries when a tenant database cannot be reached; 0 fails at once
+	ConnectBackoff time.Duration `mapstructure:"connect_backoff"` // Wait before the first retry, doubled on each later retry, 500ms
 }
 
 // JWTConfig represents JWT configuration
@@ -48,14 +158,147 @@ type AuthConfig struct {
 	RefreshTokenDuration time.Duration `mapstructure:"refresh_token_duration"` // 7 days
 	RSAPrivateKeyPath    string        `mapstructure:"rsa_private_key_path"`
 	RSAPublicKeyPath     string        `mapstructure:"rsa_public_key_path"`
+	VerificationKeyPaths []string      `mapstructure:"verification_key_paths"` // Retired public keys still accepted during rotation
 	Issuer               string        `mapstructure:"issuer"`
 	BCryptCost           int           `mapstructure:"bcrypt_cost"`
+	PasswordAlgorithm    string        `mapstructure:"password_algorithm"` // bcrypt (default) or argon2id; older hashes are upgraded on login
+	TokenMode            string        `mapstructure:"token_mode"`         // jwt (default) or opaque
+
+	RequireEmailVerification   bool          `mapstructure:"require_email_verification"`    // Reject logins until the email is verified
+	VerificationTokenDuration  time.Duration `mapstructure:"verification_token_duration"`   // 24 hours
+	PasswordResetTokenDuration time.Duration `mapstructure:"password_reset_token_duration"` // 1 hour
+	AuthorizationCodeDuration  time.Duration `mapstructure:"authorization_code_duration"`   // Lifetime of OAuth2 authorization codes, 1 minute
+	CleanupInterval            time.Duration `mapstructure:"cleanup_interval"`              // How often expired tokens are purged, 1 hour
+
+	MaxFailedLogins   int           `mapstructure:"max_failed_logins"`   // Consecutive failed logins before the account is locked; 0 disables lockout
+	LockoutDuration   time.Duration `mapstructure:"lockout_duration"`    // 15 minutes
+	LockoutWebhookURL string        `mapstructure:"lockout_webhook_url"` // Optional URL notified when an account is locked
+
+	WebhookMaxAttempts  int           `mapstructure:"webhook_max_attempts"`  // Delivery attempts before a webhook is dead-lettered, 3
+	WebhookRetryBackoff time.Duration `mapstructure:"webhook_retry_backoff"` // Wait before the first retry, doubled on each later retry, 1 second
+
+	ReplayProtectionSecret string        `mapstructure:"replay_protection_secret"` // HMAC key for signed nonces on sensitive routes; empty disables replay protection
+	ReplayWindow           time.Duration `mapstructure:"replay_window"`            // How far a request timestamp may drift from server time, 5 minutes
+
+	BlacklistCacheSize int           `mapstructure:"blacklist_cache_size"` // Blacklist verdicts kept in memory when Redis caching is off, 10000
+	BlacklistCacheTTL  time.Duration `mapstructure:"blacklist_cache_ttl"`  // How long a token found not blacklisted skips the database, 30 seconds; 0 caches only blacklistings
 }
 
 // LoggerConfig represents logger configuration
 type LoggerConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
+	Level              string            `mapstructure:"level"`
+	Format             string            `mapstructure:"format"`
+	OutputPath         string            `mapstructure:"output_path"`          // Also write logs to this file, rotated; empty logs to stdout only
+	MaxSizeMB          int               `mapstructure:"max_size_mb"`          // Rotate the file when it reaches this size, 100
+	MaxBackups         int               `mapstructure:"max_backups"`          // Rotated files to keep; 0 keeps all
+	MaxAgeDays         int               `mapstructure:"max_age_days"`         // Delete rotated files older than this; 0 keeps them
+	Compress           bool              `mapstructure:"compress"`             // Gzip rotated files
+	Sampling           LogSamplingConfig `mapstructure:"sampling"`
+	SlowQueryThreshold time.Duration     `mapstructure:"slow_query_threshold"` // Database queries slower than this are logged at warn level, 200ms; 0 disables
+}
+
+// LogSamplingConfig limits repeated log entries per second; error and higher levels are never sampled
+type LogSamplingConfig struct {
+	Initial    int `mapstructure:"initial"`    // Identical entries logged each second before sampling starts; 0 disables sampling
+	Thereafter int `mapstructure:"thereafter"` // After Initial, log every Nth identical entry that second; 0 drops the rest
+}
+
+// Enabled reports whether sampling is configured
+func (c *LogSamplingConfig) Enabled() bool {
+	return c.Initial > 0
+}
+
+// NormalizationConfig represents user input normalization policy
+type NormalizationConfig struct {
+	LowercaseCodes bool `mapstructure:"lowercase_codes"` // Lowercase codes and SKUs so "ABC" and "abc" collide
+}
+
+// BulkConfig represents settings for batch operations
+type BulkConfig struct {
+	Concurrency  int `mapstructure:"concurrency"`    // Max items processed in parallel
+	MaxBatchSize int `mapstructure:"max_batch_size"` // Max items accepted in one batch create or bulk delete request, 100
+}
+
+// EncryptionConfig represents encryption settings for tenant secrets such as connection strings
+type EncryptionConfig struct {
+	MasterKey     string `mapstructure:"master_key"`      // Base64-encoded 32-byte key; empty disables encryption
+	PerTenantKeys bool   `mapstructure:"per_tenant_keys"` // Derive an isolated key per tenant (HKDF) instead of using the master key for all
+}
+
+// TracingConfig represents OpenTelemetry tracing settings
+type TracingConfig struct {
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint"` // OTLP/HTTP collector host:port, e.g. localhost:4318; empty disables tracing
+	Insecure     bool    `mapstructure:"insecure"`      // Export over plain HTTP instead of HTTPS
+	ServiceName  string  `mapstructure:"service_name"`  // service.name resource attribute, myapp
+	SampleRatio  float64 `mapstructure:"sample_ratio"`  // Fraction of new traces recorded, 0-1; incoming sampled parents are always kept
+}
+
+// RateLimitConfig represents request rate limits, applied per tenant, authenticated user or client IP
+type RateLimitConfig struct {
+	RequestsPerSecond float64       `mapstructure:"requests_per_second"` // Sustained rate allowed for each user or client IP, 20
+	Burst             int           `mapstructure:"burst"`               // Requests allowed at once; defaults to the rate rounded up
+	ExpiresIn         time.Duration `mapstructure:"expires_in"`          // Limiters idle this long are forgotten, 3m
+}
+
+// IdempotencyConfig represents how long responses to requests with an Idempotency-Key header are kept for replay
+type IdempotencyConfig struct {
+	TTL time.Duration `mapstructure:"ttl"` // A key can be reused for a new request after this long, 24h
+}
+
+// OutboxConfig represents settings for publishing events recorded in the outbox tables
+type OutboxConfig struct {
+	WebhookURL   string        `mapstructure:"webhook_url"`   // Events are posted here; empty leaves them unsent in the outbox
+	PollInterval time.Duration `mapstructure:"poll_interval"` // How often unsent events are published, 5 seconds
+	BatchSize    int           `mapstructure:"batch_size"`    // Max events read from one database per poll, 100
+	MaxAttempts  int           `mapstructure:"max_attempts"`  // Failed deliveries before an event is dead-lettered, 10
+}
+
+// CacheConfig represents settings for the Redis cache of single-record reads
+type CacheConfig struct {
+	RedisAddr     string        `mapstructure:"redis_addr"`     // host:port of Redis; empty disables caching
+	RedisPassword string        `mapstructure:"redis_password"` // Optional
+	RedisDB       int           `mapstructure:"redis_db"`       // Redis logical database number
+	TTL           time.Duration `mapstructure:"ttl"`            // How long a cached record is served, 5 minutes
+	Timeout       time.Duration `mapstructure:"timeout"`        // Bound on each Redis call before falling back to the database, 200ms
+}
+
+// Enabled reports whether reads are cached
+func (c *CacheConfig) Enabled() bool {
+	return c.RedisAddr != ""
+}
+
+// Enabled reports whether the outbox publisher runs
+func (c *OutboxConfig) Enabled() bool {
+	return c.WebhookURL != ""
+}
+
+// Enabled reports whether spans are exported
+func (c *TracingConfig) Enabled() bool {
+	return c.OTLPEndpoint != ""
+}
+
+// HealthConfig represents readiness check settings
+type HealthConfig struct {
+	CriticalDependencies []string `mapstructure:"critical_dependencies"` // Failing critical dependencies make readiness return 503; others only degrade it
+	DiskPath             string   `mapstructure:"disk_path"`             // Filesystem checked for free space; empty disables the disk check
+	MinFreeDiskMB        int      `mapstructure:"min_free_disk_mb"`      // The disk check fails below this much free space, 100
+}
+
+// Dependencies checked by the readiness endpoint
+const (
+	HealthDependencyMasterDatabase  = "master_database"
+	HealthDependencyTenantDatabases = "tenant_databases"
+	HealthDependencyDisk            = "disk"
+)
+
+// IsCritical reports whether a failing dependency should make the service unready
+func (c *HealthConfig) IsCritical(dependency string) bool {
+	for _, name := range c.CriticalDependencies {
+		if name == dependency {
+			return true
+		}
+	}
+	return false
 }
 
 // Validate validates the server configuration
@@ -66,9 +309,75 @@ func (c *ServerConfig) Validate() error {
 	if c.Port <= 0 || c.Port > 65535 {
 		return fmt.Errorf("server port must be between 1 and 65535")
 	}
+	if c.RequestTimeoutSeconds < 0 {
+		return fmt.Errorf("server request_timeout_seconds must not be negative")
+	}
+	if c.MaxBodyBytes < 0 {
+		return fmt.Errorf("server max_body_bytes must not be negative")
+	}
+	if c.ShutdownTimeoutSeconds < 0 {
+		return fmt.Errorf("server shutdown_timeout_seconds must not be negative")
+	}
+	if c.ShutdownTimeoutSeconds == 0 {
+		c.ShutdownTimeoutSeconds = 10 // default: 10 seconds
+	}
+	if c.CompressionMinLength < 0 {
+		return fmt.Errorf("server compression_min_length must not be negative")
+	}
+	if err := c.CORS.Validate(); err != nil {
+		return err
+	}
+	for _, cidr := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("server trusted_proxies entry %q is not a CIDR range", cidr)
+		}
+	}
+	if c.SecurityHeaders.HSTSMaxAge < 0 {
+		return fmt.Errorf("server security_headers hsts_max_age must not be negative")
+	}
+	switch c.DeleteResponse {
+	case "":
+		c.DeleteResponse = DeleteResponseNoContent // default value
+	case DeleteResponseNoContent, DeleteResponseStructured, DeleteResponseMessage:
+	default:
+		return fmt.Errorf("server delete_response must be one of: no_content, structured, message")
+	}
+	switch c.UnknownFields {
+	case "":
+		c.UnknownFields = UnknownFieldsIgnore // default value
+	case UnknownFieldsIgnore, UnknownFieldsReject:
+	default:
+		return fmt.Errorf("server unknown_fields must be one of: ignore, reject")
+	}
+	return nil
+}
+
+// Validate validates the CORS configuration
+func (c *CORSConfig) Validate() error {
+	if !c.AllowCredentials {
+		return nil
+	}
+	if len(c.AllowedOrigins) == 0 {
+		return fmt.Errorf("server cors allow_credentials requires explicit allowed_origins")
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			return fmt.Errorf("server cors allow_credentials cannot be combined with a \"*\" origin")
+		}
+	}
 	return nil
 }
 
+// RequestTimeout returns the global request timeout as a duration (0 means disabled)
+func (c *ServerConfig) RequestTimeout() time.Duration {
+	return time.Duration(c.RequestTimeoutSeconds) * time.Second
+}
+
+// ShutdownTimeout returns how long the HTTP server waits for in-flight requests when stopping
+func (c *ServerConfig) ShutdownTimeout() time.Duration {
+	return time.Duration(c.ShutdownTimeoutSeconds) * time.Second
+}
+
 // Validate validates the database configuration
 func (c *DatabaseConfig) Validate() error {
 	if c.Driver == "" {
@@ -95,6 +404,182 @@ func (c *DatabaseConfig) Validate() error {
 	if c.MaxIdleConns <= 0 {
 		c.MaxIdleConns = 5 // default value
 	}
+	if c.ConnectRetries < 0 {
+		return fmt.Errorf("database connect_retries must not be negative")
+	}
+	if c.ConnectBackoff <= 0 {
+		c.ConnectBackoff = 500 * time.Millisecond // default value
+	}
+	if c.SSLMode == "" {
+		c.SSLMode = SSLModeDisable // default value
+	}
+	if !ValidSSLMode(c.SSLMode) {
+		return fmt.Errorf("database ssl_mode must be one of: disable, require, verify-ca, verify-full, got: %s", c.SSLMode)
+	}
+	return nil
+}
+
+// Validate validates the tenant connection cache configuration
+func (c *TenantConnectionsConfig) Validate() error {
+	if c.MaxCached < 0 {
+		return fmt.Errorf("tenant_connections max_cached must not be negative")
+	}
+	if c.IdleTimeout < 0 {
+		return fmt.Errorf("tenant_connections idle_timeout must not be negative")
+	}
+	if c.CloseGrace < 0 {
+		return fmt.Errorf("tenant_connections close_grace must not be negative")
+	}
+	if c.ConnectRetries < 0 {
+		return fmt.Errorf("tenant_connections connect_retries must not be negative")
+	}
+	if c.ConnectBackoff < 0 {
+		return fmt.Errorf("tenant_connections connect_backoff must not be negative")
+	}
+	if c.ConnectBackoff == 0 {
+		c.ConnectBackoff = 500 * time.Millisecond // default value
+	}
+	return nil
+}
+
+// Validate validates the bulk operation configuration
+func (c *BulkConfig) Validate() error {
+	if c.Concurrency < 0 {
+		return fmt.Errorf("bulk concurrency must not be negative")
+	}
+	if c.MaxBatchSize < 0 {
+		return fmt.Errorf("bulk max_batch_size must not be negative")
+	}
+	if c.Concurrency == 0 {
+		c.Concurrency = 4 // default value
+	}
+	if c.MaxBatchSize == 0 {
+		c.MaxBatchSize = 100 // default value
+	}
+	return nil
+}
+
+// Validate validates the encryption configuration
+func (c *EncryptionConfig) Validate() error {
+	if c.MasterKey == "" {
+		return nil
+	}
+	key, err := base64.StdEncoding.DecodeString(c.MasterKey)
+	if err != nil {
+		return fmt.Errorf("encryption master_key must be base64 encoded: %w", err)
+	}
+	if len(key) != 32 {
+		return fmt.Errorf("encryption master_key must decode to 32 bytes, got %d", len(key))
+	}
+	return nil
+}
+
+// Validate validates the health check configuration
+func (c *HealthConfig) Validate() error {
+	if c.CriticalDependencies == nil {
+		c.CriticalDependencies = []string{HealthDependencyMasterDatabase} // default value
+	}
+	for _, name := range c.CriticalDependencies {
+		switch name {
+		case HealthDependencyMasterDatabase, HealthDependencyTenantDatabases, HealthDependencyDisk:
+		default:
+			return fmt.Errorf("health critical_dependencies must only contain: master_database, tenant_databases, disk, got: %s", name)
+		}
+	}
+	if c.MinFreeDiskMB < 0 {
+		return fmt.Errorf("health min_free_disk_mb must not be negative")
+	}
+	if c.MinFreeDiskMB == 0 {
+		c.MinFreeDiskMB = 100 // default value
+	}
+	return nil
+}
+
+// Validate validates the rate limit configuration
+func (c *RateLimitConfig) Validate() error {
+	if c.RequestsPerSecond < 0 {
+		return fmt.Errorf("rate_limit requests_per_second must not be negative")
+	}
+	if c.Burst < 0 {
+		return fmt.Errorf("rate_limit burst must not be negative")
+	}
+	if c.ExpiresIn < 0 {
+		return fmt.Errorf("rate_limit expires_in must not be negative")
+	}
+	if c.RequestsPerSecond == 0 {
+		c.RequestsPerSecond = 20 // default value
+	}
+	if c.Burst == 0 {
+		c.Burst = int(math.Ceil(c.RequestsPerSecond))
+	}
+	if c.ExpiresIn == 0 {
+		c.ExpiresIn = 3 * time.Minute // default value
+	}
+	return nil
+}
+
+// Validate validates the idempotency configuration
+func (c *IdempotencyConfig) Validate() error {
+	if c.TTL < 0 {
+		return fmt.Errorf("idempotency ttl must not be negative")
+	}
+	if c.TTL == 0 {
+		c.TTL = 24 * time.Hour // default value
+	}
+	return nil
+}
+
+// Validate validates the cache configuration
+func (c *CacheConfig) Validate() error {
+	if c.RedisDB < 0 {
+		return fmt.Errorf("cache redis_db must not be negative")
+	}
+	if c.TTL < 0 {
+		return fmt.Errorf("cache ttl must not be negative")
+	}
+	if c.Timeout < 0 {
+		return fmt.Errorf("cache timeout must not be negative")
+	}
+	if c.TTL == 0 {
+		c.TTL = 5 * time.Minute // default value
+	}
+	if c.Timeout == 0 {
+		c.Timeout = 200 * time.Millisecond // default value
+	}
+	return nil
+}
+
+// Validate validates the outbox configuration
+func (c *OutboxConfig) Validate() error {
+	if c.PollInterval < 0 {
+		return fmt.Errorf("outbox poll_interval must not be negative")
+	}
+	if c.BatchSize < 0 {
+		return fmt.Errorf("outbox batch_size must not be negative")
+	}
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("outbox max_attempts must not be negative")
+	}
+	if c.PollInterval == 0 {
+		c.PollInterval = 5 * time.Second // default value
+	}
+	if c.BatchSize == 0 {
+		c.BatchSize = 100 // default value
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 10 // default value
+	}
+	return nil
+}
+
+// Validate validates the tracing configuration
+func (c *TracingConfig) Validate() error {
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("tracing sample_ratio must be between 0 and 1, got: %v", c.SampleRatio)
+	}
+	if c.ServiceName == "" {
+		c.ServiceName = "myapp" // default value
+	}
 	return nil
 }
 
@@ -135,6 +620,54 @@ func (c *AuthConfig) Validate() error {
 	if c.BCryptCost < 4 || c.BCryptCost > 31 {
 		return fmt.Errorf("bcrypt_cost must be between 4 and 31")
 	}
+	if c.PasswordAlgorithm == "" {
+		c.PasswordAlgorithm = PasswordAlgorithmBcrypt // default: bcrypt
+	}
+	if c.PasswordAlgorithm != PasswordAlgorithmBcrypt && c.PasswordAlgorithm != PasswordAlgorithmArgon2id {
+		return fmt.Errorf("password_algorithm must be %s or %s", PasswordAlgorithmBcrypt, PasswordAlgorithmArgon2id)
+	}
+	if c.TokenMode == "" {
+		c.TokenMode = TokenModeJWT // default: signed JWTs
+	}
+	if c.TokenMode != TokenModeJWT && c.TokenMode != TokenModeOpaque {
+		return fmt.Errorf("token_mode must be %s or %s", TokenModeJWT, TokenModeOpaque)
+	}
+	if c.VerificationTokenDuration <= 0 {
+		c.VerificationTokenDuration = 24 * time.Hour // default: 24 hours
+	}
+	if c.PasswordResetTokenDuration <= 0 {
+		c.PasswordResetTokenDuration = time.Hour // default: 1 hour
+	}
+	if c.AuthorizationCodeDuration <= 0 {
+		c.AuthorizationCodeDuration = time.Minute // default: 1 minute
+	}
+	if c.CleanupInterval <= 0 {
+		c.CleanupInterval = time.Hour // default: 1 hour
+	}
+	if c.MaxFailedLogins < 0 {
+		return fmt.Errorf("max_failed_logins must not be negative")
+	}
+	if c.LockoutDuration <= 0 {
+		c.LockoutDuration = 15 * time.Minute // default: 15 minutes
+	}
+	if c.WebhookMaxAttempts < 0 {
+		return fmt.Errorf("webhook_max_attempts must not be negative")
+	}
+	if c.WebhookMaxAttempts == 0 {
+		c.WebhookMaxAttempts = 3 // default: 3 attempts
+	}
+	if c.WebhookRetryBackoff <= 0 {
+		c.WebhookRetryBackoff = time.Second // default: 1 second
+	}
+	if c.ReplayWindow <= 0 {
+		c.ReplayWindow = 5 * time.Minute // default: 5 minutes
+	}
+	if c.BlacklistCacheSize <= 0 {
+		c.BlacklistCacheSize = 10000 // default: 10000 entries
+	}
+	if c.BlacklistCacheTTL < 0 {
+		return fmt.Errorf("blacklist_cache_ttl must not be negative")
+	}
 	return nil
 }
 
@@ -165,6 +698,24 @@ func (c *LoggerConfig) Validate() error {
 	if !valid {
 		return fmt.Errorf("logger format must be 'json' or 'console'")
 	}
+	if c.MaxSizeMB < 0 {
+		return fmt.Errorf("logger max_size_mb must not be negative")
+	}
+	if c.MaxBackups < 0 {
+		return fmt.Errorf("logger max_backups must not be negative")
+	}
+	if c.MaxAgeDays < 0 {
+		return fmt.Errorf("logger max_age_days must not be negative")
+	}
+	if c.Sampling.Initial < 0 || c.Sampling.Thereafter < 0 {
+		return fmt.Errorf("logger sampling initial and thereafter must not be negative")
+	}
+	if c.SlowQueryThreshold < 0 {
+		return fmt.Errorf("logger slow_query_threshold must not be negative")
+	}
+	if c.MaxSizeMB == 0 {
+		c.MaxSizeMB = 100 // default value
+	}
 	return nil
 }
 
@@ -176,9 +727,41 @@ func (c *Config) Validate() error {
 	if err := c.MasterDatabase.Validate(); err != nil {
 		return fmt.Errorf("validate master database config: %w", err)
 	}
+	for i := range c.ReadReplicas {
+		if err := c.ReadReplicas[i].Validate(); err != nil {
+			return fmt.Errorf("validate read replica %d config: %w", i, err)
+		}
+	}
 	if err := c.TenantDatabase.Validate(); err != nil {
 		return fmt.Errorf("validate tenant database config: %w", err)
 	}
+	if err := c.TenantConnections.Validate(); err != nil {
+		return fmt.Errorf("validate tenant connections config: %w", err)
+	}
+	if err := c.Health.Validate(); err != nil {
+		return fmt.Errorf("validate health config: %w", err)
+	}
+	if err := c.Encryption.Validate(); err != nil {
+		return fmt.Errorf("validate encryption config: %w", err)
+	}
+	if err := c.Tracing.Validate(); err != nil {
+		return fmt.Errorf("validate tracing config: %w", err)
+	}
+	if err := c.Bulk.Validate(); err != nil {
+		return fmt.Errorf("validate bulk config: %w", err)
+	}
+	if err := c.RateLimit.Validate(); err != nil {
+		return fmt.Errorf("validate rate limit config: %w", err)
+	}
+	if err := c.Idempotency.Validate(); err != nil {
+		return fmt.Errorf("validate idempotency config: %w", err)
+	}
+	if err := c.Outbox.Validate(); err != nil {
+		return fmt.Errorf("validate outbox config: %w", err)
+	}
+	if err := c.Cache.Validate(); err != nil {
+		return fmt.Errorf("validate cache config: %w", err)
+	}
 	if err := c.JWT.Validate(); err != nil {
 		return fmt.Errorf("validate jwt config: %w", err)
 	}
@@ -191,40 +774,245 @@ func (c *Config) Validate() error {
 	return nil
 }
 
-// LoadConfig loads and validates configuration from file and environment
+// Config file formats accepted by LoadConfigWithFormat
+const (
+	ConfigFormatYAML = "yaml"
+	ConfigFormatJSON = "json"
+	ConfigFormatTOML = "toml"
+)
+
+// envPrefix prefixes the environment variables that override config keys
+const envPrefix = "MYAPP"
+
+// requiredKeys have no usable default and must be set by the config file or the environment
+var requiredKeys = []string{
+	"master_database.driver",
+	"master_database.host",
+	"master_database.name",
+	"master_database.user",
+	"master_database.password",
+	"tenant_database.driver",
+	"tenant_database.host",
+	"tenant_database.name",
+	"tenant_database.user",
+	"tenant_database.password",
+	"jwt.secret",
+	"auth.rsa_private_key_path",
+	"auth.rsa_public_key_path",
+}
+
+// MissingKeysError lists every required config key that was left empty
+type MissingKeysError struct {
+	Keys []string
+}
+
+func (e *MissingKeysError) Error() string {
+	missing := make([]string, len(e.Keys))
+	for i, key := range e.Keys {
+		missing[i] = fmt.Sprintf("%s (%s)", key, EnvVarName(key))
+	}
+	return "missing required config: " + strings.Join(missing, ", ")
+}
+
+// EnvVarName returns the environment variable that overrides key, e.g. MYAPP_MASTER_DATABASE_PASSWORD
+func EnvVarName(key string) string {
+	return envPrefix + "_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// configKeys lists the dotted mapstructure keys of every leaf field in t
+func configKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("mapstructure")
+		if tag == "" {
+			continue
+		}
+		key := prefix + tag
+		if field.Type.Kind() == reflect.Struct {
+			keys = append(keys, configKeys(field.Type, key+".")...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// checkRequiredKeys returns a MissingKeysError naming every required key that is empty
+func checkRequiredKeys(v *viper.Viper) error {
+	var missing []string
+	for _, key := range requiredKeys {
+		if strings.TrimSpace(v.GetString(key)) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingKeysError{Keys: missing}
+	}
+	return nil
+}
+
+// DotEnvFile is loaded into the environment, if it exists, before MYAPP_ variables are read
+const DotEnvFile = ".env"
+
+// LoadConfig loads and validates configuration from file and environment.
+// The file format is detected from the extension: .yaml, .yml, .json or .toml.
 func LoadConfig(configPath string) (*Config, error) {
+	return LoadConfigWithFormat(configPath, "")
+}
+
+// DetectConfigFormat returns the config format for a file extension
+func DetectConfigFormat(configPath string) (string, error) {
+	switch strings.ToLower(filepath.Ext(configPath)) {
+	case ".yaml", ".yml":
+		return ConfigFormatYAML, nil
+	case ".json":
+		return ConfigFormatJSON, nil
+	case ".toml":
+		return ConfigFormatTOML, nil
+	default:
+		return "", fmt.Errorf("unsupported config file extension %q: use .yaml, .yml, .json or .toml", filepath.Ext(configPath))
+	}
+}
+
+// LoadDotEnv sets environment variables from a KEY=value file. Variables already set in the
+// environment win, and a missing file is not an error.
+func LoadDotEnv(path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err := gotenv.Load(path); err != nil {
+		return fmt.Errorf("load env file %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadConfigWithFormat loads and validates configuration from a file in the given format
+// (yaml, json or toml), then DotEnvFile and the environment. An empty format is detected from the extension.
+func LoadConfigWithFormat(configPath, format string) (*Config, error) {
 	v := viper.New()
 	
 	// Set defaults
 	v.SetDefault("server.host", "0.0.0.0")
 	v.SetDefault("server.port", 8080)
+	v.SetDefault("server.request_timeout_seconds", 30)
+	v.SetDefault("server.max_body_bytes", 1<<20) // 1 MB
+	v.SetDefault("server.shutdown_timeout_seconds", 10)
+	v.SetDefault("server.compression_enabled", false)
+	v.SetDefault("server.compression_min_length", 1024)
+	v.SetDefault("server.metrics_enabled", false)
+	v.SetDefault("server.pprof_enabled", false)
+	v.SetDefault("server.delete_response", DeleteResponseNoContent)
+	v.SetDefault("server.unknown_fields", UnknownFieldsIgnore)
+	v.SetDefault("server.security_headers.content_type_options", "nosniff")
+	v.SetDefault("server.security_headers.frame_options", "DENY")
+	v.SetDefault("server.security_headers.referrer_policy", "no-referrer")
+	v.SetDefault("server.security_headers.content_security_policy", "default-src 'none'; frame-ancestors 'none'")
+	v.SetDefault("server.security_headers.hsts_max_age", 31536000) // 1 year
+	v.SetDefault("server.security_headers.hsts_include_subdomains", false)
+	v.SetDefault("server.security_headers.hsts_preload", false)
+	v.SetDefault("master_database.ssl_mode", SSLModeDisable)
+	v.SetDefault("tenant_database.ssl_mode", SSLModeDisable)
+	v.SetDefault("master_database.auto_migrate", false)
+	v.SetDefault("tenant_database.auto_migrate", false)
+	v.SetDefault("master_database.connect_retries", 3)
+	v.SetDefault("tenant_database.connect_retries", 3)
+	v.SetDefault("master_database.connect_backoff", "500ms")
+	v.SetDefault("tenant_database.connect_backoff", "500ms")
+	v.SetDefault("tenant_connections.max_cached", 100)
+	v.SetDefault("tenant_connections.idle_timeout", "30m")
+	v.SetDefault("tenant_connections.close_grace", "30s")
+	v.SetDefault("tenant_connections.strict_context", false)
+	v.SetDefault("tenant_connections.connect_retries", 2)
+	v.SetDefault("tenant_connections.connect_backoff", "200ms")
+	v.SetDefault("normalization.lowercase_codes", false)
+	v.SetDefault("bulk.concurrency", 4)
+	v.SetDefault("bulk.max_batch_size", 100)
+	v.SetDefault("health.critical_dependencies", []string{HealthDependencyMasterDatabase})
+	v.SetDefault("health.min_free_disk_mb", 100)
+	v.SetDefault("encryption.per_tenant_keys", true)
+	v.SetDefault("tracing.service_name", "myapp")
+	v.SetDefault("tracing.sample_ratio", 1.0)
+	v.SetDefault("rate_limit.requests_per_second", 20)
+	v.SetDefault("rate_limit.expires_in", "3m")
+	v.SetDefault("idempotency.ttl", "24h")
+	v.SetDefault("outbox.poll_interval", "5s")
+	v.SetDefault("outbox.batch_size", 100)
+	v.SetDefault("outbox.max_attempts", 10)
+	v.SetDefault("cache.ttl", "5m")
+	v.SetDefault("cache.timeout", "200ms")
 	v.SetDefault("logger.level", "info")
 	v.SetDefault("logger.format", "json")
+	v.SetDefault("logger.max_size_mb", 100)
+	v.SetDefault("logger.slow_query_threshold", "200ms")
 	v.SetDefault("jwt.expiration_hours", 24)
 	v.SetDefault("auth.access_token_duration", "15m")
 	v.SetDefault("auth.refresh_token_duration", "168h") // 7 days
 	v.SetDefault("auth.issuer", "myapp-auth-service")
 	v.SetDefault("auth.bcrypt_cost", 12)
+	v.SetDefault("auth.password_algorithm", PasswordAlgorithmBcrypt)
+	v.SetDefault("auth.token_mode", TokenModeJWT)
+	v.SetDefault("auth.require_email_verification", false)
+	v.SetDefault("auth.verification_token_duration", "24h")
+	v.SetDefault("auth.password_reset_token_duration", "1h")
+	v.SetDefault("auth.authorization_code_duration", "1m")
+	v.SetDefault("auth.cleanup_interval", "1h")
+	v.SetDefault("auth.max_failed_logins", 0)
+	v.SetDefault("auth.lockout_duration", "15m")
+	v.SetDefault("auth.webhook_max_attempts", 3)
+	v.SetDefault("auth.webhook_retry_backoff", "1s")
+	v.SetDefault("auth.replay_window", "5m")
+	v.SetDefault("auth.blacklist_cache_size", 10000)
+	v.SetDefault("auth.blacklist_cache_ttl", "30s")
 	
 	// Read config file if provided
 	if configPath != "" {
+		if format == "" {
+			detected, err := DetectConfigFormat(configPath)
+			if err != nil {
+				return nil, err
+			}
+			format = detected
+		}
+		switch format {
+		case ConfigFormatYAML, ConfigFormatJSON, ConfigFormatTOML:
+		default:
+			return nil, fmt.Errorf("unsupported config format %q: use yaml, json or toml", format)
+		}
+		
 		v.SetConfigFile(configPath)
+		v.SetConfigType(format)
 		if err := v.ReadInConfig(); err != nil {
 			return nil, fmt.Errorf("read config file %s: %w", configPath, err)
 		}
 	}
 	
-	// Environment variables support
-	v.SetEnvPrefix("MYAPP")
+	// Environment variables support, including those from the .env file
+	if err := LoadDotEnv(DotEnvFile); err != nil {
+		return nil, err
+	}
+	v.SetEnvPrefix(envPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 	
+	// Bind every key explicitly so it is read from the environment even when no file or default mentions it
+	for _, key := range configKeys(reflect.TypeOf(Config{}), "") {
+		if err := v.BindEnv(key); err != nil {
+			return nil, fmt.Errorf("bind env for %s: %w", key, err)
+		}
+	}
+	
 	// Unmarshal config
 	cfg := &Config{}
 	if err := v.Unmarshal(cfg); err != nil {
 		return nil, fmt.Errorf("unmarshal config: %w", err)
 	}
 	
+	// Report every missing required key at once, before Validate stops at the first problem
+	if err := checkRequiredKeys(v); err != nil {
+		return nil, err
+	}
+	
 	// Validate config
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("validate config: %w", err)
@@ -1,9 +1,13 @@
 package config
 
 import (
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestServerConfig_Validate tests ServerConfig validation
@@ -66,6 +70,104 @@ func TestServerConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "server port must be between 1 and 65535",
 		},
+		{
+			name: "negative request timeout",
+			config: ServerConfig{
+				Host:                  "0.0.0.0",
+				Port:                  8080,
+				RequestTimeoutSeconds: -1,
+			},
+			wantErr: true,
+			errMsg:  "server request_timeout_seconds must not be negative",
+		},
+		{
+			name: "negative max body bytes",
+			config: ServerConfig{
+				Host:         "0.0.0.0",
+				Port:         8080,
+				MaxBodyBytes: -1,
+			},
+			wantErr: true,
+			errMsg:  "server max_body_bytes must not be negative",
+		},
+		{
+			name: "negative hsts max age",
+			config: ServerConfig{
+				Host:            "0.0.0.0",
+				Port:            8080,
+				SecurityHeaders: SecurityHeadersConfig{HSTSMaxAge: -1},
+			},
+			wantErr: true,
+			errMsg:  "server security_headers hsts_max_age must not be negative",
+		},
+		{
+			name: "invalid delete response",
+			config: ServerConfig{
+				Host:           "0.0.0.0",
+				Port:           8080,
+				DeleteResponse: "accepted",
+			},
+			wantErr: true,
+			errMsg:  "server delete_response must be one of: no_content, structured, message",
+		},
+		{
+			name: "invalid unknown fields mode",
+			config: ServerConfig{
+				Host:          "0.0.0.0",
+				Port:          8080,
+				UnknownFields: "drop",
+			},
+			wantErr: true,
+			errMsg:  "server unknown_fields must be one of: ignore, reject",
+		},
+		{
+			name: "cors credentials with explicit origins",
+			config: ServerConfig{
+				Host: "0.0.0.0",
+				Port: 8080,
+				CORS: CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowCredentials: true},
+			},
+			wantErr: false,
+		},
+		{
+			name: "cors credentials with wildcard origin",
+			config: ServerConfig{
+				Host: "0.0.0.0",
+				Port: 8080,
+				CORS: CORSConfig{AllowedOrigins: []string{"https://app.example.com", "*"}, AllowCredentials: true},
+			},
+			wantErr: true,
+			errMsg:  `server cors allow_credentials cannot be combined with a "*" origin`,
+		},
+		{
+			name: "cors credentials without origins",
+			config: ServerConfig{
+				Host: "0.0.0.0",
+				Port: 8080,
+				CORS: CORSConfig{AllowCredentials: true},
+			},
+			wantErr: true,
+			errMsg:  "server cors allow_credentials requires explicit allowed_origins",
+		},
+		{
+			name: "trusted proxies as CIDR ranges",
+			config: ServerConfig{
+				Host:           "0.0.0.0",
+				Port:           8080,
+				TrustedProxies: []string{"10.0.0.0/8", "2001:db8::/32"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "trusted proxy without a prefix length",
+			config: ServerConfig{
+				Host:           "0.0.0.0",
+				Port:           8080,
+				TrustedProxies: []string{"10.0.0.1"},
+			},
+			wantErr: true,
+			errMsg:  `server trusted_proxies entry "10.0.0.1" is not a CIDR range`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -222,6 +324,73 @@ func TestDatabaseConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "database user is required",
 		},
+		{
+			name: "valid ssl mode require",
+			config: DatabaseConfig{
+				Driver:   "postgres",
+				Host:     "localhost",
+				Port:     5432,
+				Name:     "mydb",
+				User:     "user",
+				Password: "pass",
+				SSLMode:  "require",
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid ssl mode verify-full",
+			config: DatabaseConfig{
+				Driver:   "postgres",
+				Host:     "localhost",
+				Port:     5432,
+				Name:     "mydb",
+				User:     "user",
+				Password: "pass",
+				SSLMode:  "verify-full",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid ssl mode",
+			config: DatabaseConfig{
+				Driver:   "postgres",
+				Host:     "localhost",
+				Port:     5432,
+				Name:     "mydb",
+				User:     "user",
+				Password: "pass",
+				SSLMode:  "prefer",
+			},
+			wantErr: true,
+			errMsg:  "database ssl_mode must be one of",
+		},
+		{
+			name: "invalid ssl mode casing",
+			config: DatabaseConfig{
+				Driver:   "postgres",
+				Host:     "localhost",
+				Port:     5432,
+				Name:     "mydb",
+				User:     "user",
+				Password: "pass",
+				SSLMode:  "REQUIRE",
+			},
+			wantErr: true,
+			errMsg:  "database ssl_mode must be one of",
+		},
+		{
+			name: "negative connect retries",
+			config: DatabaseConfig{
+				Driver:         "postgres",
+				Host:           "localhost",
+				Port:           5432,
+				Name:           "mydb",
+				User:           "user",
+				ConnectRetries: -1,
+			},
+			wantErr: true,
+			errMsg:  "database connect_retries must not be negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -239,11 +408,20 @@ func TestDatabaseConfig_Validate(t *testing.T) {
 				if tt.config.MaxIdleConns == 0 {
 					assert.Equal(t, 5, tt.config.MaxIdleConns)
 				}
+				assert.NotEmpty(t, tt.config.SSLMode)
+				assert.Positive(t, tt.config.ConnectBackoff)
 			}
 		})
 	}
 }
 
+// TestDatabaseConfig_Validate_DefaultSSLMode tests that an unset ssl mode defaults to disable
+func TestDatabaseConfig_Validate_DefaultSSLMode(t *testing.T) {
+	cfg := DatabaseConfig{Driver: "postgres", Host: "localhost", Port: 5432, Name: "mydb", User: "user"}
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, SSLModeDisable, cfg.SSLMode)
+}
+
 // TestJWTConfig_Validate tests JWTConfig validation
 func TestJWTConfig_Validate(t *testing.T) {
 	tests := []struct {
@@ -313,6 +491,69 @@ func TestJWTConfig_Validate(t *testing.T) {
 	}
 }
 
+// TestTenantConnectionsConfig_Validate tests TenantConnectionsConfig validation
+func TestTenantConnectionsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TenantConnectionsConfig
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:    "valid tenant connections config",
+			config:  TenantConnectionsConfig{MaxCached: 100, IdleTimeout: 30 * time.Minute},
+			wantErr: false,
+		},
+		{
+			name:    "unlimited and no idle timeout",
+			config:  TenantConnectionsConfig{},
+			wantErr: false,
+		},
+		{
+			name:    "negative max cached",
+			config:  TenantConnectionsConfig{MaxCached: -1},
+			wantErr: true,
+			errMsg:  "tenant_connections max_cached must not be negative",
+		},
+		{
+			name:    "negative idle timeout",
+			config:  TenantConnectionsConfig{IdleTimeout: -time.Second},
+			wantErr: true,
+			errMsg:  "tenant_connections idle_timeout must not be negative",
+		},
+		{
+			name:    "negative close grace",
+			config:  TenantConnectionsConfig{CloseGrace: -time.Second},
+			wantErr: true,
+			errMsg:  "tenant_connections close_grace must not be negative",
+		},
+		{
+			name:    "negative connect retries",
+			config:  TenantConnectionsConfig{ConnectRetries: -1},
+			wantErr: true,
+			errMsg:  "tenant_connections connect_retries must not be negative",
+		},
+		{
+			name:    "negative connect backoff",
+			config:  TenantConnectionsConfig{ConnectRetries: 2, ConnectBackoff: -time.Millisecond},
+			wantErr: true,
+			errMsg:  "tenant_connections connect_backoff must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 // TestLoggerConfig_Validate tests LoggerConfig validation
 func TestLoggerConfig_Validate(t *testing.T) {
 	tests := []struct {
@@ -387,6 +628,68 @@ func TestLoggerConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "logger format must be 'json' or 'console'",
 		},
+		{
+			name: "valid file output",
+			config: LoggerConfig{
+				Level:      "info",
+				Format:     "json",
+				OutputPath: "logs/myapp.log",
+				MaxSizeMB:  50,
+				MaxBackups: 3,
+				MaxAgeDays: 7,
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative max size",
+			config: LoggerConfig{
+				Level:     "info",
+				Format:    "json",
+				MaxSizeMB: -1,
+			},
+			wantErr: true,
+			errMsg:  "logger max_size_mb must not be negative",
+		},
+		{
+			name: "negative max backups",
+			config: LoggerConfig{
+				Level:      "info",
+				Format:     "json",
+				MaxBackups: -1,
+			},
+			wantErr: true,
+			errMsg:  "logger max_backups must not be negative",
+		},
+		{
+			name: "negative max age",
+			config: LoggerConfig{
+				Level:      "info",
+				Format:     "json",
+				MaxAgeDays: -1,
+			},
+			wantErr: true,
+			errMsg:  "logger max_age_days must not be negative",
+		},
+		{
+			name: "negative sampling",
+			config: LoggerConfig{
+				Level:    "info",
+				Format:   "json",
+				Sampling: LogSamplingConfig{Initial: 100, Thereafter: -1},
+			},
+			wantErr: true,
+			errMsg:  "logger sampling initial and thereafter must not be negative",
+		},
+		{
+			name: "negative slow query threshold",
+			config: LoggerConfig{
+				Level:              "info",
+				Format:             "json",
+				SlowQueryThreshold: -time.Millisecond,
+			},
+			wantErr: true,
+			errMsg:  "logger slow_query_threshold must not be negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -402,6 +705,275 @@ func TestLoggerConfig_Validate(t *testing.T) {
 	}
 }
 
+// TestBulkConfig_Validate tests BulkConfig validation
+func TestBulkConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       BulkConfig
+		wantErr      bool
+		errMsg       string
+		concurrency  int
+		maxBatchSize int
+	}{
+		{
+			name:         "explicit concurrency",
+			config:       BulkConfig{Concurrency: 16, MaxBatchSize: 500},
+			concurrency:  16,
+			maxBatchSize: 500,
+		},
+		{
+			name:         "default concurrency",
+			config:       BulkConfig{},
+			concurrency:  4,
+			maxBatchSize: 100,
+		},
+		{
+			name:    "negative concurrency",
+			config:  BulkConfig{Concurrency: -1},
+			wantErr: true,
+			errMsg:  "bulk concurrency must not be negative",
+		},
+		{
+			name:    "negative max batch size",
+			config:  BulkConfig{MaxBatchSize: -1},
+			wantErr: true,
+			errMsg:  "bulk max_batch_size must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.concurrency, tt.config.Concurrency)
+				assert.Equal(t, tt.maxBatchSize, tt.config.MaxBatchSize)
+			}
+		})
+	}
+}
+
+// TestOutboxConfig_Validate tests OutboxConfig validation and defaults
+func TestOutboxConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       OutboxConfig
+		wantErr      bool
+		errMsg       string
+		pollInterval time.Duration
+		batchSize    int
+		maxAttempts  int
+	}{
+		{
+			name:         "explicit values",
+			config:       OutboxConfig{PollInterval: time.Second, BatchSize: 10, MaxAttempts: 3},
+			pollInterval: time.Second,
+			batchSize:    10,
+			maxAttempts:  3,
+		},
+		{
+			name:         "defaults",
+			config:       OutboxConfig{},
+			pollInterval: 5 * time.Second,
+			batchSize:    100,
+			maxAttempts:  10,
+		},
+		{
+			name:    "negative poll interval",
+			config:  OutboxConfig{PollInterval: -time.Second},
+			wantErr: true,
+			errMsg:  "outbox poll_interval must not be negative",
+		},
+		{
+			name:    "negative batch size",
+			config:  OutboxConfig{BatchSize: -1},
+			wantErr: true,
+			errMsg:  "outbox batch_size must not be negative",
+		},
+		{
+			name:    "negative max attempts",
+			config:  OutboxConfig{MaxAttempts: -1},
+			wantErr: true,
+			errMsg:  "outbox max_attempts must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.pollInterval, tt.config.PollInterval)
+				assert.Equal(t, tt.batchSize, tt.config.BatchSize)
+				assert.Equal(t, tt.maxAttempts, tt.config.MaxAttempts)
+			}
+		})
+	}
+}
+
+// TestCacheConfig_Validate tests CacheConfig validation and defaults
+func TestCacheConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CacheConfig
+		wantErr bool
+		errMsg  string
+		ttl     time.Duration
+		timeout time.Duration
+	}{
+		{
+			name:    "explicit values",
+			config:  CacheConfig{RedisAddr: "localhost:6379", TTL: time.Minute, Timeout: time.Second},
+			ttl:     time.Minute,
+			timeout: time.Second,
+		},
+		{
+			name:    "defaults",
+			config:  CacheConfig{},
+			ttl:     5 * time.Minute,
+			timeout: 200 * time.Millisecond,
+		},
+		{
+			name:    "negative redis db",
+			config:  CacheConfig{RedisDB: -1},
+			wantErr: true,
+			errMsg:  "cache redis_db must not be negative",
+		},
+		{
+			name:    "negative ttl",
+			config:  CacheConfig{TTL: -time.Second},
+			wantErr: true,
+			errMsg:  "cache ttl must not be negative",
+		},
+		{
+			name:    "negative timeout",
+			config:  CacheConfig{Timeout: -time.Second},
+			wantErr: true,
+			errMsg:  "cache timeout must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.ttl, tt.config.TTL)
+				assert.Equal(t, tt.timeout, tt.config.Timeout)
+			}
+		})
+	}
+}
+
+// TestEncryptionConfig_Validate tests EncryptionConfig validation
+func TestEncryptionConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  EncryptionConfig
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:   "encryption disabled",
+			config: EncryptionConfig{},
+		},
+		{
+			name:   "32-byte key",
+			config: EncryptionConfig{MasterKey: "QkJCQkJCQkJCQkJCQkJCQkJCQkJCQkJCQkJCQkJCQkI=", PerTenantKeys: true},
+		},
+		{
+			name:    "not base64",
+			config:  EncryptionConfig{MasterKey: "not base64!"},
+			wantErr: true,
+			errMsg:  "encryption master_key must be base64 encoded",
+		},
+		{
+			name:    "wrong key length",
+			config:  EncryptionConfig{MasterKey: "c2hvcnQ="},
+			wantErr: true,
+			errMsg:  "encryption master_key must decode to 32 bytes, got 5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestHealthConfig_Validate tests HealthConfig validation
+func TestHealthConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   HealthConfig
+		wantErr  bool
+		errMsg   string
+		critical []string
+	}{
+		{
+			name:     "default critical dependencies",
+			config:   HealthConfig{},
+			critical: []string{HealthDependencyMasterDatabase},
+		},
+		{
+			name:     "explicitly nothing critical",
+			config:   HealthConfig{CriticalDependencies: []string{}},
+			critical: []string{},
+		},
+		{
+			name:     "tenant databases critical",
+			config:   HealthConfig{CriticalDependencies: []string{HealthDependencyMasterDatabase, HealthDependencyTenantDatabases}},
+			critical: []string{HealthDependencyMasterDatabase, HealthDependencyTenantDatabases},
+		},
+		{
+			name:     "disk critical",
+			config:   HealthConfig{CriticalDependencies: []string{HealthDependencyDisk}, DiskPath: "/var/lib/myapp"},
+			critical: []string{HealthDependencyDisk},
+		},
+		{
+			name:    "unknown dependency",
+			config:  HealthConfig{CriticalDependencies: []string{"redis"}},
+			wantErr: true,
+			errMsg:  "got: redis",
+		},
+		{
+			name:    "negative free disk threshold",
+			config:  HealthConfig{MinFreeDiskMB: -1},
+			wantErr: true,
+			errMsg:  "health min_free_disk_mb must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.critical, tt.config.CriticalDependencies)
+			}
+		})
+	}
+}
+
 // TestConfig_Validate tests full Config validation
 func TestConfig_Validate(t *testing.T) {
 	validConfig := &Config{
@@ -433,6 +1005,10 @@ func TestConfig_Validate(t *testing.T) {
 			Secret:          "this-is-a-very-long-secret-key-with-at-least-32-characters",
 			ExpirationHours: 24,
 		},
+		Auth: AuthConfig{
+			RSAPrivateKeyPath: "keys/private.pem",
+			RSAPublicKeyPath:  "keys/public.pem",
+		},
 		Logger: LoggerConfig{
 			Level:  "info",
 			Format: "json",
@@ -460,6 +1036,25 @@ func TestConfig_Validate(t *testing.T) {
 		assert.Contains(t, err.Error(), "validate master database config")
 	})
 
+	t.Run("read replicas get database defaults", func(t *testing.T) {
+		cfg := *validConfig
+		cfg.ReadReplicas = []DatabaseConfig{{Driver: "postgres", Host: "replica-1", Port: 5432, Name: "master_db", User: "user"}}
+		require.NoError(t, cfg.Validate())
+		assert.Equal(t, 25, cfg.ReadReplicas[0].MaxOpenConns)
+		assert.Equal(t, SSLModeDisable, cfg.ReadReplicas[0].SSLMode)
+	})
+
+	t.Run("invalid read replica config", func(t *testing.T) {
+		cfg := *validConfig
+		cfg.ReadReplicas = []DatabaseConfig{
+			{Driver: "postgres", Host: "replica-1", Port: 5432, Name: "master_db", User: "user"},
+			{Driver: "postgres", Port: 5432, Name: "master_db", User: "user"},
+		}
+		err := cfg.Validate()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "validate read replica 1 config")
+	})
+
 	t.Run("invalid tenant database config", func(t *testing.T) {
 		cfg := *validConfig
 		cfg.TenantDatabase.Name = ""
@@ -505,3 +1100,358 @@ func TestLoadConfig(t *testing.T) {
 		t.Skip("Environment variable configuration tested through integration tests")
 	})
 }
+
+// unsetEnv clears every MYAPP_ variable for the duration of the test
+func unsetEnv(t *testing.T) {
+	t.Helper()
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(name, "MYAPP_") {
+			t.Setenv(name, "") // Restores the original value after the test
+			require.NoError(t, os.Unsetenv(name))
+		}
+	}
+}
+
+// TestLoadConfig_MissingRequiredKeys tests that every missing required key is reported in one error
+func TestLoadConfig_MissingRequiredKeys(t *testing.T) {
+	unsetEnv(t)
+
+	t.Run("no file and no environment", func(t *testing.T) {
+		cfg, err := LoadConfig("")
+		require.Error(t, err)
+		assert.Nil(t, cfg)
+
+		var missing *MissingKeysError
+		require.ErrorAs(t, err, &missing)
+		assert.Equal(t, requiredKeys, missing.Keys)
+		for _, key := range requiredKeys {
+			assert.Contains(t, err.Error(), key)
+			assert.Contains(t, err.Error(), EnvVarName(key))
+		}
+		assert.Contains(t, err.Error(), "MYAPP_MASTER_DATABASE_PASSWORD")
+	})
+
+	t.Run("environment fills required keys", func(t *testing.T) {
+		t.Setenv("MYAPP_MASTER_DATABASE_DRIVER", "postgres")
+		t.Setenv("MYAPP_MASTER_DATABASE_HOST", "localhost")
+		t.Setenv("MYAPP_MASTER_DATABASE_NAME", "master_db")
+		t.Setenv("MYAPP_MASTER_DATABASE_USER", "app")
+		t.Setenv("MYAPP_MASTER_DATABASE_PASSWORD", "secret")
+		t.Setenv("MYAPP_TENANT_DATABASE_DRIVER", "postgres")
+		t.Setenv("MYAPP_TENANT_DATABASE_HOST", "localhost")
+		t.Setenv("MYAPP_TENANT_DATABASE_NAME", "tenant_db")
+		t.Setenv("MYAPP_TENANT_DATABASE_USER", "app")
+
+		_, err := LoadConfig("")
+		require.Error(t, err)
+		var missing *MissingKeysError
+		require.ErrorAs(t, err, &missing)
+		assert.Equal(t, []string{"tenant_database.password", "jwt.secret", "auth.rsa_private_key_path", "auth.rsa_public_key_path"}, missing.Keys)
+
+		t.Setenv("MYAPP_TENANT_DATABASE_PASSWORD", "secret")
+		t.Setenv("MYAPP_JWT_SECRET", "this-is-a-very-long-secret-key-with-at-least-32-characters")
+		t.Setenv("MYAPP_AUTH_RSA_PRIVATE_KEY_PATH", "keys/private.pem")
+		t.Setenv("MYAPP_AUTH_RSA_PUBLIC_KEY_PATH", "keys/public.pem")
+
+		t.Setenv("MYAPP_MASTER_DATABASE_PORT", "5432")
+		t.Setenv("MYAPP_TENANT_DATABASE_PORT", "5432")
+
+		cfg, err := LoadConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", cfg.MasterDatabase.Password)
+		assert.Equal(t, 5432, cfg.TenantDatabase.Port)
+		assert.Equal(t, "keys/public.pem", cfg.Auth.RSAPublicKeyPath)
+	})
+}
+
+// TestLoadConfig_Formats tests that the same config in YAML, JSON and TOML parses to identical structs
+func TestLoadConfig_Formats(t *testing.T) {
+	want, err := LoadConfig("testdata/config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, 9090, want.Server.Port)
+	assert.Equal(t, []string{"https://app.example.com"}, want.Server.CORS.AllowedOrigins)
+	assert.Equal(t, 10*time.Minute, want.Auth.AccessTokenDuration)
+	assert.Equal(t, "debug", want.Logger.Level)
+
+	tests := []struct {
+		name   string
+		path   string
+		format string
+	}{
+		{name: "json by extension", path: "testdata/config.json"},
+		{name: "toml by extension", path: "testdata/config.toml"},
+		{name: "explicit json format", path: "testdata/config.conf", format: ConfigFormatJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadConfigWithFormat(tt.path, tt.format)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	t.Run("unknown extension", func(t *testing.T) {
+		_, err := LoadConfig("testdata/config.conf")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported config file extension")
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := LoadConfigWithFormat("testdata/config.yaml", "ini")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported config format")
+	})
+}
+
+// TestLoadDotEnv tests that a .env file fills unset variables, which then override the config file
+func TestLoadDotEnv(t *testing.T) {
+	// Register cleanup for the variable, then unset it so the .env file can provide it
+	t.Setenv("MYAPP_LOGGER_LEVEL", "")
+	require.NoError(t, os.Unsetenv("MYAPP_LOGGER_LEVEL"))
+
+	require.NoError(t, LoadDotEnv("testdata/test.env"))
+	assert.Equal(t, "warn", os.Getenv("MYAPP_LOGGER_LEVEL"))
+
+	cfg, err := LoadConfig("testdata/config.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "warn", cfg.Logger.Level)
+
+	t.Run("existing variables win", func(t *testing.T) {
+		t.Setenv("MYAPP_LOGGER_LEVEL", "error")
+		require.NoError(t, LoadDotEnv("testdata/test.env"))
+		assert.Equal(t, "error", os.Getenv("MYAPP_LOGGER_LEVEL"))
+	})
+
+	t.Run("missing file is ignored", func(t *testing.T) {
+		assert.NoError(t, LoadDotEnv("testdata/missing.env"))
+	})
+}
+
+func TestRateLimitConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    RateLimitConfig
+		wantErr   bool
+		errMsg    string
+		wantRate  float64
+		wantBurst int
+	}{
+		{
+			name:      "defaults",
+			config:    RateLimitConfig{},
+			wantRate:  20,
+			wantBurst: 20,
+		},
+		{
+			name:      "burst defaults to the rate rounded up",
+			config:    RateLimitConfig{RequestsPerSecond: 2.5},
+			wantRate:  2.5,
+			wantBurst: 3,
+		},
+		{
+			name:      "explicit burst",
+			config:    RateLimitConfig{RequestsPerSecond: 5, Burst: 50},
+			wantRate:  5,
+			wantBurst: 50,
+		},
+		{
+			name:    "negative rate",
+			config:  RateLimitConfig{RequestsPerSecond: -1},
+			wantErr: true,
+			errMsg:  "rate_limit requests_per_second must not be negative",
+		},
+		{
+			name:    "negative burst",
+			config:  RateLimitConfig{Burst: -1},
+			wantErr: true,
+			errMsg:  "rate_limit burst must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantRate, tt.config.RequestsPerSecond)
+				assert.Equal(t, tt.wantBurst, tt.config.Burst)
+				assert.Equal(t, 3*time.Minute, tt.config.ExpiresIn)
+			}
+		})
+	}
+}
+
+func TestIdempotencyConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  IdempotencyConfig
+		wantErr bool
+		errMsg  string
+		wantTTL time.Duration
+	}{
+		{
+			name:    "defaults",
+			config:  IdempotencyConfig{},
+			wantTTL: 24 * time.Hour,
+		},
+		{
+			name:    "explicit ttl",
+			config:  IdempotencyConfig{TTL: time.Hour},
+			wantTTL: time.Hour,
+		},
+		{
+			name:    "negative ttl",
+			config:  IdempotencyConfig{TTL: -time.Second},
+			wantErr: true,
+			errMsg:  "idempotency ttl must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantTTL, tt.config.TTL)
+			}
+		})
+	}
+}
+
+func TestTracingConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TracingConfig
+		wantErr bool
+		errMsg  string
+	}{
+		{
+			name:   "tracing disabled",
+			config: TracingConfig{},
+		},
+		{
+			name:   "collector with full sampling",
+			config: TracingConfig{OTLPEndpoint: "localhost:4318", SampleRatio: 1},
+		},
+		{
+			name:    "negative sample ratio",
+			config:  TracingConfig{SampleRatio: -0.1},
+			wantErr: true,
+			errMsg:  "tracing sample_ratio must be between 0 and 1",
+		},
+		{
+			name:    "sample ratio above one",
+			config:  TracingConfig{SampleRatio: 1.5},
+			wantErr: true,
+			errMsg:  "tracing sample_ratio must be between 0 and 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "myapp", tt.config.ServiceName)
+			}
+		})
+	}
+}
+
+func TestAuthConfig_Validate_TokenMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		wantErr  bool
+		wantMode string
+	}{
+		{name: "default", mode: "", wantMode: TokenModeJWT},
+		{name: "jwt", mode: TokenModeJWT, wantMode: TokenModeJWT},
+		{name: "opaque", mode: TokenModeOpaque, wantMode: TokenModeOpaque},
+		{name: "unknown", mode: "paseto", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := AuthConfig{RSAPrivateKeyPath: "private.pem", RSAPublicKeyPath: "public.pem", TokenMode: tt.mode}
+			err := cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "token_mode")
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantMode, cfg.TokenMode)
+			}
+		})
+	}
+}
+
+func TestAuthConfig_Validate_PasswordAlgorithm(t *testing.T) {
+	tests := []struct {
+		name          string
+		algorithm     string
+		wantErr       bool
+		wantAlgorithm string
+	}{
+		{name: "default", algorithm: "", wantAlgorithm: PasswordAlgorithmBcrypt},
+		{name: "bcrypt", algorithm: PasswordAlgorithmBcrypt, wantAlgorithm: PasswordAlgorithmBcrypt},
+		{name: "argon2id", algorithm: PasswordAlgorithmArgon2id, wantAlgorithm: PasswordAlgorithmArgon2id},
+		{name: "unknown", algorithm: "scrypt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := AuthConfig{RSAPrivateKeyPath: "private.pem", RSAPublicKeyPath: "public.pem", PasswordAlgorithm: tt.algorithm}
+			err := cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "password_algorithm")
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantAlgorithm, cfg.PasswordAlgorithm)
+			}
+		})
+	}
+}
+
+func TestAuthConfig_Validate_BlacklistCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		ttl      time.Duration
+		wantErr  bool
+		wantSize int
+		wantTTL  time.Duration
+	}{
+		{name: "defaults", wantSize: 10000},
+		{name: "custom", size: 500, ttl: time.Minute, wantSize: 500, wantTTL: time.Minute},
+		{name: "negative size", size: -1, wantSize: 10000},
+		{name: "negative ttl", ttl: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := AuthConfig{RSAPrivateKeyPath: "private.pem", RSAPublicKeyPath: "public.pem", BlacklistCacheSize: tt.size, BlacklistCacheTTL: tt.ttl}
+			err := cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "blacklist_cache_ttl")
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantSize, cfg.BlacklistCacheSize)
+				assert.Equal(t, tt.wantTTL, cfg.BlacklistCacheTTL)
+			}
+		})
+	}
+}
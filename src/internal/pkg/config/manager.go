@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// ConfigManager holds the active configuration and reloads it from the config file when the file
+// changes or the process receives SIGHUP. A reloaded config replaces the active one only if it validates.
+type ConfigManager struct {
+	path string
+
+	mu          sync.RWMutex
+	current     *Config
+	subscribers []func(*Config)
+
+	reloadMu sync.Mutex // Serializes reloads from the file watcher and SIGHUP
+}
+
+// NewConfigManager loads the config at configPath; an empty path uses defaults and environment variables only
+func NewConfigManager(configPath string) (*ConfigManager, error) {
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfigManager{path: configPath, current: cfg}, nil
+}
+
+// Current returns the active configuration. The returned Config must not be modified;
+// a reload swaps in a new Config instead of changing this one.
+func (m *ConfigManager) Current() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// Subscribe registers fn to be called with the new configuration after every successful reload
+func (m *ConfigManager) Subscribe(fn func(*Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, fn)
+}
+
+// Reload reads and validates the config file again. On failure the active config is kept and the error returned.
+func (m *ConfigManager) Reload() error {
+	m.reloadMu.Lock()
+	defer m.reloadMu.Unlock()
+
+	cfg, err := LoadConfig(m.path)
+	if err != nil {
+		return fmt.Errorf("reload config: %w", err)
+	}
+
+	m.mu.Lock()
+	m.current = cfg
+	subscribers := append([]func(*Config){}, m.subscribers...)
+	m.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(cfg)
+	}
+	return nil
+}
+
+// Watch reloads the config whenever the file changes, reporting rejected reloads to onError.
+// It does nothing when the manager was created without a config file.
+func (m *ConfigManager) Watch(onError func(error)) {
+	if m.path == "" {
+		return
+	}
+
+	watcher := viper.New()
+	watcher.SetConfigFile(m.path)
+	watcher.OnConfigChange(func(fsnotify.Event) {
+		if err := m.Reload(); err != nil {
+			onError(err)
+		}
+	})
+	watcher.WatchConfig()
+}
+
+// WatchSignals reloads the config on SIGHUP, reporting rejected reloads to onError, until stop is called
+func (m *ConfigManager) WatchSignals(onError func(error)) (stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if err := m.Reload(); err != nil {
+					onError(err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
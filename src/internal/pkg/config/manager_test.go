@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestConfig writes a minimal valid config file with the given logger level.
+// The file is replaced with a rename so a watcher never reads it half written.
+func writeTestConfig(t *testing.T, path, level string) {
+	t.Helper()
+	content := fmt.Sprintf(`
+master_database:
+  driver: postgres
+  host: localhost
+  port: 5432
+  name: master_db
+  user: user
+  password: pass
+  max_open_conns: 25
+  max_idle_conns: 5
+tenant_database:
+  driver: postgres
+  host: localhost
+  port: 5432
+  name: tenant_db
+  user: user
+  password: pass
+  max_open_conns: 25
+  max_idle_conns: 5
+jwt:
+  secret: this-is-a-very-long-secret-key-with-at-least-32-characters
+auth:
+  rsa_private_key_path: keys/private.pem
+  rsa_public_key_path: keys/public.pem
+logger:
+  level: %s
+`, level)
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+// TestConfigManager_Watch tests that editing the config file reloads it and notifies subscribers
+func TestConfigManager_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeTestConfig(t, path, "info")
+
+	manager, err := NewConfigManager(path)
+	require.NoError(t, err)
+	assert.Equal(t, "info", manager.Current().Logger.Level)
+
+	levels := make(chan string, 10)
+	manager.Subscribe(func(cfg *Config) {
+		levels <- cfg.Logger.Level
+	})
+	reloadErrs := make(chan error, 10)
+	manager.Watch(func(err error) {
+		reloadErrs <- err
+	})
+
+	writeTestConfig(t, path, "debug")
+
+	select {
+	case level := <-levels:
+		assert.Equal(t, "debug", level)
+	case err := <-reloadErrs:
+		t.Fatalf("unexpected reload error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscriber was not called after the config file changed")
+	}
+	assert.Equal(t, "debug", manager.Current().Logger.Level)
+}
+
+// TestConfigManager_Reload tests that a config failing validation is rejected and the active config kept
+func TestConfigManager_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeTestConfig(t, path, "info")
+
+	manager, err := NewConfigManager(path)
+	require.NoError(t, err)
+	active := manager.Current()
+
+	calls := 0
+	manager.Subscribe(func(*Config) { calls++ })
+
+	t.Run("invalid config is rejected", func(t *testing.T) {
+		writeTestConfig(t, path, "verbose")
+
+		err := manager.Reload()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "validate logger config")
+		assert.Same(t, active, manager.Current())
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("valid config is swapped in", func(t *testing.T) {
+		writeTestConfig(t, path, "warn")
+
+		require.NoError(t, manager.Reload())
+		assert.Equal(t, "warn", manager.Current().Logger.Level)
+		assert.Equal(t, "info", active.Logger.Level, "the previous config is not modified")
+		assert.Equal(t, 1, calls)
+	})
+}
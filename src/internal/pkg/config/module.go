@@ -1,30 +1,69 @@
 package config
 
 import (
+	"context"
+
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
+// defaultConfigPath is the config file loaded by NewDefaultConfigManager
+const defaultConfigPath = "config/config.yaml"
+
 // Module exports config dependency
 var Module = fx.Options(
+	fx.Provide(NewDefaultConfigManager),
 	fx.Provide(NewConfig),
 	fx.Provide(NewAuthConfig), // Provide AuthConfig extracted from Config
+	fx.Invoke(RegisterReloadHooks),
 )
 
-// NewConfig creates a new Config instance
-func NewConfig() (*Config, error) {
+// NewDefaultConfigManager creates a ConfigManager for the default config file
+func NewDefaultConfigManager() (*ConfigManager, error) {
 	// Try to load config from default path
-	cfg, err := LoadConfig("config/config.yaml")
+	manager, err := NewConfigManager(defaultConfigPath)
 	if err != nil {
 		// If default config fails, try without config file (use env vars and defaults)
-		cfg, err = LoadConfig("")
+		manager, err = NewConfigManager("")
 		if err != nil {
 			return nil, err
 		}
 	}
-	return cfg, nil
+	return manager, nil
+}
+
+// NewConfig returns the configuration loaded at startup.
+// Components that must follow reloads use ConfigManager.Subscribe instead.
+func NewConfig(manager *ConfigManager) *Config {
+	return manager.Current()
 }
 
 // NewAuthConfig extracts AuthConfig from Config for dependency injection
 func NewAuthConfig(cfg *Config) *AuthConfig {
 	return &cfg.Auth
 }
+
+// RegisterReloadHooks starts watching the config file and SIGHUP when the app starts
+func RegisterReloadHooks(lc fx.Lifecycle, manager *ConfigManager, logger *zap.Logger) {
+	var stopSignals func()
+	onError := func(err error) {
+		logger.Error("Rejected configuration reload, keeping the active config", zap.Error(err))
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			manager.Subscribe(func(*Config) {
+				logger.Info("Configuration reloaded")
+			})
+			manager.Watch(onError)
+			stopSignals = manager.WatchSignals(onError)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if stopSignals != nil {
+				stopSignals()
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// CreatedByField is the model field set to the acting user on insert
+	CreatedByField = "CreatedBy"
+	// UpdatedByField is the model field set to the acting user on insert and update
+	UpdatedByField = "UpdatedBy"
+)
+
+// stampUser sets the named fields of each entity to the user ID carried by db's context. Entities are left
+// untouched when the context has no user or the model lacks an unsigned field of that name, so system
+// writes keep zero.
+func stampUser[T any](db *gorm.DB, entities []*T, fields ...string) error {
+	userID, ok := GetUserID(db.Statement.Context)
+	if !ok || len(entities) == 0 {
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return fmt.Errorf("parse model schema: %w", err)
+	}
+	for _, name := range fields {
+		f := stmt.Schema.LookUpField(name)
+		if f == nil || f.DBName == "" {
+			continue
+		}
+		switch f.FieldType.Kind() {
+		case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		default:
+			continue
+		}
+		for _, entity := range entities {
+			if err := f.Set(db.Statement.Context, reflect.ValueOf(entity).Elem(), userID); err != nil {
+				return fmt.Errorf("set %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
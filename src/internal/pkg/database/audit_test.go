@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AuditedEntity is a test entity with created_by and updated_by audit columns
+type AuditedEntity struct {
+	ID        uint `gorm:"primarykey"`
+	Name      string
+	CreatedBy uint `gorm:"not null;default:0"`
+	UpdatedBy uint `gorm:"not null;default:0"`
+}
+
+// TestBaseRepository_AuditColumns tests that inserts and updates record the user ID carried by the context
+func TestBaseRepository_AuditColumns(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&AuditedEntity{}))
+	repo := NewBaseRepository[AuditedEntity](db)
+
+	t.Run("insert sets created_by and updated_by", func(t *testing.T) {
+		entity := &AuditedEntity{Name: "created"}
+		require.NoError(t, repo.Insert(WithUserID(context.Background(), 7), entity))
+
+		stored, err := repo.GetByID(context.Background(), entity.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uint(7), stored.CreatedBy)
+		assert.Equal(t, uint(7), stored.UpdatedBy)
+	})
+
+	t.Run("batch insert sets every entity", func(t *testing.T) {
+		entities := []*AuditedEntity{{Name: "first"}, {Name: "second"}}
+		require.NoError(t, repo.InsertBatch(WithUserID(context.Background(), 8), entities))
+
+		for _, entity := range entities {
+			stored, err := repo.GetByID(context.Background(), entity.ID)
+			require.NoError(t, err)
+			assert.Equal(t, uint(8), stored.CreatedBy)
+		}
+	})
+
+	t.Run("update sets updated_by only", func(t *testing.T) {
+		entity := &AuditedEntity{Name: "original"}
+		require.NoError(t, repo.Insert(WithUserID(context.Background(), 7), entity))
+
+		require.NoError(t, repo.UpdateByID(WithUserID(context.Background(), 9), entity.ID, &AuditedEntity{Name: "changed"}))
+
+		stored, err := repo.GetByID(context.Background(), entity.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "changed", stored.Name)
+		assert.Equal(t, uint(7), stored.CreatedBy)
+		assert.Equal(t, uint(9), stored.UpdatedBy)
+	})
+
+	t.Run("system write leaves columns zero", func(t *testing.T) {
+		entity := &AuditedEntity{Name: "system"}
+		require.NoError(t, repo.Insert(context.Background(), entity))
+
+		stored, err := repo.GetByID(context.Background(), entity.ID)
+		require.NoError(t, err)
+		assert.Zero(t, stored.CreatedBy)
+		assert.Zero(t, stored.UpdatedBy)
+	})
+
+	t.Run("model without audit columns is unaffected", func(t *testing.T) {
+		plain := NewBaseRepository[TestEntity](db)
+		require.NoError(t, plain.Insert(WithUserID(context.Background(), 7), &TestEntity{Name: "plain"}))
+	})
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxConnectBackoff caps the wait between connection attempts
+const maxConnectBackoff = 30 * time.Second
+
+// connectWithRetry calls connect until it succeeds, retrying transient failures up to retries times.
+// The wait before retry n is backoff doubled n-1 times, capped at maxConnectBackoff, with up to half of
+// it taken off at random so instances restarting together do not reconnect in lockstep. Errors that
+// retrying cannot fix, such as rejected credentials, are returned at once; otherwise the last error is
+// returned once the retries are used up or ctx ends.
+func connectWithRetry(ctx context.Context, retries int, backoff time.Duration, log *zap.Logger, connect func(ctx context.Context) (*gorm.DB, error)) (*gorm.DB, error) {
+	for attempt := 0; ; attempt++ {
+		db, err := connect(ctx)
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= retries || !isTransientConnectError(err) {
+			return nil, err
+		}
+
+		delay := connectDelay(backoff, attempt)
+		log.Warn("Database connection failed, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Int("retries", retries),
+			zap.Duration("delay", delay),
+			zap.Error(err))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+	}
+}
+
+// connectDelay returns the jittered wait before retrying after the given zero-based attempt
+func connectDelay(backoff time.Duration, attempt int) time.Duration {
+	delay := backoff
+	for i := 0; i < attempt && delay < maxConnectBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxConnectBackoff {
+		delay = maxConnectBackoff
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return delay - time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// isTransientConnectError reports whether a failed connection attempt may succeed when retried.
+// Rejected credentials, a missing database and a cancelled context are not retried.
+func isTransientConnectError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "28000", "28P01", "3D000": // invalid_authorization_specification, invalid_password, invalid_catalog_name
+			return false
+		}
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1044, 1045, 1049: // ER_DBACCESS_DENIED_ERROR, ER_ACCESS_DENIED_ERROR, ER_BAD_DB_ERROR
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,312 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"myapp/internal/pkg/config"
+)
+
+// flakyDialector fails to connect the first failures times, then opens the wrapped dialector
+type flakyDialector struct {
+	gorm.Dialector
+	failures int
+	err      error // Returned on failure; a numbered connection refused error when nil
+	attempts int
+}
+
+// Initialize fails until the configured number of failures has been reached
+func (d *flakyDialector) Initialize(db *gorm.DB) error {
+	d.attempts++
+	if d.attempts <= d.failures {
+		if d.err != nil {
+			return d.err
+		}
+		return fmt.Errorf("dial tcp: connection refused (attempt %d)", d.attempts)
+	}
+	return d.Dialector.Initialize(db)
+}
+
+// connectFlaky retries opening dialector with openDatabase
+func connectFlaky(ctx context.Context, retries int, backoff time.Duration, dialector *flakyDialector) (*gorm.DB, error) {
+	cfg := config.DatabaseConfig{Name: "test", MaxOpenConns: 1, MaxIdleConns: 1}
+	return connectWithRetry(ctx, retries, backoff, zap.NewNop(), func(ctx context.Context) (*gorm.DB, error) {
+		return openDatabase(ctx, dialector, cfg, logger.Default.LogMode(logger.Silent))
+	})
+}
+
+// TestConnectWithRetry tests that transient connection failures are retried with backoff
+func TestConnectWithRetry(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		dialector := &flakyDialector{Dialector: sqlite.Open(":memory:"), failures: 2}
+
+		db, err := connectFlaky(ctx, 3, time.Millisecond, dialector)
+		require.NoError(t, err)
+		assert.Equal(t, 3, dialector.attempts)
+		assert.NoError(t, db.Exec("SELECT 1").Error)
+	})
+
+	t.Run("gives up after the retries and returns the last error", func(t *testing.T) {
+		dialector := &flakyDialector{Dialector: sqlite.Open(":memory:"), failures: 10}
+
+		_, err := connectFlaky(ctx, 2, time.Millisecond, dialector)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "attempt 3")
+		assert.Equal(t, 3, dialector.attempts)
+	})
+
+	t.Run("no retries fails at once", func(t *testing.T) {
+		dialector := &flakyDialector{Dialector: sqlite.Open(":memory:"), failures: 1}
+
+		_, err := connectFlaky(ctx, 0, time.Millisecond, dialector)
+		require.Error(t, err)
+		assert.Equal(t, 1, dialector.attempts)
+	})
+
+	t.Run("rejected credentials are not retried", func(t *testing.T) {
+		for _, authErr := range []error{
+			&pgconn.PgError{Code: "28P01", Message: "password authentication failed"},
+			&mysql.MySQLError{Number: 1045, Message: "Access denied"},
+		} {
+			dialector := &flakyDialector{Dialector: sqlite.Open(":memory:"), failures: 5, err: authErr}
+
+			_, err := connectFlaky(ctx, 3, time.Millisecond, dialector)
+			require.Error(t, err)
+			assert.ErrorIs(t, err, authErr)
+			assert.Equal(t, 1, dialector.attempts)
+		}
+	})
+
+	t.Run("stops waiting when the context ends", func(t *testing.T) {
+		dialector := &flakyDialector{Dialector: sqlite.Open(":memory:"), failures: 5}
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := connectFlaky(ctx, 3, time.Hour, dialector)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), "attempt 1")
+		assert.Equal(t, 1, dialector.attempts)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+// TestConnectDelay tests that the wait doubles per attempt, is capped and is jittered down by at most half
+func TestConnectDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{3, 800 * time.Millisecond},
+		{20, maxConnectBackoff},
+		{100, maxConnectBackoff},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			delay := connectDelay(100*time.Millisecond, tt.attempt)
+			assert.LessOrEqual(t, delay, tt.max, "attempt %d", tt.attempt)
+			assert.GreaterOrEqual(t, delay, tt.max/2, "attempt %d", tt.attempt)
+		}
+	}
+	assert.Zero(t, connectDelay(0, 2))
+}
+
+// TestIsTransientConnectError tests which connection errors are worth retrying
+func TestIsTransientConnectError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"network error", errors.New("dial tcp 10.0.0.1:5432: connect: connection refused"), true},
+		{"postgres starting up", &pgconn.PgError{Code: "57P03"}, true},
+		{"postgres wrong password", fmt.Errorf("open: %w", &pgconn.PgError{Code: "28P01"}), false},
+		{"postgres unknown role", &pgconn.PgError{Code: "28000"}, false},
+		{"postgres unknown database", &pgconn.PgError{Code: "3D000"}, false},
+		{"mysql access denied", &mysql.MySQLError{Number: 1045}, false},
+		{"mysql unknown database", &mysql.MySQLError{Number: 1049}, false},
+		{"mysql too many connections", &mysql.MySQLError{Number: 1040}, true},
+		{"cancelled", fmt.Errorf("ping: %w", context.Canceled), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.transient, isTransientConnectError(tt.err))
+		})
+	}
+}
+
+// TestTenantConnectionManager_ConnectRetry tests that an unreachable tenant database is retried
+func TestTenantConnectionManager_ConnectRetry(t *testing.T) {
+	masterDB := setupTestDB(t)
+	require.NoError(t, masterDB.AutoMigrate(&Tenant{}))
+	require.NoError(t, masterDB.Create(&Tenant{
+		ID:       "unreachable",
+		Name:     "Unreachable",
+		DBType:   "sqlite",
+		Cnn:      filepath.Join(t.TempDir(), "missing", "tenant.db"),
+		IsActive: true,
+	}).Error)
+
+	core, recorded := observer.New(zapcore.WarnLevel)
+	manager := NewTenantConnectionManager(masterDB, zap.New(core), WithConnectRetry(2, time.Millisecond))
+	defer manager.CloseAll()
+
+	_, err := manager.GetTenantDB(context.Background(), "unreachable")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sqlite database for tenant unreachable")
+
+	retries := recorded.FilterMessage("Database connection failed, retrying").All()
+	require.Len(t, retries, 2)
+	assert.Equal(t, "unreachable", retries[0].ContextMap()["tenant_id"])
+	assert.Zero(t, manager.Stats().Cached)
+}
+
+// TestTenantConnectionManager_ConnectRetryDoesNotBlockOtherTenants tests that the lock is not held while an
+// unreachable tenant database is retried
+func TestTenantConnectionManager_ConnectRetryDoesNotBlockOtherTenants(t *testing.T) {
+	masterDB := setupTestDB(t)
+	require.NoError(t, masterDB.AutoMigrate(&Tenant{}))
+	dir := t.TempDir()
+	for id, cnn := range map[string]string{
+		"unreachable": filepath.Join(dir, "missing", "tenant.db"),
+		"cached":      filepath.Join(dir, "cached.db"),
+		"new":         filepath.Join(dir, "new.db"),
+	} {
+		require.NoError(t, masterDB.Create(&Tenant{ID: id, Name: id, DBType: "sqlite", Cnn: cnn, IsActive: true}).Error)
+	}
+
+	const backoff = 500 * time.Millisecond
+	core, recorded := observer.New(zapcore.WarnLevel)
+	manager := NewTenantConnectionManager(masterDB, zap.New(core), WithConnectRetry(2, backoff))
+	defer manager.CloseAll()
+
+	ctx := context.Background()
+	_, err := manager.GetTenantDB(ctx, "cached")
+	require.NoError(t, err)
+
+	failed := make(chan error, 1)
+	go func() {
+		_, err := manager.GetTenantDB(ctx, "unreachable")
+		failed <- err
+	}()
+	require.Eventually(t, func() bool {
+		return recorded.FilterMessage("Database connection failed, retrying").Len() > 0
+	}, 5*time.Second, time.Millisecond)
+
+	for _, id := range []string{"cached", "new"} {
+		start := time.Now()
+		_, err := manager.GetTenantDB(ctx, id)
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), backoff/2, "tenant %s waited for the unreachable tenant", id)
+	}
+
+	require.Error(t, <-failed)
+	assert.Equal(t, 2, manager.Stats().Cached)
+}
+
+// TestTenantConnectionManager_ConcurrentOpen tests that concurrent first requests for a tenant share one connection
+func TestTenantConnectionManager_ConcurrentOpen(t *testing.T) {
+	masterDB := setupTestDB(t)
+	require.NoError(t, masterDB.AutoMigrate(&Tenant{}))
+	require.NoError(t, masterDB.Create(&Tenant{
+		ID:       "shared",
+		Name:     "Shared",
+		DBType:   "sqlite",
+		Cnn:      filepath.Join(t.TempDir(), "shared.db"),
+		IsActive: true,
+	}).Error)
+
+	manager := NewTenantConnectionManager(masterDB, zap.NewNop())
+	defer manager.CloseAll()
+
+	const requests = 8
+	dbs := make([]*gorm.DB, requests)
+	var wg sync.WaitGroup
+	for i := range dbs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			db, err := manager.GetTenantDB(context.Background(), "shared")
+			assert.NoError(t, err)
+			dbs[i] = db
+		}(i)
+	}
+	wg.Wait()
+
+	for _, db := range dbs[1:] {
+		assert.Same(t, dbs[0], db)
+	}
+	assert.Equal(t, 1, manager.Stats().Cached)
+}
+
+// TestTenantConnectionManager_SharedOpenOutlivesFirstCaller tests that cancelling the request that started a
+// shared open does not fail the requests waiting on it
+func TestTenantConnectionManager_SharedOpenOutlivesFirstCaller(t *testing.T) {
+	masterDB := setupTestDB(t)
+	require.NoError(t, masterDB.AutoMigrate(&Tenant{}))
+	dir := filepath.Join(t.TempDir(), "late")
+	require.NoError(t, masterDB.Create(&Tenant{
+		ID:       "late",
+		Name:     "Late",
+		DBType:   "sqlite",
+		Cnn:      filepath.Join(dir, "tenant.db"),
+		IsActive: true,
+	}).Error)
+
+	core, recorded := observer.New(zapcore.WarnLevel)
+	manager := NewTenantConnectionManager(masterDB, zap.New(core), WithConnectRetry(10, 50*time.Millisecond))
+	defer manager.CloseAll()
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := manager.GetTenantDB(firstCtx, "late")
+		first <- err
+	}()
+	require.Eventually(t, func() bool {
+		return recorded.FilterMessage("Database connection failed, retrying").Len() > 0
+	}, 5*time.Second, time.Millisecond)
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := manager.GetTenantDB(context.Background(), "late")
+		waiter <- err
+	}()
+	time.Sleep(50 * time.Millisecond) // Let the waiter join the open
+
+	// The first caller gives up at once, the open keeps retrying for the waiter
+	cancelFirst()
+	assert.ErrorIs(t, <-first, context.Canceled)
+
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	select {
+	case err := <-waiter:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiter did not get the shared connection")
+	}
+	assert.Equal(t, 1, manager.Stats().Cached)
+}
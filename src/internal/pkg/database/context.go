@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
@@ -11,8 +12,28 @@ type contextKey string
 const (
 	// TenantIDKey is the context key for tenant ID
 	TenantIDKey contextKey = "tenantID"
+	// UserIDKey is the context key for the ID of the user performing a write
+	UserIDKey contextKey = "userID"
 )
 
+// ErrTenantNotInContext is returned when an operation needs a tenant ID but the context carries none
+var ErrTenantNotInContext = errors.New("tenant ID not found in context")
+
+// TenantContextError reports which tenant-scoped operation was attempted without a tenant in context.
+// It matches ErrTenantNotInContext with errors.Is.
+type TenantContextError struct {
+	Operation string
+}
+
+func (e *TenantContextError) Error() string {
+	return fmt.Sprintf("%s: %s requires a tenant ID in the request context (is ContextMiddleware registered?)", ErrTenantNotInContext, e.Operation)
+}
+
+// Unwrap returns ErrTenantNotInContext
+func (e *TenantContextError) Unwrap() error {
+	return ErrTenantNotInContext
+}
+
 // WithTenantID adds tenant ID to context
 func WithTenantID(ctx context.Context, tenantID string) context.Context {
 	return context.WithValue(ctx, TenantIDKey, tenantID)
@@ -22,7 +43,21 @@ func WithTenantID(ctx context.Context, tenantID string) context.Context {
 func GetTenantID(ctx context.Context) (string, error) {
 	tenantID, ok := ctx.Value(TenantIDKey).(string)
 	if !ok || tenantID == "" {
-		return "", fmt.Errorf("tenant ID not found in context")
+		return "", ErrTenantNotInContext
 	}
 	return tenantID, nil
 }
+
+// WithUserID adds the acting user's ID to context, for the created_by and updated_by audit columns
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, UserIDKey, userID)
+}
+
+// GetUserID retrieves the acting user's ID from context; ok is false for unauthenticated and system writes
+func GetUserID(ctx context.Context) (uint, bool) {
+	userID, ok := ctx.Value(UserIDKey).(uint)
+	if !ok || userID == 0 {
+		return 0, false
+	}
+	return userID, true
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRequestCancelled is returned when a statement fails because its context was cancelled,
+	// usually because the client disconnected
+	ErrRequestCancelled = errors.New("request cancelled")
+	// ErrTimeout is returned when a statement fails because its context deadline passed
+	ErrTimeout = errors.New("request timed out")
+)
+
+// registerContextErrors adds GORM callbacks that mark statement errors caused by the context with
+// ErrRequestCancelled or ErrTimeout, so BaseRepository, TenantRepo and custom repository queries can be told
+// apart from database failures. The original error stays in the chain for errors.Is(err, context.Canceled).
+func registerContextErrors(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().After("gorm:create").Register("context:after_create", markContextError),
+		cb.Query().After("gorm:query").Register("context:after_query", markContextError),
+		cb.Update().After("gorm:update").Register("context:after_update", markContextError),
+		cb.Delete().After("gorm:delete").Register("context:after_delete", markContextError),
+		cb.Row().After("gorm:row").Register("context:after_row", markContextError),
+		cb.Raw().After("gorm:raw").Register("context:after_raw", markContextError),
+	)
+}
+
+// markContextError wraps the statement error with the sentinel for its context. Drivers do not always return
+// the context error itself when a running query is interrupted, so the context is checked as well.
+func markContextError(db *gorm.DB) {
+	if db.Error == nil || errors.Is(db.Error, ErrRequestCancelled) || errors.Is(db.Error, ErrTimeout) {
+		return
+	}
+
+	var ctxErr error
+	if db.Statement.Context != nil {
+		ctxErr = db.Statement.Context.Err()
+	}
+	switch {
+	case errors.Is(db.Error, context.DeadlineExceeded) || errors.Is(ctxErr, context.DeadlineExceeded):
+		db.Error = fmt.Errorf("%w: %w", ErrTimeout, db.Error)
+	case errors.Is(db.Error, context.Canceled) || errors.Is(ctxErr, context.Canceled):
+		db.Error = fmt.Errorf("%w: %w", ErrRequestCancelled, db.Error)
+	}
+}
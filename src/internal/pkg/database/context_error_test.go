@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// endlessQuery narrows a query with a subquery that only stops when the context is done
+func endlessQuery(db *gorm.DB) *gorm.DB {
+	return db.Where("value < (WITH RECURSIVE seq(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM seq) SELECT count(*) FROM seq)")
+}
+
+// TestRegisterContextErrors tests that statements failing because of their context return the sentinel errors
+func TestRegisterContextErrors(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, registerContextErrors(db))
+	repo := NewBaseRepository[TestEntity](db)
+	require.NoError(t, repo.Insert(context.Background(), &TestEntity{Name: "Slow", Status: "active", Value: 1}))
+
+	t.Run("cancelled mid-query", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		_, err := repo.GroupByCount(ctx, "status", endlessQuery)
+		assert.ErrorIs(t, err, ErrRequestCancelled)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, ErrTimeout)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("deadline passes mid-query", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := repo.GroupByCount(ctx, "status", endlessQuery)
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, ErrRequestCancelled)
+	})
+
+	t.Run("cancelled before the statement", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := repo.GetAll(ctx, 0, 0)
+		assert.ErrorIs(t, err, ErrRequestCancelled)
+		err = repo.Insert(ctx, &TestEntity{Name: "Late"})
+		assert.ErrorIs(t, err, ErrRequestCancelled)
+		err = repo.UpdateByID(ctx, 1, &TestEntity{Name: "Late"})
+		assert.ErrorIs(t, err, ErrRequestCancelled)
+		err = repo.DeleteByID(ctx, 1)
+		assert.ErrorIs(t, err, ErrRequestCancelled)
+	})
+
+	t.Run("other errors are unchanged", func(t *testing.T) {
+		_, err := repo.GetByID(context.Background(), 9999)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.NotErrorIs(t, err, ErrRequestCancelled)
+		assert.NotErrorIs(t, err, ErrTimeout)
+	})
+}
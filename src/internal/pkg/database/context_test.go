@@ -116,6 +116,50 @@ func TestGetTenantID(t *testing.T) {
 	}
 }
 
+// TestGetUserID tests reading the acting user ID from context
+func TestGetUserID(t *testing.T) {
+	tests := []struct {
+		name     string
+		setupCtx func() context.Context
+		wantID   uint
+		wantOK   bool
+	}{
+		{
+			name: "get user ID",
+			setupCtx: func() context.Context {
+				return WithUserID(context.Background(), 42)
+			},
+			wantID: 42,
+			wantOK: true,
+		},
+		{
+			name: "context without user ID",
+			setupCtx: func() context.Context {
+				return context.Background()
+			},
+		},
+		{
+			name: "context with zero user ID",
+			setupCtx: func() context.Context {
+				return WithUserID(context.Background(), 0)
+			},
+		},
+		{
+			name: "context with wrong type value",
+			setupCtx: func() context.Context {
+				return context.WithValue(context.Background(), UserIDKey, "42")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, ok := GetUserID(tt.setupCtx())
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantID, userID)
+		})
+	}
+}
 // TestContextChaining tests that context values are preserved through chaining
 func TestContextChaining(t *testing.T) {
 	t.Run("tenant ID preserved through context chain", func(t *testing.T) {
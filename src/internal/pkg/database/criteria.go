@@ -0,0 +1,86 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// Operator is a comparison operator used in a Criterion
+type Operator string
+
+// Supported operators for GetWhereAdvanced
+const (
+	OpEq   Operator = "="
+	OpNeq  Operator = "!="
+	OpLT   Operator = "<"
+	OpLTE  Operator = "<="
+	OpGT   Operator = ">"
+	OpGTE  Operator = ">="
+	OpLike Operator = "LIKE"
+	OpIn   Operator = "IN"
+)
+
+// ErrInvalidCriterion is returned when a criterion references an unknown field or operator
+var ErrInvalidCriterion = errors.New("invalid criterion")
+
+// Criterion describes a single filter condition: Field Op Value
+type Criterion struct {
+	Field string
+	Op    Operator
+	Value interface{}
+}
+
+// validOperator reports whether op is supported
+func validOperator(op Operator) bool {
+	switch op {
+	case OpEq, OpNeq, OpLT, OpLTE, OpGT, OpGTE, OpLike, OpIn:
+		return true
+	}
+	return false
+}
+
+// resolveColumn validates a field name against the model schema and returns its column name
+func resolveColumn(db *gorm.DB, model interface{}, field string) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", fmt.Errorf("parse model schema: %w", err)
+	}
+
+	f := stmt.Schema.LookUpField(field)
+	if f == nil || f.DBName == "" {
+		return "", fmt.Errorf("%w: unknown field %q", ErrInvalidCriterion, field)
+	}
+	return f.DBName, nil
+}
+
+// applyCriteria validates the criteria against the model and adds them to the query
+func applyCriteria(query *gorm.DB, model interface{}, criteria []Criterion) (*gorm.DB, error) {
+	for _, c := range criteria {
+		if !validOperator(c.Op) {
+			return nil, fmt.Errorf("%w: unsupported operator %q", ErrInvalidCriterion, c.Op)
+		}
+
+		column, err := resolveColumn(query, model, c.Field)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.Op == OpIn {
+			v := reflect.ValueOf(c.Value)
+			if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+				return nil, fmt.Errorf("%w: IN on %q requires a slice value", ErrInvalidCriterion, c.Field)
+			}
+			// An empty IN list matches nothing
+			if v.Len() == 0 {
+				query = query.Where("1 = 0")
+				continue
+			}
+		}
+
+		query = query.Where(fmt.Sprintf("%s %s ?", column, c.Op), c.Value)
+	}
+	return query, nil
+}
@@ -0,0 +1,94 @@
+package database
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidCursor is returned when a cursor token cannot be decoded or the cursor order is unknown
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Keyset pagination orders for GetAfterCursor
+const (
+	CursorOrderAsc  = "asc"
+	CursorOrderDesc = "desc"
+)
+
+// cursorPrefix marks a decoded token as an ID cursor so arbitrary base64 is rejected
+const cursorPrefix = "id:"
+
+// EncodeCursor returns the opaque token for a page starting after id
+func EncodeCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatUint(uint64(id), 10)))
+}
+
+// DecodeCursor returns the ID encoded in token by EncodeCursor. An empty token is the first page and decodes to 0.
+func DecodeCursor(token string) (uint, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	value, ok := strings.CutPrefix(string(raw), cursorPrefix)
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown token format", ErrInvalidCursor)
+	}
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%w: bad id %q", ErrInvalidCursor, value)
+	}
+	return uint(id), nil
+}
+
+// getAfterCursor loads up to limit entities whose ID follows afterID in orderBy, and the ID to pass as
+// afterID for the next page, or 0 when this is the last page. An afterID of 0 starts at the first row.
+func getAfterCursor[T any](db *gorm.DB, afterID uint, limit int, orderBy string, scopes ...func(*gorm.DB) *gorm.DB) ([]*T, uint, error) {
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("%w: limit must be positive", ErrInvalidCursor)
+	}
+
+	desc := false
+	switch strings.ToLower(orderBy) {
+	case "", CursorOrderAsc:
+	case CursorOrderDesc:
+		desc = true
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown order %q", ErrInvalidCursor, orderBy)
+	}
+
+	query := db.Scopes(scopes...)
+	if afterID > 0 {
+		if desc {
+			query = query.Where("id < ?", afterID)
+		} else {
+			query = query.Where("id > ?", afterID)
+		}
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	entities := []*T{}
+	if err := query.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: desc}).
+		Limit(limit + 1).
+		Find(&entities).Error; err != nil {
+		return nil, 0, fmt.Errorf("get entities after cursor: %w", err)
+	}
+	if len(entities) <= limit {
+		return entities, 0, nil
+	}
+
+	entities = entities[:limit]
+	next, err := primaryKeyOf(db, entities[limit-1])
+	if err != nil {
+		return nil, 0, err
+	}
+	return entities, next, nil
+}
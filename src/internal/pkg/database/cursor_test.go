@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestCursorToken tests encoding and decoding of opaque cursor tokens
+func TestCursorToken(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		id, err := DecodeCursor(EncodeCursor(42))
+		require.NoError(t, err)
+		assert.Equal(t, uint(42), id)
+	})
+
+	t.Run("empty token is the first page", func(t *testing.T) {
+		id, err := DecodeCursor("")
+		require.NoError(t, err)
+		assert.Equal(t, uint(0), id)
+	})
+
+	invalid := map[string]string{
+		"not base64":   "!!!",
+		"wrong format": base64.RawURLEncoding.EncodeToString([]byte("offset:10")),
+		"not a number": base64.RawURLEncoding.EncodeToString([]byte("id:abc")),
+		"zero id":      base64.RawURLEncoding.EncodeToString([]byte("id:0")),
+	}
+	for name, token := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := DecodeCursor(token)
+			assert.ErrorIs(t, err, ErrInvalidCursor)
+		})
+	}
+}
+
+// TestBaseRepository_GetAfterCursor tests paging through a table with keyset pagination
+func TestBaseRepository_GetAfterCursor(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	var all []uint
+	for i := 0; i < 23; i++ {
+		entity := &TestEntity{Name: fmt.Sprintf("Entity %d", i), Status: "active", Value: i}
+		if i%5 == 0 {
+			entity.Status = "inactive"
+		}
+		require.NoError(t, repo.Insert(ctx, entity))
+		all = append(all, entity.ID)
+	}
+
+	// pageAll follows next cursors until the last page and returns every ID seen
+	pageAll := func(t *testing.T, orderBy string, scopes ...func(*gorm.DB) *gorm.DB) []uint {
+		var seen []uint
+		var after uint
+		for pages := 0; ; pages++ {
+			require.Less(t, pages, 10, "paging did not terminate")
+			entities, next, err := repo.GetAfterCursor(ctx, after, 5, orderBy, scopes...)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(entities), 5)
+			for _, entity := range entities {
+				seen = append(seen, entity.ID)
+			}
+			if next == 0 {
+				return seen
+			}
+			assert.Equal(t, entities[len(entities)-1].ID, next)
+			after = next
+		}
+	}
+
+	t.Run("ascending", func(t *testing.T) {
+		assert.Equal(t, all, pageAll(t, CursorOrderAsc))
+	})
+
+	t.Run("descending", func(t *testing.T) {
+		reversed := make([]uint, len(all))
+		for i, id := range all {
+			reversed[len(all)-1-i] = id
+		}
+		assert.Equal(t, reversed, pageAll(t, CursorOrderDesc))
+	})
+
+	t.Run("scoped", func(t *testing.T) {
+		active := func(db *gorm.DB) *gorm.DB { return db.Where("status = ?", "active") }
+		seen := pageAll(t, "", active)
+		assert.Len(t, seen, 18)
+		assert.NotContains(t, seen, all[0])
+	})
+
+	t.Run("exact last page has no next cursor", func(t *testing.T) {
+		entities, next, err := repo.GetAfterCursor(ctx, all[17], 5, CursorOrderAsc)
+		require.NoError(t, err)
+		assert.Len(t, entities, 5)
+		assert.Equal(t, uint(0), next)
+	})
+
+	t.Run("invalid order", func(t *testing.T) {
+		_, _, err := repo.GetAfterCursor(ctx, 0, 5, "sideways")
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, _, err := repo.GetAfterCursor(ctx, 0, 0, CursorOrderAsc)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
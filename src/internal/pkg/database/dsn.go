@@ -0,0 +1,117 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"myapp/internal/pkg/config"
+)
+
+// SSLOptions holds the SSL settings applied to a PostgreSQL connection
+type SSLOptions struct {
+	Mode     string // disable, require, verify-ca or verify-full
+	RootCert string // Optional CA certificate path
+	Cert     string // Optional client certificate path
+	Key      string // Optional client key path
+}
+
+// Validate checks that the SSL mode is supported
+func (o SSLOptions) Validate() error {
+	if !config.ValidSSLMode(o.Mode) {
+		return fmt.Errorf("invalid ssl mode %q: must be one of disable, require, verify-ca, verify-full", o.Mode)
+	}
+	return nil
+}
+
+// params returns the libpq parameters for the options in a stable order, skipping empty paths
+func (o SSLOptions) params() [][2]string {
+	params := [][2]string{{"sslmode", o.Mode}}
+	if o.RootCert != "" {
+		params = append(params, [2]string{"sslrootcert", o.RootCert})
+	}
+	if o.Cert != "" {
+		params = append(params, [2]string{"sslcert", o.Cert})
+	}
+	if o.Key != "" {
+		params = append(params, [2]string{"sslkey", o.Key})
+	}
+	return params
+}
+
+// postgresDSN builds a keyword/value PostgreSQL DSN from the database configuration
+func postgresDSN(cfg config.DatabaseConfig) (string, error) {
+	mode := cfg.SSLMode
+	if mode == "" {
+		mode = config.SSLModeDisable
+	}
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name)
+	return withSSLOptions(dsn, SSLOptions{
+		Mode:     mode,
+		RootCert: cfg.SSLRootCert,
+		Cert:     cfg.SSLCert,
+		Key:      cfg.SSLKey,
+	})
+}
+
+// mysqlDSN builds a MySQL DSN from the database configuration using the same format as tenant connection strings.
+// SSL modes map onto the driver's tls parameter; certificate paths are not supported
+func mysqlDSN(cfg config.DatabaseConfig) (string, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&loc=UTC&allowPublicKeyRetrieval=true",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
+
+	switch cfg.SSLMode {
+	case "", config.SSLModeDisable:
+		return dsn, nil
+	case config.SSLModeRequire:
+		dsn += "&tls=skip-verify"
+	case config.SSLModeVerifyCA, config.SSLModeVerifyFull:
+		dsn += "&tls=true"
+	default:
+		return "", SSLOptions{Mode: cfg.SSLMode}.Validate()
+	}
+	if cfg.SSLRootCert != "" || cfg.SSLCert != "" || cfg.SSLKey != "" {
+		return "", fmt.Errorf("ssl certificate paths are not supported for mysql")
+	}
+	return dsn, nil
+}
+
+// withSSLOptions applies the SSL options to a PostgreSQL DSN in either URL or keyword/value form,
+// overriding any SSL settings already present
+func withSSLOptions(dsn string, opts SSLOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("parse postgres dsn: %w", err)
+		}
+		query := u.Query()
+		for _, p := range opts.params() {
+			query.Set(p[0], p[1])
+		}
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+
+	// In keyword/value form the last occurrence of a keyword wins
+	var b strings.Builder
+	b.WriteString(dsn)
+	for _, p := range opts.params() {
+		fmt.Fprintf(&b, " %s=%s", p[0], quoteDSNValue(p[1]))
+	}
+	return b.String(), nil
+}
+
+// quoteDSNValue quotes a keyword/value DSN value when it is empty or contains spaces, quotes or backslashes
+func quoteDSNValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, " '\\") {
+		return v
+	}
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, `'`, `\'`)
+	return "'" + v + "'"
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// IsDuplicateKeyError reports whether err is a unique constraint violation from PostgreSQL, MySQL or SQLite.
+// Use it to turn a lost race between an existence check and an insert into a conflict instead of a 500.
+func IsDuplicateKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505" // unique_violation
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1062 // ER_DUP_ENTRY
+	}
+	// go-sqlite3 is matched by message so this package does not depend on the cgo driver's error type
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
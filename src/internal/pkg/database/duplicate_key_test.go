@@ -0,0 +1,47 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestIsDuplicateKeyError tests that unique violations are recognized for each supported driver
+func TestIsDuplicateKeyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"gorm translated", gorm.ErrDuplicatedKey, true},
+		{"postgres unique violation", fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"}), true},
+		{"postgres other violation", &pgconn.PgError{Code: "23503"}, false},
+		{"mysql duplicate entry", &mysql.MySQLError{Number: 1062}, true},
+		{"mysql other error", &mysql.MySQLError{Number: 1452}, false},
+		{"sqlite unique constraint", errors.New("UNIQUE constraint failed: products.sku"), true},
+		{"other error", errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsDuplicateKeyError(tt.err))
+		})
+	}
+
+	t.Run("sqlite insert", func(t *testing.T) {
+		db := setupTestDB(t)
+		require.NoError(t, db.Exec("CREATE TABLE items (sku TEXT UNIQUE)").Error)
+		require.NoError(t, db.Exec("INSERT INTO items (sku) VALUES ('A')").Error)
+
+		err := db.Exec("INSERT INTO items (sku) VALUES ('A')").Error
+		require.Error(t, err)
+		assert.True(t, IsDuplicateKeyError(err))
+	})
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidFields is returned when a field selection names a field outside the allowlist
+var ErrInvalidFields = errors.New("invalid fields")
+
+// FieldSet is a sparse fieldset: the public field names a client asked for and the columns backing them
+type FieldSet struct {
+	Names   []string
+	Columns []string
+}
+
+// ParseFields parses a comma-separated field selection such as "id,name,price".
+// Each name is looked up in allowed, which maps the public field name to its column. Unknown names reject
+// the whole selection when strict is set and are dropped otherwise; repeated names are listed once.
+// A nil FieldSet means every field: it is returned for an empty selection and for one left empty by
+// dropping unknown names.
+func ParseFields(raw string, allowed map[string]string, strict bool) (*FieldSet, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	set := &FieldSet{Names: make([]string, 0, len(parts)), Columns: make([]string, 0, len(parts))}
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		name := strings.TrimSpace(part)
+		column, ok := allowed[name]
+		if name == "" || !ok {
+			if strict {
+				return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidFields, name)
+			}
+			continue
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		set.Names = append(set.Names, name)
+		set.Columns = append(set.Columns, column)
+	}
+	if len(set.Names) == 0 {
+		return nil, nil
+	}
+	return set, nil
+}
+
+// SelectedNames returns the selected field names, or nil for every field when the set is nil
+func (f *FieldSet) SelectedNames() []string {
+	if f == nil {
+		return nil
+	}
+	return f.Names
+}
+
+// SelectedColumns returns the selected columns, or nil for every column when the set is nil
+func (f *FieldSet) SelectedColumns() []string {
+	if f == nil {
+		return nil
+	}
+	return f.Columns
+}
+
+// SelectColumns is a scope that loads only the given columns, or every column when there are none.
+// The id column is always selected so cursors and follow-up updates keep working. Columns are quoted
+// by GORM, so only allowlisted names from ParseFields should be passed in.
+func SelectColumns(columns []string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if len(columns) == 0 {
+			return db
+		}
+		selected := make([]string, 0, len(columns)+1)
+		selected = append(selected, "id")
+		for _, column := range columns {
+			if column != "id" {
+				selected = append(selected, column)
+			}
+		}
+		return db.Select(selected)
+	}
+}
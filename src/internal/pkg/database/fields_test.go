@@ -0,0 +1,69 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestParseFields tests parsing of comma-separated field selections
+func TestParseFields(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		set, err := ParseFields("  ", testSortFields, true)
+		require.NoError(t, err)
+		assert.Nil(t, set)
+	})
+
+	t.Run("names map to columns in request order", func(t *testing.T) {
+		set, err := ParseFields("state, name,state", testSortFields, true)
+		require.NoError(t, err)
+		assert.Equal(t, &FieldSet{Names: []string{"state", "name"}, Columns: []string{"status", "name"}}, set)
+	})
+
+	t.Run("strict rejects unknown fields", func(t *testing.T) {
+		for _, raw := range []string{"password", "name,password", "name,", "name;drop table"} {
+			_, err := ParseFields(raw, testSortFields, true)
+			assert.ErrorIs(t, err, ErrInvalidFields, raw)
+		}
+	})
+
+	t.Run("lenient drops unknown fields", func(t *testing.T) {
+		set, err := ParseFields("name,password,,value", testSortFields, false)
+		require.NoError(t, err)
+		assert.Equal(t, &FieldSet{Names: []string{"name", "value"}, Columns: []string{"name", "value"}}, set)
+
+		set, err = ParseFields("password", testSortFields, false)
+		require.NoError(t, err)
+		assert.Nil(t, set, "a selection with no known fields means every field")
+	})
+}
+
+// TestSelectColumns tests that only the selected columns, plus id, are queried
+func TestSelectColumns(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.Create(&TestEntity{Name: "a", Status: "active", Value: 7}).Error)
+
+	t.Run("query selects only the columns", func(t *testing.T) {
+		stmt := db.Session(&gorm.Session{DryRun: true}).Scopes(SelectColumns([]string{"name", "value"})).Find(&[]TestEntity{}).Statement
+		assert.Equal(t, "SELECT `id`,`name`,`value` FROM `test_entities`", stmt.SQL.String())
+	})
+
+	t.Run("unselected columns stay zero", func(t *testing.T) {
+		var entities []TestEntity
+		require.NoError(t, db.Scopes(SelectColumns([]string{"id", "status"})).Find(&entities).Error)
+		require.Len(t, entities, 1)
+		assert.NotZero(t, entities[0].ID)
+		assert.Equal(t, "active", entities[0].Status)
+		assert.Empty(t, entities[0].Name)
+		assert.Zero(t, entities[0].Value)
+	})
+
+	t.Run("no columns selects everything", func(t *testing.T) {
+		var entity TestEntity
+		require.NoError(t, db.Scopes(SelectColumns(nil)).First(&entity).Error)
+		assert.Equal(t, "a", entity.Name)
+		assert.Equal(t, 7, entity.Value)
+	})
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// gormLogger sends GORM's log output to zap. Queries slower than slowThreshold are logged at warn level;
+// when the zap logger has debug enabled every query is logged at debug level.
+type gormLogger struct {
+	log           *zap.Logger
+	slowThreshold time.Duration // 0 disables slow query warnings
+	tenantID      string        // Logged when the query context carries no tenant ID
+	level         logger.LogLevel
+}
+
+// NewGormLogger creates a GORM logger backed by log that warns about queries slower than slowThreshold
+func NewGormLogger(log *zap.Logger, slowThreshold time.Duration) logger.Interface {
+	return &gormLogger{log: log, slowThreshold: slowThreshold, level: logger.Warn}
+}
+
+// newTenantGormLogger creates a GORM logger for a tenant connection, so its queries are logged with the
+// tenant ID even when made without tenant context
+func newTenantGormLogger(log *zap.Logger, slowThreshold time.Duration, tenantID string) logger.Interface {
+	return &gormLogger{log: log, slowThreshold: slowThreshold, tenantID: tenantID, level: logger.Warn}
+}
+
+// LogMode returns a copy of the logger at the given level; logger.Silent turns off query logging,
+// logger.Info (as set by db.Debug()) logs every query at info level
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs a GORM message at info level
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.log.Info(fmt.Sprintf(msg, args...), l.tenantField(ctx)...)
+	}
+}
+
+// Warn logs a GORM message at warn level
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.log.Warn(fmt.Sprintf(msg, args...), l.tenantField(ctx)...)
+	}
+}
+
+// Error logs a GORM message at error level
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.log.Error(fmt.Sprintf(msg, args...), l.tenantField(ctx)...)
+	}
+}
+
+// Trace logs a finished statement. The SQL is only rendered when the statement is going to be logged.
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	var level zapcore.Level
+	var msg string
+	switch {
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		level, msg = zap.WarnLevel, "Slow query"
+	case l.level >= logger.Info:
+		level, msg = zap.InfoLevel, "Query"
+	default:
+		level, msg = zap.DebugLevel, "Query"
+	}
+	ce := l.log.Check(level, msg)
+	if ce == nil {
+		return
+	}
+
+	sql, rows := fc()
+	fields := append(l.tenantField(ctx),
+		zap.String("sql", sql),
+		zap.Duration("duration", elapsed),
+		zap.Int64("rows_affected", rows),
+	)
+	if level == zap.WarnLevel {
+		fields = append(fields, zap.Duration("threshold", l.slowThreshold))
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		fields = append(fields, zap.Error(err))
+	}
+	ce.Write(fields...)
+}
+
+// tenantField returns the tenant_id field from ctx, or from the connection's tenant, when known
+func (l *gormLogger) tenantField(ctx context.Context) []zap.Field {
+	tenantID, err := GetTenantID(ctx)
+	if err != nil {
+		tenantID = l.tenantID
+	}
+	if tenantID == "" {
+		return nil
+	}
+	return []zap.Field{zap.String("tenant_id", tenantID)}
+}
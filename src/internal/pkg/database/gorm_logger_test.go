@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// slowQueryKey marks a statement that the test callback should delay
+const slowQueryKey = "test:slow_query"
+
+// setupLoggedDB opens an in-memory SQLite database that logs through gormLog. Statements run with
+// slowQuery take at least the given delay.
+func setupLoggedDB(t *testing.T, gormLog logger.Interface) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: gormLog})
+	require.NoError(t, err)
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:delay", func(db *gorm.DB) {
+		if delay, ok := db.Get(slowQueryKey); ok {
+			time.Sleep(delay.(time.Duration))
+		}
+	}))
+	require.NoError(t, db.Session(&gorm.Session{Logger: gormLog.LogMode(logger.Silent)}).AutoMigrate(&TestEntity{}))
+	require.NoError(t, db.Session(&gorm.Session{Logger: gormLog.LogMode(logger.Silent)}).Create(&TestEntity{Name: "seed"}).Error)
+	return db
+}
+
+// slowQuery delays the statement by d
+func slowQuery(d time.Duration) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Set(slowQueryKey, d)
+	}
+}
+
+// TestGormLogger tests that slow queries are logged at warn level and other queries only at debug level
+func TestGormLogger(t *testing.T) {
+	const threshold = 20 * time.Millisecond
+
+	t.Run("slow query is logged", func(t *testing.T) {
+		core, recorded := observer.New(zapcore.InfoLevel)
+		db := setupLoggedDB(t, NewGormLogger(zap.New(core), threshold))
+		ctx := WithTenantID(context.Background(), "tenant-1")
+
+		var entities []TestEntity
+		require.NoError(t, db.WithContext(ctx).Scopes(slowQuery(2*threshold)).Where("name = ?", "seed").Find(&entities).Error)
+
+		entries := recorded.FilterMessage("Slow query").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+		fields := entries[0].ContextMap()
+		assert.Contains(t, fields["sql"], `WHERE name = "seed"`)
+		assert.GreaterOrEqual(t, fields["duration"], 2*threshold)
+		assert.Equal(t, int64(1), fields["rows_affected"])
+		assert.Equal(t, threshold, fields["threshold"])
+		assert.Equal(t, "tenant-1", fields["tenant_id"])
+	})
+
+	t.Run("fast query is not logged", func(t *testing.T) {
+		core, recorded := observer.New(zapcore.InfoLevel)
+		db := setupLoggedDB(t, NewGormLogger(zap.New(core), threshold))
+
+		var entities []TestEntity
+		require.NoError(t, db.Find(&entities).Error)
+
+		assert.Zero(t, recorded.Len())
+	})
+
+	t.Run("every query is logged at debug level", func(t *testing.T) {
+		core, recorded := observer.New(zapcore.DebugLevel)
+		db := setupLoggedDB(t, NewGormLogger(zap.New(core), threshold))
+
+		var entities []TestEntity
+		require.NoError(t, db.Find(&entities).Error)
+
+		entries := recorded.FilterMessage("Query").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, zapcore.DebugLevel, entries[0].Level)
+		assert.Contains(t, entries[0].ContextMap()["sql"], "SELECT * FROM `test_entities`")
+		assert.NotContains(t, entries[0].ContextMap(), "tenant_id")
+	})
+
+	t.Run("zero threshold disables slow query warnings", func(t *testing.T) {
+		core, recorded := observer.New(zapcore.InfoLevel)
+		db := setupLoggedDB(t, NewGormLogger(zap.New(core), 0))
+
+		var entities []TestEntity
+		require.NoError(t, db.Scopes(slowQuery(threshold)).Find(&entities).Error)
+
+		assert.Zero(t, recorded.Len())
+	})
+
+	t.Run("silent mode logs nothing", func(t *testing.T) {
+		core, recorded := observer.New(zapcore.DebugLevel)
+		gormLog := NewGormLogger(zap.New(core), threshold)
+		db := setupLoggedDB(t, gormLog)
+
+		var entities []TestEntity
+		require.NoError(t, db.Session(&gorm.Session{Logger: gormLog.LogMode(logger.Silent)}).Scopes(slowQuery(2*threshold)).Find(&entities).Error)
+
+		assert.Zero(t, recorded.Len())
+	})
+
+	t.Run("tenant connection logs its tenant", func(t *testing.T) {
+		core, recorded := observer.New(zapcore.InfoLevel)
+		db := setupLoggedDB(t, newTenantGormLogger(zap.New(core), threshold, "tenant-2"))
+
+		var entities []TestEntity
+		require.NoError(t, db.Scopes(slowQuery(2*threshold)).Find(&entities).Error)
+
+		entries := recorded.FilterMessage("Slow query").All()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "tenant-2", entries[0].ContextMap()["tenant_id"])
+	})
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidGroupBy is returned when a group-by field is not a column of the model
+var ErrInvalidGroupBy = errors.New("invalid group by")
+
+// groupCount is one row of a GROUP BY ... COUNT(*) query
+type groupCount struct {
+	Value sql.NullString
+	Count int64
+}
+
+// groupByCount counts rows of model per distinct value of field. NULL values are reported under "".
+// Scopes narrow the rows before grouping.
+func groupByCount(query *gorm.DB, model interface{}, field string, scopes ...func(*gorm.DB) *gorm.DB) (map[string]int64, error) {
+	column, err := resolveColumn(query, model, field)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCriterion) {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidGroupBy, field)
+		}
+		return nil, err
+	}
+
+	// Find rather than Scan: Scan reads rows after the statement callbacks have run, so a cancelled
+	// context would not be marked by registerContextErrors
+	var rows []groupCount
+	quoted := query.Statement.Quote(column)
+	if err := query.Model(model).
+		Scopes(scopes...).
+		Select(quoted + " AS value, COUNT(*) AS count").
+		Group(quoted).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("group by %s: %w", column, err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Value.String] += row.Count
+	}
+	return counts, nil
+}
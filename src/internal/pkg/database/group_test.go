@@ -0,0 +1,51 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// TestBaseRepository_GroupByCount tests counting entities per distinct field value
+func TestBaseRepository_GroupByCount(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	for _, e := range []*TestEntity{
+		{Name: "a", Status: "active", Value: 1},
+		{Name: "b", Status: "active", Value: 2},
+		{Name: "c", Status: "inactive", Value: 3},
+		{Name: "d", Status: "", Value: 4},
+	} {
+		require.NoError(t, repo.Insert(ctx, e))
+	}
+
+	t.Run("group by field", func(t *testing.T) {
+		counts, err := repo.GroupByCount(ctx, "Status")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"active": 2, "inactive": 1, "": 1}, counts)
+	})
+
+	t.Run("column name is accepted", func(t *testing.T) {
+		counts, err := repo.GroupByCount(ctx, "status")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), counts["active"])
+	})
+
+	t.Run("scopes narrow rows", func(t *testing.T) {
+		counts, err := repo.GroupByCount(ctx, "Status", func(db *gorm.DB) *gorm.DB {
+			return db.Where("value > ?", 1)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"active": 1, "inactive": 1, "": 1}, counts)
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := repo.GroupByCount(ctx, "status; DROP TABLE test_entities")
+		assert.ErrorIs(t, err, ErrInvalidGroupBy)
+	})
+}
@@ -1,19 +1,23 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"go.uber.org/zap"
+	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/encryption"
 )
 
 // DatabaseManager manages master and tenant database connections
 type DatabaseManager struct {
 	MasterDB         *gorm.DB
+	MasterReplicas   []*gorm.DB // Read replicas of MasterDB from read_replicas, used by MasterRepo.ReadOnly
 	TenantDB         *gorm.DB // Deprecated: Use TenantConnManager for dynamic connections
 	TenantConnManager *TenantConnectionManager
 }
@@ -21,7 +25,7 @@ type DatabaseManager struct {
 // NewDatabaseManager creates a new DatabaseManager with master and tenant connections
 func NewDatabaseManager(cfg *config.Config, log *zap.Logger) (*DatabaseManager, error) {
 	// Create master database connection
-	masterDB, err := NewDatabase(cfg.MasterDatabase, log)
+	masterDB, err := NewDatabase(cfg.MasterDatabase, log, cfg.Logger.SlowQueryThreshold)
 	if err != nil {
 		return nil, fmt.Errorf("create master database connection: %w", err)
 	}
@@ -29,8 +33,21 @@ func NewDatabaseManager(cfg *config.Config, log *zap.Logger) (*DatabaseManager,
 		zap.String("host", cfg.MasterDatabase.Host),
 		zap.String("name", cfg.MasterDatabase.Name))
 	
+	// Read replicas of the master database are optional; without them reads use the primary
+	masterReplicas := make([]*gorm.DB, 0, len(cfg.ReadReplicas))
+	for i, replicaCfg := range cfg.ReadReplicas {
+		replica, err := NewDatabase(replicaCfg, log, cfg.Logger.SlowQueryThreshold)
+		if err != nil {
+			return nil, fmt.Errorf("create read replica %d connection: %w", i, err)
+		}
+		masterReplicas = append(masterReplicas, replica)
+		log.Info("Master read replica connected",
+			zap.String("host", replicaCfg.Host),
+			zap.String("name", replicaCfg.Name))
+	}
+	
 	// Create tenant database connection (for backward compatibility)
-	tenantDB, err := NewDatabase(cfg.TenantDatabase, log)
+	tenantDB, err := NewDatabase(cfg.TenantDatabase, log, cfg.Logger.SlowQueryThreshold)
 	if err != nil {
 		return nil, fmt.Errorf("create tenant database connection: %w", err)
 	}
@@ -38,35 +55,80 @@ func NewDatabaseManager(cfg *config.Config, log *zap.Logger) (*DatabaseManager,
 		zap.String("host", cfg.TenantDatabase.Host),
 		zap.String("name", cfg.TenantDatabase.Name))
 	
+	opts := []TenantConnectionOption{
+		WithMaxCachedConnections(cfg.TenantConnections.MaxCached),
+		WithIdleTimeout(cfg.TenantConnections.IdleTimeout),
+		WithCloseGrace(cfg.TenantConnections.CloseGrace),
+		WithConcurrency(cfg.Bulk.Concurrency),
+		WithStrictTenantContext(cfg.TenantConnections.StrictContext),
+		WithSlowQueryThreshold(cfg.Logger.SlowQueryThreshold),
+		WithConnectRetry(cfg.TenantConnections.ConnectRetries, cfg.TenantConnections.ConnectBackoff),
+	}
+	
+	// Tenant connection strings may be encrypted with the tenant's key
+	encryptor, err := encryption.NewFromConfig(cfg.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("create tenant secret encryptor: %w", err)
+	}
+	if encryptor != nil {
+		opts = append(opts, WithSecretDecryptor(encryptor))
+	}
+	
 	// Create tenant connection manager for dynamic connections
-	tenantConnManager := NewTenantConnectionManager(masterDB, log)
-	log.Info("Tenant connection manager initialized")
+	tenantConnManager := NewTenantConnectionManager(masterDB, log, opts...)
+	log.Info("Tenant connection manager initialized",
+		zap.Bool("encrypted_secrets", encryptor != nil))
 	
 	return &DatabaseManager{
 		MasterDB:          masterDB,
+		MasterReplicas:    masterReplicas,
 		TenantDB:          tenantDB, // Kept for backward compatibility
 		TenantConnManager: tenantConnManager,
 	}, nil
 }
 
-// NewDatabase creates a new database connection based on configuration
-func NewDatabase(cfg config.DatabaseConfig, log *zap.Logger) (*gorm.DB, error) {
-	// For PostgreSQL only (as per user selection)
-	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name)
+// NewDatabase creates a new database connection based on configuration. Queries slower than
+// slowQueryThreshold are logged at warn level, and every query at debug level; 0 disables the warnings.
+// A database that cannot be reached is retried connect_retries times with backoff.
+func NewDatabase(cfg config.DatabaseConfig, log *zap.Logger, slowQueryThreshold time.Duration) (*gorm.DB, error) {
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("build %s dsn for %s: %w", cfg.Driver, cfg.Name, err)
+	}
 	
-	// Configure GORM logger
-	gormLogger := logger.Default.LogMode(logger.Silent)
+	db, err := connectWithRetry(context.Background(), cfg.ConnectRetries, cfg.ConnectBackoff, log.With(zap.String("database", cfg.Name)),
+		func(ctx context.Context) (*gorm.DB, error) {
+			return openDatabase(ctx, dialector, cfg, NewGormLogger(log, slowQueryThreshold))
+		})
+	if err != nil {
+		return nil, err
+	}
+	
+	log.Debug("Database connection pool configured",
+		zap.Int("max_open_conns", cfg.MaxOpenConns),
+		zap.Int("max_idle_conns", cfg.MaxIdleConns))
 	
+	return db, nil
+}
+
+// openDatabase makes a single attempt to open and ping the database; the pool is closed again when the
+// ping fails
+func openDatabase(ctx context.Context, dialector gorm.Dialector, cfg config.DatabaseConfig, gormLog logger.Interface) (*gorm.DB, error) {
 	// Open database connection
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: gormLogger,
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger: gormLog,
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("open postgres database %s: %w", cfg.Name, err)
+		return nil, fmt.Errorf("open %s database %s: %w", dialector.Name(), cfg.Name, err)
+	}
+	if err := registerTracing(db); err != nil {
+		return nil, fmt.Errorf("register tracing for %s: %w", cfg.Name, err)
+	}
+	if err := registerContextErrors(db); err != nil {
+		return nil, fmt.Errorf("register context errors for %s: %w", cfg.Name, err)
 	}
 	
 	// Get underlying SQL DB to configure connection pool
@@ -81,17 +143,34 @@ func NewDatabase(cfg config.DatabaseConfig, log *zap.Logger) (*gorm.DB, error) {
 	sqlDB.SetConnMaxLifetime(time.Hour)
 	
 	// Test connection
-	if err := sqlDB.Ping(); err != nil {
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
 		return nil, fmt.Errorf("ping database: %w", err)
 	}
 	
-	log.Debug("Database connection pool configured",
-		zap.Int("max_open_conns", cfg.MaxOpenConns),
-		zap.Int("max_idle_conns", cfg.MaxIdleConns))
-	
 	return db, nil
 }
 
+// newDialector selects the GORM dialector and builds the DSN for the configured driver
+func newDialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case "postgres", "":
+		dsn, err := postgresDSN(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.Open(dsn), nil
+	case "mysql":
+		dsn, err := mysqlDSN(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return mysql.Open(dsn), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver '%s'", cfg.Driver)
+	}
+}
+
 // Close closes all database connections
 func (m *DatabaseManager) Close() error {
 	var errors []error
@@ -104,6 +183,14 @@ func (m *DatabaseManager) Close() error {
 		}
 	}
 	
+	for i, replica := range m.MasterReplicas {
+		if sqlDB, err := replica.DB(); err == nil {
+			if err := sqlDB.Close(); err != nil {
+				errors = append(errors, fmt.Errorf("close read replica %d: %w", i, err))
+			}
+		}
+	}
+	
 	if m.TenantDB != nil {
 		if sqlDB, err := m.TenantDB.DB(); err == nil {
 			if err := sqlDB.Close(); err != nil {
@@ -112,6 +199,12 @@ func (m *DatabaseManager) Close() error {
 		}
 	}
 	
+	if m.TenantConnManager != nil {
+		if err := m.TenantConnManager.CloseAll(); err != nil {
+			errors = append(errors, fmt.Errorf("close tenant connections: %w", err))
+		}
+	}
+	
 	if len(errors) > 0 {
 		return fmt.Errorf("errors closing databases: %v", errors)
 	}
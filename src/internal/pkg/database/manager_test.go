@@ -6,9 +6,56 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"myapp/internal/pkg/config"
 )
 
+// TestNewDialector tests that the dialector and DSN match the configured driver
+func TestNewDialector(t *testing.T) {
+	base := config.DatabaseConfig{Host: "db", Port: 3306, Name: "app", User: "user", Password: "pass"}
+
+	t.Run("postgres", func(t *testing.T) {
+		cfg := base
+		cfg.Driver = "postgres"
+		dialector, err := newDialector(cfg)
+		require.NoError(t, err)
+		require.IsType(t, &postgres.Dialector{}, dialector)
+		assert.Equal(t, "postgres", dialector.Name())
+		assert.Equal(t, "host=db port=3306 user=user password=pass dbname=app sslmode=disable", dialector.(*postgres.Dialector).DSN)
+	})
+
+	t.Run("mysql", func(t *testing.T) {
+		cfg := base
+		cfg.Driver = "mysql"
+		dialector, err := newDialector(cfg)
+		require.NoError(t, err)
+		require.IsType(t, &mysql.Dialector{}, dialector)
+		assert.Equal(t, "mysql", dialector.Name())
+		assert.Equal(t, "user:pass@tcp(db:3306)/app?parseTime=true&loc=UTC&allowPublicKeyRetrieval=true", dialector.(*mysql.Dialector).DSN)
+	})
+
+	t.Run("mysql with ssl", func(t *testing.T) {
+		cfg := base
+		cfg.Driver = "mysql"
+		cfg.SSLMode = config.SSLModeVerifyFull
+		dialector, err := newDialector(cfg)
+		require.NoError(t, err)
+		assert.Contains(t, dialector.(*mysql.Dialector).DSN, "&tls=true")
+
+		cfg.SSLRootCert = "/etc/ssl/ca.pem"
+		_, err = newDialector(cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported driver", func(t *testing.T) {
+		cfg := base
+		cfg.Driver = "mongodb"
+		_, err := newDialector(cfg)
+		assert.Error(t, err)
+	})
+}
+
 // TestNewDatabase tests database connection creation
 func TestNewDatabase(t *testing.T) {
 	t.Run("create postgres connection with valid config", func(t *testing.T) {
@@ -28,9 +75,38 @@ func TestNewDatabase(t *testing.T) {
 		}
 		logger := zaptest.NewLogger(t)
 
-		db, err := NewDatabase(cfg, logger)
+		db, err := NewDatabase(cfg, logger, 0)
+		require.NoError(t, err)
+		require.NotNil(t, db)
+
+		// Cleanup
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	})
+
+	t.Run("create mysql connection with valid config", func(t *testing.T) {
+		// Note: This test requires a running MySQL instance
+		// Skip if not available
+		t.Skip("Requires running MySQL instance")
+
+		cfg := config.DatabaseConfig{
+			Driver:       "mysql",
+			Host:         "localhost",
+			Port:         3306,
+			Name:         "test_db",
+			User:         "test_user",
+			Password:     "test_pass",
+			MaxOpenConns: 10,
+			MaxIdleConns: 2,
+		}
+		logger := zaptest.NewLogger(t)
+
+		db, err := NewDatabase(cfg, logger, 0)
 		require.NoError(t, err)
 		require.NotNil(t, db)
+		assert.Equal(t, "mysql", db.Dialector.Name())
 
 		// Cleanup
 		sqlDB, _ := db.DB()
@@ -52,7 +128,7 @@ func TestNewDatabase(t *testing.T) {
 		}
 		logger := zaptest.NewLogger(t)
 
-		db, err := NewDatabase(cfg, logger)
+		db, err := NewDatabase(cfg, logger, 0)
 		assert.Error(t, err)
 		assert.Nil(t, db)
 	})
@@ -195,7 +271,7 @@ func TestDatabaseConnectionPool(t *testing.T) {
 		}
 		logger := zaptest.NewLogger(t)
 
-		db, err := NewDatabase(cfg, logger)
+		db, err := NewDatabase(cfg, logger, 0)
 		require.NoError(t, err)
 		require.NotNil(t, db)
 
@@ -288,7 +364,7 @@ func TestNewDatabase_ErrorHandling(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			db, err := NewDatabase(tt.config, logger)
+			db, err := NewDatabase(tt.config, logger, 0)
 			assert.Error(t, err)
 			assert.Nil(t, db)
 		})
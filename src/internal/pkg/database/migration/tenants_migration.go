@@ -6,7 +6,8 @@ import (
 	"myapp/internal/pkg/database"
 )
 
-// MigrateTenants creates the tenants table in the master database
+// MigrateTenants creates the tenants table in the master database.
+// Existing tables gain any new columns, such as the per-tenant pool settings, defaulting to zero.
 func MigrateTenants(db *gorm.DB) error {
 	if err := db.AutoMigrate(&database.Tenant{}); err != nil {
 		return fmt.Errorf("migrate tenants table: %w", err)
@@ -0,0 +1,51 @@
+package migration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/database"
+)
+
+// legacyTenant mirrors the tenants table before per-tenant pool settings were added
+type legacyTenant struct {
+	ID        string `gorm:"primaryKey;type:varchar(100)"`
+	Name      string `gorm:"type:varchar(255);not null"`
+	DBType    string `gorm:"type:varchar(50);not null;default:'mysql';column:db_type"`
+	Cnn       string `gorm:"type:text;not null;column:cnn"`
+	IsActive  bool   `gorm:"default:true;column:is_active"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TableName specifies the table name for legacyTenant
+func (legacyTenant) TableName() string {
+	return "tenants"
+}
+
+// TestMigrateTenants_AddsPoolColumns tests upgrading a tenants table created before pool settings existed
+func TestMigrateTenants_AddsPoolColumns(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	// Legacy schema without pool columns
+	require.NoError(t, db.AutoMigrate(&legacyTenant{}))
+	require.NoError(t, db.Create(&legacyTenant{ID: "legacy", Name: "Legacy", DBType: "sqlite", Cnn: ":memory:", IsActive: true}).Error)
+
+	require.NoError(t, MigrateTenants(db))
+
+	for _, column := range []string{"db_max_open_conns", "db_max_idle_conns", "db_conn_max_lifetime_seconds"} {
+		assert.True(t, db.Migrator().HasColumn(&database.Tenant{}, column), column)
+	}
+
+	var tenant database.Tenant
+	require.NoError(t, db.First(&tenant, "id = ?", "legacy").Error)
+	assert.Zero(t, tenant.DBMaxOpenConns)
+
+	tenant.ApplyPoolDefaults()
+	assert.Equal(t, database.DefaultTenantMaxOpenConns, tenant.DBMaxOpenConns)
+}
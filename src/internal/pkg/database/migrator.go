@@ -0,0 +1,225 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNoAppliedMigrations is returned by Down when a service has nothing to roll back
+	ErrNoAppliedMigrations = errors.New("no applied migrations")
+	// ErrIrreversibleMigration is returned by Down for a migration without a Down step
+	ErrIrreversibleMigration = errors.New("migration cannot be rolled back")
+	// ErrUnknownMigration is returned when the database records a version the service does not define
+	ErrUnknownMigration = errors.New("unknown migration version")
+)
+
+// Migration is one versioned schema change of a service
+type Migration struct {
+	Version     uint   // Increasing within a service, starting at 1
+	Description string
+	Up          func(tx *gorm.DB) error
+	Down        func(tx *gorm.DB) error // nil when the change cannot be undone
+}
+
+// MigrationSet is the ordered migrations of one service
+type MigrationSet struct {
+	Service    string
+	Migrations []Migration
+}
+
+// SchemaMigration records a migration applied to a database
+type SchemaMigration struct {
+	Service     string    `gorm:"primaryKey;size:100"`
+	Version     uint      `gorm:"primaryKey;autoIncrement:false"`
+	Description string    `gorm:"size:255"`
+	AppliedAt   time.Time `gorm:"not null"`
+}
+
+// TableName specifies the table name for SchemaMigration model
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Service     string
+	Version     uint
+	Description string
+	AppliedAt   *time.Time // nil while pending
+}
+
+// Migrator applies and rolls back the migrations of a service, recording them in schema_migrations
+type Migrator struct {
+	db  *gorm.DB
+	set MigrationSet
+}
+
+// NewMigrator creates a migrator for set. Versions must be unique and increasing, and every migration needs an Up step.
+func NewMigrator(db *gorm.DB, set MigrationSet) (*Migrator, error) {
+	if set.Service == "" {
+		return nil, fmt.Errorf("migration service name is required")
+	}
+	var last uint
+	for _, m := range set.Migrations {
+		if m.Version <= last {
+			return nil, fmt.Errorf("%s migration %d must have a version above %d", set.Service, m.Version, last)
+		}
+		if m.Up == nil {
+			return nil, fmt.Errorf("%s migration %d has no up step", set.Service, m.Version)
+		}
+		last = m.Version
+	}
+	return &Migrator{db: db, set: set}, nil
+}
+
+// Service returns the name of the service whose migrations m runs
+func (m *Migrator) Service() string {
+	return m.set.Service
+}
+
+// MigrateUp applies the pending migrations of set to db
+func MigrateUp(ctx context.Context, db *gorm.DB, set MigrationSet) error {
+	migrator, err := NewMigrator(db, set)
+	if err != nil {
+		return err
+	}
+	_, err = migrator.Up(ctx)
+	return err
+}
+
+// Up applies every pending migration in version order and returns the ones it applied.
+// Each migration and its schema_migrations row are committed together where the driver supports
+// transactional DDL; MySQL commits DDL implicitly, so there a failed step may leave partial changes.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, migration := range m.set.Migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := m.runStep(ctx, migration.Up, func(tx *gorm.DB) error {
+			return tx.Create(&SchemaMigration{
+				Service:     m.set.Service,
+				Version:     migration.Version,
+				Description: migration.Description,
+				AppliedAt:   time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return ran, fmt.Errorf("apply %s migration %d (%s): %w", m.set.Service, migration.Version, migration.Description, err)
+		}
+		ran = append(ran, migration)
+	}
+	return ran, nil
+}
+
+// Down rolls back the most recently applied migration and returns it
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var last SchemaMigration
+	err := m.db.WithContext(ctx).
+		Where("service = ?", m.set.Service).
+		Order("version DESC").
+		First(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%s: %w", m.set.Service, ErrNoAppliedMigrations)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get last %s migration: %w", m.set.Service, err)
+	}
+
+	migration, ok := m.find(last.Version)
+	if !ok {
+		return nil, fmt.Errorf("%s migration %d: %w", m.set.Service, last.Version, ErrUnknownMigration)
+	}
+	if migration.Down == nil {
+		return nil, fmt.Errorf("%s migration %d (%s): %w", m.set.Service, migration.Version, migration.Description, ErrIrreversibleMigration)
+	}
+
+	err = m.runStep(ctx, migration.Down, func(tx *gorm.DB) error {
+		return tx.Where("service = ? AND version = ?", m.set.Service, migration.Version).Delete(&SchemaMigration{}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("roll back %s migration %d (%s): %w", m.set.Service, migration.Version, migration.Description, err)
+	}
+	return &migration, nil
+}
+
+// Status lists every migration of the service in version order with the time it was applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.set.Migrations))
+	for _, migration := range m.set.Migrations {
+		status := MigrationStatus{
+			Service:     m.set.Service,
+			Version:     migration.Version,
+			Description: migration.Description,
+		}
+		if record, ok := applied[migration.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// appliedVersions creates schema_migrations if needed and returns the service's applied migrations by version
+func (m *Migrator) appliedVersions(ctx context.Context) (map[uint]SchemaMigration, error) {
+	db := m.db.WithContext(ctx)
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("migrate schema_migrations table: %w", err)
+	}
+
+	var records []SchemaMigration
+	if err := db.Where("service = ?", m.set.Service).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("list %s migrations: %w", m.set.Service, err)
+	}
+
+	applied := make(map[uint]SchemaMigration, len(records))
+	for _, record := range records {
+		if _, ok := m.find(record.Version); !ok {
+			return nil, fmt.Errorf("%s migration %d: %w", m.set.Service, record.Version, ErrUnknownMigration)
+		}
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// runStep runs a migration step and then record, in one transaction unless the driver commits DDL implicitly
+func (m *Migrator) runStep(ctx context.Context, step, record func(tx *gorm.DB) error) error {
+	run := func(tx *gorm.DB) error {
+		if err := step(tx); err != nil {
+			return err
+		}
+		return record(tx)
+	}
+
+	db := m.db.WithContext(ctx)
+	if db.Dialector.Name() == "mysql" {
+		return run(db)
+	}
+	return db.Transaction(run)
+}
+
+// find returns the migration with the given version
+func (m *Migrator) find(version uint) (Migration, bool) {
+	for _, migration := range m.set.Migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// MigratedWidget is the table created by the first test migration
+type MigratedWidget struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+// MigratedGadget is the table created by the second test migration
+type MigratedGadget struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+// testMigrationSet returns two reversible migrations creating one table each
+func testMigrationSet() MigrationSet {
+	return MigrationSet{
+		Service: "widgets",
+		Migrations: []Migration{
+			{
+				Version:     1,
+				Description: "create widgets",
+				Up:          func(tx *gorm.DB) error { return tx.AutoMigrate(&MigratedWidget{}) },
+				Down:        func(tx *gorm.DB) error { return tx.Migrator().DropTable(&MigratedWidget{}) },
+			},
+			{
+				Version:     2,
+				Description: "create gadgets",
+				Up:          func(tx *gorm.DB) error { return tx.AutoMigrate(&MigratedGadget{}) },
+				Down:        func(tx *gorm.DB) error { return tx.Migrator().DropTable(&MigratedGadget{}) },
+			},
+		},
+	}
+}
+
+// TestMigrator tests applying, inspecting and rolling back migrations
+func TestMigrator(t *testing.T) {
+	db := setupTestDB(t)
+	migrator, err := NewMigrator(db, testMigrationSet())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("status before applying", func(t *testing.T) {
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		require.Len(t, statuses, 2)
+		assert.Nil(t, statuses[0].AppliedAt)
+		assert.Nil(t, statuses[1].AppliedAt)
+	})
+
+	t.Run("up applies both migrations once", func(t *testing.T) {
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		require.Len(t, applied, 2)
+		assert.Equal(t, uint(1), applied[0].Version)
+		assert.Equal(t, uint(2), applied[1].Version)
+		assert.True(t, db.Migrator().HasTable(&MigratedWidget{}))
+		assert.True(t, db.Migrator().HasTable(&MigratedGadget{}))
+
+		applied, err = migrator.Up(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		for _, status := range statuses {
+			require.NotNil(t, status.AppliedAt, "version %d", status.Version)
+		}
+	})
+
+	t.Run("down rolls back the latest migration", func(t *testing.T) {
+		rolledBack, err := migrator.Down(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint(2), rolledBack.Version)
+		assert.True(t, db.Migrator().HasTable(&MigratedWidget{}))
+		assert.False(t, db.Migrator().HasTable(&MigratedGadget{}))
+
+		statuses, err := migrator.Status(ctx)
+		require.NoError(t, err)
+		assert.NotNil(t, statuses[0].AppliedAt)
+		assert.Nil(t, statuses[1].AppliedAt)
+	})
+
+	t.Run("up reapplies the rolled back migration", func(t *testing.T) {
+		applied, err := migrator.Up(ctx)
+		require.NoError(t, err)
+		require.Len(t, applied, 1)
+		assert.Equal(t, uint(2), applied[0].Version)
+		assert.True(t, db.Migrator().HasTable(&MigratedGadget{}))
+	})
+
+	t.Run("down past the first migration", func(t *testing.T) {
+		_, err := migrator.Down(ctx)
+		require.NoError(t, err)
+		_, err = migrator.Down(ctx)
+		require.NoError(t, err)
+
+		_, err = migrator.Down(ctx)
+		assert.ErrorIs(t, err, ErrNoAppliedMigrations)
+	})
+}
+
+// TestMigrator_FailedStep tests that a failing step is rolled back with its schema_migrations row
+func TestMigrator_FailedStep(t *testing.T) {
+	db := setupTestDB(t)
+	set := testMigrationSet()
+	set.Migrations[1].Up = func(tx *gorm.DB) error {
+		if err := tx.AutoMigrate(&MigratedGadget{}); err != nil {
+			return err
+		}
+		return errors.New("boom")
+	}
+	migrator, err := NewMigrator(db, set)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	applied, err := migrator.Up(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+	require.Len(t, applied, 1)
+	assert.False(t, db.Migrator().HasTable(&MigratedGadget{}), "sqlite rolls back the failed step")
+
+	statuses, err := migrator.Status(ctx)
+	require.NoError(t, err)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+}
+
+// TestMigrator_Irreversible tests rolling back a migration without a Down step
+func TestMigrator_Irreversible(t *testing.T) {
+	db := setupTestDB(t)
+	set := testMigrationSet()
+	set.Migrations = set.Migrations[:1]
+	set.Migrations[0].Down = nil
+	migrator, err := NewMigrator(db, set)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	_, err = migrator.Down(ctx)
+	assert.ErrorIs(t, err, ErrIrreversibleMigration)
+	assert.True(t, db.Migrator().HasTable(&MigratedWidget{}))
+}
+
+// TestNewMigrator_InvalidSet tests that sets with unordered versions or missing steps are rejected
+func TestNewMigrator_InvalidSet(t *testing.T) {
+	db := setupTestDB(t)
+	up := func(*gorm.DB) error { return nil }
+
+	tests := []struct {
+		name string
+		set  MigrationSet
+	}{
+		{name: "missing service", set: MigrationSet{Migrations: []Migration{{Version: 1, Up: up}}}},
+		{name: "zero version", set: MigrationSet{Service: "s", Migrations: []Migration{{Version: 0, Up: up}}}},
+		{name: "unordered versions", set: MigrationSet{Service: "s", Migrations: []Migration{{Version: 2, Up: up}, {Version: 1, Up: up}}}},
+		{name: "duplicate version", set: MigrationSet{Service: "s", Migrations: []Migration{{Version: 1, Up: up}, {Version: 1, Up: up}}}},
+		{name: "missing up", set: MigrationSet{Service: "s", Migrations: []Migration{{Version: 1}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewMigrator(db, tt.set)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// Database names reported by PoolStats
+const (
+	PoolMaster        = "master"
+	PoolMasterReplica = "master_replica" // Followed by the replica's index, e.g. master_replica_0
+	PoolTenantLegacy  = "tenant_legacy"  // DatabaseManager.TenantDB
+	PoolTenant        = "tenant"         // A cached tenant connection, with TenantID set
+)
+
+// PoolStats is the connection pool state of one open database connection
+type PoolStats struct {
+	Database string
+	TenantID string // Only set for tenant connections
+	Stats    sql.DBStats
+}
+
+// PoolStats returns the connection pool state of the master database, its read replicas, the legacy tenant
+// database and every cached tenant connection. Tenant connections evicted from the cache are not reported.
+func (m *DatabaseManager) PoolStats() []PoolStats {
+	var pools []PoolStats
+	if stats, ok := dbStats(m.MasterDB); ok {
+		pools = append(pools, PoolStats{Database: PoolMaster, Stats: stats})
+	}
+	for i, replica := range m.MasterReplicas {
+		if stats, ok := dbStats(replica); ok {
+			pools = append(pools, PoolStats{Database: fmt.Sprintf("%s_%d", PoolMasterReplica, i), Stats: stats})
+		}
+	}
+	if stats, ok := dbStats(m.TenantDB); ok {
+		pools = append(pools, PoolStats{Database: PoolTenantLegacy, Stats: stats})
+	}
+	if m.TenantConnManager != nil {
+		pools = append(pools, m.TenantConnManager.PoolStats()...)
+	}
+	return pools
+}
+
+// PoolStats returns the connection pool state of every cached tenant connection, ordered by tenant ID
+func (m *TenantConnectionManager) PoolStats() []PoolStats {
+	m.mu.RLock()
+	pools := make([]PoolStats, 0, len(m.conns))
+	for tenantID, conn := range m.conns {
+		if stats, ok := dbStats(conn.db); ok {
+			pools = append(pools, PoolStats{Database: PoolTenant, TenantID: tenantID, Stats: stats})
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(pools, func(i, j int) bool { return pools[i].TenantID < pools[j].TenantID })
+	return pools
+}
+
+// dbStats returns the pool statistics of db, or false when db has no open connection pool
+func dbStats(db *gorm.DB) (sql.DBStats, bool) {
+	if db == nil || db.Config == nil || db.ConnPool == nil {
+		return sql.DBStats{}, false
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return sql.DBStats{}, false
+	}
+	return sqlDB.Stats(), true
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidTenantConfig is returned when a tenant to provision is missing required settings
+var ErrInvalidTenantConfig = errors.New("invalid tenant config")
+
+// TenantExistsError is returned by ProvisionTenant when a tenant with the same ID is already registered,
+// active or not
+type TenantExistsError struct {
+	TenantID string
+}
+
+func (e *TenantExistsError) Error() string {
+	return fmt.Sprintf("tenant %s already exists", e.TenantID)
+}
+
+// TenantMigration creates or updates a service's tables in a tenant database
+type TenantMigration func(db *gorm.DB) error
+
+// RegisterTenantMigration adds a migration run by ProvisionTenant against every new tenant database.
+// Services register their schema at startup; migrations run in registration order.
+func (m *TenantConnectionManager) RegisterTenantMigration(migration TenantMigration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.migrations = append(m.migrations, migration)
+}
+
+// ProvisionTenant onboards a tenant: it validates the connection settings, opens the tenant database,
+// runs the registered tenant migrations and then records the tenant as active in the master database.
+// The tenant only becomes visible to GetTenantDB once its schema exists. tenant.Cnn is stored as given,
+// so it may already be encrypted for the configured SecretDecryptor.
+func (m *TenantConnectionManager) ProvisionTenant(ctx context.Context, tenant Tenant) error {
+	if err := validateTenant(&tenant); err != nil {
+		return err
+	}
+
+	var count int64
+	if err := m.masterDB.WithContext(ctx).Model(&Tenant{}).Where("id = ?", tenant.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("check tenant %s exists: %w", tenant.ID, err)
+	}
+	if count > 0 {
+		return &TenantExistsError{TenantID: tenant.ID}
+	}
+
+	// Migrate through a dedicated connection; the cached one is opened on first use after activation
+	effective := tenant
+	effective.ApplyPoolDefaults()
+	db, err := m.openTenantDB(ctx, &effective)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := closeDB(db); err != nil {
+			m.logger.Warn("Failed to close provisioning connection",
+				zap.String("tenant_id", tenant.ID),
+				zap.Error(err))
+		}
+	}()
+
+	m.mu.RLock()
+	migrations := append([]TenantMigration(nil), m.migrations...)
+	m.mu.RUnlock()
+	for _, migrate := range migrations {
+		if err := migrate(db.WithContext(ctx)); err != nil {
+			return fmt.Errorf("migrate database for tenant %s: %w", tenant.ID, err)
+		}
+	}
+
+	tenant.IsActive = true
+	if err := m.masterDB.WithContext(ctx).Create(&tenant).Error; err != nil {
+		// Another request may have provisioned the same ID since the check above
+		if exists := m.masterDB.WithContext(ctx).Model(&Tenant{}).Where("id = ?", tenant.ID).Count(&count); exists.Error == nil && count > 0 {
+			return &TenantExistsError{TenantID: tenant.ID}
+		}
+		return fmt.Errorf("create tenant %s: %w", tenant.ID, err)
+	}
+
+	m.logger.Info("Tenant provisioned",
+		zap.String("tenant_id", tenant.ID),
+		zap.String("db_type", tenant.DBType),
+		zap.Int("migrations", len(migrations)))
+	return nil
+}
+
+// DeactivateTenant marks a tenant inactive and closes its cached connection, so GetTenantDB rejects it.
+// It returns a not found error wrapping gorm.ErrRecordNotFound when no such tenant exists.
+func (m *TenantConnectionManager) DeactivateTenant(ctx context.Context, tenantID string) error {
+	result := m.masterDB.WithContext(ctx).Model(&Tenant{}).Where("id = ?", tenantID).Update("is_active", false)
+	if result.Error != nil {
+		return fmt.Errorf("deactivate tenant %s: %w", tenantID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("tenant %s not found: %w", tenantID, gorm.ErrRecordNotFound)
+	}
+
+	if err := m.CloseTenant(tenantID); err != nil {
+		return err
+	}
+	m.logger.Info("Tenant deactivated", zap.String("tenant_id", tenantID))
+	return nil
+}
+
+// validateTenant checks the settings ProvisionTenant needs before touching any database
+func validateTenant(tenant *Tenant) error {
+	switch {
+	case tenant.ID == "":
+		return fmt.Errorf("%w: id is required", ErrInvalidTenantConfig)
+	case len(tenant.ID) > 100:
+		return fmt.Errorf("%w: id must be at most 100 characters", ErrInvalidTenantConfig)
+	case tenant.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidTenantConfig)
+	case tenant.Cnn == "":
+		return fmt.Errorf("%w: connection string is required", ErrInvalidTenantConfig)
+	}
+
+	switch tenant.DBType {
+	case "postgresql", "postgres":
+	case "mysql", "sqlite":
+		if err := requireNoSSLMode(tenant); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTenantConfig, err)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported database type %q", ErrInvalidTenantConfig, tenant.DBType)
+	}
+
+	if tenant.DBMaxOpenConns < 0 || tenant.DBMaxIdleConns < 0 || tenant.DBConnMaxLifetimeSeconds < 0 {
+		return fmt.Errorf("%w: pool settings must not be negative", ErrInvalidTenantConfig)
+	}
+	return nil
+}
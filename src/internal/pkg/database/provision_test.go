@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+)
+
+// TestTenantConnectionManager_ProvisionTenant tests onboarding a SQLite tenant end to end
+func TestTenantConnectionManager_ProvisionTenant(t *testing.T) {
+	masterDB := setupTestMasterDB(t)
+	manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+	defer manager.CloseAll()
+	manager.RegisterTenantMigration(func(db *gorm.DB) error {
+		return db.AutoMigrate(&TestEntity{})
+	})
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	tenant := Tenant{ID: "provisioned", Name: "Provisioned", DBType: "sqlite", Cnn: filepath.Join(dir, "provisioned.db")}
+
+	t.Run("creates the schema and an active tenant", func(t *testing.T) {
+		require.NoError(t, manager.ProvisionTenant(ctx, tenant))
+
+		var stored Tenant
+		require.NoError(t, masterDB.First(&stored, "id = ?", tenant.ID).Error)
+		assert.True(t, stored.IsActive)
+		assert.Equal(t, tenant.Cnn, stored.Cnn)
+
+		db, err := manager.GetTenantDB(ctx, tenant.ID)
+		require.NoError(t, err)
+		assert.True(t, db.Migrator().HasTable(&TestEntity{}))
+		require.NoError(t, db.Create(&TestEntity{Name: "first"}).Error)
+	})
+
+	t.Run("existing tenant is rejected", func(t *testing.T) {
+		err := manager.ProvisionTenant(ctx, tenant)
+
+		var exists *TenantExistsError
+		require.ErrorAs(t, err, &exists)
+		assert.Equal(t, tenant.ID, exists.TenantID)
+	})
+
+	t.Run("invalid config is rejected before touching the master database", func(t *testing.T) {
+		for name, bad := range map[string]Tenant{
+			"missing id":   {Name: "x", DBType: "sqlite", Cnn: filepath.Join(dir, "x.db")},
+			"missing name": {ID: "x", DBType: "sqlite", Cnn: filepath.Join(dir, "x.db")},
+			"missing cnn":  {ID: "x", Name: "x", DBType: "sqlite"},
+			"bad db type":  {ID: "x", Name: "x", DBType: "oracle", Cnn: "whatever"},
+			"ssl on mysql": {ID: "x", Name: "x", DBType: "mysql", Cnn: "whatever", DBSSLMode: "require"},
+		} {
+			err := manager.ProvisionTenant(ctx, bad)
+			assert.ErrorIs(t, err, ErrInvalidTenantConfig, name)
+		}
+
+		var count int64
+		require.NoError(t, masterDB.Model(&Tenant{}).Where("id = ?", "x").Count(&count).Error)
+		assert.Zero(t, count)
+	})
+
+	t.Run("failed migration leaves no tenant row", func(t *testing.T) {
+		failing := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+		defer failing.CloseAll()
+		failing.RegisterTenantMigration(func(*gorm.DB) error { return errors.New("boom") })
+
+		err := failing.ProvisionTenant(ctx, Tenant{ID: "unmigrated", Name: "Unmigrated", DBType: "sqlite", Cnn: filepath.Join(dir, "unmigrated.db")})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "boom")
+
+		var count int64
+		require.NoError(t, masterDB.Model(&Tenant{}).Where("id = ?", "unmigrated").Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
+
+// TestTenantConnectionManager_DeactivateTenant tests that deactivation hides the tenant and evicts its connection
+func TestTenantConnectionManager_DeactivateTenant(t *testing.T) {
+	masterDB := setupTestMasterDB(t)
+	manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+	defer manager.CloseAll()
+	ctx := context.Background()
+
+	tenant := Tenant{ID: "retiring", Name: "Retiring", DBType: "sqlite", Cnn: filepath.Join(t.TempDir(), "retiring.db")}
+	require.NoError(t, manager.ProvisionTenant(ctx, tenant))
+	_, err := manager.GetTenantDB(ctx, tenant.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, manager.Stats().Cached)
+
+	require.NoError(t, manager.DeactivateTenant(ctx, tenant.ID))
+	assert.Equal(t, 0, manager.Stats().Cached)
+
+	var stored Tenant
+	require.NoError(t, masterDB.First(&stored, "id = ?", tenant.ID).Error)
+	assert.False(t, stored.IsActive)
+
+	_, err = manager.GetTenantDB(ctx, tenant.ID)
+	assert.Error(t, err)
+
+	t.Run("provisioning the same id again is rejected", func(t *testing.T) {
+		var exists *TenantExistsError
+		assert.ErrorAs(t, manager.ProvisionTenant(ctx, tenant), &exists)
+	})
+
+	t.Run("unknown tenant", func(t *testing.T) {
+		assert.ErrorIs(t, manager.DeactivateTenant(ctx, "missing"), gorm.ErrRecordNotFound)
+	})
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// replicaPool hands out read replica connections round robin
+type replicaPool struct {
+	dbs  []*gorm.DB
+	next atomic.Uint64
+}
+
+// newReplicaPool creates a pool of the given replicas, or returns nil when there are none
+func newReplicaPool(replicas []*gorm.DB) *replicaPool {
+	var dbs []*gorm.DB
+	for _, db := range replicas {
+		if db != nil {
+			dbs = append(dbs, db)
+		}
+	}
+	if len(dbs) == 0 {
+		return nil
+	}
+	return &replicaPool{dbs: dbs}
+}
+
+// pick returns the next replica, or nil for a nil pool
+func (p *replicaPool) pick() *gorm.DB {
+	if p == nil {
+		return nil
+	}
+	return p.dbs[(p.next.Add(1)-1)%uint64(len(p.dbs))]
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// seedEntities inserts entities directly into db, bypassing any repository
+func seedEntities(t *testing.T, db *gorm.DB, entities ...*TestEntity) {
+	for _, entity := range entities {
+		require.NoError(t, db.Create(entity).Error)
+	}
+}
+
+// TestBaseRepository_ReadOnly tests that a read-only repository reads from the replica and writes to the primary
+func TestBaseRepository_ReadOnly(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reads hit the replica", func(t *testing.T) {
+		primary := setupTestDB(t)
+		replica := setupTestDB(t)
+		seedEntities(t, primary, &TestEntity{Name: "on primary", Status: "active", Value: 1})
+		seedEntities(t, replica,
+			&TestEntity{Name: "on replica", Status: "active", Value: 2},
+			&TestEntity{Name: "also on replica", Status: "active", Value: 3})
+
+		repo := NewBaseRepositoryWithReplicas[TestEntity](primary, replica).ReadOnly()
+
+		entity, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "on replica", entity.Name)
+
+		all, err := repo.GetAll(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+
+		matching, err := repo.GetWhere(ctx, map[string]interface{}{"value": 3})
+		require.NoError(t, err)
+		require.Len(t, matching, 1)
+		assert.Equal(t, "also on replica", matching[0].Name)
+
+		count, err := repo.Count(ctx, map[string]interface{}{"status": "active"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("writes hit the primary", func(t *testing.T) {
+		primary := setupTestDB(t)
+		replica := setupTestDB(t)
+		seedEntities(t, primary, &TestEntity{Name: "original", Status: "active"})
+		seedEntities(t, replica, &TestEntity{Name: "original", Status: "active"})
+
+		repo := NewBaseRepositoryWithReplicas[TestEntity](primary, replica).ReadOnly()
+
+		require.NoError(t, repo.Insert(ctx, &TestEntity{Name: "inserted", Status: "active"}))
+		require.NoError(t, repo.UpdateByID(ctx, 1, &TestEntity{Name: "updated"}))
+
+		var onPrimary []TestEntity
+		require.NoError(t, primary.Order("id").Find(&onPrimary).Error)
+		require.Len(t, onPrimary, 2)
+		assert.Equal(t, "updated", onPrimary[0].Name)
+		assert.Equal(t, "inserted", onPrimary[1].Name)
+
+		var onReplica []TestEntity
+		require.NoError(t, replica.Find(&onReplica).Error)
+		require.Len(t, onReplica, 1)
+		assert.Equal(t, "original", onReplica[0].Name)
+	})
+
+	t.Run("reads are spread over replicas", func(t *testing.T) {
+		primary := setupTestDB(t)
+		first := setupTestDB(t)
+		second := setupTestDB(t)
+		seedEntities(t, first, &TestEntity{Name: "first"})
+		seedEntities(t, second, &TestEntity{Name: "second"}, &TestEntity{Name: "second"})
+
+		repo := NewBaseRepositoryWithReplicas[TestEntity](primary, first, second).ReadOnly()
+
+		var counts []int64
+		for i := 0; i < 4; i++ {
+			count, err := repo.Count(ctx, nil)
+			require.NoError(t, err)
+			counts = append(counts, count)
+		}
+		assert.Equal(t, []int64{1, 2, 1, 2}, counts)
+	})
+
+	t.Run("without replicas reads hit the primary", func(t *testing.T) {
+		primary := setupTestDB(t)
+		seedEntities(t, primary, &TestEntity{Name: "on primary"})
+
+		repo := NewBaseRepositoryWithReplicas[TestEntity](primary).ReadOnly()
+		assert.Equal(t, primary, repo.ReadDB())
+
+		entity, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "on primary", entity.Name)
+	})
+
+	t.Run("only the read-only variant uses replicas", func(t *testing.T) {
+		primary := setupTestDB(t)
+		replica := setupTestDB(t)
+		seedEntities(t, primary, &TestEntity{Name: "on primary"})
+		seedEntities(t, replica, &TestEntity{Name: "on replica"})
+
+		repo := NewMasterRepo[TestEntity](&DatabaseManager{MasterDB: primary, MasterReplicas: []*gorm.DB{replica}})
+
+		entity, err := repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "on primary", entity.Name)
+
+		entity, err = repo.ReadOnly().GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "on replica", entity.Name)
+	})
+}
@@ -2,14 +2,20 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BaseRepository provides common CRUD operations for any entity type
 type BaseRepository[T any] struct {
-	db *gorm.DB
+	db       *gorm.DB
+	replicas *replicaPool // Read replicas of db, nil when there are none
+	readOnly bool         // Reads go to a replica, see ReadOnly
 }
 
 // NewBaseRepository creates a new BaseRepository
@@ -17,61 +23,138 @@ func NewBaseRepository[T any](db *gorm.DB) *BaseRepository[T] {
 	return &BaseRepository[T]{db: db}
 }
 
-// Insert inserts a new entity into the database
-func (r *BaseRepository[T]) Insert(ctx context.Context, entity *T) error {
-	if err := r.db.WithContext(ctx).Create(entity).Error; err != nil {
-		return fmt.Errorf("insert entity: %w", err)
+// NewBaseRepositoryWithReplicas creates a BaseRepository whose ReadOnly variant spreads reads over the
+// given read replicas of db. Without replicas it behaves like NewBaseRepository.
+func NewBaseRepositoryWithReplicas[T any](db *gorm.DB, replicas ...*gorm.DB) *BaseRepository[T] {
+	return &BaseRepository[T]{db: db, replicas: newReplicaPool(replicas)}
+}
+
+// ReadOnly returns a repository that sends reads (the Get methods, Count, Exists and GroupByCount) to a
+// read replica, round robin, while writes still go to the primary. Replicas may lag behind the primary,
+// so use it for reads that do not need to see a write made moments before. Reads fall back to the
+// primary when no replicas are configured.
+func (r *BaseRepository[T]) ReadOnly() *BaseRepository[T] {
+	return &BaseRepository[T]{db: r.db, replicas: r.replicas, readOnly: true}
+}
+
+// ReadDB returns the connection reads go to: a replica for a ReadOnly repository, otherwise the primary
+func (r *BaseRepository[T]) ReadDB() *gorm.DB {
+	if r.readOnly {
+		if replica := r.replicas.pick(); replica != nil {
+			return replica
+		}
 	}
-	return nil
+	return r.db
 }
 
-// InsertBatch inserts multiple entities into the database
+// Insert inserts a new entity into the database. CreatedBy and UpdatedBy are set from the user ID in ctx.
+func (r *BaseRepository[T]) Insert(ctx context.Context, entity *T) error {
+	return insert(r.db.WithContext(ctx), entity)
+}
+
+// InsertBatch inserts multiple entities into the database. CreatedBy and UpdatedBy are set from the user ID in ctx.
 func (r *BaseRepository[T]) InsertBatch(ctx context.Context, entities []*T) error {
-	if len(entities) == 0 {
-		return nil
-	}
-	if err := r.db.WithContext(ctx).Create(entities).Error; err != nil {
-		return fmt.Errorf("insert batch entities: %w", err)
-	}
-	return nil
+	return insertBatch(r.db.WithContext(ctx), entities)
 }
 
-// UpdateByID updates an entity by its ID
+// UpdateByID updates an entity by its ID. UpdatedBy is set from the user ID in ctx.
 func (r *BaseRepository[T]) UpdateByID(ctx context.Context, id uint, entity *T) error {
-	if err := r.db.WithContext(ctx).Model(entity).Where("id = ?", id).Updates(entity).Error; err != nil {
-		return fmt.Errorf("update entity by id %d: %w", id, err)
-	}
-	return nil
+	_, err := updateByID(r.db.WithContext(ctx), id, entity)
+	return err
+}
+
+// UpdateByIDStrict updates an entity by its ID and returns a not found error wrapping
+// gorm.ErrRecordNotFound when no row was updated
+func (r *BaseRepository[T]) UpdateByIDStrict(ctx context.Context, id uint, entity *T) error {
+	return updateByIDStrict(r.db.WithContext(ctx), id, entity)
+}
+
+// UpdateByIDReturning updates an entity by its ID and returns the row as stored after the update.
+// It returns a not found error wrapping gorm.ErrRecordNotFound when no row was updated.
+func (r *BaseRepository[T]) UpdateByIDReturning(ctx context.Context, id uint, entity *T) (*T, error) {
+	return updateByIDReturning(r.db.WithContext(ctx), id, entity)
+}
+
+// UpdateByIDWithVersion updates an entity by its ID using optimistic locking on the model's Version field.
+// The row is only updated if its version is still expectedVersion; the version is then incremented in the
+// database and in entity. It returns an error wrapping ErrStaleObject when another update got there first,
+// and a not found error wrapping gorm.ErrRecordNotFound when the row does not exist.
+func (r *BaseRepository[T]) UpdateByIDWithVersion(ctx context.Context, id uint, expectedVersion uint, entity *T) error {
+	return updateByIDWithVersion(r.db.WithContext(ctx), id, expectedVersion, entity)
 }
 
 // UpdateWhere updates entities matching conditions with the provided updates
 func (r *BaseRepository[T]) UpdateWhere(ctx context.Context, conditions map[string]interface{}, updates map[string]interface{}) error {
-	query := r.db.WithContext(ctx).Model(new(T))
-	for key, value := range conditions {
-		query = query.Where(fmt.Sprintf("%s = ?", key), value)
-	}
-	if err := query.Updates(updates).Error; err != nil {
-		return fmt.Errorf("update entities where conditions: %w", err)
-	}
-	return nil
+	_, err := r.UpdateWhereCount(ctx, conditions, updates)
+	return err
+}
+
+// UpdateWhereCount updates entities matching conditions and returns how many rows were affected
+func (r *BaseRepository[T]) UpdateWhereCount(ctx context.Context, conditions map[string]interface{}, updates map[string]interface{}) (int64, error) {
+	return updateWhere[T](r.db.WithContext(ctx), conditions, updates)
+}
+
+// Upsert inserts the entity or, when a row with the same conflict columns exists, updates updateColumns.
+// An empty updateColumns updates every column.
+func (r *BaseRepository[T]) Upsert(ctx context.Context, entity *T, conflictColumns []string, updateColumns []string) error {
+	return upsert(r.db.WithContext(ctx), entity, conflictColumns, updateColumns)
+}
+
+// FindOrCreate returns the entity matching conditions, creating it when none exists
+func (r *BaseRepository[T]) FindOrCreate(ctx context.Context, conditions map[string]interface{}, entity *T) (*T, bool, error) {
+	return findOrCreate(r.db.WithContext(ctx), conditions, entity)
 }
 
 // GetByID retrieves an entity by its ID
 func (r *BaseRepository[T]) GetByID(ctx context.Context, id uint) (*T, error) {
 	var entity T
-	if err := r.db.WithContext(ctx).First(&entity, id).Error; err != nil {
+	if err := r.ReadDB().WithContext(ctx).First(&entity, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("entity with id %d not found", id)
+			return nil, fmt.Errorf("entity with id %d not found: %w", id, err)
 		}
 		return nil, fmt.Errorf("get entity by id %d: %w", id, err)
 	}
 	return &entity, nil
 }
 
+// GetByUUID retrieves an entity keyed by a UUID string, such as one embedding BaseModel
+func (r *BaseRepository[T]) GetByUUID(ctx context.Context, id string) (*T, error) {
+	return getByUUID[T](r.ReadDB().WithContext(ctx), id)
+}
+
+// GetByIDs retrieves the entities with the given IDs; IDs without a matching entity are skipped
+func (r *BaseRepository[T]) GetByIDs(ctx context.Context, ids []uint) ([]*T, error) {
+	return getByIDs[T](r.ReadDB().WithContext(ctx), ids)
+}
+
+// GetByIDsMap retrieves the entities with the given IDs keyed by ID, so callers can look them up in input order.
+// IDs without a matching entity have no entry.
+func (r *BaseRepository[T]) GetByIDsMap(ctx context.Context, ids []uint) (map[uint]*T, error) {
+	return getByIDsMap[T](r.ReadDB().WithContext(ctx), ids)
+}
+
+// GetAfterCursor retrieves up to limit entities after afterID using keyset pagination on id, in
+// CursorOrderAsc (the default) or CursorOrderDesc order. It also returns the afterID of the next page,
+// or 0 on the last page. Scopes narrow the rows before paging.
+func (r *BaseRepository[T]) GetAfterCursor(ctx context.Context, afterID uint, limit int, orderBy string, scopes ...func(*gorm.DB) *gorm.DB) ([]*T, uint, error) {
+	return getAfterCursor[T](r.ReadDB().WithContext(ctx), afterID, limit, orderBy, scopes...)
+}
+
+// GetByField retrieves the first entity whose field equals value. The field must be a column of the model;
+// a missing row returns a not found error wrapping gorm.ErrRecordNotFound.
+func (r *BaseRepository[T]) GetByField(ctx context.Context, field string, value interface{}) (*T, error) {
+	return getByField[T](r.ReadDB().WithContext(ctx), field, value)
+}
+
+// GetManyByField retrieves the entities whose field equals value. The field must be a column of the model.
+func (r *BaseRepository[T]) GetManyByField(ctx context.Context, field string, value interface{}) ([]*T, error) {
+	return getManyByField[T](r.ReadDB().WithContext(ctx), field, value)
+}
+
 // GetAll retrieves all entities with optional limit and offset
 func (r *BaseRepository[T]) GetAll(ctx context.Context, limit, offset int) ([]*T, error) {
 	var entities []*T
-	query := r.db.WithContext(ctx)
+	query := r.ReadDB().WithContext(ctx)
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -89,7 +172,7 @@ func (r *BaseRepository[T]) GetAll(ctx context.Context, limit, offset int) ([]*T
 // GetWhere retrieves entities matching the provided conditions
 func (r *BaseRepository[T]) GetWhere(ctx context.Context, conditions map[string]interface{}) ([]*T, error) {
 	var entities []*T
-	query := r.db.WithContext(ctx)
+	query := r.ReadDB().WithContext(ctx)
 	
 	for key, value := range conditions {
 		query = query.Where(fmt.Sprintf("%s = ?", key), value)
@@ -101,6 +184,20 @@ func (r *BaseRepository[T]) GetWhere(ctx context.Context, conditions map[string]
 	return entities, nil
 }
 
+// GetWhereAdvanced retrieves entities matching all of the provided criteria
+func (r *BaseRepository[T]) GetWhereAdvanced(ctx context.Context, criteria []Criterion) ([]*T, error) {
+	var entities []*T
+	query, err := applyCriteria(r.ReadDB().WithContext(ctx), new(T), criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := query.Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("get entities where criteria: %w", err)
+	}
+	return entities, nil
+}
+
 // DeleteByID deletes an entity by its ID
 func (r *BaseRepository[T]) DeleteByID(ctx context.Context, id uint) error {
 	if err := r.db.WithContext(ctx).Delete(new(T), id).Error; err != nil {
@@ -109,24 +206,32 @@ func (r *BaseRepository[T]) DeleteByID(ctx context.Context, id uint) error {
 	return nil
 }
 
-// DeleteWhere deletes entities matching the provided conditions
+// DeleteByUUID deletes an entity keyed by a UUID string, such as one embedding BaseModel
+func (r *BaseRepository[T]) DeleteByUUID(ctx context.Context, id string) error {
+	return deleteByUUID[T](r.db.WithContext(ctx), id)
+}
+
+// RestoreByID restores a soft-deleted entity by its ID
+func (r *BaseRepository[T]) RestoreByID(ctx context.Context, id uint) error {
+	return restoreByID[T](r.db.WithContext(ctx), id)
+}
+
+// DeleteWhere deletes entities matching the provided conditions. A condition key is a column compared for
+// equality, or a clause with its own placeholder such as "id IN ?".
 func (r *BaseRepository[T]) DeleteWhere(ctx context.Context, conditions map[string]interface{}) error {
-	query := r.db.WithContext(ctx).Model(new(T))
-	
-	for key, value := range conditions {
-		query = query.Where(fmt.Sprintf("%s = ?", key), value)
-	}
-	
-	if err := query.Delete(new(T)).Error; err != nil {
-		return fmt.Errorf("delete entities where conditions: %w", err)
-	}
-	return nil
+	_, err := r.DeleteWhereCount(ctx, conditions)
+	return err
+}
+
+// DeleteWhereCount deletes entities matching the provided conditions and returns how many rows were affected
+func (r *BaseRepository[T]) DeleteWhereCount(ctx context.Context, conditions map[string]interface{}) (int64, error) {
+	return deleteWhere[T](r.db.WithContext(ctx), conditions)
 }
 
 // Count counts entities matching the provided conditions
 func (r *BaseRepository[T]) Count(ctx context.Context, conditions map[string]interface{}) (int64, error) {
 	var count int64
-	query := r.db.WithContext(ctx).Model(new(T))
+	query := r.ReadDB().WithContext(ctx).Model(new(T))
 	
 	for key, value := range conditions {
 		query = query.Where(fmt.Sprintf("%s = ?", key), value)
@@ -138,6 +243,11 @@ func (r *BaseRepository[T]) Count(ctx context.Context, conditions map[string]int
 	return count, nil
 }
 
+// GroupByCount counts entities per distinct value of field, after applying the optional scopes
+func (r *BaseRepository[T]) GroupByCount(ctx context.Context, field string, scopes ...func(*gorm.DB) *gorm.DB) (map[string]int64, error) {
+	return groupByCount(r.ReadDB().WithContext(ctx), new(T), field, scopes...)
+}
+
 // Exists checks if any entities match the provided conditions
 func (r *BaseRepository[T]) Exists(ctx context.Context, conditions map[string]interface{}) (bool, error) {
 	count, err := r.Count(ctx, conditions)
@@ -163,10 +273,11 @@ type MasterRepo[T any] struct {
 	*BaseRepository[T]
 }
 
-// NewMasterRepo creates a new repository connected to the master database
+// NewMasterRepo creates a new repository connected to the master database; its ReadOnly variant reads from
+// the master read replicas
 func NewMasterRepo[T any](dbManager *DatabaseManager) *MasterRepo[T] {
 	return &MasterRepo[T]{
-		BaseRepository: NewBaseRepository[T](dbManager.MasterDB),
+		BaseRepository: NewBaseRepositoryWithReplicas[T](dbManager.MasterDB, dbManager.MasterReplicas...),
 	}
 }
 
@@ -188,21 +299,20 @@ func NewTenantRepo[T any](connManager *TenantConnectionManager) *TenantRepo[T] {
 func (r *TenantRepo[T]) getTenantDB(ctx context.Context) (*gorm.DB, error) {
 	tenantID, err := GetTenantID(ctx)
 	if err != nil {
-		return nil, err
+		misuse := &TenantContextError{Operation: fmt.Sprintf("TenantRepo[%T]", *new(T))}
+		r.connManager.reportMissingTenant(misuse)
+		return nil, misuse
 	}
 	return r.connManager.GetTenantDB(ctx, tenantID)
 }
 
-// Insert inserts a new entity into the tenant database
+// Insert inserts a new entity into the tenant database. CreatedBy and UpdatedBy are set from the user ID in ctx.
 func (r *TenantRepo[T]) Insert(ctx context.Context, entity *T) error {
 	db, err := r.getTenantDB(ctx)
 	if err != nil {
 		return fmt.Errorf("get tenant database: %w", err)
 	}
-	if err := db.WithContext(ctx).Create(entity).Error; err != nil {
-		return fmt.Errorf("insert entity: %w", err)
-	}
-	return nil
+	return insert(db.WithContext(ctx), entity)
 }
 
 // InsertBatch inserts multiple entities into the tenant database
@@ -214,10 +324,7 @@ func (r *TenantRepo[T]) InsertBatch(ctx context.Context, entities []*T) error {
 	if err != nil {
 		return fmt.Errorf("get tenant database: %w", err)
 	}
-	if err := db.WithContext(ctx).Create(entities).Error; err != nil {
-		return fmt.Errorf("insert batch entities: %w", err)
-	}
-	return nil
+	return insertBatch(db.WithContext(ctx), entities)
 }
 
 // UpdateByID updates an entity by its ID in the tenant database
@@ -226,26 +333,72 @@ func (r *TenantRepo[T]) UpdateByID(ctx context.Context, id uint, entity *T) erro
 	if err != nil {
 		return fmt.Errorf("get tenant database: %w", err)
 	}
-	if err := db.WithContext(ctx).Model(entity).Where("id = ?", id).Updates(entity).Error; err != nil {
-		return fmt.Errorf("update entity by id %d: %w", id, err)
+	_, err = updateByID(db.WithContext(ctx), id, entity)
+	return err
+}
+
+// UpdateByIDStrict updates an entity by its ID in the tenant database and returns a not found error
+// wrapping gorm.ErrRecordNotFound when no row was updated
+func (r *TenantRepo[T]) UpdateByIDStrict(ctx context.Context, id uint, entity *T) error {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return fmt.Errorf("get tenant database: %w", err)
 	}
-	return nil
+	return updateByIDStrict(db.WithContext(ctx), id, entity)
+}
+
+// UpdateByIDReturning updates an entity by its ID in the tenant database and returns the row as stored
+// after the update. It returns a not found error wrapping gorm.ErrRecordNotFound when no row was updated.
+func (r *TenantRepo[T]) UpdateByIDReturning(ctx context.Context, id uint, entity *T) (*T, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant database: %w", err)
+	}
+	return updateByIDReturning(db.WithContext(ctx), id, entity)
+}
+
+// UpdateByIDWithVersion updates an entity by its ID in the tenant database using optimistic locking on the
+// model's Version field. It returns an error wrapping ErrStaleObject when the row's version is no longer
+// expectedVersion.
+func (r *TenantRepo[T]) UpdateByIDWithVersion(ctx context.Context, id uint, expectedVersion uint, entity *T) error {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return fmt.Errorf("get tenant database: %w", err)
+	}
+	return updateByIDWithVersion(db.WithContext(ctx), id, expectedVersion, entity)
 }
 
 // UpdateWhere updates entities matching conditions with the provided updates
 func (r *TenantRepo[T]) UpdateWhere(ctx context.Context, conditions map[string]interface{}, updates map[string]interface{}) error {
+	_, err := r.UpdateWhereCount(ctx, conditions, updates)
+	return err
+}
+
+// UpdateWhereCount updates entities matching conditions in the tenant database and returns how many rows were affected
+func (r *TenantRepo[T]) UpdateWhereCount(ctx context.Context, conditions map[string]interface{}, updates map[string]interface{}) (int64, error) {
 	db, err := r.getTenantDB(ctx)
 	if err != nil {
-		return fmt.Errorf("get tenant database: %w", err)
+		return 0, fmt.Errorf("get tenant database: %w", err)
 	}
-	query := db.WithContext(ctx).Model(new(T))
-	for key, value := range conditions {
-		query = query.Where(fmt.Sprintf("%s = ?", key), value)
+	return updateWhere[T](db.WithContext(ctx), conditions, updates)
+}
+
+// Upsert inserts the entity into the tenant database or updates updateColumns on conflict
+func (r *TenantRepo[T]) Upsert(ctx context.Context, entity *T, conflictColumns []string, updateColumns []string) error {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return fmt.Errorf("get tenant database: %w", err)
 	}
-	if err := query.Updates(updates).Error; err != nil {
-		return fmt.Errorf("update entities where conditions: %w", err)
+	return upsert(db.WithContext(ctx), entity, conflictColumns, updateColumns)
+}
+
+// FindOrCreate returns the entity matching conditions from the tenant database, creating it when none exists
+func (r *TenantRepo[T]) FindOrCreate(ctx context.Context, conditions map[string]interface{}, entity *T) (*T, bool, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("get tenant database: %w", err)
 	}
-	return nil
+	return findOrCreate(db.WithContext(ctx), conditions, entity)
 }
 
 // GetByID retrieves an entity by its ID from the tenant database
@@ -257,13 +410,70 @@ func (r *TenantRepo[T]) GetByID(ctx context.Context, id uint) (*T, error) {
 	var entity T
 	if err := db.WithContext(ctx).First(&entity, id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("entity with id %d not found", id)
+			return nil, fmt.Errorf("entity with id %d not found: %w", id, err)
 		}
 		return nil, fmt.Errorf("get entity by id %d: %w", id, err)
 	}
 	return &entity, nil
 }
 
+// GetByUUID retrieves an entity keyed by a UUID string from the tenant database
+func (r *TenantRepo[T]) GetByUUID(ctx context.Context, id string) (*T, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant database: %w", err)
+	}
+	return getByUUID[T](db.WithContext(ctx), id)
+}
+
+// GetByIDs retrieves the entities with the given IDs from the tenant database; IDs without a matching entity are skipped
+func (r *TenantRepo[T]) GetByIDs(ctx context.Context, ids []uint) ([]*T, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant database: %w", err)
+	}
+	return getByIDs[T](db.WithContext(ctx), ids)
+}
+
+// GetByIDsMap retrieves the entities with the given IDs from the tenant database keyed by ID.
+// IDs without a matching entity have no entry.
+func (r *TenantRepo[T]) GetByIDsMap(ctx context.Context, ids []uint) (map[uint]*T, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant database: %w", err)
+	}
+	return getByIDsMap[T](db.WithContext(ctx), ids)
+}
+
+// GetAfterCursor retrieves up to limit entities after afterID from the tenant database using keyset
+// pagination on id, and the afterID of the next page, or 0 on the last page
+func (r *TenantRepo[T]) GetAfterCursor(ctx context.Context, afterID uint, limit int, orderBy string, scopes ...func(*gorm.DB) *gorm.DB) ([]*T, uint, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("get tenant database: %w", err)
+	}
+	return getAfterCursor[T](db.WithContext(ctx), afterID, limit, orderBy, scopes...)
+}
+
+// GetByField retrieves the first entity whose field equals value from the tenant database.
+// A missing row returns a not found error wrapping gorm.ErrRecordNotFound.
+func (r *TenantRepo[T]) GetByField(ctx context.Context, field string, value interface{}) (*T, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant database: %w", err)
+	}
+	return getByField[T](db.WithContext(ctx), field, value)
+}
+
+// GetManyByField retrieves the entities whose field equals value from the tenant database
+func (r *TenantRepo[T]) GetManyByField(ctx context.Context, field string, value interface{}) ([]*T, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant database: %w", err)
+	}
+	return getManyByField[T](db.WithContext(ctx), field, value)
+}
+
 // GetAll retrieves all entities with optional limit and offset from the tenant database
 func (r *TenantRepo[T]) GetAll(ctx context.Context, limit, offset int) ([]*T, error) {
 	db, err := r.getTenantDB(ctx)
@@ -305,6 +515,24 @@ func (r *TenantRepo[T]) GetWhere(ctx context.Context, conditions map[string]inte
 	return entities, nil
 }
 
+// GetWhereAdvanced retrieves entities matching all of the provided criteria from the tenant database
+func (r *TenantRepo[T]) GetWhereAdvanced(ctx context.Context, criteria []Criterion) ([]*T, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant database: %w", err)
+	}
+	var entities []*T
+	query, err := applyCriteria(db.WithContext(ctx), new(T), criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := query.Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("get entities where criteria: %w", err)
+	}
+	return entities, nil
+}
+
 // DeleteByID deletes an entity by its ID from the tenant database
 func (r *TenantRepo[T]) DeleteByID(ctx context.Context, id uint) error {
 	db, err := r.getTenantDB(ctx)
@@ -317,22 +545,37 @@ func (r *TenantRepo[T]) DeleteByID(ctx context.Context, id uint) error {
 	return nil
 }
 
-// DeleteWhere deletes entities matching the provided conditions from the tenant database
-func (r *TenantRepo[T]) DeleteWhere(ctx context.Context, conditions map[string]interface{}) error {
+// DeleteByUUID deletes an entity keyed by a UUID string from the tenant database
+func (r *TenantRepo[T]) DeleteByUUID(ctx context.Context, id string) error {
 	db, err := r.getTenantDB(ctx)
 	if err != nil {
 		return fmt.Errorf("get tenant database: %w", err)
 	}
-	query := db.WithContext(ctx).Model(new(T))
-	
-	for key, value := range conditions {
-		query = query.Where(fmt.Sprintf("%s = ?", key), value)
+	return deleteByUUID[T](db.WithContext(ctx), id)
+}
+
+// RestoreByID restores a soft-deleted entity by its ID in the tenant database
+func (r *TenantRepo[T]) RestoreByID(ctx context.Context, id uint) error {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return fmt.Errorf("get tenant database: %w", err)
 	}
-	
-	if err := query.Delete(new(T)).Error; err != nil {
-		return fmt.Errorf("delete entities where conditions: %w", err)
+	return restoreByID[T](db.WithContext(ctx), id)
+}
+
+// DeleteWhere deletes entities matching the provided conditions from the tenant database
+func (r *TenantRepo[T]) DeleteWhere(ctx context.Context, conditions map[string]interface{}) error {
+	_, err := r.DeleteWhereCount(ctx, conditions)
+	return err
+}
+
+// DeleteWhereCount deletes entities matching the provided conditions from the tenant database and returns how many rows were affected
+func (r *TenantRepo[T]) DeleteWhereCount(ctx context.Context, conditions map[string]interface{}) (int64, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get tenant database: %w", err)
 	}
-	return nil
+	return deleteWhere[T](db.WithContext(ctx), conditions)
 }
 
 // Count counts entities matching the provided conditions in the tenant database
@@ -354,6 +597,15 @@ func (r *TenantRepo[T]) Count(ctx context.Context, conditions map[string]interfa
 	return count, nil
 }
 
+// GroupByCount counts entities per distinct value of field in the tenant database, after applying the optional scopes
+func (r *TenantRepo[T]) GroupByCount(ctx context.Context, field string, scopes ...func(*gorm.DB) *gorm.DB) (map[string]int64, error) {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant database: %w", err)
+	}
+	return groupByCount(db.WithContext(ctx), new(T), field, scopes...)
+}
+
 // Exists checks if any entities match the provided conditions in the tenant database
 func (r *TenantRepo[T]) Exists(ctx context.Context, conditions map[string]interface{}) (bool, error) {
 	count, err := r.Count(ctx, conditions)
@@ -373,3 +625,192 @@ func (r *TenantRepo[T]) WithTx(tx *gorm.DB) *BaseRepository[T] {
 func (r *TenantRepo[T]) GetDB(ctx context.Context) (*gorm.DB, error) {
 	return r.getTenantDB(ctx)
 }
+
+// getByIDs loads the entities whose primary key is in ids
+func getByIDs[T any](db *gorm.DB, ids []uint) ([]*T, error) {
+	entities := []*T{}
+	if len(ids) == 0 {
+		return entities, nil
+	}
+	if err := db.Find(&entities, ids).Error; err != nil {
+		return nil, fmt.Errorf("get entities by ids: %w", err)
+	}
+	return entities, nil
+}
+
+// getByIDsMap loads the entities whose primary key is in ids and indexes them by primary key
+func getByIDsMap[T any](db *gorm.DB, ids []uint) (map[uint]*T, error) {
+	entities, err := getByIDs[T](db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*T, len(entities))
+	for _, entity := range entities {
+		id, err := primaryKeyOf(db, entity)
+		if err != nil {
+			return nil, err
+		}
+		byID[id] = entity
+	}
+	return byID, nil
+}
+
+// primaryKeyOf returns the uint primary key of entity
+func primaryKeyOf[T any](db *gorm.DB, entity *T) (uint, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(entity); err != nil {
+		return 0, fmt.Errorf("parse model schema: %w", err)
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return 0, fmt.Errorf("model %s has no primary key", stmt.Schema.Name)
+	}
+
+	value, _ := pk.ValueOf(db.Statement.Context, reflect.ValueOf(entity).Elem())
+	id, ok := value.(uint)
+	if !ok {
+		return 0, fmt.Errorf("model %s primary key is %T, not uint", stmt.Schema.Name, value)
+	}
+	return id, nil
+}
+
+// getByField returns the first entity whose field equals value, validating field against the model schema
+func getByField[T any](db *gorm.DB, field string, value interface{}) (*T, error) {
+	column, err := resolveColumn(db, new(T), field)
+	if err != nil {
+		return nil, err
+	}
+	var entity T
+	if err := db.Where(db.Statement.Quote(column)+" = ?", value).First(&entity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("entity with %s %v not found: %w", column, value, err)
+		}
+		return nil, fmt.Errorf("get entity by %s: %w", column, err)
+	}
+	return &entity, nil
+}
+
+// getManyByField returns the entities whose field equals value, validating field against the model schema
+func getManyByField[T any](db *gorm.DB, field string, value interface{}) ([]*T, error) {
+	column, err := resolveColumn(db, new(T), field)
+	if err != nil {
+		return nil, err
+	}
+	entities := []*T{}
+	if err := db.Where(db.Statement.Quote(column)+" = ?", value).Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("get entities by %s: %w", column, err)
+	}
+	return entities, nil
+}
+
+// insert creates the entity after stamping its audit user fields
+func insert[T any](db *gorm.DB, entity *T) error {
+	if err := stampUser(db, []*T{entity}, CreatedByField, UpdatedByField); err != nil {
+		return err
+	}
+	if err := db.Create(entity).Error; err != nil {
+		return fmt.Errorf("insert entity: %w", err)
+	}
+	return nil
+}
+
+// insertBatch creates the entities after stamping their audit user fields
+func insertBatch[T any](db *gorm.DB, entities []*T) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	if err := stampUser(db, entities, CreatedByField, UpdatedByField); err != nil {
+		return err
+	}
+	if err := db.Create(entities).Error; err != nil {
+		return fmt.Errorf("insert batch entities: %w", err)
+	}
+	return nil
+}
+
+// updateByID updates the entity with the given ID and returns how many rows were affected
+func updateByID[T any](db *gorm.DB, id uint, entity *T) (int64, error) {
+	if err := stampUser(db, []*T{entity}, UpdatedByField); err != nil {
+		return 0, err
+	}
+	result := db.Model(entity).Where("id = ?", id).Updates(entity)
+	if result.Error != nil {
+		return 0, fmt.Errorf("update entity by id %d: %w", id, result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// updateByIDStrict is updateByID that treats zero affected rows as not found
+func updateByIDStrict[T any](db *gorm.DB, id uint, entity *T) error {
+	affected, err := updateByID(db, id, entity)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("entity with id %d not found: %w", id, gorm.ErrRecordNotFound)
+	}
+	return nil
+}
+
+// updateByIDReturning is updateByIDStrict that also returns the stored row. Postgres returns it from the
+// UPDATE itself via RETURNING; other drivers re-read the row after the update.
+func updateByIDReturning[T any](db *gorm.DB, id uint, entity *T) (*T, error) {
+	if err := stampUser(db, []*T{entity}, UpdatedByField); err != nil {
+		return nil, err
+	}
+	if db.Dialector.Name() == "postgres" {
+		result := db.Model(entity).Clauses(clause.Returning{}).Where("id = ?", id).Updates(entity)
+		if result.Error != nil {
+			return nil, fmt.Errorf("update entity by id %d: %w", id, result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return nil, fmt.Errorf("entity with id %d not found: %w", id, gorm.ErrRecordNotFound)
+		}
+		return entity, nil
+	}
+
+	if err := updateByIDStrict(db, id, entity); err != nil {
+		return nil, err
+	}
+	var updated T
+	if err := db.First(&updated, id).Error; err != nil {
+		return nil, fmt.Errorf("get updated entity by id %d: %w", id, err)
+	}
+	return &updated, nil
+}
+
+// updateWhere applies updates to the rows matching conditions and returns how many rows were affected
+func updateWhere[T any](db *gorm.DB, conditions map[string]interface{}, updates map[string]interface{}) (int64, error) {
+	query := db.Model(new(T))
+	for key, value := range conditions {
+		query = query.Where(fmt.Sprintf("%s = ?", key), value)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return 0, fmt.Errorf("update entities where conditions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// deleteWhere deletes the rows matching conditions and returns how many rows were affected
+func deleteWhere[T any](db *gorm.DB, conditions map[string]interface{}) (int64, error) {
+	query := db.Model(new(T))
+	for key, value := range conditions {
+		query = query.Where(conditionClause(key), value)
+	}
+	result := query.Delete(new(T))
+	if result.Error != nil {
+		return 0, fmt.Errorf("delete entities where conditions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// conditionClause turns a condition key into a WHERE clause: a column becomes "column = ?" and a key that
+// already holds a placeholder is used as is
+func conditionClause(key string) string {
+	if strings.Contains(key, "?") {
+		return key
+	}
+	return key + " = ?"
+}
@@ -127,6 +127,121 @@ func TestBaseRepository_GetByID(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, retrieved)
 		assert.Contains(t, err.Error(), "not found")
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+// TestBaseRepository_GetByIDs tests retrieving several entities by ID
+func TestBaseRepository_GetByIDs(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	first := &TestEntity{Name: "First", Status: "active", Value: 1}
+	second := &TestEntity{Name: "Second", Status: "active", Value: 2}
+	require.NoError(t, repo.InsertBatch(ctx, []*TestEntity{first, second}))
+
+	t.Run("existing and missing ids", func(t *testing.T) {
+		entities, err := repo.GetByIDs(ctx, []uint{second.ID, 99999, first.ID})
+		require.NoError(t, err)
+		require.Len(t, entities, 2)
+
+		names := []string{entities[0].Name, entities[1].Name}
+		assert.ElementsMatch(t, []string{"First", "Second"}, names)
+	})
+
+	t.Run("no ids", func(t *testing.T) {
+		entities, err := repo.GetByIDs(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, entities)
+	})
+}
+
+// TestBaseRepository_GetByIDsMap tests retrieving several entities by ID keyed by ID
+func TestBaseRepository_GetByIDsMap(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	entities := []*TestEntity{
+		{Name: "First", Status: "active", Value: 1},
+		{Name: "Second", Status: "active", Value: 2},
+		{Name: "Third", Status: "active", Value: 3},
+	}
+	require.NoError(t, repo.InsertBatch(ctx, entities))
+
+	t.Run("subset with a missing id", func(t *testing.T) {
+		ids := []uint{entities[2].ID, 99999, entities[0].ID}
+		byID, err := repo.GetByIDsMap(ctx, ids)
+		require.NoError(t, err)
+		require.Len(t, byID, 2)
+
+		assert.Equal(t, "Third", byID[ids[0]].Name)
+		assert.NotContains(t, byID, uint(99999))
+		assert.Equal(t, "First", byID[ids[2]].Name)
+	})
+
+	t.Run("no ids", func(t *testing.T) {
+		byID, err := repo.GetByIDsMap(ctx, []uint{})
+		require.NoError(t, err)
+		assert.NotNil(t, byID)
+		assert.Empty(t, byID)
+	})
+}
+
+// TestBaseRepository_GetByField tests retrieving entities by a single column
+func TestBaseRepository_GetByField(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	entities := []*TestEntity{
+		{Name: "Alpha", Status: "active", Value: 10},
+		{Name: "Beta", Status: "active", Value: 20},
+		{Name: "Gamma", Status: "inactive", Value: 30},
+	}
+	require.NoError(t, repo.InsertBatch(ctx, entities))
+
+	t.Run("found", func(t *testing.T) {
+		entity, err := repo.GetByField(ctx, "name", "Beta")
+		require.NoError(t, err)
+		assert.Equal(t, entities[1].ID, entity.ID)
+
+		entity, err = repo.GetByField(ctx, "Value", 30)
+		require.NoError(t, err)
+		assert.Equal(t, "Gamma", entity.Name)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		entity, err := repo.GetByField(ctx, "name", "Delta")
+		assert.Nil(t, entity)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Contains(t, err.Error(), "not found")
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		entity, err := repo.GetByField(ctx, "name = name OR 1", "Delta")
+		assert.Nil(t, entity)
+		assert.ErrorIs(t, err, ErrInvalidCriterion)
+	})
+
+	t.Run("many", func(t *testing.T) {
+		results, err := repo.GetManyByField(ctx, "status", "active")
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(results))
+		for _, entity := range results {
+			names = append(names, entity.Name)
+		}
+		assert.ElementsMatch(t, []string{"Alpha", "Beta"}, names)
+
+		results, err = repo.GetManyByField(ctx, "status", "archived")
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		results, err = repo.GetManyByField(ctx, "missing", "active")
+		assert.ErrorIs(t, err, ErrInvalidCriterion)
+		assert.Nil(t, results)
 	})
 }
 
@@ -217,6 +332,76 @@ func TestBaseRepository_GetWhere(t *testing.T) {
 	})
 }
 
+// TestBaseRepository_GetWhereAdvanced tests retrieval with operator criteria
+func TestBaseRepository_GetWhereAdvanced(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	// Setup test data
+	entities := []*TestEntity{
+		{Name: "Alpha", Status: "active", Value: 10},
+		{Name: "Beta", Status: "active", Value: 20},
+		{Name: "Gamma", Status: "inactive", Value: 30},
+		{Name: "Alphabet", Status: "pending", Value: 40},
+	}
+	err := repo.InsertBatch(ctx, entities)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		criteria []Criterion
+		expected []string
+	}{
+		{"equal", []Criterion{{Field: "status", Op: OpEq, Value: "inactive"}}, []string{"Gamma"}},
+		{"not equal", []Criterion{{Field: "status", Op: OpNeq, Value: "active"}}, []string{"Gamma", "Alphabet"}},
+		{"less than", []Criterion{{Field: "value", Op: OpLT, Value: 20}}, []string{"Alpha"}},
+		{"less than or equal", []Criterion{{Field: "value", Op: OpLTE, Value: 20}}, []string{"Alpha", "Beta"}},
+		{"greater than", []Criterion{{Field: "value", Op: OpGT, Value: 30}}, []string{"Alphabet"}},
+		{"greater than or equal", []Criterion{{Field: "value", Op: OpGTE, Value: 30}}, []string{"Gamma", "Alphabet"}},
+		{"like", []Criterion{{Field: "name", Op: OpLike, Value: "Alpha%"}}, []string{"Alpha", "Alphabet"}},
+		{"in", []Criterion{{Field: "status", Op: OpIn, Value: []string{"inactive", "pending"}}}, []string{"Gamma", "Alphabet"}},
+		{"empty in", []Criterion{{Field: "status", Op: OpIn, Value: []string{}}}, []string{}},
+		{"range", []Criterion{
+			{Field: "value", Op: OpGTE, Value: 20},
+			{Field: "value", Op: OpLT, Value: 40},
+		}, []string{"Beta", "Gamma"}},
+		{"struct field name", []Criterion{{Field: "Status", Op: OpEq, Value: "pending"}}, []string{"Alphabet"}},
+		{"no criteria", nil, []string{"Alpha", "Beta", "Gamma", "Alphabet"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := repo.GetWhereAdvanced(ctx, tt.criteria)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(results))
+			for _, entity := range results {
+				names = append(names, entity.Name)
+			}
+			assert.ElementsMatch(t, tt.expected, names)
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		results, err := repo.GetWhereAdvanced(ctx, []Criterion{{Field: "value; DROP TABLE test_entities", Op: OpEq, Value: 1}})
+		assert.ErrorIs(t, err, ErrInvalidCriterion)
+		assert.Nil(t, results)
+	})
+
+	t.Run("unsupported operator", func(t *testing.T) {
+		results, err := repo.GetWhereAdvanced(ctx, []Criterion{{Field: "value", Op: Operator("BETWEEN"), Value: 1}})
+		assert.ErrorIs(t, err, ErrInvalidCriterion)
+		assert.Nil(t, results)
+	})
+
+	t.Run("in with non-slice value", func(t *testing.T) {
+		results, err := repo.GetWhereAdvanced(ctx, []Criterion{{Field: "status", Op: OpIn, Value: "active"}})
+		assert.ErrorIs(t, err, ErrInvalidCriterion)
+		assert.Nil(t, results)
+	})
+}
+
 // TestBaseRepository_UpdateByID tests updating entity by ID
 func TestBaseRepository_UpdateByID(t *testing.T) {
 	db := setupTestDB(t)
@@ -333,6 +518,136 @@ func TestBaseRepository_DeleteWhere(t *testing.T) {
 	})
 }
 
+// TestBaseRepository_UpdateWhereCount tests the affected-row counts of conditional updates
+func TestBaseRepository_UpdateWhereCount(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	entities := []*TestEntity{
+		{Name: "Entity 1", Status: "active", Value: 10},
+		{Name: "Entity 2", Status: "active", Value: 20},
+		{Name: "Entity 3", Status: "inactive", Value: 30},
+	}
+	require.NoError(t, repo.InsertBatch(ctx, entities))
+
+	tests := []struct {
+		name       string
+		conditions map[string]interface{}
+		want       int64
+	}{
+		{name: "all matching", conditions: map[string]interface{}{"status": "active"}, want: 2},
+		{name: "partial match", conditions: map[string]interface{}{"status": "active", "value": 20}, want: 1},
+		{name: "no match", conditions: map[string]interface{}{"status": "archived"}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			affected, err := repo.UpdateWhereCount(ctx, tt.conditions, map[string]interface{}{"name": "Updated"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, affected)
+		})
+	}
+}
+
+// TestBaseRepository_DeleteWhereCount tests the affected-row counts of conditional deletes
+func TestBaseRepository_DeleteWhereCount(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	entities := []*TestEntity{
+		{Name: "Keep", Status: "active", Value: 10},
+		{Name: "Delete 1", Status: "inactive", Value: 20},
+		{Name: "Delete 2", Status: "inactive", Value: 30},
+	}
+	require.NoError(t, repo.InsertBatch(ctx, entities))
+
+	t.Run("no match", func(t *testing.T) {
+		affected, err := repo.DeleteWhereCount(ctx, map[string]interface{}{"status": "archived"})
+		require.NoError(t, err)
+		assert.Zero(t, affected)
+	})
+
+	t.Run("partial match", func(t *testing.T) {
+		affected, err := repo.DeleteWhereCount(ctx, map[string]interface{}{"status": "inactive", "value": 20})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), affected)
+	})
+
+	t.Run("all matching", func(t *testing.T) {
+		affected, err := repo.DeleteWhereCount(ctx, map[string]interface{}{"status": "inactive"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), affected, "the other inactive row was already deleted")
+
+		remaining, err := repo.GetAll(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, remaining, 1)
+	})
+
+	t.Run("clause with placeholder", func(t *testing.T) {
+		affected, err := repo.DeleteWhereCount(ctx, map[string]interface{}{"id IN ?": []uint{entities[0].ID, entities[1].ID, 9999}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), affected, "only the kept row was still there")
+	})
+}
+
+// TestBaseRepository_UpdateByIDStrict tests that updating a missing ID is reported as not found
+func TestBaseRepository_UpdateByIDStrict(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	entity := &TestEntity{Name: "Original", Status: "active", Value: 1}
+	require.NoError(t, repo.Insert(ctx, entity))
+
+	t.Run("existing entity", func(t *testing.T) {
+		err := repo.UpdateByIDStrict(ctx, entity.ID, &TestEntity{Name: "Updated"})
+		require.NoError(t, err)
+
+		updated, err := repo.GetByID(ctx, entity.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Updated", updated.Name)
+	})
+
+	t.Run("missing entity", func(t *testing.T) {
+		err := repo.UpdateByIDStrict(ctx, 99999, &TestEntity{Name: "Ghost"})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		// The lenient variant still treats this as a no-op
+		assert.NoError(t, repo.UpdateByID(ctx, 99999, &TestEntity{Name: "Ghost"}))
+	})
+}
+
+// TestBaseRepository_UpdateByIDReturning tests updating and reading back an entity in one call
+func TestBaseRepository_UpdateByIDReturning(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewBaseRepository[TestEntity](db)
+	ctx := context.Background()
+
+	entity := &TestEntity{Name: "Original", Status: "active", Value: 1}
+	require.NoError(t, repo.Insert(ctx, entity))
+	createdAt := entity.CreatedAt
+
+	t.Run("returns the stored row", func(t *testing.T) {
+		// Only the changed field is set; the rest must come back from the database
+		updated, err := repo.UpdateByIDReturning(ctx, entity.ID, &TestEntity{Value: 42})
+		require.NoError(t, err)
+
+		assert.Equal(t, entity.ID, updated.ID)
+		assert.Equal(t, 42, updated.Value)
+		assert.Equal(t, "Original", updated.Name)
+		assert.Equal(t, "active", updated.Status)
+		assert.WithinDuration(t, createdAt, updated.CreatedAt, time.Second)
+	})
+
+	t.Run("missing entity", func(t *testing.T) {
+		updated, err := repo.UpdateByIDReturning(ctx, 99999, &TestEntity{Name: "Ghost"})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Nil(t, updated)
+	})
+}
+
 // TestBaseRepository_Count tests counting entities
 func TestBaseRepository_Count(t *testing.T) {
 	db := setupTestDB(t)
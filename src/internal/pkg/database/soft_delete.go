@@ -0,0 +1,37 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrSoftDeleteUnsupported is returned when restoring an entity without a DeletedAt field
+var ErrSoftDeleteUnsupported = errors.New("entity does not support soft delete")
+
+// restoreByID clears deleted_at on a soft-deleted row. Restoring a row that is not deleted is a no-op;
+// a row that never existed or was hard-deleted yields gorm.ErrRecordNotFound.
+func restoreByID[T any](db *gorm.DB, id uint) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return fmt.Errorf("parse model schema: %w", err)
+	}
+	deletedAt := stmt.Schema.LookUpField("DeletedAt")
+	if deletedAt == nil {
+		return ErrSoftDeleteUnsupported
+	}
+
+	var entity T
+	if err := db.Unscoped().First(&entity, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("entity with id %d not found: %w", id, err)
+		}
+		return fmt.Errorf("get entity by id %d: %w", id, err)
+	}
+
+	if err := db.Unscoped().Model(new(T)).Where("id = ?", id).Update(deletedAt.DBName, nil).Error; err != nil {
+		return fmt.Errorf("restore entity by id %d: %w", id, err)
+	}
+	return nil
+}
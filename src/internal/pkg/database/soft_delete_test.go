@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// SoftDeleteEntity is a test model supporting soft delete
+type SoftDeleteEntity struct {
+	ID        uint `gorm:"primarykey"`
+	Name      string
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+// TestBaseRepository_RestoreByID tests restoring soft-deleted entities
+func TestBaseRepository_RestoreByID(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&SoftDeleteEntity{}, &TestEntity{}))
+
+	repo := NewBaseRepository[SoftDeleteEntity](db)
+	ctx := context.Background()
+
+	t.Run("restore soft-deleted entity", func(t *testing.T) {
+		entity := &SoftDeleteEntity{Name: "restore me"}
+		require.NoError(t, repo.Insert(ctx, entity))
+		require.NoError(t, repo.DeleteByID(ctx, entity.ID))
+
+		_, err := repo.GetByID(ctx, entity.ID)
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		require.NoError(t, repo.RestoreByID(ctx, entity.ID))
+
+		restored, err := repo.GetByID(ctx, entity.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "restore me", restored.Name)
+	})
+
+	t.Run("restore active entity is a no-op", func(t *testing.T) {
+		entity := &SoftDeleteEntity{Name: "active"}
+		require.NoError(t, repo.Insert(ctx, entity))
+
+		assert.NoError(t, repo.RestoreByID(ctx, entity.ID))
+	})
+
+	t.Run("never created", func(t *testing.T) {
+		err := repo.RestoreByID(ctx, 99999)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("hard deleted", func(t *testing.T) {
+		entity := &SoftDeleteEntity{Name: "gone"}
+		require.NoError(t, repo.Insert(ctx, entity))
+		require.NoError(t, db.Unscoped().Delete(&SoftDeleteEntity{}, entity.ID).Error)
+
+		err := repo.RestoreByID(ctx, entity.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("entity without soft delete", func(t *testing.T) {
+		plainRepo := NewBaseRepository[TestEntity](db)
+		err := plainRepo.RestoreByID(ctx, 1)
+		assert.ErrorIs(t, err, ErrSoftDeleteUnsupported)
+	})
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidSort is returned when a sort expression references a field outside the allowlist
+var ErrInvalidSort = errors.New("invalid sort")
+
+// SortField is a single column in an ORDER BY, in priority order
+type SortField struct {
+	Column string
+	Desc   bool
+}
+
+// ParseSort parses a comma-separated sort expression such as "category,-price".
+// A leading "-" sorts descending and a leading "+" (or none) ascending. Each name is looked up in
+// allowed, which maps the public field name to its column; any unknown or repeated field rejects
+// the whole expression. An empty expression yields no fields.
+func ParseSort(raw string, allowed map[string]string) ([]SortField, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	fields := make([]SortField, 0, len(parts))
+	seen := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		name := strings.TrimSpace(part)
+		desc := false
+		switch {
+		case strings.HasPrefix(name, "-"):
+			desc = true
+			name = name[1:]
+		case strings.HasPrefix(name, "+"):
+			name = name[1:]
+		}
+
+		column, ok := allowed[name]
+		if name == "" || !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSort, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: field %q listed more than once", ErrInvalidSort, name)
+		}
+		seen[name] = true
+		fields = append(fields, SortField{Column: column, Desc: desc})
+	}
+	return fields, nil
+}
+
+// OrderBy is a scope that applies the sort fields in order, or fallback when there are none.
+// Columns are quoted by GORM, so only allowlisted names from ParseSort should be passed in.
+func OrderBy(fields []SortField, fallback ...SortField) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		order := fields
+		if len(order) == 0 {
+			order = fallback
+		}
+		for _, f := range order {
+			db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: f.Column}, Desc: f.Desc})
+		}
+		return db
+	}
+}
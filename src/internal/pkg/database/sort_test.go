@@ -0,0 +1,78 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSortFields = map[string]string{
+	"name":  "name",
+	"value": "value",
+	"state": "status",
+}
+
+// TestParseSort tests parsing of comma-separated sort expressions
+func TestParseSort(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		fields, err := ParseSort("  ", testSortFields)
+		require.NoError(t, err)
+		assert.Empty(t, fields)
+	})
+
+	t.Run("multiple fields with directions", func(t *testing.T) {
+		fields, err := ParseSort("state, -value,+name", testSortFields)
+		require.NoError(t, err)
+		assert.Equal(t, []SortField{
+			{Column: "status"},
+			{Column: "value", Desc: true},
+			{Column: "name"},
+		}, fields)
+	})
+
+	t.Run("invalid field anywhere is rejected", func(t *testing.T) {
+		for _, raw := range []string{"password", "name,password", "name,-value,password", "name,", "-", "name;drop table"} {
+			_, err := ParseSort(raw, testSortFields)
+			assert.ErrorIs(t, err, ErrInvalidSort, raw)
+		}
+	})
+
+	t.Run("repeated field is rejected", func(t *testing.T) {
+		_, err := ParseSort("name,-name", testSortFields)
+		assert.ErrorIs(t, err, ErrInvalidSort)
+	})
+}
+
+// TestOrderBy tests that sort fields are applied in priority order
+func TestOrderBy(t *testing.T) {
+	db := setupTestDB(t)
+	for _, e := range []TestEntity{
+		{Name: "b", Status: "active", Value: 1},
+		{Name: "a", Status: "inactive", Value: 5},
+		{Name: "c", Status: "active", Value: 3},
+		{Name: "d", Status: "inactive", Value: 2},
+	} {
+		require.NoError(t, db.Create(&e).Error)
+	}
+
+	names := func(t *testing.T, fields []SortField, fallback ...SortField) []string {
+		var entities []TestEntity
+		require.NoError(t, db.Scopes(OrderBy(fields, fallback...)).Find(&entities).Error)
+		result := make([]string, len(entities))
+		for i, e := range entities {
+			result[i] = e.Name
+		}
+		return result
+	}
+
+	t.Run("multi-field", func(t *testing.T) {
+		fields, err := ParseSort("state,-value", testSortFields)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c", "b", "a", "d"}, names(t, fields))
+	})
+
+	t.Run("fallback when no fields", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "c", "d", "b"}, names(t, nil, SortField{Column: "value", Desc: true}))
+	})
+}
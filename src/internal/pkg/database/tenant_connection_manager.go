@@ -2,33 +2,189 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/parallel"
 )
 
 // TenantConnectionManager manages dynamic database connections for tenants
 type TenantConnectionManager struct {
 	masterDB *gorm.DB
 	logger   *zap.Logger
+
+	maxCachedConnections int           // 0 means unlimited
+	idleTimeout          time.Duration // 0 disables idle eviction
+	closeGrace           time.Duration // How long evicted connections stay open for requests still holding them
+	concurrency          int           // Max tenants processed in parallel by batch operations
+	strictTenantContext  bool          // Log and count tenant repository calls without a tenant in context
+	slowQueryThreshold   time.Duration // Queries slower than this are logged at warn level; 0 disables
+	connectRetries       int           // Retries when a tenant database cannot be reached
+	connectBackoff       time.Duration // Wait before the first retry, doubled on each later retry
+	secretDecryptor      SecretDecryptor
+
+	mu         sync.RWMutex
+	conns      map[string]*tenantConn  // Cached connections keyed by tenant ID
+	opening    map[string]*pendingConn // Connections being opened, keyed by tenant ID
+	retired    map[*gorm.DB]string     // Evicted connections waiting for in-flight requests, with their tenant ID
+	migrations []TenantMigration       // Run by ProvisionTenant against new tenant databases
+	evictions  atomic.Int64
+
+	missingTenantContext atomic.Int64 // Counted only in strict mode
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// tenantConn is a cached tenant connection along with the config it was opened from
+type tenantConn struct {
+	db         *gorm.DB
+	configHash string
+	lastUsed   atomic.Int64 // Unix nanoseconds of the last GetTenantDB hit
+}
+
+// pendingConn is a tenant connection being opened. Requests for the same tenant and config wait for it
+// instead of dialing the database themselves; db and err are set before done is closed.
+type pendingConn struct {
+	configHash string
+	done       chan struct{}
+	db         *gorm.DB
+	err        error
+}
+
+// wait returns the result of the open, or ctx's error if ctx ends first
+func (p *pendingConn) wait(ctx context.Context) (*gorm.DB, error) {
+	select {
+	case <-p.done:
+		return p.db, p.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// TenantConnectionOption configures a TenantConnectionManager
+type TenantConnectionOption func(*TenantConnectionManager)
+
+// WithMaxCachedConnections caps the number of cached tenant connections, evicting the least recently used
+func WithMaxCachedConnections(n int) TenantConnectionOption {
+	return func(m *TenantConnectionManager) {
+		m.maxCachedConnections = n
+	}
+}
+
+// WithIdleTimeout closes cached tenant connections that have not been used for the given duration
+func WithIdleTimeout(d time.Duration) TenantConnectionOption {
+	return func(m *TenantConnectionManager) {
+		m.idleTimeout = d
+	}
+}
+
+// WithCloseGrace keeps evicted tenant connections open for d, and after that until none of their connections
+// is in use, so requests that already hold them can finish
+func WithCloseGrace(d time.Duration) TenantConnectionOption {
+	return func(m *TenantConnectionManager) {
+		m.closeGrace = d
+	}
+}
+
+// WithConcurrency sets how many tenants batch operations process in parallel
+func WithConcurrency(n int) TenantConnectionOption {
+	return func(m *TenantConnectionManager) {
+		m.concurrency = n
+	}
+}
+
+// WithStrictTenantContext makes tenant repositories log and count calls made without a tenant in context,
+// which usually means ContextMiddleware is missing from a route
+func WithStrictTenantContext(strict bool) TenantConnectionOption {
+	return func(m *TenantConnectionManager) {
+		m.strictTenantContext = strict
+	}
+}
+
+// SecretDecryptor decrypts tenant secrets stored in the master database, such as connection strings.
+// Values that are not encrypted must be returned unchanged.
+type SecretDecryptor interface {
+	DecryptString(tenantID, value string) (string, error)
+}
+
+// WithSlowQueryThreshold logs tenant queries slower than d at warn level; 0 disables the warnings
+func WithSlowQueryThreshold(d time.Duration) TenantConnectionOption {
+	return func(m *TenantConnectionManager) {
+		m.slowQueryThreshold = d
+	}
+}
+
+// WithConnectRetry retries opening a tenant database that cannot be reached up to retries times, waiting
+// backoff before the first retry and doubling it on each later one
+func WithConnectRetry(retries int, backoff time.Duration) TenantConnectionOption {
+	return func(m *TenantConnectionManager) {
+		m.connectRetries = retries
+		m.connectBackoff = backoff
+	}
+}
+
+// WithSecretDecryptor decrypts tenant connection strings before opening them
+func WithSecretDecryptor(d SecretDecryptor) TenantConnectionOption {
+	return func(m *TenantConnectionManager) {
+		m.secretDecryptor = d
+	}
+}
+
+// TenantConnectionStats reports the state of the tenant connection cache
+type TenantConnectionStats struct {
+	Cached               int   `json:"cached"`
+	Evictions            int64 `json:"evictions"`
+	MissingTenantContext int64 `json:"missing_tenant_context"` // Repository calls without a tenant, strict mode only
 }
 
 // NewTenantConnectionManager creates a new tenant connection manager
-func NewTenantConnectionManager(masterDB *gorm.DB, logger *zap.Logger) *TenantConnectionManager {
-	return &TenantConnectionManager{
+func NewTenantConnectionManager(masterDB *gorm.DB, logger *zap.Logger, opts ...TenantConnectionOption) *TenantConnectionManager {
+	m := &TenantConnectionManager{
 		masterDB: masterDB,
 		logger:   logger,
+		stop:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+
+	if m.idleTimeout > 0 {
+		go m.evictIdleLoop()
+	}
+	return m
 }
 
-// GetTenantDB retrieves or creates a database connection for the specified tenant
+// GetTenantDB retrieves or creates a database connection for the specified tenant.
+// Connections are cached per tenant and reopened when the tenant's connection config changes.
 func (m *TenantConnectionManager) GetTenantDB(ctx context.Context, tenantID string) (*gorm.DB, error) {
+	ctx, span := tracer().Start(ctx, "database.GetTenantDB",
+		trace.WithAttributes(attribute.String("tenant_id", tenantID)))
+	defer span.End()
+
+	db, err := m.getTenantDB(ctx, tenantID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return db, err
+}
+
+// getTenantDB looks up the tenant and returns its cached connection, opening one when needed
+func (m *TenantConnectionManager) getTenantDB(ctx context.Context, tenantID string) (*gorm.DB, error) {
 	// Query master database for tenant configuration
 	var tenant Tenant
 	if err := m.masterDB.WithContext(ctx).Where("id = ? AND is_active = ?", tenantID, true).First(&tenant).Error; err != nil {
@@ -38,29 +194,317 @@ func (m *TenantConnectionManager) GetTenantDB(ctx context.Context, tenantID stri
 		return nil, fmt.Errorf("query tenant %s: %w", tenantID, err)
 	}
 
-	// Configure GORM logger
-	gormLogger := logger.Default.LogMode(logger.Silent)
+	tenant.ApplyPoolDefaults()
+	hash := tenantConfigHash(&tenant)
+
+	// Fast path: connection already cached for the current config
+	m.mu.RLock()
+	conn, ok := m.conns[tenantID]
+	m.mu.RUnlock()
+	if ok && conn.configHash == hash {
+		conn.touch()
+		return conn.db, nil
+	}
+
+	m.mu.Lock()
+
+	// Re-check after acquiring the write lock
+	if conn, ok := m.conns[tenantID]; ok {
+		if conn.configHash == hash {
+			conn.touch()
+			m.mu.Unlock()
+			return conn.db, nil
+		}
+		// Config changed since the connection was opened, drop the stale one
+		m.retireLocked(tenantID, conn.db)
+		delete(m.conns, tenantID)
+		m.logger.Info("Tenant database config changed, reopening connection",
+			zap.String("tenant_id", tenantID))
+	}
+
+	// Concurrent first requests share one open; each stops waiting for it only when its own ctx ends
+	if pending, ok := m.opening[tenantID]; ok && pending.configHash == hash {
+		m.mu.Unlock()
+		return pending.wait(ctx)
+	}
+	pending := &pendingConn{configHash: hash, done: make(chan struct{})}
+	if m.opening == nil {
+		m.opening = make(map[string]*pendingConn)
+	}
+	m.opening[tenantID] = pending
+	m.mu.Unlock()
+
+	// Dial, ping and retry without holding mu, so an unreachable tenant database does not stall other tenants.
+	// The open outlives this request so that a cancelled first caller does not fail the requests waiting on it.
+	go func() {
+		openCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tenantOpenTimeout)
+		defer cancel()
+		db, err := m.openTenantDB(openCtx, &tenant)
+		m.finishOpen(tenantID, pending, db, err)
+	}()
+
+	db, err := pending.wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	trace.SpanFromContext(ctx).AddEvent("tenant connection opened")
+
+	return db, nil
+}
+
+// finishOpen caches a newly opened connection and hands the result to the requests waiting on pending
+func (m *TenantConnectionManager) finishOpen(tenantID string, pending *pendingConn, db *gorm.DB, err error) {
+	m.mu.Lock()
+	if m.opening[tenantID] == pending {
+		delete(m.opening, tenantID)
+	}
+	if err == nil {
+		// A connection opened meanwhile for another config of the tenant is replaced
+		if old, ok := m.conns[tenantID]; ok {
+			m.retireLocked(tenantID, old.db)
+			delete(m.conns, tenantID)
+		}
+		if m.conns == nil {
+			m.conns = make(map[string]*tenantConn)
+		}
+		if m.maxCachedConnections > 0 && len(m.conns) >= m.maxCachedConnections {
+			m.evictLRULocked()
+		}
+
+		conn := &tenantConn{db: db, configHash: pending.configHash}
+		conn.touch()
+		m.conns[tenantID] = conn
+	}
+	m.mu.Unlock()
+
+	pending.db, pending.err = db, err
+	close(pending.done)
+}
+
+// evictLRULocked closes the least recently used connection; the caller must hold m.mu
+func (m *TenantConnectionManager) evictLRULocked() {
+	var oldestID string
+	var oldest int64
+	for id, conn := range m.conns {
+		if used := conn.lastUsed.Load(); oldestID == "" || used < oldest {
+			oldestID, oldest = id, used
+		}
+	}
+	if oldestID == "" {
+		return
+	}
+
+	m.evictLocked(oldestID)
+	m.logger.Debug("Evicted least recently used tenant connection",
+		zap.String("tenant_id", oldestID))
+}
+
+// evictLocked removes a cached connection and retires it; the caller must hold m.mu
+func (m *TenantConnectionManager) evictLocked(tenantID string) {
+	conn := m.conns[tenantID]
+	delete(m.conns, tenantID)
+	m.evictions.Add(1)
+
+	m.retireLocked(tenantID, conn.db)
+}
+
+// retiredDrainInterval is how often a retired connection is checked for connections still in use
+const retiredDrainInterval = 100 * time.Millisecond
+
+// retireLocked takes a connection out of the cache without cutting off requests that still hold it: it is
+// closed once closeGrace has passed and none of its connections is in use, e.g. by an open transaction.
+// The caller must hold m.mu.
+func (m *TenantConnectionManager) retireLocked(tenantID string, db *gorm.DB) {
+	if m.closeGrace <= 0 && !poolInUse(db) {
+		m.closeRetired(tenantID, db)
+		return
+	}
+
+	if m.retired == nil {
+		m.retired = make(map[*gorm.DB]string)
+	}
+	m.retired[db] = tenantID
+	go m.closeWhenDrained(tenantID, db)
+}
+
+// closeWhenDrained closes a retired connection after closeGrace once it is no longer in use. CloseAll closes
+// it instead when called first.
+func (m *TenantConnectionManager) closeWhenDrained(tenantID string, db *gorm.DB) {
+	grace := time.NewTimer(m.closeGrace)
+	defer grace.Stop()
+	select {
+	case <-m.stop:
+		return
+	case <-grace.C:
+	}
+
+	ticker := time.NewTicker(retiredDrainInterval)
+	defer ticker.Stop()
+	for poolInUse(db) {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+
+	m.mu.Lock()
+	_, ok := m.retired[db]
+	delete(m.retired, db)
+	m.mu.Unlock()
+
+	if ok {
+		m.closeRetired(tenantID, db)
+	}
+}
+
+// closeRetired closes a connection taken out of the cache, logging failures
+func (m *TenantConnectionManager) closeRetired(tenantID string, db *gorm.DB) {
+	if err := closeDB(db); err != nil {
+		m.logger.Warn("Failed to close evicted tenant connection",
+			zap.String("tenant_id", tenantID),
+			zap.Error(err))
+	}
+}
+
+// evictIdleLoop periodically closes connections idle longer than idleTimeout until CloseAll is called
+func (m *TenantConnectionManager) evictIdleLoop() {
+	ticker := time.NewTicker(m.idleTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.evictIdle()
+		}
+	}
+}
+
+// evictIdle closes connections that have not been used within idleTimeout
+func (m *TenantConnectionManager) evictIdle() {
+	cutoff := time.Now().Add(-m.idleTimeout).UnixNano()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, conn := range m.conns {
+		if conn.lastUsed.Load() < cutoff {
+			m.evictLocked(id)
+			m.logger.Debug("Evicted idle tenant connection",
+				zap.String("tenant_id", id))
+		}
+	}
+}
+
+// Stats returns the number of cached connections and total evictions
+func (m *TenantConnectionManager) Stats() TenantConnectionStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return TenantConnectionStats{
+		Cached:               len(m.conns),
+		Evictions:            m.evictions.Load(),
+		MissingTenantContext: m.missingTenantContext.Load(),
+	}
+}
+
+// reportMissingTenant records a tenant repository call made without a tenant in context when strict mode is on.
+// The stack trace points at the caller that skipped the tenant context.
+func (m *TenantConnectionManager) reportMissingTenant(err *TenantContextError) {
+	if m == nil || !m.strictTenantContext {
+		return
+	}
+	m.missingTenantContext.Add(1)
+	m.logger.Error("Tenant repository used without tenant context",
+		zap.String("operation", err.Operation),
+		zap.Stack("stack"))
+}
+
+// touch records that the connection was just used
+func (c *tenantConn) touch() {
+	c.lastUsed.Store(time.Now().UnixNano())
+}
+
+// tenantOpenTimeout bounds opening a tenant database, retries included
+const tenantOpenTimeout = 30 * time.Second
+
+// openTenantDB opens and verifies a new database connection for the tenant, retrying a database that cannot
+// be reached as set by WithConnectRetry until ctx ends.
+func (m *TenantConnectionManager) openTenantDB(ctx context.Context, tenant *Tenant) (*gorm.DB, error) {
+	dialector, err := m.tenantDialector(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := connectWithRetry(ctx, m.connectRetries, m.connectBackoff, m.logger.With(zap.String("tenant_id", tenant.ID)),
+		func(ctx context.Context) (*gorm.DB, error) {
+			return m.connectTenantDB(ctx, tenant, dialector)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Debug("Tenant database connection established",
+		zap.String("tenant_id", tenant.ID),
+		zap.String("db_type", tenant.DBType))
+
+	return db, nil
+}
+
+// tenantDialector builds the GORM dialector for the tenant's database type and connection string
+func (m *TenantConnectionManager) tenantDialector(tenant *Tenant) (gorm.Dialector, error) {
+	tenantID := tenant.ID
+
+	cnn := tenant.Cnn
+	if m.secretDecryptor != nil {
+		var err error
+		if cnn, err = m.secretDecryptor.DecryptString(tenantID, tenant.Cnn); err != nil {
+			return nil, fmt.Errorf("decrypt connection string for tenant %s: %w", tenantID, err)
+		}
+	}
 
 	// Create database connection based on tenant's database type using the connection string
 	var dialector gorm.Dialector
 
 	switch tenant.DBType {
 	case "postgresql", "postgres":
-		dialector = postgres.Open(tenant.Cnn)
+		dsn := cnn
+		if tenant.DBSSLMode != "" {
+			var err error
+			if dsn, err = withSSLOptions(dsn, tenant.SSLOptions()); err != nil {
+				return nil, fmt.Errorf("apply ssl settings for tenant %s: %w", tenantID, err)
+			}
+		}
+		dialector = postgres.Open(dsn)
 
 	case "mysql":
-		dialector = mysql.Open(tenant.Cnn)
+		if err := requireNoSSLMode(tenant); err != nil {
+			return nil, err
+		}
+		dialector = mysql.Open(cnn)
 
 	case "sqlite":
-		dialector = sqlite.Open(tenant.Cnn)
+		if err := requireNoSSLMode(tenant); err != nil {
+			return nil, err
+		}
+		dialector = sqlite.Open(cnn)
 
 	default:
 		return nil, fmt.Errorf("unsupported database type '%s' for tenant %s", tenant.DBType, tenantID)
 	}
+	return dialector, nil
+}
+
+// connectTenantDB makes a single attempt to open and ping the tenant database; the pool is closed again
+// when the ping fails
+func (m *TenantConnectionManager) connectTenantDB(ctx context.Context, tenant *Tenant, dialector gorm.Dialector) (*gorm.DB, error) {
+	tenantID := tenant.ID
 
 	// Open database connection
 	db, err := gorm.Open(dialector, &gorm.Config{
-		Logger: gormLogger,
+		Logger: newTenantGormLogger(m.logger, m.slowQueryThreshold, tenantID),
 		NowFunc: func() time.Time {
 			return time.Now().UTC()
 		},
@@ -68,6 +512,12 @@ func (m *TenantConnectionManager) GetTenantDB(ctx context.Context, tenantID stri
 	if err != nil {
 		return nil, fmt.Errorf("open %s database for tenant %s: %w", tenant.DBType, tenantID, err)
 	}
+	if err := registerTracing(db); err != nil {
+		return nil, fmt.Errorf("register tracing for tenant %s: %w", tenantID, err)
+	}
+	if err := registerContextErrors(db); err != nil {
+		return nil, fmt.Errorf("register context errors for tenant %s: %w", tenantID, err)
+	}
 
 	// Get underlying SQL DB to configure connection pool
 	sqlDB, err := db.DB()
@@ -75,24 +525,210 @@ func (m *TenantConnectionManager) GetTenantDB(ctx context.Context, tenantID stri
 		return nil, fmt.Errorf("get underlying database connection for tenant %s: %w", tenantID, err)
 	}
 
-	// Configure connection pool with reasonable defaults
-	sqlDB.SetMaxOpenConns(25)
-	sqlDB.SetMaxIdleConns(5)
-	sqlDB.SetConnMaxLifetime(time.Hour)
+	// Configure connection pool from the tenant's effective settings
+	sqlDB.SetMaxOpenConns(tenant.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(tenant.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(tenant.ConnMaxLifetime())
 
-	// Test connection
-	if err := sqlDB.Ping(); err != nil {
+	// Test connection within the open budget
+	if err := sqlDB.PingContext(ctx); err != nil {
+		sqlDB.Close()
 		return nil, fmt.Errorf("ping %s database for tenant %s: %w", tenant.DBType, tenantID, err)
 	}
 
-	m.logger.Debug("Tenant database connection established",
-		zap.String("tenant_id", tenantID),
-		zap.String("db_type", tenant.DBType))
-
 	return db, nil
 }
 
-// GetTenantConfig retrieves tenant configuration from master database
+// tenantPingTimeout bounds a single tenant health check
+const tenantPingTimeout = 5 * time.Second
+
+// PingTenant verifies that a tenant's database is reachable, reusing the cached connection
+func (m *TenantConnectionManager) PingTenant(ctx context.Context, tenantID string) error {
+	ctx, cancel := context.WithTimeout(ctx, tenantPingTimeout)
+	defer cancel()
+
+	db, err := m.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("get underlying database connection for tenant %s: %w", tenantID, err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping database for tenant %s: %w", tenantID, err)
+	}
+	return nil
+}
+
+// ActiveTenantIDs lists the IDs of all active tenants
+func (m *TenantConnectionManager) ActiveTenantIDs(ctx context.Context) ([]string, error) {
+	var tenantIDs []string
+	if err := m.masterDB.WithContext(ctx).Model(&Tenant{}).Where("is_active = ?", true).Order("id").Pluck("id", &tenantIDs).Error; err != nil {
+		return nil, fmt.Errorf("list active tenants: %w", err)
+	}
+	return tenantIDs, nil
+}
+
+// Concurrency returns how many tenants batch operations process in parallel
+func (m *TenantConnectionManager) Concurrency() int {
+	if m.concurrency <= 0 {
+		return parallel.DefaultConcurrency
+	}
+	return m.concurrency
+}
+
+// PingAllTenants pings every active tenant concurrently and returns the result per tenant ID (nil when reachable)
+func (m *TenantConnectionManager) PingAllTenants(ctx context.Context) (map[string]error, error) {
+	tenantIDs, err := m.ActiveTenantIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pingErrs, _ := parallel.Map(ctx, tenantIDs, m.Concurrency(), func(ctx context.Context, _ int, tenantID string) (error, error) {
+		return m.PingTenant(ctx, tenantID), nil
+	})
+
+	results := make(map[string]error, len(tenantIDs))
+	for i, tenantID := range tenantIDs {
+		results[tenantID] = pingErrs[i]
+	}
+	return results, nil
+}
+
+// ForEachTenant runs fn against the database of each tenant, up to Concurrency tenants at a time, resolving
+// connections through the cache. Every tenant is attempted even if others fail; the result maps each tenant
+// whose connection or fn failed (or that was skipped because ctx was cancelled) to its error, so it is empty
+// when all succeeded. fn receives a db bound to ctx and must be safe to call concurrently.
+func (m *TenantConnectionManager) ForEachTenant(ctx context.Context, tenantIDs []string, fn func(tenantID string, db *gorm.DB) error) map[string]error {
+	unique := make([]string, 0, len(tenantIDs))
+	seen := make(map[string]bool, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		if !seen[tenantID] {
+			seen[tenantID] = true
+			unique = append(unique, tenantID)
+		}
+	}
+
+	errs := make([]error, len(unique))
+	ran := make([]bool, len(unique))
+	_ = parallel.ForEach(ctx, unique, m.Concurrency(), func(ctx context.Context, i int, tenantID string) error {
+		ran[i] = true
+		errs[i] = m.runForTenant(ctx, tenantID, fn)
+		return nil
+	})
+
+	failed := make(map[string]error)
+	for i, tenantID := range unique {
+		if !ran[i] {
+			errs[i] = ctx.Err()
+		}
+		if errs[i] != nil {
+			failed[tenantID] = errs[i]
+		}
+	}
+	return failed
+}
+
+// runForTenant resolves the tenant's database and calls fn, turning a panic into an error
+func (m *TenantConnectionManager) runForTenant(ctx context.Context, tenantID string, fn func(tenantID string, db *gorm.DB) error) (err error) {
+	db, err := m.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("tenant %s: panic: %v", tenantID, p)
+		}
+	}()
+	if err := fn(tenantID, db.WithContext(ctx)); err != nil {
+		return fmt.Errorf("tenant %s: %w", tenantID, err)
+	}
+	return nil
+}
+
+// CloseTenant closes and evicts the cached connection for a tenant
+func (m *TenantConnectionManager) CloseTenant(tenantID string) error {
+	m.mu.Lock()
+	conn, ok := m.conns[tenantID]
+	delete(m.conns, tenantID)
+	m.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	if err := closeDB(conn.db); err != nil {
+		return fmt.Errorf("close database for tenant %s: %w", tenantID, err)
+	}
+	return nil
+}
+
+// CloseAll closes and evicts every cached tenant connection, along with evicted ones still draining, and stops
+// idle eviction
+func (m *TenantConnectionManager) CloseAll() error {
+	if m.stop != nil {
+		m.stopOnce.Do(func() { close(m.stop) })
+	}
+
+	m.mu.Lock()
+	conns := m.conns
+	retired := m.retired
+	m.conns = nil
+	m.retired = nil
+	m.mu.Unlock()
+
+	var errs []error
+	for tenantID, conn := range conns {
+		if err := closeDB(conn.db); err != nil {
+			errs = append(errs, fmt.Errorf("close database for tenant %s: %w", tenantID, err))
+		}
+	}
+	for db, tenantID := range retired {
+		if err := closeDB(db); err != nil {
+			errs = append(errs, fmt.Errorf("close evicted database for tenant %s: %w", tenantID, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("errors closing tenant databases: %v", errs)
+	}
+	return nil
+}
+
+// requireNoSSLMode rejects SSL settings on tenants whose driver does not support them
+func requireNoSSLMode(tenant *Tenant) error {
+	if tenant.DBSSLMode != "" && tenant.DBSSLMode != config.SSLModeDisable {
+		return fmt.Errorf("ssl mode %q is only supported for postgres tenants, tenant %s uses %s", tenant.DBSSLMode, tenant.ID, tenant.DBType)
+	}
+	return nil
+}
+
+// tenantConfigHash fingerprints the connection settings of a tenant
+func tenantConfigHash(tenant *Tenant) string {
+	key := fmt.Sprintf("%s\x00%s\x00%d\x00%d\x00%d\x00%s\x00%s\x00%s\x00%s", tenant.DBType, tenant.Cnn,
+		tenant.DBMaxOpenConns, tenant.DBMaxIdleConns, tenant.DBConnMaxLifetimeSeconds,
+		tenant.DBSSLMode, tenant.DBSSLRootCert, tenant.DBSSLCert, tenant.DBSSLKey)
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// poolInUse reports whether any connection of the pool is checked out, e.g. by a query or an open transaction
+func poolInUse(db *gorm.DB) bool {
+	sqlDB, err := db.DB()
+	return err == nil && sqlDB.Stats().InUse > 0
+}
+
+// closeDB closes the underlying sql.DB of a GORM connection
+func closeDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// GetTenantConfig retrieves tenant configuration from master database.
+// Pool settings are returned as their effective values with defaults applied.
 func (m *TenantConnectionManager) GetTenantConfig(ctx context.Context, tenantID string) (*Tenant, error) {
 	var tenant Tenant
 	if err := m.masterDB.WithContext(ctx).Where("id = ?", tenantID).First(&tenant).Error; err != nil {
@@ -101,5 +737,6 @@ func (m *TenantConnectionManager) GetTenantConfig(ctx context.Context, tenantID
 		}
 		return nil, fmt.Errorf("query tenant %s: %w", tenantID, err)
 	}
+	tenant.ApplyPoolDefaults()
 	return &tenant, nil
 }
@@ -10,15 +10,22 @@ package database
 // Alternatively, skip these tests and rely on integration tests with PostgreSQL.
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"myapp/internal/pkg/encryption"
 )
 
 // setupTestMasterDB creates an in-memory master database with tenant records
@@ -26,6 +33,12 @@ func setupTestMasterDB(t *testing.T) *gorm.DB {
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	require.NoError(t, err)
 
+	// Every connection to :memory: opens its own empty database; keep the pool to the one holding the tables,
+	// since PingAllTenants and ForEachTenant query the master from several goroutines
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
 	// Auto migrate tenant model
 	err = db.AutoMigrate(&Tenant{})
 	require.NoError(t, err)
@@ -138,6 +151,9 @@ func TestTenantConnectionManager_GetTenantDB(t *testing.T) {
 		}
 		err := masterDB.Create(tenant).Error
 		require.NoError(t, err)
+		// GORM skips zero values for columns with a default, so deactivate explicitly
+		err = masterDB.Model(tenant).Update("is_active", false).Error
+		require.NoError(t, err)
 
 		// Try to get tenant DB
 		db, err := manager.GetTenantDB(ctx, "inactive-tenant")
@@ -312,6 +328,468 @@ func TestTenantConnectionManager_MultipleTenants(t *testing.T) {
 	})
 }
 
+// TestTenantConnectionManager_Cache tests that tenant connections are cached and evicted
+func TestTenantConnectionManager_Cache(t *testing.T) {
+	masterDB := setupTestMasterDB(t)
+	logger := zaptest.NewLogger(t)
+	manager := NewTenantConnectionManager(masterDB, logger)
+	ctx := context.Background()
+
+	for _, id := range []string{"cache-1", "cache-2"} {
+		err := masterDB.Create(&Tenant{ID: id, Name: id, IsActive: true, DBType: "sqlite", Cnn: ":memory:"}).Error
+		require.NoError(t, err)
+	}
+
+	t.Run("same pointer for one tenant", func(t *testing.T) {
+		db1, err := manager.GetTenantDB(ctx, "cache-1")
+		require.NoError(t, err)
+		db2, err := manager.GetTenantDB(ctx, "cache-1")
+		require.NoError(t, err)
+
+		assert.Same(t, db1, db2)
+	})
+
+	t.Run("different pointers across tenants", func(t *testing.T) {
+		db1, err := manager.GetTenantDB(ctx, "cache-1")
+		require.NoError(t, err)
+		db2, err := manager.GetTenantDB(ctx, "cache-2")
+		require.NoError(t, err)
+
+		assert.NotSame(t, db1, db2)
+	})
+
+	t.Run("changed DSN invalidates cached connection", func(t *testing.T) {
+		db1, err := manager.GetTenantDB(ctx, "cache-2")
+		require.NoError(t, err)
+
+		err = masterDB.Model(&Tenant{}).Where("id = ?", "cache-2").Update("cnn", "file::memory:").Error
+		require.NoError(t, err)
+
+		db2, err := manager.GetTenantDB(ctx, "cache-2")
+		require.NoError(t, err)
+		assert.NotSame(t, db1, db2)
+
+		// The stale connection is closed
+		sqlDB, err := db1.DB()
+		require.NoError(t, err)
+		assert.Error(t, sqlDB.Ping())
+	})
+
+	t.Run("concurrent first requests open one connection", func(t *testing.T) {
+		err := masterDB.Create(&Tenant{ID: "cache-3", Name: "cache-3", IsActive: true, DBType: "sqlite", Cnn: ":memory:"}).Error
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		results := make(chan *gorm.DB, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				db, err := manager.GetTenantDB(ctx, "cache-3")
+				assert.NoError(t, err)
+				results <- db
+			}()
+		}
+		wg.Wait()
+		close(results)
+
+		first := <-results
+		for db := range results {
+			assert.Same(t, first, db)
+		}
+	})
+
+	t.Run("close tenant evicts connection", func(t *testing.T) {
+		db1, err := manager.GetTenantDB(ctx, "cache-1")
+		require.NoError(t, err)
+
+		require.NoError(t, manager.CloseTenant("cache-1"))
+		require.NoError(t, manager.CloseTenant("unknown"))
+
+		db2, err := manager.GetTenantDB(ctx, "cache-1")
+		require.NoError(t, err)
+		assert.NotSame(t, db1, db2)
+	})
+
+	t.Run("close all evicts every connection", func(t *testing.T) {
+		db1, err := manager.GetTenantDB(ctx, "cache-1")
+		require.NoError(t, err)
+
+		require.NoError(t, manager.CloseAll())
+
+		sqlDB, err := db1.DB()
+		require.NoError(t, err)
+		assert.Error(t, sqlDB.Ping())
+
+		db2, err := manager.GetTenantDB(ctx, "cache-1")
+		require.NoError(t, err)
+		assert.NotSame(t, db1, db2)
+	})
+}
+
+// TestTenantConnectionManager_Eviction tests LRU and idle eviction of cached connections
+func TestTenantConnectionManager_Eviction(t *testing.T) {
+	ctx := context.Background()
+
+	createTenants := func(t *testing.T, masterDB *gorm.DB, ids ...string) {
+		for _, id := range ids {
+			err := masterDB.Create(&Tenant{ID: id, Name: id, IsActive: true, DBType: "sqlite", Cnn: ":memory:"}).Error
+			require.NoError(t, err)
+		}
+	}
+
+	t.Run("evict least recently used when full", func(t *testing.T) {
+		masterDB := setupTestMasterDB(t)
+		manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t), WithMaxCachedConnections(2))
+		defer manager.CloseAll()
+		createTenants(t, masterDB, "lru-1", "lru-2", "lru-3")
+
+		db1, err := manager.GetTenantDB(ctx, "lru-1")
+		require.NoError(t, err)
+		db2, err := manager.GetTenantDB(ctx, "lru-2")
+		require.NoError(t, err)
+
+		// Touch lru-1 so lru-2 becomes the least recently used
+		time.Sleep(time.Millisecond)
+		_, err = manager.GetTenantDB(ctx, "lru-1")
+		require.NoError(t, err)
+
+		_, err = manager.GetTenantDB(ctx, "lru-3")
+		require.NoError(t, err)
+
+		stats := manager.Stats()
+		assert.Equal(t, 2, stats.Cached)
+		assert.Equal(t, int64(1), stats.Evictions)
+
+		// lru-2 was closed, lru-1 is still usable
+		sqlDB2, err := db2.DB()
+		require.NoError(t, err)
+		assert.Error(t, sqlDB2.Ping())
+
+		again, err := manager.GetTenantDB(ctx, "lru-1")
+		require.NoError(t, err)
+		assert.Same(t, db1, again)
+	})
+
+	t.Run("close connections idle past timeout", func(t *testing.T) {
+		masterDB := setupTestMasterDB(t)
+		manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t), WithIdleTimeout(50*time.Millisecond))
+		defer manager.CloseAll()
+		createTenants(t, masterDB, "idle-1")
+
+		db, err := manager.GetTenantDB(ctx, "idle-1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, manager.Stats().Cached)
+
+		assert.Eventually(t, func() bool {
+			return manager.Stats().Cached == 0
+		}, 2*time.Second, 10*time.Millisecond)
+		assert.Equal(t, int64(1), manager.Stats().Evictions)
+
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		assert.Error(t, sqlDB.Ping())
+	})
+
+	t.Run("evicted connection stays open for an open transaction", func(t *testing.T) {
+		masterDB := setupTestMasterDB(t)
+		manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t), WithMaxCachedConnections(1))
+		defer manager.CloseAll()
+		createTenants(t, masterDB, "tx-1", "tx-2")
+
+		db1, err := manager.GetTenantDB(ctx, "tx-1")
+		require.NoError(t, err)
+		tx := db1.Begin()
+		require.NoError(t, tx.Error)
+		require.NoError(t, tx.Exec("CREATE TABLE items (id INTEGER PRIMARY KEY)").Error)
+
+		// Opening tx-2 evicts tx-1 while its transaction is still running
+		_, err = manager.GetTenantDB(ctx, "tx-2")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), manager.Stats().Evictions)
+
+		// Other requests holding tx-1 keep working until the transaction is done
+		require.NoError(t, db1.Exec("SELECT 1").Error)
+		require.NoError(t, tx.Exec("INSERT INTO items (id) VALUES (1)").Error)
+		require.NoError(t, tx.Commit().Error)
+
+		// Closed once the transaction released its connection
+		sqlDB1, err := db1.DB()
+		require.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			return sqlDB1.Ping() != nil
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("evicted connection stays open during the close grace", func(t *testing.T) {
+		masterDB := setupTestMasterDB(t)
+		manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t),
+			WithMaxCachedConnections(1), WithCloseGrace(time.Minute))
+		createTenants(t, masterDB, "grace-1", "grace-2")
+
+		db1, err := manager.GetTenantDB(ctx, "grace-1")
+		require.NoError(t, err)
+		_, err = manager.GetTenantDB(ctx, "grace-2")
+		require.NoError(t, err)
+
+		// A request that resolved grace-1 before the eviction can still query it
+		sqlDB1, err := db1.DB()
+		require.NoError(t, err)
+		assert.NoError(t, sqlDB1.Ping())
+
+		// CloseAll does not wait for the grace period
+		require.NoError(t, manager.CloseAll())
+		assert.Error(t, sqlDB1.Ping())
+	})
+
+	t.Run("close all stops idle eviction", func(t *testing.T) {
+		masterDB := setupTestMasterDB(t)
+		manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t), WithIdleTimeout(time.Minute))
+
+		require.NoError(t, manager.CloseAll())
+		require.NoError(t, manager.CloseAll())
+
+		select {
+		case <-manager.stop:
+		default:
+			t.Fatal("expected idle eviction loop to be stopped")
+		}
+	})
+}
+
+// TestTenantConnectionManager_PoolSettings tests per-tenant connection pool configuration
+func TestTenantConnectionManager_PoolSettings(t *testing.T) {
+	masterDB := setupTestMasterDB(t)
+	manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+	defer manager.CloseAll()
+	ctx := context.Background()
+
+	tenants := []*Tenant{
+		{ID: "pool-custom", Name: "Custom", IsActive: true, DBType: "sqlite", Cnn: ":memory:",
+			DBMaxOpenConns: 7, DBMaxIdleConns: 3, DBConnMaxLifetimeSeconds: 120},
+		{ID: "pool-default", Name: "Default", IsActive: true, DBType: "sqlite", Cnn: ":memory:"},
+	}
+	for _, tenant := range tenants {
+		require.NoError(t, masterDB.Create(tenant).Error)
+	}
+
+	t.Run("custom pool settings", func(t *testing.T) {
+		db, err := manager.GetTenantDB(ctx, "pool-custom")
+		require.NoError(t, err)
+
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		assert.Equal(t, 7, sqlDB.Stats().MaxOpenConnections)
+	})
+
+	t.Run("default pool settings", func(t *testing.T) {
+		db, err := manager.GetTenantDB(ctx, "pool-default")
+		require.NoError(t, err)
+
+		sqlDB, err := db.DB()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultTenantMaxOpenConns, sqlDB.Stats().MaxOpenConnections)
+	})
+
+	t.Run("tenant config reports effective settings", func(t *testing.T) {
+		custom, err := manager.GetTenantConfig(ctx, "pool-custom")
+		require.NoError(t, err)
+		assert.Equal(t, 7, custom.DBMaxOpenConns)
+		assert.Equal(t, 3, custom.DBMaxIdleConns)
+		assert.Equal(t, 2*time.Minute, custom.ConnMaxLifetime())
+
+		defaults, err := manager.GetTenantConfig(ctx, "pool-default")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultTenantMaxOpenConns, defaults.DBMaxOpenConns)
+		assert.Equal(t, DefaultTenantMaxIdleConns, defaults.DBMaxIdleConns)
+		assert.Equal(t, time.Hour, defaults.ConnMaxLifetime())
+	})
+
+	t.Run("changed pool settings reopen connection", func(t *testing.T) {
+		db1, err := manager.GetTenantDB(ctx, "pool-custom")
+		require.NoError(t, err)
+
+		err = masterDB.Model(&Tenant{}).Where("id = ?", "pool-custom").Update("db_max_open_conns", 9).Error
+		require.NoError(t, err)
+
+		db2, err := manager.GetTenantDB(ctx, "pool-custom")
+		require.NoError(t, err)
+		assert.NotSame(t, db1, db2)
+
+		sqlDB, err := db2.DB()
+		require.NoError(t, err)
+		assert.Equal(t, 9, sqlDB.Stats().MaxOpenConnections)
+	})
+}
+
+// TestTenantConnectionManager_PingTenants tests tenant reachability checks
+func TestTenantConnectionManager_PingTenants(t *testing.T) {
+	masterDB := setupTestMasterDB(t)
+	manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+	defer manager.CloseAll()
+	ctx := context.Background()
+
+	tenants := []*Tenant{
+		{ID: "ping-ok-1", Name: "Reachable 1", IsActive: true, DBType: "sqlite", Cnn: ":memory:"},
+		{ID: "ping-ok-2", Name: "Reachable 2", IsActive: true, DBType: "sqlite", Cnn: ":memory:"},
+		{ID: "ping-bad-path", Name: "Bad Path", IsActive: true, DBType: "sqlite", Cnn: "/nonexistent/dir/tenant.db"},
+		{ID: "ping-bad-type", Name: "Bad Type", IsActive: true, DBType: "oracle", Cnn: "whatever"},
+	}
+	for _, tenant := range tenants {
+		require.NoError(t, masterDB.Create(tenant).Error)
+	}
+	inactive := &Tenant{ID: "ping-inactive", Name: "Inactive", IsActive: true, DBType: "sqlite", Cnn: ":memory:"}
+	require.NoError(t, masterDB.Create(inactive).Error)
+	require.NoError(t, masterDB.Model(inactive).Update("is_active", false).Error)
+
+	t.Run("ping single tenant", func(t *testing.T) {
+		assert.NoError(t, manager.PingTenant(ctx, "ping-ok-1"))
+		assert.Error(t, manager.PingTenant(ctx, "ping-bad-path"))
+		assert.Error(t, manager.PingTenant(ctx, "missing"))
+	})
+
+	t.Run("ping all active tenants", func(t *testing.T) {
+		results, err := manager.PingAllTenants(ctx)
+		require.NoError(t, err)
+
+		assert.Len(t, results, 4)
+		assert.NotContains(t, results, "ping-inactive")
+		assert.NoError(t, results["ping-ok-1"])
+		assert.NoError(t, results["ping-ok-2"])
+		assert.Error(t, results["ping-bad-path"])
+		assert.Error(t, results["ping-bad-type"])
+	})
+
+	t.Run("ping reuses cached connections", func(t *testing.T) {
+		db1, err := manager.GetTenantDB(ctx, "ping-ok-1")
+		require.NoError(t, err)
+		require.NoError(t, manager.PingTenant(ctx, "ping-ok-1"))
+		db2, err := manager.GetTenantDB(ctx, "ping-ok-1")
+		require.NoError(t, err)
+		assert.Same(t, db1, db2)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := manager.PingAllTenants(cancelled)
+		assert.Error(t, err)
+	})
+}
+
+// TestTenantConnectionManager_ForEachTenant tests running a callback per tenant with isolated failures
+func TestTenantConnectionManager_ForEachTenant(t *testing.T) {
+	masterDB := setupTestMasterDB(t)
+	manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+	defer manager.CloseAll()
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	tenants := []*Tenant{
+		{ID: "each-1", Name: "Tenant 1", IsActive: true, DBType: "sqlite", Cnn: filepath.Join(dir, "each-1.db")},
+		{ID: "each-2", Name: "Tenant 2", IsActive: true, DBType: "sqlite", Cnn: filepath.Join(dir, "each-2.db")},
+		{ID: "each-broken", Name: "Broken", IsActive: true, DBType: "oracle", Cnn: "whatever"},
+	}
+	for _, tenant := range tenants {
+		require.NoError(t, masterDB.Create(tenant).Error)
+	}
+	for id, rows := range map[string]int{"each-1": 2, "each-2": 3} {
+		db, err := manager.GetTenantDB(ctx, id)
+		require.NoError(t, err)
+		require.NoError(t, db.AutoMigrate(&TestEntity{}))
+		for i := 0; i < rows; i++ {
+			require.NoError(t, db.Create(&TestEntity{Name: id}).Error)
+		}
+	}
+
+	t.Run("runs every tenant", func(t *testing.T) {
+		var mu sync.Mutex
+		counts := map[string]int64{}
+		errs := manager.ForEachTenant(ctx, []string{"each-1", "each-2", "each-1"}, func(tenantID string, db *gorm.DB) error {
+			var count int64
+			if err := db.Model(&TestEntity{}).Count(&count).Error; err != nil {
+				return err
+			}
+			mu.Lock()
+			counts[tenantID] = count
+			mu.Unlock()
+			return nil
+		})
+
+		assert.Empty(t, errs)
+		assert.Equal(t, map[string]int64{"each-1": 2, "each-2": 3}, counts)
+	})
+
+	t.Run("failures are isolated", func(t *testing.T) {
+		var mu sync.Mutex
+		var visited []string
+		errs := manager.ForEachTenant(ctx, []string{"each-1", "each-2", "each-broken", "each-missing"}, func(tenantID string, db *gorm.DB) error {
+			mu.Lock()
+			visited = append(visited, tenantID)
+			mu.Unlock()
+			if tenantID == "each-1" {
+				return errors.New("report failed")
+			}
+			if tenantID == "each-2" {
+				panic("unexpected")
+			}
+			return nil
+		})
+
+		assert.ElementsMatch(t, []string{"each-1", "each-2"}, visited)
+		require.Len(t, errs, 4)
+		assert.ErrorContains(t, errs["each-1"], "report failed")
+		assert.ErrorContains(t, errs["each-2"], "panic")
+		assert.Error(t, errs["each-broken"])
+		assert.Error(t, errs["each-missing"])
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		called := false
+		errs := manager.ForEachTenant(cancelled, []string{"each-1"}, func(string, *gorm.DB) error {
+			called = true
+			return nil
+		})
+		assert.False(t, called)
+		assert.ErrorIs(t, errs["each-1"], context.Canceled)
+	})
+}
+
+// TestTenantConnectionManager_EncryptedConnectionString tests opening tenants whose DSN is encrypted
+func TestTenantConnectionManager_EncryptedConnectionString(t *testing.T) {
+	masterDB := setupTestMasterDB(t)
+	enc, err := encryption.New(bytes.Repeat([]byte{0x42}, 32), true)
+	require.NoError(t, err)
+	manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t), WithSecretDecryptor(enc))
+	defer manager.CloseAll()
+	ctx := context.Background()
+
+	encrypted, err := enc.EncryptString("enc-ok", ":memory:")
+	require.NoError(t, err)
+	// Copied from another tenant, so it was encrypted with the wrong key
+	stolen, err := enc.EncryptString("enc-ok", ":memory:")
+	require.NoError(t, err)
+
+	for _, tenant := range []*Tenant{
+		{ID: "enc-ok", Name: "Encrypted", IsActive: true, DBType: "sqlite", Cnn: encrypted},
+		{ID: "enc-plain", Name: "Plain", IsActive: true, DBType: "sqlite", Cnn: ":memory:"},
+		{ID: "enc-stolen", Name: "Stolen", IsActive: true, DBType: "sqlite", Cnn: stolen},
+	} {
+		require.NoError(t, masterDB.Create(tenant).Error)
+	}
+
+	_, err = manager.GetTenantDB(ctx, "enc-ok")
+	assert.NoError(t, err)
+	_, err = manager.GetTenantDB(ctx, "enc-plain")
+	assert.NoError(t, err, "plain text connection strings keep working")
+	_, err = manager.GetTenantDB(ctx, "enc-stolen")
+	assert.ErrorIs(t, err, encryption.ErrDecrypt)
+}
+
 // TestTenantModel tests the Tenant model structure
 func TestTenantModel(t *testing.T) {
 	t.Run("create tenant model", func(t *testing.T) {
@@ -331,3 +809,48 @@ func TestTenantModel(t *testing.T) {
 		assert.Contains(t, tenant.Cnn, "host=localhost")
 	})
 }
+
+// TestTenantRepo_MissingTenantContext tests that tenant repository calls without a tenant yield the typed error
+// and are counted in strict mode
+func TestTenantRepo_MissingTenantContext(t *testing.T) {
+	masterDB := setupTestMasterDB(t)
+
+	t.Run("typed error", func(t *testing.T) {
+		repo := NewTenantRepo[TestEntity](NewTenantConnectionManager(masterDB, zaptest.NewLogger(t)))
+
+		_, err := repo.GetByID(context.Background(), 1)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrTenantNotInContext)
+
+		var tenantErr *TenantContextError
+		require.ErrorAs(t, err, &tenantErr)
+		assert.Equal(t, "TenantRepo[database.TestEntity]", tenantErr.Operation)
+		assert.Contains(t, err.Error(), "ContextMiddleware")
+	})
+
+	t.Run("strict mode counts and logs misuse", func(t *testing.T) {
+		core, logs := observer.New(zap.ErrorLevel)
+		manager := NewTenantConnectionManager(masterDB, zap.New(core), WithStrictTenantContext(true))
+		repo := NewTenantRepo[TestEntity](manager)
+		ctx := context.Background()
+
+		_, err := repo.GetAll(ctx, 10, 0)
+		assert.ErrorIs(t, err, ErrTenantNotInContext)
+		err = repo.Insert(ctx, &TestEntity{Name: "x"})
+		assert.ErrorIs(t, err, ErrTenantNotInContext)
+
+		assert.Equal(t, int64(2), manager.Stats().MissingTenantContext)
+		entries := logs.FilterMessage("Tenant repository used without tenant context").All()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "TenantRepo[database.TestEntity]", entries[0].ContextMap()["operation"])
+	})
+
+	t.Run("non-strict mode does not count", func(t *testing.T) {
+		manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+		repo := NewTenantRepo[TestEntity](manager)
+
+		_, err := repo.Count(context.Background(), nil)
+		assert.ErrorIs(t, err, ErrTenantNotInContext)
+		assert.Equal(t, int64(0), manager.Stats().MissingTenantContext)
+	})
+}
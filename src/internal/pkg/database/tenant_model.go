@@ -7,15 +7,62 @@ import (
 // Tenant represents a tenant entity stored in the master database
 // Contains database connection information for the tenant's dedicated database
 type Tenant struct {
-	ID        string    `gorm:"primaryKey;type:varchar(100)" json:"id"`
-	Name      string    `gorm:"type:varchar(255);not null" json:"name"`
-	DBType    string    `gorm:"type:varchar(50);not null;default:'mysql';column:db_type" json:"db_type"` // Database type: mysql, postgresql, sqlite
-	Cnn       string    `gorm:"type:text;not null;column:cnn" json:"-"`                                   // Connection string (DSN) - not exposed in JSON for security
-	IsActive  bool      `gorm:"default:true;column:is_active" json:"is_active"`
+	ID       string `gorm:"primaryKey;type:varchar(100)" json:"id"`
+	Name     string `gorm:"type:varchar(255);not null" json:"name"`
+	DBType   string `gorm:"type:varchar(50);not null;default:'mysql';column:db_type" json:"db_type"` // Database type: mysql, postgresql, sqlite
+	Cnn      string `gorm:"type:text;not null;column:cnn" json:"-"`                                  // Connection string (DSN) - not exposed in JSON for security
+	IsActive bool   `gorm:"default:true;column:is_active" json:"is_active"`
+
+	// Connection pool overrides; zero falls back to the defaults below
+	DBMaxOpenConns           int `gorm:"column:db_max_open_conns;not null;default:0" json:"db_max_open_conns"`
+	DBMaxIdleConns           int `gorm:"column:db_max_idle_conns;not null;default:0" json:"db_max_idle_conns"`
+	DBConnMaxLifetimeSeconds int `gorm:"column:db_conn_max_lifetime_seconds;not null;default:0" json:"db_conn_max_lifetime_seconds"`
+
+	// SSL settings for postgres tenants; an empty mode keeps whatever the connection string specifies
+	DBSSLMode     string `gorm:"column:db_ssl_mode;type:varchar(20);not null;default:''" json:"db_ssl_mode"`
+	DBSSLRootCert string `gorm:"column:db_ssl_root_cert;type:text" json:"db_ssl_root_cert"`
+	DBSSLCert     string `gorm:"column:db_ssl_cert;type:text" json:"db_ssl_cert"`
+	DBSSLKey      string `gorm:"column:db_ssl_key;type:text" json:"db_ssl_key"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Default tenant connection pool settings
+const (
+	DefaultTenantMaxOpenConns           = 25
+	DefaultTenantMaxIdleConns           = 5
+	DefaultTenantConnMaxLifetimeSeconds = 3600
+)
+
+// ApplyPoolDefaults replaces unset pool settings with the defaults so the fields hold the effective values
+func (t *Tenant) ApplyPoolDefaults() {
+	if t.DBMaxOpenConns <= 0 {
+		t.DBMaxOpenConns = DefaultTenantMaxOpenConns
+	}
+	if t.DBMaxIdleConns <= 0 {
+		t.DBMaxIdleConns = DefaultTenantMaxIdleConns
+	}
+	if t.DBConnMaxLifetimeSeconds <= 0 {
+		t.DBConnMaxLifetimeSeconds = DefaultTenantConnMaxLifetimeSeconds
+	}
+}
+
+// ConnMaxLifetime returns the connection max lifetime as a duration
+func (t *Tenant) ConnMaxLifetime() time.Duration {
+	return time.Duration(t.DBConnMaxLifetimeSeconds) * time.Second
+}
+
+// SSLOptions returns the tenant's SSL settings
+func (t *Tenant) SSLOptions() SSLOptions {
+	return SSLOptions{
+		Mode:     t.DBSSLMode,
+		RootCert: t.DBSSLRootCert,
+		Cert:     t.DBSSLCert,
+		Key:      t.DBSSLKey,
+	}
+}
+
 // TableName specifies the table name for Tenant
 func (Tenant) TableName() string {
 	return "tenants"
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// tracerName identifies spans started by the database package
+const tracerName = "myapp/internal/pkg/database"
+
+// spanKey is the gorm instance key holding the span of the running statement
+const spanKey = "myapp:tracing_span"
+
+// tracer returns the database tracer from the global provider
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// registerTracing adds GORM callbacks that wrap every statement in a child span of the context
+// passed to WithContext, so BaseRepository, TenantRepo and custom repository queries are traced
+func registerTracing(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("tracing:before_create", startStatementSpan("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", endStatementSpan),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", startStatementSpan("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", endStatementSpan),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", startStatementSpan("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", endStatementSpan),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startStatementSpan("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", endStatementSpan),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", startStatementSpan("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", endStatementSpan),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startStatementSpan("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", endStatementSpan),
+	)
+}
+
+// startStatementSpan starts a span named after the operation and table
+func startStatementSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		name := "db." + operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := tracer().Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(db.Dialector.Name()),
+				semconv.DBOperation(operation),
+				semconv.DBSQLTable(db.Statement.Table),
+			))
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+// endStatementSpan records the SQL, affected rows and error and ends the span
+func endStatementSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBStatement(db.Statement.SQL.String()), // Placeholders only, values are not recorded
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap/zaptest"
+)
+
+// recordSpans installs an in-memory span recorder as the global tracer provider for the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+// findSpan returns the first ended span with the given name
+func findSpan(spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+// spanAttribute returns the value of a span attribute
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracing_TenantDBSpans(t *testing.T) {
+	recorder := recordSpans(t)
+
+	masterDB := setupTestMasterDB(t)
+	require.NoError(t, masterDB.Create(&Tenant{
+		ID: "traced-tenant", Name: "Traced", IsActive: true, DBType: "sqlite", Cnn: ":memory:",
+		CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}).Error)
+	manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	db, err := manager.GetTenantDB(ctx, "traced-tenant")
+	require.NoError(t, err)
+	require.NoError(t, db.WithContext(ctx).AutoMigrate(&TestEntity{}))
+	require.NoError(t, NewBaseRepository[TestEntity](db).Insert(ctx, &TestEntity{Name: "traced"}))
+	_, err = NewBaseRepository[TestEntity](db).GetByID(ctx, 9999)
+	assert.Error(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+
+	t.Run("GetTenantDB span", func(t *testing.T) {
+		span := findSpan(spans, "database.GetTenantDB")
+		require.NotNil(t, span)
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		tenantID, ok := spanAttribute(span, "tenant_id")
+		require.True(t, ok)
+		assert.Equal(t, "traced-tenant", tenantID.AsString())
+		require.Len(t, span.Events(), 1)
+		assert.Equal(t, "tenant connection opened", span.Events()[0].Name)
+	})
+
+	t.Run("repository statements are child spans", func(t *testing.T) {
+		insert := findSpan(spans, "db.create test_entities")
+		require.NotNil(t, insert)
+		assert.Equal(t, parent.SpanContext().TraceID(), insert.SpanContext().TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), insert.Parent().SpanID())
+		statement, ok := spanAttribute(insert, "db.statement")
+		require.True(t, ok)
+		assert.Contains(t, statement.AsString(), "INSERT INTO")
+
+		query := findSpan(spans, "db.query test_entities")
+		require.NotNil(t, query)
+		assert.Equal(t, codes.Unset, query.Status().Code, "record not found is not a span error")
+	})
+
+	t.Run("failed lookup marks the span as an error", func(t *testing.T) {
+		_, err := manager.GetTenantDB(context.Background(), "missing-tenant")
+		require.Error(t, err)
+
+		var failed sdktrace.ReadOnlySpan
+		for _, span := range recorder.Ended() {
+			if span.Name() == "database.GetTenantDB" {
+				if tenantID, _ := spanAttribute(span, "tenant_id"); tenantID.AsString() == "missing-tenant" {
+					failed = span
+				}
+			}
+		}
+		require.NotNil(t, failed)
+		assert.Equal(t, codes.Error, failed.Status().Code)
+	})
+}
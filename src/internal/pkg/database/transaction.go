@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// runInTransaction begins a transaction on db, invokes fn and commits when fn returns nil.
+// The transaction is rolled back when fn returns an error or panics; panics are re-raised after the rollback.
+func runInTransaction(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) error) (err error) {
+	tx := db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("begin transaction: %w", tx.Error)
+	}
+
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if rbErr := tx.Rollback().Error; rbErr != nil && err != nil {
+			err = fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	// A failed commit ends the transaction too, so there is nothing left to roll back
+	committed = true
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Transaction runs fn in a master database transaction, committing on success and rolling back on error or panic
+func (m *DatabaseManager) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return runInTransaction(ctx, m.MasterDB, fn)
+}
+
+// Transaction runs fn in a transaction on the current tenant's database, committing on success and rolling back on error or panic
+func (r *TenantRepo[T]) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	db, err := r.getTenantDB(ctx)
+	if err != nil {
+		return fmt.Errorf("get tenant database: %w", err)
+	}
+	return runInTransaction(ctx, db, fn)
+}
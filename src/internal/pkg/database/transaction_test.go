@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupTransactionDB creates a file-backed SQLite database so the transaction and
+// follow-up queries share the same data across pooled connections
+func setupTransactionDB(t *testing.T) (*gorm.DB, string) {
+	dsn := filepath.Join(t.TempDir(), "tx.db")
+	db, err := gorm.Open(sqlite.Open(dsn+"?_busy_timeout=5000"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&TestEntity{}))
+	return db, dsn
+}
+
+// TestDatabaseManager_Transaction tests commit, rollback and panic handling
+func TestDatabaseManager_Transaction(t *testing.T) {
+	db, _ := setupTransactionDB(t)
+	manager := &DatabaseManager{MasterDB: db}
+	ctx := context.Background()
+
+	count := func() int64 {
+		var n int64
+		require.NoError(t, db.Model(&TestEntity{}).Count(&n).Error)
+		return n
+	}
+
+	t.Run("commit on success", func(t *testing.T) {
+		err := manager.Transaction(ctx, func(tx *gorm.DB) error {
+			if err := tx.Create(&TestEntity{Name: "first", Status: "active"}).Error; err != nil {
+				return err
+			}
+			return tx.Create(&TestEntity{Name: "second", Status: "active"}).Error
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count())
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		err := manager.Transaction(ctx, func(tx *gorm.DB) error {
+			require.NoError(t, tx.Create(&TestEntity{Name: "discarded", Status: "active"}).Error)
+			return errBoom
+		})
+		assert.ErrorIs(t, err, errBoom)
+		assert.Equal(t, int64(2), count())
+	})
+
+	t.Run("rollback and re-panic on panic", func(t *testing.T) {
+		assert.PanicsWithValue(t, "kaboom", func() {
+			_ = manager.Transaction(ctx, func(tx *gorm.DB) error {
+				require.NoError(t, tx.Create(&TestEntity{Name: "discarded", Status: "active"}).Error)
+				panic("kaboom")
+			})
+		})
+		assert.Equal(t, int64(2), count())
+	})
+}
+
+// TestTenantRepo_Transaction tests that the transaction runs against the tenant's database
+func TestTenantRepo_Transaction(t *testing.T) {
+	tenantDB, dsn := setupTransactionDB(t)
+
+	masterDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, masterDB.AutoMigrate(&Tenant{}))
+	require.NoError(t, masterDB.Create(&Tenant{ID: "tenant-tx", Name: "Tx", DBType: "sqlite", Cnn: dsn, IsActive: true}).Error)
+
+	manager := NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+	t.Cleanup(func() { manager.CloseAll() })
+
+	repo := NewTenantRepo[TestEntity](manager)
+	ctx := WithTenantID(context.Background(), "tenant-tx")
+
+	t.Run("commit on success", func(t *testing.T) {
+		err := repo.Transaction(ctx, func(tx *gorm.DB) error {
+			return tx.Create(&TestEntity{Name: "kept", Status: "active"}).Error
+		})
+		require.NoError(t, err)
+
+		var n int64
+		require.NoError(t, tenantDB.Model(&TestEntity{}).Count(&n).Error)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		err := repo.Transaction(ctx, func(tx *gorm.DB) error {
+			require.NoError(t, tx.Create(&TestEntity{Name: "discarded", Status: "active"}).Error)
+			return errors.New("boom")
+		})
+		assert.Error(t, err)
+
+		var n int64
+		require.NoError(t, tenantDB.Model(&TestEntity{}).Count(&n).Error)
+		assert.Equal(t, int64(1), n)
+	})
+
+	t.Run("missing tenant in context", func(t *testing.T) {
+		called := false
+		err := repo.Transaction(context.Background(), func(tx *gorm.DB) error {
+			called = true
+			return nil
+		})
+		assert.Error(t, err)
+		assert.False(t, called)
+	})
+}
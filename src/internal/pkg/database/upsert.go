@@ -0,0 +1,93 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// upsert inserts entity or updates updateColumns when a row with the same conflictColumns exists
+func upsert[T any](db *gorm.DB, entity *T, conflictColumns []string, updateColumns []string) error {
+	onConflict := clause.OnConflict{}
+
+	// MySQL resolves conflicts against any unique key and ignores the conflict target,
+	// while Postgres and SQLite require one for DO UPDATE
+	if db.Dialector.Name() != "mysql" {
+		if len(conflictColumns) == 0 {
+			return fmt.Errorf("%w: upsert requires at least one conflict column", ErrInvalidCriterion)
+		}
+		for _, field := range conflictColumns {
+			column, err := resolveColumn(db, new(T), field)
+			if err != nil {
+				return err
+			}
+			onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+		}
+	}
+
+	if len(updateColumns) == 0 {
+		onConflict.UpdateAll = true
+	} else {
+		columns := make([]string, 0, len(updateColumns))
+		for _, field := range updateColumns {
+			column, err := resolveColumn(db, new(T), field)
+			if err != nil {
+				return err
+			}
+			columns = append(columns, column)
+		}
+		onConflict.DoUpdates = clause.AssignmentColumns(columns)
+	}
+
+	if err := db.Clauses(onConflict).Create(entity).Error; err != nil {
+		return fmt.Errorf("upsert entity: %w", err)
+	}
+	return nil
+}
+
+// findOrCreate returns the row matching conditions, inserting entity when none exists.
+// A concurrent insert of the same row is resolved by the unique constraint: the losing
+// insert becomes a no-op and the winner's row is returned.
+func findOrCreate[T any](db *gorm.DB, conditions map[string]interface{}, entity *T) (*T, bool, error) {
+	find := func() (*T, error) {
+		var existing T
+		query := db
+		for key, value := range conditions {
+			query = query.Where(fmt.Sprintf("%s = ?", key), value)
+		}
+		result := query.Limit(1).Find(&existing)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, nil
+		}
+		return &existing, nil
+	}
+
+	existing, err := find()
+	if err != nil {
+		return nil, false, fmt.Errorf("find entity: %w", err)
+	}
+	if existing != nil {
+		return existing, false, nil
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(entity)
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("create entity: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return entity, true, nil
+	}
+
+	existing, err = find()
+	if err != nil {
+		return nil, false, fmt.Errorf("find entity: %w", err)
+	}
+	if existing == nil {
+		return nil, false, fmt.Errorf("find entity after conflict: %w", gorm.ErrRecordNotFound)
+	}
+	return existing, false, nil
+}
@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// UpsertEntity is a test model with a unique business key
+type UpsertEntity struct {
+	ID    uint   `gorm:"primarykey"`
+	Code  string `gorm:"size:50;uniqueIndex"`
+	Name  string `gorm:"size:255"`
+	Value int
+}
+
+// setupUpsertDB creates a file-backed SQLite database so that concurrent connections share state
+func setupUpsertDB(t *testing.T) *gorm.DB {
+	dsn := filepath.Join(t.TempDir(), "upsert.db") + "?_busy_timeout=5000&_journal_mode=WAL"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.AutoMigrate(&UpsertEntity{})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// TestBaseRepository_Upsert tests insert-or-update on a conflict column
+func TestBaseRepository_Upsert(t *testing.T) {
+	db := setupUpsertDB(t)
+	repo := NewBaseRepository[UpsertEntity](db)
+	ctx := context.Background()
+
+	t.Run("insert then update", func(t *testing.T) {
+		err := repo.Upsert(ctx, &UpsertEntity{Code: "A", Name: "First", Value: 1}, []string{"code"}, []string{"name"})
+		require.NoError(t, err)
+
+		err = repo.Upsert(ctx, &UpsertEntity{Code: "A", Name: "Second", Value: 2}, []string{"code"}, []string{"name"})
+		require.NoError(t, err)
+
+		results, err := repo.GetWhere(ctx, map[string]interface{}{"code": "A"})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Second", results[0].Name)
+		assert.Equal(t, 1, results[0].Value, "columns not listed in updateColumns are kept")
+	})
+
+	t.Run("update all columns", func(t *testing.T) {
+		err := repo.Upsert(ctx, &UpsertEntity{Code: "B", Name: "First", Value: 1}, []string{"code"}, nil)
+		require.NoError(t, err)
+
+		err = repo.Upsert(ctx, &UpsertEntity{Code: "B", Name: "Second", Value: 2}, []string{"code"}, nil)
+		require.NoError(t, err)
+
+		results, err := repo.GetWhere(ctx, map[string]interface{}{"code": "B"})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Second", results[0].Name)
+		assert.Equal(t, 2, results[0].Value)
+	})
+
+	t.Run("missing conflict columns", func(t *testing.T) {
+		err := repo.Upsert(ctx, &UpsertEntity{Code: "C"}, nil, nil)
+		assert.ErrorIs(t, err, ErrInvalidCriterion)
+	})
+
+	t.Run("unknown column", func(t *testing.T) {
+		err := repo.Upsert(ctx, &UpsertEntity{Code: "C"}, []string{"code"}, []string{"missing"})
+		assert.ErrorIs(t, err, ErrInvalidCriterion)
+	})
+
+	t.Run("within transaction", func(t *testing.T) {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			txRepo := repo.WithTx(tx)
+			for i := 0; i < 3; i++ {
+				if err := txRepo.Upsert(ctx, &UpsertEntity{Code: "TX", Name: fmt.Sprintf("v%d", i)}, []string{"code"}, []string{"name"}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+
+		count, err := repo.Count(ctx, map[string]interface{}{"code": "TX"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("concurrent upserts produce one row", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				errs <- repo.Upsert(ctx, &UpsertEntity{Code: "CONC", Name: fmt.Sprintf("v%d", i), Value: i}, []string{"code"}, []string{"name", "value"})
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+
+		count, err := repo.Count(ctx, map[string]interface{}{"code": "CONC"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
+
+// TestBaseRepository_FindOrCreate tests returning existing rows or creating new ones
+func TestBaseRepository_FindOrCreate(t *testing.T) {
+	db := setupUpsertDB(t)
+	repo := NewBaseRepository[UpsertEntity](db)
+	ctx := context.Background()
+
+	t.Run("creates when missing", func(t *testing.T) {
+		entity, created, err := repo.FindOrCreate(ctx, map[string]interface{}{"code": "X"}, &UpsertEntity{Code: "X", Name: "New"})
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotZero(t, entity.ID)
+	})
+
+	t.Run("returns existing", func(t *testing.T) {
+		entity, created, err := repo.FindOrCreate(ctx, map[string]interface{}{"code": "X"}, &UpsertEntity{Code: "X", Name: "Other"})
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, "New", entity.Name)
+	})
+
+	t.Run("within transaction", func(t *testing.T) {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			txRepo := repo.WithTx(tx)
+			_, created, err := txRepo.FindOrCreate(ctx, map[string]interface{}{"code": "TX"}, &UpsertEntity{Code: "TX"})
+			if err != nil {
+				return err
+			}
+			assert.True(t, created)
+			_, created, err = txRepo.FindOrCreate(ctx, map[string]interface{}{"code": "TX"}, &UpsertEntity{Code: "TX"})
+			assert.False(t, created)
+			return err
+		})
+		require.NoError(t, err)
+
+		count, err := repo.Count(ctx, map[string]interface{}{"code": "TX"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("concurrent calls create one row", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		createdCount := 0
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				entity, created, err := repo.FindOrCreate(ctx, map[string]interface{}{"code": "CONC"}, &UpsertEntity{Code: "CONC"})
+				assert.NoError(t, err)
+				assert.NotNil(t, entity)
+				if created {
+					mu.Lock()
+					createdCount++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 1, createdCount)
+		count, err := repo.Count(ctx, map[string]interface{}{"code": "CONC"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
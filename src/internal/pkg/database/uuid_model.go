@@ -0,0 +1,71 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"myapp/internal/pkg/uuidv7"
+)
+
+// ErrInvalidUUID is returned by the UUID lookups when the given ID is not a valid UUID
+var ErrInvalidUUID = errors.New("invalid uuid")
+
+// uuidGenerator assigns BaseModel primary keys
+var uuidGenerator = uuidv7.NewGenerator()
+
+// BaseModel is an opt-in base for entities keyed by a UUIDv7 string instead of an auto-increment integer.
+// UUIDv7 keys are time-ordered, so they index well, and they do not reveal row counts or collide when
+// rows from several tenant databases are merged. Embed it in place of an ID field:
+//
+//	type Order struct {
+//		database.BaseModel
+//		Total int64
+//	}
+//
+// Look such entities up with GetByUUID and DeleteByUUID; the uint ID methods do not apply to them.
+type BaseModel struct {
+	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate assigns a new UUIDv7 when the ID is not set, keeping IDs chosen by the caller
+func (m *BaseModel) BeforeCreate(tx *gorm.DB) error {
+	if m.ID != "" {
+		return nil
+	}
+	id, err := uuidGenerator.GenerateString()
+	if err != nil {
+		return fmt.Errorf("generate uuid: %w", err)
+	}
+	m.ID = id
+	return nil
+}
+
+// getByUUID returns the entity whose UUID primary key is id
+func getByUUID[T any](db *gorm.DB, id string) (*T, error) {
+	if !uuidv7.IsValid(id) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidUUID, id)
+	}
+	var entity T
+	if err := db.First(&entity, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("entity with id %s not found: %w", id, err)
+		}
+		return nil, fmt.Errorf("get entity by id %s: %w", id, err)
+	}
+	return &entity, nil
+}
+
+// deleteByUUID deletes the entity whose UUID primary key is id
+func deleteByUUID[T any](db *gorm.DB, id string) error {
+	if !uuidv7.IsValid(id) {
+		return fmt.Errorf("%w: %q", ErrInvalidUUID, id)
+	}
+	if err := db.Where("id = ?", id).Delete(new(T)).Error; err != nil {
+		return fmt.Errorf("delete entity by id %s: %w", id, err)
+	}
+	return nil
+}
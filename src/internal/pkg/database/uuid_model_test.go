@@ -0,0 +1,67 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// UUIDEntity is a test model keyed by a UUIDv7
+type UUIDEntity struct {
+	BaseModel
+	Name string `gorm:"size:255"`
+}
+
+// TestBaseModel_UUIDPrimaryKey tests that inserts get a UUIDv7 key that can be queried and deleted
+func TestBaseModel_UUIDPrimaryKey(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&UUIDEntity{}))
+	repo := NewBaseRepository[UUIDEntity](db)
+	ctx := context.Background()
+
+	first := &UUIDEntity{Name: "first"}
+	require.NoError(t, repo.Insert(ctx, first))
+	second := &UUIDEntity{Name: "second"}
+	require.NoError(t, repo.Insert(ctx, second))
+
+	t.Run("assigns a version 7 uuid", func(t *testing.T) {
+		id, err := uuid.Parse(first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), id.Version())
+		assert.Less(t, first.ID, second.ID, "UUIDv7 keys sort in insert order")
+	})
+
+	t.Run("get by uuid", func(t *testing.T) {
+		found, err := repo.GetByUUID(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "first", found.Name)
+		assert.False(t, found.CreatedAt.IsZero())
+	})
+
+	t.Run("keeps a preset id", func(t *testing.T) {
+		preset := &UUIDEntity{BaseModel: BaseModel{ID: "018f3b5e-8c3a-7000-8000-000000000001"}, Name: "preset"}
+		require.NoError(t, repo.Insert(ctx, preset))
+		found, err := repo.GetByUUID(ctx, "018f3b5e-8c3a-7000-8000-000000000001")
+		require.NoError(t, err)
+		assert.Equal(t, "preset", found.Name)
+	})
+
+	t.Run("invalid uuid", func(t *testing.T) {
+		_, err := repo.GetByUUID(ctx, "not-a-uuid")
+		assert.ErrorIs(t, err, ErrInvalidUUID)
+		assert.ErrorIs(t, repo.DeleteByUUID(ctx, "42"), ErrInvalidUUID)
+	})
+
+	t.Run("delete by uuid", func(t *testing.T) {
+		require.NoError(t, repo.DeleteByUUID(ctx, second.ID))
+		_, err := repo.GetByUUID(ctx, second.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = repo.GetByUUID(ctx, first.ID)
+		assert.NoError(t, err, "only the given row is deleted")
+	})
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// VersionField is the model field used for optimistic locking by UpdateByIDWithVersion
+const VersionField = "Version"
+
+var (
+	// ErrStaleObject is returned when a versioned update finds the row changed since the expected version was read
+	ErrStaleObject = errors.New("stale object")
+	// ErrNoVersionField is returned by a versioned update on a model without an unsigned Version column
+	ErrNoVersionField = errors.New("model has no version field")
+)
+
+// versionField returns the schema field of model's Version column
+func versionField(db *gorm.DB, model interface{}) (*schema.Field, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, fmt.Errorf("parse model schema: %w", err)
+	}
+
+	f := stmt.Schema.LookUpField(VersionField)
+	if f == nil || f.DBName == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoVersionField, stmt.Schema.Name)
+	}
+	switch f.FieldType.Kind() {
+	case reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return f, nil
+	}
+	return nil, fmt.Errorf("%w: %s.%s is %s, not an unsigned integer", ErrNoVersionField, stmt.Schema.Name, VersionField, f.FieldType)
+}
+
+// updateByIDWithVersion updates the entity with the given ID only if its version is still expectedVersion,
+// and sets the stored and in-memory version to expectedVersion+1. When no row is updated it returns
+// ErrStaleObject if the row exists and a not found error wrapping gorm.ErrRecordNotFound otherwise.
+func updateByIDWithVersion[T any](db *gorm.DB, id uint, expectedVersion uint, entity *T) error {
+	field, err := versionField(db, entity)
+	if err != nil {
+		return err
+	}
+
+	if err := stampUser(db, []*T{entity}, UpdatedByField); err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(entity).Elem()
+	if err := field.Set(db.Statement.Context, value, uint64(expectedVersion)+1); err != nil {
+		return fmt.Errorf("set version: %w", err)
+	}
+
+	result := db.Model(entity).
+		Where("id = ?", id).
+		Where(db.Statement.Quote(field.DBName)+" = ?", expectedVersion).
+		Updates(entity)
+	if result.Error == nil && result.RowsAffected == 1 {
+		return nil
+	}
+
+	// Leave the caller's entity at the version it expected, so it can re-read and retry
+	if err := field.Set(db.Statement.Context, value, uint64(expectedVersion)); err != nil {
+		return fmt.Errorf("reset version: %w", err)
+	}
+	if result.Error != nil {
+		return fmt.Errorf("update entity by id %d: %w", id, result.Error)
+	}
+
+	var count int64
+	if err := db.Model(new(T)).Where("id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("check entity with id %d exists: %w", id, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("entity with id %d not found: %w", id, gorm.ErrRecordNotFound)
+	}
+	return fmt.Errorf("entity with id %d changed since version %d: %w", id, expectedVersion, ErrStaleObject)
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// VersionedEntity is a test entity with an optimistic locking version
+type VersionedEntity struct {
+	ID      uint `gorm:"primarykey"`
+	Name    string
+	Version uint `gorm:"not null;default:0"`
+}
+
+// TestBaseRepository_UpdateByIDWithVersion tests optimistic locking between two readers of the same row
+func TestBaseRepository_UpdateByIDWithVersion(t *testing.T) {
+	db := setupTestDB(t)
+	require.NoError(t, db.AutoMigrate(&VersionedEntity{}))
+	repo := NewBaseRepository[VersionedEntity](db)
+	ctx := context.Background()
+
+	entity := &VersionedEntity{Name: "original"}
+	require.NoError(t, repo.Insert(ctx, entity))
+
+	t.Run("second writer gets a stale object error", func(t *testing.T) {
+		first, err := repo.GetByID(ctx, entity.ID)
+		require.NoError(t, err)
+		second, err := repo.GetByID(ctx, entity.ID)
+		require.NoError(t, err)
+
+		first.Name = "first"
+		require.NoError(t, repo.UpdateByIDWithVersion(ctx, first.ID, first.Version, first))
+		assert.Equal(t, uint(1), first.Version)
+
+		second.Name = "second"
+		err = repo.UpdateByIDWithVersion(ctx, second.ID, second.Version, second)
+		assert.ErrorIs(t, err, ErrStaleObject)
+		assert.Equal(t, uint(0), second.Version, "the failed update leaves the entity at the version it read")
+
+		stored, err := repo.GetByID(ctx, entity.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "first", stored.Name)
+		assert.Equal(t, uint(1), stored.Version)
+	})
+
+	t.Run("retry after re-reading succeeds", func(t *testing.T) {
+		current, err := repo.GetByID(ctx, entity.ID)
+		require.NoError(t, err)
+
+		current.Name = "second"
+		require.NoError(t, repo.UpdateByIDWithVersion(ctx, current.ID, current.Version, current))
+
+		stored, err := repo.GetByID(ctx, entity.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "second", stored.Name)
+		assert.Equal(t, uint(2), stored.Version)
+	})
+
+	t.Run("missing row is not found", func(t *testing.T) {
+		err := repo.UpdateByIDWithVersion(ctx, 99999, 0, &VersionedEntity{Name: "ghost"})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.NotErrorIs(t, err, ErrStaleObject)
+	})
+
+	t.Run("model without a version field", func(t *testing.T) {
+		plain := NewBaseRepository[TestEntity](db)
+		err := plain.UpdateByIDWithVersion(ctx, 1, 0, &TestEntity{Name: "x"})
+		assert.ErrorIs(t, err, ErrNoVersionField)
+	})
+
+	t.Run("plain update leaves the version alone", func(t *testing.T) {
+		require.NoError(t, repo.UpdateByID(ctx, entity.ID, &VersionedEntity{Name: "plain"}))
+
+		stored, err := repo.GetByID(ctx, entity.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "plain", stored.Name)
+		assert.Equal(t, uint(2), stored.Version)
+	})
+}
@@ -0,0 +1,156 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+	"myapp/internal/pkg/config"
+)
+
+// encryptedPrefix marks values produced by Encryptor so plain text values can still be read
+const encryptedPrefix = "enc:v1:"
+
+// keySize is the AES-256 key length
+const keySize = 32
+
+// tenantKeyInfo is the HKDF context string; changing it changes every derived key
+const tenantKeyInfo = "myapp tenant encryption key v1"
+
+// ErrDecrypt is returned when a value cannot be decrypted, e.g. it was encrypted for another tenant
+var ErrDecrypt = errors.New("decrypt value")
+
+// Encryptor encrypts tenant-scoped values with AES-256-GCM.
+// With per-tenant keys each tenant gets a key derived from the master key, so a value
+// encrypted for one tenant cannot be decrypted with another tenant's key.
+type Encryptor struct {
+	masterKey     []byte
+	perTenantKeys bool
+}
+
+// New creates an encryptor from a 32-byte master key
+func New(masterKey []byte, perTenantKeys bool) (*Encryptor, error) {
+	if len(masterKey) != keySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", keySize, len(masterKey))
+	}
+	return &Encryptor{masterKey: masterKey, perTenantKeys: perTenantKeys}, nil
+}
+
+// NewFromConfig creates an encryptor from configuration; it returns nil when no master key is configured
+func NewFromConfig(cfg config.EncryptionConfig) (*Encryptor, error) {
+	if cfg.MasterKey == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.MasterKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode master key: %w", err)
+	}
+	return New(key, cfg.PerTenantKeys)
+}
+
+// DeriveTenantKey derives the tenant's key from the master key with HKDF-SHA256, using the tenant ID as salt
+func DeriveTenantKey(masterKey []byte, tenantID string) ([]byte, error) {
+	key := make([]byte, keySize)
+	reader := hkdf.New(sha256.New, masterKey, []byte(tenantID), []byte(tenantKeyInfo))
+	if _, err := io.ReadFull(reader, key); err != nil {
+		return nil, fmt.Errorf("derive key for tenant %s: %w", tenantID, err)
+	}
+	return key, nil
+}
+
+// TenantKey returns the key used for the tenant: a derived key, or the master key when per-tenant keys are off
+func (e *Encryptor) TenantKey(tenantID string) ([]byte, error) {
+	if !e.perTenantKeys {
+		return e.masterKey, nil
+	}
+	return DeriveTenantKey(e.masterKey, tenantID)
+}
+
+// EncryptString encrypts a value for the tenant and returns it as "enc:v1:<base64>"
+func (e *Encryptor) EncryptString(tenantID, plaintext string) (string, error) {
+	key, err := e.TenantKey(tenantID)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := Seal(key, []byte(plaintext), []byte(tenantID))
+	if err != nil {
+		return "", err
+	}
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptString decrypts a value produced by EncryptString for the same tenant.
+// Values without the encrypted prefix are returned unchanged so existing plain text keeps working.
+func (e *Encryptor) DecryptString(tenantID, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid encoding: %v", ErrDecrypt, err)
+	}
+	key, err := e.TenantKey(tenantID)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := Open(key, sealed, []byte(tenantID))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// IsEncrypted reports whether value was produced by EncryptString
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Seal encrypts plaintext with AES-256-GCM, binding it to additionalData, and returns nonce || ciphertext
+func Seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts the output of Seal; it fails with ErrDecrypt for a wrong key, wrong additionalData or tampered data
+func Open(key, sealed, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("%w: ciphertext too short", ErrDecrypt)
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDecrypt, err)
+	}
+	return plaintext, nil
+}
+
+// newGCM creates an AES-GCM cipher for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,112 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+// Checker probes a single dependency for the readiness report
+type Checker interface {
+	// Name identifies the dependency, e.g. master_database; it is matched against health.critical_dependencies
+	Name() string
+	// Check returns an error when the dependency is unavailable
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to the Checker interface
+type CheckerFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// NewCheckerFunc creates a named checker from a function
+func NewCheckerFunc(name string, check func(ctx context.Context) error) CheckerFunc {
+	return CheckerFunc{name: name, check: check}
+}
+
+// Name returns the dependency name
+func (c CheckerFunc) Name() string {
+	return c.name
+}
+
+// Check runs the check function
+func (c CheckerFunc) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+// MasterDatabaseChecker checks that the master database accepts connections
+type MasterDatabaseChecker struct {
+	dbManager *database.DatabaseManager
+}
+
+// Name returns the dependency name
+func (c MasterDatabaseChecker) Name() string {
+	return config.HealthDependencyMasterDatabase
+}
+
+// Check pings the master database
+func (c MasterDatabaseChecker) Check(ctx context.Context) error {
+	sqlDB, err := c.dbManager.MasterDB.DB()
+	if err != nil {
+		return fmt.Errorf("get master database: %w", err)
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping master database: %w", err)
+	}
+	return nil
+}
+
+// TenantDatabasesChecker checks that every active tenant database is reachable
+type TenantDatabasesChecker struct {
+	connManager *database.TenantConnectionManager
+}
+
+// Name returns the dependency name
+func (c TenantDatabasesChecker) Name() string {
+	return config.HealthDependencyTenantDatabases
+}
+
+// Check pings all active tenant databases through the tenant connection manager
+func (c TenantDatabasesChecker) Check(ctx context.Context) error {
+	results, err := c.connManager.PingAllTenants(ctx)
+	if err != nil {
+		return fmt.Errorf("list tenants: %w", err)
+	}
+
+	down := 0
+	for _, pingErr := range results {
+		if pingErr != nil {
+			down++
+		}
+	}
+	if down > 0 {
+		return fmt.Errorf("%d of %d tenant databases unreachable", down, len(results))
+	}
+	return nil
+}
+
+// DiskChecker checks that a filesystem has at least a minimum of free space
+type DiskChecker struct {
+	path         string
+	minFreeBytes uint64
+}
+
+// Name returns the dependency name
+func (c DiskChecker) Name() string {
+	return config.HealthDependencyDisk
+}
+
+// Check compares the free space on the filesystem holding path with the minimum
+func (c DiskChecker) Check(ctx context.Context) error {
+	free, err := freeDiskBytes(c.path)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", c.path, err)
+	}
+	if free < c.minFreeBytes {
+		return fmt.Errorf("%d MB free on %s, need %d MB", free>>20, c.path, c.minFreeBytes>>20)
+	}
+	return nil
+}
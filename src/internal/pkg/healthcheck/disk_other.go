@@ -0,0 +1,10 @@
+//go:build !unix
+
+package healthcheck
+
+import "errors"
+
+// freeDiskBytes is not implemented on this platform; leave health.disk_path empty
+func freeDiskBytes(path string) (uint64, error) {
+	return 0, errors.New("disk check is not supported on this platform")
+}
@@ -0,0 +1,10 @@
+package healthcheck
+
+import (
+	"go.uber.org/fx"
+)
+
+// Module exports the health check service
+var Module = fx.Options(
+	fx.Provide(NewService),
+)
@@ -0,0 +1,174 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+// Overall readiness states
+const (
+	StatusReady       = "ready"       // every dependency is up
+	StatusDegraded    = "degraded"    // only non-critical dependencies are down
+	StatusUnavailable = "unavailable" // a critical dependency is down
+)
+
+// Check result states
+const (
+	CheckUp   = "up"
+	CheckDown = "down"
+)
+
+// HealthReport is the outcome of running every dependency check
+type HealthReport struct {
+	Status   string                 `json:"status"`
+	Service  string                 `json:"service"`
+	Uptime   string                 `json:"uptime"`
+	Checks   map[string]CheckResult `json:"checks"`
+	Warnings []string               `json:"warnings"`
+	Build    BuildInfo              `json:"build"`
+	Time     time.Time              `json:"time"`
+}
+
+// HTTPStatus returns 503 when a critical dependency is down and 200 otherwise
+func (r *HealthReport) HTTPStatus() int {
+	if r.Status == StatusUnavailable {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// CheckResult is the outcome of a single dependency check
+type CheckResult struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// BuildInfo identifies the running binary
+type BuildInfo struct {
+	Version   string `json:"version,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// Service runs the dependency checks behind the readiness endpoints
+type Service struct {
+	config    *config.Config
+	logger    *zap.Logger
+	checkers  []Checker
+	startTime time.Time
+	build     BuildInfo
+}
+
+// NewService creates a health service that checks the master database, the tenant databases
+// and, when health.disk_path is set, free disk space
+func NewService(cfg *config.Config, logger *zap.Logger, dbManager *database.DatabaseManager) *Service {
+	checkers := []Checker{MasterDatabaseChecker{dbManager: dbManager}}
+	if dbManager.TenantConnManager != nil {
+		checkers = append(checkers, TenantDatabasesChecker{connManager: dbManager.TenantConnManager})
+	}
+	if cfg.Health.DiskPath != "" {
+		checkers = append(checkers, DiskChecker{
+			path:         cfg.Health.DiskPath,
+			minFreeBytes: uint64(cfg.Health.MinFreeDiskMB) << 20,
+		})
+	}
+	return NewServiceWithCheckers(cfg, logger, checkers...)
+}
+
+// NewServiceWithCheckers creates a health service that runs the given checkers
+func NewServiceWithCheckers(cfg *config.Config, logger *zap.Logger, checkers ...Checker) *Service {
+	return &Service{
+		config:    cfg,
+		logger:    logger,
+		checkers:  checkers,
+		startTime: time.Now(),
+		build:     readBuildInfo(),
+	}
+}
+
+// Check runs every checker concurrently and aggregates the results.
+// A failing critical dependency (health.critical_dependencies) makes the report unavailable;
+// any other failing dependency only degrades it and is listed in the warnings.
+func (s *Service) Check(ctx context.Context) HealthReport {
+	results := make([]CheckResult, len(s.checkers))
+	errs := make([]error, len(s.checkers))
+	
+	var wg sync.WaitGroup
+	for i, checker := range s.checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			start := time.Now()
+			errs[i] = checker.Check(ctx)
+			results[i] = CheckResult{
+				Status:    CheckUp,
+				Critical:  s.config.Health.IsCritical(checker.Name()),
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+		}(i, checker)
+	}
+	wg.Wait()
+	
+	report := HealthReport{
+		Status:   StatusReady,
+		Service:  "myapp",
+		Uptime:   time.Since(s.startTime).String(),
+		Checks:   make(map[string]CheckResult, len(s.checkers)),
+		Warnings: []string{},
+		Build:    s.build,
+		Time:     time.Now().UTC(),
+	}
+	for i, checker := range s.checkers {
+		name := checker.Name()
+		result := results[i]
+		if err := errs[i]; err != nil {
+			result.Status = CheckDown
+			result.Error = err.Error()
+			if result.Critical {
+				report.Status = StatusUnavailable
+				s.logger.Error("Critical dependency unavailable",
+					zap.String("dependency", name),
+					zap.Float64("latency_ms", result.LatencyMS),
+					zap.Error(err))
+			} else {
+				if report.Status == StatusReady {
+					report.Status = StatusDegraded
+				}
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %s", name, err.Error()))
+				s.logger.Warn("Non-critical dependency unavailable",
+					zap.String("dependency", name),
+					zap.Float64("latency_ms", result.LatencyMS),
+					zap.Error(err))
+			}
+		}
+		report.Checks[name] = result
+	}
+	return report
+}
+
+// readBuildInfo reads the module version and VCS revision embedded by the Go toolchain
+func readBuildInfo() BuildInfo {
+	build := BuildInfo{GoVersion: runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+	build.Version = info.Main.Version
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			build.Revision = setting.Value
+		}
+	}
+	return build
+}
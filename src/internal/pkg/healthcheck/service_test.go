@@ -0,0 +1,106 @@
+package healthcheck
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+// setupMasterOnlyService creates a health service checking an in-memory master database
+func setupMasterOnlyService(t *testing.T, extra ...Checker) (*Service, *gorm.DB) {
+	masterDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	cfg := &config.Config{}
+	require.NoError(t, cfg.Health.Validate())
+
+	checkers := append([]Checker{MasterDatabaseChecker{dbManager: &database.DatabaseManager{MasterDB: masterDB}}}, extra...)
+	return NewServiceWithCheckers(cfg, zaptest.NewLogger(t), checkers...), masterDB
+}
+
+func TestService_Check(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("working database is ready", func(t *testing.T) {
+		svc, _ := setupMasterOnlyService(t)
+
+		report := svc.Check(ctx)
+		assert.Equal(t, StatusReady, report.Status)
+		assert.Equal(t, http.StatusOK, report.HTTPStatus())
+		assert.Empty(t, report.Warnings)
+
+		master := report.Checks[config.HealthDependencyMasterDatabase]
+		assert.Equal(t, CheckUp, master.Status)
+		assert.True(t, master.Critical)
+		assert.GreaterOrEqual(t, master.LatencyMS, 0.0)
+		assert.Empty(t, master.Error)
+		assert.NotEmpty(t, report.Build.GoVersion)
+	})
+
+	t.Run("failing database is unavailable", func(t *testing.T) {
+		svc, masterDB := setupMasterOnlyService(t)
+		sqlDB, err := masterDB.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+
+		report := svc.Check(ctx)
+		assert.Equal(t, StatusUnavailable, report.Status)
+		assert.Equal(t, http.StatusServiceUnavailable, report.HTTPStatus())
+		master := report.Checks[config.HealthDependencyMasterDatabase]
+		assert.Equal(t, CheckDown, master.Status)
+		assert.Contains(t, master.Error, "ping master database")
+	})
+
+	t.Run("failing non-critical check degrades", func(t *testing.T) {
+		cache := NewCheckerFunc("cache", func(ctx context.Context) error {
+			return errors.New("connection refused")
+		})
+		svc, _ := setupMasterOnlyService(t, cache)
+
+		report := svc.Check(ctx)
+		assert.Equal(t, StatusDegraded, report.Status)
+		assert.Equal(t, http.StatusOK, report.HTTPStatus())
+		assert.Equal(t, []string{"cache: connection refused"}, report.Warnings)
+		assert.False(t, report.Checks["cache"].Critical)
+	})
+
+	t.Run("checks run concurrently and report their own latency", func(t *testing.T) {
+		slow := func(name string) Checker {
+			return NewCheckerFunc(name, func(ctx context.Context) error {
+				time.Sleep(100 * time.Millisecond)
+				return nil
+			})
+		}
+		svc, _ := setupMasterOnlyService(t, slow("slow_a"), slow("slow_b"))
+
+		start := time.Now()
+		report := svc.Check(ctx)
+		assert.Less(t, time.Since(start), 190*time.Millisecond)
+		assert.GreaterOrEqual(t, report.Checks["slow_a"].LatencyMS, 100.0)
+		assert.GreaterOrEqual(t, report.Checks["slow_b"].LatencyMS, 100.0)
+	})
+}
+
+func TestDiskChecker(t *testing.T) {
+	dir := os.TempDir()
+
+	assert.NoError(t, DiskChecker{path: dir, minFreeBytes: 1}.Check(context.Background()))
+
+	err := DiskChecker{path: dir, minFreeBytes: 1 << 62}.Check(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MB free on")
+
+	err = DiskChecker{path: "/nonexistent/path", minFreeBytes: 1}.Check(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// contextKey is a custom type for context keys to avoid collisions
+type contextKey string
+
+const (
+	// loggerContextKey holds the base logger seeded by WithContext
+	loggerContextKey contextKey = "logger"
+	// fieldsContextKey holds the correlation fields added to the derived logger
+	fieldsContextKey contextKey = "logFields"
+)
+
+// contextFields are the correlation IDs FromContext adds to every entry
+type contextFields struct {
+	RequestID string
+	TenantID  string
+	UserID    uint // 0 when the request is not authenticated
+}
+
+// WithContext seeds ctx with the base logger that FromContext decorates
+func WithContext(ctx context.Context, base *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, base)
+}
+
+// WithRequestID records the request ID logged as request_id
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	fields := fieldsFromContext(ctx)
+	fields.RequestID = requestID
+	return context.WithValue(ctx, fieldsContextKey, fields)
+}
+
+// WithTenantID records the tenant ID logged as tenant_id
+func WithTenantID(ctx context.Context, tenantID string) context.Context {
+	fields := fieldsFromContext(ctx)
+	fields.TenantID = tenantID
+	return context.WithValue(ctx, fieldsContextKey, fields)
+}
+
+// WithUserID records the authenticated user ID logged as user_id
+func WithUserID(ctx context.Context, userID uint) context.Context {
+	fields := fieldsFromContext(ctx)
+	fields.UserID = userID
+	return context.WithValue(ctx, fieldsContextKey, fields)
+}
+
+// FromContext returns the logger seeded by WithContext, or a no-op logger, with request_id,
+// tenant_id and user_id fields for the IDs present in ctx
+func FromContext(ctx context.Context) *zap.Logger {
+	return FromContextOr(ctx, zap.NewNop())
+}
+
+// FromContextOr is like FromContext but decorates fallback when ctx was not seeded with a logger
+func FromContextOr(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	base, ok := ctx.Value(loggerContextKey).(*zap.Logger)
+	if !ok || base == nil {
+		base = fallback
+	}
+
+	fields := fieldsFromContext(ctx)
+	var zapFields []zap.Field
+	if fields.RequestID != "" {
+		zapFields = append(zapFields, zap.String("request_id", fields.RequestID))
+	}
+	if fields.TenantID != "" {
+		zapFields = append(zapFields, zap.String("tenant_id", fields.TenantID))
+	}
+	if fields.UserID != 0 {
+		zapFields = append(zapFields, zap.Uint("user_id", fields.UserID))
+	}
+	if len(zapFields) == 0 {
+		return base
+	}
+	return base.With(zapFields...)
+}
+
+// fieldsFromContext returns a copy of the correlation fields in ctx
+func fieldsFromContext(ctx context.Context) contextFields {
+	fields, _ := ctx.Value(fieldsContextKey).(contextFields)
+	return fields
+}
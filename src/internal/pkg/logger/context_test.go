@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestFromContext tests that the derived logger carries the IDs present in the context
+func TestFromContext(t *testing.T) {
+	tests := []struct {
+		name       string
+		decorate   func(context.Context) context.Context
+		wantFields map[string]interface{}
+	}{
+		{
+			name:       "no IDs",
+			decorate:   func(ctx context.Context) context.Context { return ctx },
+			wantFields: map[string]interface{}{},
+		},
+		{
+			name: "request ID only",
+			decorate: func(ctx context.Context) context.Context {
+				return WithRequestID(ctx, "req-1")
+			},
+			wantFields: map[string]interface{}{"request_id": "req-1"},
+		},
+		{
+			name: "request, tenant and user",
+			decorate: func(ctx context.Context) context.Context {
+				ctx = WithRequestID(ctx, "req-1")
+				ctx = WithTenantID(ctx, "tenant-a")
+				return WithUserID(ctx, 42)
+			},
+			wantFields: map[string]interface{}{"request_id": "req-1", "tenant_id": "tenant-a", "user_id": uint64(42)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, recorded := observer.New(zapcore.InfoLevel)
+			ctx := tt.decorate(WithContext(context.Background(), zap.New(core)))
+
+			FromContext(ctx).Info("handled")
+
+			entries := recorded.All()
+			assert.Len(t, entries, 1)
+			assert.Equal(t, tt.wantFields, entries[0].ContextMap())
+		})
+	}
+}
+
+// TestFromContext_DoesNotLeakIntoParent tests that adding an ID to a child context leaves the parent unchanged
+func TestFromContext_DoesNotLeakIntoParent(t *testing.T) {
+	core, recorded := observer.New(zapcore.InfoLevel)
+	parent := WithRequestID(WithContext(context.Background(), zap.New(core)), "req-1")
+	_ = WithUserID(parent, 7)
+
+	FromContext(parent).Info("parent")
+	assert.Equal(t, map[string]interface{}{"request_id": "req-1"}, recorded.All()[0].ContextMap())
+}
+
+// TestFromContextOr tests the fallback logger for contexts that were not seeded
+func TestFromContextOr(t *testing.T) {
+	core, recorded := observer.New(zapcore.InfoLevel)
+	ctx := WithTenantID(context.Background(), "tenant-a")
+
+	FromContextOr(ctx, zap.New(core)).Info("background job")
+	FromContext(ctx).Info("dropped by the no-op logger")
+
+	entries := recorded.All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "tenant-a", entries[0].ContextMap()["tenant_id"])
+}
@@ -3,14 +3,34 @@ package logger
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 	"myapp/internal/pkg/config"
 )
 
 // NewLogger creates a new zap logger based on configuration
 func NewLogger(cfg *config.Config) (*zap.Logger, error) {
+	level, err := NewAtomicLevel(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return NewLoggerWithLevel(cfg, level)
+}
+
+// NewAtomicLevel creates the log level from configuration; changing it later changes the level of loggers built with it
+func NewAtomicLevel(cfg *config.Config) (zap.AtomicLevel, error) {
+	level, err := parseLogLevel(cfg.Logger.Level)
+	if err != nil {
+		return zap.AtomicLevel{}, fmt.Errorf("parse log level: %w", err)
+	}
+	return zap.NewAtomicLevelAt(level), nil
+}
+
+// NewLoggerWithLevel creates a new zap logger based on configuration that logs at level
+func NewLoggerWithLevel(cfg *config.Config, level zap.AtomicLevel) (*zap.Logger, error) {
 	var zapConfig zap.Config
 	
 	// Set config based on format
@@ -22,11 +42,12 @@ func NewLogger(cfg *config.Config) (*zap.Logger, error) {
 	}
 	
 	// Set log level
-	level, err := parseLogLevel(cfg.Logger.Level)
-	if err != nil {
-		return nil, fmt.Errorf("parse log level: %w", err)
+	zapConfig.Level = level
+	
+	// Configured sampling replaces zap's built-in production sampling, which also samples errors
+	if cfg.Logger.Sampling.Enabled() {
+		zapConfig.Sampling = nil
 	}
-	zapConfig.Level = zap.NewAtomicLevelAt(level)
 	
 	// Build logger
 	logger, err := zapConfig.Build(
@@ -37,9 +58,91 @@ func NewLogger(cfg *config.Config) (*zap.Logger, error) {
 		return nil, fmt.Errorf("build logger: %w", err)
 	}
 	
+	// Tee to a rotating file when an output path is configured
+	if cfg.Logger.OutputPath != "" {
+		fileCore := newFileCore(cfg.Logger, level)
+		logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return zapcore.NewTee(core, fileCore)
+		}))
+	}
+	
+	if cfg.Logger.Sampling.Enabled() {
+		logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return newSampledCore(core, cfg.Logger.Sampling)
+		}))
+	}
+	
 	return logger, nil
 }
 
+// samplingTick is the interval over which identical entries are counted for sampling
+const samplingTick = time.Second
+
+// newSampledCore samples entries below error level; errors and above always reach core
+func newSampledCore(core zapcore.Core, sampling config.LogSamplingConfig) zapcore.Core {
+	belowError := func(l zapcore.Level) bool { return l < zapcore.ErrorLevel }
+	errorAndAbove := func(l zapcore.Level) bool { return l >= zapcore.ErrorLevel }
+	
+	sampled := zapcore.NewSamplerWithOptions(&levelFilterCore{Core: core, enabled: belowError}, samplingTick, sampling.Initial, sampling.Thereafter)
+	return zapcore.NewTee(sampled, &levelFilterCore{Core: core, enabled: errorAndAbove})
+}
+
+// levelFilterCore passes only entries whose level enabled accepts to the wrapped core
+type levelFilterCore struct {
+	zapcore.Core
+	enabled func(zapcore.Level) bool
+}
+
+// Enabled reports whether the level passes the filter and is enabled on the wrapped core
+func (c *levelFilterCore) Enabled(level zapcore.Level) bool {
+	return c.enabled(level) && c.Core.Enabled(level)
+}
+
+// With adds fields to the wrapped core and keeps the filter
+func (c *levelFilterCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelFilterCore{Core: c.Core.With(fields), enabled: c.enabled}
+}
+
+// Check adds the wrapped core to ce when the entry passes the filter
+func (c *levelFilterCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.enabled(ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+// newFileCore creates a core writing to logger.output_path, rotated by lumberjack.
+// Console format is written without colors so the file stays plain text.
+func newFileCore(cfg config.LoggerConfig, level zap.AtomicLevel) zapcore.Core {
+	writer := &lumberjack.Logger{
+		Filename:   cfg.OutputPath,
+		MaxSize:    cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAge:     cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+	}
+	
+	var encoder zapcore.Encoder
+	if strings.ToLower(cfg.Format) == "json" {
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	} else {
+		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	}
+	return zapcore.NewCore(encoder, zapcore.AddSync(writer), level)
+}
+
+// RegisterLevelReload applies logger.level from every reloaded configuration
+func RegisterLevelReload(manager *config.ConfigManager, level zap.AtomicLevel, logger *zap.Logger) {
+	manager.Subscribe(func(cfg *config.Config) {
+		newLevel, err := parseLogLevel(cfg.Logger.Level)
+		if err != nil || newLevel == level.Level() {
+			return
+		}
+		level.SetLevel(newLevel)
+		logger.Info("Log level changed", zap.Stringer("level", newLevel))
+	})
+}
+
 // parseLogLevel converts string log level to zapcore.Level
 func parseLogLevel(level string) (zapcore.Level, error) {
 	switch strings.ToLower(level) {
@@ -1,11 +1,15 @@
 package logger
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"myapp/internal/pkg/config"
 )
 
@@ -286,3 +290,68 @@ func TestNewLogger_CaseInsensitivity(t *testing.T) {
 		})
 	}
 }
+
+// TestNewLogger_FileOutput tests that logs are also written to the configured file
+func TestNewLogger_FileOutput(t *testing.T) {
+	for _, format := range []string{"json", "console"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "logs", "app.log")
+			cfg := &config.Config{
+				Logger: config.LoggerConfig{
+					Level:      "info",
+					Format:     format,
+					OutputPath: path,
+					MaxSizeMB:  1,
+				},
+			}
+
+			logger, err := NewLogger(cfg)
+			require.NoError(t, err)
+			logger.Info("written to file", zap.String("format", format))
+			logger.Debug("below the configured level")
+			_ = logger.Sync()
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err, "log file should be created")
+			assert.Contains(t, string(content), "written to file")
+			assert.NotContains(t, string(content), "below the configured level")
+			assert.NotContains(t, string(content), "\x1b[", "file output has no color codes")
+		})
+	}
+}
+
+// TestNewSampledCore tests that repeated info entries are sampled while errors always pass
+func TestNewSampledCore(t *testing.T) {
+	core, recorded := observer.New(zapcore.DebugLevel)
+	logger := zap.New(newSampledCore(core, config.LogSamplingConfig{Initial: 2, Thereafter: 10}))
+
+	const produced = 50
+	for i := 0; i < produced; i++ {
+		logger.Info("repeated message")
+	}
+	for i := 0; i < produced; i++ {
+		logger.Error("repeated failure")
+	}
+
+	infos := recorded.FilterMessage("repeated message").Len()
+	assert.Less(t, infos, produced, "info entries should be sampled")
+	assert.Equal(t, 2+(produced-2)/10, infos)
+	assert.Equal(t, produced, recorded.FilterMessage("repeated failure").Len(), "errors bypass sampling")
+}
+
+// TestNewLogger_Sampling tests that NewLogger applies configured sampling to info but not error entries
+func TestNewLogger_Sampling(t *testing.T) {
+	cfg := &config.Config{
+		Logger: config.LoggerConfig{
+			Level:    "info",
+			Format:   "json",
+			Sampling: config.LogSamplingConfig{Initial: 1, Thereafter: 0},
+		},
+	}
+	logger, err := NewLogger(cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, logger.Check(zapcore.InfoLevel, "first"))
+	assert.Nil(t, logger.Check(zapcore.InfoLevel, "first"), "the second identical entry is dropped")
+	assert.NotNil(t, logger.Check(zapcore.ErrorLevel, "failure"))
+	assert.NotNil(t, logger.Check(zapcore.ErrorLevel, "failure"))
+}
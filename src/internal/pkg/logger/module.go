@@ -6,5 +6,7 @@ import (
 
 // Module exports logger dependency
 var Module = fx.Options(
-	fx.Provide(NewLogger),
+	fx.Provide(NewAtomicLevel),
+	fx.Provide(NewLoggerWithLevel),
+	fx.Invoke(RegisterLevelReload),
 )
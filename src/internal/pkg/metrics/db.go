@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"myapp/internal/pkg/database"
+)
+
+// dbLabels label connection pool gauges by database (master, master_replica_N, tenant_legacy or tenant)
+// and by tenant ID for tenant connections
+var dbLabels = []string{"database", "tenant"}
+
+// DBStatsCollector exports the connection pool state of every open database connection. Stats are read on
+// each scrape, so tenant connections evicted from the cache simply stop being reported. Wait count and
+// duration are gauges rather than counters because a reopened tenant connection starts again from zero.
+type DBStatsCollector struct {
+	dbManager *database.DatabaseManager
+
+	openConnections *prometheus.Desc
+	inUse           *prometheus.Desc
+	idle            *prometheus.Desc
+	waitCount       *prometheus.Desc
+	waitDuration    *prometheus.Desc
+}
+
+// NewDBStatsCollector creates a collector for the connections held by dbManager
+func NewDBStatsCollector(dbManager *database.DatabaseManager) *DBStatsCollector {
+	return &DBStatsCollector{
+		dbManager:       dbManager,
+		openConnections: prometheus.NewDesc("db_open_connections", "Number of established connections, in use or idle.", dbLabels, nil),
+		inUse:           prometheus.NewDesc("db_in_use", "Number of connections currently in use.", dbLabels, nil),
+		idle:            prometheus.NewDesc("db_idle", "Number of idle connections.", dbLabels, nil),
+		waitCount:       prometheus.NewDesc("db_wait_count", "Number of times a query waited for a free connection.", dbLabels, nil),
+		waitDuration:    prometheus.NewDesc("db_wait_duration", "Total time in seconds spent waiting for a free connection.", dbLabels, nil),
+	}
+}
+
+// RegisterDBStats registers a DBStatsCollector for dbManager with reg. A collector that is already
+// registered, e.g. by an earlier server in the same process, is kept.
+func RegisterDBStats(reg prometheus.Registerer, dbManager *database.DatabaseManager) error {
+	_, err := register[prometheus.Collector](reg, NewDBStatsCollector(dbManager))
+	return err
+}
+
+// Describe sends the descriptors of the connection pool gauges
+func (c *DBStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openConnections
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+// Collect reads the current pool statistics of every connection
+func (c *DBStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.dbManager == nil {
+		return
+	}
+	for _, pool := range c.dbManager.PoolStats() {
+		labels := []string{pool.Database, pool.TenantID}
+		ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(pool.Stats.OpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(pool.Stats.InUse), labels...)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(pool.Stats.Idle), labels...)
+		ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.GaugeValue, float64(pool.Stats.WaitCount), labels...)
+		ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.GaugeValue, pool.Stats.WaitDuration.Seconds(), labels...)
+	}
+}
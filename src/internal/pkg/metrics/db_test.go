@@ -0,0 +1,62 @@
+// +build cgo
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/testsupport"
+)
+
+// scrape returns the metrics page served for reg
+func scrape(t *testing.T, reg *prometheus.Registry) string {
+	e := echo.New()
+	e.GET(Path, Handler(reg))
+	rec := request(e, Path, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestDBStatsCollector(t *testing.T) {
+	tenant := testsupport.NewTestTenant(t)
+	reg := prometheus.NewRegistry()
+	require.NoError(t, RegisterDBStats(reg, tenant.DBManager))
+
+	t.Run("master and legacy tenant databases", func(t *testing.T) {
+		sqlDB, err := tenant.MasterDB.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Ping())
+
+		body := scrape(t, reg)
+		for _, name := range []string{"db_open_connections", "db_in_use", "db_idle", "db_wait_count", "db_wait_duration"} {
+			assert.Contains(t, body, name+`{database="master",tenant=""}`)
+			assert.Contains(t, body, name+`{database="tenant_legacy",tenant=""}`)
+		}
+		assert.Contains(t, body, `db_open_connections{database="master",tenant=""} 1`)
+		assert.Contains(t, body, `db_in_use{database="master",tenant=""} 0`)
+	})
+
+	t.Run("cached tenant connections", func(t *testing.T) {
+		_, err := tenant.ConnManager.GetTenantDB(context.Background(), tenant.ID)
+		require.NoError(t, err)
+
+		body := scrape(t, reg)
+		assert.Contains(t, body, `db_open_connections{database="tenant",tenant="`+tenant.ID+`"}`)
+
+		// Evicted connections are no longer reported
+		require.NoError(t, tenant.ConnManager.CloseTenant(tenant.ID))
+		body = scrape(t, reg)
+		assert.NotContains(t, body, `tenant="`+tenant.ID+`"`)
+		assert.Contains(t, body, `db_open_connections{database="master",tenant=""}`)
+	})
+
+	t.Run("registering twice keeps the first collector", func(t *testing.T) {
+		assert.NoError(t, RegisterDBStats(reg, tenant.DBManager))
+	})
+}
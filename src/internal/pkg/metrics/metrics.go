@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	custommw "myapp/internal/pkg/middleware"
+)
+
+// Path is where the scrape endpoint is served
+const Path = "/metrics"
+
+// unmatchedRoute labels requests that matched no route, so arbitrary URLs cannot blow up label cardinality
+const unmatchedRoute = "unmatched"
+
+// unknownTenantType labels requests that failed before the request context was determined
+const unknownTenantType = "unknown"
+
+// HTTPMetrics holds the collectors recorded for every HTTP request
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// NewHTTPMetrics creates the HTTP request collectors and registers them with reg.
+// Collectors that are already registered, e.g. by an earlier server in the same process, are reused.
+func NewHTTPMetrics(reg prometheus.Registerer) (*HTTPMetrics, error) {
+	requests, err := register(reg, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Number of HTTP requests handled.",
+	}, []string{"method", "route", "status", "tenant_type"}))
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := register(reg, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time spent handling HTTP requests.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status", "tenant_type"}))
+	if err != nil {
+		return nil, err
+	}
+
+	inFlight, err := register(reg, prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests currently being handled.",
+	}, []string{"method", "route"}))
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTTPMetrics{requests: requests, duration: duration, inFlight: inFlight}, nil
+}
+
+// register registers c, returning the existing collector when an identical one is already registered
+func register[T prometheus.Collector](reg prometheus.Registerer, c T) (T, error) {
+	if err := reg.Register(c); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			if existing, ok := registered.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// Middleware records request count, duration and in-flight requests. Requests are labeled by
+// route template rather than URL, and by tenant type (master or tenant) from the request context.
+// It must run outside the recover middleware so panics are counted as 500s.
+func (m *HTTPMetrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Path() == Path {
+				return next(c)
+			}
+
+			method := c.Request().Method
+			route := routeLabel(c)
+			inFlight := m.inFlight.WithLabelValues(method, route)
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			start := time.Now()
+			err := next(c)
+			elapsed := time.Since(start)
+
+			labels := []string{method, route, strconv.Itoa(status(c, err)), tenantType(c)}
+			m.requests.WithLabelValues(labels...).Inc()
+			m.duration.WithLabelValues(labels...).Observe(elapsed.Seconds())
+			return err
+		}
+	}
+}
+
+// Handler serves the metrics collected by gatherer in the Prometheus text format
+func Handler(gatherer prometheus.Gatherer) echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+}
+
+// routeLabel returns the matched route template, e.g. /api/products/:id
+func routeLabel(c echo.Context) string {
+	if route := c.Path(); route != "" {
+		return route
+	}
+	return unmatchedRoute
+}
+
+// status returns the response status, or the status the error handler will send for err
+func status(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code
+	}
+	return http.StatusInternalServerError
+}
+
+// tenantType returns "master" or "tenant" as set by the context middleware
+func tenantType(c echo.Context) string {
+	if requestContext, ok := custommw.GetRequestContext(c); ok {
+		return requestContext.Type
+	}
+	return unknownTenantType
+}
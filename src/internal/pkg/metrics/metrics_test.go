@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	custommw "myapp/internal/pkg/middleware"
+)
+
+// setupMetricsServer serves a few routes behind the metrics middleware with a private registry
+func setupMetricsServer(t *testing.T) *echo.Echo {
+	reg := prometheus.NewRegistry()
+	httpMetrics, err := NewHTTPMetrics(reg)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(httpMetrics.Middleware())
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			requestType := "master"
+			if c.Request().Header.Get("X-Tenant-ID") != "" {
+				requestType = "tenant"
+			}
+			c.Set("requestContext", &custommw.RequestContext{Type: requestType})
+			return next(c)
+		}
+	})
+	e.GET("/products/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/missing", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound, "not found")
+	})
+	e.GET(Path, Handler(reg))
+	return e
+}
+
+// request sends a GET request and returns the recorder
+func request(e *echo.Echo, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHTTPMetrics_Middleware(t *testing.T) {
+	e := setupMetricsServer(t)
+
+	request(e, "/products/1", nil)
+	request(e, "/products/2", nil)
+	request(e, "/products/3", map[string]string{"X-Tenant-ID": "tenant-a"})
+	request(e, "/missing", nil)
+	request(e, "/no-such-route", nil)
+
+	rec := request(e, Path, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/products/:id",status="200",tenant_type="master"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/products/:id",status="200",tenant_type="tenant"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/missing",status="404",tenant_type="master"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/products/:id",status="200",tenant_type="master"} 2`)
+	assert.Contains(t, body, `http_requests_in_flight{method="GET",route="/products/:id"} 0`)
+	assert.NotContains(t, body, `/no-such-route`, "unmatched URLs must not become labels")
+	assert.NotContains(t, body, `route="/metrics"`, "scrapes are not recorded")
+}
+
+func TestNewHTTPMetrics_ReusesRegisteredCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	first, err := NewHTTPMetrics(reg)
+	require.NoError(t, err)
+	second, err := NewHTTPMetrics(reg)
+	require.NoError(t, err)
+
+	assert.Same(t, first.requests, second.requests)
+	assert.Same(t, first.duration, second.duration)
+	assert.Same(t, first.inFlight, second.inFlight)
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/database"
+)
+
+// auditWriteTimeout bounds a single audit log insert
+const auditWriteTimeout = 5 * time.Second
+
+// AuditLog records a mutating request for compliance: who made it, what it did and how it ended
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey"`
+	UserID    uint      `gorm:"index"` // 0 when the request was not authenticated
+	Email     string
+	TenantID  string    `gorm:"index"` // Empty for master requests
+	Method    string    `gorm:"not null"`
+	Path      string    `gorm:"not null"`
+	Status    int       `gorm:"not null"`
+	SourceIP  string
+	RequestID string
+	CreatedAt time.Time `gorm:"index;not null"`
+}
+
+// TableName specifies the table name for AuditLog model
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditRepository stores audit logs in the master database
+type AuditRepository struct {
+	*database.MasterRepo[AuditLog]
+}
+
+// NewAuditRepository creates a new audit repository using master database
+func NewAuditRepository(dbManager *database.DatabaseManager) *AuditRepository {
+	return &AuditRepository{
+		MasterRepo: database.NewMasterRepo[AuditLog](dbManager),
+	}
+}
+
+// SaveAuditLog persists an audit log entry
+func (r *AuditRepository) SaveAuditLog(ctx context.Context, entry *AuditLog) error {
+	if err := r.Insert(ctx, entry); err != nil {
+		return fmt.Errorf("save audit log: %w", err)
+	}
+	return nil
+}
+
+// AuditMiddleware records every mutating request (POST, PUT, PATCH, DELETE) in the audit_logs table.
+// The entry is written in the background after the handler returns, so a failed write is logged
+// but never delays or changes the response.
+func AuditMiddleware(logger *zap.Logger, auditRepo *AuditRepository) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !isMutating(c.Request().Method) {
+				return next(c)
+			}
+
+			err := next(c)
+
+			entry := newAuditLog(c, err)
+			ctx := context.WithoutCancel(c.Request().Context())
+			go func() {
+				writeCtx, cancel := context.WithTimeout(ctx, auditWriteTimeout)
+				defer cancel()
+
+				if saveErr := auditRepo.SaveAuditLog(writeCtx, entry); saveErr != nil {
+					logger.Error("Failed to write audit log",
+						zap.Error(saveErr),
+						zap.Uint("user_id", entry.UserID),
+						zap.String("method", entry.Method),
+						zap.String("path", entry.Path),
+						zap.Int("status", entry.Status))
+				}
+			}()
+
+			return err
+		}
+	}
+}
+
+// isMutating reports whether method changes server state and must be audited
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// newAuditLog builds the audit entry for a finished request
+func newAuditLog(c echo.Context, err error) *AuditLog {
+	entry := &AuditLog{
+		Method:    c.Request().Method,
+		Path:      c.Request().URL.Path,
+		Status:    auditStatus(c, err),
+		SourceIP:  c.RealIP(),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+		CreatedAt: time.Now(),
+	}
+	if user, userErr := auth.GetUserFromContext(c); userErr == nil {
+		entry.UserID = user.UserID
+		entry.Email = user.Email
+	}
+	if reqCtx, ok := GetRequestContext(c); ok {
+		entry.TenantID = reqCtx.TenantID
+	}
+	return entry
+}
+
+// auditStatus returns the status code the client receives, including one the error handler has yet to write
+func auditStatus(c echo.Context, err error) int {
+	if err == nil || c.Response().Committed {
+		return c.Response().Status
+	}
+	if he, ok := err.(*echo.HTTPError); ok {
+		return he.Code
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,119 @@
+// +build cgo
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/database"
+	"myapp/internal/testsupport"
+)
+
+// newAuditTestEcho wires AuditMiddleware behind a fake auth middleware that authenticates as user
+func newAuditTestEcho(logger *zap.Logger, db *gorm.DB, user *auth.UserContext) *echo.Echo {
+	e := echo.New()
+	repo := NewAuditRepository(&database.DatabaseManager{MasterDB: db})
+	setUser := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if user != nil {
+				c.Set("user", user)
+			}
+			return next(c)
+		}
+	}
+
+	e.DELETE("/items/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	}, setUser, AuditMiddleware(logger, repo))
+	e.PUT("/items/:id", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusNotFound, "item not found")
+	}, setUser, AuditMiddleware(logger, repo))
+	e.GET("/items/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, setUser, AuditMiddleware(logger, repo))
+	return e
+}
+
+// waitForAuditLogs waits until the background writes have stored count rows
+func waitForAuditLogs(t *testing.T, db *gorm.DB, count int) []AuditLog {
+	t.Helper()
+	var logs []AuditLog
+	require.Eventually(t, func() bool {
+		logs = nil
+		return db.Order("id").Find(&logs).Error == nil && len(logs) >= count
+	}, 2*time.Second, 10*time.Millisecond)
+	return logs
+}
+
+func TestAuditMiddleware_RecordsDelete(t *testing.T) {
+	db := testsupport.NewTestDB(t, &AuditLog{})
+	user := &auth.UserContext{UserID: 42, Email: "admin@example.com", Role: "admin"}
+	e := newAuditTestEcho(zaptest.NewLogger(t), db, user)
+
+	req := httptest.NewRequest(http.MethodDelete, "/items/7", nil)
+	req.Header.Set(echo.HeaderXRealIP, "203.0.113.9")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	logs := waitForAuditLogs(t, db, 1)
+	require.Len(t, logs, 1)
+	entry := logs[0]
+	assert.Equal(t, uint(42), entry.UserID)
+	assert.Equal(t, "admin@example.com", entry.Email)
+	assert.Equal(t, http.MethodDelete, entry.Method)
+	assert.Equal(t, "/items/7", entry.Path)
+	assert.Equal(t, http.StatusNoContent, entry.Status)
+	assert.Equal(t, "203.0.113.9", entry.SourceIP)
+	assert.False(t, entry.CreatedAt.IsZero())
+}
+
+func TestAuditMiddleware_RecordsErrorStatus(t *testing.T) {
+	db := testsupport.NewTestDB(t, &AuditLog{})
+	e := newAuditTestEcho(zaptest.NewLogger(t), db, nil)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/items/7", nil))
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	logs := waitForAuditLogs(t, db, 1)
+	assert.Equal(t, http.StatusNotFound, logs[0].Status)
+	assert.Zero(t, logs[0].UserID, "anonymous requests are recorded without a user")
+}
+
+func TestAuditMiddleware_SkipsReads(t *testing.T) {
+	db := testsupport.NewTestDB(t, &AuditLog{})
+	e := newAuditTestEcho(zaptest.NewLogger(t), db, nil)
+
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/7", nil))
+	e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/items/7", nil))
+
+	logs := waitForAuditLogs(t, db, 1)
+	require.Len(t, logs, 1)
+	assert.Equal(t, http.MethodDelete, logs[0].Method)
+}
+
+func TestAuditMiddleware_WriteFailureDoesNotFailRequest(t *testing.T) {
+	db := testsupport.NewTestDB(t) // audit_logs is not migrated, so every write fails
+	core, recorded := observer.New(zap.ErrorLevel)
+	e := newAuditTestEcho(zap.New(core), db, nil)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/items/7", nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	require.Eventually(t, func() bool {
+		return recorded.FilterMessage("Failed to write audit log").Len() == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"gorm.io/gorm"
 	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/logger"
 )
 
 // RequestContext represents the context of a request (tenant or master)
@@ -39,6 +40,7 @@ func ContextMiddleware(dbManager *database.DatabaseManager) echo.MiddlewareFunc
 			// Add tenant ID to Go context for repository layer
 			if tenantID != "" {
 				goCtx := database.WithTenantID(c.Request().Context(), tenantID)
+				goCtx = logger.WithTenantID(goCtx, tenantID)
 				c.SetRequest(c.Request().WithContext(goCtx))
 			}
 			
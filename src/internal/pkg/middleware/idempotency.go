@@ -0,0 +1,269 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client-chosen key that identifies retries of the same request
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set to "true" on responses replayed from the store
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength bounds the key so it fits the indexed column
+	maxIdempotencyKeyLength = 255
+	// idempotencyReleaseTimeout bounds cleanup of a key whose request failed
+	idempotencyReleaseTimeout = 5 * time.Second
+)
+
+var (
+	// ErrIdempotencyKeyInProgress is returned when another request with the same key has not finished
+	ErrIdempotencyKeyInProgress = errors.New("idempotency key in progress")
+	// ErrIdempotencyKeyMismatch is returned when a key is reused for a different method or path
+	ErrIdempotencyKeyMismatch = errors.New("idempotency key reused for a different request")
+)
+
+// IdempotencyKey identifies a request by the client's key, scoped to the caller
+type IdempotencyKey struct {
+	Key    string
+	UserID uint // 0 for unauthenticated callers
+	Method string
+	Path   string
+}
+
+// IdempotentResponse is the first response to a key, replayed for its retries
+type IdempotentResponse struct {
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyStore reserves idempotency keys and keeps the response of the request that reserved them
+type IdempotencyStore interface {
+	// Begin reserves key for a new request and returns nil, or returns the stored response of a finished
+	// request with the same key. It returns ErrIdempotencyKeyInProgress while that request is still running
+	// and ErrIdempotencyKeyMismatch when the key was used for another method or path.
+	Begin(ctx context.Context, key IdempotencyKey) (*IdempotentResponse, error)
+	// Complete stores the response for a key reserved by Begin
+	Complete(ctx context.Context, key IdempotencyKey, resp *IdempotentResponse) error
+	// Release forgets a key reserved by Begin so the request can be retried
+	Release(ctx context.Context, key IdempotencyKey) error
+}
+
+// IdempotencyRecord is a reserved idempotency key and, once the request finished, its response
+type IdempotencyRecord struct {
+	Key         string    `gorm:"column:idempotency_key;primaryKey;size:255"`
+	UserID      uint      `gorm:"primaryKey;autoIncrement:false"`
+	Method      string    `gorm:"not null"`
+	Path        string    `gorm:"not null"`
+	Completed   bool      `gorm:"not null;default:false"`
+	Status      int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time `gorm:"index;not null"`
+	CreatedAt   time.Time
+}
+
+// TableName specifies the table name for IdempotencyRecord model
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
+
+// IdempotencyRepository stores idempotency keys in the master database for idempotency.ttl
+type IdempotencyRepository struct {
+	*database.MasterRepo[IdempotencyRecord]
+	ttl time.Duration
+}
+
+// NewIdempotencyRepository creates a new idempotency repository using master database
+func NewIdempotencyRepository(dbManager *database.DatabaseManager, cfg *config.Config) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		MasterRepo: database.NewMasterRepo[IdempotencyRecord](dbManager),
+		ttl:        cfg.Idempotency.TTL,
+	}
+}
+
+// NewIdempotencyStore provides the idempotency repository as the IdempotencyStore used by IdempotencyMiddleware
+func NewIdempotencyStore(repo *IdempotencyRepository) IdempotencyStore {
+	return repo
+}
+
+// Begin reserves key, taking over an expired reservation, or reports the state of the existing one
+func (r *IdempotencyRepository) Begin(ctx context.Context, key IdempotencyKey) (*IdempotentResponse, error) {
+	db := r.GetDB().WithContext(ctx)
+	now := time.Now()
+	record := &IdempotencyRecord{
+		Key:       key.Key,
+		UserID:    key.UserID,
+		Method:    key.Method,
+		Path:      key.Path,
+		ExpiresAt: now.Add(r.ttl),
+	}
+
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return nil, fmt.Errorf("reserve idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	// The key exists; reuse it if it expired, so an expired row never blocks a new request
+	result = db.Model(&IdempotencyRecord{}).
+		Where("idempotency_key = ? AND user_id = ? AND expires_at <= ?", key.Key, key.UserID, now).
+		Updates(map[string]interface{}{
+			"method":       key.Method,
+			"path":         key.Path,
+			"completed":    false,
+			"status":       0,
+			"content_type": "",
+			"body":         nil,
+			"expires_at":   record.ExpiresAt,
+			"created_at":   now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("take over expired idempotency key: %w", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return nil, nil
+	}
+
+	var existing IdempotencyRecord
+	if err := db.Where("idempotency_key = ? AND user_id = ?", key.Key, key.UserID).First(&existing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Released between our insert and this read; the client can retry
+			return nil, ErrIdempotencyKeyInProgress
+		}
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+	if existing.Method != key.Method || existing.Path != key.Path {
+		return nil, ErrIdempotencyKeyMismatch
+	}
+	if !existing.Completed {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return &IdempotentResponse{Status: existing.Status, ContentType: existing.ContentType, Body: existing.Body}, nil
+}
+
+// Complete stores the response for a reserved key
+func (r *IdempotencyRepository) Complete(ctx context.Context, key IdempotencyKey, resp *IdempotentResponse) error {
+	err := r.GetDB().WithContext(ctx).Model(&IdempotencyRecord{}).
+		Where("idempotency_key = ? AND user_id = ?", key.Key, key.UserID).
+		Updates(map[string]interface{}{
+			"completed":    true,
+			"status":       resp.Status,
+			"content_type": resp.ContentType,
+			"body":         resp.Body,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release deletes a reserved key that has not completed
+func (r *IdempotencyRepository) Release(ctx context.Context, key IdempotencyKey) error {
+	err := r.GetDB().WithContext(ctx).
+		Where("idempotency_key = ? AND user_id = ? AND completed = ?", key.Key, key.UserID, false).
+		Delete(&IdempotencyRecord{}).Error
+	if err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// IdempotencyMiddleware makes requests carrying an Idempotency-Key header safe to retry. The first response
+// with a status below 500 is stored per key and authenticated user, and later requests with the same key get
+// that response back without running the handler. Mount it after JWTMiddleware so keys are scoped per user.
+// Requests without the header are passed through unchanged.
+func IdempotencyMiddleware(store IdempotencyStore, logger *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			header := c.Request().Header.Get(IdempotencyKeyHeader)
+			if header == "" {
+				return next(c)
+			}
+			if len(header) > maxIdempotencyKeyLength {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength),
+				})
+			}
+
+			key := IdempotencyKey{Key: header, Method: c.Request().Method, Path: c.Request().URL.Path}
+			if user, err := auth.GetUserFromContext(c); err == nil {
+				key.UserID = user.UserID
+			}
+
+			ctx := c.Request().Context()
+			stored, err := store.Begin(ctx, key)
+			switch {
+			case errors.Is(err, ErrIdempotencyKeyInProgress):
+				return c.JSON(http.StatusConflict, map[string]string{
+					"error": "A request with this Idempotency-Key is in progress",
+				})
+			case errors.Is(err, ErrIdempotencyKeyMismatch):
+				return c.JSON(http.StatusUnprocessableEntity, map[string]string{
+					"error": "Idempotency-Key was already used for a different request",
+				})
+			case err != nil:
+				logger.Error("Failed to check idempotency key", zap.Error(err))
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"error": "Failed to check idempotency key",
+				})
+			case stored != nil:
+				c.Response().Header().Set(IdempotentReplayedHeader, "true")
+				return c.Blob(stored.Status, stored.ContentType, stored.Body)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			handlerErr := next(c)
+			c.Response().Writer = recorder.ResponseWriter
+
+			status := c.Response().Status
+			if handlerErr != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+				// Let the client retry: errors are rendered after this middleware returns and are not stored
+				releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), idempotencyReleaseTimeout)
+				defer cancel()
+				if err := store.Release(releaseCtx, key); err != nil {
+					logger.Error("Failed to release idempotency key", zap.Error(err))
+				}
+				return handlerErr
+			}
+
+			resp := &IdempotentResponse{
+				Status:      status,
+				ContentType: c.Response().Header().Get(echo.HeaderContentType),
+				Body:        recorder.body.Bytes(),
+			}
+			if err := store.Complete(context.WithoutCancel(ctx), key, resp); err != nil {
+				logger.Error("Failed to store idempotent response", zap.Error(err))
+			}
+			return nil
+		}
+	}
+}
+
+// responseRecorder copies the response body while writing it to the client
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write writes b to the client and keeps a copy
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,192 @@
+// +build cgo
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/testsupport"
+)
+
+// idempotencyTestItem is the row created by the test handler
+type idempotencyTestItem struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+// idempotencyFixture is an Echo server whose create handler inserts an idempotencyTestItem per call
+type idempotencyFixture struct {
+	e       *echo.Echo
+	db      *gorm.DB
+	calls   atomic.Int32
+	fail    atomic.Bool   // Make the handler answer 500 without inserting
+	release chan struct{} // When set, the handler waits for it before inserting
+	started chan struct{}
+}
+
+// newIdempotencyFixture wires IdempotencyMiddleware behind a fake auth middleware reading the user ID from X-User
+func newIdempotencyFixture(t *testing.T, ttl time.Duration) *idempotencyFixture {
+	f := &idempotencyFixture{db: testsupport.NewTestDB(t, &IdempotencyRecord{}, &idempotencyTestItem{})}
+	repo := NewIdempotencyRepository(&database.DatabaseManager{MasterDB: f.db}, &config.Config{
+		Idempotency: config.IdempotencyConfig{TTL: ttl},
+	})
+
+	setUser := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Header.Get("X-User") {
+			case "1":
+				c.Set("user", &auth.UserContext{UserID: 1})
+			case "2":
+				c.Set("user", &auth.UserContext{UserID: 2})
+			}
+			return next(c)
+		}
+	}
+	create := func(c echo.Context) error {
+		f.calls.Add(1)
+		if f.started != nil {
+			close(f.started)
+			<-f.release
+		}
+		if f.fail.Load() {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "boom"})
+		}
+		item := &idempotencyTestItem{Name: "item"}
+		if err := f.db.Create(item).Error; err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, item)
+	}
+
+	f.e = echo.New()
+	f.e.POST("/items", create, setUser, IdempotencyMiddleware(repo, zaptest.NewLogger(t)))
+	f.e.POST("/other", create, setUser, IdempotencyMiddleware(repo, zaptest.NewLogger(t)))
+	return f
+}
+
+// post sends a POST to path with the given Idempotency-Key and user
+func (f *idempotencyFixture) post(path, key, user string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	req.Header.Set("X-User", user)
+	rec := httptest.NewRecorder()
+	f.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// itemCount returns how many items the handler inserted
+func (f *idempotencyFixture) itemCount(t *testing.T) int64 {
+	var count int64
+	require.NoError(t, f.db.Model(&idempotencyTestItem{}).Count(&count).Error)
+	return count
+}
+
+func TestIdempotencyMiddleware_ReplaysStoredResponse(t *testing.T) {
+	f := newIdempotencyFixture(t, time.Hour)
+
+	first := f.post("/items", "key-1", "1")
+	require.Equal(t, http.StatusCreated, first.Code)
+
+	second := f.post("/items", "key-1", "1")
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, first.Header().Get(echo.HeaderContentType), second.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+	assert.Equal(t, int32(1), f.calls.Load())
+	assert.Equal(t, int64(1), f.itemCount(t))
+}
+
+func TestIdempotencyMiddleware_KeysAreScoped(t *testing.T) {
+	f := newIdempotencyFixture(t, time.Hour)
+
+	t.Run("per user", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, f.post("/items", "shared", "1").Code)
+		rec := f.post("/items", "shared", "2")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, int64(2), f.itemCount(t))
+	})
+
+	t.Run("reuse for another path is rejected", func(t *testing.T) {
+		rec := f.post("/other", "shared", "1")
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, int64(2), f.itemCount(t))
+	})
+
+	t.Run("no key is not deduplicated", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, f.post("/items", "", "1").Code)
+		require.Equal(t, http.StatusCreated, f.post("/items", "", "1").Code)
+		assert.Equal(t, int64(4), f.itemCount(t))
+	})
+}
+
+func TestIdempotencyMiddleware_InProgress(t *testing.T) {
+	f := newIdempotencyFixture(t, time.Hour)
+	f.started = make(chan struct{})
+	f.release = make(chan struct{})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- f.post("/items", "slow", "1")
+	}()
+	<-f.started
+
+	rec := f.post("/items", "slow", "1")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "in progress")
+
+	close(f.release)
+	assert.Equal(t, http.StatusCreated, (<-done).Code)
+	assert.Equal(t, int64(1), f.itemCount(t))
+}
+
+func TestIdempotencyMiddleware_ServerErrorIsNotStored(t *testing.T) {
+	f := newIdempotencyFixture(t, time.Hour)
+
+	f.fail.Store(true)
+	require.Equal(t, http.StatusInternalServerError, f.post("/items", "retry", "1").Code)
+
+	f.fail.Store(false)
+	rec := f.post("/items", "retry", "1")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Empty(t, rec.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, int32(2), f.calls.Load())
+	assert.Equal(t, int64(1), f.itemCount(t))
+}
+
+func TestIdempotencyMiddleware_ExpiredKeyIsReused(t *testing.T) {
+	f := newIdempotencyFixture(t, time.Millisecond)
+
+	require.Equal(t, http.StatusCreated, f.post("/items", "short", "1").Code)
+	time.Sleep(5 * time.Millisecond)
+
+	rec := f.post("/items", "short", "1")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Empty(t, rec.Header().Get(IdempotentReplayedHeader))
+	assert.Equal(t, int64(2), f.itemCount(t))
+}
+
+func TestIdempotencyMiddleware_KeyTooLong(t *testing.T) {
+	f := newIdempotencyFixture(t, time.Hour)
+
+	rec := f.post("/items", strings.Repeat("k", maxIdempotencyKeyLength+1), "1")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, int32(0), f.calls.Load())
+}
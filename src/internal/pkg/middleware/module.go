@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+// Module exports the audit repository used by AuditMiddleware and the stores used by RateLimitMiddleware
+// and IdempotencyMiddleware
+var Module = fx.Options(
+	fx.Provide(NewAuditRepository),
+	fx.Provide(NewReloadingRateLimitStore),
+	fx.Provide(NewIdempotencyRepository),
+	fx.Provide(NewIdempotencyStore),
+	fx.Invoke(RegisterMigrations),
+)
+
+// RunMigrations creates or updates the audit_logs and idempotency_keys tables
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&AuditLog{}); err != nil {
+		return fmt.Errorf("migrate audit logs: %w", err)
+	}
+	if err := db.AutoMigrate(&IdempotencyRecord{}); err != nil {
+		return fmt.Errorf("migrate idempotency keys: %w", err)
+	}
+	return nil
+}
+
+// Migrations returns the versioned schema changes of the middleware tables
+func Migrations() database.MigrationSet {
+	return database.MigrationSet{
+		Service: "middleware",
+		Migrations: []database.Migration{
+			{
+				Version:     1,
+				Description: "create audit_logs and idempotency_keys tables",
+				Up:          RunMigrations,
+				Down: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable(&IdempotencyRecord{}, &AuditLog{})
+				},
+			},
+		},
+	}
+}
+
+// RegisterMigrations migrates the middleware tables in the master database.
+// Migrations only run when master_database.auto_migrate is enabled.
+func RegisterMigrations(cfg *config.Config, dbManager *database.DatabaseManager, logger *zap.Logger) {
+	if !cfg.MasterDatabase.AutoMigrate {
+		logger.Info("Skipping middleware migrations, master_database.auto_migrate is disabled")
+		return
+	}
+
+	if err := database.MigrateUp(context.Background(), dbManager.MasterDB, Migrations()); err != nil {
+		logger.Error("Failed to migrate middleware tables", zap.Error(err))
+		return
+	}
+
+	logger.Info("Middleware tables migrated successfully")
+}
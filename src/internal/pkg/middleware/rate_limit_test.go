@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+)
+
+// newRateLimitTestEcho serves GET /items with ContextMiddleware and RateLimitMiddleware allowing burst requests.
+// Like NewEcho without trusted proxies, the client IP is the connection address. A request with an
+// X-Test-User header is treated as authenticated by that user ID, standing in for JWTMiddleware.
+func newRateLimitTestEcho(t *testing.T, burst int) *echo.Echo {
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{RequestsPerSecond: 0.001, Burst: burst}}
+	require.NoError(t, cfg.RateLimit.Validate())
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.Use(ContextMiddleware(mockDatabaseManager()))
+	authenticate := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if id := c.Request().Header.Get("X-Test-User"); id != "" {
+				userID, err := strconv.ParseUint(id, 10, 64)
+				require.NoError(t, err)
+				c.Set("user", &auth.UserContext{UserID: uint(userID)})
+			}
+			return next(c)
+		}
+	}
+	e.GET("/items", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, authenticate, RateLimitMiddleware(NewMemoryRateLimitStore(cfg)))
+	return e
+}
+
+// getItems sends GET /items from remoteAddr with the given headers and returns the status code
+func getItems(e *echo.Echo, remoteAddr string, headers map[string]string) int {
+	req := httptest.NewRequest(http.MethodGet, "/items", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+// TestRateLimitMiddleware_PerUser tests that exhausting one user's limit leaves other users behind the same IP unaffected
+func TestRateLimitMiddleware_PerUser(t *testing.T) {
+	e := newRateLimitTestEcho(t, 2)
+	userA := map[string]string{"X-Test-User": "1"}
+	userB := map[string]string{"X-Test-User": "2"}
+
+	assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.1:1000", userA))
+	assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.1:1000", userA))
+	assert.Equal(t, http.StatusTooManyRequests, getItems(e, "10.0.0.1:1000", userA))
+
+	assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.1:1000", userB), "another user behind the same IP keeps its own limit")
+	assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.1:1000", userB))
+	assert.Equal(t, http.StatusTooManyRequests, getItems(e, "10.0.0.1:1000", userB))
+}
+
+// TestRateLimitMiddleware_PerIP tests that anonymous requests are limited per client IP
+func TestRateLimitMiddleware_PerIP(t *testing.T) {
+	e := newRateLimitTestEcho(t, 1)
+
+	assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.1:1000", nil))
+	assert.Equal(t, http.StatusTooManyRequests, getItems(e, "10.0.0.1:1000", nil))
+	assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.2:1000", nil))
+}
+
+// TestRateLimitMiddleware_ClientHeadersDoNotResetBucket tests that rotating X-Tenant-ID or forwarding headers
+// does not give a caller a fresh bucket
+func TestRateLimitMiddleware_ClientHeadersDoNotResetBucket(t *testing.T) {
+	t.Run("anonymous", func(t *testing.T) {
+		e := newRateLimitTestEcho(t, 1)
+
+		assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.1:1000", map[string]string{"X-Tenant-ID": "tenant-a"}))
+		assert.Equal(t, http.StatusTooManyRequests, getItems(e, "10.0.0.1:1000", map[string]string{"X-Tenant-ID": "tenant-b"}))
+		assert.Equal(t, http.StatusTooManyRequests, getItems(e, "10.0.0.1:1000", map[string]string{echo.HeaderXForwardedFor: "203.0.113.1"}))
+		assert.Equal(t, http.StatusTooManyRequests, getItems(e, "10.0.0.1:1000", map[string]string{echo.HeaderXRealIP: "203.0.113.2"}))
+	})
+
+	t.Run("authenticated", func(t *testing.T) {
+		e := newRateLimitTestEcho(t, 1)
+
+		assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.1:1000", map[string]string{"X-Test-User": "1", "X-Tenant-ID": "tenant-a"}))
+		assert.Equal(t, http.StatusTooManyRequests, getItems(e, "10.0.0.1:1000", map[string]string{"X-Test-User": "1", "X-Tenant-ID": "tenant-b"}))
+		assert.Equal(t, http.StatusTooManyRequests, getItems(e, "10.0.0.2:1000", map[string]string{"X-Test-User": "1"}))
+	})
+
+	t.Run("another tenant's quota cannot be drained", func(t *testing.T) {
+		e := newRateLimitTestEcho(t, 1)
+
+		assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.1:1000", map[string]string{"X-Tenant-ID": "tenant-a"}))
+		assert.Equal(t, http.StatusTooManyRequests, getItems(e, "10.0.0.1:1000", map[string]string{"X-Tenant-ID": "tenant-a"}))
+		assert.Equal(t, http.StatusOK, getItems(e, "10.0.0.2:1000", map[string]string{"X-Tenant-ID": "tenant-a"}))
+	})
+}
+
+// TestRateLimitIdentifier tests the user and IP precedence of rate limit keys
+func TestRateLimitIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		tenantID string
+		user     *auth.UserContext
+		want     string
+	}{
+		{
+			name:     "authenticated user wins over tenant header",
+			tenantID: "tenant-a",
+			user:     &auth.UserContext{UserID: 7},
+			want:     "user:7",
+		},
+		{
+			name: "authenticated user",
+			user: &auth.UserContext{UserID: 7},
+			want: "user:7",
+		},
+		{
+			name:     "tenant header is not a key",
+			tenantID: "tenant-a",
+			want:     "ip:203.0.113.9",
+		},
+		{
+			name: "client IP fallback",
+			want: "ip:203.0.113.9",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "203.0.113.9:1234"
+			req.Header.Set(echo.HeaderXRealIP, "198.51.100.1")
+			e := echo.New()
+			e.IPExtractor = echo.ExtractIPDirect()
+			c := e.NewContext(req, httptest.NewRecorder())
+			if tt.tenantID != "" {
+				c.Set("requestContext", &RequestContext{Type: "tenant", TenantID: tt.tenantID})
+			}
+			if tt.user != nil {
+				c.Set("user", tt.user)
+			}
+
+			got, err := RateLimitIdentifier(c)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// TestNewMemoryRateLimitStore_RefillsOverTime tests that the configured rate refills the bucket
+func TestNewMemoryRateLimitStore_RefillsOverTime(t *testing.T) {
+	store := NewMemoryRateLimitStore(&config.Config{RateLimit: config.RateLimitConfig{RequestsPerSecond: 50, Burst: 1, ExpiresIn: time.Minute}})
+
+	allowed, err := store.Allow("tenant:a")
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, _ = store.Allow("tenant:a")
+	assert.False(t, allowed)
+
+	require.Eventually(t, func() bool {
+		allowed, _ := store.Allow("tenant:a")
+		return allowed
+	}, time.Second, 5*time.Millisecond)
+}
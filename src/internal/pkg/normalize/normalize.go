@@ -0,0 +1,43 @@
+package normalize
+
+import (
+	"strings"
+
+	"myapp/internal/pkg/config"
+)
+
+// Policy normalizes user-supplied strings before they are stored or compared
+type Policy struct {
+	LowercaseCodes bool
+}
+
+// NewPolicy creates a normalization policy from configuration
+func NewPolicy(cfg config.NormalizationConfig) Policy {
+	return Policy{
+		LowercaseCodes: cfg.LowercaseCodes,
+	}
+}
+
+// Text trims leading and trailing whitespace
+func (p Policy) Text(s string) string {
+	return strings.TrimSpace(s)
+}
+
+// Name trims and collapses internal runs of whitespace into a single space
+func (p Policy) Name(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Code trims identifiers such as SKUs and codes, lowercasing them when configured
+func (p Policy) Code(s string) string {
+	s = strings.TrimSpace(s)
+	if p.LowercaseCodes {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+// Email trims and lowercases an email address
+func (p Policy) Email(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
@@ -0,0 +1,35 @@
+package normalize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"myapp/internal/pkg/config"
+)
+
+// TestPolicy tests string normalization rules
+func TestPolicy(t *testing.T) {
+	lower := NewPolicy(config.NormalizationConfig{LowercaseCodes: true})
+	preserve := NewPolicy(config.NormalizationConfig{})
+
+	tests := []struct {
+		name     string
+		fn       func(string) string
+		input    string
+		expected string
+	}{
+		{"text trims", preserve.Text, "  hello world \t", "hello world"},
+		{"name collapses whitespace", preserve.Name, "  John \t  Doe  ", "John Doe"},
+		{"code trims and lowercases", lower.Code, "  ABC ", "abc"},
+		{"code lowercase is idempotent", lower.Code, "abc", "abc"},
+		{"code preserves case by default", preserve.Code, "  ABC ", "ABC"},
+		{"email trims and lowercases", preserve.Email, " John.Doe@Example.COM ", "john.doe@example.com"},
+		{"empty string", lower.Name, "   ", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.fn(tt.input))
+		})
+	}
+}
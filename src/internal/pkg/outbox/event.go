@@ -0,0 +1,64 @@
+// Package outbox implements the transactional outbox: events are written to an outbox_events table in the
+// same transaction as the change they describe, and a publisher delivers them afterwards, so an event is
+// never lost to a crash between commit and delivery.
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Event is a change waiting in, or already published from, the outbox of the database it was written to
+type Event struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	AggregateType string     `gorm:"type:varchar(50);not null;index:idx_outbox_events_aggregate" json:"aggregate_type"` // Kind of record that changed, such as "product"
+	AggregateID   uint       `gorm:"not null;index:idx_outbox_events_aggregate" json:"aggregate_id"`
+	EventType     string     `gorm:"type:varchar(100);not null" json:"event_type"` // Such as "product.updated"
+	Payload       string     `gorm:"type:text;not null" json:"payload"`            // JSON body of the event
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`           // Failed deliveries so far
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	SentAt        *time.Time `gorm:"index" json:"sent_at,omitempty"`
+	DeadAt        *time.Time `gorm:"index" json:"dead_at,omitempty"` // Set once max_attempts deliveries failed; the event is not retried
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for Event
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+// IsPending reports whether the event still has to be published
+func (e *Event) IsPending() bool {
+	return e.SentAt == nil && e.DeadAt == nil
+}
+
+// Record writes an event to the outbox through tx, which should be the transaction making the change so
+// the event commits or rolls back with it. The payload is stored as JSON.
+func Record(tx *gorm.DB, aggregateType string, aggregateID uint, eventType string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s event payload: %w", eventType, err)
+	}
+
+	event := &Event{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       string(body),
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return fmt.Errorf("record %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// Migrate creates or updates the outbox_events table
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&Event{}); err != nil {
+		return fmt.Errorf("failed to migrate outbox_events table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+// publishTimeout bounds one publishing pass over all outboxes
+const publishTimeout = time.Minute
+
+// MasterModule publishes the events recorded in the master database's outbox
+var MasterModule = fx.Options(
+	fx.Provide(newWebhookPublisher),
+	fx.Invoke(StartMasterPublisher),
+)
+
+// TenantModule publishes the events recorded in the outbox of every active tenant database
+var TenantModule = fx.Options(
+	fx.Provide(newWebhookPublisher),
+	fx.Invoke(StartTenantPublisher),
+)
+
+// newWebhookPublisher creates a publisher posting events to outbox.webhook_url
+func newWebhookPublisher(cfg *config.Config, logger *zap.Logger) *Publisher {
+	return NewPublisher(&cfg.Outbox, NewWebhookDispatcher(cfg.Outbox.WebhookURL, nil), logger)
+}
+
+// StartMasterPublisher starts a background worker publishing the master database's outbox every
+// outbox.poll_interval. It does nothing when outbox.webhook_url is empty.
+func StartMasterPublisher(lc fx.Lifecycle, cfg *config.Config, publisher *Publisher, dbManager *database.DatabaseManager, logger *zap.Logger) {
+	startWorker(lc, cfg, logger, func(ctx context.Context) {
+		PublishDatabase(ctx, publisher, dbManager.MasterDB, logger)
+	})
+}
+
+// StartTenantPublisher starts a background worker publishing the outbox of every active tenant every
+// outbox.poll_interval. It does nothing when outbox.webhook_url is empty.
+func StartTenantPublisher(lc fx.Lifecycle, cfg *config.Config, publisher *Publisher, dbManager *database.DatabaseManager, logger *zap.Logger) {
+	startWorker(lc, cfg, logger, func(ctx context.Context) {
+		PublishTenants(ctx, publisher, dbManager.TenantConnManager, logger)
+	})
+}
+
+// PublishDatabase runs one publishing pass over the outbox in db and logs the outcome
+func PublishDatabase(ctx context.Context, publisher *Publisher, db *gorm.DB, logger *zap.Logger) {
+	result, err := publisher.Publish(ctx, db)
+	if err != nil {
+		logger.Error("Failed to publish outbox events", zap.Error(err))
+	}
+	logResult(logger, result)
+}
+
+// PublishTenants runs one publishing pass over the outbox of every active tenant and logs the outcome.
+// The tenant ID is in the context handed to the dispatcher.
+func PublishTenants(ctx context.Context, publisher *Publisher, connManager *database.TenantConnectionManager, logger *zap.Logger) {
+	tenantIDs, err := connManager.ActiveTenantIDs(ctx)
+	if err != nil {
+		logger.Error("Failed to list tenants for outbox publishing", zap.Error(err))
+		return
+	}
+
+	failed := connManager.ForEachTenant(ctx, tenantIDs, func(tenantID string, db *gorm.DB) error {
+		result, err := publisher.Publish(database.WithTenantID(ctx, tenantID), db)
+		logResult(logger.With(zap.String("tenant_id", tenantID)), result)
+		return err
+	})
+	for tenantID, err := range failed {
+		logger.Error("Failed to publish outbox events", zap.String("tenant_id", tenantID), zap.Error(err))
+	}
+}
+
+// logResult logs a publishing pass that did anything
+func logResult(logger *zap.Logger, result PublishResult) {
+	if result == (PublishResult{}) {
+		return
+	}
+	logger.Info("Published outbox events",
+		zap.Int("sent", result.Sent),
+		zap.Int("failed", result.Failed),
+		zap.Int("dead_lettered", result.DeadLettered),
+		zap.Int("deferred", result.Deferred))
+}
+
+// startWorker runs publish every outbox.poll_interval until the app stops
+func startWorker(lc fx.Lifecycle, cfg *config.Config, logger *zap.Logger, publish func(ctx context.Context)) {
+	if !cfg.Outbox.Enabled() {
+		logger.Info("Outbox publisher disabled, outbox.webhook_url is empty")
+		return
+	}
+
+	interval := cfg.Outbox.PollInterval
+	workerCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ticker.C:
+						publishCtx, publishCancel := context.WithTimeout(workerCtx, publishTimeout)
+						publish(publishCtx)
+						publishCancel()
+					case <-workerCtx.Done():
+						logger.Info("Outbox publisher stopped")
+						return
+					}
+				}
+			}()
+
+			logger.Info("Outbox publisher started", zap.Duration("interval", interval))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+
+			// Wait for an in-flight pass to finish
+			select {
+			case <-done:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+}
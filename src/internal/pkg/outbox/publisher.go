@@ -0,0 +1,239 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+// dispatchTimeout bounds a single delivery
+const dispatchTimeout = 10 * time.Second
+
+// Dispatcher delivers one event to its consumers. It returns an error when the event must be retried.
+// The tenant whose database the event came from, if any, is available through database.GetTenantID(ctx).
+type Dispatcher interface {
+	Dispatch(ctx context.Context, event *Event) error
+}
+
+// DispatcherFunc adapts a function to Dispatcher
+type DispatcherFunc func(ctx context.Context, event *Event) error
+
+// Dispatch calls f
+func (f DispatcherFunc) Dispatch(ctx context.Context, event *Event) error {
+	return f(ctx, event)
+}
+
+// Envelope is the JSON body posted by WebhookDispatcher. Consumers should deduplicate on ID and TenantID,
+// since delivery is at least once.
+type Envelope struct {
+	ID            uint            `json:"id"`
+	TenantID      string          `json:"tenant_id,omitempty"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   uint            `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Data          json.RawMessage `json:"data"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// WebhookDispatcher posts each event to a URL as an Envelope and succeeds on a 2xx response
+type WebhookDispatcher struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookDispatcher creates a dispatcher posting to url. A nil client uses one with a short timeout.
+func NewWebhookDispatcher(url string, client *http.Client) *WebhookDispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: dispatchTimeout}
+	}
+	return &WebhookDispatcher{url: url, client: client}
+}
+
+// Dispatch posts the event
+func (d *WebhookDispatcher) Dispatch(ctx context.Context, event *Event) error {
+	tenantID, _ := database.GetTenantID(ctx)
+	body, err := json.Marshal(&Envelope{
+		ID:            event.ID,
+		TenantID:      tenantID,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		EventType:     event.EventType,
+		Data:          json.RawMessage(event.Payload),
+		CreatedAt:     event.CreatedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal event envelope: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create event request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", event.EventType)
+	req.Header.Set("X-Event-ID", strconv.FormatUint(uint64(event.ID), 10))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("event endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PublishResult counts what one publishing pass over an outbox did
+type PublishResult struct {
+	Sent         int
+	Failed       int // Deliveries that failed and will be retried
+	DeadLettered int
+	Deferred     int // Events held back because an earlier event of the same aggregate failed in this pass
+}
+
+// Publisher delivers pending outbox events with at-least-once semantics: an event is marked sent only after
+// its dispatch succeeded, so a crash in between delivers it again. Events of one aggregate are delivered in
+// the order they were recorded; after a failed delivery the aggregate's later events wait for the retry.
+// An event that fails max_attempts times is dead-lettered and no longer holds its aggregate back.
+type Publisher struct {
+	dispatcher  Dispatcher
+	batchSize   int
+	maxAttempts int
+	logger      *zap.Logger
+}
+
+// NewPublisher creates a publisher using outbox.batch_size and outbox.max_attempts. A nil logger logs nothing.
+func NewPublisher(cfg *config.OutboxConfig, dispatcher Dispatcher, logger *zap.Logger) *Publisher {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	return &Publisher{
+		dispatcher:  dispatcher,
+		batchSize:   cfg.BatchSize,
+		maxAttempts: maxAttempts,
+		logger:      logger,
+	}
+}
+
+// aggregateKey identifies the aggregate an event belongs to
+type aggregateKey struct {
+	aggregateType string
+	aggregateID   uint
+}
+
+// Publish delivers up to outbox.batch_size pending events from the outbox in db, oldest first.
+// A failed delivery is recorded on the event and is not returned as an error; the error is for the outbox
+// itself being unreadable or unwritable.
+func (p *Publisher) Publish(ctx context.Context, db *gorm.DB) (PublishResult, error) {
+	var result PublishResult
+
+	var events []*Event
+	query := db.WithContext(ctx).Where("sent_at IS NULL AND dead_at IS NULL").Order("id")
+	if p.batchSize > 0 {
+		query = query.Limit(p.batchSize)
+	}
+	if err := query.Find(&events).Error; err != nil {
+		return result, fmt.Errorf("list pending outbox events: %w", err)
+	}
+
+	blocked := make(map[aggregateKey]bool)
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		key := aggregateKey{event.AggregateType, event.AggregateID}
+		if blocked[key] {
+			result.Deferred++
+			continue
+		}
+
+		dispatchErr := p.dispatcher.Dispatch(ctx, event)
+		if dispatchErr == nil {
+			if err := p.markSent(ctx, db, event); err != nil {
+				return result, err
+			}
+			result.Sent++
+			continue
+		}
+
+		if ctx.Err() != nil {
+			// Shutting down; the interrupted delivery does not count as an attempt
+			return result, ctx.Err()
+		}
+		blocked[key] = true
+		dead, err := p.markFailed(ctx, db, event, dispatchErr)
+		if err != nil {
+			return result, err
+		}
+		if dead {
+			result.DeadLettered++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// markSent records a successful delivery. The sent_at IS NULL guard keeps the first delivery time if two
+// publishers raced on the same event.
+func (p *Publisher) markSent(ctx context.Context, db *gorm.DB, event *Event) error {
+	now := time.Now()
+	err := db.WithContext(ctx).Model(&Event{}).
+		Where("id = ? AND sent_at IS NULL", event.ID).
+		Update("sent_at", now).Error
+	if err != nil {
+		return fmt.Errorf("mark outbox event %d sent: %w", event.ID, err)
+	}
+	event.SentAt = &now
+	return nil
+}
+
+// markFailed records a failed delivery and dead-letters the event once it has used up its attempts.
+// It reports whether the event was dead-lettered.
+func (p *Publisher) markFailed(ctx context.Context, db *gorm.DB, event *Event, dispatchErr error) (bool, error) {
+	event.Attempts++
+	event.LastError = dispatchErr.Error()
+	updates := map[string]interface{}{
+		"attempts":   event.Attempts,
+		"last_error": event.LastError,
+	}
+
+	fields := []zap.Field{
+		zap.Uint("event_id", event.ID),
+		zap.String("event_type", event.EventType),
+		zap.String("aggregate_type", event.AggregateType),
+		zap.Uint("aggregate_id", event.AggregateID),
+		zap.Int("attempts", event.Attempts),
+		zap.Error(dispatchErr),
+	}
+	dead := event.Attempts >= p.maxAttempts
+	if dead {
+		now := time.Now()
+		event.DeadAt = &now
+		updates["dead_at"] = now
+		p.logger.Error("Outbox event moved to dead letter", fields...)
+	} else {
+		p.logger.Warn("Outbox event delivery failed", fields...)
+	}
+
+	if err := db.WithContext(ctx).Model(&Event{}).Where("id = ?", event.ID).Updates(updates).Error; err != nil {
+		return false, fmt.Errorf("record outbox event %d failure: %w", event.ID, err)
+	}
+	return dead, nil
+}
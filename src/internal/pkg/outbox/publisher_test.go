@@ -0,0 +1,181 @@
+// +build cgo
+
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
+	"myapp/internal/testsupport"
+)
+
+// recordingDispatcher records the IDs of dispatched events and fails those listed in failures
+type recordingDispatcher struct {
+	mu         sync.Mutex
+	dispatched []uint
+	failures   map[uint]int // Event ID to how many more deliveries fail
+}
+
+func (d *recordingDispatcher) Dispatch(ctx context.Context, event *outbox.Event) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dispatched = append(d.dispatched, event.ID)
+	if d.failures[event.ID] > 0 {
+		d.failures[event.ID]--
+		return errors.New("consumer unavailable")
+	}
+	return nil
+}
+
+// record commits one event in its own transaction and returns it
+func record(t *testing.T, db *gorm.DB, aggregateID uint, eventType string) *outbox.Event {
+	require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+		return outbox.Record(tx, "product", aggregateID, eventType, map[string]uint{"id": aggregateID})
+	}))
+	var event outbox.Event
+	require.NoError(t, db.Order("id DESC").First(&event).Error)
+	return &event
+}
+
+// TestPublisher_Publish tests that a committed event is delivered and marked sent exactly once
+func TestPublisher_Publish(t *testing.T) {
+	db := testsupport.NewTestDB(t, &outbox.Event{})
+	dispatcher := &recordingDispatcher{}
+	publisher := outbox.NewPublisher(&config.OutboxConfig{BatchSize: 100, MaxAttempts: 3}, dispatcher, nil)
+	ctx := context.Background()
+
+	event := record(t, db, 1, "product.created")
+	assert.Equal(t, `{"id":1}`, event.Payload)
+	assert.True(t, event.IsPending())
+
+	result, err := publisher.Publish(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.PublishResult{Sent: 1}, result)
+
+	var stored outbox.Event
+	require.NoError(t, db.First(&stored, event.ID).Error)
+	require.NotNil(t, stored.SentAt)
+	assert.Zero(t, stored.Attempts)
+
+	// A second pass finds nothing left to send
+	result, err = publisher.Publish(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.PublishResult{}, result)
+	assert.Equal(t, []uint{event.ID}, dispatcher.dispatched)
+}
+
+// TestRecord_RolledBack tests that an event written in a rolled back transaction is never published
+func TestRecord_RolledBack(t *testing.T) {
+	db := testsupport.NewTestDB(t, &outbox.Event{})
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		require.NoError(t, outbox.Record(tx, "product", 1, "product.created", map[string]uint{"id": 1}))
+		return errors.New("mutation failed")
+	})
+	require.Error(t, err)
+
+	var count int64
+	require.NoError(t, db.Model(&outbox.Event{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+// TestPublisher_Publish_Ordering tests that a failed event holds back later events of its aggregate only
+func TestPublisher_Publish_Ordering(t *testing.T) {
+	db := testsupport.NewTestDB(t, &outbox.Event{})
+	first := record(t, db, 1, "product.created")
+	second := record(t, db, 1, "product.updated")
+	other := record(t, db, 2, "product.created")
+
+	dispatcher := &recordingDispatcher{failures: map[uint]int{first.ID: 1}}
+	publisher := outbox.NewPublisher(&config.OutboxConfig{BatchSize: 100, MaxAttempts: 3}, dispatcher, nil)
+	ctx := context.Background()
+
+	result, err := publisher.Publish(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.PublishResult{Sent: 1, Failed: 1, Deferred: 1}, result)
+	assert.Equal(t, []uint{first.ID, other.ID}, dispatcher.dispatched, "the update waits for the failed create")
+
+	var stored outbox.Event
+	require.NoError(t, db.First(&stored, first.ID).Error)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Equal(t, "consumer unavailable", stored.LastError)
+	assert.True(t, stored.IsPending())
+
+	result, err = publisher.Publish(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.PublishResult{Sent: 2}, result)
+	assert.Equal(t, []uint{first.ID, other.ID, first.ID, second.ID}, dispatcher.dispatched)
+}
+
+// TestPublisher_Publish_DeadLetter tests that an event is dead-lettered after max attempts and stops blocking its aggregate
+func TestPublisher_Publish_DeadLetter(t *testing.T) {
+	db := testsupport.NewTestDB(t, &outbox.Event{})
+	poison := record(t, db, 1, "product.created")
+	next := record(t, db, 1, "product.updated")
+
+	dispatcher := &recordingDispatcher{failures: map[uint]int{poison.ID: 10}}
+	publisher := outbox.NewPublisher(&config.OutboxConfig{BatchSize: 100, MaxAttempts: 2}, dispatcher, nil)
+	ctx := context.Background()
+
+	result, err := publisher.Publish(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.PublishResult{Failed: 1, Deferred: 1}, result)
+
+	result, err = publisher.Publish(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.PublishResult{DeadLettered: 1, Deferred: 1}, result)
+
+	var stored outbox.Event
+	require.NoError(t, db.First(&stored, poison.ID).Error)
+	require.NotNil(t, stored.DeadAt)
+	assert.Nil(t, stored.SentAt)
+	assert.Equal(t, 2, stored.Attempts)
+
+	result, err = publisher.Publish(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, outbox.PublishResult{Sent: 1}, result)
+	assert.Equal(t, []uint{poison.ID, poison.ID, next.ID}, dispatcher.dispatched)
+}
+
+// TestWebhookDispatcher_Dispatch tests the envelope posted for an event
+func TestWebhookDispatcher_Dispatch(t *testing.T) {
+	var (
+		envelope outbox.Envelope
+		header   http.Header
+	)
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&envelope))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	dispatcher := outbox.NewWebhookDispatcher(server.URL, nil)
+	event := &outbox.Event{ID: 7, AggregateType: "product", AggregateID: 3, EventType: "product.updated", Payload: `{"id":3}`}
+	ctx := database.WithTenantID(context.Background(), "tenant-a")
+
+	require.NoError(t, dispatcher.Dispatch(ctx, event))
+	assert.Equal(t, uint(7), envelope.ID)
+	assert.Equal(t, "tenant-a", envelope.TenantID)
+	assert.Equal(t, "product", envelope.AggregateType)
+	assert.Equal(t, uint(3), envelope.AggregateID)
+	assert.Equal(t, "product.updated", envelope.EventType)
+	assert.JSONEq(t, `{"id":3}`, string(envelope.Data))
+	assert.Equal(t, "product.updated", header.Get("X-Event-Type"))
+	assert.Equal(t, "7", header.Get("X-Event-ID"))
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, dispatcher.Dispatch(ctx, event))
+}
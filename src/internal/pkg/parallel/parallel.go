@@ -0,0 +1,78 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultConcurrency is used when a non-positive concurrency limit is given
+const DefaultConcurrency = 4
+
+// ForEach runs fn for every item with at most limit calls in flight.
+// All items are attempted unless ctx is cancelled; errors are joined in item order.
+func ForEach[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, index int, item T) error) error {
+	errs := run(ctx, items, limit, fn)
+	return joinErrors(errs)
+}
+
+// Map runs fn for every item with at most limit calls in flight and returns results in item order.
+// Results for failed items are left as the zero value; errors are joined in item order.
+func Map[T, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, index int, item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	errs := run(ctx, items, limit, func(ctx context.Context, i int, item T) error {
+		result, err := fn(ctx, i, item)
+		if err != nil {
+			return err
+		}
+		results[i] = result
+		return nil
+	})
+	return results, joinErrors(errs)
+}
+
+// run executes fn across items with a bounded worker pool and returns one error slot per item
+func run[T any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, index int, item T) error) []error {
+	if limit <= 0 {
+		limit = DefaultConcurrency
+	}
+
+	errs := make([]error, len(items))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(i int, item T) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(ctx, i, item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// joinErrors combines per-item errors, annotating each with its index
+func joinErrors(errs []error) error {
+	var joined []error
+	for i, err := range errs {
+		if err != nil {
+			joined = append(joined, fmt.Errorf("item %d: %w", i, err))
+		}
+	}
+	return errors.Join(joined...)
+}
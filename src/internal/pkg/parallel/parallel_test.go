@@ -0,0 +1,115 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestForEach tests bounded parallel processing
+func TestForEach(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("processes every item within the concurrency bound", func(t *testing.T) {
+		items := make([]int, 50)
+		for i := range items {
+			items[i] = i
+		}
+
+		var inFlight, maxInFlight, processed atomic.Int64
+		err := ForEach(ctx, items, 3, func(ctx context.Context, i int, item int) error {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				prev := maxInFlight.Load()
+				if current <= prev || maxInFlight.CompareAndSwap(prev, current) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			processed.Add(1)
+			return nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(50), processed.Load())
+		assert.LessOrEqual(t, maxInFlight.Load(), int64(3))
+		assert.Greater(t, maxInFlight.Load(), int64(1), "items should run in parallel")
+	})
+
+	t.Run("aggregates errors without aborting other items", func(t *testing.T) {
+		errOdd := errors.New("odd item")
+		var processed atomic.Int64
+
+		err := ForEach(ctx, []int{0, 1, 2, 3, 4}, 2, func(ctx context.Context, i int, item int) error {
+			processed.Add(1)
+			if item%2 == 1 {
+				return errOdd
+			}
+			return nil
+		})
+
+		require.Error(t, err)
+		assert.ErrorIs(t, err, errOdd)
+		assert.Contains(t, err.Error(), "item 1: odd item")
+		assert.Contains(t, err.Error(), "item 3: odd item")
+		assert.Equal(t, int64(5), processed.Load())
+	})
+
+	t.Run("default concurrency for non-positive limit", func(t *testing.T) {
+		var processed atomic.Int64
+		err := ForEach(ctx, []string{"a", "b", "c"}, 0, func(ctx context.Context, i int, item string) error {
+			processed.Add(1)
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), processed.Load())
+	})
+
+	t.Run("cancelled context skips remaining items", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		var processed atomic.Int64
+		err := ForEach(cancelled, []int{1, 2, 3}, 1, func(ctx context.Context, i int, item int) error {
+			processed.Add(1)
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int64(0), processed.Load())
+	})
+}
+
+// TestMap tests ordered results from parallel processing
+func TestMap(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("preserves item order", func(t *testing.T) {
+		items := []int{5, 4, 3, 2, 1}
+		results, err := Map(ctx, items, 5, func(ctx context.Context, i int, item int) (int, error) {
+			// Later items finish first
+			time.Sleep(time.Duration(item) * time.Millisecond)
+			return item * 10, nil
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{50, 40, 30, 20, 10}, results)
+	})
+
+	t.Run("failed items keep zero value", func(t *testing.T) {
+		results, err := Map(ctx, []int{1, 2, 3}, 2, func(ctx context.Context, i int, item int) (string, error) {
+			if item == 2 {
+				return "", errors.New("boom")
+			}
+			return "ok", nil
+		})
+
+		assert.Error(t, err)
+		assert.Equal(t, []string{"ok", "", "ok"}, results)
+	})
+}
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// bodyLimitKey is the echo context key of the request's limitedBody
+const bodyLimitKey = "body_limit"
+
+// BodyLimitMiddleware answers 413 when the request body is larger than limit bytes. NewEcho installs it
+// server-wide from server.max_body_bytes; routes that accept larger payloads can add their own, e.g.
+// g.POST("/import", h, server.BodyLimitMiddleware(10<<20)), which replaces the server-wide limit for that route.
+//
+// The body is read at most limit+1 bytes deep, so an oversized upload is never held in memory. A declared
+// Content-Length over the limit fails the first read. Once the limit is hit, c.Bind fails and whatever the
+// handler writes in response is discarded in favour of the 413. A zero limit disables the middleware.
+func BodyLimitMiddleware(limit int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if limit <= 0 {
+			return next
+		}
+		return func(c echo.Context) error {
+			// A route limit replaces the server-wide one installed further out
+			if body, ok := c.Get(bodyLimitKey).(*limitedBody); ok {
+				body.limit = limit
+				return next(c)
+			}
+
+			req := c.Request()
+			body := &limitedBody{ReadCloser: req.Body, limit: limit, declared: req.ContentLength}
+			req.Body = body
+			c.Set(bodyLimitKey, body)
+
+			res := c.Response()
+			original := res.Writer
+			writer := &bodyLimitWriter{ResponseWriter: original, body: body}
+			res.Writer = writer
+			defer func() { res.Writer = original }()
+
+			err := next(c)
+
+			if body.exceeded && !writer.started {
+				// Undo what echo recorded for the discarded response
+				res.Committed = false
+				res.Size = 0
+				return echo.ErrStatusRequestEntityTooLarge
+			}
+			return err
+		}
+	}
+}
+
+// limitedBody fails reads once more than limit bytes have been read or declared
+type limitedBody struct {
+	io.ReadCloser
+	limit    int64
+	declared int64 // Content-Length, -1 when unknown
+	read     int64
+	exceeded bool
+}
+
+// Read reads from the body until the limit is passed
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded || b.declared > b.limit {
+		b.exceeded = true
+		return 0, echo.ErrStatusRequestEntityTooLarge
+	}
+
+	// Read one byte past the limit to tell a body of exactly limit bytes from a larger one
+	if remaining := b.limit - b.read + 1; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	if b.read > b.limit {
+		b.exceeded = true
+		return 0, echo.ErrStatusRequestEntityTooLarge
+	}
+	return n, err
+}
+
+// bodyLimitWriter passes writes through until the body limit is hit. A response started before that is
+// completed; one started after it is dropped so the 413 can be sent.
+type bodyLimitWriter struct {
+	http.ResponseWriter
+	body    *limitedBody
+	started bool
+}
+
+// dropped reports whether the response has not started and the body was too large
+func (w *bodyLimitWriter) dropped() bool {
+	return !w.started && w.body.exceeded
+}
+
+// WriteHeader sends the status unless the body was too large
+func (w *bodyLimitWriter) WriteHeader(code int) {
+	if w.dropped() {
+		return
+	}
+	w.started = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write sends body bytes unless the body was too large before the response started
+func (w *bodyLimitWriter) Write(b []byte) (int, error) {
+	if w.dropped() {
+		return len(b), nil
+	}
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes a started response
+func (w *bodyLimitWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.started {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands the connection to the handler, e.g. for websockets
+func (w *bodyLimitWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.started = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *bodyLimitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ETag returns a strong entity tag for v: a quoted hash of its JSON encoding, so it changes whenever
+// any field of the response does
+func ETag(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("marshal entity: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// RespondWithETag writes v as JSON with its ETag header. A GET or HEAD whose If-None-Match lists the
+// current tag gets 304 Not Modified without a body instead.
+func RespondWithETag(c echo.Context, code int, v interface{}) error {
+	etag, err := ETag(v)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set("ETag", etag)
+
+	method := c.Request().Method
+	if (method == http.MethodGet || method == http.MethodHead) && etagMatches(c.Request().Header.Get("If-None-Match"), etag, true) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSON(code, v)
+}
+
+// IfMatch reports whether the request's If-Match precondition holds for current, the entity as the client
+// would have fetched it. A request without If-Match always passes.
+func IfMatch(c echo.Context, current interface{}) (bool, error) {
+	header := c.Request().Header.Get("If-Match")
+	if header == "" {
+		return true, nil
+	}
+	etag, err := ETag(current)
+	if err != nil {
+		return false, err
+	}
+	return etagMatches(header, etag, false), nil
+}
+
+// RespondPreconditionFailed writes the 412 response for an update whose If-Match no longer holds
+func RespondPreconditionFailed(c echo.Context) error {
+	return c.JSON(http.StatusPreconditionFailed, map[string]string{
+		"error": "Resource was modified since it was fetched",
+	})
+}
+
+// etagMatches reports whether header, a comma-separated list of entity tags or "*", includes etag.
+// Weak comparison (If-None-Match) ignores a W/ prefix; strong comparison (If-Match) never matches a weak tag.
+func etagMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
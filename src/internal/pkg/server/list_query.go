@@ -0,0 +1,117 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// DefaultListLimit is the page size used when a list request does not set limit
+	DefaultListLimit = 20
+	// MaxListLimit caps the page size a list request may ask for
+	MaxListLimit = 100
+)
+
+// ErrInvalidListQuery matches every *ListQueryError with errors.Is
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// ListQueryError is returned by ParseListQuery for a paging parameter that is not a non-negative integer, and
+// by RequireFlags for a flag that is not true or false. Its message is written as is in the 400 response,
+// e.g. "limit must be a non-negative integer".
+type ListQueryError struct {
+	Param string
+	Want  string // What the parameter must be; "a non-negative integer" when empty
+}
+
+func (e *ListQueryError) Error() string {
+	want := e.Want
+	if want == "" {
+		want = "a non-negative integer"
+	}
+	return fmt.Sprintf("%s must be %s", e.Param, want)
+}
+
+// Is reports whether target is ErrInvalidListQuery
+func (e *ListQueryError) Is(target error) bool {
+	return target == ErrInvalidListQuery
+}
+
+// ListQuery holds the paging, sort and filter parameters shared by list endpoints
+type ListQuery struct {
+	Limit   int               // Page size, DefaultListLimit when unset and at most MaxListLimit
+	Offset  int               // Rows to skip, never negative
+	Sort    string            // Raw sort expression, validated by the endpoint's allowlist
+	Filters map[string]string // Non-empty values of the filter parameters the endpoint accepts
+}
+
+// ParseListQuery reads limit, offset and sort, plus the named filter parameters, from the query string.
+// A missing or zero limit uses DefaultListLimit and a larger one is capped at MaxListLimit; a limit or
+// offset that is not a non-negative integer returns a *ListQueryError. Every list handler parses its
+// paging through here so they all default and reject alike.
+func ParseListQuery(c echo.Context, filters ...string) (ListQuery, error) {
+	q := ListQuery{
+		Limit:   DefaultListLimit,
+		Sort:    strings.TrimSpace(c.QueryParam("sort")),
+		Filters: make(map[string]string, len(filters)),
+	}
+
+	limit, err := nonNegativeParam(c, "limit")
+	if err != nil {
+		return ListQuery{}, err
+	}
+	switch {
+	case limit > MaxListLimit:
+		q.Limit = MaxListLimit
+	case limit > 0:
+		q.Limit = limit
+	}
+
+	if q.Offset, err = nonNegativeParam(c, "offset"); err != nil {
+		return ListQuery{}, err
+	}
+
+	for _, name := range filters {
+		if value := c.QueryParam(name); value != "" {
+			q.Filters[name] = value
+		}
+	}
+	return q, nil
+}
+
+// Filter returns the value of a filter parameter, or "" when it was not set
+func (q ListQuery) Filter(name string) string {
+	return q.Filters[name]
+}
+
+// Flag reports whether a filter parameter was set to "true"
+func (q ListQuery) Flag(name string) bool {
+	return q.Filters[name] == "true"
+}
+
+// RequireFlags returns a *ListQueryError for the first named filter that is set to anything but true or false,
+// so a mistyped flag is rejected instead of read as false
+func (q ListQuery) RequireFlags(names ...string) error {
+	for _, name := range names {
+		if value, ok := q.Filters[name]; ok && value != "true" && value != "false" {
+			return &ListQueryError{Param: name, Want: "true or false"}
+		}
+	}
+	return nil
+}
+
+// nonNegativeParam parses an optional non-negative integer query parameter, 0 when it is absent
+func nonNegativeParam(c echo.Context, name string) (int, error) {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		return 0, &ListQueryError{Param: name}
+	}
+	return value, nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseListQuery tests list query defaults, caps, filters and rejected values
+func TestParseListQuery(t *testing.T) {
+	parse := func(rawQuery string, filters ...string) (ListQuery, error) {
+		req := httptest.NewRequest(http.MethodGet, "/items?"+rawQuery, nil)
+		return ParseListQuery(echo.New().NewContext(req, httptest.NewRecorder()), filters...)
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		q, err := parse("")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultListLimit, q.Limit)
+		assert.Zero(t, q.Offset)
+		assert.Empty(t, q.Sort)
+		assert.Empty(t, q.Filters)
+
+		q, err = parse("limit=0")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultListLimit, q.Limit)
+	})
+
+	t.Run("explicit values and cap", func(t *testing.T) {
+		q, err := parse("limit=5&offset=40&sort=-price")
+		require.NoError(t, err)
+		assert.Equal(t, 5, q.Limit)
+		assert.Equal(t, 40, q.Offset)
+		assert.Equal(t, "-price", q.Sort)
+
+		q, err = parse("limit=5000")
+		require.NoError(t, err)
+		assert.Equal(t, MaxListLimit, q.Limit)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		q, err := parse("category=books&active=true&search=&other=x", "category", "active", "search")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"category": "books", "active": "true"}, q.Filters)
+		assert.Equal(t, "books", q.Filter("category"))
+		assert.True(t, q.Flag("active"))
+		assert.False(t, q.Flag("search"))
+		assert.Empty(t, q.Filter("other"), "undeclared parameters are not filters")
+	})
+
+	t.Run("flags", func(t *testing.T) {
+		q, err := parse("active=false&archived=true", "active", "archived", "deleted")
+		require.NoError(t, err)
+		assert.NoError(t, q.RequireFlags("active", "archived", "deleted"))
+
+		q, err = parse("active=yes", "active")
+		require.NoError(t, err)
+		err = q.RequireFlags("active")
+		assert.ErrorIs(t, err, ErrInvalidListQuery)
+		assert.EqualError(t, err, "active must be true or false")
+	})
+
+	t.Run("invalid numbers", func(t *testing.T) {
+		for _, rawQuery := range []string{"limit=abc", "limit=-1", "offset=1.5", "offset=-10", "limit=10&offset=x"} {
+			_, err := parse(rawQuery)
+			assert.ErrorIs(t, err, ErrInvalidListQuery, rawQuery)
+		}
+
+		_, err := parse("limit=abc")
+		assert.EqualError(t, err, "limit must be a non-negative integer")
+		_, err = parse("offset=-1")
+		assert.EqualError(t, err, "offset must be a non-negative integer")
+	})
+}
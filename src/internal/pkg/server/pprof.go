@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+)
+
+// PprofPath is the prefix the net/http/pprof handlers are served under
+const PprofPath = "/debug/pprof"
+
+// RegisterPprofRoutes serves the net/http/pprof handlers under /debug/pprof/, behind the given middleware.
+// Callers must guard the routes, e.g. with authentication and an admin role, as profiles expose internals.
+// CPU profiles and traces are cut short by server.request_timeout_seconds, so request them for less,
+// e.g. /debug/pprof/profile?seconds=10.
+func RegisterPprofRoutes(e *echo.Echo, guard ...echo.MiddlewareFunc) {
+	g := e.Group(PprofPath, guard...)
+	g.GET("/cmdline", echo.WrapHandler(http.HandlerFunc(pprof.Cmdline)))
+	g.GET("/profile", echo.WrapHandler(http.HandlerFunc(pprof.Profile)))
+	g.GET("/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	g.POST("/symbol", echo.WrapHandler(http.HandlerFunc(pprof.Symbol)))
+	g.GET("/trace", echo.WrapHandler(http.HandlerFunc(pprof.Trace)))
+	// The index also serves the named profiles, e.g. /debug/pprof/heap
+	g.GET("/", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+	g.GET("/*", echo.WrapHandler(http.HandlerFunc(pprof.Index)))
+}
+
+// RegisterPprof serves pprof to authenticated admins when server.pprof_enabled is set
+func RegisterPprof(e *echo.Echo, cfg *config.Config, authService *auth.Service, logger *zap.Logger) {
+	if !cfg.Server.PprofEnabled {
+		return
+	}
+	RegisterPprofRoutes(e, auth.JWTMiddleware(authService, logger), auth.RequireRole("admin"))
+	logger.Warn("pprof endpoints enabled for admins", zap.String("path", PprofPath+"/"))
+}
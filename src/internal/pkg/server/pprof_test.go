@@ -0,0 +1,76 @@
+// +build cgo
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"myapp/internal/testsupport"
+)
+
+// setupPprofServer creates a server with server.pprof_enabled set as given, and admin and user tokens
+func setupPprofServer(t *testing.T, enabled bool) (e *echo.Echo, adminToken, userToken string) {
+	db := testsupport.NewTestDB(t, testsupport.AuthModels()...)
+	authService := testsupport.NewTestAuthService(t, db)
+
+	cfg := mockConfig()
+	cfg.Server.PprofEnabled = enabled
+	logger := zaptest.NewLogger(t)
+	e = NewEcho(cfg, logger, mockDatabaseManager())
+	RegisterPprof(e, cfg, authService, logger)
+
+	adminToken = testsupport.NewTestAccessToken(t, authService, db, "admin@example.com", "admin")
+	userToken = testsupport.NewTestAccessToken(t, authService, db, "user@example.com", "user")
+	return e, adminToken, userToken
+}
+
+// getWithToken sends a GET request with an optional bearer token
+func getWithToken(e *echo.Echo, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRegisterPprof(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		e, adminToken, userToken := setupPprofServer(t, true)
+
+		rec := getWithToken(e, "/debug/pprof/", adminToken)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Types of profiles available")
+		assert.Contains(t, rec.Body.String(), "goroutine")
+
+		rec = getWithToken(e, "/debug/pprof/goroutine?debug=1", adminToken)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "goroutine profile")
+
+		rec = getWithToken(e, "/debug/pprof/cmdline", adminToken)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = getWithToken(e, "/debug/pprof/", "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"UNAUTHORIZED"`)
+
+		rec = getWithToken(e, "/debug/pprof/heap", userToken)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"FORBIDDEN"`)
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		e, adminToken, _ := setupPprofServer(t, false)
+
+		rec := getWithToken(e, "/debug/pprof/", adminToken)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"code":"NOT_FOUND"`)
+	})
+}
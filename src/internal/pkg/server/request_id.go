@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"myapp/internal/pkg/logger"
+	"myapp/internal/pkg/uuidv7"
+)
+
+// RequestIDKey is the Echo context key holding the request ID
+const RequestIDKey = "request_id"
+
+// maxRequestIDLength caps client-supplied request IDs so they cannot bloat logs
+const maxRequestIDLength = 128
+
+// contextKey is a custom type for context keys to avoid collisions
+type contextKey string
+
+// requestIDContextKey is the context.Context key holding the request ID
+const requestIDContextKey contextKey = "requestID"
+
+// WithRequestID adds a request ID to the context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey, requestID)
+}
+
+// GetRequestID returns the request ID stored in the context, or "" when there is none
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// RequestIDMiddleware gives every request a correlation ID. It keeps a valid incoming X-Request-ID
+// header or generates a UUIDv7, stores the ID in the Echo context and the request context, and
+// echoes it back in the X-Request-ID response header.
+func RequestIDMiddleware(generator *uuidv7.Generator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			requestID := req.Header.Get(echo.HeaderXRequestID)
+			if !validRequestID(requestID) {
+				id, err := generator.GenerateString()
+				if err != nil {
+					return err
+				}
+				requestID = id
+			}
+
+			c.Set(RequestIDKey, requestID)
+			ctx := logger.WithRequestID(WithRequestID(req.Context(), requestID), requestID)
+			c.SetRequest(req.WithContext(ctx))
+			c.Response().Header().Set(echo.HeaderXRequestID, requestID)
+
+			return next(c)
+		}
+	}
+}
+
+// ContextLoggerMiddleware seeds the request context with base so logger.FromContext returns it,
+// decorated with the request, tenant and user IDs the other middleware record
+func ContextLoggerMiddleware(base *zap.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			c.SetRequest(req.WithContext(logger.WithContext(req.Context(), base)))
+			return next(c)
+		}
+	}
+}
+
+// requestIDFromContext returns the request ID set by RequestIDMiddleware, or "" when it did not run
+func requestIDFromContext(c echo.Context) string {
+	requestID, _ := c.Get(RequestIDKey).(string)
+	return requestID
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII up to maxRequestIDLength
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
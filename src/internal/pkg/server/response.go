@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+// StatusClientClosedRequest is the non-standard status, taken from nginx, for a request the client abandoned
+const StatusClientClosedRequest = 499
+
+// RespondServerError writes the response for an error the handler does not map itself: 499 when the client
+// cancelled the request, 504 when its deadline passed, otherwise 500 with message
+func RespondServerError(c echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, database.ErrRequestCancelled):
+		return c.JSON(StatusClientClosedRequest, map[string]string{
+			"error": "Request cancelled",
+		})
+	case errors.Is(err, database.ErrTimeout):
+		return c.JSON(http.StatusGatewayTimeout, map[string]string{
+			"error": "Request timed out",
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": message,
+	})
+}
+
+// RespondDeleted writes the success response for a DELETE request according to the configured mode
+func RespondDeleted(c echo.Context, mode string, id interface{}, message string) error {
+	switch mode {
+	case config.DeleteResponseStructured:
+		return c.JSON(http.StatusOK, map[string]interface{}{
+			"deleted": true,
+			"id":      id,
+		})
+	case config.DeleteResponseMessage:
+		return c.JSON(http.StatusOK, map[string]string{
+			"message": message,
+		})
+	default:
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// SelectFields restricts the JSON object encoding of v to the given keys, for sparse fieldset responses.
+// A requested key the object omits, such as an empty omitempty field, is written as null. With no names
+// v is returned unchanged.
+func SelectFields(v interface{}, names []string) (interface{}, error) {
+	if len(names) == 0 {
+		return v, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("marshal response: %w", err)
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("select fields: %w", err)
+	}
+	selected := make(map[string]json.RawMessage, len(names))
+	for _, name := range names {
+		value, ok := all[name]
+		if !ok {
+			value = json.RawMessage("null")
+		}
+		selected[name] = value
+	}
+	return selected, nil
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"myapp/internal/pkg/config"
+)
+
+// SecurityHeadersMiddleware adds the headers in server.security_headers to every response, including errors.
+// They are filled in when the response is written and only where the handler left them unset, so a handler
+// can override any of them. Strict-Transport-Security is only sent on HTTPS requests, either served with TLS
+// or forwarded by a proxy with X-Forwarded-Proto: https. An empty value omits that header.
+func SecurityHeadersMiddleware(cfg config.SecurityHeadersConfig) echo.MiddlewareFunc {
+	headers := map[string]string{
+		echo.HeaderXContentTypeOptions:   cfg.ContentTypeOptions,
+		echo.HeaderXFrameOptions:         cfg.FrameOptions,
+		echo.HeaderReferrerPolicy:        cfg.ReferrerPolicy,
+		echo.HeaderContentSecurityPolicy: cfg.ContentSecurityPolicy,
+	}
+	for name, value := range headers {
+		if value == "" {
+			delete(headers, name)
+		}
+	}
+	hsts := hstsValue(cfg)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if len(headers) == 0 && hsts == "" {
+			return next
+		}
+		return func(c echo.Context) error {
+			https := c.Scheme() == "https"
+			res := c.Response()
+			res.Before(func() {
+				header := res.Header()
+				for name, value := range headers {
+					if header.Get(name) == "" {
+						header.Set(name, value)
+					}
+				}
+				if https && hsts != "" && header.Get(echo.HeaderStrictTransportSecurity) == "" {
+					header.Set(echo.HeaderStrictTransportSecurity, hsts)
+				}
+			})
+			return next(c)
+		}
+	}
+}
+
+// hstsValue builds the Strict-Transport-Security header, or "" when hsts_max_age is 0
+func hstsValue(cfg config.SecurityHeadersConfig) string {
+	if cfg.HSTSMaxAge <= 0 {
+		return ""
+	}
+	value := "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+	if cfg.HSTSIncludeSubdomains {
+		value += "; includeSubDomains"
+	}
+	if cfg.HSTSPreload {
+		value += "; preload"
+	}
+	return value
+}
@@ -2,16 +2,24 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
+	"sync/atomic"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"myapp/internal/pkg/config"
 	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/metrics"
 	custommw "myapp/internal/pkg/middleware"
+	"myapp/internal/pkg/uuidv7"
+	"myapp/internal/pkg/validation"
 )
 
 // NewEcho creates a new Echo server instance
@@ -25,22 +33,106 @@ func NewEcho(cfg *config.Config, logger *zap.Logger, dbManager *database.Databas
 	// Configure custom error handler
 	e.HTTPErrorHandler = customErrorHandler(logger)
 	
+	// c.Validate checks the validate tags of request structs
+	e.Validator = NewValidator()
+	
+	// c.RealIP only believes forwarding headers from trusted proxies, so clients cannot pick their rate limit key
+	e.IPExtractor = ipExtractor(cfg.Server.TrustedProxies)
+	
 	// Global middleware chain (order matters!)
+	e.Use(RequestIDMiddleware(uuidv7.NewGenerator())) // First, so every log line and error carries the ID
+	e.Use(ContextLoggerMiddleware(logger)) // logger.FromContext(ctx) in handlers and services
+	e.Use(tracingMiddleware()) // Spans are no-ops unless tracing.otlp_endpoint is set
+	e.Use(SecurityHeadersMiddleware(cfg.Server.SecurityHeaders)) // Filled in as the response is written, also on errors
+	if cfg.Server.MetricsEnabled {
+		useMetrics(e, logger, dbManager)
+	}
 	e.Use(middleware.Recover())
 	e.Use(requestLoggerMiddleware(logger))
+	e.Use(BodyLimitMiddleware(cfg.Server.MaxBodyBytes)) // 413 for oversized bodies before handlers bind them
+	if cfg.Server.CompressionEnabled {
+		e.Use(compressionMiddleware(cfg.Server.CompressionMinLength))
+	}
+	e.Use(TimeoutMiddleware(cfg.Server.RequestTimeout())) // Request budget for service/repository calls
 	e.Use(custommw.ContextMiddleware(dbManager)) // Tenant/Master context detection
-	e.Use(middleware.CORS())
+	e.Use(middleware.CORSWithConfig(corsConfig(cfg.Server.CORS)))
 	
 	return e
 }
 
+// useMetrics records HTTP and connection pool metrics in the default Prometheus registry and serves them
+// on /metrics. The middleware sits outside recover so panics are counted as 500s.
+func useMetrics(e *echo.Echo, logger *zap.Logger, dbManager *database.DatabaseManager) {
+	httpMetrics, err := metrics.NewHTTPMetrics(prometheus.DefaultRegisterer)
+	if err != nil {
+		logger.Error("Failed to register HTTP metrics, metrics disabled", zap.Error(err))
+		return
+	}
+	if err := metrics.RegisterDBStats(prometheus.DefaultRegisterer, dbManager); err != nil {
+		logger.Error("Failed to register database pool metrics", zap.Error(err))
+	}
+	e.Use(httpMetrics.Middleware())
+	e.GET(metrics.Path, metrics.Handler(prometheus.DefaultGatherer))
+}
+
+// ipExtractor reads the client IP from X-Forwarded-For when the request comes through one of the trusted
+// proxy ranges, and from the connection otherwise. Ranges are validated by ServerConfig.Validate.
+func ipExtractor(trustedProxies []string) echo.IPExtractor {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, cidr := range trustedProxies {
+		if _, ipNet, err := net.ParseCIDR(cidr); err == nil {
+			options = append(options, echo.TrustIPRange(ipNet))
+		}
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// compressedExtensions are paths whose content is already compressed, so gzipping them only costs CPU
+var compressedExtensions = []string{".gz", ".zip", ".br", ".png", ".jpg", ".jpeg", ".gif", ".webp", ".woff", ".woff2", ".mp4", ".pdf"}
+
+// compressionMiddleware gzips responses of at least minLength bytes for clients that send Accept-Encoding: gzip.
+// Requests for already-compressed files are skipped.
+func compressionMiddleware(minLength int) echo.MiddlewareFunc {
+	return middleware.GzipWithConfig(middleware.GzipConfig{
+		MinLength: minLength,
+		Skipper: func(c echo.Context) bool {
+			path := strings.ToLower(c.Request().URL.Path)
+			for _, ext := range compressedExtensions {
+				if strings.HasSuffix(path, ext) {
+					return true
+				}
+			}
+			return false
+		},
+	})
+}
+
+// corsConfig builds the CORS middleware settings; without configured origins any origin is allowed
+func corsConfig(cfg config.CORSConfig) middleware.CORSConfig {
+	origins := cfg.AllowedOrigins
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+	return middleware.CORSConfig{
+		AllowOrigins:     origins,
+		AllowMethods:     cfg.AllowedMethods,
+		AllowHeaders:     cfg.AllowedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+	}
+}
+
 // requestLoggerMiddleware creates a middleware for request logging
 func requestLoggerMiddleware(logger *zap.Logger) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			req := c.Request()
+			requestID := requestIDFromContext(c)
 			
 			logger.Info("Incoming request",
+				zap.String("request_id", requestID),
 				zap.String("method", req.Method),
 				zap.String("uri", req.RequestURI),
 				zap.String("remote_addr", req.RemoteAddr),
@@ -48,11 +140,14 @@ func requestLoggerMiddleware(logger *zap.Logger) echo.MiddlewareFunc {
 			
 			err := next(c)
 			
+			// Size counts bytes before compression
 			res := c.Response()
 			logger.Info("Request completed",
+				zap.String("request_id", requestID),
 				zap.String("method", req.Method),
 				zap.String("uri", req.RequestURI),
 				zap.Int("status", res.Status),
+				zap.Int64("size", res.Size),
 			)
 			
 			return err
@@ -60,12 +155,23 @@ func requestLoggerMiddleware(logger *zap.Logger) echo.MiddlewareFunc {
 	}
 }
 
+// errorCode converts an HTTP status to the machine-readable code in the error envelope, e.g. 404 -> NOT_FOUND
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "UNKNOWN_ERROR"
+	}
+	return strings.ToUpper(strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text))
+}
+
 // customErrorHandler handles errors and returns appropriate responses
 func customErrorHandler(logger *zap.Logger) echo.HTTPErrorHandler {
 	return func(err error, c echo.Context) {
 		code := http.StatusInternalServerError
 		message := "Internal server error"
 		
+		// Validation failures returned by handlers keep their per-field entries
+		var validationErr *validation.ValidationError
 		if he, ok := err.(*echo.HTTPError); ok {
 			code = he.Code
 			if msg, ok := he.Message.(string); ok {
@@ -73,11 +179,18 @@ func customErrorHandler(logger *zap.Logger) echo.HTTPErrorHandler {
 			} else if msg, ok := he.Message.(error); ok {
 				message = msg.Error()
 			}
+		} else if errors.As(err, &validationErr) {
+			code = http.StatusBadRequest
+			message = validation.Message
 		} else {
 			message = err.Error()
 		}
 		
+		requestID := requestIDFromContext(c)
+		tenantID, _ := database.GetTenantID(c.Request().Context())
 		logger.Error("Request error",
+			zap.String("request_id", requestID),
+			zap.String("tenant_id", tenantID),
 			zap.Int("status", code),
 			zap.String("message", message),
 			zap.String("path", c.Request().URL.Path),
@@ -88,20 +201,46 @@ func customErrorHandler(logger *zap.Logger) echo.HTTPErrorHandler {
 			if c.Request().Method == http.MethodHead {
 				c.NoContent(code)
 			} else {
-				c.JSON(code, map[string]interface{}{
-					"error":   message,
-					"status":  code,
-					"path":    c.Request().URL.Path,
-				})
+				body := map[string]interface{}{
+					"code":   errorCode(code),
+					"error":  message,
+					"status": code,
+					"path":   c.Request().URL.Path,
+				}
+				// Support fields are left out rather than sent empty
+				if requestID != "" {
+					body["request_id"] = requestID
+				}
+				if tenantID != "" {
+					body["tenant_id"] = tenantID
+				}
+				if validationErr != nil {
+					body["fields"] = validationErr.Fields
+				}
+				c.JSON(code, body)
 			}
 		}
 	}
 }
 
-// RegisterHooks registers server lifecycle hooks
+// RegisterHooks registers server lifecycle hooks.
+// On stop the server refuses new connections and lets in-flight requests finish within
+// server.shutdown_timeout_seconds. Hooks stop in reverse order, so database.Module must be
+// listed before server.Module for connections to close only after the server has drained.
 func RegisterHooks(lc fx.Lifecycle, e *echo.Echo, cfg *config.Config, logger *zap.Logger) {
 	addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
 	
+	// Track open connections so shutdown can report how many were still being drained
+	var openConns atomic.Int64
+	e.Server.ConnState = func(conn net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			openConns.Add(1)
+		case http.StateHijacked, http.StateClosed:
+			openConns.Add(-1)
+		}
+	}
+	
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			go func() {
@@ -113,9 +252,20 @@ func RegisterHooks(lc fx.Lifecycle, e *echo.Echo, cfg *config.Config, logger *za
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
-			logger.Info("Shutting down HTTP server")
+			timeout := cfg.Server.ShutdownTimeout()
+			logger.Info("Shutting down HTTP server",
+				zap.Int64("open_connections", openConns.Load()),
+				zap.Duration("timeout", timeout))
+			
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
 			if err := e.Shutdown(ctx); err != nil {
-				logger.Error("Server shutdown failed", zap.Error(err))
+				logger.Error("Server shutdown failed",
+					zap.Int64("open_connections", openConns.Load()),
+					zap.Error(err))
 				return err
 			}
 			logger.Info("HTTP server stopped successfully")
@@ -1,18 +1,42 @@
 package server
 
 import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"myapp/internal/pkg/config"
 	"myapp/internal/pkg/database"
+	applog "myapp/internal/pkg/logger"
+	"myapp/internal/pkg/uuidv7"
+	"myapp/internal/pkg/validation"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
@@ -240,6 +264,76 @@ func TestCustomErrorHandler_AlreadyCommitted(t *testing.T) {
 	})
 }
 
+// TestCustomErrorHandler_SupportFields tests request and tenant IDs in error responses
+func TestCustomErrorHandler_SupportFields(t *testing.T) {
+	e := NewEcho(mockConfig(), zaptest.NewLogger(t), mockDatabaseManager())
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusConflict, "conflict")
+	})
+
+	serve := func(t *testing.T, headers map[string]string) map[string]interface{} {
+		req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusConflict, rec.Code)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body
+	}
+
+	t.Run("tenant request error", func(t *testing.T) {
+		body := serve(t, map[string]string{"X-Tenant-ID": "tenant-42", echo.HeaderXRequestID: "req-tenant"})
+
+		assert.Equal(t, "req-tenant", body["request_id"])
+		assert.Equal(t, "tenant-42", body["tenant_id"])
+		assert.Equal(t, "conflict", body["error"])
+	})
+
+	t.Run("master request error", func(t *testing.T) {
+		body := serve(t, map[string]string{"X-Request-Type": "master", echo.HeaderXRequestID: "req-master"})
+
+		assert.Equal(t, "req-master", body["request_id"])
+		assert.NotContains(t, body, "tenant_id")
+	})
+
+	t.Run("fields omitted without middleware", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/fail", nil), rec)
+		customErrorHandler(zaptest.NewLogger(t))(errors.New("boom"), c)
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.NotContains(t, body, "request_id")
+		assert.NotContains(t, body, "tenant_id")
+	})
+}
+
+// TestCustomErrorHandler_ValidationError tests that a returned validation error keeps one entry per field
+func TestCustomErrorHandler_ValidationError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/test", nil), rec)
+	err := &validation.ValidationError{Fields: []validation.FieldError{
+		{Field: "email", Tag: "email", Message: "email must be a valid email address"},
+		{Field: "password", Tag: "min", Message: "password must be at least 8 characters"},
+	}}
+	customErrorHandler(zaptest.NewLogger(t))(fmt.Errorf("register: %w", err), c)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	var body struct {
+		Code   string                  `json:"code"`
+		Error  string                  `json:"error"`
+		Fields []validation.FieldError `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "BAD_REQUEST", body.Code)
+	assert.Equal(t, "validation failed", body.Error)
+	assert.Equal(t, err.Fields, body.Fields)
+}
+
 // TestNewEcho_Integration tests the full Echo server setup
 func TestNewEcho_Integration(t *testing.T) {
 	t.Run("server handles successful request", func(t *testing.T) {
@@ -331,6 +425,220 @@ func TestNewEcho_Integration(t *testing.T) {
 	})
 }
 
+// TestNewEcho_CORSOrigins tests that only configured origins are allowed
+func TestNewEcho_CORSOrigins(t *testing.T) {
+	cfg := mockConfig()
+	cfg.Server.CORS = config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders:   []string{echo.HeaderAuthorization, echo.HeaderContentType},
+		AllowCredentials: true,
+	}
+	e := NewEcho(cfg, zaptest.NewLogger(t), mockDatabaseManager())
+	e.GET("/test", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/test", nil)
+		req.Header.Set(echo.HeaderOrigin, origin)
+		req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("allowed origin is reflected", func(t *testing.T) {
+		rec := preflight("https://app.example.com")
+		assert.Equal(t, "https://app.example.com", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Equal(t, "true", rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+		assert.Equal(t, "GET,POST", rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+	})
+
+	t.Run("disallowed origin is not reflected", func(t *testing.T) {
+		rec := preflight("https://evil.example.com")
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowCredentials))
+	})
+}
+
+// TestNewEcho_ClientIP tests that forwarding headers only set the client IP when sent by a trusted proxy
+func TestNewEcho_ClientIP(t *testing.T) {
+	realIP := func(t *testing.T, trustedProxies []string, remoteAddr string) string {
+		cfg := mockConfig()
+		cfg.Server.TrustedProxies = trustedProxies
+		e := NewEcho(cfg, zaptest.NewLogger(t), mockDatabaseManager())
+		e.GET("/ip", func(c echo.Context) error {
+			return c.String(http.StatusOK, c.RealIP())
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set(echo.HeaderXForwardedFor, "203.0.113.9")
+		req.Header.Set(echo.HeaderXRealIP, "203.0.113.10")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec.Body.String()
+	}
+
+	t.Run("no trusted proxies uses the connection address", func(t *testing.T) {
+		assert.Equal(t, "10.0.0.5", realIP(t, nil, "10.0.0.5:4321"))
+	})
+
+	t.Run("trusted proxy forwards the client address", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.9", realIP(t, []string{"10.0.0.0/8"}, "10.0.0.5:4321"))
+	})
+
+	t.Run("untrusted peer cannot forward an address", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.7", realIP(t, []string{"10.0.0.0/8"}, "198.51.100.7:4321"))
+	})
+}
+
+// TestNewEcho_SecurityHeaders tests the security headers on normal and error responses
+func TestNewEcho_SecurityHeaders(t *testing.T) {
+	cfg := mockConfig()
+	cfg.Server.SecurityHeaders = config.SecurityHeadersConfig{
+		ContentTypeOptions:    "nosniff",
+		FrameOptions:          "DENY",
+		ReferrerPolicy:        "no-referrer",
+		ContentSecurityPolicy: "default-src 'none'",
+		HSTSMaxAge:            31536000,
+		HSTSIncludeSubdomains: true,
+	}
+	e := NewEcho(cfg, zaptest.NewLogger(t), mockDatabaseManager())
+	e.GET("/test", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
+	})
+	e.GET("/framed", func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderXFrameOptions, "SAMEORIGIN")
+		return c.NoContent(http.StatusOK)
+	})
+
+	serve := func(path string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("headers are set on a normal response", func(t *testing.T) {
+		rec := serve("/test", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+		assert.Equal(t, "DENY", rec.Header().Get(echo.HeaderXFrameOptions))
+		assert.Equal(t, "no-referrer", rec.Header().Get(echo.HeaderReferrerPolicy))
+		assert.Equal(t, "default-src 'none'", rec.Header().Get(echo.HeaderContentSecurityPolicy))
+	})
+
+	t.Run("hsts is omitted on plain http", func(t *testing.T) {
+		rec := serve("/test", nil)
+		assert.Empty(t, rec.Header().Get(echo.HeaderStrictTransportSecurity))
+	})
+
+	t.Run("hsts is sent on https", func(t *testing.T) {
+		rec := serve("/test", map[string]string{echo.HeaderXForwardedProto: "https"})
+		assert.Equal(t, "max-age=31536000; includeSubDomains", rec.Header().Get(echo.HeaderStrictTransportSecurity))
+	})
+
+	t.Run("headers are set on errors", func(t *testing.T) {
+		rec := serve("/missing", nil)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+	})
+
+	t.Run("handler headers are not clobbered", func(t *testing.T) {
+		rec := serve("/framed", nil)
+		assert.Equal(t, []string{"SAMEORIGIN"}, rec.Header().Values(echo.HeaderXFrameOptions))
+	})
+
+	t.Run("empty values omit the header", func(t *testing.T) {
+		cfg := mockConfig()
+		cfg.Server.SecurityHeaders = config.SecurityHeadersConfig{ContentTypeOptions: "nosniff"}
+		e := NewEcho(cfg, zaptest.NewLogger(t), mockDatabaseManager())
+		e.GET("/test", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set(echo.HeaderXForwardedProto, "https")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+		assert.Empty(t, rec.Header().Get(echo.HeaderXFrameOptions))
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentSecurityPolicy))
+		assert.Empty(t, rec.Header().Get(echo.HeaderStrictTransportSecurity))
+	})
+}
+
+// TestNewEcho_Compression tests gzip compression above the size threshold
+func TestNewEcho_Compression(t *testing.T) {
+	cfg := mockConfig()
+	cfg.Server.CompressionEnabled = true
+	cfg.Server.CompressionMinLength = 1024
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	e := NewEcho(cfg, zap.New(core), mockDatabaseManager())
+	large := strings.Repeat("product,", 1000)
+	e.GET("/large", func(c echo.Context) error {
+		return c.String(http.StatusOK, large)
+	})
+	e.GET("/small", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	e.GET("/logo.png", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, "image/png", []byte(large))
+	})
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set(echo.HeaderAcceptEncoding, acceptEncoding)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("large payload is compressed", func(t *testing.T) {
+		rec := get("/large", "gzip")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "gzip", rec.Header().Get(echo.HeaderContentEncoding))
+		assert.Less(t, rec.Body.Len(), len(large))
+
+		reader, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, large, string(body))
+
+		// The request log reports the uncompressed size
+		completed := logs.FilterMessage("Request completed").All()
+		require.NotEmpty(t, completed)
+		assert.Equal(t, int64(len(large)), completed[len(completed)-1].ContextMap()["size"])
+	})
+
+	t.Run("small payload is not compressed", func(t *testing.T) {
+		rec := get("/small", "gzip")
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+		assert.Equal(t, "ok", rec.Body.String())
+	})
+
+	t.Run("client without gzip support", func(t *testing.T) {
+		rec := get("/large", "")
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+		assert.Equal(t, large, rec.Body.String())
+	})
+
+	t.Run("already compressed content is skipped", func(t *testing.T) {
+		rec := get("/logo.png", "gzip")
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+		assert.Equal(t, len(large), rec.Body.Len())
+	})
+}
+
 // TestRegisterHooks tests lifecycle hooks registration
 func TestRegisterHooks(t *testing.T) {
 	t.Run("register hooks does not panic", func(t *testing.T) {
@@ -350,6 +658,83 @@ func TestRegisterHooks(t *testing.T) {
 	})
 }
 
+// TestRegisterHooks_GracefulShutdown tests that stopping the server drains in-flight requests
+func TestRegisterHooks_GracefulShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.NoError(t, listener.Close())
+
+	cfg := mockConfig()
+	cfg.Server.Host = "127.0.0.1"
+	cfg.Server.Port = port
+	cfg.Server.ShutdownTimeoutSeconds = 5
+
+	e := echo.New()
+	started := make(chan struct{})
+	var requestDone atomic.Bool
+	e.GET("/slow", func(c echo.Context) error {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		requestDone.Store(true)
+		return c.String(http.StatusOK, "done")
+	})
+
+	lc := fxtest.NewLifecycle(t)
+
+	// Stands in for the database hook, which is registered before the server's
+	var closedAfterDrain bool
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			closedAfterDrain = requestDone.Load()
+			return nil
+		},
+	})
+
+	RegisterHooks(lc, e, cfg, zaptest.NewLogger(t))
+	lc.RequireStart()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/slow", port)
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, 2*time.Second, 10*time.Millisecond)
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		results <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+
+	<-started
+	lc.RequireStop()
+
+	res := <-results
+	require.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, "done", res.body)
+	assert.True(t, closedAfterDrain, "later hooks must stop only after the server drained")
+
+	// The server no longer accepts connections
+	_, err = http.Get(url)
+	assert.Error(t, err)
+}
+
 // TestErrorHandlerJSONFormat tests error response JSON format
 func TestErrorHandlerJSONFormat(t *testing.T) {
 	t.Run("error response has correct structure", func(t *testing.T) {
@@ -371,3 +756,574 @@ func TestErrorHandlerJSONFormat(t *testing.T) {
 		assert.Contains(t, rec.Body.String(), "/test-path")
 	})
 }
+
+// slowQueryRepository simulates a repository whose query outlives the request budget
+type slowQueryRepository struct {
+	*database.BaseRepository[struct{}]
+}
+
+// CountForever runs a recursive query that only stops when the context is cancelled
+func (r *slowQueryRepository) CountForever(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.GetDB().WithContext(ctx).
+		Raw("WITH RECURSIVE seq(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM seq) SELECT count(*) FROM seq").
+		Scan(&count).Error
+	return count, err
+}
+
+// slowQueryService is the service layer between the handler and the repository
+type slowQueryService struct {
+	repo *slowQueryRepository
+}
+
+// Count delegates to the repository with the request context
+func (s *slowQueryService) Count(ctx context.Context) (int64, error) {
+	return s.repo.CountForever(ctx)
+}
+
+// TestRequestTimeout_Propagation verifies the global request budget flows from the
+// middleware through the service and repository down to the GORM query
+func TestRequestTimeout_Propagation(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+
+	svc := &slowQueryService{
+		repo: &slowQueryRepository{BaseRepository: database.NewBaseRepository[struct{}](db)},
+	}
+
+	cfg := mockConfig()
+	cfg.Server.RequestTimeoutSeconds = 1
+	e := NewEcho(cfg, zaptest.NewLogger(t), mockDatabaseManager())
+
+	var queryErr error
+	e.GET("/slow", func(c echo.Context) error {
+		_, queryErr = svc.Count(c.Request().Context())
+		return queryErr
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	rec := httptest.NewRecorder()
+
+	start := time.Now()
+	e.ServeHTTP(rec, req)
+	elapsed := time.Since(start)
+
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.Contains(t, rec.Body.String(), "request timed out")
+	require.Error(t, queryErr, "query should be cancelled by the request deadline")
+	assert.Less(t, elapsed, 5*time.Second, "query should stop shortly after the deadline")
+}
+
+// TestTimeoutMiddleware tests the request timeout middleware in isolation
+func TestTimeoutMiddleware(t *testing.T) {
+	t.Run("sets deadline on request context", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		h := TimeoutMiddleware(time.Second)(func(c echo.Context) error {
+			_, ok := c.Request().Context().Deadline()
+			assert.True(t, ok)
+			return c.NoContent(http.StatusOK)
+		})
+
+		assert.NoError(t, h(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("zero timeout disables middleware", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		h := TimeoutMiddleware(0)(func(c echo.Context) error {
+			_, ok := c.Request().Context().Deadline()
+			assert.False(t, ok)
+			return c.NoContent(http.StatusOK)
+		})
+
+		assert.NoError(t, h(c))
+	})
+}
+
+// TestTimeoutMiddleware_PerRoute tests route-level timeouts on top of the global request timeout
+func TestTimeoutMiddleware_PerRoute(t *testing.T) {
+	cfg := mockConfig()
+	cfg.Server.RequestTimeoutSeconds = 30
+	e := NewEcho(cfg, zaptest.NewLogger(t), mockDatabaseManager())
+
+	e.GET("/sleep", func(c echo.Context) error {
+		time.Sleep(200 * time.Millisecond)
+		return c.NoContent(http.StatusOK)
+	}, TimeoutMiddleware(50*time.Millisecond))
+	e.GET("/wait", func(c echo.Context) error {
+		<-c.Request().Context().Done()
+		return c.Request().Context().Err()
+	}, TimeoutMiddleware(50*time.Millisecond))
+	e.GET("/fast", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, TimeoutMiddleware(time.Second))
+	e.GET("/panic", func(c echo.Context) error {
+		panic("boom")
+	}, TimeoutMiddleware(time.Second))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("handler sleeping past the deadline gets 504", func(t *testing.T) {
+		rec := serve("/sleep")
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+		assert.Contains(t, rec.Body.String(), "request timed out")
+	})
+
+	t.Run("handler waiting on the context is released at the deadline", func(t *testing.T) {
+		start := time.Now()
+		rec := serve("/wait")
+		assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("handler within the deadline succeeds", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("/fast").Code)
+	})
+
+	t.Run("panic is still recovered", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, serve("/panic").Code)
+	})
+}
+
+// TestBodyLimitMiddleware tests the server-wide body limit and route overrides
+func TestBodyLimitMiddleware(t *testing.T) {
+	cfg := mockConfig()
+	cfg.Server.MaxBodyBytes = 64
+	e := NewEcho(cfg, zaptest.NewLogger(t), mockDatabaseManager())
+
+	// Handlers answer bind errors themselves, as the service handlers do
+	create := func(c echo.Context) error {
+		var req map[string]interface{}
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request body"})
+		}
+		return c.JSON(http.StatusCreated, req)
+	}
+	e.POST("/items", create)
+	e.POST("/import", create, BodyLimitMiddleware(1024))
+	e.POST("/tiny", create, BodyLimitMiddleware(8))
+
+	// body returns a JSON object of exactly size bytes
+	body := func(size int) string {
+		return `{"name":"` + strings.Repeat("x", size-11) + `"}`
+	}
+	serve := func(path, payload string, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if chunked {
+			req.ContentLength = -1
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("body under the limit is bound", func(t *testing.T) {
+		rec := serve("/items", body(32), false)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), "xxx")
+	})
+
+	t.Run("body of exactly the limit is bound", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, serve("/items", body(64), false).Code)
+		assert.Equal(t, http.StatusCreated, serve("/items", body(64), true).Code)
+	})
+
+	t.Run("declared length over the limit gets 413", func(t *testing.T) {
+		rec := serve("/items", body(65), false)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "Invalid request body")
+	})
+
+	t.Run("chunked body over the limit gets 413", func(t *testing.T) {
+		rec := serve("/items", body(4096), true)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	})
+
+	t.Run("route can raise the limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, serve("/import", body(512), false).Code)
+		assert.Equal(t, http.StatusCreated, serve("/import", body(512), true).Code)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, serve("/import", body(2048), false).Code)
+	})
+
+	t.Run("route can lower the limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusRequestEntityTooLarge, serve("/tiny", body(32), false).Code)
+	})
+
+	t.Run("zero limit disables the middleware", func(t *testing.T) {
+		cfg := mockConfig()
+		e := NewEcho(cfg, zaptest.NewLogger(t), mockDatabaseManager())
+		e.POST("/items", create)
+
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body(1<<20)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+}
+
+// TestNewEcho_Metrics tests that request metrics are recorded and served when enabled
+func TestNewEcho_Metrics(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		cfg := mockConfig()
+		cfg.Server.MetricsEnabled = true
+		e := NewEcho(cfg, zaptest.NewLogger(t), mockDatabaseManager())
+		e.GET("/metrics-test/:id", func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})
+
+		for i := 0; i < 3; i++ {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/metrics-test/%d", i), nil))
+			require.Equal(t, http.StatusOK, rec.Code)
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `http_requests_total{method="GET",route="/metrics-test/:id",status="200",tenant_type="master"} 3`)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		e := NewEcho(mockConfig(), zaptest.NewLogger(t), mockDatabaseManager())
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+// TestTracingMiddleware tests that each request gets a server span continuing the incoming trace
+func TestTracingMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	e := NewEcho(mockConfig(), zaptest.NewLogger(t), mockDatabaseManager())
+	e.GET("/traced/:id", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/traced/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	req.Header.Set("X-Tenant-ID", "tenant-a")
+	req.Header.Set(echo.HeaderXRequestID, "req-123")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /traced/:id", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "req-123", attrs["request_id"].AsString())
+	assert.Equal(t, "tenant-a", attrs["tenant_id"].AsString())
+	assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"].AsInt64())
+}
+
+// TestRespondDeleted tests the configurable DELETE success responses
+func TestRespondDeleted(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		expectedCode int
+		expectedBody string
+	}{
+		{"no content", config.DeleteResponseNoContent, http.StatusNoContent, ""},
+		{"default", "", http.StatusNoContent, ""},
+		{"structured", config.DeleteResponseStructured, http.StatusOK, `{"deleted":true,"id":42}`},
+		{"message", config.DeleteResponseMessage, http.StatusOK, `{"message":"Item deleted successfully"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodDelete, "/items/42", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := RespondDeleted(c, tt.mode, uint(42), "Item deleted successfully")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			if tt.expectedBody == "" {
+				assert.Empty(t, rec.Body.String())
+			} else {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestRespondServerError tests that cancelled and timed out statements are told apart from other failures
+func TestRespondServerError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{"cancelled", fmt.Errorf("get all: %w", database.ErrRequestCancelled), StatusClientClosedRequest, `{"error":"Request cancelled"}`},
+		{"timed out", fmt.Errorf("get all: %w", database.ErrTimeout), http.StatusGatewayTimeout, `{"error":"Request timed out"}`},
+		{"other error", errors.New("disk I/O error"), http.StatusInternalServerError, `{"error":"Failed to retrieve items"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/items", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := RespondServerError(c, tt.err, "Failed to retrieve items")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+// TestSelectFields tests that sparse fieldset responses keep only the requested keys
+func TestSelectFields(t *testing.T) {
+	type item struct {
+		ID    uint    `json:"id"`
+		Name  string  `json:"name"`
+		Price float64 `json:"price"`
+		Note  string  `json:"note,omitempty"`
+	}
+	v := &item{ID: 1, Name: "Keyboard", Price: 49.5}
+
+	t.Run("subset", func(t *testing.T) {
+		selected, err := SelectFields(v, []string{"name", "price"})
+		require.NoError(t, err)
+		data, err := json.Marshal(selected)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"name":"Keyboard","price":49.5}`, string(data))
+	})
+
+	t.Run("omitted field is null", func(t *testing.T) {
+		selected, err := SelectFields(v, []string{"id", "note"})
+		require.NoError(t, err)
+		data, err := json.Marshal(selected)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"id":1,"note":null}`, string(data))
+	})
+
+	t.Run("no names returns the value", func(t *testing.T) {
+		selected, err := SelectFields(v, nil)
+		require.NoError(t, err)
+		assert.Same(t, v, selected)
+	})
+}
+
+// TestRespondWithETag tests If-None-Match handling and the If-Match precondition
+func TestRespondWithETag(t *testing.T) {
+	entity := map[string]interface{}{"id": 1, "name": "Keyboard"}
+	etag, err := ETag(entity)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		method       string
+		ifNoneMatch  string
+		expectedCode int
+	}{
+		{"no precondition", http.MethodGet, "", http.StatusOK},
+		{"matching tag", http.MethodGet, etag, http.StatusNotModified},
+		{"weak matching tag in a list", http.MethodGet, `"other", W/` + etag, http.StatusNotModified},
+		{"wildcard", http.MethodGet, "*", http.StatusNotModified},
+		{"different tag", http.MethodGet, `"other"`, http.StatusOK},
+		{"not a read", http.MethodPut, etag, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/items/1", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rec := httptest.NewRecorder()
+			require.NoError(t, RespondWithETag(echo.New().NewContext(req, rec), http.StatusOK, entity))
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Equal(t, etag, rec.Header().Get("ETag"))
+		})
+	}
+
+	t.Run("if-match", func(t *testing.T) {
+		for value, want := range map[string]bool{"": true, etag: true, "*": true, `"other"`: false, "W/" + etag: false} {
+			req := httptest.NewRequest(http.MethodPut, "/items/1", nil)
+			if value != "" {
+				req.Header.Set("If-Match", value)
+			}
+			matched, err := IfMatch(echo.New().NewContext(req, httptest.NewRecorder()), entity)
+			require.NoError(t, err)
+			assert.Equal(t, want, matched, value)
+		}
+	})
+}
+
+// TestUnknownRoutes tests that unmatched paths and methods use the standard error envelope
+func TestUnknownRoutes(t *testing.T) {
+	e := NewEcho(mockConfig(), zaptest.NewLogger(t), mockDatabaseManager())
+	e.GET("/items", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.DELETE("/items", func(c echo.Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	decode := func(t *testing.T, rec *httptest.ResponseRecorder) map[string]interface{} {
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body
+	}
+
+	t.Run("unknown path returns 404 envelope", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/does-not-exist", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		body := decode(t, rec)
+		assert.Equal(t, "NOT_FOUND", body["code"])
+		assert.Equal(t, http.StatusText(http.StatusNotFound), body["error"])
+		assert.Equal(t, float64(http.StatusNotFound), body["status"])
+		assert.Equal(t, "/does-not-exist", body["path"])
+	})
+
+	t.Run("wrong method returns 405 with Allow", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/items", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		allow := rec.Header().Get(echo.HeaderAllow)
+		assert.Contains(t, allow, http.MethodGet)
+		assert.Contains(t, allow, http.MethodDelete)
+		assert.NotContains(t, allow, http.MethodPut)
+
+		body := decode(t, rec)
+		assert.Equal(t, "METHOD_NOT_ALLOWED", body["code"])
+		assert.Equal(t, "/items", body["path"])
+	})
+}
+
+// TestRequestIDMiddleware tests request ID generation and propagation
+func TestRequestIDMiddleware(t *testing.T) {
+	e := echo.New()
+	mw := RequestIDMiddleware(uuidv7.NewGenerator())
+
+	run := func(t *testing.T, header string) (*httptest.ResponseRecorder, string, string) {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		if header != "" {
+			req.Header.Set(echo.HeaderXRequestID, header)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		var fromEcho, fromCtx string
+		err := mw(func(c echo.Context) error {
+			fromEcho, _ = c.Get(RequestIDKey).(string)
+			fromCtx = GetRequestID(c.Request().Context())
+			return c.NoContent(http.StatusOK)
+		})(c)
+		require.NoError(t, err)
+		return rec, fromEcho, fromCtx
+	}
+
+	t.Run("generates an ID when the header is absent", func(t *testing.T) {
+		rec, fromEcho, fromCtx := run(t, "")
+
+		id := rec.Header().Get(echo.HeaderXRequestID)
+		parsed, err := uuid.Parse(id)
+		require.NoError(t, err)
+		assert.Equal(t, uuid.Version(7), parsed.Version())
+		assert.Equal(t, id, fromEcho)
+		assert.Equal(t, id, fromCtx)
+	})
+
+	t.Run("preserves an incoming ID", func(t *testing.T) {
+		rec, fromEcho, fromCtx := run(t, "client-trace-123")
+
+		assert.Equal(t, "client-trace-123", rec.Header().Get(echo.HeaderXRequestID))
+		assert.Equal(t, "client-trace-123", fromEcho)
+		assert.Equal(t, "client-trace-123", fromCtx)
+	})
+
+	t.Run("replaces an invalid incoming ID", func(t *testing.T) {
+		rec, _, _ := run(t, strings.Repeat("a", maxRequestIDLength+1))
+
+		_, err := uuid.Parse(rec.Header().Get(echo.HeaderXRequestID))
+		assert.NoError(t, err)
+	})
+
+	t.Run("error responses carry the request ID", func(t *testing.T) {
+		e := NewEcho(mockConfig(), zaptest.NewLogger(t), mockDatabaseManager())
+		req := httptest.NewRequest(http.MethodGet, "/does-not-exist", nil)
+		req.Header.Set(echo.HeaderXRequestID, "trace-404")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, "trace-404", rec.Header().Get(echo.HeaderXRequestID))
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "trace-404", body["request_id"])
+	})
+}
+
+// TestContextLoggerMiddleware tests that handlers log with the request and tenant IDs through logger.FromContext
+func TestContextLoggerMiddleware(t *testing.T) {
+	core, recorded := observer.New(zapcore.InfoLevel)
+	e := NewEcho(mockConfig(), zap.New(core), mockDatabaseManager())
+	e.GET("/orders", func(c echo.Context) error {
+		applog.FromContext(c.Request().Context()).Info("listing orders")
+		return c.NoContent(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-ctx-1")
+	req.Header.Set("X-Tenant-ID", "tenant-42")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	entries := recorded.FilterMessage("listing orders").All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "req-ctx-1", fields["request_id"])
+	assert.Equal(t, "tenant-42", fields["tenant_id"])
+	assert.NotContains(t, fields, "user_id")
+}
+
+// TestErrorCode tests status to error code conversion
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, "NOT_FOUND", errorCode(http.StatusNotFound))
+	assert.Equal(t, "METHOD_NOT_ALLOWED", errorCode(http.StatusMethodNotAllowed))
+	assert.Equal(t, "INTERNAL_SERVER_ERROR", errorCode(http.StatusInternalServerError))
+	assert.Equal(t, "IM_A_TEAPOT", errorCode(http.StatusTeapot))
+	assert.Equal(t, "UNKNOWN_ERROR", errorCode(999))
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TimeoutMiddleware bounds the request context with timeout so the deadline propagates through
+// services and repositories down to GORM queries, and answers 504 once the budget is spent.
+// NewEcho installs it server-wide from server.request_timeout_seconds; routes can add their own,
+// e.g. g.GET("/report", h, server.TimeoutMiddleware(5*time.Second)). Deadlines nest, so a route
+// timeout can only shorten the global one.
+//
+// The handler keeps running on the request goroutine, so panics still reach the recover middleware.
+// A handler that ignores the context finishes its work, but anything it writes after the deadline
+// is discarded in favour of the 504. A zero timeout disables the middleware.
+func TimeoutMiddleware(timeout time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if timeout <= 0 {
+			return next
+		}
+		return func(c echo.Context) error {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+			
+			res := c.Response()
+			original := res.Writer
+			writer := &deadlineWriter{ResponseWriter: original, ctx: ctx}
+			res.Writer = writer
+			defer func() { res.Writer = original }()
+			
+			err := next(c)
+			
+			// Report an exhausted budget as 504 regardless of how the handler wrapped the error
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) && !writer.started {
+				// Undo what echo recorded for the discarded late response
+				res.Committed = false
+				res.Size = 0
+				return echo.NewHTTPError(http.StatusGatewayTimeout, "request timed out")
+			}
+			return err
+		}
+	}
+}
+
+// deadlineWriter passes writes through until the context expires. A response started before
+// the deadline is completed; one started after it is dropped so the timeout can be reported.
+type deadlineWriter struct {
+	http.ResponseWriter
+	ctx     context.Context
+	started bool
+}
+
+// expired reports whether the response has not started and the deadline has passed
+func (w *deadlineWriter) expired() bool {
+	return !w.started && w.ctx.Err() != nil
+}
+
+// WriteHeader sends the status unless the deadline has passed
+func (w *deadlineWriter) WriteHeader(code int) {
+	if w.expired() {
+		return
+	}
+	w.started = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write sends body bytes unless the deadline passed before the response started
+func (w *deadlineWriter) Write(b []byte) (int, error) {
+	if w.expired() {
+		return 0, w.ctx.Err()
+	}
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes a started response
+func (w *deadlineWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok && w.started {
+		flusher.Flush()
+	}
+}
+
+// Hijack hands the connection to the handler, e.g. for websockets
+func (w *deadlineWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.started = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (w *deadlineWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,37 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"myapp/internal/pkg/validation"
+)
+
+// CustomValidator implements echo.Validator with go-playground/validator, reporting fields by their JSON names
+type CustomValidator struct {
+	validate *validator.Validate
+}
+
+// NewValidator creates the request validator registered on Echo by NewEcho
+func NewValidator() *CustomValidator {
+	return &CustomValidator{validate: validation.New()}
+}
+
+// Validate checks i against its validate tags. Field failures are returned as a *validation.ValidationError.
+func (cv *CustomValidator) Validate(i interface{}) error {
+	return validation.Check(cv.validate, i)
+}
+
+// RespondInvalid writes the 400 response for a request that failed c.Validate.
+// Field failures are listed one by one under "fields".
+func RespondInvalid(c echo.Context, err error) error {
+	var validationErr *validation.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.JSON(http.StatusBadRequest, validationErr)
+	}
+	return c.JSON(http.StatusBadRequest, map[string]string{
+		"error": err.Error(),
+	})
+}
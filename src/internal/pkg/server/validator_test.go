@@ -0,0 +1,143 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"myapp/internal/pkg/validation"
+)
+
+// validatedItem is a nested request item used by validatedRequest
+type validatedItem struct {
+	SKU string `json:"sku" validate:"required,min=3"`
+}
+
+// validatedRequest exercises the validate tags used by request structs
+type validatedRequest struct {
+	Name  string          `json:"name" validate:"required,min=3,max=10"`
+	Email string          `json:"email,omitempty" validate:"omitempty,email"`
+	Kind  string          `json:"kind" validate:"omitempty,oneof=basic premium"`
+	Price int64           `json:"price" validate:"gt=0"`
+	Stock int             `json:"stock" validate:"gte=0"`
+	Items []validatedItem `json:"items" validate:"omitempty,max=2,dive"`
+}
+
+// TestCustomValidator tests that validate tag failures are reported per field with JSON names
+func TestCustomValidator(t *testing.T) {
+	v := NewValidator()
+
+	tests := []struct {
+		name    string
+		req     validatedRequest
+		want    []validation.FieldError
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			req:  validatedRequest{Name: "Widget", Price: 1},
+		},
+		{
+			name:    "missing and out of range fields",
+			req:     validatedRequest{Stock: -1},
+			wantErr: true,
+			want: []validation.FieldError{
+				{Field: "name", Tag: "required", Message: "name is required"},
+				{Field: "price", Tag: "gt", Message: "price must be greater than 0"},
+				{Field: "stock", Tag: "gte", Message: "stock must be 0 or greater"},
+			},
+		},
+		{
+			name:    "string length, email and oneof",
+			req:     validatedRequest{Name: "This name is too long", Email: "nope", Kind: "gold", Price: 1},
+			wantErr: true,
+			want: []validation.FieldError{
+				{Field: "name", Tag: "max", Message: "name must be at most 10 characters"},
+				{Field: "email", Tag: "email", Message: "email must be a valid email address"},
+				{Field: "kind", Tag: "oneof", Message: "kind must be one of: basic, premium"},
+			},
+		},
+		{
+			name:    "nested items",
+			req:     validatedRequest{Name: "Widget", Price: 1, Items: []validatedItem{{SKU: "ABC"}, {SKU: "A"}}},
+			wantErr: true,
+			want: []validation.FieldError{
+				{Field: "items[1].sku", Tag: "min", Message: "items[1].sku must be at least 3 characters"},
+			},
+		},
+		{
+			name:    "too many items",
+			req:     validatedRequest{Name: "Widget", Price: 1, Items: make([]validatedItem, 3)},
+			wantErr: true,
+			want: []validation.FieldError{
+				{Field: "items", Tag: "max", Message: "items must contain at most 2 items"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(&tt.req)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+
+			var validationErr *validation.ValidationError
+			require.True(t, errors.As(err, &validationErr), "got %v", err)
+			assert.Equal(t, tt.want, validationErr.Fields)
+			assert.Contains(t, err.Error(), tt.want[0].Message)
+		})
+	}
+}
+
+// TestNewEcho_Validator tests that c.Validate works on NewEcho and RespondInvalid lists each field
+func TestNewEcho_Validator(t *testing.T) {
+	e := NewEcho(mockConfig(), zaptest.NewLogger(t), mockDatabaseManager())
+	e.POST("/items", func(c echo.Context) error {
+		var req validatedRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		if err := c.Validate(&req); err != nil {
+			return RespondInvalid(c, err)
+		}
+		return c.NoContent(http.StatusCreated)
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("valid body", func(t *testing.T) {
+		rec := post(`{"name":"Widget","price":5}`)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		rec := post(`{"name":"W","price":0}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var body struct {
+			Error  string                  `json:"error"`
+			Fields []validation.FieldError `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, validation.Message, body.Error)
+		assert.Equal(t, []validation.FieldError{
+			{Field: "name", Tag: "min", Message: "name must be at least 3 characters"},
+			{Field: "price", Tag: "gt", Message: "price must be greater than 0"},
+		}, body.Fields)
+	})
+}
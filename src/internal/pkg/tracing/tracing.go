@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"myapp/internal/pkg/config"
+)
+
+// Module exports the tracer provider. List it before database.Module and server.Module so it
+// stops last and spans from requests drained during shutdown are still exported.
+var Module = fx.Options(
+	fx.Provide(NewTracerProvider),
+	fx.Invoke(RegisterHooks),
+)
+
+// Propagator reads and writes W3C traceparent/tracestate and baggage headers
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// NewTracerProvider creates the tracer provider and installs it globally, so packages that call
+// otel.Tracer create spans through it. Without tracing.otlp_endpoint it is a no-op provider.
+func NewTracerProvider(cfg *config.Config) (trace.TracerProvider, error) {
+	otel.SetTextMapPropagator(Propagator)
+	if !cfg.Tracing.Enabled() {
+		provider := noop.NewTracerProvider()
+		otel.SetTracerProvider(provider)
+		return provider, nil
+	}
+
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Tracing.OTLPEndpoint)}
+	if cfg.Tracing.Insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	// The exporter connects lazily, so an unreachable collector does not block startup
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.Tracing.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("create tracing resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider, nil
+}
+
+// RegisterHooks flushes buffered spans and shuts the exporter down when the app stops
+func RegisterHooks(lc fx.Lifecycle, provider trace.TracerProvider, cfg *config.Config, logger *zap.Logger) {
+	sdkProvider, ok := provider.(*sdktrace.TracerProvider)
+	if !ok {
+		return
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.Info("Tracing enabled",
+				zap.String("otlp_endpoint", cfg.Tracing.OTLPEndpoint),
+				zap.Float64("sample_ratio", cfg.Tracing.SampleRatio))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := sdkProvider.Shutdown(ctx); err != nil {
+				logger.Error("Failed to shut down tracer provider", zap.Error(err))
+				return err
+			}
+			logger.Info("Tracer provider shut down")
+			return nil
+		},
+	})
+}
@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap/zaptest"
+	"myapp/internal/pkg/config"
+)
+
+func TestNewTracerProvider(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	t.Run("no-op without an endpoint", func(t *testing.T) {
+		provider, err := NewTracerProvider(&config.Config{})
+		require.NoError(t, err)
+		assert.IsType(t, noop.TracerProvider{}, provider)
+
+		_, span := otel.Tracer("test").Start(context.Background(), "span")
+		assert.False(t, span.IsRecording())
+	})
+
+	t.Run("exports to the configured collector and shuts down on stop", func(t *testing.T) {
+		cfg := &config.Config{Tracing: config.TracingConfig{
+			OTLPEndpoint: "127.0.0.1:1", Insecure: true, ServiceName: "myapp-test", SampleRatio: 1,
+		}}
+		provider, err := NewTracerProvider(cfg)
+		require.NoError(t, err)
+		sdkProvider, ok := provider.(*sdktrace.TracerProvider)
+		require.True(t, ok)
+
+		_, span := otel.Tracer("test").Start(context.Background(), "span")
+		assert.True(t, span.IsRecording())
+		span.End()
+
+		lc := fxtest.NewLifecycle(t)
+		RegisterHooks(lc, provider, cfg, zaptest.NewLogger(t))
+		lc.RequireStart()
+
+		// The collector is unreachable, so the final flush fails but the provider still stops
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		_ = lc.Stop(ctx)
+
+		_, after := sdkProvider.Tracer("test").Start(context.Background(), "after shutdown")
+		assert.False(t, after.IsRecording())
+	})
+}
@@ -1,12 +1,27 @@
 package uuidv7
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"sync"
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// deterministicEpoch is the timestamp of the first UUID from a deterministic generator
+var deterministicEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
 // Generator provides a wrapper around google/uuid's UUIDv7 generation
 // The underlying google/uuid library handles thread-safety and monotonic ordering internally
-type Generator struct{}
+type Generator struct {
+	mu    sync.Mutex       // serializes reads from rand and calls to clock
+	rand  io.Reader        // source of the random bits; nil uses crypto/rand
+	clock func() time.Time // source of the timestamp; nil uses the current time
+}
 
 // NewGenerator creates a new UUIDv7 generator instance
 // This is a lightweight wrapper around google/uuid's UUIDv7 implementation
@@ -14,12 +29,55 @@ func NewGenerator() *Generator {
 	return &Generator{}
 }
 
+// NewGeneratorWithReader creates a generator that takes its random bits from r instead of crypto/rand.
+// Timestamps still come from the current time; use NewDeterministicGenerator for fully reproducible IDs.
+// The IDs are only as unpredictable as r, so this is meant for tests.
+func NewGeneratorWithReader(r io.Reader) *Generator {
+	return &Generator{rand: r}
+}
+
+// NewDeterministicGenerator creates a generator for tests that returns the same sequence of valid
+// UUIDv7s for the same seed. Random bits come from a math/rand source seeded with seed, and the
+// timestamp starts at 2024-01-01 UTC and advances by one millisecond per UUID, so the sequence is
+// strictly increasing. Never use it for IDs that must be unguessable.
+func NewDeterministicGenerator(seed int64) *Generator {
+	next := deterministicEpoch
+	return &Generator{
+		rand: mathrand.New(mathrand.NewSource(seed)),
+		clock: func() time.Time {
+			now := next
+			next = next.Add(time.Millisecond)
+			return now
+		},
+	}
+}
+
 // Generate creates a new UUIDv7 using google/uuid's implementation
 // UUIDv7 includes a Unix timestamp in milliseconds (48 bits) and ensures
 // monotonic ordering even when multiple UUIDs are generated in the same millisecond.
 // The google/uuid library handles thread-safety and counter management internally.
+// Generators with an injected random source or clock draw from them under a lock.
 func (g *Generator) Generate() (uuid.UUID, error) {
-	return uuid.NewV7()
+	if g.rand == nil {
+		return uuid.NewV7()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.clock == nil {
+		return uuid.NewV7FromReader(g.rand)
+	}
+
+	var id uuid.UUID
+	if _, err := io.ReadFull(g.rand, id[6:]); err != nil {
+		return uuid.Nil, err
+	}
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(g.clock().UnixMilli()))
+	copy(id[:6], ms[2:])
+	id[6] = 0x70 | id[6]&0x0f // version 7
+	id[8] = 0x80 | id[8]&0x3f // RFC 4122 variant
+	return id, nil
 }
 
 // GenerateString generates a UUIDv7 and returns it as a string
@@ -95,3 +153,31 @@ func IsValid(s string) bool {
 	_, err := uuid.Parse(s)
 	return err == nil
 }
+
+// Timestamp extracts the creation time embedded in a UUIDv7: the first 48 bits hold
+// the Unix time in milliseconds. It returns an error for UUIDs of any other version.
+func Timestamp(id uuid.UUID) (time.Time, error) {
+	if id.Version() != 7 {
+		return time.Time{}, fmt.Errorf("uuidv7: %s is a version %d UUID, not version 7", id, id.Version())
+	}
+	var ms [8]byte
+	copy(ms[2:], id[:6])
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(ms[:]))), nil
+}
+
+// TimestampString parses s and extracts the creation time embedded in it
+func TimestampString(s string) (time.Time, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return Timestamp(id)
+}
+
+// Compare returns -1, 0 or +1 as a sorts before, equal to or after b.
+// UUIDv7s from this package sort in generation order: by timestamp first, and within the
+// same millisecond by the monotonic counter google/uuid keeps in the following bits.
+// Comparing the canonical strings gives the same order.
+func Compare(a, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
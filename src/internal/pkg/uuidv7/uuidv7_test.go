@@ -1,7 +1,10 @@
 package uuidv7
 
 import (
+	"bytes"
+	"errors"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/google/uuid"
@@ -222,6 +225,94 @@ func TestIsValid(t *testing.T) {
 	assert.False(t, IsValid("not-a-uuid"))
 }
 
+func TestTimestamp(t *testing.T) {
+	gen := NewGenerator()
+
+	before := time.Now().Truncate(time.Millisecond)
+	id, err := gen.Generate()
+	require.NoError(t, err)
+	after := time.Now()
+
+	ts, err := Timestamp(id)
+	require.NoError(t, err)
+	assert.False(t, ts.Before(before), "timestamp %v before %v", ts, before)
+	assert.False(t, ts.After(after), "timestamp %v after %v", ts, after)
+	assert.WithinDuration(t, after, ts, 5*time.Millisecond)
+
+	fromString, err := TimestampString(id.String())
+	require.NoError(t, err)
+	assert.True(t, ts.Equal(fromString))
+}
+
+func TestTimestampKnownValue(t *testing.T) {
+	// Example from RFC 9562 appendix A.6: 2022-02-22 19:22:22 UTC
+	ts, err := TimestampString("017f22e2-79b0-7cc3-98c4-dc0c0c07398f")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 2, 22, 19, 22, 22, 0, time.UTC), ts.UTC())
+}
+
+func TestTimestampRejectsOtherVersions(t *testing.T) {
+	_, err := Timestamp(uuid.New())
+	assert.Error(t, err, "version 4 UUIDs carry no timestamp")
+
+	_, err = TimestampString("not-a-uuid")
+	assert.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	gen := NewGenerator()
+
+	ids, err := gen.GenerateBatch(100)
+	require.NoError(t, err)
+	for i := 1; i < len(ids); i++ {
+		assert.Equal(t, -1, Compare(ids[i-1], ids[i]), "UUID %d should sort before UUID %d", i-1, i)
+		assert.Equal(t, 1, Compare(ids[i], ids[i-1]))
+	}
+	assert.Equal(t, 0, Compare(ids[0], ids[0]))
+}
+
+func TestDeterministicGenerator(t *testing.T) {
+	first, err := NewDeterministicGenerator(42).GenerateBatch(50)
+	require.NoError(t, err)
+	second, err := NewDeterministicGenerator(42).GenerateBatch(50)
+	require.NoError(t, err)
+	other, err := NewDeterministicGenerator(43).GenerateBatch(50)
+	require.NoError(t, err)
+
+	assert.Equal(t, first, second, "the same seed produces the same sequence")
+	for i := range first {
+		assert.NotEqual(t, first[i], other[i], "different seeds diverge at UUID %d", i)
+	}
+
+	for i, id := range first {
+		assert.Equal(t, uuid.Version(7), id.Version())
+		assert.Equal(t, uuid.RFC4122, id.Variant())
+
+		ts, err := Timestamp(id)
+		require.NoError(t, err)
+		assert.Equal(t, deterministicEpoch.Add(time.Duration(i)*time.Millisecond), ts.UTC())
+		if i > 0 {
+			assert.Equal(t, -1, Compare(first[i-1], id))
+		}
+	}
+}
+
+func TestNewGeneratorWithReader(t *testing.T) {
+	random := bytes.Repeat([]byte{0xab}, 16)
+	id, err := NewGeneratorWithReader(bytes.NewReader(random)).Generate()
+	require.NoError(t, err)
+	assert.Equal(t, uuid.Version(7), id.Version())
+	assert.Equal(t, uuid.RFC4122, id.Variant())
+	assert.Equal(t, byte(0xab), id[15], "random bits come from the reader")
+
+	ts, err := Timestamp(id)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), ts, time.Second, "timestamps still use the current time")
+
+	_, err = NewGeneratorWithReader(iotest.ErrReader(errors.New("no entropy"))).Generate()
+	assert.Error(t, err)
+}
+
 func TestHighFrequencyGeneration(t *testing.T) {
 	gen := NewGenerator()
 
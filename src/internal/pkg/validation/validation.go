@@ -0,0 +1,136 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Message is the "error" value of a validation failure response
+const Message = "validation failed"
+
+// FieldError describes one field that failed validation
+type FieldError struct {
+	Field   string `json:"field"`   // JSON name of the field, e.g. name or products[0].sku
+	Tag     string `json:"tag"`     // The failed validate tag, e.g. required or max
+	Message string `json:"message"` // Human-readable reason
+}
+
+// ValidationError lists every field of a request that failed its validate tags.
+// It is serialized as {"error":"validation failed","fields":[...]}.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Message
+	}
+	return Message + ": " + strings.Join(messages, "; ")
+}
+
+// MarshalJSON writes the error as the validation failure response body
+func (e *ValidationError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Error  string       `json:"error"`
+		Fields []FieldError `json:"fields"`
+	}{Message, e.Fields})
+}
+
+// FromValidationErrors converts go-playground field failures into a *ValidationError, one entry per field
+func FromValidationErrors(errs validator.ValidationErrors) *ValidationError {
+	fields := make([]FieldError, len(errs))
+	for i, fe := range errs {
+		field := fieldPath(fe)
+		fields[i] = FieldError{
+			Field:   field,
+			Tag:     fe.Tag(),
+			Message: fieldMessage(field, fe),
+		}
+	}
+	return &ValidationError{Fields: fields}
+}
+
+// New creates a validator that reports fields by their JSON names
+func New() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// defaultValidator backs Struct; validator.Validate caches struct metadata and is safe for concurrent use
+var defaultValidator = New()
+
+// Struct checks s against its validate tags. Field failures are returned as a *ValidationError.
+// It is meant for handlers that run without the Echo validator, e.g. the auth package.
+func Struct(s interface{}) error {
+	return Check(defaultValidator, s)
+}
+
+// Check validates s with v. Field failures are returned as a *ValidationError.
+func Check(v *validator.Validate, s interface{}) error {
+	err := v.Struct(s)
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+	return FromValidationErrors(fieldErrs)
+}
+
+// fieldPath returns the field's JSON path without the name of the top-level struct
+func fieldPath(fe validator.FieldError) string {
+	namespace := fe.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// fieldMessage explains a failed validate tag
+func fieldMessage(field string, fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "min", "max":
+		bound := "at least"
+		if fe.Tag() == "max" {
+			bound = "at most"
+		}
+		switch fe.Kind() {
+		case reflect.String:
+			return fmt.Sprintf("%s must be %s %s characters", field, bound, fe.Param())
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("%s must contain %s %s items", field, bound, fe.Param())
+		default:
+			return fmt.Sprintf("%s must be %s %s", field, bound, fe.Param())
+		}
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be %s or greater", field, fe.Param())
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, fe.Param())
+	case "lte":
+		return fmt.Sprintf("%s must be %s or less", field, fe.Param())
+	default:
+		return fmt.Sprintf("%s failed the %s rule", field, fe.Tag())
+	}
+}
@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signupRequest has several fields that can fail at once
+type signupRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8"`
+	Name     string `json:"display_name" validate:"max=5"`
+	Internal string `json:"-" validate:"omitempty,max=1"`
+}
+
+// TestStruct tests that every invalid field is reported as its own entry under its JSON name
+func TestStruct(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, Struct(&signupRequest{Email: "a@example.com", Password: "longenough"}))
+	})
+
+	t.Run("multiple invalid fields", func(t *testing.T) {
+		err := Struct(&signupRequest{Email: "nope", Password: "short", Name: "too long"})
+
+		var validationErr *ValidationError
+		require.True(t, errors.As(err, &validationErr), "got %v", err)
+		assert.Equal(t, []FieldError{
+			{Field: "email", Tag: "email", Message: "email must be a valid email address"},
+			{Field: "password", Tag: "min", Message: "password must be at least 8 characters"},
+			{Field: "display_name", Tag: "max", Message: "display_name must be at most 5 characters"},
+		}, validationErr.Fields)
+		assert.Equal(t, "validation failed: email must be a valid email address; "+
+			"password must be at least 8 characters; display_name must be at most 5 characters", err.Error())
+	})
+
+	t.Run("non-struct input", func(t *testing.T) {
+		err := Struct("not a struct")
+		require.Error(t, err)
+		var validationErr *ValidationError
+		assert.False(t, errors.As(err, &validationErr))
+	})
+}
+
+// TestValidationError_MarshalJSON tests the response body of a validation failure
+func TestValidationError_MarshalJSON(t *testing.T) {
+	err := &ValidationError{Fields: []FieldError{
+		{Field: "email", Tag: "required", Message: "email is required"},
+		{Field: "password", Tag: "required", Message: "password is required"},
+	}}
+
+	data, marshalErr := json.Marshal(err)
+	require.NoError(t, marshalErr)
+	assert.JSONEq(t, `{
+		"error": "validation failed",
+		"fields": [
+			{"field": "email", "tag": "required", "message": "email is required"},
+			{"field": "password", "tag": "required", "message": "password is required"}
+		]
+	}`, string(data))
+}
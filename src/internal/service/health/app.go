@@ -3,8 +3,11 @@ package health
 import (
 	"go.uber.org/fx"
 	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/healthcheck"
 	"myapp/internal/pkg/logger"
 	"myapp/internal/pkg/server"
+	"myapp/internal/pkg/tracing"
 )
 
 // AppModule combines infrastructure and health service modules
@@ -12,9 +15,12 @@ var AppModule = fx.Options(
 	// Infrastructure modules
 	config.Module,
 	logger.Module,
+	tracing.Module, // Before database and server so it stops last and flushes their spans
+	database.Module,
 	server.Module,
+	healthcheck.Module,
 	
-	// Health service module (no database needed)
+	// Health service module (master database used for tenant checks)
 	Module,
 	
 	// Router registration
@@ -6,19 +6,26 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/healthcheck"
 )
 
 // Handler handles health check requests
 type Handler struct {
+	config    *config.Config
 	logger    *zap.Logger
-	startTime time.Time
+	dbManager *database.DatabaseManager
+	service   *healthcheck.Service
 }
 
 // NewHandler creates a new health check handler
-func NewHandler(logger *zap.Logger) *Handler {
+func NewHandler(cfg *config.Config, logger *zap.Logger, dbManager *database.DatabaseManager, service *healthcheck.Service) *Handler {
 	return &Handler{
+		config:    cfg,
 		logger:    logger,
-		startTime: time.Now(),
+		dbManager: dbManager,
+		service:   service,
 	}
 }
 
@@ -31,16 +38,11 @@ func (h *Handler) Health(c echo.Context) error {
 	})
 }
 
-// Ready returns the readiness status of the service
+// Ready returns the readiness status of the service with the status and latency of every
+// dependency check. It returns 503 only when a critical dependency is down.
 func (h *Handler) Ready(c echo.Context) error {
-	uptime := time.Since(h.startTime)
-	
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"status":  "ready",
-		"service": "myapp",
-		"uptime":  uptime.String(),
-		"time":    time.Now().UTC(),
-	})
+	report := h.service.Check(c.Request().Context())
+	return c.JSON(report.HTTPStatus(), report)
 }
 
 // Live returns the liveness status of the service
@@ -50,3 +52,39 @@ func (h *Handler) Live(c echo.Context) error {
 		"time":   time.Now().UTC(),
 	})
 }
+
+// Tenants returns the reachability of every active tenant database. The endpoint is unauthenticated, so
+// ping errors, which can name hosts, users and DSNs, are only logged.
+func (h *Handler) Tenants(c echo.Context) error {
+	results, err := h.dbManager.TenantConnManager.PingAllTenants(c.Request().Context())
+	if err != nil {
+		h.logger.Error("Failed to list tenants for health check", zap.Error(err))
+		return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"status": "unhealthy",
+			"error":  "Failed to list tenants",
+			"time":   time.Now().UTC(),
+		})
+	}
+
+	status := "healthy"
+	code := http.StatusOK
+	tenants := make(map[string]map[string]string, len(results))
+	for tenantID, pingErr := range results {
+		if pingErr != nil {
+			status = "degraded"
+			code = http.StatusServiceUnavailable
+			tenants[tenantID] = map[string]string{"status": "down"}
+			h.logger.Warn("Tenant database unreachable",
+				zap.String("tenant_id", tenantID),
+				zap.Error(pingErr))
+			continue
+		}
+		tenants[tenantID] = map[string]string{"status": "up"}
+	}
+
+	return c.JSON(code, map[string]interface{}{
+		"status":  status,
+		"tenants": tenants,
+		"time":    time.Now().UTC(),
+	})
+}
@@ -0,0 +1,154 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/healthcheck"
+)
+
+// setupTenantHealthHandler creates a handler whose master database holds the given tenants
+func setupTenantHealthHandler(t *testing.T, tenants ...*database.Tenant) *Handler {
+	masterDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	// Tenants are pinged concurrently; a second :memory: connection would see an empty database
+	sqlDB, err := masterDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, masterDB.AutoMigrate(&database.Tenant{}))
+	for _, tenant := range tenants {
+		require.NoError(t, masterDB.Create(tenant).Error)
+	}
+
+	logger := zaptest.NewLogger(t)
+	connManager := database.NewTenantConnectionManager(masterDB, logger)
+	t.Cleanup(func() { connManager.CloseAll() })
+
+	cfg := &config.Config{}
+	require.NoError(t, cfg.Health.Validate())
+
+	dbManager := &database.DatabaseManager{
+		MasterDB:          masterDB,
+		TenantConnManager: connManager,
+	}
+	return NewHandler(cfg, logger, dbManager, healthcheck.NewService(cfg, logger, dbManager))
+}
+
+// TestHandler_Tenants tests the tenant database health endpoint
+func TestHandler_Tenants(t *testing.T) {
+	e := echo.New()
+
+	t.Run("all tenants reachable", func(t *testing.T) {
+		h := setupTenantHealthHandler(t,
+			&database.Tenant{ID: "ok", Name: "OK", IsActive: true, DBType: "sqlite", Cnn: ":memory:"},
+		)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/health/tenants", nil), rec)
+		require.NoError(t, h.Tenants(c))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "healthy", body["status"])
+		assert.Equal(t, map[string]interface{}{"status": "up"}, body["tenants"].(map[string]interface{})["ok"])
+	})
+
+	t.Run("unreachable tenant degrades status", func(t *testing.T) {
+		h := setupTenantHealthHandler(t,
+			&database.Tenant{ID: "ok", Name: "OK", IsActive: true, DBType: "sqlite", Cnn: ":memory:"},
+			&database.Tenant{ID: "bad", Name: "Bad", IsActive: true, DBType: "sqlite", Cnn: "/nonexistent/dir/tenant.db"},
+		)
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/health/tenants", nil), rec)
+		require.NoError(t, h.Tenants(c))
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, "degraded", body["status"])
+
+		tenants := body["tenants"].(map[string]interface{})
+		assert.Equal(t, "up", tenants["ok"].(map[string]interface{})["status"])
+		assert.Equal(t, map[string]interface{}{"status": "down"}, tenants["bad"])
+		assert.NotContains(t, rec.Body.String(), "/nonexistent/dir")
+	})
+}
+
+// TestHandler_Ready tests readiness with critical and non-critical dependencies
+func TestHandler_Ready(t *testing.T) {
+	e := echo.New()
+
+	ready := func(t *testing.T, h *Handler) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/health/ready", nil), rec)
+		require.NoError(t, h.Ready(c))
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec.Code, body
+	}
+	okTenant := &database.Tenant{ID: "ok", Name: "OK", IsActive: true, DBType: "sqlite", Cnn: ":memory:"}
+	badTenant := &database.Tenant{ID: "bad", Name: "Bad", IsActive: true, DBType: "sqlite", Cnn: "/nonexistent/dir/tenant.db"}
+
+	t.Run("all dependencies up", func(t *testing.T) {
+		h := setupTenantHealthHandler(t, okTenant)
+
+		code, body := ready(t, h)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "ready", body["status"])
+		assert.Empty(t, body["warnings"])
+		checks := body["checks"].(map[string]interface{})
+		assert.Equal(t, "up", checks[config.HealthDependencyMasterDatabase].(map[string]interface{})["status"])
+		assert.Equal(t, "up", checks[config.HealthDependencyTenantDatabases].(map[string]interface{})["status"])
+	})
+
+	t.Run("non-critical failure is degraded but ready", func(t *testing.T) {
+		h := setupTenantHealthHandler(t, okTenant, badTenant)
+
+		code, body := ready(t, h)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "degraded", body["status"])
+		require.Len(t, body["warnings"], 1)
+		assert.Contains(t, body["warnings"].([]interface{})[0], config.HealthDependencyTenantDatabases)
+
+		tenants := body["checks"].(map[string]interface{})[config.HealthDependencyTenantDatabases].(map[string]interface{})
+		assert.Equal(t, "down", tenants["status"])
+		assert.Equal(t, false, tenants["critical"])
+		assert.Equal(t, "1 of 2 tenant databases unreachable", tenants["error"])
+	})
+
+	t.Run("critical tenant failure is unavailable", func(t *testing.T) {
+		h := setupTenantHealthHandler(t, okTenant, badTenant)
+		h.config.Health.CriticalDependencies = []string{config.HealthDependencyMasterDatabase, config.HealthDependencyTenantDatabases}
+
+		code, body := ready(t, h)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "unavailable", body["status"])
+	})
+
+	t.Run("master database down is unavailable", func(t *testing.T) {
+		h := setupTenantHealthHandler(t)
+		sqlDB, err := h.dbManager.MasterDB.DB()
+		require.NoError(t, err)
+		require.NoError(t, sqlDB.Close())
+
+		code, body := ready(t, h)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "unavailable", body["status"])
+		master := body["checks"].(map[string]interface{})[config.HealthDependencyMasterDatabase].(map[string]interface{})
+		assert.Equal(t, "down", master["status"])
+		assert.Equal(t, true, master["critical"])
+	})
+}
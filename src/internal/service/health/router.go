@@ -17,6 +17,7 @@ func RegisterHealthRoutes(
 	e.GET("/health", healthHandler.Health)
 	e.GET("/health/ready", healthHandler.Ready)
 	e.GET("/health/live", healthHandler.Live)
+	e.GET("/health/tenants", healthHandler.Tenants)
 	
 	logger.Info("Health routes registered successfully")
 }
@@ -2,10 +2,15 @@ package master
 
 import (
 	"go.uber.org/fx"
+	"myapp/internal/pkg/cache"
 	"myapp/internal/pkg/config"
 	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/healthcheck"
 	"myapp/internal/pkg/logger"
+	"myapp/internal/pkg/outbox"
+	custommw "myapp/internal/pkg/middleware"
 	"myapp/internal/pkg/server"
+	"myapp/internal/pkg/tracing"
 	authmodule "myapp/internal/pkg/auth"
 	mastermodule "myapp/internal/service/master/module"
 	masterrouter "myapp/internal/service/master/router"
@@ -16,8 +21,12 @@ var AppModule = fx.Options(
 	// Infrastructure modules
 	config.Module,
 	logger.Module,
+	tracing.Module, // Before database and server so it stops last and flushes their spans
 	database.Module,
 	server.Module,
+	custommw.Module, // Audit log repository and rate limit store for the routers
+	cache.Module,    // Redis cache for master reads by ID, nil when disabled
+	healthcheck.Module,
 	
 	// Auth module (included in master service)
 	authmodule.Module,
@@ -25,6 +34,10 @@ var AppModule = fx.Options(
 	// Master service module
 	mastermodule.Module,
 	
+	// Publishes master events from the master database's outbox
+	outbox.MasterModule,
+	
 	// Router registration
 	fx.Invoke(masterrouter.RegisterMasterRoutes),
+	fx.Invoke(server.RegisterPprof), // Admin-only profiling when server.pprof_enabled is set
 )
@@ -4,22 +4,29 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/labstack/echo/v4"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/healthcheck"
+	"myapp/internal/pkg/server"
 	"myapp/internal/service/master/model"
 	"myapp/internal/service/master/service"
 )
 
 // Handler handles master HTTP requests
 type Handler struct {
-	service *service.Service
+	service        *service.Service
+	health         *healthcheck.Service
+	deleteResponse string
 }
 
 // NewHandler creates a new master handler
-func NewHandler(service *service.Service) *Handler {
+func NewHandler(service *service.Service, health *healthcheck.Service, cfg *config.Config) *Handler {
 	return &Handler{
-		service: service,
+		service:        service,
+		health:         health,
+		deleteResponse: cfg.Server.DeleteResponse,
 	}
 }
 
@@ -34,9 +41,7 @@ func (h *Handler) CreateMaster(c echo.Context) error {
 	}
 
 	if err := c.Validate(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return server.RespondInvalid(c, err)
 	}
 
 	master, err := h.service.CreateMaster(c.Request().Context(), &req)
@@ -46,9 +51,7 @@ func (h *Handler) CreateMaster(c echo.Context) error {
 				"error": err.Error(),
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to create master record",
-		})
+		return server.RespondServerError(c, err, "Failed to create master record")
 	}
 
 	return c.JSON(http.StatusCreated, master.ToResponse())
@@ -56,6 +59,8 @@ func (h *Handler) CreateMaster(c echo.Context) error {
 
 // GetMaster handles retrieving a master record by ID
 // GET /api/masters/:id
+// The response carries an ETag; sending it back in If-None-Match returns 304 while the record is unchanged.
+// Soft-deleted records are not found unless an admin sets includeDeleted=true.
 func (h *Handler) GetMaster(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -64,51 +69,66 @@ func (h *Handler) GetMaster(c echo.Context) error {
 		})
 	}
 
-	master, err := h.service.GetMasterByID(c.Request().Context(), uint(id))
+	query, err := server.ParseListQuery(c, "includeDeleted")
+	if err == nil {
+		err = query.RequireFlags("includeDeleted")
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	includeDeleted := query.Flag("includeDeleted")
+	if includeDeleted && !isAdmin(c) {
+		return respondDeletedForbidden(c)
+	}
+
+	master, err := h.service.GetMasterByID(c.Request().Context(), uint(id), includeDeleted)
 	if err != nil {
 		if errors.Is(err, service.ErrMasterNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{
 				"error": "Master record not found",
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to get master record",
-		})
+		return server.RespondServerError(c, err, "Failed to get master record")
 	}
 
-	return c.JSON(http.StatusOK, master.ToResponse())
+	return server.RespondWithETag(c, http.StatusOK, master.ToResponse())
 }
 
 // GetMasters handles retrieving all master records
 // GET /api/masters
+// Soft-deleted records are left out unless an admin sets includeDeleted=true.
 func (h *Handler) GetMasters(c echo.Context) error {
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
-	masterType := c.QueryParam("type")
-	search := c.QueryParam("search")
-	activeOnly := c.QueryParam("active") == "true"
-
-	if limit <= 0 {
-		limit = 20
+	query, err := server.ParseListQuery(c, "type", "search", "active", "includeDeleted")
+	if err == nil {
+		err = query.RequireFlags("active", "includeDeleted")
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
 	}
 
 	var masters []*model.Master
-	var err error
-
-	if search != "" {
-		masters, err = h.service.SearchMasters(c.Request().Context(), search, limit, offset)
-	} else if masterType != "" {
-		masters, err = h.service.GetMastersByType(c.Request().Context(), masterType, limit, offset)
-	} else if activeOnly {
-		masters, err = h.service.GetActiveMasters(c.Request().Context(), limit, offset)
+	limit, offset := query.Limit, query.Offset
+	includeDeleted := query.Flag("includeDeleted")
+	if includeDeleted && !isAdmin(c) {
+		return respondDeletedForbidden(c)
+	}
+
+	if search := query.Filter("search"); search != "" {
+		masters, err = h.service.SearchMasters(c.Request().Context(), search, includeDeleted, limit, offset)
+	} else if masterType := query.Filter("type"); masterType != "" {
+		masters, err = h.service.GetMastersByType(c.Request().Context(), masterType, includeDeleted, limit, offset)
+	} else if query.Flag("active") {
+		masters, err = h.service.GetActiveMasters(c.Request().Context(), includeDeleted, limit, offset)
 	} else {
-		masters, err = h.service.GetAllMasters(c.Request().Context(), limit, offset)
+		masters, err = h.service.GetAllMasters(c.Request().Context(), includeDeleted, limit, offset)
 	}
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to get master records",
-		})
+		return server.RespondServerError(c, err, "Failed to get master records")
 	}
 
 	responses := make([]*model.MasterResponse, len(masters))
@@ -125,6 +145,8 @@ func (h *Handler) GetMasters(c echo.Context) error {
 
 // UpdateMaster handles master record update
 // PUT /api/masters/:id
+// With If-Match the update only applies while the record still has that ETag, otherwise it fails with 412.
+// Master records carry no version, so a write landing between the check and the update is not detected.
 func (h *Handler) UpdateMaster(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -141,9 +163,26 @@ func (h *Handler) UpdateMaster(c echo.Context) error {
 	}
 
 	if err := c.Validate(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return server.RespondInvalid(c, err)
+	}
+
+	if c.Request().Header.Get("If-Match") != "" {
+		current, err := h.service.GetMasterByID(c.Request().Context(), uint(id), false)
+		if err != nil {
+			if errors.Is(err, service.ErrMasterNotFound) {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "Master record not found",
+				})
+			}
+			return server.RespondServerError(c, err, "Failed to update master record")
+		}
+		matched, err := server.IfMatch(c, current.ToResponse())
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return server.RespondPreconditionFailed(c)
+		}
 	}
 
 	master, err := h.service.UpdateMaster(c.Request().Context(), uint(id), &req)
@@ -158,16 +197,15 @@ func (h *Handler) UpdateMaster(c echo.Context) error {
 				"error": err.Error(),
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to update master record",
-		})
+		return server.RespondServerError(c, err, "Failed to update master record")
 	}
 
-	return c.JSON(http.StatusOK, master.ToResponse())
+	return server.RespondWithETag(c, http.StatusOK, master.ToResponse())
 }
 
 // DeleteMaster handles master record deletion
 // DELETE /api/masters/:id
+// The record is soft-deleted and can be restored; hard=true removes it permanently. The route is admin-only.
 func (h *Handler) DeleteMaster(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -176,28 +214,84 @@ func (h *Handler) DeleteMaster(c echo.Context) error {
 		})
 	}
 
-	if err := h.service.DeleteMaster(c.Request().Context(), uint(id)); err != nil {
+	hard := c.QueryParam("hard") == "true"
+	if err := h.service.DeleteMaster(c.Request().Context(), uint(id), hard); err != nil {
+		if errors.Is(err, service.ErrMasterNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "Master record not found",
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to delete master record")
+	}
+
+	return server.RespondDeleted(c, h.deleteResponse, uint(id), "Master record deleted successfully")
+}
+
+// RestoreMaster handles restoring a soft-deleted master record
+// POST /api/masters/:id/restore
+func (h *Handler) RestoreMaster(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid master ID",
+		})
+	}
+
+	master, err := h.service.RestoreMaster(c.Request().Context(), uint(id))
+	if err != nil {
 		if errors.Is(err, service.ErrMasterNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{
 				"error": "Master record not found",
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to delete master record",
+		return server.RespondServerError(c, err, "Failed to restore master record")
+	}
+
+	return c.JSON(http.StatusOK, master.ToResponse())
+}
+
+// DeleteMasters handles deleting several master records by ID in one transaction
+// POST /api/masters/bulk-delete
+// The response reports how many records were deleted; IDs that did not exist are not counted.
+func (h *Handler) DeleteMasters(c echo.Context) error {
+	var req model.BulkDeleteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Master record deleted successfully",
-	})
+	deleted, err := h.service.DeleteMasters(c.Request().Context(), req.IDs)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBulkDelete) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to delete master records")
+	}
+
+	return c.JSON(http.StatusOK, &model.BulkDeleteResponse{Deleted: deleted})
 }
 
-// Health returns the basic health status of the service
+// Health returns the status of the service and its dependencies, with 503 when a critical one is down
 // GET /health
 func (h *Handler) Health(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"status":  "healthy",
-		"service": "master-service",
-		"time":    time.Now().UTC(),
+	report := h.health.Check(c.Request().Context())
+	report.Service = "master-service"
+	return c.JSON(report.HTTPStatus(), report)
+}
+
+// isAdmin reports whether the request was authenticated as an admin; the public routes authenticate only
+// requests that carry a token
+func isAdmin(c echo.Context) bool {
+	user, err := auth.GetUserFromContext(c)
+	return err == nil && user.Role == "admin"
+}
+
+// respondDeletedForbidden rejects a request for soft-deleted records from a caller who is not an admin
+func respondDeletedForbidden(c echo.Context) error {
+	return c.JSON(http.StatusForbidden, map[string]string{
+		"error": "includeDeleted requires the admin role",
 	})
 }
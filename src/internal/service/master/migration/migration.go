@@ -5,9 +5,38 @@ import (
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
 	"myapp/internal/service/master/model"
 )
 
+// Migrations returns the versioned schema changes of the master service
+func Migrations(logger *zap.Logger) database.MigrationSet {
+	return database.MigrationSet{
+		Service: "master",
+		Migrations: []database.Migration{
+			{
+				Version:     1,
+				Description: "create masters table",
+				Up: func(tx *gorm.DB) error {
+					return RunMigrations(tx, logger)
+				},
+				Down: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable(&model.Master{})
+				},
+			},
+			{
+				Version:     2,
+				Description: "create outbox_events table",
+				Up:          outbox.Migrate,
+				Down: func(tx *gorm.DB) error {
+					return tx.Migrator().DropTable(&outbox.Event{})
+				},
+			},
+		},
+	}
+}
+
 // RunMigrations runs database migrations for master service
 func RunMigrations(db *gorm.DB, logger *zap.Logger) error {
 	if err := db.AutoMigrate(&model.Master{}); err != nil {
@@ -0,0 +1,18 @@
+package model
+
+// Outbox events recorded with master record changes
+const (
+	// AggregateMaster is the aggregate type of master events
+	AggregateMaster = "master"
+
+	EventMasterCreated  = "master.created"  // Payload is the MasterResponse
+	EventMasterUpdated  = "master.updated"  // Payload is the MasterResponse after the update
+	EventMasterDeleted  = "master.deleted"  // Payload is the MasterDeletedEvent
+	EventMasterRestored = "master.restored" // Payload is the MasterResponse after the restore
+)
+
+// MasterDeletedEvent is the payload of a master.deleted event
+type MasterDeletedEvent struct {
+	ID   uint `json:"id"`
+	Hard bool `json:"hard"` // The record was removed permanently and cannot be restored
+}
@@ -12,6 +12,8 @@ type Master struct {
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	CreatedBy   uint           `gorm:"not null;default:0" json:"created_by"` // User who created the record, 0 for system writes
+	UpdatedBy   uint           `gorm:"not null;default:0" json:"updated_by"` // User who last modified the record
 	
 	Name        string `gorm:"type:varchar(255);not null" json:"name"`
 	Description string `gorm:"type:text" json:"description"`
@@ -42,11 +44,23 @@ type UpdateMasterRequest struct {
 	IsActive    *bool   `json:"is_active,omitempty"`
 }
 
+// BulkDeleteRequest defines the request structure for deleting several master records by ID
+type BulkDeleteRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1"`
+}
+
+// BulkDeleteResponse reports how many of the requested master records were deleted
+type BulkDeleteResponse struct {
+	Deleted int `json:"deleted"`
+}
+
 // MasterResponse defines the response structure for master record
 type MasterResponse struct {
 	ID          uint      `json:"id"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+	CreatedBy   uint      `json:"created_by"`
+	UpdatedBy   uint      `json:"updated_by"`
 	Name        string    `json:"name"`
 	Description string    `json:"description"`
 	Code        string    `json:"code"`
@@ -60,6 +74,8 @@ func (m *Master) ToResponse() *MasterResponse {
 		ID:          m.ID,
 		CreatedAt:   m.CreatedAt,
 		UpdatedAt:   m.UpdatedAt,
+		CreatedBy:   m.CreatedBy,
+		UpdatedBy:   m.UpdatedBy,
 		Name:        m.Name,
 		Description: m.Description,
 		Code:        m.Code,
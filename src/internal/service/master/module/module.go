@@ -1,8 +1,11 @@
 package module
 
 import (
+	"context"
+
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+	"myapp/internal/pkg/config"
 	"myapp/internal/pkg/database"
 	"myapp/internal/service/master/handler"
 	"myapp/internal/service/master/migration"
@@ -27,10 +30,16 @@ var Module = fx.Options(
 	fx.Invoke(RegisterMigrations),
 )
 
-// RegisterMigrations registers database migrations for master service
-func RegisterMigrations(dbManager *database.DatabaseManager, logger *zap.Logger) {
+// RegisterMigrations registers database migrations for master service.
+// Migrations only run when master_database.auto_migrate is enabled.
+func RegisterMigrations(cfg *config.Config, dbManager *database.DatabaseManager, logger *zap.Logger) {
+	if !cfg.MasterDatabase.AutoMigrate {
+		logger.Info("Skipping master migrations, master_database.auto_migrate is disabled")
+		return
+	}
+	
 	// Use master database for master service migrations
-	if err := migration.RunMigrations(dbManager.MasterDB, logger); err != nil {
+	if err := database.MigrateUp(context.Background(), dbManager.MasterDB, migration.Migrations(logger)); err != nil {
 		logger.Error("Failed to run master migrations", zap.Error(err))
 	}
 }
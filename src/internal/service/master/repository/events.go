@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
+	"myapp/internal/service/master/model"
+)
+
+// InsertMaster inserts a master record and records a master.created event in the same transaction
+func (r *Repository) InsertMaster(ctx context.Context, master *model.Master) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := database.NewBaseRepository[model.Master](tx).Insert(ctx, master); err != nil {
+			return err
+		}
+		return outbox.Record(tx, model.AggregateMaster, master.ID, model.EventMasterCreated, master.ToResponse())
+	})
+}
+
+// UpdateMaster updates a master record and records a master.updated event in the same transaction
+func (r *Repository) UpdateMaster(ctx context.Context, id uint, master *model.Master) error {
+	defer r.cached.Invalidate(ctx, id)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := database.NewBaseRepository[model.Master](tx).UpdateByID(ctx, id, master); err != nil {
+			return err
+		}
+		return outbox.Record(tx, model.AggregateMaster, id, model.EventMasterUpdated, master.ToResponse())
+	})
+}
+
+// DeleteMaster soft-deletes a master record, or removes it permanently when hard is set, and records a
+// master.deleted event in the same transaction
+func (r *Repository) DeleteMaster(ctx context.Context, id uint, hard bool) error {
+	defer r.cached.Invalidate(ctx, id)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx
+		if hard {
+			query = tx.Unscoped()
+		}
+		if err := query.Delete(&model.Master{}, id).Error; err != nil {
+			return err
+		}
+		return outbox.Record(tx, model.AggregateMaster, id, model.EventMasterDeleted, &model.MasterDeletedEvent{ID: id, Hard: hard})
+	})
+}
+
+// RestoreMaster restores a soft-deleted master record and records a master.restored event in the same
+// transaction. A record that never existed or was hard-deleted yields gorm.ErrRecordNotFound.
+func (r *Repository) RestoreMaster(ctx context.Context, id uint) (*model.Master, error) {
+	var master model.Master
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := database.NewBaseRepository[model.Master](tx).RestoreByID(ctx, id); err != nil {
+			return err
+		}
+		if err := tx.First(&master, id).Error; err != nil {
+			return err
+		}
+		return outbox.Record(tx, model.AggregateMaster, id, model.EventMasterRestored, master.ToResponse())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &master, nil
+}
@@ -5,34 +5,126 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"myapp/internal/pkg/cache"
 	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
 	"myapp/internal/service/master/model"
 )
 
 // Repository handles master data access using master database
 type Repository struct {
 	*database.MasterRepo[model.Master]
-	db *gorm.DB
+	db     *gorm.DB
+	reads  *database.BaseRepository[model.Master] // Lists and searches, served by read replicas when configured
+	cached *cache.CachingRepository[model.Master]
 }
 
-// NewRepository creates a new master repository using master database
-func NewRepository(dbManager *database.DatabaseManager) *Repository {
+// NewRepository creates a new master repository using master database. Reads by ID are cached when
+// masterCache is not nil; every method that changes a record invalidates its entry.
+func NewRepository(dbManager *database.DatabaseManager, masterCache *cache.Cache) *Repository {
+	masterRepo := database.NewMasterRepo[model.Master](dbManager)
 	return &Repository{
-		MasterRepo: database.NewMasterRepo[model.Master](dbManager),
+		MasterRepo: masterRepo,
 		db:         dbManager.MasterDB, // For custom queries
+		reads:      masterRepo.ReadOnly(),
+		cached:     cache.NewCachingRepository[model.Master](masterRepo, masterCache, "master"),
 	}
 }
 
-// GetByCode retrieves a master record by code
-func (r *Repository) GetByCode(ctx context.Context, code string) (*model.Master, error) {
+// GetByID retrieves a master record by ID, from the cache when it holds one
+func (r *Repository) GetByID(ctx context.Context, id uint) (*model.Master, error) {
+	return r.cached.GetByID(ctx, id)
+}
+
+// UpdateByID updates a master record by ID and invalidates its cache entry
+func (r *Repository) UpdateByID(ctx context.Context, id uint, master *model.Master) error {
+	return r.cached.UpdateByID(ctx, id, master)
+}
+
+// DeleteByID soft-deletes a master record by ID and invalidates its cache entry
+func (r *Repository) DeleteByID(ctx context.Context, id uint) error {
+	return r.cached.DeleteByID(ctx, id)
+}
+
+// withDeleted is a scope that includes soft-deleted masters when includeDeleted is set
+func withDeleted(includeDeleted bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if includeDeleted {
+			return db.Unscoped()
+		}
+		return db
+	}
+}
+
+// GetMaster retrieves a master record by ID, finding soft-deleted ones only when includeDeleted is set.
+// Only reads without includeDeleted use the cache.
+func (r *Repository) GetMaster(ctx context.Context, id uint, includeDeleted bool) (*model.Master, error) {
+	if !includeDeleted {
+		return r.GetByID(ctx, id)
+	}
+
 	var master model.Master
-	err := r.db.WithContext(ctx).Where("code = ?", code).First(&master).Error
-	if err != nil {
+	if err := r.db.WithContext(ctx).Unscoped().First(&master, id).Error; err != nil {
 		return nil, err
 	}
 	return &master, nil
 }
 
+// ListMasters retrieves master records with pagination, skipping soft-deleted ones unless includeDeleted is set
+func (r *Repository) ListMasters(ctx context.Context, includeDeleted bool, limit, offset int) ([]*model.Master, error) {
+	var masters []*model.Master
+	query := r.reads.ReadDB().WithContext(ctx).Scopes(withDeleted(includeDeleted))
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Find(&masters).Error; err != nil {
+		return nil, err
+	}
+	return masters, nil
+}
+
+// GetByCode retrieves a master record by code
+func (r *Repository) GetByCode(ctx context.Context, code string) (*model.Master, error) {
+	return r.GetByField(ctx, "code", code)
+}
+
+// DeleteByIDs soft-deletes the master records with the given IDs in one transaction and returns how many
+// were deleted; IDs that do not exist or are already deleted are not counted. A master.deleted event is
+// recorded for each deleted record in the same transaction.
+func (r *Repository) DeleteByIDs(ctx context.Context, ids []uint) (int64, error) {
+	defer r.cached.Invalidate(ctx, ids...)
+
+	var deleted int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		if err := tx.Model(&model.Master{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return nil
+		}
+
+		var err error
+		deleted, err = database.NewBaseRepository[model.Master](tx).DeleteWhereCount(ctx, map[string]interface{}{"id IN ?": existing})
+		if err != nil {
+			return err
+		}
+		for _, id := range existing {
+			if err := outbox.Record(tx, model.AggregateMaster, id, model.EventMasterDeleted, &model.MasterDeletedEvent{ID: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
 // CodeExists checks if a code already exists
 func (r *Repository) CodeExists(ctx context.Context, code string) (bool, error) {
 	var count int64
@@ -43,10 +135,10 @@ func (r *Repository) CodeExists(ctx context.Context, code string) (bool, error)
 	return count > 0, nil
 }
 
-// GetByType retrieves master records by type
-func (r *Repository) GetByType(ctx context.Context, masterType string, limit, offset int) ([]*model.Master, error) {
+// GetByType retrieves master records by type, skipping soft-deleted ones unless includeDeleted is set
+func (r *Repository) GetByType(ctx context.Context, masterType string, includeDeleted bool, limit, offset int) ([]*model.Master, error) {
 	var masters []*model.Master
-	query := r.db.WithContext(ctx).Where("type = ?", masterType)
+	query := r.reads.ReadDB().WithContext(ctx).Where("type = ?", masterType).Scopes(withDeleted(includeDeleted))
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -62,10 +154,10 @@ func (r *Repository) GetByType(ctx context.Context, masterType string, limit, of
 	return masters, nil
 }
 
-// GetActiveMasters retrieves all active master records
-func (r *Repository) GetActiveMasters(ctx context.Context, limit, offset int) ([]*model.Master, error) {
+// GetActiveMasters retrieves all active master records, skipping soft-deleted ones unless includeDeleted is set
+func (r *Repository) GetActiveMasters(ctx context.Context, includeDeleted bool, limit, offset int) ([]*model.Master, error) {
 	var masters []*model.Master
-	query := r.db.WithContext(ctx).Where("is_active = ?", true)
+	query := r.reads.ReadDB().WithContext(ctx).Where("is_active = ?", true).Scopes(withDeleted(includeDeleted))
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -81,14 +173,15 @@ func (r *Repository) GetActiveMasters(ctx context.Context, limit, offset int) ([
 	return masters, nil
 }
 
-// SearchMasters searches master records by name or description
-func (r *Repository) SearchMasters(ctx context.Context, query string, limit, offset int) ([]*model.Master, error) {
+// SearchMasters searches master records by name or description, skipping soft-deleted ones unless includeDeleted is set
+func (r *Repository) SearchMasters(ctx context.Context, query string, includeDeleted bool, limit, offset int) ([]*model.Master, error) {
 	var masters []*model.Master
 	searchQuery := "%" + query + "%"
 	
-	dbQuery := r.db.WithContext(ctx).
+	dbQuery := r.reads.ReadDB().WithContext(ctx).
 		Where("name LIKE ? OR description LIKE ?", searchQuery, searchQuery).
-		Where("is_active = ?", true)
+		Where("is_active = ?", true).
+		Scopes(withDeleted(includeDeleted))
 	
 	if limit > 0 {
 		dbQuery = dbQuery.Limit(limit)
@@ -6,6 +6,8 @@ import (
 	"fmt"
 
 	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/normalize"
 	"myapp/internal/service/master/model"
 	"myapp/internal/service/master/repository"
 )
@@ -15,22 +17,33 @@ var (
 	ErrMasterNotFound = errors.New("master record not found")
 	// ErrCodeExists is returned when code already exists
 	ErrCodeExists = errors.New("master record with this code already exists")
+	// ErrInvalidBulkDelete is returned when a bulk delete names no IDs or too many
+	ErrInvalidBulkDelete = errors.New("invalid bulk delete request")
 )
 
 // Service handles master business logic
 type Service struct {
-	repo *repository.Repository
+	repo         *repository.Repository
+	norm         normalize.Policy
+	maxBatchSize int
 }
 
 // NewService creates a new master service
-func NewService(repo *repository.Repository) *Service {
+func NewService(repo *repository.Repository, cfg *config.Config) *Service {
 	return &Service{
-		repo: repo,
+		repo:         repo,
+		norm:         normalize.NewPolicy(cfg.Normalization),
+		maxBatchSize: cfg.Bulk.MaxBatchSize,
 	}
 }
 
 // CreateMaster creates a new master record
 func (s *Service) CreateMaster(ctx context.Context, req *model.CreateMasterRequest) (*model.Master, error) {
+	req.Name = s.norm.Name(req.Name)
+	req.Description = s.norm.Text(req.Description)
+	req.Code = s.norm.Code(req.Code)
+	req.Type = s.norm.Text(req.Type)
+
 	// Check if code already exists
 	exists, err := s.repo.CodeExists(ctx, req.Code)
 	if err != nil {
@@ -48,16 +61,16 @@ func (s *Service) CreateMaster(ctx context.Context, req *model.CreateMasterReque
 		IsActive:    true,
 	}
 
-	if err := s.repo.Insert(ctx, master); err != nil {
+	if err := s.repo.InsertMaster(ctx, master); err != nil {
 		return nil, fmt.Errorf("create master: %w", err)
 	}
 
 	return master, nil
 }
 
-// GetMasterByID retrieves a master record by ID
-func (s *Service) GetMasterByID(ctx context.Context, id uint) (*model.Master, error) {
-	master, err := s.repo.GetByID(ctx, id)
+// GetMasterByID retrieves a master record by ID, finding soft-deleted ones only when includeDeleted is set
+func (s *Service) GetMasterByID(ctx context.Context, id uint, includeDeleted bool) (*model.Master, error) {
+	master, err := s.repo.GetMaster(ctx, id, includeDeleted)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrMasterNotFound
@@ -69,7 +82,7 @@ func (s *Service) GetMasterByID(ctx context.Context, id uint) (*model.Master, er
 
 // GetMasterByCode retrieves a master record by code
 func (s *Service) GetMasterByCode(ctx context.Context, code string) (*model.Master, error) {
-	master, err := s.repo.GetByCode(ctx, code)
+	master, err := s.repo.GetByCode(ctx, s.norm.Code(code))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrMasterNotFound
@@ -79,9 +92,9 @@ func (s *Service) GetMasterByCode(ctx context.Context, code string) (*model.Mast
 	return master, nil
 }
 
-// GetAllMasters retrieves all master records with pagination
-func (s *Service) GetAllMasters(ctx context.Context, limit, offset int) ([]*model.Master, error) {
-	masters, err := s.repo.GetAll(ctx, limit, offset)
+// GetAllMasters retrieves all master records with pagination, skipping soft-deleted ones unless includeDeleted is set
+func (s *Service) GetAllMasters(ctx context.Context, includeDeleted bool, limit, offset int) ([]*model.Master, error) {
+	masters, err := s.repo.ListMasters(ctx, includeDeleted, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("get all masters: %w", err)
 	}
@@ -89,8 +102,8 @@ func (s *Service) GetAllMasters(ctx context.Context, limit, offset int) ([]*mode
 }
 
 // GetActiveMasters retrieves all active master records with pagination
-func (s *Service) GetActiveMasters(ctx context.Context, limit, offset int) ([]*model.Master, error) {
-	masters, err := s.repo.GetActiveMasters(ctx, limit, offset)
+func (s *Service) GetActiveMasters(ctx context.Context, includeDeleted bool, limit, offset int) ([]*model.Master, error) {
+	masters, err := s.repo.GetActiveMasters(ctx, includeDeleted, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("get active masters: %w", err)
 	}
@@ -98,8 +111,8 @@ func (s *Service) GetActiveMasters(ctx context.Context, limit, offset int) ([]*m
 }
 
 // GetMastersByType retrieves master records by type
-func (s *Service) GetMastersByType(ctx context.Context, masterType string, limit, offset int) ([]*model.Master, error) {
-	masters, err := s.repo.GetByType(ctx, masterType, limit, offset)
+func (s *Service) GetMastersByType(ctx context.Context, masterType string, includeDeleted bool, limit, offset int) ([]*model.Master, error) {
+	masters, err := s.repo.GetByType(ctx, masterType, includeDeleted, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("get masters by type: %w", err)
 	}
@@ -107,8 +120,8 @@ func (s *Service) GetMastersByType(ctx context.Context, masterType string, limit
 }
 
 // SearchMasters searches master records by name or description
-func (s *Service) SearchMasters(ctx context.Context, query string, limit, offset int) ([]*model.Master, error) {
-	masters, err := s.repo.SearchMasters(ctx, query, limit, offset)
+func (s *Service) SearchMasters(ctx context.Context, query string, includeDeleted bool, limit, offset int) ([]*model.Master, error) {
+	masters, err := s.repo.SearchMasters(ctx, query, includeDeleted, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("search masters: %w", err)
 	}
@@ -117,22 +130,23 @@ func (s *Service) SearchMasters(ctx context.Context, query string, limit, offset
 
 // UpdateMaster updates a master record
 func (s *Service) UpdateMaster(ctx context.Context, id uint, req *model.UpdateMasterRequest) (*model.Master, error) {
-	master, err := s.GetMasterByID(ctx, id)
+	master, err := s.GetMasterByID(ctx, id, false)
 	if err != nil {
 		return nil, err
 	}
 
 	// Apply updates
 	if req.Name != nil {
-		master.Name = *req.Name
+		master.Name = s.norm.Name(*req.Name)
 	}
 	if req.Description != nil {
-		master.Description = *req.Description
+		master.Description = s.norm.Text(*req.Description)
 	}
 	if req.Code != nil {
+		code := s.norm.Code(*req.Code)
 		// Check if new code already exists (if changed)
-		if *req.Code != master.Code {
-			exists, err := s.repo.CodeExists(ctx, *req.Code)
+		if code != master.Code {
+			exists, err := s.repo.CodeExists(ctx, code)
 			if err != nil {
 				return nil, fmt.Errorf("check code existence: %w", err)
 			}
@@ -140,29 +154,69 @@ func (s *Service) UpdateMaster(ctx context.Context, id uint, req *model.UpdateMa
 				return nil, ErrCodeExists
 			}
 		}
-		master.Code = *req.Code
+		master.Code = code
 	}
 	if req.Type != nil {
-		master.Type = *req.Type
+		master.Type = s.norm.Text(*req.Type)
 	}
 	if req.IsActive != nil {
 		master.IsActive = *req.IsActive
 	}
 
-	if err := s.repo.UpdateByID(ctx, id, master); err != nil {
+	if err := s.repo.UpdateMaster(ctx, id, master); err != nil {
 		return nil, fmt.Errorf("update master: %w", err)
 	}
 
 	return master, nil
 }
 
-// DeleteMaster deletes a master record
-func (s *Service) DeleteMaster(ctx context.Context, id uint) error {
-	if err := s.repo.DeleteByID(ctx, id); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrMasterNotFound
-		}
+// DeleteMaster soft-deletes a master record so it can be restored later. With hard set the record is removed
+// permanently instead, which also purges one that was already soft-deleted.
+func (s *Service) DeleteMaster(ctx context.Context, id uint, hard bool) error {
+	if _, err := s.GetMasterByID(ctx, id, hard); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteMaster(ctx, id, hard); err != nil {
 		return fmt.Errorf("delete master: %w", err)
 	}
 	return nil
 }
+
+// RestoreMaster restores a soft-deleted master record; restoring one that is not deleted is a no-op
+func (s *Service) RestoreMaster(ctx context.Context, id uint) (*model.Master, error) {
+	master, err := s.repo.RestoreMaster(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMasterNotFound
+		}
+		return nil, fmt.Errorf("restore master: %w", err)
+	}
+	return master, nil
+}
+
+// DeleteMasters soft-deletes the master records with the given IDs in one transaction, all or nothing, and
+// returns how many were deleted. Repeated IDs count once and IDs that do not exist are skipped.
+// At most bulk.max_batch_size IDs are accepted.
+func (s *Service) DeleteMasters(ctx context.Context, ids []uint) (int, error) {
+	unique := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return 0, fmt.Errorf("%w: ids are required", ErrInvalidBulkDelete)
+	}
+	if len(unique) > s.maxBatchSize {
+		return 0, fmt.Errorf("%w: at most %d ids are allowed", ErrInvalidBulkDelete, s.maxBatchSize)
+	}
+
+	deleted, err := s.repo.DeleteByIDs(ctx, unique)
+	if err != nil {
+		return 0, fmt.Errorf("delete masters: %w", err)
+	}
+	return int(deleted), nil
+}
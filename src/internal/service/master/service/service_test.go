@@ -0,0 +1,250 @@
+// +build cgo
+
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/cache"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
+	"myapp/internal/service/master/model"
+	"myapp/internal/service/master/repository"
+	"myapp/internal/service/master/service"
+	"myapp/internal/testsupport"
+)
+
+// setupTestService creates a master service backed by a SQLite master database
+func setupTestService(t *testing.T) (*service.Service, *gorm.DB) {
+	return setupTestServiceWithConfig(t, &config.Config{})
+}
+
+// setupTestServiceWithConfig is setupTestService with the given configuration
+func setupTestServiceWithConfig(t *testing.T, cfg *config.Config) (*service.Service, *gorm.DB) {
+	db := testsupport.NewTestDB(t, &model.Master{}, &outbox.Event{})
+	repo := repository.NewRepository(&database.DatabaseManager{MasterDB: db}, nil)
+	return service.NewService(repo, cfg), db
+}
+
+// TestService_Normalization tests that create and update normalize names and codes per policy
+func TestService_Normalization(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := setupTestServiceWithConfig(t, &config.Config{
+		Normalization: config.NormalizationConfig{LowercaseCodes: true},
+	})
+
+	master, err := svc.CreateMaster(ctx, &model.CreateMasterRequest{Name: "  Main   Warehouse ", Code: "  ABC ", Type: " location "})
+	require.NoError(t, err)
+	assert.Equal(t, "Main Warehouse", master.Name)
+	assert.Equal(t, "abc", master.Code)
+	assert.Equal(t, "location", master.Type)
+
+	t.Run("create with the same code in another form", func(t *testing.T) {
+		_, err := svc.CreateMaster(ctx, &model.CreateMasterRequest{Name: "Other", Code: "abc", Type: "location"})
+		assert.ErrorIs(t, err, service.ErrCodeExists)
+	})
+
+	t.Run("lookup by code", func(t *testing.T) {
+		found, err := svc.GetMasterByCode(ctx, " ABC")
+		require.NoError(t, err)
+		assert.Equal(t, master.ID, found.ID)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		other, err := svc.CreateMaster(ctx, &model.CreateMasterRequest{Name: "Other", Code: "xyz", Type: "location"})
+		require.NoError(t, err)
+
+		taken := " ABC "
+		_, err = svc.UpdateMaster(ctx, other.ID, &model.UpdateMasterRequest{Code: &taken})
+		assert.ErrorIs(t, err, service.ErrCodeExists)
+
+		name, code := " Second  Site ", " XYZ2 "
+		updated, err := svc.UpdateMaster(ctx, other.ID, &model.UpdateMasterRequest{Name: &name, Code: &code})
+		require.NoError(t, err)
+		assert.Equal(t, "Second Site", updated.Name)
+		assert.Equal(t, "xyz2", updated.Code)
+	})
+}
+
+// TestService_DeleteMaster tests soft delete, restore and hard delete of master records
+func TestService_DeleteMaster(t *testing.T) {
+	svc, db := setupTestService(t)
+	ctx := context.Background()
+
+	master, err := svc.CreateMaster(ctx, &model.CreateMasterRequest{Name: "Region", Code: "REGION", Type: "geo"})
+	require.NoError(t, err)
+	other, err := svc.CreateMaster(ctx, &model.CreateMasterRequest{Name: "Country", Code: "COUNTRY", Type: "geo"})
+	require.NoError(t, err)
+
+	t.Run("soft delete hides record from default reads", func(t *testing.T) {
+		require.NoError(t, svc.DeleteMaster(ctx, master.ID, false))
+
+		listed, err := svc.GetAllMasters(ctx, false, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, other.ID, listed[0].ID)
+
+		byType, err := svc.GetMastersByType(ctx, "geo", false, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, byType, 1)
+
+		_, err = svc.GetMasterByID(ctx, master.ID, false)
+		assert.ErrorIs(t, err, service.ErrMasterNotFound)
+	})
+
+	t.Run("include deleted returns soft-deleted record", func(t *testing.T) {
+		listed, err := svc.GetAllMasters(ctx, true, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, listed, 2)
+
+		found, err := svc.GetMasterByID(ctx, master.ID, true)
+		require.NoError(t, err)
+		assert.Equal(t, master.ID, found.ID)
+	})
+
+	t.Run("restore brings record back", func(t *testing.T) {
+		restored, err := svc.RestoreMaster(ctx, master.ID)
+		require.NoError(t, err)
+		assert.Equal(t, master.ID, restored.ID)
+
+		listed, err := svc.GetAllMasters(ctx, false, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, listed, 2)
+	})
+
+	t.Run("restore never created", func(t *testing.T) {
+		_, err := svc.RestoreMaster(ctx, 9999)
+		assert.ErrorIs(t, err, service.ErrMasterNotFound)
+	})
+
+	t.Run("hard delete removes record permanently", func(t *testing.T) {
+		require.NoError(t, svc.DeleteMaster(ctx, master.ID, true))
+
+		var count int64
+		require.NoError(t, db.Unscoped().Model(&model.Master{}).Where("id = ?", master.ID).Count(&count).Error)
+		assert.Zero(t, count)
+
+		_, err := svc.RestoreMaster(ctx, master.ID)
+		assert.ErrorIs(t, err, service.ErrMasterNotFound)
+	})
+
+	t.Run("hard delete purges soft-deleted record", func(t *testing.T) {
+		require.NoError(t, svc.DeleteMaster(ctx, other.ID, false))
+		require.NoError(t, svc.DeleteMaster(ctx, other.ID, true))
+
+		_, err := svc.GetMasterByID(ctx, other.ID, true)
+		assert.ErrorIs(t, err, service.ErrMasterNotFound)
+	})
+}
+
+// TestService_OutboxEvents tests that every master mutation records its event in the outbox
+func TestService_OutboxEvents(t *testing.T) {
+	svc, db := setupTestService(t)
+	ctx := context.Background()
+
+	master, err := svc.CreateMaster(ctx, &model.CreateMasterRequest{Name: "Region", Code: "REGION", Type: "geo"})
+	require.NoError(t, err)
+	name := "Area"
+	_, err = svc.UpdateMaster(ctx, master.ID, &model.UpdateMasterRequest{Name: &name})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteMaster(ctx, master.ID, false))
+	_, err = svc.RestoreMaster(ctx, master.ID)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteMaster(ctx, master.ID, true))
+
+	var events []outbox.Event
+	require.NoError(t, db.Order("id").Find(&events).Error)
+	require.Len(t, events, 5)
+
+	types := make([]string, len(events))
+	for i, event := range events {
+		assert.Equal(t, model.AggregateMaster, event.AggregateType)
+		assert.Equal(t, master.ID, event.AggregateID)
+		assert.True(t, event.IsPending())
+		types[i] = event.EventType
+	}
+	assert.Equal(t, []string{
+		model.EventMasterCreated,
+		model.EventMasterUpdated,
+		model.EventMasterDeleted,
+		model.EventMasterRestored,
+		model.EventMasterDeleted,
+	}, types)
+	assert.JSONEq(t, `{"id":`+strconv.FormatUint(uint64(master.ID), 10)+`,"hard":true}`, events[4].Payload)
+
+	var updated model.MasterResponse
+	require.NoError(t, json.Unmarshal([]byte(events[1].Payload), &updated))
+	assert.Equal(t, "Area", updated.Name)
+}
+
+// TestService_OutboxEvents_DeleteMasters tests that bulk delete records one event per deleted record
+func TestService_OutboxEvents_DeleteMasters(t *testing.T) {
+	svc, db := setupTestServiceWithConfig(t, &config.Config{Bulk: config.BulkConfig{MaxBatchSize: 3}})
+	ctx := context.Background()
+
+	first, err := svc.CreateMaster(ctx, &model.CreateMasterRequest{Name: "Region", Code: "REGION", Type: "geo"})
+	require.NoError(t, err)
+	second, err := svc.CreateMaster(ctx, &model.CreateMasterRequest{Name: "Country", Code: "COUNTRY", Type: "geo"})
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteMaster(ctx, second.ID, false))
+
+	deleted, err := svc.DeleteMasters(ctx, []uint{first.ID, second.ID, 9999})
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	var events []outbox.Event
+	require.NoError(t, db.Where("event_type = ?", model.EventMasterDeleted).Order("id").Find(&events).Error)
+	require.Len(t, events, 2, "the already deleted and the missing record record nothing")
+	assert.Equal(t, second.ID, events[0].AggregateID)
+	assert.Equal(t, first.ID, events[1].AggregateID)
+	assert.JSONEq(t, `{"id":`+strconv.FormatUint(uint64(first.ID), 10)+`,"hard":false}`, events[1].Payload)
+}
+
+// TestService_Cache tests that reads by ID are served from Redis until the record changes
+func TestService_Cache(t *testing.T) {
+	server := miniredis.RunT(t)
+	masterCache := cache.NewWithClient(redis.NewClient(&redis.Options{Addr: server.Addr()}), time.Minute, nil)
+	t.Cleanup(func() { masterCache.Close() })
+
+	db := testsupport.NewTestDB(t, &model.Master{}, &outbox.Event{})
+	repo := repository.NewRepository(&database.DatabaseManager{MasterDB: db}, masterCache)
+	svc := service.NewService(repo, &config.Config{})
+	ctx := context.Background()
+
+	master, err := svc.CreateMaster(ctx, &model.CreateMasterRequest{Name: "Region", Code: "REGION", Type: "geo"})
+	require.NoError(t, err)
+	key := "entity:master:" + strconv.FormatUint(uint64(master.ID), 10)
+
+	_, err = svc.GetMasterByID(ctx, master.ID, false)
+	require.NoError(t, err)
+	require.True(t, server.Exists(key))
+
+	// A change behind the repository's back is not seen while the entry is cached
+	require.NoError(t, db.Model(&model.Master{}).Where("id = ?", master.ID).Update("name", "Stale").Error)
+	cached, err := svc.GetMasterByID(ctx, master.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "Region", cached.Name)
+
+	name := "Area"
+	_, err = svc.UpdateMaster(ctx, master.ID, &model.UpdateMasterRequest{Name: &name})
+	require.NoError(t, err)
+	assert.False(t, server.Exists(key))
+
+	fresh, err := svc.GetMasterByID(ctx, master.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "Area", fresh.Name)
+
+	require.NoError(t, svc.DeleteMaster(ctx, master.ID, false))
+	_, err = svc.GetMasterByID(ctx, master.ID, false)
+	assert.ErrorIs(t, err, service.ErrMasterNotFound)
+}
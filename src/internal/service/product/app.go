@@ -2,10 +2,16 @@ package product
 
 import (
 	"go.uber.org/fx"
+	"myapp/internal/pkg/cache"
 	"myapp/internal/pkg/config"
 	"myapp/internal/pkg/database"
 	"myapp/internal/pkg/logger"
+	"myapp/internal/pkg/outbox"
+	custommw "myapp/internal/pkg/middleware"
 	"myapp/internal/pkg/server"
+	"myapp/internal/pkg/tracing"
+	authmodule "myapp/internal/pkg/auth"
+	productmigration "myapp/internal/service/product/migration"
 	productmodule "myapp/internal/service/product/module"
 	productrouter "myapp/internal/service/product/router"
 )
@@ -15,13 +21,26 @@ var AppModule = fx.Options(
 	// Infrastructure modules
 	config.Module,
 	logger.Module,
+	tracing.Module, // Before database and server so it stops last and flushes their spans
 	database.Module,
 	server.Module,
+	custommw.Module, // Audit log repository and rate limit store for the routers
+	cache.Module,    // Redis cache for product reads by ID, nil when disabled
+	
+	// Auth service for JWTMiddleware on protected routes
+	authmodule.ServiceModule,
 	
 	// Product service module
 	productmodule.Module,
 	
+	// Product schema for tenants provisioned at runtime
+	fx.Invoke(productmigration.RegisterTenantMigrations),
+	
+	// Publishes product events from each tenant's outbox
+	outbox.TenantModule,
+	
 	// Router registration
 	fx.Invoke(productrouter.RegisterProductRoutes),
 	fx.Invoke(productrouter.RegisterProductTestOnlyRoutes),
+	fx.Invoke(server.RegisterPprof), // Admin-only profiling when server.pprof_enabled is set
 )
@@ -1,24 +1,33 @@
 package handler
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/server"
 	"myapp/internal/service/product/model"
 	"myapp/internal/service/product/service"
 )
 
 // Handler handles product HTTP requests
 type Handler struct {
-	service *service.Service
+	service        *service.Service
+	deleteResponse string
+	strictFields   bool
 }
 
 // NewHandler creates a new product handler
-func NewHandler(service *service.Service) *Handler {
+func NewHandler(service *service.Service, cfg *config.Config) *Handler {
 	return &Handler{
-		service: service,
+		service:        service,
+		deleteResponse: cfg.Server.DeleteResponse,
+		strictFields:   cfg.Server.UnknownFields == config.UnknownFieldsReject,
 	}
 }
 
@@ -33,9 +42,7 @@ func (h *Handler) CreateProduct(c echo.Context) error {
 	}
 
 	if err := c.Validate(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return server.RespondInvalid(c, err)
 	}
 
 	product, err := h.service.CreateProduct(c.Request().Context(), &req)
@@ -45,16 +52,55 @@ func (h *Handler) CreateProduct(c echo.Context) error {
 				"error": err.Error(),
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to create product",
-		})
+		return server.RespondServerError(c, err, "Failed to create product")
 	}
 
 	return c.JSON(http.StatusCreated, product.ToResponse())
 }
 
+// CreateProducts handles creating several products at once. Either every product is created or none is;
+// when items fail validation the response lists each failed field by its path, e.g. products[1].sku.
+// POST /api/products/batch
+func (h *Handler) CreateProducts(c echo.Context) error {
+	var req model.BatchCreateProductsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	products, err := h.service.CreateProducts(c.Request().Context(), req.Products)
+	if err != nil {
+		var validationErr *service.BatchValidationError
+		if errors.As(err, &validationErr) {
+			return server.RespondInvalid(c, validationErr.ValidationError())
+		}
+		if errors.Is(err, service.ErrInvalidBatchCreate) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, service.ErrSKUExists) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to create products")
+	}
+
+	responses := make([]*model.ProductResponse, len(products))
+	for i, product := range products {
+		responses[i] = product.ToResponse()
+	}
+
+	return c.JSON(http.StatusCreated, map[string]interface{}{
+		"products": responses,
+	})
+}
+
 // GetProduct handles retrieving a product by ID
 // GET /api/products/:id
+// The response carries an ETag; sending it back in If-None-Match returns 304 while the product is unchanged.
 func (h *Handler) GetProduct(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -70,49 +116,66 @@ func (h *Handler) GetProduct(c echo.Context) error {
 				"error": "Product not found",
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to get product",
-		})
+		return server.RespondServerError(c, err, "Failed to get product")
 	}
 
-	return c.JSON(http.StatusOK, product.ToResponse())
+	return server.RespondWithETag(c, http.StatusOK, product.ToResponse())
 }
 
 // GetProducts handles retrieving all products
 // GET /api/products
+// Passing ?cursor= (empty for the first page) switches from limit/offset to keyset pagination; see getProductsAfterCursor.
+// ?fields=id,name,price returns only those keys of each product and loads only their columns; unknown names
+// are dropped or rejected according to server.unknown_fields.
 func (h *Handler) GetProducts(c echo.Context) error {
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
-	category := c.QueryParam("category")
-	search := c.QueryParam("search")
-	activeOnly := c.QueryParam("active") == "true"
+	fields, err := database.ParseFields(c.QueryParam("fields"), model.SelectFields, h.strictFields)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
 
-	if limit <= 0 {
-		limit = 20
+	query, err := server.ParseListQuery(c, "category", "search", "active", "include_archived")
+	if err == nil {
+		err = query.RequireFlags("active", "include_archived")
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if c.QueryParams().Has("cursor") {
+		return h.getProductsAfterCursor(c, query, fields)
 	}
 
 	var products []*model.Product
-	var err error
-
-	if search != "" {
-		products, err = h.service.SearchProducts(c.Request().Context(), search, limit, offset)
-	} else if category != "" {
-		products, err = h.service.GetProductsByCategory(c.Request().Context(), category, limit, offset)
-	} else if activeOnly {
-		products, err = h.service.GetActiveProducts(c.Request().Context(), limit, offset)
+	limit, offset, sort := query.Limit, query.Offset, query.Sort
+	includeArchived := query.Flag("include_archived")
+	columns := fields.SelectedColumns()
+
+	if search := query.Filter("search"); search != "" {
+		products, err = h.service.SearchProducts(c.Request().Context(), search, includeArchived, sort, columns, limit, offset)
+	} else if category := query.Filter("category"); category != "" {
+		products, err = h.service.GetProductsByCategory(c.Request().Context(), category, includeArchived, sort, columns, limit, offset)
+	} else if query.Flag("active") {
+		products, err = h.service.GetActiveProducts(c.Request().Context(), includeArchived, sort, columns, limit, offset)
 	} else {
-		products, err = h.service.GetAllProducts(c.Request().Context(), limit, offset)
+		products, err = h.service.GetAllProducts(c.Request().Context(), includeArchived, sort, columns, limit, offset)
 	}
 
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to get products",
-		})
+		if errors.Is(err, service.ErrInvalidSort) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to get products")
 	}
 
-	responses := make([]*model.ProductResponse, len(products))
-	for i, product := range products {
-		responses[i] = product.ToResponse()
+	responses, err := productResponses(products, fields)
+	if err != nil {
+		return server.RespondServerError(c, err, "Failed to get products")
 	}
 
 	return c.JSON(http.StatusOK, map[string]interface{}{
@@ -122,8 +185,138 @@ func (h *Handler) GetProducts(c echo.Context) error {
 	})
 }
 
+// productResponses converts products to their responses, keeping only the selected fields when fields is set
+func productResponses(products []*model.Product, fields *database.FieldSet) ([]interface{}, error) {
+	responses := make([]interface{}, len(products))
+	for i, product := range products {
+		response, err := server.SelectFields(product.ToResponse(), fields.SelectedNames())
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = response
+	}
+	return responses, nil
+}
+
+// getProductsAfterCursor lists products in ID order, ?order=asc (default) or desc, one page per request.
+// The response's next_cursor is passed back as ?cursor= for the following page and is empty on the last page.
+// Filters other than include_archived and custom sorts are not supported with cursors; fields is.
+func (h *Handler) getProductsAfterCursor(c echo.Context, query server.ListQuery, fields *database.FieldSet) error {
+	limit := query.Limit
+	includeArchived := query.Flag("include_archived")
+
+	if query.Sort != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "sort cannot be combined with cursor pagination",
+		})
+	}
+
+	products, next, err := h.service.GetProductsAfterCursor(c.Request().Context(), includeArchived, c.QueryParam("cursor"), c.QueryParam("order"), fields.SelectedColumns(), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to get products")
+	}
+
+	responses, err := productResponses(products, fields)
+	if err != nil {
+		return server.RespondServerError(c, err, "Failed to get products")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"products":    responses,
+		"limit":       limit,
+		"next_cursor": next,
+	})
+}
+
+// BatchGetProducts handles retrieving several products by ID
+// POST /api/products/batch-get
+func (h *Handler) BatchGetProducts(c echo.Context) error {
+	var req model.BatchGetProductsRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	products, missing, err := h.service.GetProductsByIDs(c.Request().Context(), req.IDs)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBatchGet) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to get products")
+	}
+
+	responses := make([]*model.ProductResponse, len(products))
+	for i, product := range products {
+		responses[i] = product.ToResponse()
+	}
+
+	return c.JSON(http.StatusOK, &model.BatchGetProductsResponse{
+		Products:   responses,
+		MissingIDs: missing,
+	})
+}
+
+// DeleteProducts handles deleting several products by ID in one transaction
+// POST /api/products/bulk-delete
+// The response reports how many products were deleted; IDs that did not exist are not counted.
+func (h *Handler) DeleteProducts(c echo.Context) error {
+	var req model.BulkDeleteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	deleted, err := h.service.DeleteProducts(c.Request().Context(), req.IDs)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBulkDelete) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to delete products")
+	}
+
+	return c.JSON(http.StatusOK, &model.BulkDeleteResponse{Deleted: deleted})
+}
+
+// GetProductStats handles counting products per group
+// GET /api/products/stats?group_by=category
+func (h *Handler) GetProductStats(c echo.Context) error {
+	query, err := server.ParseListQuery(c, "group_by", "include_archived")
+	if err == nil {
+		err = query.RequireFlags("include_archived")
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	counts, err := h.service.CountProductsBy(c.Request().Context(), query.Filter("group_by"), query.Flag("include_archived"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidGroupBy) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to get product stats")
+	}
+
+	return c.JSON(http.StatusOK, counts)
+}
+
 // UpdateProduct handles product update
 // PUT /api/products/:id
+// With If-Match the update only applies while the product still has that ETag, otherwise it fails with 412.
 func (h *Handler) UpdateProduct(c echo.Context) error {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -140,24 +333,99 @@ func (h *Handler) UpdateProduct(c echo.Context) error {
 	}
 
 	if err := c.Validate(&req); err != nil {
+		return server.RespondInvalid(c, err)
+	}
+
+	conditional := c.Request().Header.Get("If-Match") != ""
+	if conditional {
+		if proceed, err := h.checkIfMatch(c, uint(id), &req); !proceed || err != nil {
+			return err
+		}
+	}
+
+	// Anonymous updates are recorded in the price history with user 0
+	changedBy, _ := auth.GetUserIDFromContext(c)
+
+	product, err := h.service.UpdateProduct(c.Request().Context(), uint(id), &req, changedBy)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "Product not found",
+			})
+		}
+		if errors.Is(err, service.ErrProductConflict) {
+			if conditional {
+				return server.RespondPreconditionFailed(c)
+			}
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to update product")
+	}
+
+	return server.RespondWithETag(c, http.StatusOK, product.ToResponse())
+}
+
+// checkIfMatch compares the request's If-Match with the current product. When the update must not go ahead
+// it writes the 404 or 412 response and returns false. On a match the update is pinned to the fetched
+// version, so a write landing in between still fails.
+func (h *Handler) checkIfMatch(c echo.Context, id uint, req *model.UpdateProductRequest) (bool, error) {
+	current, err := h.service.GetProductByID(c.Request().Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			return false, c.JSON(http.StatusNotFound, map[string]string{
+				"error": "Product not found",
+			})
+		}
+		return false, server.RespondServerError(c, err, "Failed to update product")
+	}
+
+	matched, err := server.IfMatch(c, current.ToResponse())
+	if err != nil {
+		return false, err
+	}
+	if !matched {
+		return false, server.RespondPreconditionFailed(c)
+	}
+	if req.Version == nil {
+		req.Version = &current.Version
+	}
+	return true, nil
+}
+
+// GetPriceHistory handles listing the price changes of a product, newest first
+// GET /api/products/:id/price-history
+func (h *Handler) GetPriceHistory(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid product ID",
+		})
+	}
+
+	query, err := server.ParseListQuery(c)
+	if err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": err.Error(),
 		})
 	}
 
-	product, err := h.service.UpdateProduct(c.Request().Context(), uint(id), &req)
+	changes, err := h.service.GetPriceHistory(c.Request().Context(), uint(id), query.Limit, query.Offset)
 	if err != nil {
 		if errors.Is(err, service.ErrProductNotFound) {
 			return c.JSON(http.StatusNotFound, map[string]string{
 				"error": "Product not found",
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to update product",
-		})
+		return server.RespondServerError(c, err, "Failed to get price history")
 	}
 
-	return c.JSON(http.StatusOK, product.ToResponse())
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"price_history": changes,
+		"limit":         query.Limit,
+		"offset":        query.Offset,
+	})
 }
 
 // DeleteProduct handles product deletion
@@ -176,14 +444,67 @@ func (h *Handler) DeleteProduct(c echo.Context) error {
 				"error": "Product not found",
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to delete product",
+		return server.RespondServerError(c, err, "Failed to delete product")
+	}
+
+	return server.RespondDeleted(c, h.deleteResponse, uint(id), "Product deleted successfully")
+}
+
+// RestoreProduct handles restoring a soft-deleted product
+// POST /api/products/:id/restore
+func (h *Handler) RestoreProduct(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid product ID",
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Product deleted successfully",
-	})
+	product, err := h.service.RestoreProduct(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "Product not found",
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to restore product")
+	}
+
+	return c.JSON(http.StatusOK, product.ToResponse())
+}
+
+// ArchiveProduct handles taking a product off sale
+// POST /api/products/:id/archive
+func (h *Handler) ArchiveProduct(c echo.Context) error {
+	return h.setArchived(c, h.service.ArchiveProduct, "Failed to archive product")
+}
+
+// UnarchiveProduct handles putting an archived product back on sale
+// POST /api/products/:id/unarchive
+func (h *Handler) UnarchiveProduct(c echo.Context) error {
+	return h.setArchived(c, h.service.UnarchiveProduct, "Failed to unarchive product")
+}
+
+// setArchived runs an archive state change for the product in the path and writes the response
+func (h *Handler) setArchived(c echo.Context, change func(context.Context, uint) (*model.Product, error), failureMsg string) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid product ID",
+		})
+	}
+
+	product, err := change(c.Request().Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "Product not found",
+			})
+		}
+		return server.RespondServerError(c, err, failureMsg)
+	}
+
+	return c.JSON(http.StatusOK, product.ToResponse())
 }
 
 // UpdateStock handles stock update
@@ -216,12 +537,37 @@ func (h *Handler) UpdateStock(c echo.Context) error {
 				"error": err.Error(),
 			})
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to update stock",
-		})
+		return server.RespondServerError(c, err, "Failed to update stock")
 	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "Stock updated successfully",
 	})
 }
+
+// AdjustPrices handles bulk price adjustment for a category
+// POST /api/products/adjust-prices
+func (h *Handler) AdjustPrices(c echo.Context) error {
+	var req model.AdjustPricesRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Invalid request body",
+		})
+	}
+
+	updated, err := h.service.AdjustPrices(c.Request().Context(), req.Category, req.Percent)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidPriceAdjustment) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+		return server.RespondServerError(c, err, "Failed to adjust prices")
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"category": req.Category,
+		"percent":  req.Percent,
+		"updated":  updated,
+	})
+}
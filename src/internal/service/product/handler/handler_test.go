@@ -0,0 +1,612 @@
+// +build cgo
+
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
+	"myapp/internal/pkg/server"
+	"myapp/internal/pkg/validation"
+	"myapp/internal/service/product/handler"
+	"myapp/internal/service/product/model"
+	"myapp/internal/service/product/repository"
+	"myapp/internal/service/product/service"
+	"myapp/internal/testsupport"
+)
+
+const testTenantID = testsupport.DefaultTenantID
+
+// setupTestHandler creates a product handler backed by a SQLite tenant database
+func setupTestHandler(t *testing.T, deleteResponse string) (*handler.Handler, *gorm.DB) {
+	h, tenant := setupTestHandlerTenant(t, deleteResponse)
+	return h, tenant.DB
+}
+
+// setupTestHandlerTenant is setupTestHandler for tests that need the whole test tenant
+func setupTestHandlerTenant(t *testing.T, deleteResponse string) (*handler.Handler, *testsupport.TestTenant) {
+	tenant := testsupport.NewTestTenant(t, &model.Product{}, &model.PriceChange{}, &outbox.Event{})
+	cfg := &config.Config{Server: config.ServerConfig{DeleteResponse: deleteResponse}}
+	require.NoError(t, cfg.Bulk.Validate())
+
+	svc := service.NewService(repository.NewRepository(tenant.DBManager, nil), cfg)
+	return handler.NewHandler(svc, cfg), tenant
+}
+
+// newDeleteContext builds an Echo context for DELETE /api/products/:id scoped to the test tenant
+func newDeleteContext(id string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/api/products/"+id, nil)
+	req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+	rec := httptest.NewRecorder()
+
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	return c, rec
+}
+
+// TestHandler_DeleteProduct tests DELETE success and not-found responses
+func TestHandler_DeleteProduct(t *testing.T) {
+	t.Run("no content on success", func(t *testing.T) {
+		h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+		product := testsupport.NewProduct().Create(t, db)
+
+		c, rec := newDeleteContext(strconv.Itoa(int(product.ID)))
+		require.NoError(t, h.DeleteProduct(c))
+
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+
+		var count int64
+		db.Model(&model.Product{}).Where("id = ?", product.ID).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("structured body on success", func(t *testing.T) {
+		h, db := setupTestHandler(t, config.DeleteResponseStructured)
+		product := testsupport.NewProduct().Create(t, db)
+
+		c, rec := newDeleteContext(strconv.Itoa(int(product.ID)))
+		require.NoError(t, h.DeleteProduct(c))
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"deleted":true,"id":`+strconv.Itoa(int(product.ID))+`}`, rec.Body.String())
+	})
+
+	t.Run("not found for missing product", func(t *testing.T) {
+		h, _ := setupTestHandler(t, config.DeleteResponseNoContent)
+
+		c, rec := newDeleteContext("9999")
+		require.NoError(t, h.DeleteProduct(c))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.JSONEq(t, `{"error":"Product not found"}`, rec.Body.String())
+	})
+}
+
+// TestHandler_DeleteProducts tests the bulk delete count and its request validation
+func TestHandler_DeleteProducts(t *testing.T) {
+	h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+	first := testsupport.NewProduct().Create(t, db)
+	second := testsupport.NewProduct().Create(t, db)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/products/bulk-delete", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.DeleteProducts(e.NewContext(req, rec)))
+		return rec
+	}
+
+	rec := post(fmt.Sprintf(`{"ids":[%d,%d,424242]}`, first.ID, second.ID))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"deleted":2}`, rec.Body.String())
+
+	var count int64
+	require.NoError(t, db.Model(&model.Product{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	rec = post(`{"ids":[]}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// TestHandler_GetProducts_IncludeArchived tests that archived products are listed only with ?include_archived=true
+func TestHandler_GetProducts_IncludeArchived(t *testing.T) {
+	h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+	testsupport.NewProduct().WithSKU("SKU-1").Create(t, db)
+	testsupport.NewProduct().WithSKU("SKU-2").Archived().Create(t, db)
+
+	list := func(query string) []model.ProductResponse {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/products"+query, nil)
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.GetProducts(e.NewContext(req, rec)))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var body struct {
+			Products []model.ProductResponse `json:"products"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body.Products
+	}
+
+	products := list("")
+	require.Len(t, products, 1)
+	assert.Equal(t, "SKU-1", products[0].SKU)
+
+	products = list("?include_archived=true")
+	assert.Len(t, products, 2)
+}
+
+func TestHandler_GetProducts_InvalidSort(t *testing.T) {
+	h, _ := setupTestHandler(t, config.DeleteResponseNoContent)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/products?sort=category,-password", nil)
+	req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.GetProducts(e.NewContext(req, rec)))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "password")
+}
+
+// TestHandler_GetProducts_InvalidPaging tests that malformed limit and offset are rejected instead of ignored
+func TestHandler_GetProducts_InvalidPaging(t *testing.T) {
+	h, _ := setupTestHandler(t, config.DeleteResponseNoContent)
+
+	tests := map[string]string{
+		"?limit=abc":       `{"error":"limit must be a non-negative integer"}`,
+		"?offset=-1":       `{"error":"offset must be a non-negative integer"}`,
+		"?cursor=&limit=x": `{"error":"limit must be a non-negative integer"}`,
+	}
+	for query, body := range tests {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/products"+query, nil)
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.GetProducts(e.NewContext(req, rec)))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.JSONEq(t, body, rec.Body.String(), query)
+	}
+}
+
+// TestHandler_GetProducts_Cursor tests paging through products by following next_cursor
+func TestHandler_GetProducts_Cursor(t *testing.T) {
+	h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+	var skus []string
+	for i := 0; i < 7; i++ {
+		sku := fmt.Sprintf("SKU-%d", i)
+		testsupport.NewProduct().WithSKU(sku).Create(t, db)
+		skus = append(skus, sku)
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/products"+query, nil)
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.GetProducts(e.NewContext(req, rec)))
+		return rec
+	}
+
+	pageAll := func(order string) []string {
+		var seen []string
+		cursor := ""
+		for pages := 0; pages < 5; pages++ {
+			rec := get("?limit=3&order=" + order + "&cursor=" + cursor)
+			require.Equal(t, http.StatusOK, rec.Code)
+
+			var body struct {
+				Products   []model.ProductResponse `json:"products"`
+				NextCursor string                  `json:"next_cursor"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			for _, product := range body.Products {
+				seen = append(seen, product.SKU)
+			}
+			if body.NextCursor == "" {
+				return seen
+			}
+			cursor = body.NextCursor
+		}
+		t.Fatal("paging did not terminate")
+		return nil
+	}
+
+	assert.Equal(t, skus, pageAll("asc"))
+
+	reversed := make([]string, len(skus))
+	for i, sku := range skus {
+		reversed[len(skus)-1-i] = sku
+	}
+	assert.Equal(t, reversed, pageAll("desc"))
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		rec := get("?cursor=not-a-cursor")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("sort is rejected", func(t *testing.T) {
+		rec := get("?cursor=&sort=name")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+// TestHandler_GetProducts_Fields tests that ?fields= limits both the response keys and the selected columns
+func TestHandler_GetProducts_Fields(t *testing.T) {
+	h, tenant := setupTestHandlerTenant(t, config.DeleteResponseNoContent)
+	db := tenant.DB
+	testsupport.NewProduct().WithName("Monitor").WithSKU("SKU-1").WithCategory("displays").WithPrice("300").Create(t, db)
+	testsupport.NewProduct().WithName("Keyboard").WithSKU("SKU-2").WithCategory("peripherals").WithPrice("25").Create(t, db)
+	testsupport.NewProduct().WithName("Mouse").WithSKU("SKU-3").WithCategory("peripherals").WithPrice("15").Create(t, db)
+
+	var queries []string
+	require.NoError(t, tenant.ConnDB(t).Callback().Query().After("gorm:query").Register("test:capture_sql", func(tx *gorm.DB) {
+		queries = append(queries, tx.Statement.SQL.String())
+	}))
+
+	get := func(t *testing.T, h *handler.Handler, query string) *httptest.ResponseRecorder {
+		queries = nil
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/products"+query, nil)
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.GetProducts(e.NewContext(req, rec)))
+		return rec
+	}
+	products := func(t *testing.T, rec *httptest.ResponseRecorder) []map[string]json.RawMessage {
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var body struct {
+			Products []map[string]json.RawMessage `json:"products"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return body.Products
+	}
+	keys := func(product map[string]json.RawMessage) []string {
+		result := make([]string, 0, len(product))
+		for key := range product {
+			result = append(result, key)
+		}
+		return result
+	}
+
+	t.Run("subset with filter and pagination", func(t *testing.T) {
+		rec := get(t, h, "?fields=id,name,price&category=peripherals&sort=price&limit=1&offset=1")
+		listed := products(t, rec)
+		require.Len(t, listed, 1)
+		assert.ElementsMatch(t, []string{"id", "name", "price"}, keys(listed[0]))
+		assert.JSONEq(t, `"Keyboard"`, string(listed[0]["name"]))
+		assert.JSONEq(t, `25.00`, string(listed[0]["price"]))
+
+		require.Len(t, queries, 1)
+		assert.Contains(t, queries[0], "SELECT `id`,`name`,`price` FROM `products`")
+	})
+
+	t.Run("cursor pagination", func(t *testing.T) {
+		rec := get(t, h, "?fields=sku&cursor=&limit=2")
+		listed := products(t, rec)
+		require.Len(t, listed, 2)
+		for _, product := range listed {
+			assert.Equal(t, []string{"sku"}, keys(product))
+		}
+		assert.Contains(t, rec.Body.String(), `"next_cursor":"`)
+	})
+
+	t.Run("no fields returns everything", func(t *testing.T) {
+		listed := products(t, get(t, h, ""))
+		require.Len(t, listed, 3)
+		assert.Contains(t, keys(listed[0]), "description")
+		assert.Contains(t, queries[0], "SELECT * FROM `products`")
+	})
+
+	t.Run("unknown fields are ignored by default", func(t *testing.T) {
+		listed := products(t, get(t, h, "?fields=name,password"))
+		require.Len(t, listed, 3)
+		assert.Equal(t, []string{"name"}, keys(listed[0]))
+	})
+
+	t.Run("unknown fields are rejected when configured", func(t *testing.T) {
+		cfg := &config.Config{Server: config.ServerConfig{UnknownFields: config.UnknownFieldsReject}}
+		require.NoError(t, cfg.Bulk.Validate())
+		strict := handler.NewHandler(service.NewService(repository.NewRepository(testsupport.NewTestTenant(t, &model.Product{}, &outbox.Event{}).DBManager, nil), cfg), cfg)
+
+		rec := get(t, strict, "?fields=name,password")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "password")
+	})
+}
+
+// TestHandler_CreateProducts tests the batch create response for a clean batch and for invalid items
+func TestHandler_CreateProducts(t *testing.T) {
+	h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/products/batch", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.CreateProducts(e.NewContext(req, rec)))
+		return rec
+	}
+
+	t.Run("created", func(t *testing.T) {
+		rec := post(`{"products":[{"name":"Cable","price":5,"sku":"C-1"},{"name":"Plug","price":2.5,"sku":"C-2"}]}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		var body struct {
+			Products []model.ProductResponse `json:"products"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Len(t, body.Products, 2)
+		assert.Equal(t, "C-2", body.Products[1].SKU)
+	})
+
+	t.Run("invalid items", func(t *testing.T) {
+		rec := post(`{"products":[{"name":"Lamp","price":5,"sku":"L-1"},{"name":"Lamp","price":5,"sku":"L-1"},{"name":"L","price":5,"sku":"L-3"}]}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var body struct {
+			Error  string                  `json:"error"`
+			Fields []validation.FieldError `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, validation.Message, body.Error)
+		require.Len(t, body.Fields, 2)
+		assert.Equal(t, validation.FieldError{Field: "products[1].sku", Tag: "unique", Message: "duplicate SKU, also used by item 0"}, body.Fields[0])
+		assert.Equal(t, "products[2].name", body.Fields[1].Field)
+		assert.Equal(t, "min", body.Fields[1].Tag)
+
+		var count int64
+		db.Model(&model.Product{}).Where("sku = ?", "L-1").Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		rec := post(`{"products":[]}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+// TestHandler_CreateProduct_DeletedSKU tests that re-creating the SKU of a deleted product is a conflict, not a 500
+func TestHandler_CreateProduct_DeletedSKU(t *testing.T) {
+	h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+	product := testsupport.NewProduct().WithSKU("SKU-DELETED").Create(t, db)
+
+	c, _ := newDeleteContext(strconv.Itoa(int(product.ID)))
+	require.NoError(t, h.DeleteProduct(c))
+
+	e := echo.New()
+	e.Validator = server.NewValidator()
+	req := httptest.NewRequest(http.MethodPost, "/api/products", strings.NewReader(`{"name":"Again","sku":"SKU-DELETED","price":"9.99"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+	rec := httptest.NewRecorder()
+	require.NoError(t, h.CreateProduct(e.NewContext(req, rec)))
+
+	assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+}
+
+// TestHandler_ConditionalRequests tests ETags on GET and PUT /api/products/:id
+func TestHandler_ConditionalRequests(t *testing.T) {
+	h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+	product := testsupport.NewProduct().WithName("Keyboard").Create(t, db)
+	id := strconv.Itoa(int(product.ID))
+
+	serve := func(method, body string, header http.Header, handle func(*handler.Handler, echo.Context) error) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Validator = server.NewValidator()
+		req := httptest.NewRequest(method, "/api/products/"+id, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		require.NoError(t, handle(h, c))
+		return rec
+	}
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		return serve(http.MethodGet, "", header, (*handler.Handler).GetProduct)
+	}
+	update := func(body string, header http.Header) *httptest.ResponseRecorder {
+		return serve(http.MethodPut, body, header, (*handler.Handler).UpdateProduct)
+	}
+
+	first := get(nil)
+	require.Equal(t, http.StatusOK, first.Code)
+	etag := first.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Contains(t, first.Body.String(), "Keyboard")
+
+	t.Run("unchanged product is not modified", func(t *testing.T) {
+		rec := get(http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+		assert.Equal(t, etag, rec.Header().Get("ETag"))
+
+		rec = get(http.Header{"If-None-Match": {`"stale"`}})
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("update with current etag", func(t *testing.T) {
+		rec := update(`{"name":"Keyboard Pro"}`, http.Header{"If-Match": {etag}})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+
+		rec = get(http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusOK, rec.Code, "the old etag no longer matches")
+	})
+
+	t.Run("update with stale etag", func(t *testing.T) {
+		rec := update(`{"name":"Keyboard Max"}`, http.Header{"If-Match": {etag}})
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+		var stored model.Product
+		require.NoError(t, db.First(&stored, product.ID).Error)
+		assert.Equal(t, "Keyboard Pro", stored.Name)
+	})
+}
+
+func TestHandler_CreateProduct_Validation(t *testing.T) {
+	h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		e.Validator = server.NewValidator()
+		req := httptest.NewRequest(http.MethodPost, "/api/products", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.CreateProduct(e.NewContext(req, rec)))
+		return rec
+	}
+
+	fieldErrors := func(t *testing.T, rec *httptest.ResponseRecorder) map[string]string {
+		require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		var body struct {
+			Fields []validation.FieldError `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		messages := make(map[string]string, len(body.Fields))
+		for _, f := range body.Fields {
+			messages[f.Field] = f.Message
+		}
+		return messages
+	}
+
+	t.Run("missing fields", func(t *testing.T) {
+		rec := post(`{"description":"no name, price or sku"}`)
+		assert.Equal(t, map[string]string{
+			"name":  "name is required",
+			"price": "price is required",
+			"sku":   "sku is required",
+		}, fieldErrors(t, rec))
+	})
+
+	t.Run("out of range fields", func(t *testing.T) {
+		rec := post(`{"name":"TV","price":-1,"stock":-5,"sku":"S1","category":"` + strings.Repeat("c", 101) + `"}`)
+		assert.Equal(t, map[string]string{
+			"name":     "name must be at least 3 characters",
+			"price":    "price must be greater than 0",
+			"stock":    "stock must be 0 or greater",
+			"sku":      "sku must be at least 3 characters",
+			"category": "category must be at most 100 characters",
+		}, fieldErrors(t, rec))
+
+		var count int64
+		db.Model(&model.Product{}).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("valid body", func(t *testing.T) {
+		rec := post(`{"name":"Television","price":499.99,"stock":3,"sku":"TV-1","category":"electronics"}`)
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	})
+}
+
+func TestHandler_GetProductStats(t *testing.T) {
+	h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+	testsupport.NewProduct().WithCategory("displays").Create(t, db)
+	testsupport.NewProduct().WithCategory("displays").Create(t, db)
+	testsupport.NewProduct().WithCategory("peripherals").Create(t, db)
+
+	stats := func(query string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/products/stats"+query, nil)
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.GetProductStats(e.NewContext(req, rec)))
+		return rec
+	}
+
+	t.Run("group by category", func(t *testing.T) {
+		rec := stats("?group_by=category")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var counts map[string]int64
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &counts))
+		assert.Equal(t, map[string]int64{"displays": 2, "peripherals": 1}, counts)
+	})
+
+	t.Run("invalid group_by", func(t *testing.T) {
+		rec := stats("?group_by=password")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid include_archived", func(t *testing.T) {
+		rec := stats("?group_by=category&include_archived=yes")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "include_archived must be true or false")
+	})
+}
+
+func TestHandler_BatchGetProducts(t *testing.T) {
+	h, db := setupTestHandler(t, config.DeleteResponseNoContent)
+	keyboard := testsupport.NewProduct().WithSKU("KB-1").Create(t, db)
+	mouse := testsupport.NewProduct().WithSKU("MS-1").Create(t, db)
+
+	batchGet := func(body string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/products/batch-get", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(database.WithTenantID(req.Context(), testTenantID))
+		rec := httptest.NewRecorder()
+		require.NoError(t, h.BatchGetProducts(e.NewContext(req, rec)))
+		return rec
+	}
+
+	t.Run("found and missing ids", func(t *testing.T) {
+		body := fmt.Sprintf(`{"ids":[%d,9999,%d]}`, mouse.ID, keyboard.ID)
+		rec := batchGet(body)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.BatchGetProductsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Products, 2)
+		assert.Equal(t, "MS-1", resp.Products[0].SKU)
+		assert.Equal(t, "KB-1", resp.Products[1].SKU)
+		assert.Equal(t, []uint{9999}, resp.MissingIDs)
+	})
+
+	t.Run("all found", func(t *testing.T) {
+		rec := batchGet(fmt.Sprintf(`{"ids":[%d]}`, keyboard.ID))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp model.BatchGetProductsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Len(t, resp.Products, 1)
+		assert.Empty(t, resp.MissingIDs)
+	})
+
+	t.Run("empty ids", func(t *testing.T) {
+		rec := batchGet(`{"ids":[]}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		rec := batchGet(`{"ids":"abc"}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
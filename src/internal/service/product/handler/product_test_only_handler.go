@@ -5,18 +5,21 @@ import (
 	"net/http"
 	"strconv"
 	"github.com/labstack/echo/v4"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/server"
 	"myapp/internal/service/product/dto"
 	"myapp/internal/service/product/service"
 )
 
 // ProductTestOnlyHandler handles product test only HTTP requests
 type ProductTestOnlyHandler struct {
-	service *service.ProductTestOnlyService
+	service        *service.ProductTestOnlyService
+	deleteResponse string
 }
 
 // NewProductTestOnlyHandler creates a new product test only handler
-func NewProductTestOnlyHandler(service *service.ProductTestOnlyService) *ProductTestOnlyHandler {
-	return &ProductTestOnlyHandler{service: service}
+func NewProductTestOnlyHandler(service *service.ProductTestOnlyService, cfg *config.Config) *ProductTestOnlyHandler {
+	return &ProductTestOnlyHandler{service: service, deleteResponse: cfg.Server.DeleteResponse}
 }
 
 // CreateProductTestOnly handles product test only creation
@@ -30,9 +33,7 @@ func (h *ProductTestOnlyHandler) CreateProductTestOnly(c echo.Context) error {
 	}
 
 	if err := c.Validate(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return server.RespondInvalid(c, err)
 	}
 
 	response, err := h.service.CreateProductTestOnly(c.Request().Context(), &req)
@@ -103,8 +104,13 @@ func (h *ProductTestOnlyHandler) GetProductTestOnlyByCode(c echo.Context) error
 // GetAllProductTestOnly handles retrieving all product test only records
 // GET /api/product-test-only
 func (h *ProductTestOnlyHandler) GetAllProductTestOnly(c echo.Context) error {
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	query, err := server.ParseListQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	limit, offset := query.Limit, query.Offset
 
 	responses, err := h.service.GetAllProductTestOnly(c.Request().Context(), limit, offset)
 	if err != nil {
@@ -130,8 +136,13 @@ func (h *ProductTestOnlyHandler) GetProductTestOnlyByType(c echo.Context) error
 		})
 	}
 
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	query, err := server.ParseListQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	limit, offset := query.Limit, query.Offset
 
 	responses, err := h.service.GetProductTestOnlyByType(c.Request().Context(), entityType, limit, offset)
 	if err != nil {
@@ -157,8 +168,13 @@ func (h *ProductTestOnlyHandler) SearchProductTestOnly(c echo.Context) error {
 		})
 	}
 
-	limit, _ := strconv.Atoi(c.QueryParam("limit"))
-	offset, _ := strconv.Atoi(c.QueryParam("offset"))
+	query, err := server.ParseListQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	limit, offset := query.Limit, query.Offset
 
 	responses, err := h.service.SearchProductTestOnly(c.Request().Context(), name, limit, offset)
 	if err != nil {
@@ -192,9 +208,7 @@ func (h *ProductTestOnlyHandler) UpdateProductTestOnly(c echo.Context) error {
 	}
 
 	if err := c.Validate(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": err.Error(),
-		})
+		return server.RespondInvalid(c, err)
 	}
 
 	response, err := h.service.UpdateProductTestOnly(c.Request().Context(), uint(id), &req)
@@ -239,7 +253,5 @@ func (h *ProductTestOnlyHandler) DeleteProductTestOnly(c echo.Context) error {
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Product test only deleted successfully",
-	})
+	return server.RespondDeleted(c, h.deleteResponse, uint(id), "Product test only deleted successfully")
 }
@@ -1,9 +1,15 @@
 package migration
 
 import (
+	"context"
 	"fmt"
 
+	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
+	"myapp/internal/pkg/parallel"
 	"myapp/internal/service/product/model"
 )
 
@@ -13,10 +19,18 @@ func RunMigrations(db *gorm.DB) error {
 		return fmt.Errorf("failed to migrate product table: %w", err)
 	}
 
+	if err := db.AutoMigrate(&model.PriceChange{}); err != nil {
+		return fmt.Errorf("failed to migrate product_price_history table: %w", err)
+	}
+
 	if err := db.AutoMigrate(&model.ProductTestOnly{}); err != nil {
 		return fmt.Errorf("failed to migrate product_test_only table: %w", err)
 	}
 
+	if err := outbox.Migrate(db); err != nil {
+		return err
+	}
+
 	// Add any additional migrations here
 	if err := createIndexes(db); err != nil {
 		return fmt.Errorf("failed to create indexes: %w", err)
@@ -25,6 +39,46 @@ func RunMigrations(db *gorm.DB) error {
 	return nil
 }
 
+// RunTenantMigrations runs product migrations against every active tenant database,
+// processing up to the manager's configured concurrency at a time. Failures are
+// collected per tenant so one broken tenant does not block the others.
+func RunTenantMigrations(ctx context.Context, connManager *database.TenantConnectionManager) error {
+	tenantIDs, err := connManager.ActiveTenantIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	return parallel.ForEach(ctx, tenantIDs, connManager.Concurrency(), func(ctx context.Context, _ int, tenantID string) error {
+		db, err := connManager.GetTenantDB(ctx, tenantID)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+		if err := RunMigrations(db.WithContext(ctx)); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+		return nil
+	})
+}
+
+// RegisterTenantMigrations registers the product schema with the connection manager,
+// so tenants created with ProvisionTenant start with the product tables.
+// When tenant_database.auto_migrate is enabled, existing active tenants are migrated on startup.
+func RegisterTenantMigrations(cfg *config.Config, dbManager *database.DatabaseManager, logger *zap.Logger) {
+	dbManager.TenantConnManager.RegisterTenantMigration(RunMigrations)
+
+	if !cfg.TenantDatabase.AutoMigrate {
+		logger.Info("Skipping product tenant migrations, tenant_database.auto_migrate is disabled")
+		return
+	}
+
+	if err := RunTenantMigrations(context.Background(), dbManager.TenantConnManager); err != nil {
+		logger.Error("Failed to run product tenant migrations", zap.Error(err))
+		return
+	}
+
+	logger.Info("Product tenant migrations completed")
+}
+
 // createIndexes creates additional database indexes
 func createIndexes(db *gorm.DB) error {
 	// Product indexes
@@ -85,7 +139,7 @@ func Seed(db *gorm.DB) error {
 		{
 			Name:        "Sample Product 1",
 			Description: "This is a sample product for testing",
-			Price:       29.99,
+			Price:       model.MustParseMoney("29.99"),
 			Stock:       100,
 			SKU:         "SAMPLE-001",
 			Category:    "Electronics",
@@ -94,7 +148,7 @@ func Seed(db *gorm.DB) error {
 		{
 			Name:        "Sample Product 2",
 			Description: "Another sample product",
-			Price:       49.99,
+			Price:       model.MustParseMoney("49.99"),
 			Stock:       50,
 			SKU:         "SAMPLE-002",
 			Category:    "Books",
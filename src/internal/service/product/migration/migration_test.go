@@ -0,0 +1,101 @@
+package migration_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/service/product/migration"
+	"myapp/internal/service/product/model"
+)
+
+// TestRunTenantMigrations tests that migrations run against every active tenant and failures are aggregated
+func TestRunTenantMigrations(t *testing.T) {
+	dir := t.TempDir()
+
+	masterDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, masterDB.AutoMigrate(&database.Tenant{}))
+
+	healthy := []string{"tenant-a", "tenant-b", "tenant-c"}
+	for _, id := range healthy {
+		require.NoError(t, masterDB.Create(&database.Tenant{
+			ID:       id,
+			Name:     id,
+			DBType:   "sqlite",
+			Cnn:      filepath.Join(dir, id+".db"),
+			IsActive: true,
+		}).Error)
+	}
+	require.NoError(t, masterDB.Create(&database.Tenant{
+		ID:       "tenant-broken",
+		Name:     "tenant-broken",
+		DBType:   "oracle",
+		Cnn:      "unused",
+		IsActive: true,
+	}).Error)
+
+	connManager := database.NewTenantConnectionManager(masterDB, zaptest.NewLogger(t), database.WithConcurrency(2))
+	t.Cleanup(func() { connManager.CloseAll() })
+
+	err = migration.RunTenantMigrations(context.Background(), connManager)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tenant tenant-broken")
+
+	for _, id := range healthy {
+		db, err := connManager.GetTenantDB(context.Background(), id)
+		require.NoError(t, err)
+		assert.True(t, db.Migrator().HasTable(&model.Product{}), "tenant %s should be migrated", id)
+	}
+}
+
+// TestRegisterTenantMigrations_AutoMigrate tests that existing tenants are migrated on startup only when tenant_database.auto_migrate is set
+func TestRegisterTenantMigrations_AutoMigrate(t *testing.T) {
+	setup := func(t *testing.T) *database.DatabaseManager {
+		masterDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+		require.NoError(t, err)
+		require.NoError(t, masterDB.AutoMigrate(&database.Tenant{}))
+		require.NoError(t, masterDB.Create(&database.Tenant{
+			ID:       "tenant-a",
+			Name:     "tenant-a",
+			DBType:   "sqlite",
+			Cnn:      filepath.Join(t.TempDir(), "tenant-a.db"),
+			IsActive: true,
+		}).Error)
+
+		connManager := database.NewTenantConnectionManager(masterDB, zaptest.NewLogger(t))
+		t.Cleanup(func() { connManager.CloseAll() })
+		return &database.DatabaseManager{MasterDB: masterDB, TenantConnManager: connManager}
+	}
+
+	hasProducts := func(t *testing.T, dbManager *database.DatabaseManager) bool {
+		db, err := dbManager.TenantConnManager.GetTenantDB(context.Background(), "tenant-a")
+		require.NoError(t, err)
+		return db.Migrator().HasTable(&model.Product{})
+	}
+
+	t.Run("disabled leaves tenants untouched", func(t *testing.T) {
+		dbManager := setup(t)
+		cfg := &config.Config{TenantDatabase: config.DatabaseConfig{AutoMigrate: false}}
+
+		migration.RegisterTenantMigrations(cfg, dbManager, zaptest.NewLogger(t))
+
+		assert.False(t, hasProducts(t, dbManager))
+	})
+
+	t.Run("enabled migrates active tenants", func(t *testing.T) {
+		dbManager := setup(t)
+		cfg := &config.Config{TenantDatabase: config.DatabaseConfig{AutoMigrate: true}}
+
+		migration.RegisterTenantMigrations(cfg, dbManager, zaptest.NewLogger(t))
+
+		assert.True(t, hasProducts(t, dbManager))
+	})
+}
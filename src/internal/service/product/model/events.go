@@ -0,0 +1,17 @@
+package model
+
+// Outbox events recorded with product changes
+const (
+	// AggregateProduct is the aggregate type of product events
+	AggregateProduct = "product"
+
+	EventProductCreated  = "product.created"  // Payload is the ProductResponse
+	EventProductUpdated  = "product.updated"  // Payload is the ProductResponse after the update
+	EventProductDeleted  = "product.deleted"  // Payload is the ProductDeletedEvent
+	EventProductRestored = "product.restored" // Payload is the ProductResponse after the restore
+)
+
+// ProductDeletedEvent is the payload of a product.deleted event
+type ProductDeletedEvent struct {
+	ID uint `json:"id"`
+}
@@ -2,20 +2,70 @@ package model
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Product represents a product entity
 type Product struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	Name        string    `gorm:"type:varchar(255);not null" json:"name"`
-	Description string    `gorm:"type:text" json:"description"`
-	Price       float64   `gorm:"type:decimal(10,2);not null" json:"price"`
-	Stock       int       `gorm:"type:int;default:0" json:"stock"`
-	SKU         string    `gorm:"type:varchar(100);uniqueIndex" json:"sku"`
-	Category    string    `gorm:"type:varchar(100)" json:"category"`
-	IsActive    bool      `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint           `gorm:"primaryKey" json:"id"`
+	Name        string         `gorm:"type:varchar(255);not null" json:"name"`
+	Description string         `gorm:"type:text" json:"description"`
+	Price       Money          `gorm:"type:decimal(10,2);not null" json:"price"`
+	Stock       int            `gorm:"type:int;default:0" json:"stock"`
+	SKU         string         `gorm:"type:varchar(100);uniqueIndex" json:"sku"` // Unique within a tenant database
+	Category    string         `gorm:"type:varchar(100)" json:"category"`
+	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	ArchivedAt  *time.Time     `gorm:"index" json:"archived_at,omitempty"`
+	Version     uint           `gorm:"not null;default:0" json:"version"` // Incremented by every update, for optimistic locking
+	CreatedBy   uint           `gorm:"not null;default:0" json:"created_by"` // User who created the product, 0 for system writes
+	UpdatedBy   uint           `gorm:"not null;default:0" json:"updated_by"` // User who last modified the product
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// MinPrice is the lowest price a product can have; bulk adjustments stop there instead of reaching zero
+const MinPrice Money = 1
+
+// SortFields maps the field names accepted in the sort query parameter to their columns
+var SortFields = map[string]string{
+	"id":         "id",
+	"name":       "name",
+	"price":      "price",
+	"stock":      "stock",
+	"sku":        "sku",
+	"category":   "category",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// SelectFields maps the field names accepted in the fields query parameter to their columns
+var SelectFields = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"description": "description",
+	"price":       "price",
+	"stock":       "stock",
+	"sku":         "sku",
+	"category":    "category",
+	"is_active":   "is_active",
+	"archived_at": "archived_at",
+	"version":     "version",
+	"created_by":  "created_by",
+	"updated_by":  "updated_by",
+	"created_at":  "created_at",
+	"updated_at":  "updated_at",
+}
+
+// GroupByFields maps the field names accepted by the stats group_by parameter to model fields
+var GroupByFields = map[string]string{
+	"category": "Category",
+}
+
+// IsArchived reports whether the product has been taken off sale
+func (p *Product) IsArchived() bool {
+	return p.ArchivedAt != nil
 }
 
 // TableName specifies the table name for Product
@@ -25,36 +75,82 @@ func (Product) TableName() string {
 
 // CreateProductRequest represents product creation request
 type CreateProductRequest struct {
-	Name        string  `json:"name" validate:"required,min=3,max=255"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
-	Stock       int     `json:"stock" validate:"gte=0"`
-	SKU         string  `json:"sku" validate:"required,min=3,max=100"`
-	Category    string  `json:"category"`
+	Name        string `json:"name" validate:"required,min=3,max=255"`
+	Description string `json:"description"`
+	Price       Money  `json:"price" validate:"required,gt=0"`
+	Stock       int    `json:"stock" validate:"gte=0"`
+	SKU         string `json:"sku" validate:"required,min=3,max=100"`
+	Category    string `json:"category" validate:"max=100"`
 }
 
 // UpdateProductRequest represents product update request
 type UpdateProductRequest struct {
-	Name        *string  `json:"name" validate:"omitempty,min=3,max=255"`
-	Description *string  `json:"description"`
-	Price       *float64 `json:"price" validate:"omitempty,gt=0"`
-	Stock       *int     `json:"stock" validate:"omitempty,gte=0"`
-	Category    *string  `json:"category"`
-	IsActive    *bool    `json:"is_active"`
+	Name        *string `json:"name" validate:"omitempty,min=3,max=255"`
+	Description *string `json:"description"`
+	Price       *Money  `json:"price" validate:"omitempty,gt=0"`
+	Stock       *int    `json:"stock" validate:"omitempty,gte=0"`
+	Category    *string `json:"category" validate:"omitempty,max=100"`
+	IsActive    *bool   `json:"is_active"`
+	Version     *uint   `json:"version"` // When set, the update is rejected if the product changed since this version
+}
+
+// AdjustPricesRequest represents a bulk price adjustment request
+type AdjustPricesRequest struct {
+	Category string  `json:"category" validate:"required"`
+	Percent  float64 `json:"percent" validate:"required"` // Rounded to two decimals
+}
+
+// BatchCreateProductsRequest represents a request to create several products at once
+type BatchCreateProductsRequest struct {
+	Products []*CreateProductRequest `json:"products" validate:"required,min=1,dive"`
+}
+
+// BatchItemError describes why one item of a batch request was rejected
+type BatchItemError struct {
+	Index int    `json:"index"` // Position of the item in the request
+	SKU   string `json:"sku,omitempty"`
+	Field string `json:"field,omitempty"` // JSON name of the rejected field within the item; empty for the item itself
+	Tag   string `json:"tag"`             // The failed rule, a validate tag such as required or unique
+	Error string `json:"error"`
+}
+
+// BatchGetProductsRequest represents a request for several products by ID
+type BatchGetProductsRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1"`
+}
+
+// BulkDeleteRequest represents a request to delete several products by ID
+type BulkDeleteRequest struct {
+	IDs []uint `json:"ids" validate:"required,min=1"`
+}
+
+// BulkDeleteResponse reports how many of the requested products were deleted
+type BulkDeleteResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// BatchGetProductsResponse lists the products found and the requested IDs that were not
+type BatchGetProductsResponse struct {
+	Products   []*ProductResponse `json:"products"`
+	MissingIDs []uint             `json:"missing_ids"`
 }
 
 // ProductResponse represents product response
 type ProductResponse struct {
-	ID          uint      `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	SKU         string    `json:"sku"`
-	Category    string    `json:"category"`
-	IsActive    bool      `json:"is_active"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint       `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Price       Money      `json:"price"`
+	Stock       int        `json:"stock"`
+	SKU         string     `json:"sku"`
+	Category    string     `json:"category"`
+	IsActive    bool       `json:"is_active"`
+	ArchivedAt  *time.Time `json:"archived_at,omitempty"`
+	Version     uint       `json:"version"`
+	CreatedBy   uint       `json:"created_by"`
+	UpdatedBy   uint       `json:"updated_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
 // ToResponse converts Product to ProductResponse
@@ -68,6 +164,10 @@ func (p *Product) ToResponse() *ProductResponse {
 		SKU:         p.SKU,
 		Category:    p.Category,
 		IsActive:    p.IsActive,
+		ArchivedAt:  p.ArchivedAt,
+		Version:     p.Version,
+		CreatedBy:   p.CreatedBy,
+		UpdatedBy:   p.UpdatedBy,
 		CreatedAt:   p.CreatedAt,
 		UpdatedAt:   p.UpdatedAt,
 	}
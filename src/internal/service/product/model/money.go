@@ -0,0 +1,162 @@
+package model
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in cents. Arithmetic on Money is exact integer math; it is stored in a
+// decimal(10,2) column and serialized as a JSON number with two decimals, e.g. 29.99.
+type Money int64
+
+// ErrInvalidMoney is returned when a value cannot be parsed as an amount with at most two decimals
+var ErrInvalidMoney = errors.New("invalid money amount")
+
+// centsPerUnit is the number of cents in one currency unit
+const centsPerUnit = 100
+
+// basisPointsPerUnit is the number of basis points (1/100 of a percent) in 100%
+const basisPointsPerUnit = 10000
+
+// ParseMoney parses a decimal amount such as "25", "25.5" or "-0.99" without going through float64
+func ParseMoney(s string) (Money, error) {
+	value := strings.TrimSpace(s)
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, fraction, hasFraction := strings.Cut(value, ".")
+	if whole == "" || !isDigits(whole) || (hasFraction && (fraction == "" || !isDigits(fraction))) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMoney, s)
+	}
+	if len(fraction) > 2 {
+		return 0, fmt.Errorf("%w: %q has more than two decimals", ErrInvalidMoney, s)
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || units > math.MaxInt64/centsPerUnit-1 {
+		return 0, fmt.Errorf("%w: %q is out of range", ErrInvalidMoney, s)
+	}
+	cents := units * centsPerUnit
+	if fraction != "" {
+		fractionCents, _ := strconv.ParseInt((fraction + "0")[:2], 10, 64)
+		cents += fractionCents
+	}
+	if negative {
+		cents = -cents
+	}
+	return Money(cents), nil
+}
+
+// MustParseMoney is like ParseMoney but panics on invalid input; it is meant for constants such as seed data
+func MustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// isDigits reports whether s consists only of ASCII digits
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Cents returns the amount in cents
+func (m Money) Cents() int64 {
+	return int64(m)
+}
+
+// String formats the amount with exactly two decimals
+func (m Money) String() string {
+	cents := int64(m)
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/centsPerUnit, cents%centsPerUnit)
+}
+
+// AdjustByBasisPoints changes the amount by basisPoints (1000 = +10%), rounding half away from zero to the cent
+func (m Money) AdjustByBasisPoints(basisPoints int64) Money {
+	scaled := int64(m) * (basisPointsPerUnit + basisPoints)
+	cents := scaled / basisPointsPerUnit
+	remainder := scaled % basisPointsPerUnit
+	if remainder*2 >= basisPointsPerUnit {
+		cents++
+	} else if remainder*2 <= -basisPointsPerUnit {
+		cents--
+	}
+	return Money(cents)
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimals
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding a decimal amount with at most two decimals
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := string(data)
+	if value == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		value = unquoted
+	}
+	parsed, err := ParseMoney(value)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as a decimal string so the database never sees a float
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan reads a decimal column, which drivers return as text, an integer or a float
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case int64:
+		*m = Money(v * centsPerUnit)
+		return nil
+	case float64:
+		*m = Money(math.Round(v * centsPerUnit))
+		return nil
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidMoney, src)
+	}
+}
+
+// scanString parses a decimal column value, trimming trailing zeros beyond the second decimal
+func (m *Money) scanString(s string) error {
+	if whole, fraction, ok := strings.Cut(s, "."); ok && len(fraction) > 2 {
+		s = whole + "." + strings.TrimRight(fraction, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	parsed, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,121 @@
+package model_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"myapp/internal/service/product/model"
+)
+
+// TestParseMoney tests parsing decimal amounts into cents
+func TestParseMoney(t *testing.T) {
+	valid := map[string]int64{
+		"0":       0,
+		"25":      2500,
+		"25.5":    2550,
+		"25.50":   2550,
+		"0.01":    1,
+		"-0.99":   -99,
+		" 29.99 ": 2999,
+	}
+	for input, cents := range valid {
+		m, err := model.ParseMoney(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, cents, m.Cents(), input)
+	}
+
+	for _, input := range []string{"", "abc", "1.005", "1e3", "1.", ".5", "--1", "1,00", "99999999999999999999"} {
+		_, err := model.ParseMoney(input)
+		assert.ErrorIs(t, err, model.ErrInvalidMoney, input)
+	}
+}
+
+// TestMoney_Arithmetic tests that sums and adjustments are exact in cents
+func TestMoney_Arithmetic(t *testing.T) {
+	t.Run("totals", func(t *testing.T) {
+		total := model.MustParseMoney("0.10") + model.MustParseMoney("0.20")
+		assert.Equal(t, model.MustParseMoney("0.30"), total)
+		assert.Equal(t, "0.30", total.String())
+
+		var sum model.Money
+		for i := 0; i < 1000; i++ {
+			sum += model.MustParseMoney("0.01")
+		}
+		assert.Equal(t, "10.00", sum.String())
+	})
+
+	t.Run("adjust by basis points", func(t *testing.T) {
+		tests := []struct {
+			price       string
+			basisPoints int64
+			want        string
+		}{
+			{"100.00", 1000, "110.00"},
+			{"25.50", 1000, "28.05"},
+			{"19.99", 5000, "29.99"},
+			{"0.01", 5000, "0.02"},
+			{"0.01", 4900, "0.01"},
+			{"10.00", -9000, "1.00"},
+			{"0.15", -5000, "0.08"},
+			{"-0.15", -5000, "-0.08"},
+		}
+		for _, tt := range tests {
+			got := model.MustParseMoney(tt.price).AdjustByBasisPoints(tt.basisPoints)
+			assert.Equal(t, tt.want, got.String(), "%s adjusted by %d", tt.price, tt.basisPoints)
+		}
+	})
+}
+
+// TestMoney_JSON tests that prices serialize as fixed two-decimal numbers and round-trip exactly
+func TestMoney_JSON(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		product := model.Product{Name: "Cable", Price: model.MustParseMoney("0.30")}
+		data, err := json.Marshal(product.ToResponse())
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"price":0.30`)
+
+		var decoded model.ProductResponse
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, product.Price, decoded.Price)
+	})
+
+	t.Run("accepts numbers and strings", func(t *testing.T) {
+		var req model.CreateProductRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"price": 19.9}`), &req))
+		assert.Equal(t, int64(1990), req.Price.Cents())
+
+		require.NoError(t, json.Unmarshal([]byte(`{"price": "19.99"}`), &req))
+		assert.Equal(t, int64(1999), req.Price.Cents())
+	})
+
+	t.Run("rejects sub-cent amounts", func(t *testing.T) {
+		var req model.CreateProductRequest
+		err := json.Unmarshal([]byte(`{"price": 19.999}`), &req)
+		assert.ErrorIs(t, err, model.ErrInvalidMoney)
+	})
+
+	t.Run("null leaves optional price unset", func(t *testing.T) {
+		var req model.UpdateProductRequest
+		require.NoError(t, json.Unmarshal([]byte(`{"price": null}`), &req))
+		assert.Nil(t, req.Price)
+	})
+}
+
+// TestMoney_Scan tests reading prices from the values database drivers return for decimal columns
+func TestMoney_Scan(t *testing.T) {
+	for _, src := range []interface{}{"28.05", []byte("28.0500"), 28.05} {
+		var m model.Money
+		require.NoError(t, m.Scan(src))
+		assert.Equal(t, int64(2805), m.Cents(), "%T", src)
+	}
+
+	var whole model.Money
+	require.NoError(t, whole.Scan(int64(110)))
+	assert.Equal(t, "110.00", whole.String())
+
+	value, err := model.MustParseMoney("28.05").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "28.05", value)
+}
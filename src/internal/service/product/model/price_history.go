@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// PriceChange records one change of a product's price, written in the same transaction as the update
+type PriceChange struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProductID uint      `gorm:"not null;index:idx_price_history_product_changed,priority:1" json:"product_id"`
+	OldPrice  Money     `gorm:"type:decimal(10,2);not null" json:"old_price"`
+	NewPrice  Money     `gorm:"type:decimal(10,2);not null" json:"new_price"`
+	ChangedBy uint      `json:"changed_by"` // ID of the user who made the change, 0 when not known
+	ChangedAt time.Time `gorm:"not null;index:idx_price_history_product_changed,priority:2" json:"changed_at"`
+}
+
+// TableName sets the table name for PriceChange
+func (PriceChange) TableName() string {
+	return "product_price_history"
+}
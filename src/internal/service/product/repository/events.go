@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
+	"myapp/internal/service/product/model"
+)
+
+// InsertProduct inserts a product and records a product.created event in the same transaction
+func (r *Repository) InsertProduct(ctx context.Context, product *model.Product) error {
+	return r.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := database.NewBaseRepository[model.Product](tx).Insert(ctx, product); err != nil {
+			return err
+		}
+		return outbox.Record(tx, model.AggregateProduct, product.ID, model.EventProductCreated, product.ToResponse())
+	})
+}
+
+// DeleteProduct soft-deletes a product and records a product.deleted event in the same transaction
+func (r *Repository) DeleteProduct(ctx context.Context, id uint) error {
+	defer r.cached.Invalidate(ctx, id)
+
+	return r.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := database.NewBaseRepository[model.Product](tx).DeleteByID(ctx, id); err != nil {
+			return err
+		}
+		return outbox.Record(tx, model.AggregateProduct, id, model.EventProductDeleted, &model.ProductDeletedEvent{ID: id})
+	})
+}
+
+// RestoreProduct restores a soft-deleted product and records a product.restored event in the same transaction.
+// A product that never existed yields gorm.ErrRecordNotFound.
+func (r *Repository) RestoreProduct(ctx context.Context, id uint) (*model.Product, error) {
+	defer r.cached.Invalidate(ctx, id)
+
+	var product model.Product
+	err := r.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := database.NewBaseRepository[model.Product](tx).RestoreByID(ctx, id); err != nil {
+			return err
+		}
+		if err := tx.First(&product, id).Error; err != nil {
+			return err
+		}
+		return outbox.Record(tx, model.AggregateProduct, id, model.EventProductRestored, product.ToResponse())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// recordUpdated reloads product id through tx and records a product.updated event with it, for writes that
+// change single columns rather than the whole product
+func recordUpdated(tx *gorm.DB, id uint) error {
+	var product model.Product
+	if err := tx.First(&product, id).Error; err != nil {
+		return err
+	}
+	return outbox.Record(tx, model.AggregateProduct, id, model.EventProductUpdated, product.ToResponse())
+}
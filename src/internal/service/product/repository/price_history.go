@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
+	"myapp/internal/service/product/model"
+)
+
+// UpdateProductWithPriceChange updates a product as UpdateByIDWithVersion does and, when change is not nil,
+// records the price change in the same transaction, so the history never disagrees with the product.
+// A product.updated event is recorded in the outbox with the update.
+func (r *Repository) UpdateProductWithPriceChange(ctx context.Context, id uint, expectedVersion uint, product *model.Product, change *model.PriceChange) error {
+	defer r.cached.Invalidate(ctx, id)
+
+	return r.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := database.NewBaseRepository[model.Product](tx).UpdateByIDWithVersion(ctx, id, expectedVersion, product); err != nil {
+			return err
+		}
+		if err := outbox.Record(tx, model.AggregateProduct, id, model.EventProductUpdated, product.ToResponse()); err != nil {
+			return err
+		}
+		if change == nil {
+			return nil
+		}
+		return tx.Create(change).Error
+	})
+}
+
+// ListPriceHistory retrieves the price changes of a product, newest first
+func (r *Repository) ListPriceHistory(ctx context.Context, productID uint, limit, offset int) ([]*model.PriceChange, error) {
+	db, err := r.GetDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []*model.PriceChange{}
+	query := db.WithContext(ctx).
+		Where("product_id = ?", productID).
+		Order("changed_at DESC").
+		Order("id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
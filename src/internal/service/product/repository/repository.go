@@ -2,40 +2,114 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"myapp/internal/pkg/cache"
 	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
 	"myapp/internal/service/product/model"
 )
 
 // Repository handles product data access
 type Repository struct {
 	*database.TenantRepo[model.Product]
-	db *gorm.DB
+	cached *cache.CachingRepository[model.Product]
 }
 
-// NewRepository creates a new product repository using tenant database
-func NewRepository(dbManager *database.DatabaseManager) *Repository {
+// NewRepository creates a new product repository using tenant database. GetByID reads are cached when
+// productCache is not nil; every method that changes a product invalidates its entry.
+func NewRepository(dbManager *database.DatabaseManager, productCache *cache.Cache) *Repository {
+	tenantRepo := database.NewTenantRepo[model.Product](dbManager.TenantConnManager)
 	return &Repository{
-		TenantRepo: database.NewTenantRepo[model.Product](dbManager.TenantConnManager),
-		db:         dbManager.TenantDB, // For custom queries
+		TenantRepo: tenantRepo,
+		cached:     cache.NewTenantCachingRepository[model.Product](tenantRepo, productCache, "product"),
 	}
 }
 
-// GetBySKU retrieves a product by SKU
-func (r *Repository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
-	var product model.Product
-	err := r.db.WithContext(ctx).Where("sku = ?", sku).First(&product).Error
+// GetByID retrieves a product by ID, from the cache when it holds one
+func (r *Repository) GetByID(ctx context.Context, id uint) (*model.Product, error) {
+	return r.cached.GetByID(ctx, id)
+}
+
+// UpdateByID updates a product by ID and invalidates its cache entry
+func (r *Repository) UpdateByID(ctx context.Context, id uint, product *model.Product) error {
+	return r.cached.UpdateByID(ctx, id, product)
+}
+
+// DeleteByID soft-deletes a product by ID and invalidates its cache entry
+func (r *Repository) DeleteByID(ctx context.Context, id uint) error {
+	return r.cached.DeleteByID(ctx, id)
+}
+
+// excludeArchived is a scope that hides archived products unless includeArchived is set
+func excludeArchived(includeArchived bool) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if includeArchived {
+			return db
+		}
+		return db.Where("archived_at IS NULL")
+	}
+}
+
+// modifiedColumns adds a version bump, updated_at and, when ctx carries the acting user, updated_by to the
+// columns of a direct update, so UpdateByIDWithVersion sees the change and the audit columns record it
+func modifiedColumns(ctx context.Context, updates map[string]interface{}) map[string]interface{} {
+	updates["version"] = gorm.Expr("version + 1")
+	updates["updated_at"] = time.Now()
+	if userID, ok := database.GetUserID(ctx); ok {
+		updates["updated_by"] = userID
+	}
+	return updates
+}
+
+// newestFirst is the default order for listings that had one before sorting was configurable
+var newestFirst = database.SortField{Column: "created_at", Desc: true}
+
+// ListProducts retrieves products with pagination, skipping archived ones unless includeArchived is set.
+// Listings load only columns (plus id) when it is not empty, here and in the other list methods.
+func (r *Repository) ListProducts(ctx context.Context, includeArchived bool, sort []database.SortField, columns []string, limit, offset int) ([]*model.Product, error) {
+	db, err := r.GetDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var products []*model.Product
+	query := db.WithContext(ctx).Scopes(excludeArchived(includeArchived), database.OrderBy(sort), database.SelectColumns(columns))
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	err = query.Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
-	return &product, nil
+	return products, nil
+}
+
+// ListProductsAfterCursor retrieves up to limit products after afterID in id order (database.CursorOrderAsc or
+// database.CursorOrderDesc) and the afterID of the next page, or 0 on the last page
+func (r *Repository) ListProductsAfterCursor(ctx context.Context, includeArchived bool, afterID uint, limit int, orderBy string, columns []string) ([]*model.Product, uint, error) {
+	return r.GetAfterCursor(ctx, afterID, limit, orderBy, excludeArchived(includeArchived), database.SelectColumns(columns))
+}
+
+// GetBySKU retrieves a product by SKU from the tenant database in ctx
+func (r *Repository) GetBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	return r.GetByField(ctx, "sku", sku)
 }
 
 // GetByCategory retrieves products by category
-func (r *Repository) GetByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Product, error) {
+func (r *Repository) GetByCategory(ctx context.Context, category string, includeArchived bool, sort []database.SortField, columns []string, limit, offset int) ([]*model.Product, error) {
+	db, err := r.GetDB(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var products []*model.Product
-	query := r.db.WithContext(ctx).Where("category = ?", category)
+	query := db.WithContext(ctx).Where("category = ?", category).Scopes(excludeArchived(includeArchived), database.OrderBy(sort), database.SelectColumns(columns))
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -44,7 +118,7 @@ func (r *Repository) GetByCategory(ctx context.Context, category string, limit,
 		query = query.Offset(offset)
 	}
 	
-	err := query.Find(&products).Error
+	err = query.Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
@@ -52,9 +126,13 @@ func (r *Repository) GetByCategory(ctx context.Context, category string, limit,
 }
 
 // GetActiveProducts retrieves all active products
-func (r *Repository) GetActiveProducts(ctx context.Context, limit, offset int) ([]*model.Product, error) {
+func (r *Repository) GetActiveProducts(ctx context.Context, includeArchived bool, sort []database.SortField, columns []string, limit, offset int) ([]*model.Product, error) {
+	db, err := r.GetDB(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var products []*model.Product
-	query := r.db.WithContext(ctx).Where("is_active = ?", true)
+	query := db.WithContext(ctx).Where("is_active = ?", true).Scopes(excludeArchived(includeArchived), database.OrderBy(sort, newestFirst), database.SelectColumns(columns))
 	
 	if limit > 0 {
 		query = query.Limit(limit)
@@ -63,31 +141,104 @@ func (r *Repository) GetActiveProducts(ctx context.Context, limit, offset int) (
 		query = query.Offset(offset)
 	}
 	
-	err := query.Order("created_at DESC").Find(&products).Error
+	err = query.Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
-// SKUExists checks if a SKU already exists
+// SKUExists checks if a SKU already exists for the tenant in ctx.
+// SKUs are unique per tenant: the check and the unique index on sku both live in the tenant's own database.
+// Soft-deleted products keep their SKU, as the unique index still covers them and they can be restored.
 func (r *Repository) SKUExists(ctx context.Context, sku string) (bool, error) {
-	var count int64
-	err := r.db.WithContext(ctx).Model(&model.Product{}).Where("sku = ?", sku).Count(&count).Error
+	db, err := r.GetDB(ctx)
 	if err != nil {
 		return false, err
 	}
+	var count int64
+	if err := db.WithContext(ctx).Unscoped().Model(&model.Product{}).Where("sku = ?", sku).Count(&count).Error; err != nil {
+		return false, err
+	}
 	return count > 0, nil
 }
 
+// ExistingSKUs returns which of skus are already used by a product of the tenant in ctx, soft-deleted ones included
+func (r *Repository) ExistingSKUs(ctx context.Context, skus []string) ([]string, error) {
+	existing := []string{}
+	if len(skus) == 0 {
+		return existing, nil
+	}
+	db, err := r.GetDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.WithContext(ctx).Unscoped().Model(&model.Product{}).Where("sku IN ?", skus).Pluck("sku", &existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// CreateProducts inserts products into the tenant database in ctx in one transaction; either all are created or none.
+// A product.created event is recorded for each product in the same transaction.
+func (r *Repository) CreateProducts(ctx context.Context, products []*model.Product) error {
+	return r.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := database.NewBaseRepository[model.Product](tx).InsertBatch(ctx, products); err != nil {
+			return err
+		}
+		for _, product := range products {
+			if err := outbox.Record(tx, model.AggregateProduct, product.ID, model.EventProductCreated, product.ToResponse()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteProducts soft-deletes the products with the given IDs in one transaction and returns how many were
+// deleted; IDs that do not exist or are already deleted are not counted. A product.deleted event is recorded
+// for each deleted product in the same transaction.
+func (r *Repository) DeleteProducts(ctx context.Context, ids []uint) (int64, error) {
+	defer r.cached.Invalidate(ctx, ids...)
+
+	var deleted int64
+	err := r.Transaction(ctx, func(tx *gorm.DB) error {
+		var existing []uint
+		if err := tx.Model(&model.Product{}).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+		if len(existing) == 0 {
+			return nil
+		}
+
+		var err error
+		deleted, err = database.NewBaseRepository[model.Product](tx).DeleteWhereCount(ctx, map[string]interface{}{"id IN ?": existing})
+		if err != nil {
+			return err
+		}
+		for _, id := range existing {
+			if err := outbox.Record(tx, model.AggregateProduct, id, model.EventProductDeleted, &model.ProductDeletedEvent{ID: id}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
 // SearchProducts searches products by name or description
-func (r *Repository) SearchProducts(ctx context.Context, query string, limit, offset int) ([]*model.Product, error) {
+func (r *Repository) SearchProducts(ctx context.Context, query string, includeArchived bool, sort []database.SortField, columns []string, limit, offset int) ([]*model.Product, error) {
+	db, err := r.GetDB(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var products []*model.Product
 	searchQuery := "%" + query + "%"
 	
-	dbQuery := r.db.WithContext(ctx).
+	dbQuery := db.WithContext(ctx).
 		Where("name LIKE ? OR description LIKE ?", searchQuery, searchQuery).
-		Where("is_active = ?", true)
+		Where("is_active = ?", true).
+		Scopes(excludeArchived(includeArchived), database.OrderBy(sort, newestFirst), database.SelectColumns(columns))
 	
 	if limit > 0 {
 		dbQuery = dbQuery.Limit(limit)
@@ -96,18 +247,96 @@ func (r *Repository) SearchProducts(ctx context.Context, query string, limit, of
 		dbQuery = dbQuery.Offset(offset)
 	}
 	
-	err := dbQuery.Order("created_at DESC").Find(&products).Error
+	err = dbQuery.Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
 	return products, nil
 }
 
-// UpdateStock updates product stock
-func (r *Repository) UpdateStock(ctx context.Context, id uint, quantity int) error {
-	return r.db.WithContext(ctx).
-		Model(&model.Product{}).
-		Where("id = ?", id).
-		UpdateColumn("stock", gorm.Expr("stock + ?", quantity)).
-		Error
+// CountByGroup counts products per distinct value of field, skipping archived ones unless includeArchived is set
+func (r *Repository) CountByGroup(ctx context.Context, field string, includeArchived bool) (map[string]int64, error) {
+	return r.GroupByCount(ctx, field, excludeArchived(includeArchived))
+}
+
+// ChangeStockLocked reads the stock of product id under a row lock (SELECT ... FOR UPDATE), lets change compute
+// the new stock and writes it back in the same transaction, so concurrent changes cannot interleave. An error
+// from change rolls back without writing; a missing product returns gorm.ErrRecordNotFound.
+// The version is bumped, so an update that read the old stock fails with ErrStaleObject instead of writing it back,
+// and a product.updated event is recorded in the same transaction.
+// SQLite has no row locks and drops the clause; its single writer serializes the updates instead.
+func (r *Repository) ChangeStockLocked(ctx context.Context, id uint, change func(stock int) (int, error)) (int, error) {
+	defer r.cached.Invalidate(ctx, id)
+
+	var stock int
+	err := r.Transaction(ctx, func(tx *gorm.DB) error {
+		var product model.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "stock").First(&product, id).Error; err != nil {
+			return err
+		}
+
+		var err error
+		stock, err = change(product.Stock)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model.Product{}).Where("id = ?", id).
+			UpdateColumns(modifiedColumns(ctx, map[string]interface{}{"stock": stock})).Error; err != nil {
+			return err
+		}
+		return recordUpdated(tx, id)
+	})
+	return stock, err
+}
+
+// SetArchivedAt sets or clears (nil) the archive timestamp of a product in the tenant database in ctx and
+// records a product.updated event in the same transaction
+func (r *Repository) SetArchivedAt(ctx context.Context, id uint, archivedAt *time.Time) error {
+	defer r.cached.Invalidate(ctx, id)
+
+	return r.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Product{}).Where("id = ?", id).
+			UpdateColumns(modifiedColumns(ctx, map[string]interface{}{"archived_at": archivedAt})).Error; err != nil {
+			return err
+		}
+		return recordUpdated(tx, id)
+	})
+}
+
+// AdjustPrices changes the price of every product in a category by basisPoints (1000 = +10%) and returns the affected count.
+// The rows are locked (SELECT ... FOR UPDATE) and new prices are computed in integer cents rather than in SQL, so rounding
+// does not depend on the database's number types. Prices never drop below model.MinPrice. Each row's version is bumped,
+// so an update that read the old price conflicts instead of overwriting the adjustment, and a product.updated event is
+// recorded for each product in the same transaction.
+func (r *Repository) AdjustPrices(ctx context.Context, category string, basisPoints int64) (int64, error) {
+	var affected int64
+	var ids []uint
+	defer func() { r.cached.Invalidate(ctx, ids...) }()
+
+	err := r.Transaction(ctx, func(tx *gorm.DB) error {
+		var products []*model.Product
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "price").Where("category = ?", category).Find(&products).Error; err != nil {
+			return err
+		}
+		for _, product := range products {
+			ids = append(ids, product.ID)
+			price := product.Price.AdjustByBasisPoints(basisPoints)
+			if price < model.MinPrice {
+				price = model.MinPrice
+			}
+			if err := tx.Model(&model.Product{}).Where("id = ?", product.ID).
+				UpdateColumns(modifiedColumns(ctx, map[string]interface{}{"price": price})).Error; err != nil {
+				return err
+			}
+			if err := recordUpdated(tx, product.ID); err != nil {
+				return err
+			}
+		}
+		affected = int64(len(products))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return affected, nil
 }
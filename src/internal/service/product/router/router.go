@@ -1,10 +1,11 @@
 package router
 
 import (
+	"myapp/internal/pkg/auth"
+	custommw "myapp/internal/pkg/middleware"
 	"myapp/internal/service/product/handler"
 
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
 	"go.uber.org/zap"
 )
 
@@ -12,12 +13,21 @@ import (
 func RegisterProductRoutes(
 	e *echo.Echo,
 	productHandler *handler.Handler,
+	authService *auth.Service,
+	auditRepo *custommw.AuditRepository,
+	rateLimitStore custommw.RateLimitStore,
+	idempotencyStore custommw.IdempotencyStore,
 	logger *zap.Logger,
 ) {
 	logger.Info("Registering product routes")
 
 	// API routes
 	api := e.Group("/api")
+	authenticate := auth.JWTMiddleware(authService, logger)
+	adminOnly := auth.RequireRole("admin")
+	audit := custommw.AuditMiddleware(logger, auditRepo)
+	rateLimit := custommw.RateLimitMiddleware(rateLimitStore)
+	idempotent := custommw.IdempotencyMiddleware(idempotencyStore, logger)
 
 	// ==========================================
 	// EXAMPLE 1: Route-Level Middleware (per route)
@@ -25,11 +35,11 @@ func RegisterProductRoutes(
 	// Middleware applied to individual routes
 	/*
 	productGroup := api.Group("/products")
-	productGroup.GET("", productHandler.GetProducts, rateLimitMiddleware())
-	productGroup.GET("/:id", productHandler.GetProduct, rateLimitMiddleware())
-	productGroup.POST("", productHandler.CreateProduct, authMiddleware(), validateRequestMiddleware())
-	productGroup.PUT("/:id", productHandler.UpdateProduct, authMiddleware(), validateRequestMiddleware())
-	productGroup.DELETE("/:id", productHandler.DeleteProduct, authMiddleware(), adminOnlyMiddleware(), auditLogMiddleware())
+	productGroup.GET("", productHandler.GetProducts, rateLimit)
+	productGroup.GET("/:id", productHandler.GetProduct, rateLimit)
+	productGroup.POST("", productHandler.CreateProduct, authenticate, validateRequestMiddleware())
+	productGroup.PUT("/:id", productHandler.UpdateProduct, authenticate, validateRequestMiddleware())
+	productGroup.DELETE("/:id", productHandler.DeleteProduct, authenticate, adminOnly, audit)
 	*/
 
 	// ==========================================
@@ -37,38 +47,47 @@ func RegisterProductRoutes(
 	// ==========================================
 	// Middleware applied to entire group - cleaner and more maintainable
 	
-	// Public group - Rate limited but no authentication
-	publicProducts := api.Group("/products", rateLimitMiddleware())
+	// Public group - Rate limited per client IP but no authentication
+	publicProducts := api.Group("/products", rateLimit)
 	publicProducts.GET("", productHandler.GetProducts)
+	publicProducts.GET("/stats", productHandler.GetProductStats)
+	publicProducts.POST("/batch-get", productHandler.BatchGetProducts)
 	publicProducts.GET("/:id", productHandler.GetProduct)
 
-	// Protected group - Requires authentication + validation
-	protectedProducts := api.Group("/products", authMiddleware(), validateRequestMiddleware())
-	protectedProducts.POST("", productHandler.CreateProduct)
+	// Protected group - Requires authentication + validation, rate limited per user
+	protectedProducts := api.Group("/products", authenticate, rateLimit, validateRequestMiddleware())
+	protectedProducts.POST("", productHandler.CreateProduct, idempotent)
+	protectedProducts.POST("/batch", productHandler.CreateProducts, idempotent)
 	protectedProducts.PUT("/:id", productHandler.UpdateProduct)
+	protectedProducts.GET("/:id/price-history", productHandler.GetPriceHistory)
 
-	// Admin group - Requires authentication + admin role + audit logging
-	adminProducts := api.Group("/products", authMiddleware(), adminOnlyMiddleware(), auditLogMiddleware())
+	// Admin group - Requires authentication + admin role + audit logging, rate limited per user
+	adminProducts := api.Group("/products", authenticate, rateLimit, adminOnly, audit)
 	adminProducts.DELETE("/:id", productHandler.DeleteProduct)
+	adminProducts.POST("/bulk-delete", productHandler.DeleteProducts)
+	adminProducts.POST("/adjust-prices", productHandler.AdjustPrices)
+	adminProducts.POST("/:id/restore", productHandler.RestoreProduct)
+	adminProducts.POST("/:id/archive", productHandler.ArchiveProduct)
+	adminProducts.POST("/:id/unarchive", productHandler.UnarchiveProduct)
 
 	// ==========================================
 	// EXAMPLE 3: Nested Groups with Inherited Middleware
 	// ==========================================
 	/*
 	// Base group with common middleware (all routes inherit this)
-	products := api.Group("/products", rateLimitMiddleware())
+	products := api.Group("/products", rateLimit)
 
 	// Public routes - inherit rate limiting only
 	products.GET("", productHandler.GetProducts)
 	products.GET("/:id", productHandler.GetProduct)
 
 	// Protected subgroup - inherits rate limiting + adds authentication
-	protected := products.Group("", authMiddleware(), validateRequestMiddleware())
+	protected := products.Group("", authenticate, validateRequestMiddleware())
 	protected.POST("", productHandler.CreateProduct)
 	protected.PUT("/:id", productHandler.UpdateProduct)
 
 	// Admin subgroup - inherits all above + adds admin check + audit log
-	admin := protected.Group("", adminOnlyMiddleware(), auditLogMiddleware())
+	admin := protected.Group("", adminOnly, audit)
 	admin.DELETE("/:id", productHandler.DeleteProduct)
 	*/
 
@@ -79,7 +98,7 @@ func RegisterProductRoutes(
 	products := api.Group("/products")
 	
 	// Add middleware dynamically to the group
-	products.Use(rateLimitMiddleware())
+	products.Use(rateLimit)
 	
 	// Public routes
 	products.GET("", productHandler.GetProducts)
@@ -87,15 +106,15 @@ func RegisterProductRoutes(
 	
 	// Create protected subgroup
 	protected := products.Group("")
-	protected.Use(authMiddleware())
+	protected.Use(authenticate)
 	protected.Use(validateRequestMiddleware())
 	protected.POST("", productHandler.CreateProduct)
 	protected.PUT("/:id", productHandler.UpdateProduct)
 	
 	// Create admin subgroup
 	admin := protected.Group("")
-	admin.Use(adminOnlyMiddleware())
-	admin.Use(auditLogMiddleware())
+	admin.Use(adminOnly)
+	admin.Use(audit)
 	admin.DELETE("/:id", productHandler.DeleteProduct)
 	*/
 
@@ -104,18 +123,18 @@ func RegisterProductRoutes(
 	// ==========================================
 	/*
 	// Group with basic middleware
-	products := api.Group("/products", rateLimitMiddleware())
+	products := api.Group("/products", rateLimit)
 	
 	// Public routes - no additional middleware
 	products.GET("", productHandler.GetProducts)
 	products.GET("/:id", productHandler.GetProduct)
 	
 	// Protected routes - add auth at route level
-	products.POST("", productHandler.CreateProduct, authMiddleware(), validateRequestMiddleware())
-	products.PUT("/:id", productHandler.UpdateProduct, authMiddleware(), validateRequestMiddleware())
+	products.POST("", productHandler.CreateProduct, authenticate, validateRequestMiddleware())
+	products.PUT("/:id", productHandler.UpdateProduct, authenticate, validateRequestMiddleware())
 	
 	// Admin route - add both auth and admin at route level
-	products.DELETE("/:id", productHandler.DeleteProduct, authMiddleware(), adminOnlyMiddleware(), auditLogMiddleware())
+	products.DELETE("/:id", productHandler.DeleteProduct, authenticate, adminOnly, audit)
 	*/
 
 	logger.Info("Product routes registered successfully")
@@ -124,50 +143,6 @@ func RegisterProductRoutes(
 // Example middleware implementations
 // Move these to a separate middleware package in production
 
-// rateLimitMiddleware limits the number of requests per IP
-func rateLimitMiddleware() echo.MiddlewareFunc {
-	return middleware.RateLimiter(middleware.NewRateLimiterMemoryStore(20)) // 20 requests per second
-}
-
-// authMiddleware validates JWT token and sets user context
-func authMiddleware() echo.MiddlewareFunc {
-	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(c echo.Context) error {
-			// Example: Check Authorization header
-			// token := c.Request().Header.Get("Authorization")
-			// if token == "" {
-			//     return echo.NewHTTPError(http.StatusUnauthorized, "missing authorization token")
-			// }
-			//
-			// // Validate token and extract user info
-			// user, err := validateToken(token)
-			// if err != nil {
-			//     return echo.NewHTTPError(http.StatusUnauthorized, "invalid token")
-			// }
-			//
-			// // Set user in context
-			// c.Set("user", user)
-
-			return next(c)
-		}
-	}
-}
-
-// adminOnlyMiddleware checks if user has admin role
-func adminOnlyMiddleware() echo.MiddlewareFunc {
-	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(c echo.Context) error {
-			// Example: Check user role from context
-			// user, ok := c.Get("user").(*User)
-			// if !ok || user.Role != "admin" {
-			//     return echo.NewHTTPError(http.StatusForbidden, "admin access required")
-			// }
-
-			return next(c)
-		}
-	}
-}
-
 // validateRequestMiddleware validates request body before processing
 func validateRequestMiddleware() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -182,20 +157,3 @@ func validateRequestMiddleware() echo.MiddlewareFunc {
 		}
 	}
 }
-
-// auditLogMiddleware logs all admin actions for compliance
-func auditLogMiddleware() echo.MiddlewareFunc {
-	return func(next echo.HandlerFunc) echo.HandlerFunc {
-		return func(c echo.Context) error {
-			// Example: Log admin action
-			// user, _ := c.Get("user").(*User)
-			// logger.Info("admin action",
-			//     zap.String("user_id", user.ID),
-			//     zap.String("action", c.Request().Method),
-			//     zap.String("path", c.Request().URL.Path),
-			// )
-
-			return next(c)
-		}
-	}
-}
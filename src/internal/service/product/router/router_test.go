@@ -0,0 +1,159 @@
+// +build cgo
+
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	custommw "myapp/internal/pkg/middleware"
+	"myapp/internal/pkg/outbox"
+	"myapp/internal/pkg/server"
+	"myapp/internal/service/product/handler"
+	"myapp/internal/service/product/model"
+	"myapp/internal/service/product/repository"
+	"myapp/internal/service/product/router"
+	"myapp/internal/service/product/service"
+	"myapp/internal/testsupport"
+)
+
+// routerFixture is an Echo server with the product routes registered against a SQLite tenant
+type routerFixture struct {
+	e          *echo.Echo
+	tenantDB   *gorm.DB
+	adminToken string
+	userToken  string
+}
+
+// setupRouter registers the product routes with real JWT authentication and issues an admin and a user token
+func setupRouter(t *testing.T) *routerFixture {
+	return setupRouterWithConfig(t, &config.Config{})
+}
+
+// setupRouterWithConfig is setupRouter with the given config, e.g. a small rate limit
+func setupRouterWithConfig(t *testing.T, cfg *config.Config) *routerFixture {
+	tenant := testsupport.NewTestTenant(t, &model.Product{}, &model.PriceChange{}, &outbox.Event{})
+	require.NoError(t, tenant.MasterDB.AutoMigrate(append(testsupport.AuthModels(), &custommw.AuditLog{}, &custommw.IdempotencyRecord{})...))
+
+	require.NoError(t, cfg.RateLimit.Validate())
+	require.NoError(t, cfg.Idempotency.Validate())
+	svc := service.NewService(repository.NewRepository(tenant.DBManager, nil), cfg)
+	authService := testsupport.NewTestAuthService(t, tenant.MasterDB)
+
+	e := echo.New()
+	e.Use(custommw.ContextMiddleware(tenant.DBManager))
+	router.RegisterProductRoutes(e, handler.NewHandler(svc, cfg), authService, custommw.NewAuditRepository(tenant.DBManager), custommw.NewMemoryRateLimitStore(cfg), custommw.NewIdempotencyRepository(tenant.DBManager, cfg), zap.NewNop())
+
+	return &routerFixture{
+		e:          e,
+		tenantDB:   tenant.DB,
+		adminToken: testsupport.NewTestAccessToken(t, authService, tenant.MasterDB, "admin@example.com", "admin"),
+		userToken:  testsupport.NewTestAccessToken(t, authService, tenant.MasterDB, "user@example.com", "user"),
+	}
+}
+
+// do sends a request for the test tenant, authenticated with token when it is not empty
+func (f *routerFixture) do(method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("X-Tenant-ID", testsupport.DefaultTenantID)
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	f.e.ServeHTTP(rec, req)
+	return rec
+}
+
+// TestRegisterProductRoutes_Authentication tests that protected and admin routes require a valid token and role
+func TestRegisterProductRoutes_Authentication(t *testing.T) {
+	f := setupRouter(t)
+	product := testsupport.NewProduct().Create(t, f.tenantDB)
+	archivePath := "/api/products/" + strconv.Itoa(int(product.ID)) + "/archive"
+
+	t.Run("protected POST without token is unauthorized", func(t *testing.T) {
+		rec := f.do(http.MethodPost, "/api/products", "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("admin POST without token is unauthorized", func(t *testing.T) {
+		rec := f.do(http.MethodPost, archivePath, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("admin POST with invalid token is unauthorized", func(t *testing.T) {
+		rec := f.do(http.MethodPost, archivePath, "not-a-token")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("admin POST with user token is forbidden", func(t *testing.T) {
+		rec := f.do(http.MethodPost, archivePath, f.userToken)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("admin POST with admin token succeeds", func(t *testing.T) {
+		rec := f.do(http.MethodPost, archivePath, f.adminToken)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("public GET needs no token", func(t *testing.T) {
+		rec := f.do(http.MethodGet, "/api/products/"+strconv.Itoa(int(product.ID)), "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+// TestRegisterProductRoutes_RateLimit tests that authenticated and admin routes are rate limited per user
+func TestRegisterProductRoutes_RateLimit(t *testing.T) {
+	f := setupRouterWithConfig(t, &config.Config{RateLimit: config.RateLimitConfig{RequestsPerSecond: 0.001, Burst: 2}})
+	product := testsupport.NewProduct().Create(t, f.tenantDB)
+	historyPath := "/api/products/" + strconv.Itoa(int(product.ID)) + "/price-history"
+
+	assert.Equal(t, http.StatusOK, f.do(http.MethodGet, historyPath, f.userToken).Code)
+	assert.Equal(t, http.StatusOK, f.do(http.MethodGet, historyPath, f.userToken).Code)
+	assert.Equal(t, http.StatusTooManyRequests, f.do(http.MethodGet, historyPath, f.userToken).Code)
+
+	// The admin has a bucket of its own, shared between protected and admin routes
+	assert.Equal(t, http.StatusOK, f.do(http.MethodGet, historyPath, f.adminToken).Code)
+	assert.Equal(t, http.StatusOK, f.do(http.MethodPost, "/api/products/"+strconv.Itoa(int(product.ID))+"/archive", f.adminToken).Code)
+	assert.Equal(t, http.StatusTooManyRequests, f.do(http.MethodPost, "/api/products/"+strconv.Itoa(int(product.ID))+"/unarchive", f.adminToken).Code)
+}
+
+// TestRegisterProductRoutes_BodyLimit tests that an oversized product body is refused with 413 before it is bound
+func TestRegisterProductRoutes_BodyLimit(t *testing.T) {
+	f := setupRouter(t)
+	f.e.Validator = server.NewValidator()
+	f.e.Use(server.BodyLimitMiddleware(1024))
+
+	create := func(description string) *httptest.ResponseRecorder {
+		payload := `{"name":"Keyboard","price":49.99,"stock":5,"sku":"KB-` + strconv.Itoa(len(description)) + `","description":"` + description + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/products", strings.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("X-Tenant-ID", testsupport.DefaultTenantID)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+f.adminToken)
+		rec := httptest.NewRecorder()
+		f.e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("body under the limit creates the product", func(t *testing.T) {
+		rec := create("compact")
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	})
+
+	t.Run("body over the limit gets 413", func(t *testing.T) {
+		rec := create(strings.Repeat("x", 4096))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+		var count int64
+		require.NoError(t, f.tenantDB.Model(&model.Product{}).Where("sku = ?", "KB-4096").Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
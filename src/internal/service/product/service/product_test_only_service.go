@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/normalize"
 	"myapp/internal/service/product/dto"
 	"myapp/internal/service/product/repository"
 )
@@ -17,15 +19,20 @@ var (
 // ProductTestOnlyService handles product test only business logic
 type ProductTestOnlyService struct {
 	repo *repository.ProductTestOnlyRepository
+	norm normalize.Policy
 }
 
 // NewProductTestOnlyService creates a new product test only service
-func NewProductTestOnlyService(repo *repository.ProductTestOnlyRepository) *ProductTestOnlyService {
-	return &ProductTestOnlyService{repo: repo}
+func NewProductTestOnlyService(repo *repository.ProductTestOnlyRepository, cfg *config.Config) *ProductTestOnlyService {
+	return &ProductTestOnlyService{repo: repo, norm: normalize.NewPolicy(cfg.Normalization)}
 }
 
 // CreateProductTestOnly creates a new product test only
 func (s *ProductTestOnlyService) CreateProductTestOnly(ctx context.Context, req *dto.CreateProductTestOnlyRequest) (*dto.ProductTestOnlyResponse, error) {
+	req.Code = s.norm.Code(req.Code)
+	req.Name = s.norm.Name(req.Name)
+	req.Type = s.norm.Text(req.Type)
+
 	// Check if code already exists
 	exists, err := s.repo.CodeExists(ctx, req.Code)
 	if err != nil {
@@ -62,7 +69,7 @@ func (s *ProductTestOnlyService) GetProductTestOnlyByID(ctx context.Context, id
 
 // GetProductTestOnlyByCode retrieves product test only by code
 func (s *ProductTestOnlyService) GetProductTestOnlyByCode(ctx context.Context, code string) (*dto.ProductTestOnlyResponse, error) {
-	entity, err := s.repo.GetByCode(ctx, code)
+	entity, err := s.repo.GetByCode(ctx, s.norm.Code(code))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrProductTestOnlyNotFound
@@ -142,6 +149,10 @@ func (s *ProductTestOnlyService) UpdateProductTestOnly(ctx context.Context, id u
 	}
 
 	// If code is being updated, check if new code already exists
+	if req.Code != nil {
+		code := s.norm.Code(*req.Code)
+		req.Code = &code
+	}
 	if req.Code != nil && *req.Code != entity.Code {
 		exists, err := s.repo.CodeExists(ctx, *req.Code)
 		if err != nil {
@@ -155,10 +166,10 @@ func (s *ProductTestOnlyService) UpdateProductTestOnly(ctx context.Context, id u
 
 	// Update fields if provided
 	if req.Name != nil {
-		entity.Name = *req.Name
+		entity.Name = s.norm.Name(*req.Name)
 	}
 	if req.Type != nil {
-		entity.Type = *req.Type
+		entity.Type = s.norm.Text(*req.Type)
 	}
 
 	// Save updates
@@ -4,8 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"time"
 
 	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/normalize"
+	"myapp/internal/pkg/validation"
 	"myapp/internal/service/product/model"
 	"myapp/internal/service/product/repository"
 )
@@ -13,26 +20,94 @@ import (
 var (
 	// ErrProductNotFound is returned when product is not found
 	ErrProductNotFound = errors.New("product not found")
-	// ErrSKUExists is returned when SKU already exists
+	// ErrSKUExists is returned when SKU already exists, also when it belongs to a deleted product that can be restored
 	ErrSKUExists = errors.New("product with this SKU already exists")
+	// ErrProductConflict is returned when a product changed since the version an update was based on
+	ErrProductConflict = errors.New("product was modified by another request")
 	// ErrInsufficientStock is returned when stock is insufficient
 	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrInvalidQuantity is returned when a stock reservation or release is not for a positive quantity
+	ErrInvalidQuantity = errors.New("quantity must be positive")
+	// ErrInvalidPriceAdjustment is returned when a bulk price adjustment is out of bounds
+	ErrInvalidPriceAdjustment = errors.New("invalid price adjustment")
+	// ErrInvalidSort is returned when a sort expression names a field that cannot be sorted on
+	ErrInvalidSort = database.ErrInvalidSort
+	// ErrInvalidFields is returned when a field selection names a field that cannot be selected
+	ErrInvalidFields = database.ErrInvalidFields
+	// ErrInvalidGroupBy is returned when stats are requested for a field that cannot be grouped on
+	ErrInvalidGroupBy = database.ErrInvalidGroupBy
+	// ErrInvalidCursor is returned when a pagination cursor or order cannot be used
+	ErrInvalidCursor = database.ErrInvalidCursor
+	// ErrInvalidBatchGet is returned when a batch get names no IDs or too many
+	ErrInvalidBatchGet = errors.New("invalid batch get request")
+	// ErrInvalidBatchCreate is returned when a batch create is empty, too large or has invalid items
+	ErrInvalidBatchCreate = errors.New("invalid batch create request")
+	// ErrInvalidBulkDelete is returned when a bulk delete names no IDs or too many
+	ErrInvalidBulkDelete = errors.New("invalid bulk delete request")
+)
+
+// BatchValidationError lists the items of a batch create that failed validation. Nothing is created
+// when it is returned. It wraps ErrInvalidBatchCreate.
+type BatchValidationError struct {
+	Items []model.BatchItemError
+}
+
+// Error implements the error interface
+func (e *BatchValidationError) Error() string {
+	return fmt.Sprintf("%s: %d invalid products", ErrInvalidBatchCreate, len(e.Items))
+}
+
+// Unwrap returns ErrInvalidBatchCreate
+func (e *BatchValidationError) Unwrap() error {
+	return ErrInvalidBatchCreate
+}
+
+// ValidationError lists the invalid items as request fields, e.g. products[2].sku, so they are reported in the
+// same shape as any other validation failure
+func (e *BatchValidationError) ValidationError() *validation.ValidationError {
+	fields := make([]validation.FieldError, len(e.Items))
+	for i, item := range e.Items {
+		field := fmt.Sprintf("products[%d]", item.Index)
+		if item.Field != "" {
+			field += "." + item.Field
+		}
+		fields[i] = validation.FieldError{Field: field, Tag: item.Tag, Message: item.Error}
+	}
+	return &validation.ValidationError{Fields: fields}
+}
+
+const (
+	// MinPriceAdjustmentPercent is the largest allowed bulk price decrease
+	MinPriceAdjustmentPercent = -90.0
+	// MaxPriceAdjustmentPercent is the largest allowed bulk price increase
+	MaxPriceAdjustmentPercent = 100.0
+	// MaxBatchGetIDs is the most products that can be fetched in one batch get
+	MaxBatchGetIDs = 100
 )
 
 // Service handles product business logic
 type Service struct {
-	repo *repository.Repository
+	repo         *repository.Repository
+	norm         normalize.Policy
+	maxBatchSize int
 }
 
 // NewService creates a new product service
-func NewService(repo *repository.Repository) *Service {
+func NewService(repo *repository.Repository, cfg *config.Config) *Service {
 	return &Service{
-		repo: repo,
+		repo:         repo,
+		norm:         normalize.NewPolicy(cfg.Normalization),
+		maxBatchSize: cfg.Bulk.MaxBatchSize,
 	}
 }
 
 // CreateProduct creates a new product
 func (s *Service) CreateProduct(ctx context.Context, req *model.CreateProductRequest) (*model.Product, error) {
+	req.Name = s.norm.Name(req.Name)
+	req.Description = s.norm.Text(req.Description)
+	req.SKU = s.norm.Code(req.SKU)
+	req.Category = s.norm.Text(req.Category)
+
 	// Check if SKU already exists
 	exists, err := s.repo.SKUExists(ctx, req.SKU)
 	if err != nil {
@@ -52,13 +127,95 @@ func (s *Service) CreateProduct(ctx context.Context, req *model.CreateProductReq
 		IsActive:    true,
 	}
 
-	if err := s.repo.Insert(ctx, product); err != nil {
+	if err := s.repo.InsertProduct(ctx, product); err != nil {
+		// Another request created the SKU after the check
+		if database.IsDuplicateKeyError(err) {
+			return nil, ErrSKUExists
+		}
 		return nil, fmt.Errorf("create product: %w", err)
 	}
 
 	return product, nil
 }
 
+// CreateProducts creates a batch of products in one transaction, all or nothing. Every item is checked
+// before anything is written: fields failing their validate tags, a SKU repeated in the batch or one already
+// in use are all reported together in a *BatchValidationError.
+func (s *Service) CreateProducts(ctx context.Context, reqs []*model.CreateProductRequest) ([]*model.Product, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%w: products are required", ErrInvalidBatchCreate)
+	}
+	if len(reqs) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d products are allowed", ErrInvalidBatchCreate, s.maxBatchSize)
+	}
+
+	var invalid []model.BatchItemError
+	products := make([]*model.Product, 0, len(reqs))
+	firstIndex := make(map[string]int, len(reqs))
+	for i, req := range reqs {
+		if req == nil {
+			invalid = append(invalid, model.BatchItemError{Index: i, Tag: "required", Error: "product is required"})
+			continue
+		}
+		req.Name = s.norm.Name(req.Name)
+		req.Description = s.norm.Text(req.Description)
+		req.SKU = s.norm.Code(req.SKU)
+		req.Category = s.norm.Text(req.Category)
+
+		if err := validation.Struct(req); err != nil {
+			var fieldsErr *validation.ValidationError
+			if !errors.As(err, &fieldsErr) {
+				return nil, fmt.Errorf("validate product %d: %w", i, err)
+			}
+			for _, f := range fieldsErr.Fields {
+				invalid = append(invalid, model.BatchItemError{Index: i, SKU: req.SKU, Field: f.Field, Tag: f.Tag, Error: f.Message})
+			}
+			continue
+		}
+		if first, ok := firstIndex[req.SKU]; ok {
+			invalid = append(invalid, model.BatchItemError{Index: i, SKU: req.SKU, Field: "sku", Tag: "unique",
+				Error: fmt.Sprintf("duplicate SKU, also used by item %d", first)})
+			continue
+		}
+		firstIndex[req.SKU] = i
+
+		products = append(products, &model.Product{
+			Name:        req.Name,
+			Description: req.Description,
+			Price:       req.Price,
+			Stock:       req.Stock,
+			SKU:         req.SKU,
+			Category:    req.Category,
+			IsActive:    true,
+		})
+	}
+
+	skus := make([]string, 0, len(firstIndex))
+	for sku := range firstIndex {
+		skus = append(skus, sku)
+	}
+	existing, err := s.repo.ExistingSKUs(ctx, skus)
+	if err != nil {
+		return nil, fmt.Errorf("check SKU existence: %w", err)
+	}
+	for _, sku := range existing {
+		invalid = append(invalid, model.BatchItemError{Index: firstIndex[sku], SKU: sku, Field: "sku", Tag: "unique", Error: ErrSKUExists.Error()})
+	}
+
+	if len(invalid) > 0 {
+		slices.SortStableFunc(invalid, func(a, b model.BatchItemError) int { return a.Index - b.Index })
+		return nil, &BatchValidationError{Items: invalid}
+	}
+
+	if err := s.repo.CreateProducts(ctx, products); err != nil {
+		if database.IsDuplicateKeyError(err) {
+			return nil, ErrSKUExists
+		}
+		return nil, fmt.Errorf("create products: %w", err)
+	}
+	return products, nil
+}
+
 // GetProductByID retrieves a product by ID
 func (s *Service) GetProductByID(ctx context.Context, id uint) (*model.Product, error) {
 	product, err := s.repo.GetByID(ctx, id)
@@ -71,9 +228,42 @@ func (s *Service) GetProductByID(ctx context.Context, id uint) (*model.Product,
 	return product, nil
 }
 
+// GetProductsByIDs retrieves the products with the given IDs in request order.
+// Duplicate IDs are collapsed; IDs with no product are returned as missing.
+func (s *Service) GetProductsByIDs(ctx context.Context, ids []uint) ([]*model.Product, []uint, error) {
+	unique := uniqueIDs(ids)
+	if len(unique) == 0 {
+		return nil, nil, fmt.Errorf("%w: ids are required", ErrInvalidBatchGet)
+	}
+	if len(unique) > MaxBatchGetIDs {
+		return nil, nil, fmt.Errorf("%w: at most %d ids are allowed", ErrInvalidBatchGet, MaxBatchGetIDs)
+	}
+
+	products, err := s.repo.GetByIDs(ctx, unique)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get products by IDs: %w", err)
+	}
+
+	byID := make(map[uint]*model.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	found := make([]*model.Product, 0, len(products))
+	missing := []uint{}
+	for _, id := range unique {
+		if product, ok := byID[id]; ok {
+			found = append(found, product)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return found, missing, nil
+}
+
 // GetProductBySKU retrieves a product by SKU
 func (s *Service) GetProductBySKU(ctx context.Context, sku string) (*model.Product, error) {
-	product, err := s.repo.GetBySKU(ctx, sku)
+	product, err := s.repo.GetBySKU(ctx, s.norm.Code(sku))
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrProductNotFound
@@ -83,18 +273,50 @@ func (s *Service) GetProductBySKU(ctx context.Context, sku string) (*model.Produ
 	return product, nil
 }
 
-// GetAllProducts retrieves all products with pagination
-func (s *Service) GetAllProducts(ctx context.Context, limit, offset int) ([]*model.Product, error) {
-	products, err := s.repo.GetAll(ctx, limit, offset)
+// GetAllProducts retrieves all products with pagination; archived products are skipped unless includeArchived is set.
+// sort is a comma-separated list of model.SortFields, each optionally prefixed with "-" for descending.
+// columns, as parsed from model.SelectFields, limits the loaded columns here and in the other listings;
+// empty loads them all.
+func (s *Service) GetAllProducts(ctx context.Context, includeArchived bool, sort string, columns []string, limit, offset int) ([]*model.Product, error) {
+	order, err := database.ParseSort(sort, model.SortFields)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.repo.ListProducts(ctx, includeArchived, order, columns, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("get all products: %w", err)
 	}
 	return products, nil
 }
 
+// GetProductsAfterCursor retrieves a page of products using keyset pagination. cursor is the opaque token
+// returned for the previous page, or empty for the first page; order is "asc" (default) or "desc" by ID.
+// The returned token is empty on the last page.
+func (s *Service) GetProductsAfterCursor(ctx context.Context, includeArchived bool, cursor, order string, columns []string, limit int) ([]*model.Product, string, error) {
+	afterID, err := database.DecodeCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	products, next, err := s.repo.ListProductsAfterCursor(ctx, includeArchived, afterID, limit, order, columns)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			return nil, "", err
+		}
+		return nil, "", fmt.Errorf("get products after cursor: %w", err)
+	}
+	if next == 0 {
+		return products, "", nil
+	}
+	return products, database.EncodeCursor(next), nil
+}
+
 // GetActiveProducts retrieves all active products with pagination
-func (s *Service) GetActiveProducts(ctx context.Context, limit, offset int) ([]*model.Product, error) {
-	products, err := s.repo.GetActiveProducts(ctx, limit, offset)
+func (s *Service) GetActiveProducts(ctx context.Context, includeArchived bool, sort string, columns []string, limit, offset int) ([]*model.Product, error) {
+	order, err := database.ParseSort(sort, model.SortFields)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.repo.GetActiveProducts(ctx, includeArchived, order, columns, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("get active products: %w", err)
 	}
@@ -102,8 +324,12 @@ func (s *Service) GetActiveProducts(ctx context.Context, limit, offset int) ([]*
 }
 
 // GetProductsByCategory retrieves products by category
-func (s *Service) GetProductsByCategory(ctx context.Context, category string, limit, offset int) ([]*model.Product, error) {
-	products, err := s.repo.GetByCategory(ctx, category, limit, offset)
+func (s *Service) GetProductsByCategory(ctx context.Context, category string, includeArchived bool, sort string, columns []string, limit, offset int) ([]*model.Product, error) {
+	order, err := database.ParseSort(sort, model.SortFields)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.repo.GetByCategory(ctx, s.norm.Text(category), includeArchived, order, columns, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("get products by category: %w", err)
 	}
@@ -111,27 +337,47 @@ func (s *Service) GetProductsByCategory(ctx context.Context, category string, li
 }
 
 // SearchProducts searches products by name or description
-func (s *Service) SearchProducts(ctx context.Context, query string, limit, offset int) ([]*model.Product, error) {
-	products, err := s.repo.SearchProducts(ctx, query, limit, offset)
+func (s *Service) SearchProducts(ctx context.Context, query string, includeArchived bool, sort string, columns []string, limit, offset int) ([]*model.Product, error) {
+	order, err := database.ParseSort(sort, model.SortFields)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.repo.SearchProducts(ctx, query, includeArchived, order, columns, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("search products: %w", err)
 	}
 	return products, nil
 }
 
-// UpdateProduct updates a product
-func (s *Service) UpdateProduct(ctx context.Context, id uint, req *model.UpdateProductRequest) (*model.Product, error) {
+// CountProductsBy returns the number of products per distinct value of one of model.GroupByFields
+func (s *Service) CountProductsBy(ctx context.Context, groupBy string, includeArchived bool) (map[string]int64, error) {
+	field, ok := model.GroupByFields[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported field %q", ErrInvalidGroupBy, groupBy)
+	}
+
+	counts, err := s.repo.CountByGroup(ctx, field, includeArchived)
+	if err != nil {
+		return nil, fmt.Errorf("count products by %s: %w", groupBy, err)
+	}
+	return counts, nil
+}
+
+// UpdateProduct updates a product. A changed price is recorded in the price history, attributed to changedBy
+// (0 when the user is not known), in the same transaction as the update.
+func (s *Service) UpdateProduct(ctx context.Context, id uint, req *model.UpdateProductRequest, changedBy uint) (*model.Product, error) {
 	product, err := s.GetProductByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	oldPrice := product.Price
 
 	// Apply updates
 	if req.Name != nil {
-		product.Name = *req.Name
+		product.Name = s.norm.Name(*req.Name)
 	}
 	if req.Description != nil {
-		product.Description = *req.Description
+		product.Description = s.norm.Text(*req.Description)
 	}
 	if req.Price != nil {
 		product.Price = *req.Price
@@ -140,45 +386,202 @@ func (s *Service) UpdateProduct(ctx context.Context, id uint, req *model.UpdateP
 		product.Stock = *req.Stock
 	}
 	if req.Category != nil {
-		product.Category = *req.Category
+		product.Category = s.norm.Text(*req.Category)
 	}
 	if req.IsActive != nil {
 		product.IsActive = *req.IsActive
 	}
 
-	if err := s.repo.UpdateByID(ctx, id, product); err != nil {
+	// Without a client version, the update is still rejected if another write lands between our read and write
+	expectedVersion := product.Version
+	if req.Version != nil {
+		expectedVersion = *req.Version
+	}
+	var change *model.PriceChange
+	if product.Price != oldPrice {
+		change = &model.PriceChange{
+			ProductID: id,
+			OldPrice:  oldPrice,
+			NewPrice:  product.Price,
+			ChangedBy: changedBy,
+			ChangedAt: time.Now(),
+		}
+	}
+	if err := s.repo.UpdateProductWithPriceChange(ctx, id, expectedVersion, product, change); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		if errors.Is(err, database.ErrStaleObject) {
+			return nil, ErrProductConflict
+		}
 		return nil, fmt.Errorf("update product: %w", err)
 	}
 
-	return product, nil
+	return s.GetProductByID(ctx, id)
 }
 
-// DeleteProduct deletes a product
+// GetPriceHistory retrieves the recorded price changes of a product, newest first, with pagination
+func (s *Service) GetPriceHistory(ctx context.Context, productID uint, limit, offset int) ([]*model.PriceChange, error) {
+	if _, err := s.GetProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.repo.ListPriceHistory(ctx, productID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("get price history: %w", err)
+	}
+	return changes, nil
+}
+
+// DeleteProduct soft-deletes a product
 func (s *Service) DeleteProduct(ctx context.Context, id uint) error {
-	if err := s.repo.DeleteByID(ctx, id); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrProductNotFound
-		}
+	if _, err := s.GetProductByID(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteProduct(ctx, id); err != nil {
 		return fmt.Errorf("delete product: %w", err)
 	}
 	return nil
 }
 
-// UpdateStock updates product stock
-func (s *Service) UpdateStock(ctx context.Context, id uint, quantity int) error {
+// DeleteProducts soft-deletes the products with the given IDs in one transaction, all or nothing, and returns
+// how many were deleted. Repeated IDs count once; IDs that do not exist are skipped, so a count below the
+// number of distinct IDs tells the caller some were missing. At most bulk.max_batch_size IDs are accepted.
+func (s *Service) DeleteProducts(ctx context.Context, ids []uint) (int, error) {
+	unique := uniqueIDs(ids)
+	if len(unique) == 0 {
+		return 0, fmt.Errorf("%w: ids are required", ErrInvalidBulkDelete)
+	}
+	if len(unique) > s.maxBatchSize {
+		return 0, fmt.Errorf("%w: at most %d ids are allowed", ErrInvalidBulkDelete, s.maxBatchSize)
+	}
+
+	deleted, err := s.repo.DeleteProducts(ctx, unique)
+	if err != nil {
+		return 0, fmt.Errorf("delete products: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// uniqueIDs returns ids without repeats, in their original order
+func uniqueIDs(ids []uint) []uint {
+	unique := make([]uint, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// RestoreProduct restores a soft-deleted product
+func (s *Service) RestoreProduct(ctx context.Context, id uint) (*model.Product, error) {
+	product, err := s.repo.RestoreProduct(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProductNotFound
+		}
+		return nil, fmt.Errorf("restore product: %w", err)
+	}
+	return product, nil
+}
+
+// ArchiveProduct takes a product off sale without deleting it; archiving twice keeps the original timestamp
+func (s *Service) ArchiveProduct(ctx context.Context, id uint) (*model.Product, error) {
 	product, err := s.GetProductByID(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if product.IsArchived() {
+		return product, nil
 	}
 
-	newStock := product.Stock + quantity
-	if newStock < 0 {
-		return ErrInsufficientStock
+	now := time.Now()
+	if err := s.repo.SetArchivedAt(ctx, id, &now); err != nil {
+		return nil, fmt.Errorf("archive product: %w", err)
 	}
+	product.ArchivedAt = &now
+	return product, nil
+}
 
-	if err := s.repo.UpdateStock(ctx, id, quantity); err != nil {
-		return fmt.Errorf("update stock: %w", err)
+// UnarchiveProduct puts an archived product back on sale
+func (s *Service) UnarchiveProduct(ctx context.Context, id uint) (*model.Product, error) {
+	product, err := s.GetProductByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsArchived() {
+		return product, nil
 	}
 
-	return nil
+	if err := s.repo.SetArchivedAt(ctx, id, nil); err != nil {
+		return nil, fmt.Errorf("unarchive product: %w", err)
+	}
+	product.ArchivedAt = nil
+	return product, nil
+}
+
+// UpdateStock adds quantity, which may be negative, to the product stock. The stock is changed under a row lock,
+// so concurrent updates cannot take it below zero.
+func (s *Service) UpdateStock(ctx context.Context, id uint, quantity int) error {
+	_, err := s.changeStock(ctx, id, quantity)
+	return err
+}
+
+// ReserveStock takes quantity units out of the product stock and returns the stock left. The stock is read and
+// written under a row lock, so concurrent reservations cannot oversell; ErrInsufficientStock is returned when
+// fewer than quantity units remain.
+func (s *Service) ReserveStock(ctx context.Context, id uint, quantity int) (int, error) {
+	if quantity <= 0 {
+		return 0, ErrInvalidQuantity
+	}
+	return s.changeStock(ctx, id, -quantity)
+}
+
+// ReleaseStock puts quantity previously reserved units back into the product stock and returns the new stock
+func (s *Service) ReleaseStock(ctx context.Context, id uint, quantity int) (int, error) {
+	if quantity <= 0 {
+		return 0, ErrInvalidQuantity
+	}
+	return s.changeStock(ctx, id, quantity)
+}
+
+// changeStock adds delta to the locked stock of product id, refusing to go below zero
+func (s *Service) changeStock(ctx context.Context, id uint, delta int) (int, error) {
+	stock, err := s.repo.ChangeStockLocked(ctx, id, func(stock int) (int, error) {
+		if stock+delta < 0 {
+			return 0, ErrInsufficientStock
+		}
+		return stock + delta, nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrProductNotFound
+		}
+		if errors.Is(err, ErrInsufficientStock) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("change stock: %w", err)
+	}
+	return stock, nil
+}
+
+// AdjustPrices applies a percentage price change to every product in a category.
+// The percent is rounded to two decimals and applied to each price in exact cents.
+func (s *Service) AdjustPrices(ctx context.Context, category string, percent float64) (int64, error) {
+	if category == "" {
+		return 0, fmt.Errorf("%w: category is required", ErrInvalidPriceAdjustment)
+	}
+	if math.IsNaN(percent) || percent < MinPriceAdjustmentPercent || percent > MaxPriceAdjustmentPercent {
+		return 0, fmt.Errorf("%w: percent must be between %.0f and %.0f", ErrInvalidPriceAdjustment, MinPriceAdjustmentPercent, MaxPriceAdjustmentPercent)
+	}
+
+	affected, err := s.repo.AdjustPrices(ctx, category, int64(math.Round(percent*100)))
+	if err != nil {
+		return 0, fmt.Errorf("adjust prices: %w", err)
+	}
+	return affected, nil
 }
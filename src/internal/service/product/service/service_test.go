@@ -0,0 +1,853 @@
+// +build cgo
+
+package service_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+	"myapp/internal/pkg/outbox"
+	"myapp/internal/service/product/model"
+	"myapp/internal/service/product/repository"
+	"myapp/internal/service/product/service"
+	"myapp/internal/testsupport"
+)
+
+// setupTestService creates a product service backed by a SQLite tenant database.
+// The returned context carries the test tenant ID.
+func setupTestService(t *testing.T, cfg *config.Config) (*service.Service, *gorm.DB, context.Context) {
+	tenant := testsupport.NewTestTenant(t, &model.Product{}, &model.PriceChange{}, &outbox.Event{})
+	repo := repository.NewRepository(tenant.DBManager, nil)
+	return service.NewService(repo, cfg), tenant.DB, tenant.Context()
+}
+
+// TestService_AdjustPrices tests bulk price adjustment by category
+func TestService_AdjustPrices(t *testing.T) {
+	svc, db, ctx := setupTestService(t, &config.Config{})
+
+	products := []*model.Product{
+		testsupport.NewProduct().WithName("Keyboard").WithCategory("peripherals").WithPrice("100").Create(t, db),
+		testsupport.NewProduct().WithName("Mouse").WithCategory("peripherals").WithPrice("25.50").Create(t, db),
+		testsupport.NewProduct().WithName("Monitor").WithCategory("displays").WithPrice("300").Create(t, db),
+	}
+
+	t.Run("apply ten percent to category", func(t *testing.T) {
+		updated, err := svc.AdjustPrices(ctx, "peripherals", 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), updated)
+
+		var keyboard, mouse, monitor model.Product
+		require.NoError(t, db.First(&keyboard, products[0].ID).Error)
+		require.NoError(t, db.First(&mouse, products[1].ID).Error)
+		require.NoError(t, db.First(&monitor, products[2].ID).Error)
+
+		assert.Equal(t, model.MustParseMoney("110.00"), keyboard.Price)
+		assert.Equal(t, model.MustParseMoney("28.05"), mouse.Price)
+		assert.Equal(t, model.MustParseMoney("300.00"), monitor.Price, "other categories are untouched")
+	})
+
+	t.Run("rounds each price half up to the cent", func(t *testing.T) {
+		cable := testsupport.NewProduct().WithCategory("cables").WithPrice("19.99").Create(t, db)
+		clip := testsupport.NewProduct().WithCategory("cables").WithPrice("0.01").Create(t, db)
+
+		_, err := svc.AdjustPrices(ctx, "cables", 50)
+		require.NoError(t, err)
+
+		var storedCable, storedClip model.Product
+		require.NoError(t, db.First(&storedCable, cable.ID).Error)
+		require.NoError(t, db.First(&storedClip, clip.ID).Error)
+		assert.Equal(t, model.MustParseMoney("29.99"), storedCable.Price, "29.985 rounds up")
+		assert.Equal(t, model.MustParseMoney("0.02"), storedClip.Price, "0.015 rounds up")
+		assert.Equal(t, model.MustParseMoney("30.01"), storedCable.Price+storedClip.Price)
+	})
+
+	t.Run("never drops below one cent", func(t *testing.T) {
+		charm := testsupport.NewProduct().WithCategory("charms").WithPrice("0.01").Create(t, db)
+
+		_, err := svc.AdjustPrices(ctx, "charms", -90)
+		require.NoError(t, err)
+
+		var stored model.Product
+		require.NoError(t, db.First(&stored, charm.ID).Error)
+		assert.Equal(t, model.MinPrice, stored.Price)
+	})
+
+	t.Run("bumps version and audit columns", func(t *testing.T) {
+		lamp := testsupport.NewProduct().WithCategory("lighting").WithPrice("40").Create(t, db)
+
+		_, err := svc.AdjustPrices(database.WithUserID(ctx, 42), "lighting", 10)
+		require.NoError(t, err)
+
+		var stored model.Product
+		require.NoError(t, db.First(&stored, lamp.ID).Error)
+		assert.Equal(t, lamp.Version+1, stored.Version)
+		assert.Equal(t, uint(42), stored.UpdatedBy)
+		assert.True(t, stored.UpdatedAt.After(lamp.UpdatedAt))
+
+		// An update based on the price read before the adjustment must not overwrite it
+		price := model.MustParseMoney("35")
+		_, err = svc.UpdateProduct(ctx, lamp.ID, &model.UpdateProductRequest{Price: &price, Version: &lamp.Version}, 0)
+		assert.ErrorIs(t, err, service.ErrProductConflict)
+	})
+
+	t.Run("unknown category", func(t *testing.T) {
+		updated, err := svc.AdjustPrices(ctx, "missing", 10)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), updated)
+	})
+
+	t.Run("reject out of bounds percent", func(t *testing.T) {
+		for _, percent := range []float64{-100, -95, 150} {
+			_, err := svc.AdjustPrices(ctx, "peripherals", percent)
+			assert.ErrorIs(t, err, service.ErrInvalidPriceAdjustment)
+		}
+	})
+
+	t.Run("reject empty category", func(t *testing.T) {
+		_, err := svc.AdjustPrices(ctx, "", 10)
+		assert.ErrorIs(t, err, service.ErrInvalidPriceAdjustment)
+	})
+}
+
+// TestService_CreateProduct_Normalization tests that SKUs and names are normalized per policy
+func TestService_CreateProduct_Normalization(t *testing.T) {
+	t.Run("lowercase codes", func(t *testing.T) {
+		svc, _, ctx := setupTestService(t, &config.Config{
+			Normalization: config.NormalizationConfig{LowercaseCodes: true},
+		})
+
+		product, err := svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "  Wireless   Mouse ", SKU: "  ABC ", Price: model.MustParseMoney("10.00")})
+		require.NoError(t, err)
+		assert.Equal(t, "abc", product.SKU)
+		assert.Equal(t, "Wireless Mouse", product.Name)
+
+		_, err = svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Mouse", SKU: "abc", Price: model.MustParseMoney("10.00")})
+		assert.ErrorIs(t, err, service.ErrSKUExists)
+
+		found, err := svc.GetProductBySKU(ctx, " ABC")
+		require.NoError(t, err)
+		assert.Equal(t, product.ID, found.ID)
+	})
+
+	t.Run("case preserved by default", func(t *testing.T) {
+		svc, _, ctx := setupTestService(t, &config.Config{})
+
+		product, err := svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Mouse", SKU: "  ABC ", Price: model.MustParseMoney("10.00")})
+		require.NoError(t, err)
+		assert.Equal(t, "ABC", product.SKU)
+
+		_, err = svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Mouse", SKU: "ABC", Price: model.MustParseMoney("10.00")})
+		assert.ErrorIs(t, err, service.ErrSKUExists)
+
+		_, err = svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Mouse", SKU: "abc", Price: model.MustParseMoney("10.00")})
+		assert.NoError(t, err)
+	})
+}
+
+// TestService_UpdateProduct_Normalization tests that updates normalize names, descriptions and categories
+func TestService_UpdateProduct_Normalization(t *testing.T) {
+	svc, _, ctx := setupTestService(t, &config.Config{})
+
+	product, err := svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Mouse", SKU: "SKU-1", Category: "peripherals", Price: 10})
+	require.NoError(t, err)
+
+	name, description, category := "  Wireless \t Mouse ", "  Quiet clicks  ", " peripherals "
+	updated, err := svc.UpdateProduct(ctx, product.ID, &model.UpdateProductRequest{Name: &name, Description: &description, Category: &category}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "Wireless Mouse", updated.Name)
+	assert.Equal(t, "Quiet clicks", updated.Description)
+	assert.Equal(t, "peripherals", updated.Category)
+
+	byCategory, err := svc.GetProductsByCategory(ctx, " peripherals", false, "", nil, 0, 0)
+	require.NoError(t, err)
+	assert.Len(t, byCategory, 1)
+}
+
+// TestService_CreateProduct_SKUPerTenant tests that SKUs only have to be unique within a tenant
+func TestService_CreateProduct_SKUPerTenant(t *testing.T) {
+	tenant := testsupport.NewTestTenant(t, &model.Product{}, &outbox.Event{})
+	otherDB := tenant.AddTenant(t, "tenant-other", &model.Product{}, &outbox.Event{})
+	svc := service.NewService(repository.NewRepository(tenant.DBManager, nil), &config.Config{})
+
+	ctx := tenant.Context()
+	otherCtx := database.WithTenantID(context.Background(), "tenant-other")
+
+	_, err := svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Keyboard", SKU: "ABC", Price: model.MustParseMoney("10.00")})
+	require.NoError(t, err)
+
+	t.Run("same SKU in another tenant", func(t *testing.T) {
+		product, err := svc.CreateProduct(otherCtx, &model.CreateProductRequest{Name: "Cable", SKU: "ABC", Price: model.MustParseMoney("5.00")})
+		require.NoError(t, err)
+
+		var stored model.Product
+		require.NoError(t, otherDB.First(&stored, product.ID).Error)
+		assert.Equal(t, "Cable", stored.Name)
+
+		found, err := svc.GetProductBySKU(otherCtx, "ABC")
+		require.NoError(t, err)
+		assert.Equal(t, "Cable", found.Name)
+	})
+
+	t.Run("same SKU twice in one tenant", func(t *testing.T) {
+		_, err := svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Mouse", SKU: "ABC", Price: model.MustParseMoney("10.00")})
+		assert.ErrorIs(t, err, service.ErrSKUExists)
+
+		_, err = svc.CreateProduct(otherCtx, &model.CreateProductRequest{Name: "Adapter", SKU: "ABC", Price: model.MustParseMoney("5.00")})
+		assert.ErrorIs(t, err, service.ErrSKUExists)
+	})
+
+	t.Run("lookup stays within the tenant", func(t *testing.T) {
+		found, err := svc.GetProductBySKU(ctx, "ABC")
+		require.NoError(t, err)
+		assert.Equal(t, "Keyboard", found.Name)
+	})
+}
+
+// TestService_UpdateProduct tests that an update returns the product as stored
+func TestService_UpdateProduct(t *testing.T) {
+	svc, db, ctx := setupTestService(t, &config.Config{})
+
+	product := testsupport.NewProduct().WithName("Keyboard").WithCategory("peripherals").WithPrice("100").Create(t, db)
+
+	t.Run("returns applied changes", func(t *testing.T) {
+		price := model.MustParseMoney("79.99")
+		stock := 12
+		updated, err := svc.UpdateProduct(ctx, product.ID, &model.UpdateProductRequest{Price: &price, Stock: &stock}, 0)
+		require.NoError(t, err)
+
+		assert.Equal(t, product.ID, updated.ID)
+		assert.Equal(t, price, updated.Price)
+		assert.Equal(t, 12, updated.Stock)
+		assert.Equal(t, "Keyboard", updated.Name)
+		assert.Equal(t, "peripherals", updated.Category)
+
+		var stored model.Product
+		require.NoError(t, db.First(&stored, product.ID).Error)
+		assert.Equal(t, stored.Price, updated.Price)
+		assert.Equal(t, stored.Stock, updated.Stock)
+		assert.WithinDuration(t, stored.UpdatedAt, updated.UpdatedAt, time.Millisecond)
+	})
+
+	t.Run("missing product", func(t *testing.T) {
+		name := "Ghost"
+		_, err := svc.UpdateProduct(ctx, 9999, &model.UpdateProductRequest{Name: &name}, 0)
+		assert.ErrorIs(t, err, service.ErrProductNotFound)
+	})
+
+	t.Run("stale version is rejected", func(t *testing.T) {
+		read, err := svc.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		version := read.Version
+
+		first := "Keyboard Pro"
+		updated, err := svc.UpdateProduct(ctx, product.ID, &model.UpdateProductRequest{Name: &first, Version: &version}, 0)
+		require.NoError(t, err)
+		assert.Equal(t, version+1, updated.Version)
+
+		second := "Keyboard Max"
+		_, err = svc.UpdateProduct(ctx, product.ID, &model.UpdateProductRequest{Name: &second, Version: &version}, 0)
+		assert.ErrorIs(t, err, service.ErrProductConflict)
+
+		var stored model.Product
+		require.NoError(t, db.First(&stored, product.ID).Error)
+		assert.Equal(t, "Keyboard Pro", stored.Name)
+	})
+
+	t.Run("records the acting user", func(t *testing.T) {
+		name := "Keyboard Audit"
+		updated, err := svc.UpdateProduct(database.WithUserID(ctx, 5), product.ID, &model.UpdateProductRequest{Name: &name}, 5)
+		require.NoError(t, err)
+		assert.Equal(t, uint(5), updated.UpdatedBy)
+
+		var stored model.Product
+		require.NoError(t, db.First(&stored, product.ID).Error)
+		assert.Equal(t, uint(5), stored.UpdatedBy)
+		assert.Zero(t, stored.CreatedBy, "the product was created without a user")
+
+		created, err := svc.CreateProduct(database.WithUserID(ctx, 6), &model.CreateProductRequest{Name: "Mouse", SKU: "SKU-AUDIT", Price: model.MustParseMoney("5.00")})
+		require.NoError(t, err)
+		var storedCreated model.Product
+		require.NoError(t, db.First(&storedCreated, created.ID).Error)
+		assert.Equal(t, uint(6), storedCreated.CreatedBy)
+		assert.Equal(t, uint(6), storedCreated.UpdatedBy)
+	})
+}
+
+// TestService_OutboxEvents tests that product changes record their events in the outbox, and failed ones do not
+func TestService_OutboxEvents(t *testing.T) {
+	svc, db, ctx := setupTestService(t, &config.Config{})
+
+	product, err := svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Keyboard", SKU: "SKU-1", Price: model.MustParseMoney("10.00")})
+	require.NoError(t, err)
+	name := "Keyboard Pro"
+	_, err = svc.UpdateProduct(ctx, product.ID, &model.UpdateProductRequest{Name: &name}, 0)
+	require.NoError(t, err)
+
+	stale := uint(0)
+	_, err = svc.UpdateProduct(ctx, product.ID, &model.UpdateProductRequest{Name: &name, Version: &stale}, 0)
+	require.ErrorIs(t, err, service.ErrProductConflict)
+
+	require.NoError(t, svc.DeleteProduct(ctx, product.ID))
+
+	var events []outbox.Event
+	require.NoError(t, db.Order("id").Find(&events).Error)
+	require.Len(t, events, 3, "the rejected update records no event")
+	assert.Equal(t, model.EventProductCreated, events[0].EventType)
+	assert.Equal(t, model.EventProductUpdated, events[1].EventType)
+	assert.Equal(t, model.EventProductDeleted, events[2].EventType)
+	for _, event := range events {
+		assert.Equal(t, model.AggregateProduct, event.AggregateType)
+		assert.Equal(t, product.ID, event.AggregateID)
+	}
+	assert.Contains(t, events[1].Payload, `"name":"Keyboard Pro"`)
+}
+
+// TestService_OutboxEvents_BulkAndColumnWrites tests that batch, stock, archive, price adjustment and restore
+// writes record one event per affected product
+func TestService_OutboxEvents_BulkAndColumnWrites(t *testing.T) {
+	svc, db, ctx := setupTestService(t, &config.Config{Bulk: config.BulkConfig{MaxBatchSize: 3}})
+
+	created, err := svc.CreateProducts(ctx, []*model.CreateProductRequest{
+		{Name: "Keyboard", SKU: "SKU-1", Price: model.MustParseMoney("10.00"), Stock: 5, Category: "peripherals"},
+		{Name: "Mouse", SKU: "SKU-2", Price: model.MustParseMoney("5.00"), Stock: 5, Category: "peripherals"},
+	})
+	require.NoError(t, err)
+	keyboard, mouse := created[0], created[1]
+
+	_, err = svc.ReserveStock(ctx, keyboard.ID, 2)
+	require.NoError(t, err)
+	_, err = svc.ArchiveProduct(ctx, keyboard.ID)
+	require.NoError(t, err)
+	_, err = svc.UnarchiveProduct(ctx, keyboard.ID)
+	require.NoError(t, err)
+	_, err = svc.AdjustPrices(ctx, "peripherals", 10)
+	require.NoError(t, err)
+	deleted, err := svc.DeleteProducts(ctx, []uint{mouse.ID, 9999})
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+	_, err = svc.RestoreProduct(ctx, mouse.ID)
+	require.NoError(t, err)
+
+	var events []outbox.Event
+	require.NoError(t, db.Order("id").Find(&events).Error)
+	byProduct := map[uint][]string{}
+	payloads := map[uint][]string{}
+	for _, event := range events {
+		assert.Equal(t, model.AggregateProduct, event.AggregateType)
+		byProduct[event.AggregateID] = append(byProduct[event.AggregateID], event.EventType)
+		payloads[event.AggregateID] = append(payloads[event.AggregateID], event.Payload)
+	}
+	assert.Equal(t, []string{
+		model.EventProductCreated,
+		model.EventProductUpdated, // reserve
+		model.EventProductUpdated, // archive
+		model.EventProductUpdated, // unarchive
+		model.EventProductUpdated, // price adjustment
+	}, byProduct[keyboard.ID])
+	assert.Equal(t, []string{
+		model.EventProductCreated,
+		model.EventProductUpdated, // price adjustment
+		model.EventProductDeleted,
+		model.EventProductRestored,
+	}, byProduct[mouse.ID])
+	assert.NotContains(t, byProduct, uint(9999), "a missing product records no event")
+
+	assert.Contains(t, payloads[keyboard.ID][1], `"stock":3`, "the event carries the product after the reservation")
+	assert.Contains(t, payloads[mouse.ID][3], `"price":5.50`, "the restored product has the adjusted price")
+}
+
+// TestService_GetPriceHistory tests that each price change is recorded with its old and new price
+func TestService_GetPriceHistory(t *testing.T) {
+	svc, db, ctx := setupTestService(t, &config.Config{})
+	product := testsupport.NewProduct().WithPrice("100").Create(t, db)
+
+	update := func(req *model.UpdateProductRequest, changedBy uint) {
+		_, err := svc.UpdateProduct(ctx, product.ID, req, changedBy)
+		require.NoError(t, err)
+	}
+	price := func(amount string) *model.Money {
+		m := model.MustParseMoney(amount)
+		return &m
+	}
+
+	update(&model.UpdateProductRequest{Price: price("120")}, 7)
+	update(&model.UpdateProductRequest{Price: price("120")}, 7) // unchanged, not recorded
+	stock := 3
+	update(&model.UpdateProductRequest{Stock: &stock}, 7)
+	update(&model.UpdateProductRequest{Price: price("99.50")}, 8)
+
+	history, err := svc.GetPriceHistory(ctx, product.ID, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	assert.Equal(t, model.MustParseMoney("120"), history[0].OldPrice)
+	assert.Equal(t, model.MustParseMoney("99.50"), history[0].NewPrice)
+	assert.Equal(t, uint(8), history[0].ChangedBy)
+	assert.Equal(t, model.MustParseMoney("100"), history[1].OldPrice)
+	assert.Equal(t, model.MustParseMoney("120"), history[1].NewPrice)
+	assert.Equal(t, uint(7), history[1].ChangedBy)
+	assert.False(t, history[0].ChangedAt.Before(history[1].ChangedAt))
+
+	t.Run("pagination", func(t *testing.T) {
+		page, err := svc.GetPriceHistory(ctx, product.ID, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		assert.Equal(t, history[1].ID, page[0].ID)
+	})
+
+	t.Run("missing product", func(t *testing.T) {
+		_, err := svc.GetPriceHistory(ctx, 9999, 0, 0)
+		assert.ErrorIs(t, err, service.ErrProductNotFound)
+	})
+}
+
+// TestService_RestoreProduct tests restoring a soft-deleted product
+func TestService_RestoreProduct(t *testing.T) {
+	svc, _, ctx := setupTestService(t, &config.Config{Bulk: config.BulkConfig{MaxBatchSize: 3}})
+
+	product, err := svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Keyboard", SKU: "SKU-1", Category: "peripherals", Price: model.MustParseMoney("10.00")})
+	require.NoError(t, err)
+
+	t.Run("restore soft-deleted product", func(t *testing.T) {
+		require.NoError(t, svc.DeleteProduct(ctx, product.ID))
+
+		listed, err := svc.GetAllProducts(ctx, false, "", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+
+		restored, err := svc.RestoreProduct(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, product.ID, restored.ID)
+
+		listed, err = svc.GetAllProducts(ctx, false, "", nil, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, product.ID, listed[0].ID)
+
+		byCategory, err := svc.GetProductsByCategory(ctx, "peripherals", false, "", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, byCategory, 1)
+	})
+
+	t.Run("never created", func(t *testing.T) {
+		_, err := svc.RestoreProduct(ctx, 9999)
+		assert.ErrorIs(t, err, service.ErrProductNotFound)
+	})
+
+	t.Run("deleted product keeps its SKU", func(t *testing.T) {
+		deleted, err := svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Mouse", SKU: "SKU-2", Price: model.MustParseMoney("5.00")})
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteProduct(ctx, deleted.ID))
+
+		_, err = svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "New Mouse", SKU: "SKU-2", Price: model.MustParseMoney("6.00")})
+		assert.ErrorIs(t, err, service.ErrSKUExists)
+
+		_, err = svc.CreateProducts(ctx, []*model.CreateProductRequest{{Name: "New Mouse", SKU: "SKU-2", Price: model.MustParseMoney("6.00")}})
+		var batchErr *service.BatchValidationError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, "SKU-2", batchErr.Items[0].SKU)
+
+		restored, err := svc.RestoreProduct(ctx, deleted.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "SKU-2", restored.SKU)
+	})
+}
+
+// TestService_ArchiveProduct tests that archived products are hidden from default listings until unarchived
+func TestService_ArchiveProduct(t *testing.T) {
+	svc, _, ctx := setupTestService(t, &config.Config{})
+
+	product, err := svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Keyboard", SKU: "SKU-1", Category: "peripherals", Price: model.MustParseMoney("10.00")})
+	require.NoError(t, err)
+	_, err = svc.CreateProduct(ctx, &model.CreateProductRequest{Name: "Mouse", SKU: "SKU-2", Category: "peripherals", Price: model.MustParseMoney("5.00")})
+	require.NoError(t, err)
+
+	t.Run("archive hides product from default listings", func(t *testing.T) {
+		archived, err := svc.ArchiveProduct(ctx, product.ID)
+		require.NoError(t, err)
+		require.NotNil(t, archived.ArchivedAt)
+		assert.True(t, archived.IsActive, "archiving does not disable the product")
+
+		listed, err := svc.GetAllProducts(ctx, false, "", nil, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, "SKU-2", listed[0].SKU)
+
+		active, err := svc.GetActiveProducts(ctx, false, "", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, active, 1)
+
+		byCategory, err := svc.GetProductsByCategory(ctx, "peripherals", false, "", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, byCategory, 1)
+
+		found, err := svc.SearchProducts(ctx, "Keyboard", false, "", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("include archived", func(t *testing.T) {
+		listed, err := svc.GetAllProducts(ctx, true, "", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, listed, 2)
+
+		found, err := svc.SearchProducts(ctx, "Keyboard", true, "", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, found, 1)
+
+		byID, err := svc.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.True(t, byID.IsArchived())
+	})
+
+	t.Run("unarchive restores product to listings", func(t *testing.T) {
+		unarchived, err := svc.UnarchiveProduct(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Nil(t, unarchived.ArchivedAt)
+
+		listed, err := svc.GetAllProducts(ctx, false, "", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Len(t, listed, 2)
+	})
+
+	t.Run("missing product", func(t *testing.T) {
+		_, err := svc.ArchiveProduct(ctx, 9999)
+		assert.ErrorIs(t, err, service.ErrProductNotFound)
+
+		_, err = svc.UnarchiveProduct(ctx, 9999)
+		assert.ErrorIs(t, err, service.ErrProductNotFound)
+	})
+}
+
+// TestService_SortProducts tests multi-field sorting of product listings
+func TestService_SortProducts(t *testing.T) {
+	svc, db, ctx := setupTestService(t, &config.Config{})
+
+	testsupport.NewProduct().WithSKU("P-1").WithCategory("displays").WithPrice("300").Create(t, db)
+	testsupport.NewProduct().WithSKU("P-2").WithCategory("peripherals").WithPrice("25").Create(t, db)
+	testsupport.NewProduct().WithSKU("P-3").WithCategory("displays").WithPrice("150").Create(t, db)
+	testsupport.NewProduct().WithSKU("P-4").WithCategory("peripherals").WithPrice("100").Create(t, db)
+
+	skus := func(products []*model.Product) []string {
+		result := make([]string, len(products))
+		for i, p := range products {
+			result[i] = p.SKU
+		}
+		return result
+	}
+
+	t.Run("category then price descending", func(t *testing.T) {
+		products, err := svc.GetAllProducts(ctx, false, "category,-price", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"P-1", "P-3", "P-4", "P-2"}, skus(products))
+	})
+
+	t.Run("category then price ascending", func(t *testing.T) {
+		products, err := svc.GetActiveProducts(ctx, false, "category,price", nil, 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"P-3", "P-1", "P-2", "P-4"}, skus(products))
+	})
+
+	t.Run("invalid field anywhere is rejected", func(t *testing.T) {
+		for _, sort := range []string{"secret", "category,secret", "-price,deleted_at"} {
+			_, err := svc.GetAllProducts(ctx, false, sort, nil, 0, 0)
+			assert.ErrorIs(t, err, service.ErrInvalidSort, sort)
+
+			_, err = svc.SearchProducts(ctx, "", false, sort, nil, 0, 0)
+			assert.ErrorIs(t, err, service.ErrInvalidSort, sort)
+		}
+	})
+}
+
+// TestService_CountProductsBy tests per-category product counts
+func TestService_CountProductsBy(t *testing.T) {
+	svc, db, ctx := setupTestService(t, &config.Config{})
+
+	testsupport.NewProduct().WithCategory("displays").Create(t, db)
+	testsupport.NewProduct().WithCategory("displays").Create(t, db)
+	testsupport.NewProduct().WithCategory("peripherals").Create(t, db)
+	testsupport.NewProduct().WithCategory("peripherals").Archived().Create(t, db)
+	testsupport.NewProduct().WithCategory("cables").Inactive().Create(t, db)
+
+	t.Run("group by category", func(t *testing.T) {
+		counts, err := svc.CountProductsBy(ctx, "category", false)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"displays": 2, "peripherals": 1, "cables": 1}, counts)
+	})
+
+	t.Run("include archived", func(t *testing.T) {
+		counts, err := svc.CountProductsBy(ctx, "category", true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), counts["peripherals"])
+	})
+
+	t.Run("invalid group_by", func(t *testing.T) {
+		for _, groupBy := range []string{"", "price", "sku", "category;--"} {
+			_, err := svc.CountProductsBy(ctx, groupBy, false)
+			assert.ErrorIs(t, err, service.ErrInvalidGroupBy, groupBy)
+		}
+	})
+}
+
+// TestService_GetProductsByIDs tests fetching several products by ID
+func TestService_GetProductsByIDs(t *testing.T) {
+	svc, db, ctx := setupTestService(t, &config.Config{})
+
+	first := testsupport.NewProduct().WithSKU("P-1").Create(t, db)
+	second := testsupport.NewProduct().WithSKU("P-2").Create(t, db)
+	deleted := testsupport.NewProduct().WithSKU("P-3").Create(t, db)
+	require.NoError(t, svc.DeleteProduct(ctx, deleted.ID))
+
+	t.Run("request order with missing ids", func(t *testing.T) {
+		products, missing, err := svc.GetProductsByIDs(ctx, []uint{second.ID, 9999, first.ID, deleted.ID, second.ID})
+		require.NoError(t, err)
+		require.Len(t, products, 2)
+		assert.Equal(t, second.ID, products[0].ID)
+		assert.Equal(t, first.ID, products[1].ID)
+		assert.Equal(t, []uint{9999, deleted.ID}, missing)
+	})
+
+	t.Run("invalid id count", func(t *testing.T) {
+		_, _, err := svc.GetProductsByIDs(ctx, nil)
+		assert.ErrorIs(t, err, service.ErrInvalidBatchGet)
+
+		tooMany := make([]uint, service.MaxBatchGetIDs+1)
+		for i := range tooMany {
+			tooMany[i] = uint(i + 1)
+		}
+		_, _, err = svc.GetProductsByIDs(ctx, tooMany)
+		assert.ErrorIs(t, err, service.ErrInvalidBatchGet)
+	})
+}
+
+// TestService_DeleteProducts tests bulk deletes of existing, missing and repeated IDs
+func TestService_DeleteProducts(t *testing.T) {
+	svc, db, ctx := setupTestService(t, &config.Config{Bulk: config.BulkConfig{MaxBatchSize: 3}})
+
+	first := testsupport.NewProduct().Create(t, db)
+	second := testsupport.NewProduct().Create(t, db)
+	kept := testsupport.NewProduct().Create(t, db)
+
+	t.Run("mix of existing and missing ids", func(t *testing.T) {
+		deleted, err := svc.DeleteProducts(ctx, []uint{first.ID, 9999, second.ID, first.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+
+		listed, err := svc.GetAllProducts(ctx, false, "", nil, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, listed, 1)
+		assert.Equal(t, kept.ID, listed[0].ID)
+	})
+
+	t.Run("already deleted ids are not counted", func(t *testing.T) {
+		deleted, err := svc.DeleteProducts(ctx, []uint{first.ID, second.ID})
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+	})
+
+	t.Run("empty or too many ids", func(t *testing.T) {
+		_, err := svc.DeleteProducts(ctx, nil)
+		assert.ErrorIs(t, err, service.ErrInvalidBulkDelete)
+
+		_, err = svc.DeleteProducts(ctx, []uint{1, 2, 3, 4})
+		assert.ErrorIs(t, err, service.ErrInvalidBulkDelete)
+
+		_, err = svc.GetProductByID(ctx, kept.ID)
+		assert.NoError(t, err, "a rejected request deletes nothing")
+	})
+}
+
+// TestService_ReserveStock tests reserving and releasing stock, including concurrent reservations
+func TestService_ReserveStock(t *testing.T) {
+	tenant := testsupport.NewTestTenant(t, &model.Product{}, &outbox.Event{})
+	svc := service.NewService(repository.NewRepository(tenant.DBManager, nil), &config.Config{})
+	ctx := tenant.Context()
+
+	// SQLite drops FOR UPDATE; a single connection serializes the transactions the way the row lock would
+	tenantDB, err := tenant.ConnManager.GetTenantDB(ctx, tenant.ID)
+	require.NoError(t, err)
+	sqlDB, err := tenantDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+
+	t.Run("reserve and release", func(t *testing.T) {
+		product := testsupport.NewProduct().WithStock(5).Create(t, tenant.DB)
+
+		left, err := svc.ReserveStock(ctx, product.ID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 2, left)
+
+		_, err = svc.ReserveStock(ctx, product.ID, 3)
+		assert.ErrorIs(t, err, service.ErrInsufficientStock)
+
+		left, err = svc.ReleaseStock(ctx, product.ID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 5, left)
+
+		_, err = svc.ReserveStock(ctx, product.ID, 0)
+		assert.ErrorIs(t, err, service.ErrInvalidQuantity)
+		_, err = svc.ReserveStock(ctx, 9999, 1)
+		assert.ErrorIs(t, err, service.ErrProductNotFound)
+	})
+
+	t.Run("concurrent reservations never oversell", func(t *testing.T) {
+		const stock, workers = 10, 25
+		product := testsupport.NewProduct().WithStock(stock).Create(t, tenant.DB)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		reserved, rejected := 0, 0
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				left, err := svc.ReserveStock(ctx, product.ID, 1)
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					assert.GreaterOrEqual(t, left, 0)
+					reserved++
+				case errors.Is(err, service.ErrInsufficientStock):
+					rejected++
+				default:
+					t.Errorf("reserve stock: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, stock, reserved)
+		assert.Equal(t, workers-stock, rejected)
+
+		var stored model.Product
+		require.NoError(t, tenant.DB.First(&stored, product.ID).Error)
+		assert.Zero(t, stored.Stock)
+	})
+}
+
+// TestService_UpdateProduct_ConcurrentReservation tests that a reservation landing between UpdateProduct's read
+// and its write makes the update conflict instead of writing back the stock it read
+func TestService_UpdateProduct_ConcurrentReservation(t *testing.T) {
+	tenant := testsupport.NewTestTenant(t, &model.Product{}, &model.PriceChange{}, &outbox.Event{})
+	svc := service.NewService(repository.NewRepository(tenant.DBManager, nil), &config.Config{})
+	ctx := tenant.Context()
+	product := testsupport.NewProduct().WithStock(5).Create(t, tenant.DB)
+
+	// Reserve once, right after UpdateProduct has loaded the product
+	reserved := false
+	require.NoError(t, tenant.ConnDB(t).Callback().Query().After("gorm:query").Register("test:reserve_after_read", func(db *gorm.DB) {
+		if reserved || db.Statement.Table != "products" {
+			return
+		}
+		reserved = true
+		left, err := svc.ReserveStock(ctx, product.ID, 2)
+		require.NoError(t, err)
+		require.Equal(t, 3, left)
+	}))
+
+	name := "Renamed"
+	_, err := svc.UpdateProduct(ctx, product.ID, &model.UpdateProductRequest{Name: &name}, 0)
+	assert.ErrorIs(t, err, service.ErrProductConflict)
+
+	var stored model.Product
+	require.NoError(t, tenant.DB.First(&stored, product.ID).Error)
+	assert.Equal(t, 3, stored.Stock, "the reservation must not be undone")
+	assert.Equal(t, product.Name, stored.Name)
+	assert.Equal(t, product.Version+1, stored.Version)
+}
+
+// TestService_CreateProducts tests all-or-nothing batch creation and its per-item validation
+func TestService_CreateProducts(t *testing.T) {
+	cfg := &config.Config{Bulk: config.BulkConfig{MaxBatchSize: 3}}
+	svc, db, ctx := setupTestService(t, cfg)
+	testsupport.NewProduct().WithSKU("TAKEN").Create(t, db)
+
+	newRequest := func(sku string) *model.CreateProductRequest {
+		return &model.CreateProductRequest{Name: "Product " + sku, Price: 1000, Stock: 1, SKU: sku}
+	}
+	countProducts := func() int64 {
+		var count int64
+		require.NoError(t, db.Model(&model.Product{}).Count(&count).Error)
+		return count
+	}
+
+	t.Run("clean batch", func(t *testing.T) {
+		products, err := svc.CreateProducts(ctx, []*model.CreateProductRequest{newRequest("B-1"), newRequest("B-2")})
+		require.NoError(t, err)
+		require.Len(t, products, 2)
+		assert.NotZero(t, products[0].ID)
+		assert.Equal(t, "B-2", products[1].SKU)
+		assert.True(t, products[1].IsActive)
+		assert.Equal(t, int64(3), countProducts())
+	})
+
+	t.Run("duplicate SKU in the batch", func(t *testing.T) {
+		_, err := svc.CreateProducts(ctx, []*model.CreateProductRequest{newRequest("D-1"), newRequest("D-2"), newRequest("D-1")})
+
+		var validationErr *service.BatchValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.ErrorIs(t, err, service.ErrInvalidBatchCreate)
+		require.Len(t, validationErr.Items, 1)
+		assert.Equal(t, 2, validationErr.Items[0].Index)
+		assert.Equal(t, "D-1", validationErr.Items[0].SKU)
+		assert.Equal(t, int64(3), countProducts(), "nothing is created when an item is invalid")
+	})
+
+	t.Run("existing SKU and invalid fields", func(t *testing.T) {
+		noName := newRequest("N-1")
+		noName.Name = ""
+		_, err := svc.CreateProducts(ctx, []*model.CreateProductRequest{newRequest("N-2"), newRequest("TAKEN"), noName})
+
+		var validationErr *service.BatchValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Items, 2)
+		assert.Equal(t, 1, validationErr.Items[0].Index)
+		assert.Contains(t, validationErr.Items[0].Error, "already exists")
+		assert.Equal(t, 2, validationErr.Items[1].Index)
+		assert.Equal(t, "name is required", validationErr.Items[1].Error)
+		assert.Equal(t, int64(3), countProducts())
+	})
+
+	t.Run("validate tags apply to each item", func(t *testing.T) {
+		short := newRequest("S")
+		short.Name = "ab"
+		_, err := svc.CreateProducts(ctx, []*model.CreateProductRequest{newRequest("V-1"), short})
+
+		var validationErr *service.BatchValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Items, 2)
+		for _, item := range validationErr.Items {
+			assert.Equal(t, 1, item.Index)
+			assert.Equal(t, "min", item.Tag)
+		}
+		assert.Equal(t, "name", validationErr.Items[0].Field)
+		assert.Equal(t, "sku", validationErr.Items[1].Field)
+
+		fields := validationErr.ValidationError().Fields
+		assert.Equal(t, "products[1].name", fields[0].Field)
+		assert.Equal(t, "products[1].sku", fields[1].Field)
+		assert.Equal(t, int64(3), countProducts())
+	})
+
+	t.Run("batch exceeding the max size", func(t *testing.T) {
+		reqs := []*model.CreateProductRequest{newRequest("M-1"), newRequest("M-2"), newRequest("M-3"), newRequest("M-4")}
+		_, err := svc.CreateProducts(ctx, reqs)
+		assert.ErrorIs(t, err, service.ErrInvalidBatchCreate)
+		assert.Contains(t, err.Error(), "at most 3")
+		assert.Equal(t, int64(3), countProducts())
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		_, err := svc.CreateProducts(ctx, nil)
+		assert.ErrorIs(t, err, service.ErrInvalidBatchCreate)
+	})
+}
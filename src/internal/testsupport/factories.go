@@ -0,0 +1,148 @@
+// +build cgo
+
+package testsupport
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	mastermodel "myapp/internal/service/master/model"
+	productmodel "myapp/internal/service/product/model"
+)
+
+// sequence makes generated SKUs and codes unique within a test binary
+var sequence atomic.Int64
+
+// nextSeq returns the next value of the shared sequence
+func nextSeq() int64 {
+	return sequence.Add(1)
+}
+
+// ProductFactory builds products with sensible defaults
+type ProductFactory struct {
+	product productmodel.Product
+}
+
+// NewProduct starts a product with a unique SKU, a name and a positive price
+func NewProduct() *ProductFactory {
+	n := nextSeq()
+	return &ProductFactory{product: productmodel.Product{
+		Name:     fmt.Sprintf("Product %d", n),
+		SKU:      fmt.Sprintf("SKU-%d", n),
+		Price:    productmodel.MustParseMoney("10.00"),
+		IsActive: true,
+	}}
+}
+
+// WithName sets the product name
+func (f *ProductFactory) WithName(name string) *ProductFactory {
+	f.product.Name = name
+	return f
+}
+
+// WithSKU sets the product SKU
+func (f *ProductFactory) WithSKU(sku string) *ProductFactory {
+	f.product.SKU = sku
+	return f
+}
+
+// WithPrice sets the product price from a decimal amount such as "25.50"
+func (f *ProductFactory) WithPrice(price string) *ProductFactory {
+	f.product.Price = productmodel.MustParseMoney(price)
+	return f
+}
+
+// WithStock sets the product stock
+func (f *ProductFactory) WithStock(stock int) *ProductFactory {
+	f.product.Stock = stock
+	return f
+}
+
+// WithCategory sets the product category
+func (f *ProductFactory) WithCategory(category string) *ProductFactory {
+	f.product.Category = category
+	return f
+}
+
+// Inactive marks the product as disabled
+func (f *ProductFactory) Inactive() *ProductFactory {
+	f.product.IsActive = false
+	return f
+}
+
+// Archived marks the product as archived
+func (f *ProductFactory) Archived() *ProductFactory {
+	now := time.Now()
+	f.product.ArchivedAt = &now
+	return f
+}
+
+// Build returns the product without saving it
+func (f *ProductFactory) Build() *productmodel.Product {
+	product := f.product
+	return &product
+}
+
+// Create inserts the product and returns it
+func (f *ProductFactory) Create(t testing.TB, db *gorm.DB) *productmodel.Product {
+	t.Helper()
+	product := f.Build()
+	require.NoError(t, db.Create(product).Error)
+	if !product.IsActive {
+		// GORM skips zero values with a column default on insert
+		require.NoError(t, db.Model(product).Update("is_active", false).Error)
+	}
+	return product
+}
+
+// MasterFactory builds master records with sensible defaults
+type MasterFactory struct {
+	master mastermodel.Master
+}
+
+// NewMaster starts a master record with a unique code, a name and a type
+func NewMaster() *MasterFactory {
+	n := nextSeq()
+	return &MasterFactory{master: mastermodel.Master{
+		Name:     fmt.Sprintf("Master %d", n),
+		Code:     fmt.Sprintf("CODE-%d", n),
+		Type:     "default",
+		IsActive: true,
+	}}
+}
+
+// WithName sets the master name
+func (f *MasterFactory) WithName(name string) *MasterFactory {
+	f.master.Name = name
+	return f
+}
+
+// WithCode sets the master code
+func (f *MasterFactory) WithCode(code string) *MasterFactory {
+	f.master.Code = code
+	return f
+}
+
+// WithType sets the master type
+func (f *MasterFactory) WithType(masterType string) *MasterFactory {
+	f.master.Type = masterType
+	return f
+}
+
+// Build returns the master record without saving it
+func (f *MasterFactory) Build() *mastermodel.Master {
+	master := f.master
+	return &master
+}
+
+// Create inserts the master record and returns it
+func (f *MasterFactory) Create(t testing.TB, db *gorm.DB) *mastermodel.Master {
+	t.Helper()
+	master := f.Build()
+	require.NoError(t, db.Create(master).Error)
+	return master
+}
@@ -0,0 +1,88 @@
+// +build cgo
+
+package testsupport
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"myapp/internal/pkg/auth"
+	"myapp/internal/pkg/auth/keys"
+	"myapp/internal/pkg/config"
+	"myapp/internal/pkg/database"
+)
+
+// TestUserPassword is the plain-text password of users created by NewTestUser
+const TestUserPassword = "SecurePass123"
+
+// AuthModels lists the models migrated for auth tests
+func AuthModels() []interface{} {
+	return []interface{}{&auth.User{}, &auth.RefreshToken{}, &auth.TokenBlacklist{}, &auth.IssuedAccessToken{}, &auth.PasswordResetToken{}, &auth.AuthorizationCode{}, &auth.AccessSession{}, &auth.UsedNonce{}, &auth.WebhookDeadLetter{}}
+}
+
+// NewTestUser inserts a user with TestUserPassword and the given role ("user" when empty)
+func NewTestUser(t testing.TB, db *gorm.DB, email, role string) *auth.User {
+	t.Helper()
+
+	if role == "" {
+		role = "user"
+	}
+	// Minimum cost keeps hashing fast in tests
+	hash, err := auth.HashPassword(TestUserPassword, &config.AuthConfig{BCryptCost: bcrypt.MinCost})
+	require.NoError(t, err)
+
+	user := &auth.User{
+		Email:    email,
+		Password: hash,
+		Role:     role,
+	}
+	require.NoError(t, db.Create(user).Error)
+	return user
+}
+
+// NewTestAuthService creates an auth service whose users and tokens live in db.
+// db must be migrated with AuthModels; a fresh RSA key pair is generated for the test.
+func NewTestAuthService(t testing.TB, db *gorm.DB) *auth.Service {
+	t.Helper()
+
+	dir := t.TempDir()
+	privateKeyPath := filepath.Join(dir, "private.pem")
+	publicKeyPath := filepath.Join(dir, "public.pem")
+	require.NoError(t, keys.GenerateAndSaveKeyPair(privateKeyPath, publicKeyPath, 2048))
+
+	authConfig := config.AuthConfig{
+		AccessTokenDuration:  15 * time.Minute,
+		RefreshTokenDuration: 7 * 24 * time.Hour,
+		RSAPrivateKeyPath:    privateKeyPath,
+		RSAPublicKeyPath:     publicKeyPath,
+		Issuer:               "test-issuer",
+		BCryptCost:           bcrypt.MinCost,
+	}
+	tokenManager, err := auth.NewTokenManager(&authConfig)
+	require.NoError(t, err)
+
+	dbManager := &database.DatabaseManager{MasterDB: db}
+	return auth.NewService(
+		auth.NewRepository(dbManager),
+		auth.NewTokenRepository(dbManager),
+		tokenManager,
+		&config.Config{Auth: authConfig},
+		zap.NewNop(),
+	)
+}
+
+// NewTestAccessToken creates a user with the given role and returns an access token for it
+func NewTestAccessToken(t testing.TB, service *auth.Service, db *gorm.DB, email, role string) string {
+	t.Helper()
+
+	NewTestUser(t, db, email, role)
+	resp, err := service.Login(context.Background(), &auth.LoginRequest{Email: email, Password: TestUserPassword})
+	require.NoError(t, err)
+	return resp.AccessToken
+}